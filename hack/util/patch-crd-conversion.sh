@@ -0,0 +1,49 @@
+#!/usr/bin/env bash
+#
+# Configure the CertificateRequestPolicy CustomResourceDefinition generated by
+# controller-gen to convert between its versions using the approver-policy
+# conversion webhook. controller-gen doesn't generate the conversion stanza,
+# and the webhook Service is only known once the chart is rendered.
+#
+# The CA bundle of the conversion webhook is injected by the cert-manager
+# cainjector from the same Secret as the ValidatingWebhookConfiguration.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+crd="${1}"
+
+if grep -q "^  conversion:" "${crd}"; then
+  exit 0
+fi
+
+tmp="$(mktemp)"
+trap 'rm -f "${tmp}"' EXIT
+
+awk '
+/^  annotations:$/ && !annotated {
+  print
+  print "    cert-manager.io/inject-ca-from-secret: \"{{ .Release.Namespace }}/{{ include \"cert-manager-approver-policy.name\" . }}-tls\""
+  annotated = 1
+  next
+}
+/^spec:$/ && !converted {
+  print
+  print "  conversion:"
+  print "    strategy: Webhook"
+  print "    webhook:"
+  print "      clientConfig:"
+  print "        service:"
+  print "          name: {{ include \"cert-manager-approver-policy.name\" . }}"
+  print "          namespace: {{ .Release.Namespace | quote }}"
+  print "          path: /convert"
+  print "      conversionReviewVersions:"
+  print "      - v1"
+  converted = 1
+  next
+}
+{ print }
+' "${crd}" > "${tmp}"
+
+cat "${tmp}" > "${crd}"
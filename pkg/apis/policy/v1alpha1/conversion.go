@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/cert-manager/approver-policy/pkg/apis/policy/v1beta1"
+)
+
+// ConvertTo converts this CertificateRequestPolicy to the hub version.
+func (p *CertificateRequestPolicy) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.CertificateRequestPolicy)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", dstRaw)
+	}
+	var (
+		spec   v1beta1.CertificateRequestPolicySpec
+		status v1beta1.CertificateRequestPolicyStatus
+	)
+	if err := convertJSON(&p.Spec, &spec); err != nil {
+		return fmt.Errorf("failed to convert spec: %w", err)
+	}
+	if err := convertJSON(&p.Status, &status); err != nil {
+		return fmt.Errorf("failed to convert status: %w", err)
+	}
+	dst.ObjectMeta, dst.Spec, dst.Status = *p.ObjectMeta.DeepCopy(), spec, status
+	return nil
+}
+
+// ConvertFrom converts the hub version to this CertificateRequestPolicy.
+func (p *CertificateRequestPolicy) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.CertificateRequestPolicy)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", srcRaw)
+	}
+	var (
+		spec   CertificateRequestPolicySpec
+		status CertificateRequestPolicyStatus
+	)
+	if err := convertJSON(&src.Spec, &spec); err != nil {
+		return fmt.Errorf("failed to convert spec: %w", err)
+	}
+	if err := convertJSON(&src.Status, &status); err != nil {
+		return fmt.Errorf("failed to convert status: %w", err)
+	}
+	p.ObjectMeta, p.Spec, p.Status = *src.ObjectMeta.DeepCopy(), spec, status
+	return nil
+}
+
+// convertJSON converts between the fields of versions which have the same JSON
+// encoding. Both versions currently have identical fields, so are converted
+// wholly by their encoding. Fields which are changed by a later version must
+// instead be converted explicitly.
+func convertJSON(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
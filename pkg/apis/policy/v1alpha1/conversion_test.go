@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/pointer"
+
+	"github.com/cert-manager/approver-policy/pkg/apis/policy/v1beta1"
+)
+
+// fuzzIterations is the number of random objects converted by each round trip
+// test.
+const fuzzIterations = 1000
+
+// fuzzSeed seeds the random objects of the round trip tests, so that failures
+// are reproducible.
+const fuzzSeed = 1
+
+func Test_Conversion_roundTrip(t *testing.T) {
+	f := fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(fuzzSeed), serializer.NewCodecFactory(GlobalScheme))
+
+	t.Run("v1alpha1 to hub to v1alpha1", func(t *testing.T) {
+		for i := 0; i < fuzzIterations; i++ {
+			policy := new(CertificateRequestPolicy)
+			f.Fuzz(policy)
+			policy = encoded(t, policy)
+
+			hub := new(v1beta1.CertificateRequestPolicy)
+			if err := policy.ConvertTo(hub); err != nil {
+				t.Fatal(err)
+			}
+			got := &CertificateRequestPolicy{TypeMeta: policy.TypeMeta}
+			if err := got.ConvertFrom(hub); err != nil {
+				t.Fatal(err)
+			}
+
+			if !assert.Equal(t, policy, got, "expected no data loss converting through the hub") {
+				return
+			}
+		}
+	})
+
+	t.Run("hub to v1alpha1 to hub", func(t *testing.T) {
+		for i := 0; i < fuzzIterations; i++ {
+			hub := new(v1beta1.CertificateRequestPolicy)
+			f.Fuzz(hub)
+			hub = encoded(t, hub)
+
+			policy := new(CertificateRequestPolicy)
+			if err := policy.ConvertFrom(hub); err != nil {
+				t.Fatal(err)
+			}
+			got := &v1beta1.CertificateRequestPolicy{TypeMeta: hub.TypeMeta}
+			if err := policy.ConvertTo(got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !assert.Equal(t, hub, got, "expected no data loss converting from the hub") {
+				return
+			}
+		}
+	})
+}
+
+func Test_Conversion_overwrites(t *testing.T) {
+	hub := &v1beta1.CertificateRequestPolicy{Spec: v1beta1.CertificateRequestPolicySpec{Priority: pointer.Int(1)}}
+	policy := &CertificateRequestPolicy{Spec: CertificateRequestPolicySpec{Priority: pointer.Int(2), Plugins: map[string]CertificateRequestPolicyPluginData{"foo": {}}}}
+	if err := policy.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, CertificateRequestPolicySpec{Priority: pointer.Int(1)}, policy.Spec, "expected fields of the destination to be replaced, not merged")
+}
+
+// encoded returns the fuzzed object after being encoded and decoded, as it
+// would be stored. Fuzzed values which aren't distinguished by the encoding,
+// such as empty and nil slices, are then not reported as lost.
+func encoded[T any](t *testing.T, obj *T) *T {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(T)
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
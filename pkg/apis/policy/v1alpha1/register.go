@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 
 	"github.com/cert-manager/approver-policy/pkg/apis/policy"
+	"github.com/cert-manager/approver-policy/pkg/apis/policy/v1beta1"
 )
 
 // SchemeGroupVersion is group version used to register these objects
@@ -59,6 +60,9 @@ func init() {
 	if err := AddToScheme(GlobalScheme); err != nil {
 		panic(fmt.Sprintf("failed to add policy.cert-manager.io scheme: %s", err))
 	}
+	if err := v1beta1.AddToScheme(GlobalScheme); err != nil {
+		panic(fmt.Sprintf("failed to add policy.cert-manager.io hub scheme: %s", err))
+	}
 }
 
 // Adds the list of known types to api.Scheme.
@@ -54,6 +54,48 @@ func (in *CertificateRequestPolicy) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyActiveSchedule) DeepCopyInto(out *CertificateRequestPolicyActiveSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]CertificateRequestPolicyActiveWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyActiveSchedule.
+func (in *CertificateRequestPolicyActiveSchedule) DeepCopy() *CertificateRequestPolicyActiveSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyActiveSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyActiveWindow) DeepCopyInto(out *CertificateRequestPolicyActiveWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyActiveWindow.
+func (in *CertificateRequestPolicyActiveWindow) DeepCopy() *CertificateRequestPolicyActiveWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyActiveWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicyAllowed) DeepCopyInto(out *CertificateRequestPolicyAllowed) {
 	*out = *in
@@ -82,6 +124,13 @@ func (in *CertificateRequestPolicyAllowed) DeepCopyInto(out *CertificateRequestP
 		*out = new(CertificateRequestPolicyAllowedStringSlice)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OtherNames != nil {
+		in, out := &in.OtherNames, &out.OtherNames
+		*out = make([]CertificateRequestPolicyAllowedOtherName, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.IsCA != nil {
 		in, out := &in.IsCA, &out.IsCA
 		*out = new(bool)
@@ -96,6 +145,23 @@ func (in *CertificateRequestPolicyAllowed) DeepCopyInto(out *CertificateRequestP
 			copy(*out, *in)
 		}
 	}
+	if in.ExactUsages != nil {
+		in, out := &in.ExactUsages, &out.ExactUsages
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IncludeDefaultUsages != nil {
+		in, out := &in.IncludeDefaultUsages, &out.IncludeDefaultUsages
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]CertificateRequestPolicyAllowedString, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.Subject != nil {
 		in, out := &in.Subject, &out.Subject
 		*out = new(CertificateRequestPolicyAllowedX509Subject)
@@ -113,6 +179,26 @@ func (in *CertificateRequestPolicyAllowed) DeepCopy() *CertificateRequestPolicyA
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyAllowedOtherName) DeepCopyInto(out *CertificateRequestPolicyAllowedOtherName) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyAllowedOtherName.
+func (in *CertificateRequestPolicyAllowedOtherName) DeepCopy() *CertificateRequestPolicyAllowedOtherName {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyAllowedOtherName)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicyAllowedString) DeepCopyInto(out *CertificateRequestPolicyAllowedString) {
 	*out = *in
@@ -126,6 +212,26 @@ func (in *CertificateRequestPolicyAllowedString) DeepCopyInto(out *CertificateRe
 		*out = new(bool)
 		**out = **in
 	}
+	if in.CaseInsensitive != nil {
+		in, out := &in.CaseInsensitive, &out.CaseInsensitive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MatchDNSNames != nil {
+		in, out := &in.MatchDNSNames, &out.MatchDNSNames
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MatchType != nil {
+		in, out := &in.MatchType, &out.MatchType
+		*out = new(AllowedMatchType)
+		**out = **in
+	}
+	if in.Matcher != nil {
+		in, out := &in.Matcher, &out.Matcher
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyAllowedString.
@@ -150,11 +256,61 @@ func (in *CertificateRequestPolicyAllowedStringSlice) DeepCopyInto(out *Certific
 			copy(*out, *in)
 		}
 	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = new(CertificateRequestPolicyValuesFrom)
+		**out = **in
+	}
+	if in.AllowedDomains != nil {
+		in, out := &in.AllowedDomains, &out.AllowedDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Required != nil {
 		in, out := &in.Required, &out.Required
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MinEntries != nil {
+		in, out := &in.MinEntries, &out.MinEntries
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxEntries != nil {
+		in, out := &in.MaxEntries, &out.MaxEntries
+		*out = new(int)
+		**out = **in
+	}
+	if in.Ordered != nil {
+		in, out := &in.Ordered, &out.Ordered
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CaseInsensitive != nil {
+		in, out := &in.CaseInsensitive, &out.CaseInsensitive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MatchType != nil {
+		in, out := &in.MatchType, &out.MatchType
+		*out = new(AllowedMatchType)
+		**out = **in
+	}
+	if in.Matcher != nil {
+		in, out := &in.Matcher, &out.Matcher
+		*out = new(string)
+		**out = **in
+	}
+	if in.IncludeCommonName != nil {
+		in, out := &in.IncludeCommonName, &out.IncludeCommonName
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NormalizeIDNA != nil {
+		in, out := &in.NormalizeIDNA, &out.NormalizeIDNA
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyAllowedStringSlice.
@@ -222,6 +378,21 @@ func (in *CertificateRequestPolicyAllowedX509Subject) DeepCopy() *CertificateReq
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyBaseRef) DeepCopyInto(out *CertificateRequestPolicyBaseRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyBaseRef.
+func (in *CertificateRequestPolicyBaseRef) DeepCopy() *CertificateRequestPolicyBaseRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyBaseRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicyCondition) DeepCopyInto(out *CertificateRequestPolicyCondition) {
 	*out = *in
@@ -254,6 +425,135 @@ func (in *CertificateRequestPolicyConstraints) DeepCopyInto(out *CertificateRequ
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.AllowedDurations != nil {
+		in, out := &in.AllowedDurations, &out.AllowedDurations
+		*out = make([]metav1.Duration, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedDurationsTolerance != nil {
+		in, out := &in.AllowedDurationsTolerance, &out.AllowedDurationsTolerance
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ExpiryAlignment != nil {
+		in, out := &in.ExpiryAlignment, &out.ExpiryAlignment
+		*out = new(ExpiryAlignment)
+		**out = **in
+	}
+	if in.ExpiryAlignmentTolerance != nil {
+		in, out := &in.ExpiryAlignmentTolerance, &out.ExpiryAlignmentTolerance
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxBackdate != nil {
+		in, out := &in.MaxBackdate, &out.MaxBackdate
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinReissueInterval != nil {
+		in, out := &in.MinReissueInterval, &out.MinReissueInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxSANCount != nil {
+		in, out := &in.MaxSANCount, &out.MaxSANCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxSANCountPerType != nil {
+		in, out := &in.MaxSANCountPerType, &out.MaxSANCountPerType
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ForbidDuplicateSANs != nil {
+		in, out := &in.ForbidDuplicateSANs, &out.ForbidDuplicateSANs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ForbidWildcardDNSNames != nil {
+		in, out := &in.ForbidWildcardDNSNames, &out.ForbidWildcardDNSNames
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxDNSNameLabels != nil {
+		in, out := &in.MaxDNSNameLabels, &out.MaxDNSNameLabels
+		*out = new(int)
+		**out = **in
+	}
+	if in.RequireSANs != nil {
+		in, out := &in.RequireSANs, &out.RequireSANs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IsCA != nil {
+		in, out := &in.IsCA, &out.IsCA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxPathLen != nil {
+		in, out := &in.MaxPathLen, &out.MaxPathLen
+		*out = new(int)
+		**out = **in
+	}
+	if in.RequireCASignUsageWhenCA != nil {
+		in, out := &in.RequireCASignUsageWhenCA, &out.RequireCASignUsageWhenCA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RequireCRLSignUsageWhenCA != nil {
+		in, out := &in.RequireCRLSignUsageWhenCA, &out.RequireCRLSignUsageWhenCA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxSubjectEntries != nil {
+		in, out := &in.MaxSubjectEntries, &out.MaxSubjectEntries
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxCommonNameLength != nil {
+		in, out := &in.MaxCommonNameLength, &out.MaxCommonNameLength
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxSubjectTotalLength != nil {
+		in, out := &in.MaxSubjectTotalLength, &out.MaxSubjectTotalLength
+		*out = new(int)
+		**out = **in
+	}
+	if in.AllowedSignatureAlgorithms != nil {
+		in, out := &in.AllowedSignatureAlgorithms, &out.AllowedSignatureAlgorithms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSANTypes != nil {
+		in, out := &in.AllowedSANTypes, &out.AllowedSANTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedExtensionOIDs != nil {
+		in, out := &in.AllowedExtensionOIDs, &out.AllowedExtensionOIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlockedPublicKeyHashes != nil {
+		in, out := &in.BlockedPublicKeyHashes, &out.BlockedPublicKeyHashes
+		*out = new(CertificateRequestPolicyValuesFrom)
+		**out = **in
+	}
+	if in.DurationByKeySize != nil {
+		in, out := &in.DurationByKeySize, &out.DurationByKeySize
+		*out = make([]CertificateRequestPolicyDurationByKeySize, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PrivateKey != nil {
 		in, out := &in.PrivateKey, &out.PrivateKey
 		*out = new(CertificateRequestPolicyConstraintsPrivateKey)
@@ -289,6 +589,24 @@ func (in *CertificateRequestPolicyConstraintsPrivateKey) DeepCopyInto(out *Certi
 		*out = new(int)
 		**out = **in
 	}
+	if in.AllowedRSAPublicExponents != nil {
+		in, out := &in.AllowedRSAPublicExponents, &out.AllowedRSAPublicExponents
+		*out = new([]int)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]int, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedECDSACurves != nil {
+		in, out := &in.AllowedECDSACurves, &out.AllowedECDSACurves
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyConstraintsPrivateKey.
@@ -301,6 +619,32 @@ func (in *CertificateRequestPolicyConstraintsPrivateKey) DeepCopy() *Certificate
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyDurationByKeySize) DeepCopyInto(out *CertificateRequestPolicyDurationByKeySize) {
+	*out = *in
+	if in.Algorithm != nil {
+		in, out := &in.Algorithm, &out.Algorithm
+		*out = new(v1.PrivateKeyAlgorithm)
+		**out = **in
+	}
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		*out = new(int)
+		**out = **in
+	}
+	out.MaxDuration = in.MaxDuration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyDurationByKeySize.
+func (in *CertificateRequestPolicyDurationByKeySize) DeepCopy() *CertificateRequestPolicyDurationByKeySize {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyDurationByKeySize)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicyList) DeepCopyInto(out *CertificateRequestPolicyList) {
 	*out = *in
@@ -343,6 +687,11 @@ func (in *CertificateRequestPolicyPluginData) DeepCopyInto(out *CertificateReque
 			(*out)[key] = val
 		}
 	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(CertificateRequestPolicyPluginSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyPluginData.
@@ -355,6 +704,42 @@ func (in *CertificateRequestPolicyPluginData) DeepCopy() *CertificateRequestPoli
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyPluginSelector) DeepCopyInto(out *CertificateRequestPolicyPluginSelector) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(CertificateRequestPolicySelectorIssuerRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyPluginSelector.
+func (in *CertificateRequestPolicyPluginSelector) DeepCopy() *CertificateRequestPolicyPluginSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyPluginSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyRateLimit) DeepCopyInto(out *CertificateRequestPolicyRateLimit) {
+	*out = *in
+	out.Window = in.Window
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyRateLimit.
+func (in *CertificateRequestPolicyRateLimit) DeepCopy() *CertificateRequestPolicyRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicySelector) DeepCopyInto(out *CertificateRequestPolicySelector) {
 	*out = *in
@@ -363,11 +748,75 @@ func (in *CertificateRequestPolicySelector) DeepCopyInto(out *CertificateRequest
 		*out = new(CertificateRequestPolicySelectorIssuerRef)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IssuerRefs != nil {
+		in, out := &in.IssuerRefs, &out.IssuerRefs
+		*out = make([]CertificateRequestPolicySelectorIssuerRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Namespace != nil {
 		in, out := &in.Namespace, &out.Namespace
 		*out = new(CertificateRequestPolicySelectorNamespace)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(CertificateRequestPolicySelectorServiceAccount)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateLabels != nil {
+		in, out := &in.CertificateLabels, &out.CertificateLabels
+		*out = new(CertificateRequestPolicySelectorCertificateLabels)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretLabels != nil {
+		in, out := &in.SecretLabels, &out.SecretLabels
+		*out = new(CertificateRequestPolicySelectorSecretLabels)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Requester != nil {
+		in, out := &in.Requester, &out.Requester
+		*out = new(CertificateRequestPolicySelectorRequester)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestAnnotations != nil {
+		in, out := &in.RequestAnnotations, &out.RequestAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RequestLabels != nil {
+		in, out := &in.RequestLabels, &out.RequestLabels
+		*out = new(CertificateRequestPolicySelectorRequestLabels)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinDuration != nil {
+		in, out := &in.MinDuration, &out.MinDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SelectOnMissingDuration != nil {
+		in, out := &in.SelectOnMissingDuration, &out.SelectOnMissingDuration
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IsRenewal != nil {
+		in, out := &in.IsRenewal, &out.IsRenewal
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySelector.
@@ -380,6 +829,28 @@ func (in *CertificateRequestPolicySelector) DeepCopy() *CertificateRequestPolicy
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicySelectorCertificateLabels) DeepCopyInto(out *CertificateRequestPolicySelectorCertificateLabels) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySelectorCertificateLabels.
+func (in *CertificateRequestPolicySelectorCertificateLabels) DeepCopy() *CertificateRequestPolicySelectorCertificateLabels {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicySelectorCertificateLabels)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicySelectorIssuerRef) DeepCopyInto(out *CertificateRequestPolicySelectorIssuerRef) {
 	*out = *in
@@ -398,6 +869,13 @@ func (in *CertificateRequestPolicySelectorIssuerRef) DeepCopyInto(out *Certifica
 		*out = new(string)
 		**out = **in
 	}
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySelectorIssuerRef.
@@ -437,6 +915,109 @@ func (in *CertificateRequestPolicySelectorNamespace) DeepCopy() *CertificateRequ
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicySelectorRequestLabels) DeepCopyInto(out *CertificateRequestPolicySelectorRequestLabels) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchExpressions != nil {
+		in, out := &in.MatchExpressions, &out.MatchExpressions
+		*out = make([]metav1.LabelSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySelectorRequestLabels.
+func (in *CertificateRequestPolicySelectorRequestLabels) DeepCopy() *CertificateRequestPolicySelectorRequestLabels {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicySelectorRequestLabels)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicySelectorRequester) DeepCopyInto(out *CertificateRequestPolicySelectorRequester) {
+	*out = *in
+	if in.Usernames != nil {
+		in, out := &in.Usernames, &out.Usernames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySelectorRequester.
+func (in *CertificateRequestPolicySelectorRequester) DeepCopy() *CertificateRequestPolicySelectorRequester {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicySelectorRequester)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicySelectorSecretLabels) DeepCopyInto(out *CertificateRequestPolicySelectorSecretLabels) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySelectorSecretLabels.
+func (in *CertificateRequestPolicySelectorSecretLabels) DeepCopy() *CertificateRequestPolicySelectorSecretLabels {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicySelectorSecretLabels)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicySelectorServiceAccount) DeepCopyInto(out *CertificateRequestPolicySelectorServiceAccount) {
+	*out = *in
+	if in.MatchNames != nil {
+		in, out := &in.MatchNames, &out.MatchNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySelectorServiceAccount.
+func (in *CertificateRequestPolicySelectorServiceAccount) DeepCopy() *CertificateRequestPolicySelectorServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicySelectorServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPolicySpec) {
 	*out = *in
@@ -458,6 +1039,43 @@ func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPoli
 		}
 	}
 	in.Selector.DeepCopyInto(&out.Selector)
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int)
+		**out = **in
+	}
+	if in.BaseRef != nil {
+		in, out := &in.BaseRef, &out.BaseRef
+		*out = new(CertificateRequestPolicyBaseRef)
+		**out = **in
+	}
+	if in.ActiveSchedule != nil {
+		in, out := &in.ActiveSchedule, &out.ActiveSchedule
+		*out = new(CertificateRequestPolicyActiveSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricsLabels != nil {
+		in, out := &in.MetricsLabels, &out.MetricsLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Enforcement != nil {
+		in, out := &in.Enforcement, &out.Enforcement
+		*out = new(CertificateRequestPolicyEnforcement)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(CertificateRequestPolicyRateLimit)
+		**out = **in
+	}
+	if in.Validations != nil {
+		in, out := &in.Validations, &out.Validations
+		*out = make([]CertificateRequestPolicyValidation, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySpec.
@@ -491,3 +1109,33 @@ func (in *CertificateRequestPolicyStatus) DeepCopy() *CertificateRequestPolicySt
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyValidation) DeepCopyInto(out *CertificateRequestPolicyValidation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyValidation.
+func (in *CertificateRequestPolicyValidation) DeepCopy() *CertificateRequestPolicyValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyValuesFrom) DeepCopyInto(out *CertificateRequestPolicyValuesFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyValuesFrom.
+func (in *CertificateRequestPolicyValuesFrom) DeepCopy() *CertificateRequestPolicyValuesFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyValuesFrom)
+	in.DeepCopyInto(out)
+	return out
+}
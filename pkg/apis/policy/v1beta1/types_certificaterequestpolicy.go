@@ -0,0 +1,1551 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var CertificateRequestPolicyKind = "CertificateRequestPolicy"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+//+kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=`.status.conditions[?(@.type == "Ready")].status`,description="CertificateRequestPolicy is ready for evaluation"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Timestamp CertificateRequestPolicy was created"
+//+kubebuilder:resource:categories=cert-manager,shortName=crp,scope=Cluster
+//+kubebuilder:subresource:status
+
+// CertificateRequestPolicy is an object for describing a "policy profile" that
+// makes decisions on whether applicable CertificateRequests should be approved
+// or denied.
+type CertificateRequestPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CertificateRequestPolicySpec   `json:"spec,omitempty"`
+	Status CertificateRequestPolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// CertificateRequestPolicyList is a list of CertificateRequestPolicies.
+type CertificateRequestPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CertificateRequestPolicy `json:"items"`
+}
+
+// CertificateRequestPolicySpec defines the desired state of
+// CertificateRequestPolicy.
+type CertificateRequestPolicySpec struct {
+	// Allowed is the set of attributes that are "allowed" by this policy. A
+	// CertificateRequest will only be considered permissible for this policy if
+	// the CertificateRequest has the same or less as what is allowed.  Empty or
+	// `nil` allowed fields mean CertificateRequests are not allowed to have that
+	// field present to be permissible.
+	// +optional
+	Allowed *CertificateRequestPolicyAllowed `json:"allowed,omitempty"`
+
+	// Constraints is the set of attributes that _must_ be satisfied by the
+	// CertificateRequest for the request to be permissible by the policy. Empty
+	// or `nil` constraint fields mean CertificateRequests satisfy that field
+	// with any value of their corresponding attribute.
+	// +optional
+	Constraints *CertificateRequestPolicyConstraints `json:"constraints,omitempty"`
+
+	// EvaluateCSRDirectly marks that the usages and CA of requests are
+	// evaluated from the extensions of the CSR in `spec.request`, rather than
+	// from the `spec.usages` and `spec.isCA` fields which cert-manager
+	// populates alongside it. The subject and Subject Alternative Names are
+	// always evaluated from the CSR.
+	// Some issuers honour the extensions of the CSR over these fields, so
+	// requests whose fields don't agree with their CSR are denied: either if
+	// `spec.isCA` doesn't match the basic constraints of the CSR, or if
+	// `spec.usages` is populated but doesn't contain every usage of the CSR,
+	// other than `cert sign` for CAs.
+	// Default is false which evaluates the fields of the CertificateRequest.
+	// +optional
+	EvaluateCSRDirectly bool `json:"evaluateCSRDirectly,omitempty"`
+
+	// Plugins define a set of plugins and their configuration that should be
+	// executed when this policy is evaluated against a CertificateRequest. A
+	// plugin must already be built within approver-policy for it to be
+	// available.
+	// +optional
+	Plugins map[string]CertificateRequestPolicyPluginData `json:"plugins,omitempty"`
+
+	// Selector is used for selecting over which CertificateRequests this
+	// CertificateRequestPolicy is appropriate for and so will used for its
+	// approval evaluation.
+	Selector CertificateRequestPolicySelector `json:"selector"`
+
+	// Priority is the precedence of this policy over other policies which
+	// select the same CertificateRequest. Only the policies with the highest
+	// priority of all selecting policies are evaluated, so that a stricter
+	// policy may override a more permissive one of lower priority. Policies
+	// of equal priority are evaluated in name order, and the request is
+	// approved if any one of them approves it.
+	// An omitted field or value of `nil` is equivalent to a priority of `0`.
+	// Must not be negative.
+	// +optional
+	Priority *int `json:"priority,omitempty"`
+
+	// BaseRef references another CertificateRequestPolicy whose Allowed and
+	// Constraints are inherited by this policy as defaults. The base policy may
+	// itself reference a base policy, up to a depth of 5, and must not form a
+	// cycle. Only Allowed and Constraints are inherited; the Plugins, Selector
+	// and Priority of base policies are ignored.
+	// Allowed fields are merged with those of the base policy:
+	//   - `values` and `allowedDomains` lists, `usages`, and the `values` of
+	//     `otherNames` of the same `oid`, are the union of both policies,
+	//     other than the `values` of `ordered` fields which this policy
+	//     overrides;
+	//   - `value`, `valuesFrom`, `required`, `minEntries`, `maxEntries`,
+	//     `ordered`, `caseInsensitive`, `matchDNSNames`, `includeCommonName`,
+	//     `normalizeIDNA`, `isCA`, `exactUsages` and `includeDefaultUsages` of
+	//     this policy override those of the base policy;
+	//   - `matchType` and `matcher` of this policy, if either is set, override
+	//     both of those of the base policy;
+	//   - `annotations` keys are merged, with this policy overriding each key.
+	// Constraints are merged by taking the stricter of both policies:
+	//   - the larger `minDuration`, `minReissueInterval` and
+	//     `privateKey.minSize`;
+	//   - `expiryAlignment` of `Midnight` over `Hour`, and the smaller
+	//     `expiryAlignmentTolerance` and `allowedDurationsTolerance`;
+	//   - the smaller `maxDuration`, `maxPathLen`, `maxSubjectEntries`,
+	//     `maxCommonNameLength`, `maxSubjectTotalLength`, `maxDNSNameLabels`
+	//     and `privateKey.maxSize`;
+	//   - the smaller `maxSANCount`, along with its `maxSANCountPerType`;
+	//   - `forbidDuplicateSANs`, `forbidWildcardDNSNames`, `requireSANs`,
+	//     `requireCASignUsageWhenCA` and `requireCRLSignUsageWhenCA` if set to
+	//     true by either policy;
+	//   - the intersection of `allowedDurations`,
+	//     `allowedSignatureAlgorithms`, `allowedSANTypes`,
+	//     `allowedExtensionOIDs`, `allowedNamespaces`,
+	//     `privateKey.allowedRSAPublicExponents` and
+	//     `privateKey.allowedECDSACurves`;
+	//   - `isCA`, `durationByKeySize`, `blockedPublicKeyHashes` and
+	//     `privateKey.algorithm` of this policy override those of the base
+	//     policy.
+	// +optional
+	BaseRef *CertificateRequestPolicyBaseRef `json:"baseRef,omitempty"`
+
+	// ActiveSchedule restricts the times at which this policy is active.
+	// Policies which are not active are not evaluated, as if they did not
+	// select the request.
+	// An omitted field or value of `nil` means the policy is always active.
+	// +optional
+	ActiveSchedule *CertificateRequestPolicyActiveSchedule `json:"activeSchedule,omitempty"`
+
+	// MetricsLabels are labels recorded on the approved and denied metrics of
+	// CertificateRequests evaluated by this policy, for example to attribute
+	// issuance to a team. Only keys permitted by the `--metrics-label-keys`
+	// flag of approver-policy may be used. Values must be valid Kubernetes
+	// label values. To bound the cardinality of metrics, values over the
+	// maximum number of distinct values of a key are recorded as `_other`.
+	// +optional
+	MetricsLabels map[string]string `json:"metricsLabels,omitempty"`
+
+	// Enforcement defines what happens to requests which this policy would
+	// deny.
+	//   - `Deny` denies the request, unless another policy approves it;
+	//   - `Warn` approves the request, recording why this policy would have
+	//     denied it as a warning event on the request and in the
+	//     `would_deny_total` metric. Useful for observing the effect of a
+	//     policy before enforcing it.
+	// Requests which are approved by another policy are unaffected.
+	// Default is nil which is equivalent to `Deny`.
+	// +kubebuilder:validation:Enum=Deny;Warn
+	// +optional
+	Enforcement *CertificateRequestPolicyEnforcement `json:"enforcement,omitempty"`
+
+	// RateLimit limits the rate at which CertificateRequests are approved by
+	// this policy, for example to contain a compromised workload. Requests
+	// which this policy would approve while the limit is exhausted are not
+	// denied, but are left unprocessed and retried once capacity frees.
+	// The state of the limit is held in memory by approver-policy, so is reset
+	// when approver-policy restarts.
+	// An omitted field or value of `nil` means approvals are not rate limited.
+	// +optional
+	RateLimit *CertificateRequestPolicyRateLimit `json:"rateLimit,omitempty"`
+
+	// BootstrapOnly marks that this policy is only for requests to the
+	// built-in SelfSigned issuer, e.g. to bootstrap a CA, and guards against it
+	// accidentally selecting a real CA. A bootstrap only policy must select
+	// exactly one issuer with `selector.issuerRef`, by its exact name, a kind of
+	// `Issuer` or `ClusterIssuer`, and the `cert-manager.io` group. An
+	// `Issuer` must also be selected in namespaces listed exactly in
+	// `selector.namespace.matchNames`. The policy is rejected if a selected
+	// issuer exists and is not a SelfSigned issuer. This is only checked when
+	// the policy is created or updated, and the policy is otherwise evaluated
+	// as any other policy.
+	// Default is false.
+	// +optional
+	BootstrapOnly bool `json:"bootstrapOnly,omitempty"`
+
+	// Validations are CEL expressions which the CertificateRequest must
+	// satisfy to be permissible by the policy, for rules across fields which
+	// can't be expressed by `allowed` and `constraints`, for example that a
+	// request whose common name ends in `-ca` must be for a CA. The request is
+	// denied with the message of every validation whose expression doesn't
+	// evaluate to true.
+	// Validations aren't inherited from the base policies of `baseRef`.
+	// +optional
+	Validations []CertificateRequestPolicyValidation `json:"validations,omitempty"`
+}
+
+// CertificateRequestPolicyValidation is a CEL expression which a
+// CertificateRequest must satisfy to be permissible by the policy.
+type CertificateRequestPolicyValidation struct {
+	// Expression is a CEL expression which must evaluate to true for the
+	// request to be permissible. The expression is evaluated against the
+	// variable `request`, which has the fields:
+	//   - `commonName`: the common name of the CSR;
+	//   - `subject`: the subject of the CSR, with the lists `organizations`,
+	//     `countries`, `organizationalUnits`, `localities`, `provinces`,
+	//     `streetAddresses` and `postalCodes`, and the string `serialNumber`;
+	//   - `dnsNames`, `ipAddresses`, `uris` and `emailAddresses`: the Subject
+	//     Alternative Names of the CSR;
+	//   - `isCA` and `usages`: the `spec.isCA` and `spec.usages` of the
+	//     request;
+	//   - `duration`: the `spec.duration` of the request, only present if
+	//     defined, so should be tested with `has(request.duration)`;
+	//   - `namespace`, `username` and `groups`: the namespace and requester of
+	//     the request.
+	// The Kubernetes CEL libraries of lists, regular expressions, URLs and
+	// strings are available. An expression which fails to evaluate, for
+	// example by exceeding the cost limit, denies the request.
+	Expression string `json:"expression"`
+
+	// Message is the reason the request is denied if the expression doesn't
+	// evaluate to true.
+	Message string `json:"message"`
+}
+
+// CertificateRequestPolicyActiveSchedule defines the time windows during which
+// a CertificateRequestPolicy is active. Times are evaluated to the minute, so
+// a request evaluated at any second of a window's start minute is within the
+// window, and at any second of its end minute is not.
+type CertificateRequestPolicyActiveSchedule struct {
+	// TimeZone is the IANA time zone name in which the windows are evaluated,
+	// e.g. "Europe/London".
+	// An omitted field or empty value is equivalent to "UTC".
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Windows are the time windows during which the policy is active. The
+	// policy is active if any window contains the current time.
+	// +kubebuilder:validation:MinItems=1
+	Windows []CertificateRequestPolicyActiveWindow `json:"windows"`
+}
+
+// CertificateRequestPolicyActiveWindow is a daily time window during which a
+// CertificateRequestPolicy is active.
+type CertificateRequestPolicyActiveWindow struct {
+	// Days are the days of the week on which the window starts. Accepts
+	// "Mon", "Tue", "Wed", "Thu", "Fri", "Sat" and "Sun".
+	// An omitted field or empty list is equivalent to every day.
+	// +optional
+	Days []string `json:"days,omitempty"`
+
+	// Start is the time of day at which the window starts, inclusive, in the
+	// form "HH:MM" using a 24 hour clock, e.g. "18:00".
+	Start string `json:"start"`
+
+	// End is the time of day at which the window ends, exclusive, in the form
+	// "HH:MM" using a 24 hour clock, e.g. "09:00". If End is not after Start,
+	// the window ends on the following day, so a window with the same Start
+	// and End lasts 24 hours.
+	End string `json:"end"`
+}
+
+// CertificateRequestPolicyRateLimit limits the rate of approvals of a
+// CertificateRequestPolicy. Approvals are limited with a token bucket which
+// holds up to Requests tokens, and is refilled at a rate of Requests tokens
+// per Window, so that bursts of up to Requests approvals are permitted.
+type CertificateRequestPolicyRateLimit struct {
+	// Requests is the maximum number of requests approved by the policy
+	// within each Window. Must be at least 1.
+	Requests int `json:"requests"`
+
+	// Window is the period in which at most Requests requests are approved,
+	// e.g. "1m". Must be greater than 0.
+	Window metav1.Duration `json:"window"`
+}
+
+// CertificateRequestPolicyBaseRef is a reference to a CertificateRequestPolicy
+// whose Allowed and Constraints are inherited.
+type CertificateRequestPolicyBaseRef struct {
+	// Name is the name of the referenced CertificateRequestPolicy.
+	Name string `json:"name"`
+}
+
+// CertificateRequestPolicyAllowed is a set of attributes that are declared as
+// permissible for a CertificateRequest to have those values present. It is
+// permissible for a CertificateRequest to request _less_ than what is allowed,
+// but _not more_, i.e. it is permissible for a CertificateRequest to request a
+// subset of what is allowed.
+// Empty fields or `nil` values declares that the equivalent CertificateRequest
+// field _must_ be omitted or empty for the request to be permitted.
+type CertificateRequestPolicyAllowed struct {
+	// CommonName defines the X.509 Common Name that is permissible.
+	// +optional
+	CommonName *CertificateRequestPolicyAllowedString `json:"commonName,omitempty"`
+
+	// DNSNames defines the X.509 DNS SANs that may be requested for.
+	// Accepts wildcards "*".
+	// Values prefixed with "regex:" are treated as regular expressions which
+	// must match the whole of the requested DNS name, e.g.
+	// `regex:[a-z0-9-]+-prod\.example\.com`.
+	// Values prefixed with "!" deny matching DNS names, and take precedence
+	// over all other values, e.g. `!internal.example.com`. At least one value
+	// must not be prefixed with "!".
+	// Values are matched as described above unless MatchType is set.
+	// +optional
+	DNSNames *CertificateRequestPolicyAllowedStringSlice `json:"dnsNames,omitempty"`
+
+	// IPAddresses defines the X.509 IP SANs that may be requested
+	// for.
+	// Values may be CIDR ranges, e.g. `10.0.0.0/8` or `fd00::/8`, which match
+	// any requested IP address contained within the range.
+	// Values prefixed with "!" deny matching IP addresses, and take precedence
+	// over all other values. At least one value must not be prefixed with "!".
+	// +optional
+	IPAddresses *CertificateRequestPolicyAllowedStringSlice `json:"ipAddresses,omitempty"`
+
+	// URIs defines the X.509 URI SANs that may be requested for.
+	// Values are matched per "/" separated path segment, where "*" matches
+	// within a single segment and "**" matches zero or more segments, e.g.
+	// `spiffe://cluster.local/ns/*/sa/*` or `spiffe://cluster.local/**`. A
+	// value of only "*" matches any URI. Values must be valid URIs.
+	// Values prefixed with "!" deny matching URIs, and take precedence over all
+	// other values. At least one value must not be prefixed with "!".
+	// Values are matched as described above unless MatchType is set. Values
+	// need not be valid URIs if MatchType is `Regex`.
+	// +optional
+	URIs *CertificateRequestPolicyAllowedStringSlice `json:"uris,omitempty"`
+
+	// EmailAddresses defines the X.509 Email SANs that may be
+	// requested for.
+	// +optional
+	EmailAddresses *CertificateRequestPolicyAllowedStringSlice `json:"emailAddresses,omitempty"`
+
+	// OtherNames defines the X.509 otherName SANs that may be requested for,
+	// such as the User Principal Name (UPN), OID `1.3.6.1.4.1.311.20.2.3`, of
+	// smartcard logon certificates. A requested otherName SAN is permitted if
+	// its value matches a value of the entry with the same OID. Only otherName
+	// values which are ASN.1 strings may be permitted.
+	// An omitted field or value of `nil` forbids any otherName SANs being
+	// requested.
+	// +optional
+	OtherNames []CertificateRequestPolicyAllowedOtherName `json:"otherNames,omitempty"`
+
+	// IsCA defines whether it is permissible for a CertificateRequest to have
+	// the `spec.IsCA` field set to `true`.
+	// An omitted field, value of `nil` or `false`, forbids the `spec.IsCA` field
+	// from bring `true`.
+	// A value of `true` permits CertificateRequests setting the `spec.IsCA` field
+	// to `true`.
+	// +optional
+	IsCA *bool `json:"isCA,omitempty"`
+
+	// Usages defines the list of permissible key usages that may appear
+	// on the CertificateRequest `spec.keyUsages` field.
+	// An omitted field or value of `nil` forbids any Usages being requested.
+	// An empty slice `[]` is equivalent to `nil`.
+	// +optional
+	Usages *[]cmapi.KeyUsage `json:"usages,omitempty"`
+
+	// ExactUsages defines whether CertificateRequests must request exactly the
+	// set of key usages defined in Usages, rather than a subset of them.
+	// Requests which omit any of the Usages, or request a usage that is not
+	// in Usages, will be denied.
+	// Usages must be defined if ExactUsages is `true`.
+	// An omitted field, value of `nil` or `false`, permits requesting any
+	// subset of Usages.
+	// +optional
+	ExactUsages *bool `json:"exactUsages,omitempty"`
+
+	// IncludeDefaultUsages defines whether CertificateRequests which don't
+	// request any key usages are evaluated as requesting the usages that
+	// cert-manager applies to them by default, `digital signature` and
+	// `key encipherment`, so that the policy matches the usages the issued
+	// certificate will have.
+	// An omitted field, value of `nil` or `false`, evaluates requests which
+	// don't request any key usages as requesting none.
+	// +optional
+	IncludeDefaultUsages *bool `json:"includeDefaultUsages,omitempty"`
+
+	// Annotations defines the annotations that are permissible to be present
+	// on the CertificateRequest, keyed by annotation key. Only the annotation
+	// keys defined here are evaluated, and all other annotations on the
+	// request are ignored.
+	// An annotation key defined here with a Value of `nil` forbids the
+	// annotation from being present on the request. An annotation key which is
+	// Required must be present on the request with a matching value.
+	// +optional
+	Annotations map[string]CertificateRequestPolicyAllowedString `json:"annotations,omitempty"`
+
+	// Subject defines the X.509 subject that is permissible. An omitted field or
+	// value of `nil` forbids any Subject being requested.
+	// +optional
+	Subject *CertificateRequestPolicyAllowedX509Subject `json:"subject,omitempty"`
+}
+
+// CertificateRequestPolicyAllowedOtherName declares the values of otherName
+// SANs of a type that are permissible for a CertificateRequest to request.
+type CertificateRequestPolicyAllowedOtherName struct {
+	// OID is the object identifier of the otherName type, in dotted decimal
+	// form, e.g. `1.3.6.1.4.1.311.20.2.3`.
+	OID string `json:"oid"`
+
+	// Values defines the values of otherName SANs of this type that may be
+	// requested for. Accepts wildcards "*".
+	Values []string `json:"values"`
+}
+
+// CertificateRequestPolicyAllowedX509Subject declares the X.509 Subject
+// attributes that are permissible for a CertificateRequest to request for this
+// policy. It is permissible for CertificateRequests to request a subset of
+// Allowed X.509 Subject attributes defined.
+type CertificateRequestPolicyAllowedX509Subject struct {
+	// Organizations define the X.509 Subject Organizations that may be requested
+	// for.
+	// +optional
+	Organizations *CertificateRequestPolicyAllowedStringSlice `json:"organizations,omitempty"`
+
+	// Countries define the X.509 Subject Countries that may be requested for.
+	// +optional
+	Countries *CertificateRequestPolicyAllowedStringSlice `json:"countries,omitempty"`
+
+	// OrganizationalUnits defines the X.509 Subject Organizational Units that
+	// may be requested for.
+	// +optional
+	OrganizationalUnits *CertificateRequestPolicyAllowedStringSlice `json:"organizationalUnits,omitempty"`
+
+	// Localities defines the X.509 Subject Localities that may be requested for.
+	// +optional
+	Localities *CertificateRequestPolicyAllowedStringSlice `json:"localities,omitempty"`
+
+	// Provinces defines the X.509 Subject Provinces that may be requested for.
+	// +optional
+	Provinces *CertificateRequestPolicyAllowedStringSlice `json:"provinces,omitempty"`
+
+	// StreetAddresses defines the X.509 Subject Street Addresses that may be
+	// requested for.
+	// +optional
+	StreetAddresses *CertificateRequestPolicyAllowedStringSlice `json:"streetAddresses,omitempty"`
+
+	// PostalCodes defines the X.509 Subject Postal Codes that may be requested
+	// for.
+	// +optional
+	PostalCodes *CertificateRequestPolicyAllowedStringSlice `json:"postalCodes,omitempty"`
+
+	// SerialNumber defines the X.509 Subject Serial Number that may be requested
+	// for.
+	// +optional
+	SerialNumber *CertificateRequestPolicyAllowedString `json:"serialNumber,omitempty"`
+}
+
+// CertificateRequestPolicyAllowedStringSlice represents an allowed string
+// slice value paired with whether the field is a required value on the
+// request.
+type CertificateRequestPolicyAllowedStringSlice struct {
+	// Defines the values that are permissible to be present on request.
+	// Accepts wildcards "*".
+	// An omitted field or value of `nil` forbids any value on the related field
+	// in the request from being requested.
+	// An empty slice `[]` is equivalent to `nil`, however an empty slice pared
+	// with Required `true` is an impossible condition that always denies, so is
+	// rejected.
+	// Values may not be `nil` if Required is `true`, unless AllowedDomains is
+	// defined.
+	// Values of the dnsNames and uris fields may be Go templates, rendered for
+	// each request with `.Namespace`, the namespace of the request, and
+	// `.Labels`, the labels of that namespace. For example
+	// `*.{{ .Namespace }}.svc.cluster.local`. Values referencing a label the
+	// namespace doesn't have never match.
+	// +optional
+	Values *[]string `json:"values,omitempty"`
+
+	// ValuesFrom references a key of a ConfigMap containing further values
+	// that are permissible to be present on request, in addition to Values.
+	// The key must contain one value per line. Blank lines, and lines
+	// beginning with "#", are ignored.
+	// Values are loaded when a request is evaluated, so changes to the
+	// ConfigMap take effect without changing the policy. Requests are not
+	// evaluated against this policy until the ConfigMap and key exist.
+	// Only supported on the dnsNames field.
+	// +optional
+	ValuesFrom *CertificateRequestPolicyValuesFrom `json:"valuesFrom,omitempty"`
+
+	// AllowedDomains defines the domains of email addresses that are
+	// permissible to be present on request, in addition to Values. A requested
+	// email address is permitted if the domain following its last "@" matches
+	// any of the domains. Domains are compared regardless of case.
+	// Accepts wildcards "*", for example `*.example.com` permits
+	// `user@sub.example.com`, but not `user@example.com`.
+	// Domains may not contain "@".
+	// Only supported on the emailAddresses field.
+	// +optional
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+
+	// Required marks this field as being a required value on the request.
+	// May only be set to true if Values, or AllowedDomains, is also defined.
+	// Default is nil which marks the field as not required.
+	// +optional
+	Required *bool `json:"required,omitempty"`
+
+	// MinEntries is the minimum number of values which must be present on the
+	// request. A request with fewer values is denied, including a request
+	// which omits the field if MinEntries is greater than 0.
+	// Only supported on the subject fields.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinEntries *int `json:"minEntries,omitempty"`
+
+	// MaxEntries is the maximum number of values which may be present on the
+	// request. Must be the same value as MinEntries or larger. Setting both
+	// to 1 requires exactly one value.
+	// Only supported on the subject fields.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxEntries *int `json:"maxEntries,omitempty"`
+
+	// Ordered marks that requested values are matched with Values by
+	// position, rather than as an unordered set: the first requested value
+	// must match the first value, the second the second, and so on. A request
+	// with more values than Values is denied. Useful for CAs which encode a
+	// hierarchy in the order of organizational units.
+	// Values must contain at least MinEntries values if Ordered is true.
+	// Only supported on the subject.organizationalUnits field.
+	// Default is nil which marks values as unordered.
+	// +optional
+	Ordered *bool `json:"ordered,omitempty"`
+
+	// CaseInsensitive marks that requested values should be compared with
+	// Values regardless of case, including when matching wildcards.
+	// Only supported on the emailAddresses field.
+	// Default is nil which marks comparisons as case-sensitive.
+	// +optional
+	CaseInsensitive *bool `json:"caseInsensitive,omitempty"`
+
+	// MatchType defines how requested values are matched with Values:
+	//   - `Exact` matches values which are equal;
+	//   - `Prefix` matches values which start with a value;
+	//   - `Suffix` matches values which end with a value;
+	//   - `Wildcard` matches values using the wildcard semantics of the field;
+	//   - `Regex` matches values which are wholly matched by a value as a
+	//     regular expression.
+	// Regular expressions are always anchored to match the whole value, e.g.
+	// `admin` doesn't match `superadmin`, so may not anchor only some
+	// alternatives of an alternation with "^" or "$", e.g. `^admin|root`.
+	// Values prefixed with "!" deny matching values with any MatchType.
+	// Only supported on the dnsNames and uris fields.
+	// Default is nil which is equivalent to `Wildcard`.
+	// +kubebuilder:validation:Enum=Exact;Prefix;Suffix;Wildcard;Regex
+	// +optional
+	MatchType *AllowedMatchType `json:"matchType,omitempty"`
+
+	// Matcher is the name of a matcher, registered with the approver-policy
+	// build, which matches requested values with Values in place of MatchType.
+	// The built-in `wildcard` matcher matches values with wildcards "*".
+	// Values prefixed with "!" deny matching values.
+	// May not be set with MatchType.
+	// Only supported on the dnsNames and uris fields.
+	// +optional
+	Matcher *string `json:"matcher,omitempty"`
+
+	// IncludeCommonName marks that a requested common name which is an IP
+	// address is permitted if it matches Values, rather than the value of the
+	// commonName field.
+	// Only supported on the ipAddresses field.
+	// Default is nil which marks the common name as always evaluated against
+	// the commonName field.
+	// +optional
+	IncludeCommonName *bool `json:"includeCommonName,omitempty"`
+
+	// NormalizeIDNA marks that requested values, and Values, are converted to
+	// their ASCII form with IDNA before being matched, so that
+	// internationalized domain names requested in Unicode match Values in
+	// punycode, and vice versa. For example, `bücher.example.com` matches
+	// `xn--bcher-kva.example.com`. Conversion maps names to lower case.
+	// Values prefixed with "regex:" are not converted. Requests with values
+	// which can't be converted are denied.
+	// Only supported on the dnsNames field, and may not be set with a `Regex`
+	// MatchType.
+	// Default is nil which marks values as matched as requested.
+	// +optional
+	NormalizeIDNA *bool `json:"normalizeIDNA,omitempty"`
+}
+
+// CertificateRequestPolicyValuesFrom references a key of a ConfigMap which
+// contains values, one per line.
+type CertificateRequestPolicyValuesFrom struct {
+	// Namespace is the namespace of the ConfigMap, which must be the namespace
+	// configured with the `--values-from-namespace` flag of approver-policy,
+	// by default the namespace approver-policy is installed in.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Key is the key of the ConfigMap's data which contains the values.
+	Key string `json:"key"`
+}
+
+// CertificateRequestPolicyEnforcement is the action taken on requests which a
+// CertificateRequestPolicy would deny.
+type CertificateRequestPolicyEnforcement string
+
+const (
+	// EnforcementDeny denies requests which the policy would deny.
+	EnforcementDeny CertificateRequestPolicyEnforcement = "Deny"
+
+	// EnforcementWarn approves requests which the policy would deny, warning
+	// why they would have been denied.
+	EnforcementWarn CertificateRequestPolicyEnforcement = "Warn"
+)
+
+// ExpiryAlignment is the boundary that the expiry of requested certificates
+// must be aligned to.
+type ExpiryAlignment string
+
+const (
+	// ExpiryAlignmentMidnight aligns expiries to midnight UTC.
+	ExpiryAlignmentMidnight ExpiryAlignment = "Midnight"
+
+	// ExpiryAlignmentHour aligns expiries to the start of an hour.
+	ExpiryAlignmentHour ExpiryAlignment = "Hour"
+)
+
+// DefaultExpiryAlignmentTolerance is the tolerance of ExpiryAlignment if
+// ExpiryAlignmentTolerance is not set.
+const DefaultExpiryAlignmentTolerance = 5 * time.Minute
+
+// AllowedMatchType is the method by which requested values are matched with
+// the allowed values of a field.
+type AllowedMatchType string
+
+const (
+	// AllowedMatchTypeExact matches requested values which are equal to an
+	// allowed value.
+	AllowedMatchTypeExact AllowedMatchType = "Exact"
+
+	// AllowedMatchTypePrefix matches requested values which start with an
+	// allowed value.
+	AllowedMatchTypePrefix AllowedMatchType = "Prefix"
+
+	// AllowedMatchTypeSuffix matches requested values which end with an
+	// allowed value.
+	AllowedMatchTypeSuffix AllowedMatchType = "Suffix"
+
+	// AllowedMatchTypeWildcard matches requested values using the wildcard
+	// semantics of the field.
+	AllowedMatchTypeWildcard AllowedMatchType = "Wildcard"
+
+	// AllowedMatchTypeRegex matches requested values which are wholly matched
+	// by an allowed value as a regular expression.
+	AllowedMatchTypeRegex AllowedMatchType = "Regex"
+)
+
+// CertificateRequestPolicyAllowedString represents an allowed string value
+// paired with whether the field is a required value on the request.
+type CertificateRequestPolicyAllowedString struct {
+	// Value defines the value that is permissible to be present on the request.
+	// Accepts wildcards "*".
+	// An omitted field or value of `nil` forbids the value from being requested.
+	// An empty string is equivalent to `nil`, however an empty string pared with
+	// Required as `true` is an impossible condition that always denies, so is
+	// rejected on the serialNumber field.
+	// Value may not be `nil` if Required is `true`, unless MatchDNSNames is
+	// `true`.
+	// +optional
+	Value *string `json:"value,omitempty"`
+
+	// Required marks this field as being a required value on the request.
+	// May only be set to true if Value is also defined, or MatchDNSNames is
+	// `true`.
+	// +optional
+	Required *bool `json:"required,omitempty"`
+
+	// CaseInsensitive marks that the requested value should be compared with
+	// Value regardless of case, including when matching wildcards and regular
+	// expressions.
+	// Only supported on the commonName field.
+	// Default is nil which marks comparisons as case-sensitive.
+	// +optional
+	CaseInsensitive *bool `json:"caseInsensitive,omitempty"`
+
+	// MatchDNSNames marks that if Value is not defined, the requested value
+	// should instead be permissible if it matches the values of
+	// `allowed.dnsNames`. If Value is defined, it takes precedence and
+	// `allowed.dnsNames` is not consulted.
+	// Only supported on the commonName field.
+	// +optional
+	MatchDNSNames *bool `json:"matchDNSNames,omitempty"`
+
+	// MatchType defines how the requested value is matched with Value:
+	//   - `Exact` matches a value which is equal;
+	//   - `Prefix` matches a value which starts with Value;
+	//   - `Suffix` matches a value which ends with Value;
+	//   - `Wildcard` matches a value using wildcards "*";
+	//   - `Regex` matches a value which is wholly matched by Value as a
+	//     regular expression.
+	// Regular expressions are always anchored to match the whole value, e.g.
+	// `admin` doesn't match `superadmin`, so may not anchor only some
+	// alternatives of an alternation with "^" or "$", e.g. `^admin|root`.
+	// Only supported on the commonName and serialNumber fields.
+	// Default is nil which is equivalent to `Wildcard`.
+	// +kubebuilder:validation:Enum=Exact;Prefix;Suffix;Wildcard;Regex
+	// +optional
+	MatchType *AllowedMatchType `json:"matchType,omitempty"`
+
+	// Matcher is the name of a matcher, registered with the approver-policy
+	// build, which matches the requested value with Value in place of
+	// MatchType. The built-in `wildcard` matcher matches values with
+	// wildcards "*".
+	// May not be set with MatchType.
+	// Only supported on the commonName and serialNumber fields.
+	// +optional
+	Matcher *string `json:"matcher,omitempty"`
+}
+
+// CertificateRequestPolicyConstraints define fields that, if defined, _must_
+// be satisfied by the CertificateRequest for the request to be permissible by
+// this policy. Fields that are omitted or have a value of `nil` will be
+// satisfied by any value on the corresponding attribute on the request.
+type CertificateRequestPolicyConstraints struct {
+	// MinDuration defines the minimum duration a certificate may be requested
+	// for.
+	// Values are inclusive (i.e. a min value of `1h` will accept a duration of
+	// `1h`). MinDuration and MaxDuration may be the same value.
+	// An omitted field or value of `nil` permits any minimum duration.
+	// If MinDuration is defined, a duration _must_ be requested on the
+	// CertificateRequest.
+	// +optional
+	MinDuration *metav1.Duration `json:"minDuration,omitempty"`
+
+	// MaxDuration defines the maximum duration a certificate may be requested
+	// for.
+	// Values are inclusive (i.e. a max value of `1h` will accept a duration of
+	// `1h`). MaxDuration and MinDuration may be the same value.
+	// An omitted field or value of `nil` permits any maximum duration.
+	// If MaxDuration is defined, a duration _must_ be requested on the
+	// CertificateRequest.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+
+	// AllowedDurations defines the discrete set of durations a certificate
+	// may be requested for, for PKIs which only issue certificates of certain
+	// lifetimes (e.g. `2160h` and `8760h`). The requested duration must be
+	// one of the listed values, within AllowedDurationsTolerance.
+	// AllowedDurations doesn't take precedence over MinDuration and
+	// MaxDuration: if both are defined, the requested duration must be one
+	// of the listed values and within the range.
+	// If AllowedDurations is defined, a duration _must_ be requested on the
+	// CertificateRequest.
+	// An omitted field or value of `nil` permits any duration. If defined,
+	// must contain at least one duration, each greater than 0.
+	// +optional
+	AllowedDurations []metav1.Duration `json:"allowedDurations,omitempty"`
+
+	// AllowedDurationsTolerance defines how much shorter or longer than one
+	// of AllowedDurations the requested duration may be. Values are
+	// inclusive.
+	// May only be set if AllowedDurations is also defined.
+	// Default is nil which requires the requested duration to be exactly one
+	// of AllowedDurations.
+	// +optional
+	AllowedDurationsTolerance *metav1.Duration `json:"allowedDurationsTolerance,omitempty"`
+
+	// ExpiryAlignment defines the boundary that the expiry of requested
+	// certificates must be aligned to:
+	//   - `Midnight` aligns expiries to midnight UTC;
+	//   - `Hour` aligns expiries to the start of an hour.
+	// The actual expiry of a certificate isn't known until it is signed, so the
+	// expiry is taken to be the requested duration after the
+	// CertificateRequest was created, and must be within
+	// ExpiryAlignmentTolerance of a boundary.
+	// If ExpiryAlignment is defined, a duration _must_ be requested on the
+	// CertificateRequest.
+	// An omitted field or value of `nil` permits any expiry.
+	// +kubebuilder:validation:Enum=Midnight;Hour
+	// +optional
+	ExpiryAlignment *ExpiryAlignment `json:"expiryAlignment,omitempty"`
+
+	// ExpiryAlignmentTolerance defines how far before or after a boundary the
+	// expiry of a requested certificate may be, while still being aligned to
+	// ExpiryAlignment. Values are inclusive.
+	// Must be less than half of the period between boundaries.
+	// May only be set if ExpiryAlignment is also defined.
+	// Default is nil which is a tolerance of 5 minutes.
+	// +optional
+	ExpiryAlignmentTolerance *metav1.Duration `json:"expiryAlignmentTolerance,omitempty"`
+
+	// MaxBackdate defines the maximum duration the notBefore of requested
+	// certificates may be backdated by, i.e. set before the time the
+	// certificate is signed.
+	// CertificateRequests don't carry a notBefore, so backdating is only known
+	// from the `cert-manager.io/backdate` annotation of the request, whose
+	// value is a duration (e.g. `1h`). Requests without the annotation are
+	// treated as not backdated, and so are permitted. Issuers which backdate
+	// certificates regardless of the annotation are not constrained.
+	// Values are inclusive (i.e. a max value of `1h` will accept a backdate of
+	// `1h`). A value of `0` forbids requesting backdated certificates.
+	// An omitted field or value of `nil` permits any backdate.
+	// +optional
+	MaxBackdate *metav1.Duration `json:"maxBackdate,omitempty"`
+
+	// MinReissueInterval defines the minimum duration between approvals of
+	// requests for the same identity, to prevent rapid re-issuance loops.
+	// Requests are for the same identity if they are owned by the same
+	// cert-manager Certificate, i.e. they have an owner reference to a
+	// Certificate with the same UID. Requests which are not owned by a
+	// Certificate are not constrained.
+	// A request which this policy approves before the interval has elapsed
+	// since the most recent approval of a request for the same
+	// identity is not denied, but left pending until the interval has
+	// elapsed.
+	// An omitted field or value of `nil` permits any interval.
+	// +optional
+	MinReissueInterval *metav1.Duration `json:"minReissueInterval,omitempty"`
+
+	// MaxSANCount defines the maximum number of Subject Alternative Names
+	// (DNS names, IP addresses, URIs, and email addresses) that may be
+	// requested for.
+	// By default, all SAN types are counted in aggregate. See
+	// MaxSANCountPerType.
+	// Values are inclusive (i.e. a max value of `10` will accept 10 SANs).
+	// An omitted field or value of `nil` permits any number of SANs. A value
+	// of `0` is not permitted.
+	// +optional
+	MaxSANCount *int `json:"maxSANCount,omitempty"`
+
+	// MaxSANCountPerType defines whether MaxSANCount is enforced on each SAN
+	// type separately, rather than on the total number of SANs requested.
+	// May only be set if MaxSANCount is also defined.
+	// Default is nil which counts all SAN types in aggregate.
+	// +optional
+	MaxSANCountPerType *bool `json:"maxSANCountPerType,omitempty"`
+
+	// ForbidDuplicateSANs defines whether requests may contain the same
+	// Subject Alternative Name more than once within a SAN type. DNS names and
+	// email addresses are compared regardless of case.
+	// Default is nil which permits duplicate SANs.
+	// +optional
+	ForbidDuplicateSANs *bool `json:"forbidDuplicateSANs,omitempty"`
+
+	// ForbidWildcardDNSNames defines whether requests may contain a wildcard
+	// DNS name, i.e. a DNS SAN or common name beginning with `*.`.
+	// Default is nil which permits wildcard DNS names.
+	// +optional
+	ForbidWildcardDNSNames *bool `json:"forbidWildcardDNSNames,omitempty"`
+
+	// MaxDNSNameLabels defines the maximum number of labels of each requested
+	// DNS SAN, e.g. `a.b.example.com` has 4 labels. Limits how deep the
+	// subdomains permitted by a wildcard pattern of `allowed.dnsNames`, such
+	// as `*.example.com`, may be. A trailing dot of a fully qualified DNS name
+	// is not counted as a label, while the `*` label of a wildcard DNS name
+	// is.
+	// Values are inclusive (i.e. a max value of `3` will accept
+	// `a.example.com`).
+	// An omitted field or value of `nil` permits any number of labels. A value
+	// of `0` is not permitted.
+	// +optional
+	MaxDNSNameLabels *int `json:"maxDNSNameLabels,omitempty"`
+
+	// RequireSANs defines whether requests must contain at least one Subject
+	// Alternative Name of any type. A request with only a common name is
+	// often a misconfiguration, since clients verify the identity of a
+	// certificate by its SANs.
+	// Default is nil which permits requests without SANs.
+	// +optional
+	RequireSANs *bool `json:"requireSANs,omitempty"`
+
+	// IsCA defines the exact value that the requested `spec.isCA` field, and
+	// the CA value of the basic constraints extension in the CSR if present,
+	// must match. Unlike `allowed.isCA`, which only permits requesting a CA,
+	// this constraint will deny requests that do not match the value, in
+	// either direction.
+	// An omitted field or value of `nil` permits any value.
+	// +optional
+	IsCA *bool `json:"isCA,omitempty"`
+
+	// MaxPathLen defines the maximum path length of the basic constraints
+	// extension that may be requested by CA requests, i.e. requests with
+	// `spec.isCA` set to `true` or with the CA value of the basic constraints
+	// extension in the CSR set. CA requests whose CSR does not request a path
+	// length are treated as requesting an unlimited path length, and so are
+	// denied. A value of `0` only permits CAs which may not issue further
+	// intermediate CAs.
+	// Requests which are not CA requests are unaffected.
+	// Values are inclusive (i.e. a max value of `1` will accept a path length
+	// of `1`).
+	// An omitted field, value of `nil` or `-1` permits any path length. Other
+	// negative values are not permitted.
+	// +optional
+	MaxPathLen *int `json:"maxPathLen,omitempty"`
+
+	// RequireCASignUsageWhenCA defines whether CA requests, i.e. requests with
+	// `spec.isCA` set to `true` or with the CA value of the basic constraints
+	// extension in the CSR set, must request the `cert sign` key usage in
+	// either `spec.usages` or the key usage extension of the CSR. A CA request
+	// without it is inconsistent, so is often a misconfiguration.
+	// Requests which are not CA requests are unaffected.
+	// Default is nil which permits CA requests without the usage.
+	// +optional
+	RequireCASignUsageWhenCA *bool `json:"requireCASignUsageWhenCA,omitempty"`
+
+	// RequireCRLSignUsageWhenCA defines whether CA requests must also request
+	// the `crl sign` key usage, as RequireCASignUsageWhenCA, for CAs which sign
+	// their own certificate revocation lists.
+	// Default is nil which permits CA requests without the usage.
+	// +optional
+	RequireCRLSignUsageWhenCA *bool `json:"requireCRLSignUsageWhenCA,omitempty"`
+
+	// MaxSubjectEntries defines the maximum number of entries of a subject
+	// field that may be requested for, keyed by the subject field. Supported
+	// keys are "organizations", "countries", "organizationalUnits",
+	// "localities", "provinces", "streetAddresses", and "postalCodes".
+	// Values are inclusive (i.e. a max value of `1` will accept 1 entry). A
+	// value of `0` forbids the subject field from being requested.
+	// An omitted key permits any number of entries for that subject field.
+	// +optional
+	MaxSubjectEntries map[string]int `json:"maxSubjectEntries,omitempty"`
+
+	// MaxCommonNameLength defines the maximum length, in bytes of its UTF-8
+	// encoding, of the common name of the CSR subject.
+	// Values are inclusive (i.e. a max value of `64` will accept a common name
+	// of 64 bytes).
+	// An omitted field or value of `nil` permits a common name of any length.
+	// A value of `0` is not permitted.
+	// +optional
+	MaxCommonNameLength *int `json:"maxCommonNameLength,omitempty"`
+
+	// MaxSubjectTotalLength defines the maximum length, in bytes, of the DER
+	// encoded subject of the CSR, i.e. the full sequence of relative
+	// distinguished names, including their attribute types and encoding.
+	// Values are inclusive.
+	// An omitted field or value of `nil` permits a subject of any length. A
+	// value of `0` is not permitted.
+	// +optional
+	MaxSubjectTotalLength *int `json:"maxSubjectTotalLength,omitempty"`
+
+	// AllowedSignatureAlgorithms defines the set of signature algorithms that
+	// the CSR of the request may be signed with. Supported values are
+	// "SHA1WithRSA", "SHA256WithRSA", "SHA384WithRSA", "SHA512WithRSA",
+	// "SHA256WithRSAPSS", "SHA384WithRSAPSS", "SHA512WithRSAPSS",
+	// "ECDSAWithSHA1", "ECDSAWithSHA256", "ECDSAWithSHA384",
+	// "ECDSAWithSHA512", and "PureEd25519".
+	// An omitted field or value of `nil` permits any signature algorithm.
+	// +optional
+	AllowedSignatureAlgorithms []string `json:"allowedSignatureAlgorithms,omitempty"`
+
+	// AllowedSANTypes defines the set of Subject Alternative Name types that
+	// may be requested for. Supported values are "DNS", "IP", "URI", and
+	// "Email". Requests containing a SAN of any other type are denied, even
+	// if that type is permitted by `allowed`, e.g. `["DNS"]` only permits
+	// requests for DNS names.
+	// An omitted field or value of `nil` permits any SAN type.
+	// +optional
+	AllowedSANTypes []string `json:"allowedSANTypes,omitempty"`
+
+	// AllowedExtensionOIDs defines the set of X.509 extensions, by dotted
+	// decimal object identifier (e.g. "1.3.6.1.4.1.11129.2.4.2"), that the
+	// CSR of the request may contain. Requests whose CSR contains an extension
+	// of any other OID are denied. The Subject Alternative Name (2.5.29.17),
+	// basic constraints (2.5.29.19), key usage (2.5.29.15) and extended key
+	// usage (2.5.29.37) extensions are always permitted, since their values
+	// are constrained by other fields.
+	// An omitted field or value of `nil` permits any extension.
+	// +optional
+	AllowedExtensionOIDs []string `json:"allowedExtensionOIDs,omitempty"`
+
+	// AllowedNamespaces defines the namespaces that requests may be created
+	// in. Accepts wildcards "*". Requests from any other namespace are denied.
+	// Unlike `selector.namespace`, which decides whether the policy applies to
+	// a request at all, AllowedNamespaces decides whether a request the policy
+	// applies to is permitted. This is useful as a guardrail for policies
+	// which select a ClusterIssuer for requests from any namespace.
+	// An omitted field or value of `nil` permits any namespace.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// BlockedPublicKeyHashes references a key of a ConfigMap containing the
+	// hex encoded SHA-256 hashes of the DER encoded SubjectPublicKeyInfo of
+	// public keys which may not be requested for, one per line, such as known
+	// compromised keys. Blank lines, and lines beginning with "#", are ignored.
+	// Requests for a listed key are denied. The hashes are loaded when a
+	// request is evaluated, so changes to the ConfigMap take effect without
+	// changing the policy. The ConfigMap and key must exist, and contain only
+	// valid hashes, when the policy is created or updated.
+	// An omitted field or value of `nil` permits any public key.
+	// +optional
+	BlockedPublicKeyHashes *CertificateRequestPolicyValuesFrom `json:"blockedPublicKeyHashes,omitempty"`
+
+	// DurationByKeySize defines the maximum duration a certificate may be
+	// requested for, depending on the algorithm and size of the requested key,
+	// e.g. so that certificates with weaker keys have shorter durations.
+	// Only the most specific rule matching the key of the request applies:
+	//   - a rule with an algorithm is more specific than a rule without;
+	//   - of those, the rule with the largest minSize is most specific.
+	// For example, the rules `{algorithm: RSA, maxDuration: 2160h}` and
+	// `{algorithm: RSA, minSize: 4096, maxDuration: 8760h}` permit at most 90
+	// days for RSA 2048 keys and up to 1 year for RSA 4096 keys.
+	// Requests whose key matches no rule are unaffected. Requests whose key
+	// matches a rule must request a duration. Applies in addition to
+	// MaxDuration.
+	// No two rules may have the same algorithm and minSize.
+	// +optional
+	DurationByKeySize []CertificateRequestPolicyDurationByKeySize `json:"durationByKeySize,omitempty"`
+
+	// PrivateKey defines the shape of permissible private keys that may be used
+	// for the request with this policy.
+	// An omitted field or value of `nil` permits the use of any private key by
+	// the requestor.
+	// +optional
+	PrivateKey *CertificateRequestPolicyConstraintsPrivateKey `json:"privateKey,omitempty"`
+}
+
+// CertificateRequestPolicyDurationByKeySize is the maximum duration of
+// certificates requested with keys matching an algorithm and minimum size.
+type CertificateRequestPolicyDurationByKeySize struct {
+	// Algorithm is the algorithm of keys the rule applies to.
+	// An omitted field or value of `nil` applies the rule to keys of any
+	// algorithm.
+	// +optional
+	Algorithm *cmapi.PrivateKeyAlgorithm `json:"algorithm,omitempty"`
+
+	// MinSize is the minimum size of keys the rule applies to.
+	// Values are inclusive (i.e. a min value of `4096` applies to a size of
+	// `4096`). Ed25519 keys have no size, so only match rules without a
+	// MinSize.
+	// An omitted field or value of `nil` applies the rule to keys of any size.
+	// +optional
+	MinSize *int `json:"minSize,omitempty"`
+
+	// MaxDuration is the maximum duration a certificate may be requested for
+	// with a matching key.
+	// Values are inclusive (i.e. a max value of `1h` will accept a duration of
+	// `1h`).
+	MaxDuration metav1.Duration `json:"maxDuration"`
+}
+
+// CertificateRequestPolicyConstraintsPrivateKey defines constraints on what
+// shape of private key is permissible for a CertificateRequest to have used
+// for its request.
+type CertificateRequestPolicyConstraintsPrivateKey struct {
+	// Algorithm defines the allowed crypto algorithm that is used by the
+	// requestor for their private key in their request.
+	// An omitted field or value of `nil` permits any Algorithm.
+	// +optional
+	Algorithm *cmapi.PrivateKeyAlgorithm `json:"algorithm,omitempty"`
+
+	// MinSize defines the minimum key size a requestor may use for their private
+	// key.
+	// Values are inclusive (i.e. a min value of `2048` will accept a size
+	// of `2048`). MinSize and MaxSize may be the same value.
+	// Ignored for requests using Ed25519 keys, which have a fixed size.
+	// An omitted field or value of `nil` permits any minimum size.
+	// +optional
+	MinSize *int `json:"minSize,omitempty"`
+
+	// MaxSize defines the maximum key size a requestor may use for their private
+	// key.
+	// Values are inclusive (i.e. a min value of `2048` will accept a size
+	// of `2048`). MaxSize and MinSize may be the same value.
+	// Ignored for requests using Ed25519 keys, which have a fixed size.
+	// An omitted field or value of `nil` permits any maximum size.
+	// +optional
+	MaxSize *int `json:"maxSize,omitempty"`
+
+	// AllowedRSAPublicExponents defines the set of public exponents that a
+	// requestor may use for their RSA private key, e.g. `[65537]`.
+	// Only applies to requests using RSA keys; requests using other algorithms
+	// are unaffected.
+	// An omitted field or value of `nil` permits any public exponent. An empty
+	// slice `[]` is not permitted.
+	// +optional
+	AllowedRSAPublicExponents *[]int `json:"allowedRSAPublicExponents,omitempty"`
+
+	// AllowedECDSACurves defines the set of elliptic curves that a requestor
+	// may use for their ECDSA private key. Supported values are "P-256",
+	// "P-384", and "P-521".
+	// Only applies to requests using ECDSA keys; requests using other
+	// algorithms are unaffected.
+	// An omitted field or value of `nil` permits any curve. An empty slice
+	// `[]` is not permitted.
+	// +optional
+	AllowedECDSACurves *[]string `json:"allowedECDSACurves,omitempty"`
+}
+
+// CertificateRequestPolicyPluginData is configuration needed by the plugin
+// approver to evaluate a CertificateRequest on this policy.
+type CertificateRequestPolicyPluginData struct {
+	// Values define a set of well-known, to the plugin, key value pairs that are
+	// required for the plugin to successfully evaluate a request based on this
+	// policy.
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+
+	// Selector restricts the CertificateRequests which the plugin evaluates to
+	// those it selects. Requests which are not selected are not evaluated by
+	// the plugin, but are still evaluated by the rest of this policy.
+	// If this field is omitted, the plugin evaluates all requests selected by
+	// this policy.
+	// +optional
+	Selector *CertificateRequestPolicyPluginSelector `json:"selector,omitempty"`
+}
+
+// CertificateRequestPolicyPluginSelector is used for selecting over which
+// CertificateRequests a plugin evaluates.
+type CertificateRequestPolicyPluginSelector struct {
+	// IssuerRef is used to match the plugin by the issuer of the request, in
+	// the same way as `spec.selector.issuerRef`. MatchLabels is not supported.
+	// +optional
+	IssuerRef *CertificateRequestPolicySelectorIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// CertificateRequestPolicySelector is used for selecting over which
+// CertificateRequests this CertificateRequestPolicy is appropriate for, and if
+// so, will be used to evaluate the request.
+// All selectors that have been configured must _all_ match a
+// CertificateRequest in order for the CertificateRequestPolicy to be chosen
+// for evaluation.
+// At least one of issuerRef, issuerRefs, namespace or serviceAccount must be
+// defined.
+type CertificateRequestPolicySelector struct {
+	// IssuerRef is used to match this CertificateRequestPolicy against processed
+	// CertificateRequests. This policy will only be evaluated against a
+	// CertificateRequest whose `spec.issuerRef` field matches
+	// `spec.selector.issuerRef`. CertificateRequests will not be processed on
+	// unmatched `issuerRef` if defined, regardless of whether the requestor is
+	// bound by RBAC.
+	// Accepts wildcards "*".
+	// Omitted values are equivalent to "*".
+	//
+	// The following value will match _all_ `issuerRefs`:
+	// ```
+	// issuerRef: {}
+	// ```
+	// +optional
+	IssuerRef *CertificateRequestPolicySelectorIssuerRef `json:"issuerRef"`
+
+	// IssuerRefs are used to match this CertificateRequestPolicy against the
+	// `spec.issuerRef` of processed CertificateRequests in the same way as
+	// IssuerRef, for policies which apply to a set of issuers. A request is
+	// selected if any of the entries matches its `spec.issuerRef`.
+	// May not be defined together with IssuerRef, and must have at least one
+	// entry if defined.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	IssuerRefs []CertificateRequestPolicySelectorIssuerRef `json:"issuerRefs,omitempty"`
+
+	// Namespace is used to select on Namespaces, meaning the
+	// CertificateRequestPolicy will only match on CertificateRequests that have
+	// been created in matching selected Namespaces.
+	// If this field is omitted, all Namespaces are selected.
+	// +optional
+	Namespace *CertificateRequestPolicySelectorNamespace `json:"namespace"`
+
+	// ServiceAccount is used to select on ServiceAccounts, meaning the
+	// CertificateRequestPolicy will only match on CertificateRequests that have
+	// been created by a matching selected ServiceAccount. The requesting
+	// ServiceAccount is resolved from the `spec.username` of the
+	// CertificateRequest, which is recorded by cert-manager on creation.
+	// CertificateRequests which were not created by a ServiceAccount will never
+	// match a policy which defines this selector.
+	// If this field is omitted, all requestors are selected.
+	// +optional
+	ServiceAccount *CertificateRequestPolicySelectorServiceAccount `json:"serviceAccount"`
+
+	// CertificateLabels is used to select on the labels of the cert-manager
+	// Certificate which owns the CertificateRequest, meaning the
+	// CertificateRequestPolicy will only match on CertificateRequests whose
+	// owning Certificate matches the selector. The owning Certificate is
+	// resolved from the `metadata.ownerReferences` of the CertificateRequest.
+	// CertificateRequests which are not owned by a Certificate will never
+	// match a policy which defines this selector.
+	// If this field is omitted, all CertificateRequests are selected.
+	// +optional
+	CertificateLabels *CertificateRequestPolicySelectorCertificateLabels `json:"certificateLabels,omitempty"`
+
+	// SecretName is used to select on the name of the Secret that the signed
+	// certificate of the CertificateRequest will be stored in, meaning the
+	// CertificateRequestPolicy will only match on CertificateRequests whose
+	// owning Certificate has a matching `spec.secretName`. The owning
+	// Certificate is resolved from the `metadata.ownerReferences` of the
+	// CertificateRequest. CertificateRequests which are not owned by a
+	// Certificate will never match a policy which defines this selector.
+	// Accepts wildcards "*".
+	// If this field is omitted, all CertificateRequests are selected.
+	// +optional
+	SecretName *string `json:"secretName,omitempty"`
+
+	// SecretLabels is used to select on the labels of the Secret that the
+	// signed certificate of the CertificateRequest will be stored in, meaning
+	// the CertificateRequestPolicy will only match on CertificateRequests whose
+	// owning Certificate matches the selector. The labels of the Secret are
+	// taken from the `spec.secretTemplate.labels` of the owning Certificate,
+	// which cert-manager sets on the Secret; labels set on the Secret by other
+	// means are not considered. CertificateRequests which are not owned by a
+	// Certificate will never match a policy which defines this selector.
+	// If this field is omitted, all CertificateRequests are selected.
+	// +optional
+	SecretLabels *CertificateRequestPolicySelectorSecretLabels `json:"secretLabels,omitempty"`
+
+	// Requester is used to select on the user which created the
+	// CertificateRequest, meaning the CertificateRequestPolicy will only match
+	// on CertificateRequests whose requester matches the selector. The
+	// requester is resolved from the `spec.username` and `spec.groups` of the
+	// CertificateRequest, which are recorded by cert-manager from the identity
+	// of the requesting user on creation. The identity of the user is not
+	// otherwise available once the request has been admitted, so requests
+	// whose username and groups were not recorded only match a requester
+	// selector which selects on neither.
+	// If this field is omitted, all requesters are selected.
+	// +optional
+	Requester *CertificateRequestPolicySelectorRequester `json:"requester,omitempty"`
+
+	// RequestAnnotations is used to select on the annotations of
+	// CertificateRequests, meaning the CertificateRequestPolicy will only match
+	// on CertificateRequests which have all of the given annotation keys, with
+	// values matching the given values.
+	// Values accept wildcards "*".
+	// If this field is omitted, all CertificateRequests are selected.
+	// +optional
+	RequestAnnotations map[string]string `json:"requestAnnotations,omitempty"`
+
+	// RequestLabels is used to select on the labels of CertificateRequests,
+	// meaning the CertificateRequestPolicy will only match on
+	// CertificateRequests whose own labels match the selector. Unlike
+	// CertificateLabels, the owning Certificate is not resolved, so requests
+	// which are not owned by a Certificate may also be selected.
+	// If this field is omitted, all CertificateRequests are selected.
+	// +optional
+	RequestLabels *CertificateRequestPolicySelectorRequestLabels `json:"requestLabels,omitempty"`
+
+	// MinDuration is used to select on the requested duration of
+	// CertificateRequests, meaning the CertificateRequestPolicy will only match
+	// on CertificateRequests which request a `spec.duration` of at least this
+	// value.
+	// If this field is omitted, all requested durations are selected.
+	// +optional
+	MinDuration *metav1.Duration `json:"minDuration,omitempty"`
+
+	// MaxDuration is used to select on the requested duration of
+	// CertificateRequests, meaning the CertificateRequestPolicy will only match
+	// on CertificateRequests which request a `spec.duration` of at most this
+	// value.
+	// If this field is omitted, all requested durations are selected.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+
+	// SelectOnMissingDuration defines whether CertificateRequests which do not
+	// request a `spec.duration`, and so will be issued with the default
+	// duration of the issuer, are selected when MinDuration or MaxDuration are
+	// defined.
+	// An omitted field or value of `false` will not select CertificateRequests
+	// without a requested duration.
+	// +optional
+	SelectOnMissingDuration *bool `json:"selectOnMissingDuration,omitempty"`
+
+	// IsRenewal is used to select on whether CertificateRequests are renewals
+	// of a Certificate, meaning the CertificateRequestPolicy will only match on
+	// CertificateRequests which are renewals if `true`, or only on the first
+	// request for a Certificate if `false`.
+	// A request is a renewal if its `cert-manager.io/certificate-revision`
+	// annotation is greater than 1, and is not if the annotation is 1. If the
+	// request has no revision annotation, it is a renewal if the Certificate
+	// which owns it owns an earlier CertificateRequest. Requests for which this
+	// can't be determined, i.e. which have a malformed revision annotation, or
+	// have no revision annotation and are not owned by a Certificate, are
+	// never selected when this field is defined.
+	// If this field is omitted, all requests are selected.
+	// +optional
+	IsRenewal *bool `json:"isRenewal,omitempty"`
+
+	// Authoritative makes the decision of this policy final for the
+	// CertificateRequests it selects: if this policy denies a selected
+	// request, the request is denied, even if other selecting policies would
+	// approve it.
+	// Authoritative policies are evaluated before other policies of the same
+	// priority, so an authoritative policy which approves a request is the
+	// approving policy. Only the selecting policies with the highest priority
+	// are evaluated, so an authoritative policy has no effect on requests
+	// which are also selected by a policy of a higher priority.
+	// Has no effect on policies with the `Warn` enforcement, whose denials
+	// never deny requests.
+	// Default is false.
+	// +optional
+	Authoritative bool `json:"authoritative,omitempty"`
+}
+
+// CertificateRequestPolicySelectorIssuerRef defines the selector for matching
+// on `issuerRef` of requests.
+type CertificateRequestPolicySelectorIssuerRef struct {
+	// Name is the wildcard selector to match the `spec.issuerRef.name` field on
+	// requests.
+	// Accepts wildcards "*".
+	// An omitted field or value of `nil` matches all.
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// Kind is the wildcard selector to match the `spec.issuerRef.kind` field on
+	// requests.
+	// Accepts wildcards "*".
+	// An omitted field or value of `nil` matches all.
+	// +optional
+	Kind *string `json:"kind,omitempty"`
+
+	// Group is the wildcard selector to match the `spec.issuerRef.group` field
+	// on requests.
+	// Accepts wildcards "*".
+	// An omitted field or value of `nil` matches all.
+	// +optional
+	Group *string `json:"group,omitempty"`
+
+	// MatchLabels is the set of labels that select on CertificateRequests whose
+	// referenced issuer has matching labels. Only cert-manager.io `Issuer` and
+	// `ClusterIssuer` issuers are supported. Policies will not match
+	// CertificateRequests whose referenced issuer does not exist, or is not
+	// supported.
+	// If Name is also defined, both Name and MatchLabels must match.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// CertificateRequestPolicySelectorNamespace defines the selector for matching
+// on the `Namespace` of requests. Note that all selectors in the Namespace
+// selector must match in order for the request to be considered for evaluation
+// by this policy.
+type CertificateRequestPolicySelectorNamespace struct {
+	// MatchNames are the set of Namespace names that select on
+	// CertificateRequests that have been created in a matching Namespace.
+	// Accepts wildcards "*".
+	// +optional
+	MatchNames []string `json:"matchNames,omitempty"`
+
+	// MatchLabels is the set of Namespace labels that select on
+	// CertificateRequests which have been created in a Namespace matching the
+	// selector.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// CertificateRequestPolicySelectorCertificateLabels defines the selector for
+// matching the labels of the Certificate which owns the request.
+type CertificateRequestPolicySelectorCertificateLabels struct {
+	// MatchLabels is the set of Certificate labels that select on
+	// CertificateRequests which are owned by a Certificate matching the
+	// selector.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// CertificateRequestPolicySelectorSecretLabels defines the selector for
+// matching the labels of the Secret that the certificate of the request will
+// be stored in.
+type CertificateRequestPolicySelectorSecretLabels struct {
+	// MatchLabels is the set of Secret labels that select on
+	// CertificateRequests which are owned by a Certificate whose
+	// `spec.secretTemplate.labels` match the selector.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// CertificateRequestPolicySelectorRequestLabels defines the selector for
+// matching the labels of the request. Both MatchLabels and MatchExpressions
+// must match if defined.
+type CertificateRequestPolicySelectorRequestLabels struct {
+	// MatchLabels is the set of labels that select on CertificateRequests
+	// whose labels match the selector.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+
+	// MatchExpressions is the list of label selector requirements that select
+	// on CertificateRequests whose labels match all of the requirements.
+	// +optional
+	MatchExpressions []metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// CertificateRequestPolicySelectorRequester defines the selector for matching
+// the user which created the request. If both Usernames and Groups are
+// defined, the requester must match both.
+type CertificateRequestPolicySelectorRequester struct {
+	// Usernames is the set of usernames that select on CertificateRequests
+	// which have been created by a user with a matching username.
+	// Accepts wildcards "*".
+	// +optional
+	Usernames []string `json:"usernames,omitempty"`
+
+	// Groups is the set of groups that select on CertificateRequests which
+	// have been created by a user who is a member of any matching group.
+	// Accepts wildcards "*".
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// CertificateRequestPolicySelectorServiceAccount defines the selector for
+// matching the ServiceAccount which created the request.
+type CertificateRequestPolicySelectorServiceAccount struct {
+	// MatchNames are the set of ServiceAccount names that select on
+	// CertificateRequests that have been created by a matching ServiceAccount.
+	// Names may be given as `<name>`, which matches a ServiceAccount of that
+	// name in the same Namespace as the request, or `<namespace>:<name>`,
+	// which matches a ServiceAccount in the given Namespace.
+	// Accepts wildcards "*".
+	// +optional
+	MatchNames []string `json:"matchNames,omitempty"`
+
+	// MatchLabels is the set of ServiceAccount labels that select on
+	// CertificateRequests which have been created by a ServiceAccount matching
+	// the selector.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// CertificateRequestPolicyStatus defines the observed state of the
+// CertificateRequestPolicy.
+type CertificateRequestPolicyStatus struct {
+	// List of status conditions to indicate the status of the
+	// CertificateRequestPolicy.
+	// Known condition types are `Ready`.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []CertificateRequestPolicyCondition `json:"conditions,omitempty"`
+}
+
+// CertificateRequestPolicyCondition contains condition information for a
+// CertificateRequestPolicyStatus.
+type CertificateRequestPolicyCondition struct {
+	// Type of the condition, known values are (`Ready`).
+	Type CertificateRequestPolicyConditionType `json:"type"`
+
+	// Status of the condition, one of ('True', 'False', 'Unknown').
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the timestamp corresponding to the last status
+	// change of this condition.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a brief machine readable explanation for the condition's last
+	// transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the details of the last
+	// transition, complementing reason.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// If set, this represents the .metadata.generation that the condition was
+	// set based upon.
+	// For instance, if .metadata.generation is currently 12, but the
+	// .status.condition[x].observedGeneration is 9, the condition is out of date
+	// with respect to the current state of the CertificateRequestPolicy.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+const (
+	// DenialReasonsAnnotationKey is the annotation set on CertificateRequests
+	// denied by approver-policy. Its value is a JSON list of machine readable
+	// reasons for why the request was denied, in the form
+	// `[{"policy": "<name>", "field": "spec.allowed.dnsNames.values", "code": "DNSNameNotAllowed", "detail": "<detail>"}]`.
+	// The code is stable, so may be used for alerting, and is omitted for
+	// denials by evaluators which don't give codes.
+	DenialReasonsAnnotationKey = "policy.cert-manager.io/denial-reasons"
+
+	// ApprovedByAnnotationKey is the annotation set on CertificateRequests
+	// approved by approver-policy. Its value is the name of the
+	// CertificateRequestPolicy which approved the request.
+	ApprovedByAnnotationKey = "policy.cert-manager.io/approved-by"
+
+	// EvaluatedPoliciesAnnotationKey is the annotation set on
+	// CertificateRequests approved or denied by approver-policy. Its value is
+	// a comma separated list of the names of the CertificateRequestPolicies
+	// which were considered for the request, in the order they were
+	// considered.
+	EvaluatedPoliciesAnnotationKey = "policy.cert-manager.io/evaluated-policies"
+
+	// EvaluationRetriesAnnotationKey is the annotation set on
+	// CertificateRequests which failed to be evaluated by approver-policy
+	// because of a transient error. Its value is the number of times
+	// evaluation of the request has been retried. Requests which still fail
+	// to be evaluated after 10 retries are denied.
+	EvaluationRetriesAnnotationKey = "policy.cert-manager.io/evaluation-retries"
+
+	// AllowSelectorChangeAnnotationKey is the annotation which, when present
+	// on an updated CertificateRequestPolicy, permits its selector to be
+	// narrowed. Without it, updates which may select fewer CertificateRequests
+	// than before are denied.
+	AllowSelectorChangeAnnotationKey = "policy.cert-manager.io/allow-selector-change"
+
+	// ForceDeleteAnnotationKey is the annotation which, when present on a
+	// CertificateRequestPolicy, permits it to be deleted while it selects
+	// pending CertificateRequests. Without it, such deletions are denied.
+	ForceDeleteAnnotationKey = "policy.cert-manager.io/force-delete"
+
+	// RevalidateAnnotationKey is the annotation which, when present on a
+	// CertificateRequestPolicy, triggers the re-evaluation of the
+	// CertificateRequests which have been approved but not yet issued, and
+	// which the policy selects or was evaluated for, if approver-policy is run
+	// with `--revalidate-approved-requests`. An approval may not be revoked,
+	// so requests which no longer pass policy are instead annotated with
+	// RevalidationFailedAnnotationKey. The annotation is removed once the
+	// re-evaluation has completed.
+	RevalidateAnnotationKey = "policy.cert-manager.io/revalidate"
+
+	// RevalidationFailedAnnotationKey is the annotation set on approved
+	// CertificateRequests which no longer pass policy when re-evaluated. Its
+	// value is why the request no longer passes policy. The annotation is
+	// removed if the request passes policy when next re-evaluated.
+	RevalidationFailedAnnotationKey = "policy.cert-manager.io/revalidation-failed"
+
+	// BackdateAnnotationKey is the annotation on CertificateRequests whose
+	// value is the duration the notBefore of the requested certificate is
+	// backdated by. It is constrained by `constraints.maxBackdate`.
+	BackdateAnnotationKey = "cert-manager.io/backdate"
+
+	// IssuerMaxDurationAnnotationKey is the annotation on cert-manager.io
+	// Issuers and ClusterIssuers whose value is the maximum duration which may
+	// be requested from them, e.g. `720h`. It is enforced on every evaluated
+	// request in addition to, and independently of, `constraints.maxDuration`.
+	// Requests which don't request a duration are compared using
+	// cert-manager's default duration of 90 days.
+	IssuerMaxDurationAnnotationKey = "policy.cert-manager.io/max-duration"
+)
+
+// CertificateRequestPolicyConditionType represents a CertificateRequestPolicy
+// condition value.
+type CertificateRequestPolicyConditionType string
+
+const (
+	// CertificateRequestPolicyConditionReady indicates that the
+	// CertificateRequestPolicy has successfully loaded the policy, and all
+	// configuration including plugin options are accepted and ready for
+	// evaluating CertificateRequests.
+	// +k8s:deepcopy-gen=false
+	CertificateRequestPolicyConditionReady CertificateRequestPolicyConditionType = "Ready"
+)
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approver
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// DenialCode is a stable, machine readable code for why a request was denied,
+// for example to alert on particular denials. Unlike the messages of denials,
+// codes never change once released.
+type DenialCode string
+
+// DenialCoder may optionally be implemented by an Evaluator to tag the field
+// errors of its denials with a DenialCode. Since the manager can't tell which
+// Evaluator returned a field error, DenialCode is called with the field
+// errors of all Evaluators, and should return an empty code for field errors
+// it doesn't recognise.
+type DenialCoder interface {
+	// DenialCode returns the code of the given field error of a denial, or
+	// an empty code if it is unknown to this Evaluator.
+	DenialCode(*field.Error) DenialCode
+}
+
+// Denial codes of the built-in allowed and constraints evaluators.
+const (
+	// DenialCodeRequestInvalid is the code for requests whose CSR can't be
+	// decoded.
+	DenialCodeRequestInvalid DenialCode = "RequestInvalid"
+
+	// DenialCodeRequestInconsistent is the code for requests whose spec
+	// doesn't agree with their CSR.
+	DenialCodeRequestInconsistent DenialCode = "RequestInconsistent"
+
+	DenialCodeCommonNameNotAllowed   DenialCode = "CommonNameNotAllowed"
+	DenialCodeCommonNameRequired     DenialCode = "CommonNameRequired"
+	DenialCodeDNSNameNotAllowed      DenialCode = "DNSNameNotAllowed"
+	DenialCodeDNSNameRequired        DenialCode = "DNSNameRequired"
+	DenialCodeDNSNameInvalid         DenialCode = "DNSNameInvalid"
+	DenialCodeIPAddressNotAllowed    DenialCode = "IPAddressNotAllowed"
+	DenialCodeIPAddressRequired      DenialCode = "IPAddressRequired"
+	DenialCodeURINotAllowed          DenialCode = "URINotAllowed"
+	DenialCodeURIRequired            DenialCode = "URIRequired"
+	DenialCodeEmailAddressNotAllowed DenialCode = "EmailAddressNotAllowed"
+	DenialCodeEmailAddressRequired   DenialCode = "EmailAddressRequired"
+	DenialCodeOtherNameNotAllowed    DenialCode = "OtherNameNotAllowed"
+	DenialCodeIsCANotAllowed         DenialCode = "IsCANotAllowed"
+	DenialCodeUsageNotAllowed        DenialCode = "UsageNotAllowed"
+	DenialCodeAnnotationNotAllowed   DenialCode = "AnnotationNotAllowed"
+	DenialCodeAnnotationRequired     DenialCode = "AnnotationRequired"
+	DenialCodeSubjectNotAllowed      DenialCode = "SubjectNotAllowed"
+	DenialCodeSubjectRequired        DenialCode = "SubjectRequired"
+
+	DenialCodeDurationTooLong              DenialCode = "DurationTooLong"
+	DenialCodeDurationTooShort             DenialCode = "DurationTooShort"
+	DenialCodeDurationNotAllowed           DenialCode = "DurationNotAllowed"
+	DenialCodeExpiryNotAligned             DenialCode = "ExpiryNotAligned"
+	DenialCodeBackdateTooLong              DenialCode = "BackdateTooLong"
+	DenialCodeNamespaceNotAllowed          DenialCode = "NamespaceNotAllowed"
+	DenialCodeKeyAlgorithmNotAllowed       DenialCode = "KeyAlgorithmNotAllowed"
+	DenialCodeKeyTooWeak                   DenialCode = "KeyTooWeak"
+	DenialCodeKeyTooLarge                  DenialCode = "KeyTooLarge"
+	DenialCodeKeyParameterNotAllowed       DenialCode = "KeyParameterNotAllowed"
+	DenialCodeKeyBlocked                   DenialCode = "KeyBlocked"
+	DenialCodeTooManySANs                  DenialCode = "TooManySANs"
+	DenialCodeDuplicateSAN                 DenialCode = "DuplicateSAN"
+	DenialCodeWildcardDNSName              DenialCode = "WildcardDNSName"
+	DenialCodeSANRequired                  DenialCode = "SANRequired"
+	DenialCodeTooManyDNSNameLabels         DenialCode = "TooManyDNSNameLabels"
+	DenialCodeTooManySubjectEntries        DenialCode = "TooManySubjectEntries"
+	DenialCodeCommonNameTooLong            DenialCode = "CommonNameTooLong"
+	DenialCodeSubjectTooLong               DenialCode = "SubjectTooLong"
+	DenialCodeSignatureAlgorithmNotAllowed DenialCode = "SignatureAlgorithmNotAllowed"
+	DenialCodeSANTypeNotAllowed            DenialCode = "SANTypeNotAllowed"
+	DenialCodeExtensionNotAllowed          DenialCode = "ExtensionNotAllowed"
+	DenialCodePathLenTooLong               DenialCode = "PathLenTooLong"
+	DenialCodeCAUsageRequired              DenialCode = "CAUsageRequired"
+)
@@ -23,8 +23,10 @@ package approver
 
 import (
 	"context"
+	"errors"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 )
@@ -51,6 +53,11 @@ type EvaluationResponse struct {
 	// Message is optional context as to why the evaluator has given the result
 	// it has.
 	Message string
+
+	// Errors are the optional field errors which caused the evaluator to give
+	// the result it has. Errors are used to produce machine readable reasons
+	// for why a request was denied, and should aggregate to Message.
+	Errors field.ErrorList
 }
 
 // Evaluator is responsible for making decisions on whether a
@@ -68,5 +75,70 @@ type Evaluator interface {
 	// An error should only be returned if there was an error in the evaluator
 	// attempting to evaluate the request over the policy itself. A policy
 	// manager may re-evaluate an evaluation if an error is returned.
+	// Temporary failures, for example an external service being unavailable,
+	// should be returned as a TransientError.
 	Evaluate(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (EvaluationResponse, error)
 }
+
+// TransientError is an error returned by an Evaluator when it failed to
+// evaluate a request because of a temporary failure, for example an external
+// service being unavailable. Requests which fail evaluation with a
+// TransientError are re-evaluated with backoff, and are never denied because
+// of the failure.
+type TransientError struct {
+	// Err is the underlying cause of the failure.
+	Err error
+}
+
+// NewTransientError returns a TransientError wrapping the given error.
+func NewTransientError(err error) error {
+	return &TransientError{Err: err}
+}
+
+// Error implements error.
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause of the failure.
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransientError returns whether the given error, or any error it wraps, is
+// a TransientError.
+func IsTransientError(err error) bool {
+	var transient *TransientError
+	return errors.As(err, &transient)
+}
+
+// Enricher may optionally be implemented by an Evaluator to contribute
+// additional allowed values to a CertificateRequestPolicy at evaluation time,
+// for example DNS names computed from an external IPAM. Enrich is called once
+// for each policy evaluated against a request, before any Evaluator is run.
+// The returned values are merged into `spec.allowed` of the policy given to
+// all Evaluators.
+type Enricher interface {
+	// Enrich returns the additional values that the given policy should allow
+	// for the given request. Enrich is always given the policy as stored in
+	// the cluster, never a policy enriched by another Enricher.
+	Enrich(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (EnrichResponse, error)
+}
+
+// EnrichResponse is the response to an enrichment request. Values are merged
+// into the `values` of the corresponding `spec.allowed` field, and may contain
+// the same wildcards as the policy.
+type EnrichResponse struct {
+	// DNSNames are additional allowed `spec.allowed.dnsNames` values.
+	DNSNames []string
+
+	// IPAddresses are additional allowed `spec.allowed.ipAddresses` values.
+	IPAddresses []string
+
+	// URIs are additional allowed `spec.allowed.uris` values.
+	URIs []string
+
+	// EmailAddresses are additional allowed `spec.allowed.emailAddresses`
+	// values.
+	EmailAddresses []string
+}
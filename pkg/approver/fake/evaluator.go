@@ -20,17 +20,22 @@ import (
 	"context"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
 )
 
 var _ approver.Evaluator = &FakeEvaluator{}
+var _ approver.Enricher = &FakeEvaluator{}
+var _ approver.DenialCoder = &FakeEvaluator{}
 
 // FakeEvaluator is a testing evaluator designed to mock evaluators with a
 // pre-determined response.
 type FakeEvaluator struct {
 	evaluateFunc func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EvaluationResponse, error)
+	enrichFunc   func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EnrichResponse, error)
+	codeFunc     func(*field.Error) approver.DenialCode
 }
 
 func NewFakeEvaluator() *FakeEvaluator {
@@ -45,3 +50,29 @@ func (f *FakeEvaluator) WithEvaluate(fn func(context.Context, *policyapi.Certifi
 func (f *FakeEvaluator) Evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
 	return f.evaluateFunc(ctx, policy, cr)
 }
+
+func (f *FakeEvaluator) WithEnrich(fn func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EnrichResponse, error)) *FakeEvaluator {
+	f.enrichFunc = fn
+	return f
+}
+
+// Enrich returns an empty response if no enrich function has been set.
+func (f *FakeEvaluator) Enrich(ctx context.Context, policy *policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (approver.EnrichResponse, error) {
+	if f.enrichFunc == nil {
+		return approver.EnrichResponse{}, nil
+	}
+	return f.enrichFunc(ctx, policy, cr)
+}
+
+func (f *FakeEvaluator) WithDenialCode(fn func(*field.Error) approver.DenialCode) *FakeEvaluator {
+	f.codeFunc = fn
+	return f
+}
+
+// DenialCode returns an empty code if no denial code function has been set.
+func (f *FakeEvaluator) DenialCode(err *field.Error) approver.DenialCode {
+	if f.codeFunc == nil {
+		return ""
+	}
+	return f.codeFunc(err)
+}
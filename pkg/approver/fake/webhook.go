@@ -24,11 +24,13 @@ import (
 )
 
 var _ approver.Webhook = &FakeWebhook{}
+var _ approver.ReadyChecker = &FakeWebhook{}
 
 // FakeWebhook is a testing webook designed to mock webhooks with a
 // pre-determined response.
 type FakeWebhook struct {
-	validateFunc func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error)
+	validateFunc   func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error)
+	checkReadyFunc func(context.Context) error
 }
 
 func NewFakeWebhook() *FakeWebhook {
@@ -40,6 +42,18 @@ func (f *FakeWebhook) WithValidate(fn func(context.Context, *policyapi.Certifica
 	return f
 }
 
+func (f *FakeWebhook) WithCheckReady(fn func(context.Context) error) *FakeWebhook {
+	f.checkReadyFunc = fn
+	return f
+}
+
 func (f *FakeWebhook) Validate(ctx context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
 	return f.validateFunc(ctx, policy)
 }
+
+func (f *FakeWebhook) CheckReady(ctx context.Context) error {
+	if f.checkReadyFunc == nil {
+		return nil
+	}
+	return f.checkReadyFunc(ctx)
+}
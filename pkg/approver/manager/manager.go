@@ -20,6 +20,8 @@ import (
 	"context"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	"github.com/cert-manager/approver-policy/pkg/approver"
 )
 
 // ReviewResult is the result from an approver manager reviewing a
@@ -49,6 +51,46 @@ type ReviewResponse struct {
 	// Message is optional context as to why the manager has given the result it
 	// has.
 	Message string
+
+	// Reasons are the machine readable reasons for why the request was denied,
+	// sorted by policy name. Only populated if the result is ResultDenied.
+	Reasons []DenialReason
+
+	// ApprovedBy is the name of the CertificateRequestPolicy which approved the
+	// request. Only populated if the result is ResultApproved.
+	ApprovedBy string
+
+	// Warnings are why CertificateRequestPolicies with the Warn enforcement
+	// would have denied the request, sorted by policy name. Only populated if
+	// the result is ResultApproved because of those policies.
+	Warnings []string
+
+	// EvaluatedPolicies are the names of the CertificateRequestPolicies which
+	// were considered for the request, in the order they were considered.
+	// Policies ordered after the approving policy are not considered. Only
+	// populated if the result is ResultApproved or ResultDenied.
+	EvaluatedPolicies []string
+}
+
+// DenialReason is a machine readable reason for why a CertificateRequest was
+// denied by a CertificateRequestPolicy.
+type DenialReason struct {
+	// Policy is the name of the CertificateRequestPolicy which denied the
+	// request.
+	Policy string `json:"policy"`
+
+	// Field is the path of the policy field which caused the denial, e.g.
+	// `spec.allowed.dnsNames.values`. Empty if the evaluator which denied the
+	// request didn't give a field.
+	Field string `json:"field,omitempty"`
+
+	// Code is the stable, machine readable code of the denial, e.g.
+	// `DNSNameNotAllowed`. Empty if the evaluator which denied the request
+	// doesn't give codes.
+	Code approver.DenialCode `json:"code,omitempty"`
+
+	// Detail is the human readable detail of why the field caused the denial.
+	Detail string `json:"detail"`
 }
 
 // Interface is an Approver Manager that responsible for evaluating whether
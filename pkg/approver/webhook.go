@@ -32,6 +32,11 @@ type WebhookValidationResponse struct {
 
 	// Errors are errors in response to the validation request being not Allowed.
 	Errors field.ErrorList
+
+	// Warnings are returned to the client regardless of whether the request
+	// was Allowed, and highlight configuration which has no effect or may
+	// not behave as expected.
+	Warnings []string
 }
 
 // Webhook is responsible for making decisions about whether a
@@ -45,3 +50,57 @@ type Webhook interface {
 	// immediately, and no other webhooks will be run.
 	Validate(context.Context, *policyapi.CertificateRequestPolicy) (WebhookValidationResponse, error)
 }
+
+// ReadyChecker may optionally be implemented by a Webhook to expose whether it
+// is healthy. If any registered Webhook reports that it is not ready, the
+// approver-policy readiness probe will fail. Webhooks which depend on
+// external services should implement ReadyChecker.
+// The method is not named Ready to avoid conflicting with Reconciler.
+type ReadyChecker interface {
+	// CheckReady returns an error if the Webhook is not ready. CheckReady
+	// should return promptly, and respect the cancellation of the given
+	// context.
+	CheckReady(context.Context) error
+}
+
+// ValuesSchemaProvider may optionally be implemented by the Webhook of a plugin
+// Approver to declare the values it accepts in `spec.plugins.<name>.values`.
+// The values of CertificateRequestPolicies which use the plugin are validated
+// against the schema at admission time, so that misconfigured policies are
+// rejected before they are evaluated against a request.
+type ValuesSchemaProvider interface {
+	// ValuesSchema returns the schema of the plugin's values.
+	ValuesSchema() ValuesSchema
+}
+
+// ValuesSchema is the schema of the values of a plugin, keyed by value key.
+// Keys which are not in the schema are not validated.
+type ValuesSchema map[string]ValueSchema
+
+// ValueSchema is the schema of a single value of a plugin.
+type ValueSchema struct {
+	// Type is the type which the value must be parsable as. Empty is
+	// equivalent to ValueTypeString.
+	Type ValueType
+
+	// Required defines whether the value must be supplied.
+	Required bool
+}
+
+// ValueType is the type of a plugin value.
+type ValueType string
+
+const (
+	// ValueTypeString accepts any value.
+	ValueTypeString ValueType = "String"
+
+	// ValueTypeInteger accepts base 10 integers, e.g. "10".
+	ValueTypeInteger ValueType = "Integer"
+
+	// ValueTypeBoolean accepts booleans parsable by strconv.ParseBool, e.g.
+	// "true".
+	ValueTypeBoolean ValueType = "Boolean"
+
+	// ValueTypeDuration accepts Go durations, e.g. "1h30m".
+	ValueTypeDuration ValueType = "Duration"
+)
@@ -24,6 +24,9 @@ import (
 
 	_ "github.com/cert-manager/approver-policy/pkg/internal/approver/allowed"
 	_ "github.com/cert-manager/approver-policy/pkg/internal/approver/constraints"
+	_ "github.com/cert-manager/approver-policy/pkg/internal/approver/grpcplugin"
+	_ "github.com/cert-manager/approver-policy/pkg/internal/approver/opa"
+	_ "github.com/cert-manager/approver-policy/pkg/internal/approver/validations"
 	"github.com/cert-manager/approver-policy/pkg/internal/cmd"
 )
 
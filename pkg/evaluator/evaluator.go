@@ -0,0 +1,174 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package evaluator evaluates CertificateRequests against a single
+// CertificateRequestPolicy, without running the approver-policy controller.
+// It is the same evaluation used by the approver-policy controller and
+// webhook, so may be embedded in other tooling to make the same decisions.
+//
+// Evaluation only considers the policy as given. Policy selection, priority,
+// RBAC, and the resolution of `spec.baseRef` are the responsibility of the
+// caller.
+package evaluator
+
+import (
+	"context"
+	"strings"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/allowed"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/constraints"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// Decision is the outcome of evaluating a CertificateRequest against a
+// CertificateRequestPolicy.
+type Decision string
+
+const (
+	// DecisionApproved is the decision when no evaluator denied the request.
+	DecisionApproved Decision = "Approved"
+
+	// DecisionDenied is the decision when at least one evaluator denied the
+	// request.
+	DecisionDenied Decision = "Denied"
+)
+
+// Response is the aggregated response of all evaluators which evaluated a
+// CertificateRequest against a CertificateRequestPolicy.
+type Response struct {
+	// Decision is the outcome of the evaluation.
+	Decision Decision
+
+	// Message is the messages of all evaluators, joined by ", ".
+	Message string
+
+	// Errors are the field errors of all evaluators which denied the request.
+	Errors field.ErrorList
+
+	// Denials are the responses of the evaluators which denied the request,
+	// in evaluator order.
+	Denials []approver.EvaluationResponse
+}
+
+// Evaluator evaluates CertificateRequests against a CertificateRequestPolicy
+// using a fixed list of evaluators.
+type Evaluator struct {
+	evaluators []approver.Evaluator
+}
+
+// New returns an Evaluator which runs the given evaluators, in order.
+func New(evaluators ...approver.Evaluator) *Evaluator {
+	return &Evaluator{evaluators: evaluators}
+}
+
+// Default returns an Evaluator which runs the built-in allowed and
+// constraints evaluators. These evaluators are not prepared, so policies
+// using `valuesFrom` fail to evaluate since their ConfigMaps can't be loaded,
+// and the maximum duration annotation of issuers is not enforced.
+func Default() *Evaluator {
+	return New(allowed.Approver(), constraints.Approver())
+}
+
+// Evaluate evaluates the request against the policy using the default
+// evaluators. Returns the decision, along with the field errors which caused
+// the request to be denied.
+// An error is only returned if the request could not be evaluated, for
+// example if a ConfigMap referenced by `valuesFrom` can't be loaded. Requests
+// which don't contain a valid CSR are denied.
+func Evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (Decision, field.ErrorList, error) {
+	return Default().Evaluate(ctx, policy, request)
+}
+
+// Evaluate evaluates the request against the policy. Returns the decision,
+// along with the field errors which caused the request to be denied.
+func (e *Evaluator) Evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (Decision, field.ErrorList, error) {
+	response, err := e.Review(ctx, policy, request)
+	if err != nil {
+		return "", nil, err
+	}
+	return response.Decision, response.Errors, nil
+}
+
+// Review evaluates the request against the policy, returning the aggregated
+// response of all evaluators. All evaluators are run, even once one has
+// denied the request, so that the response captures every reason for the
+// denial. If any evaluator errors, the error is returned immediately.
+func (e *Evaluator) Review(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (Response, error) {
+	var (
+		response = Response{Decision: DecisionApproved}
+		messages []string
+	)
+
+	for _, evaluator := range e.evaluators {
+		if !pluginSelects(policy, evaluator, request) {
+			continue
+		}
+
+		evaluation, err := evaluator.Evaluate(ctx, policy, request)
+		if err != nil {
+			return Response{}, err
+		}
+
+		if len(evaluation.Message) > 0 {
+			messages = append(messages, evaluation.Message)
+		}
+
+		if evaluation.Result == approver.ResultDenied {
+			response.Decision = DecisionDenied
+			response.Errors = append(response.Errors, evaluation.Errors...)
+			response.Denials = append(response.Denials, evaluation)
+		}
+	}
+
+	response.Message = strings.Join(messages, ", ")
+
+	return response, nil
+}
+
+// pluginSelects returns whether the evaluator should evaluate the request for
+// the given policy. Evaluators which are plugins of the policy only evaluate
+// the requests selected by the plugin's selector. All other evaluators
+// evaluate every request.
+func pluginSelects(policy *policyapi.CertificateRequestPolicy, evaluator approver.Evaluator, request *cmapi.CertificateRequest) bool {
+	named, ok := evaluator.(interface{ Name() string })
+	if !ok {
+		return true
+	}
+	plugin, ok := policy.Spec.Plugins[named.Name()]
+	if !ok || plugin.Selector == nil || plugin.Selector.IssuerRef == nil {
+		return true
+	}
+
+	var (
+		issRefSel = plugin.Selector.IssuerRef
+		issRef    = request.Spec.IssuerRef
+	)
+	if issRefSel.Name != nil && !util.WildcardMatches(*issRefSel.Name, issRef.Name) {
+		return false
+	}
+	if issRefSel.Kind != nil && !util.WildcardMatches(*issRefSel.Kind, issRef.Kind) {
+		return false
+	}
+	if issRefSel.Group != nil && !util.WildcardMatches(*issRefSel.Group, issRef.Group) {
+		return false
+	}
+	return true
+}
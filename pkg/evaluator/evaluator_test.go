@@ -0,0 +1,219 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+)
+
+func Test_Review(t *testing.T) {
+	evaluatorWithResponse := func(response approver.EvaluationResponse, err error) approver.Evaluator {
+		return fake.NewFakeEvaluator().WithEvaluate(func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+			return response, err
+		})
+	}
+
+	var (
+		notDenied = approver.EvaluationResponse{Result: approver.ResultNotDenied, Message: "a"}
+		denied1   = approver.EvaluationResponse{Result: approver.ResultDenied, Message: "b", Errors: field.ErrorList{field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), "foo", "bar")}}
+		denied2   = approver.EvaluationResponse{Result: approver.ResultDenied, Message: "c"}
+	)
+
+	tests := map[string]struct {
+		evaluators []approver.Evaluator
+
+		expResponse Response
+		expErr      bool
+	}{
+		"if no evaluators are given, return approved": {
+			evaluators:  nil,
+			expResponse: Response{Decision: DecisionApproved},
+		},
+		"if no evaluator denies, return approved with messages": {
+			evaluators:  []approver.Evaluator{evaluatorWithResponse(notDenied, nil), evaluatorWithResponse(approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil)},
+			expResponse: Response{Decision: DecisionApproved, Message: "a"},
+		},
+		"if some evaluators deny, return denied with the messages of all evaluators and errors of those which denied": {
+			evaluators: []approver.Evaluator{evaluatorWithResponse(denied1, nil), evaluatorWithResponse(notDenied, nil), evaluatorWithResponse(denied2, nil)},
+			expResponse: Response{
+				Decision: DecisionDenied,
+				Message:  "b, a, c",
+				Errors:   denied1.Errors,
+				Denials:  []approver.EvaluationResponse{denied1, denied2},
+			},
+		},
+		"if an evaluator errors, return error": {
+			evaluators: []approver.Evaluator{evaluatorWithResponse(denied1, nil), evaluatorWithResponse(approver.EvaluationResponse{}, errors.New("error"))},
+			expErr:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, err := New(test.evaluators...).Review(context.TODO(), new(policyapi.CertificateRequestPolicy), new(cmapi.CertificateRequest))
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
+
+func Test_Review_pluginSelector(t *testing.T) {
+	// plugin is an evaluator registered as the plugin "hsm", which always
+	// denies.
+	plugin := namedEvaluator{
+		name: "hsm",
+		Evaluator: fake.NewFakeEvaluator().WithEvaluate(func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+			return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied by hsm"}, nil
+		}),
+	}
+
+	policyWithPlugins := func(plugins map[string]policyapi.CertificateRequestPolicyPluginData) *policyapi.CertificateRequestPolicy {
+		return &policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{Plugins: plugins}}
+	}
+	hsmIssuers := &policyapi.CertificateRequestPolicyPluginSelector{
+		IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("hsm-*"), Kind: pointer.String("ClusterIssuer")},
+	}
+	denied := Response{
+		Decision: DecisionDenied,
+		Message:  "denied by hsm",
+		Denials:  []approver.EvaluationResponse{{Result: approver.ResultDenied, Message: "denied by hsm"}},
+	}
+
+	tests := map[string]struct {
+		policy  *policyapi.CertificateRequestPolicy
+		request *cmapi.CertificateRequest
+
+		expResponse Response
+	}{
+		"if the plugin has no selector, it should evaluate the request": {
+			policy:      policyWithPlugins(map[string]policyapi.CertificateRequestPolicyPluginData{"hsm": {}}),
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca", Kind: "Issuer"})),
+			expResponse: denied,
+		},
+		"if the plugin selector matches the issuer of the request, it should evaluate the request": {
+			policy:      policyWithPlugins(map[string]policyapi.CertificateRequestPolicyPluginData{"hsm": {Selector: hsmIssuers}}),
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "hsm-prod", Kind: "ClusterIssuer"})),
+			expResponse: denied,
+		},
+		"if the plugin selector doesn't match the issuer name of the request, it should be skipped": {
+			policy:      policyWithPlugins(map[string]policyapi.CertificateRequestPolicyPluginData{"hsm": {Selector: hsmIssuers}}),
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca", Kind: "ClusterIssuer"})),
+			expResponse: Response{Decision: DecisionApproved},
+		},
+		"if the plugin selector doesn't match the issuer kind of the request, it should be skipped": {
+			policy:      policyWithPlugins(map[string]policyapi.CertificateRequestPolicyPluginData{"hsm": {Selector: hsmIssuers}}),
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "hsm-prod", Kind: "Issuer"})),
+			expResponse: Response{Decision: DecisionApproved},
+		},
+		"if the selector is of another plugin, it should evaluate the request": {
+			policy:      policyWithPlugins(map[string]policyapi.CertificateRequestPolicyPluginData{"hsm": {}, "other": {Selector: hsmIssuers}}),
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca", Kind: "Issuer"})),
+			expResponse: denied,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, err := New(plugin).Review(context.TODO(), test.policy, test.request)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
+
+func Test_Evaluate(t *testing.T) {
+	policy := &policyapi.CertificateRequestPolicy{
+		Spec: policyapi.CertificateRequestPolicySpec{
+			Allowed: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+			},
+			Constraints: &policyapi.CertificateRequestPolicyConstraints{
+				MaxSANCount: pointer.Int(1),
+			},
+		},
+	}
+
+	csrWithDNSNames := func(t *testing.T, dnsNames ...string) []byte {
+		csr, _, err := gen.CSR(x509.ECDSA, gen.SetCSRDNSNames(dnsNames...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return csr
+	}
+
+	tests := map[string]struct {
+		request *cmapi.CertificateRequest
+
+		expDecision Decision
+		expErrors   field.ErrorList
+		expErr      bool
+	}{
+		"if request satisfies allowed and constraints, return approved": {
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrWithDNSNames(t, "foo.example.com"))),
+			expDecision: DecisionApproved,
+		},
+		"if request violates allowed and constraints, return denied with errors of both": {
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrWithDNSNames(t, "foo.example.com", "foo.example.net"))),
+			expDecision: DecisionDenied,
+			expErrors: field.ErrorList{
+				field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.example.com", "foo.example.net"}, "*.example.com"),
+				field.Invalid(field.NewPath("spec.constraints.maxSANCount"), "2", "1"),
+			},
+		},
+		"if request doesn't contain a valid CSR, return Denied": {
+			request:     gen.CertificateRequest("", gen.SetCertificateRequestCSR([]byte("not a csr"))),
+			expDecision: DecisionDenied,
+			expErrors: field.ErrorList{
+				field.Forbidden(field.NewPath("spec.request"), "error decoding certificate request PEM block"),
+				field.Forbidden(field.NewPath("spec.request"), "error decoding certificate request PEM block"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			decision, errs, err := Evaluate(context.TODO(), policy, test.request)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expDecision, decision)
+			assert.Equal(t, test.expErrors, errs)
+		})
+	}
+}
+
+// namedEvaluator is an Evaluator with a name, as with registered approvers.
+type namedEvaluator struct {
+	approver.Evaluator
+	name string
+}
+
+func (n namedEvaluator) Name() string {
+	return n.name
+}
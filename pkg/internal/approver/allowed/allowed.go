@@ -21,6 +21,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
@@ -30,12 +31,12 @@ import (
 
 // Load the allowed approver.
 func init() {
-	registry.Shared.Store(allowed{})
+	registry.Shared.Store(&allowed{})
 }
 
 // Approver returns an instance on the allowed approver.
 func Approver() approver.Interface {
-	return allowed{}
+	return &allowed{}
 }
 
 // allowed is a base approver-policy Approver that is responsible for ensuring
@@ -44,30 +45,41 @@ func Approver() approver.Interface {
 // attributes which they are allowed to in the policy are permitted. It is
 // expected that allowed must _always_ be registered for all
 // approver-policy builds.
-type allowed struct{}
+type allowed struct {
+	// lister is used to load values referenced by `valuesFrom`. Set once the
+	// approver is prepared.
+	lister client.Reader
+
+	// templates caches the allowed values rendered from templates for each
+	// namespace.
+	templates templateCache
+}
 
 // Name of Approver is "allowed"
-func (a allowed) Name() string {
+func (a *allowed) Name() string {
 	return "allowed"
 }
 
 // RegisterFlags is a no-op, allowed doesn't need any flags.
-func (a allowed) RegisterFlags(_ *pflag.FlagSet) {
+func (a *allowed) RegisterFlags(_ *pflag.FlagSet) {
 	return
 }
 
-// Prepare is a no-op, allowed doesn't need to prepare anything.
-func (a allowed) Prepare(_ context.Context, _ logr.Logger, _ manager.Manager) error {
+// Prepare sets the lister which loads values referenced by `valuesFrom` from
+// the manager's cache, so that ConfigMaps are watched and changes take effect
+// without restarting.
+func (a *allowed) Prepare(_ context.Context, _ logr.Logger, mgr manager.Manager) error {
+	a.lister = mgr.GetCache()
 	return nil
 }
 
 // Ready always returns ready, allowed doesn't have any dependencies to
 // block readiness.
-func (a allowed) Ready(_ context.Context, _ *policyapi.CertificateRequestPolicy) (approver.ReconcilerReadyResponse, error) {
+func (a *allowed) Ready(_ context.Context, _ *policyapi.CertificateRequestPolicy) (approver.ReconcilerReadyResponse, error) {
 	return approver.ReconcilerReadyResponse{Ready: true}, nil
 }
 
 // allowed never needs to manually enqueue policies.
-func (a allowed) EnqueueChan() <-chan string {
+func (a *allowed) EnqueueChan() <-chan string {
 	return nil
 }
@@ -18,25 +18,36 @@ package allowed
 
 import (
 	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
 	"strconv"
 	"strings"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
-	utilpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+	"golang.org/x/net/idna"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
 	"github.com/cert-manager/approver-policy/pkg/internal/util"
+	"github.com/cert-manager/approver-policy/pkg/matcher"
 )
 
+// defaultUsages are the key usages cert-manager applies to CertificateRequests
+// which don't request any, and must be kept in sync with
+// cmapi.DefaultKeyUsages. Requests which don't request any key usages are
+// evaluated as requesting these if the policy sets `includeDefaultUsages`.
+var defaultUsages = []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment}
+
 // Evaluate evaluates whether the given CertificateRequest conforms to the
 // allowed attributes defined in the policy. The request _must_ conform to
 // _all_ allowed attributes in the policy to be permitted by the passed policy.
 // If the request is denied by the allowed attributes an explanation is
 // returned.
 // An error signals that the policy couldn't be evaluated to completion.
-func (a allowed) Evaluate(_ context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+func (a *allowed) Evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
 	var (
 		// el will contain a list of policy violations for fields, if there are
 		// items in the list, then the request does not meet the allowed
@@ -50,29 +61,89 @@ func (a allowed) Evaluate(_ context.Context, policy *policyapi.CertificateReques
 		allowed = new(policyapi.CertificateRequestPolicyAllowed)
 	}
 
-	csr, err := utilpki.DecodeX509CertificateRequestBytes(request.Spec.Request)
+	// A request which can't be decoded is denied rather than erroring, since
+	// re-evaluating it will never succeed.
+	csr, err := util.DecodeCSR(request.Spec.Request)
+	if err != nil {
+		el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+	}
+
+	// DNS names may be allowed by values loaded from a ConfigMap.
+	dnsNames, err := a.values(ctx, allowed.DNSNames)
 	if err != nil {
 		return approver.EvaluationResponse{}, err
 	}
 
+	// DNS name and URI values may be templates rendered for the request's
+	// namespace.
+	dnsNames, err = a.renderValues(ctx, request.Namespace, dnsNames)
+	if err != nil {
+		return approver.EvaluationResponse{}, err
+	}
+	// DNS names may be matched in their ASCII form, in which case both the
+	// requested DNS names and the values are converted.
+	requestDNSNames := csr.DNSNames
+	normalizeDNSNames := allowed.DNSNames != nil && allowed.DNSNames.NormalizeIDNA != nil && *allowed.DNSNames.NormalizeIDNA
+	if normalizeDNSNames {
+		if dnsNames != nil {
+			values, errs := normalizeIDNA(fldPath.Child("dnsNames", "normalizeIDNA"), *dnsNames)
+			dnsNames = &values
+			el = append(el, errs...)
+		}
+		var errs field.ErrorList
+		requestDNSNames, errs = normalizeIDNA(fldPath.Child("dnsNames", "normalizeIDNA"), csr.DNSNames)
+		el = append(el, errs...)
+	}
+
+	var uriValues *[]string
+	if allowed.URIs != nil {
+		if uriValues, err = a.renderValues(ctx, request.Namespace, allowed.URIs.Values); err != nil {
+			return approver.EvaluationResponse{}, err
+		}
+	}
+
 	if len(csr.Subject.CommonName) > 0 {
-		if allowed.CommonName == nil || allowed.CommonName.Value == nil {
+		switch {
+		case allowed.IPAddresses != nil && allowed.IPAddresses.IncludeCommonName != nil && *allowed.IPAddresses.IncludeCommonName && net.ParseIP(csr.Subject.CommonName) != nil:
+			// If includeCommonName is enabled and the common name is an IP
+			// address, it must instead be permissible as an IP address.
+			if allowed.IPAddresses.Values == nil {
+				el = append(el, field.Invalid(fldPath.Child("ipAddresses", "values"), csr.Subject.CommonName, "nil"))
+			} else if !util.NegatedSubset(*allowed.IPAddresses.Values, []string{csr.Subject.CommonName}, util.IPMatches) {
+				el = append(el, field.Invalid(fldPath.Child("ipAddresses", "values"), csr.Subject.CommonName, strings.Join(*allowed.IPAddresses.Values, ", ")))
+			}
+		case allowed.CommonName != nil && allowed.CommonName.Value == nil && allowed.CommonName.MatchDNSNames != nil && *allowed.CommonName.MatchDNSNames:
+			// If no value is defined but matchDNSNames is enabled, the common name
+			// must instead be permissible as a DNS name.
+			commonName := []string{csr.Subject.CommonName}
+			if normalizeDNSNames {
+				var errs field.ErrorList
+				commonName, errs = normalizeIDNA(fldPath.Child("dnsNames", "normalizeIDNA"), commonName)
+				el = append(el, errs...)
+			}
+			if dnsNames == nil {
+				el = append(el, field.Invalid(fldPath.Child("dnsNames", "values"), csr.Subject.CommonName, "nil"))
+			} else if !util.NegatedSubset(*dnsNames, commonName, matchFunc(allowed.DNSNames.Matcher, allowed.DNSNames.MatchType, util.PatternMatches)) {
+				el = append(el, field.Invalid(fldPath.Child("dnsNames", "values"), csr.Subject.CommonName, valuesDetail(allowed.DNSNames, *dnsNames)))
+			}
+		case allowed.CommonName == nil || allowed.CommonName.Value == nil:
 			el = append(el, field.Invalid(fldPath.Child("commonName", "value"), csr.Subject.CommonName, "nil"))
-		} else if !util.WildcardMatches(*allowed.CommonName.Value, csr.Subject.CommonName) {
+		case !commonNameMatches(allowed.CommonName, csr.Subject.CommonName):
 			el = append(el, field.Invalid(fldPath.Child("commonName", "value"), csr.Subject.CommonName, *allowed.CommonName.Value))
 		}
-	} else if allowed.CommonName != nil && allowed.CommonName.Required != nil && *allowed.CommonName.Required {
-		el = append(el, field.Required(fldPath.Child("commonName", "required"), strconv.FormatBool(*allowed.CommonName.Required)))
+	} else if allowed.CommonName != nil && isRequired(allowed.CommonName.Required) {
+		el = append(el, requiredError(fldPath.Child("commonName", "required")))
 	}
 
 	if len(csr.DNSNames) > 0 {
-		if allowed.DNSNames == nil || allowed.DNSNames.Values == nil {
+		if dnsNames == nil {
 			el = append(el, field.Invalid(fldPath.Child("dnsNames", "values"), csr.DNSNames, "nil"))
-		} else if !util.WildcardSubset(*allowed.DNSNames.Values, csr.DNSNames) {
-			el = append(el, field.Invalid(fldPath.Child("dnsNames", "values"), csr.DNSNames, strings.Join(*allowed.DNSNames.Values, ", ")))
+		} else if !util.NegatedSubset(*dnsNames, requestDNSNames, matchFunc(allowed.DNSNames.Matcher, allowed.DNSNames.MatchType, util.PatternMatches)) {
+			el = append(el, field.Invalid(fldPath.Child("dnsNames", "values"), csr.DNSNames, valuesDetail(allowed.DNSNames, *dnsNames)))
 		}
-	} else if allowed.DNSNames != nil && allowed.DNSNames.Required != nil && *allowed.DNSNames.Required {
-		el = append(el, field.Required(fldPath.Child("dnsNames", "required"), strconv.FormatBool(*allowed.DNSNames.Required)))
+	} else if allowed.DNSNames != nil && isRequired(allowed.DNSNames.Required) {
+		el = append(el, requiredError(fldPath.Child("dnsNames", "required")))
 	}
 
 	if len(csr.IPAddresses) > 0 {
@@ -82,11 +153,11 @@ func (a allowed) Evaluate(_ context.Context, policy *policyapi.CertificateReques
 		}
 		if allowed.IPAddresses == nil || allowed.IPAddresses.Values == nil {
 			el = append(el, field.Invalid(fldPath.Child("ipAddresses", "values"), ips, "nil"))
-		} else if !util.WildcardSubset(*allowed.IPAddresses.Values, ips) {
+		} else if !util.NegatedSubset(*allowed.IPAddresses.Values, ips, util.IPMatches) {
 			el = append(el, field.Invalid(fldPath.Child("ipAddresses", "values"), ips, strings.Join(*allowed.IPAddresses.Values, ", ")))
 		}
-	} else if allowed.IPAddresses != nil && allowed.IPAddresses.Required != nil && *allowed.IPAddresses.Required {
-		el = append(el, field.Required(fldPath.Child("ipAddresses", "required"), strconv.FormatBool(*allowed.IPAddresses.Required)))
+	} else if allowed.IPAddresses != nil && isRequired(allowed.IPAddresses.Required) {
+		el = append(el, requiredError(fldPath.Child("ipAddresses", "required")))
 	}
 
 	if len(csr.URIs) > 0 {
@@ -94,36 +165,75 @@ func (a allowed) Evaluate(_ context.Context, policy *policyapi.CertificateReques
 		for _, uri := range csr.URIs {
 			uris = append(uris, uri.String())
 		}
-		if allowed.URIs == nil || allowed.URIs.Values == nil {
+		if uriValues == nil {
 			el = append(el, field.Invalid(fldPath.Child("uris", "values"), uris, "nil"))
-		} else if !util.WildcardSubset(*allowed.URIs.Values, uris) {
-			el = append(el, field.Invalid(fldPath.Child("uris", "values"), uris, strings.Join(*allowed.URIs.Values, ", ")))
+		} else if !util.NegatedSubset(*uriValues, uris, matchFunc(allowed.URIs.Matcher, allowed.URIs.MatchType, util.URIMatches)) {
+			el = append(el, field.Invalid(fldPath.Child("uris", "values"), uris, strings.Join(*uriValues, ", ")))
 		}
-	} else if allowed.URIs != nil && allowed.URIs.Required != nil && *allowed.URIs.Required {
-		el = append(el, field.Required(fldPath.Child("uris", "required"), strconv.FormatBool(*allowed.URIs.Required)))
+	} else if allowed.URIs != nil && isRequired(allowed.URIs.Required) {
+		el = append(el, requiredError(fldPath.Child("uris", "required")))
 	}
 
 	if len(csr.EmailAddresses) > 0 {
-		if allowed.EmailAddresses == nil || allowed.EmailAddresses.Values == nil {
+		if allowed.EmailAddresses == nil || (allowed.EmailAddresses.Values == nil && allowed.EmailAddresses.AllowedDomains == nil) {
 			el = append(el, field.Invalid(fldPath.Child("emailAddresses", "values"), csr.EmailAddresses, "nil"))
-		} else if !util.WildcardSubset(*allowed.EmailAddresses.Values, csr.EmailAddresses) {
-			el = append(el, field.Invalid(fldPath.Child("emailAddresses", "values"), csr.EmailAddresses, strings.Join(*allowed.EmailAddresses.Values, ", ")))
+		} else if !emailAddressesAllowed(allowed.EmailAddresses, csr.EmailAddresses) {
+			if allowed.EmailAddresses.Values == nil {
+				el = append(el, field.Invalid(fldPath.Child("emailAddresses", "allowedDomains"), csr.EmailAddresses, strings.Join(allowed.EmailAddresses.AllowedDomains, ", ")))
+			} else {
+				el = append(el, field.Invalid(fldPath.Child("emailAddresses", "values"), csr.EmailAddresses, strings.Join(*allowed.EmailAddresses.Values, ", ")))
+			}
 		}
-	} else if allowed.EmailAddresses != nil && allowed.EmailAddresses.Required != nil && *allowed.EmailAddresses.Required {
-		el = append(el, field.Required(fldPath.Child("emailAddresses", "required"), strconv.FormatBool(*allowed.EmailAddresses.Required)))
+	} else if allowed.EmailAddresses != nil && isRequired(allowed.EmailAddresses.Required) {
+		el = append(el, requiredError(fldPath.Child("emailAddresses", "required")))
+	}
+
+	// otherName SANs aren't decoded by the standard library, so are decoded
+	// from the extension of the CSR. As with the CSR itself, a request whose
+	// otherNames can't be decoded is denied.
+	if otherNames, err := util.DecodeOtherNames(csr); err != nil {
+		el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+	} else {
+		el = append(el, otherNamesAllowed(fldPath.Child("otherNames"), allowed.OtherNames, otherNames)...)
 	}
 
-	if request.Spec.IsCA {
+	// The usages and CA of the request are taken from its CSR if the policy
+	// evaluates the CSR directly.
+	isCA, usages := request.Spec.IsCA, request.Spec.Usages
+	if policy.Spec.EvaluateCSRDirectly {
+		var mismatches field.ErrorList
+		isCA, usages, mismatches = csrUsages(csr, request)
+		el = append(el, mismatches...)
+	}
+	if len(usages) == 0 && allowed.IncludeDefaultUsages != nil && *allowed.IncludeDefaultUsages {
+		usages = defaultUsages
+	}
+
+	if isCA {
 		if allowed.IsCA == nil {
-			el = append(el, field.Invalid(fldPath.Child("isCA"), request.Spec.IsCA, "nil"))
+			el = append(el, field.Invalid(fldPath.Child("isCA"), isCA, "nil"))
 		} else if !*allowed.IsCA {
-			el = append(el, field.Invalid(fldPath.Child("isCA"), request.Spec.IsCA, strconv.FormatBool(*allowed.IsCA)))
+			el = append(el, field.Invalid(fldPath.Child("isCA"), isCA, strconv.FormatBool(*allowed.IsCA)))
 		}
 	}
 
-	if len(request.Spec.Usages) > 0 {
+	if allowed.ExactUsages != nil && *allowed.ExactUsages {
+		var requestUsages []string
+		for _, usage := range usages {
+			requestUsages = append(requestUsages, string(usage))
+		}
+		var policyUsages []string
+		if allowed.Usages != nil {
+			for _, usage := range *allowed.Usages {
+				policyUsages = append(policyUsages, string(usage))
+			}
+		}
+		if !sets.New(requestUsages...).Equal(sets.New(policyUsages...)) {
+			el = append(el, field.Invalid(fldPath.Child("usages"), requestUsages, "exactly: "+strings.Join(policyUsages, ", ")))
+		}
+	} else if len(usages) > 0 {
 		var requestUsages []string
-		for _, usage := range request.Spec.Usages {
+		for _, usage := range usages {
 			requestUsages = append(requestUsages, string(usage))
 		}
 		if allowed.Usages == nil {
@@ -139,94 +249,349 @@ func (a allowed) Evaluate(_ context.Context, policy *policyapi.CertificateReques
 		}
 	}
 
+	if len(allowed.Annotations) > 0 {
+		fldPath := fldPath.Child("annotations")
+		for _, key := range sets.List(sets.KeySet(allowed.Annotations)) {
+			allowedAnn := allowed.Annotations[key]
+			if value, ok := request.Annotations[key]; ok {
+				if allowedAnn.Value == nil {
+					el = append(el, field.Invalid(fldPath.Key(key).Child("value"), value, "nil"))
+				} else if !util.WildcardMatches(*allowedAnn.Value, value) {
+					el = append(el, field.Invalid(fldPath.Key(key).Child("value"), value, *allowedAnn.Value))
+				}
+			} else if isRequired(allowedAnn.Required) {
+				el = append(el, requiredError(fldPath.Key(key).Child("required")))
+			}
+		}
+	}
+
 	fldPath = fldPath.Child("subject")
 	allowedSub := allowed.Subject
+	if allowedSub == nil {
+		allowedSub = new(policyapi.CertificateRequestPolicyAllowedX509Subject)
+	}
+
+	el = append(el, subjectSliceAllowed(fldPath.Child("organizations"), allowedSub.Organizations, csr.Subject.Organization)...)
+	el = append(el, subjectSliceAllowed(fldPath.Child("countries"), allowedSub.Countries, csr.Subject.Country)...)
+	el = append(el, subjectSliceAllowed(fldPath.Child("organizationalUnits"), allowedSub.OrganizationalUnits, csr.Subject.OrganizationalUnit)...)
+	el = append(el, subjectSliceAllowed(fldPath.Child("localities"), allowedSub.Localities, csr.Subject.Locality)...)
+	el = append(el, subjectSliceAllowed(fldPath.Child("provinces"), allowedSub.Provinces, csr.Subject.Province)...)
+	el = append(el, subjectSliceAllowed(fldPath.Child("streetAddresses"), allowedSub.StreetAddresses, csr.Subject.StreetAddress)...)
+	el = append(el, subjectSliceAllowed(fldPath.Child("postalCodes"), allowedSub.PostalCodes, csr.Subject.PostalCode)...)
+
+	if len(csr.Subject.SerialNumber) > 0 {
+		if allowedSub.SerialNumber == nil || allowedSub.SerialNumber.Value == nil {
+			el = append(el, field.Invalid(fldPath.Child("serialNumber", "value"), csr.Subject.SerialNumber, "nil"))
+		} else if !matchFunc(allowedSub.SerialNumber.Matcher, allowedSub.SerialNumber.MatchType, util.WildcardMatches)(*allowedSub.SerialNumber.Value, csr.Subject.SerialNumber) {
+			el = append(el, field.Invalid(fldPath.Child("serialNumber", "value"), csr.Subject.SerialNumber, *allowedSub.SerialNumber.Value))
+		}
+	} else if allowedSub.SerialNumber != nil && isRequired(allowedSub.SerialNumber.Required) {
+		el = append(el, requiredError(fldPath.Child("serialNumber", "required")))
+	}
+
+	// If there are errors, then return not approved and the aggregated errors
+	if len(el) > 0 {
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+	}
+
+	// If no evaluation errors resulting from this policy, return not denied
+	return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+}
 
-	if len(csr.Subject.Organization) > 0 {
-		if allowedSub == nil || allowedSub.Organizations == nil || allowedSub.Organizations.Values == nil {
-			el = append(el, field.Invalid(fldPath.Child("organizations", "values"), csr.Subject.Organization, "nil"))
-		} else if !util.WildcardSubset(*allowedSub.Organizations.Values, csr.Subject.Organization) {
-			el = append(el, field.Invalid(fldPath.Child("organizations", "values"), csr.Subject.Organization, strings.Join(*allowedSub.Organizations.Values, ", ")))
+// subjectSliceAllowed returns errors if the requested values of a subject
+// field are not permitted by the allowed field, or if the field is required
+// but was not requested. Values are matched by position if the allowed field
+// is ordered.
+func subjectSliceAllowed(fldPath *field.Path, allowed *policyapi.CertificateRequestPolicyAllowedStringSlice, values []string) field.ErrorList {
+	var el field.ErrorList
+	if len(values) > 0 {
+		if allowed == nil || allowed.Values == nil {
+			el = append(el, field.Invalid(fldPath.Child("values"), values, "nil"))
+		} else if isOrdered(allowed.Ordered) {
+			if !orderedMatches(*allowed.Values, values) {
+				el = append(el, field.Invalid(fldPath.Child("values"), values, "in order: "+strings.Join(*allowed.Values, ", ")))
+			}
+		} else if !util.WildcardSubset(*allowed.Values, values) {
+			el = append(el, field.Invalid(fldPath.Child("values"), values, strings.Join(*allowed.Values, ", ")))
 		}
-	} else if allowedSub != nil && allowedSub.Organizations != nil && allowedSub.Organizations.Required != nil && *allowedSub.Organizations.Required {
-		el = append(el, field.Required(fldPath.Child("organizations", "required"), strconv.FormatBool(*allowedSub.Organizations.Required)))
+	} else if allowed != nil && isRequired(allowed.Required) {
+		el = append(el, requiredError(fldPath.Child("required")))
 	}
+	return append(el, entriesAllowed(fldPath, allowed, values)...)
+}
 
-	if len(csr.Subject.Country) > 0 {
-		if allowedSub == nil || allowedSub.Countries == nil {
-			el = append(el, field.Invalid(fldPath.Child("countries", "values"), csr.Subject.Country, "nil"))
-		} else if !util.WildcardSubset(*allowedSub.Countries.Values, csr.Subject.Country) {
-			el = append(el, field.Invalid(fldPath.Child("countries", "values"), csr.Subject.Country, strings.Join(*allowedSub.Countries.Values, ", ")))
+// isOrdered returns whether the ordered option of an allowed field is set to
+// true.
+func isOrdered(ordered *bool) bool {
+	return ordered != nil && *ordered
+}
+
+// orderedMatches returns whether each value matches the wildcard pattern at
+// the same position. There must not be more values than patterns.
+func orderedMatches(patterns, values []string) bool {
+	if len(values) > len(patterns) {
+		return false
+	}
+	for i, value := range values {
+		if !util.WildcardMatches(patterns[i], value) {
+			return false
 		}
-	} else if allowedSub != nil && allowedSub.Countries != nil && allowedSub.Countries.Required != nil && *allowedSub.Countries.Required {
-		el = append(el, field.Required(fldPath.Child("countries", "required"), strconv.FormatBool(*allowedSub.Countries.Required)))
 	}
+	return true
+}
 
-	if len(csr.Subject.OrganizationalUnit) > 0 {
-		if allowedSub == nil || allowedSub.OrganizationalUnits == nil {
-			el = append(el, field.Invalid(fldPath.Child("organizationalUnits", "values"), csr.Subject.OrganizationalUnit, "nil"))
-		} else if !util.WildcardSubset(*allowedSub.OrganizationalUnits.Values, csr.Subject.OrganizationalUnit) {
-			el = append(el, field.Invalid(fldPath.Child("organizationalUnits", "values"), csr.Subject.OrganizationalUnit, strings.Join(*allowedSub.OrganizationalUnits.Values, ", ")))
+// otherNamesAllowed returns errors if the values of the requested otherName
+// SANs of each OID are not permitted by the allowed entry of that OID.
+func otherNamesAllowed(fldPath *field.Path, allowed []policyapi.CertificateRequestPolicyAllowedOtherName, otherNames []util.OtherName) field.ErrorList {
+	var oids []string
+	values := make(map[string][]string)
+	for _, otherName := range otherNames {
+		if _, ok := values[otherName.OID]; !ok {
+			oids = append(oids, otherName.OID)
 		}
-	} else if allowedSub != nil && allowedSub.OrganizationalUnits != nil && allowedSub.OrganizationalUnits.Required != nil && *allowedSub.OrganizationalUnits.Required {
-		el = append(el, field.Required(fldPath.Child("organizationalUnits", "required"), strconv.FormatBool(*allowedSub.OrganizationalUnits.Required)))
+		values[otherName.OID] = append(values[otherName.OID], otherName.Value)
 	}
 
-	if len(csr.Subject.Locality) > 0 {
-		if allowedSub == nil || allowedSub.Localities == nil {
-			el = append(el, field.Invalid(fldPath.Child("localities", "values"), csr.Subject.Locality, "nil"))
-		} else if !util.WildcardSubset(*allowedSub.Localities.Values, csr.Subject.Locality) {
-			el = append(el, field.Invalid(fldPath.Child("localities", "values"), csr.Subject.Locality, strings.Join(*allowedSub.Localities.Values, ", ")))
+	var el field.ErrorList
+	for _, oid := range oids {
+		i := -1
+		for j := range allowed {
+			if allowed[j].OID == oid {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			el = append(el, field.Invalid(fldPath, values[oid], fmt.Sprintf("nil for OID %s", oid)))
+		} else if !util.WildcardSubset(allowed[i].Values, values[oid]) {
+			el = append(el, field.Invalid(fldPath.Index(i).Child("values"), values[oid], strings.Join(allowed[i].Values, ", ")))
 		}
-	} else if allowedSub != nil && allowedSub.Localities != nil && allowedSub.Localities.Required != nil && *allowedSub.Localities.Required {
-		el = append(el, field.Required(fldPath.Child("localities", "required"), strconv.FormatBool(*allowedSub.Localities.Required)))
+	}
+	return el
+}
+
+// isRequired returns whether the required option of an allowed field is set
+// to true.
+func isRequired(required *bool) bool {
+	return required != nil && *required
+}
+
+// requiredError returns the error of a request which omits a field that the
+// policy marks as required.
+func requiredError(fldPath *field.Path) *field.Error {
+	return field.Required(fldPath, "true")
+}
+
+// entriesAllowed returns errors if the number of requested values is outside
+// the minEntries and maxEntries of the allowed field.
+func entriesAllowed(fldPath *field.Path, allowed *policyapi.CertificateRequestPolicyAllowedStringSlice, values []string) field.ErrorList {
+	if allowed == nil {
+		return nil
+	}
+
+	var el field.ErrorList
+	if allowed.MinEntries != nil && len(values) < *allowed.MinEntries {
+		el = append(el, field.Invalid(fldPath.Child("minEntries"), strconv.Itoa(len(values)), strconv.Itoa(*allowed.MinEntries)))
+	}
+	if allowed.MaxEntries != nil && len(values) > *allowed.MaxEntries {
+		el = append(el, field.Invalid(fldPath.Child("maxEntries"), strconv.Itoa(len(values)), strconv.Itoa(*allowed.MaxEntries)))
+	}
+	return el
+}
+
+// csrUsages returns whether the CSR of the request requests a CA, along with
+// the usages it requests. Returns errors for fields of the request which don't
+// agree with its CSR, since issuers may honour either: spec.isCA must match
+// the CSR, and a populated spec.usages must contain every usage of the CSR,
+// other than "cert sign" which cert-manager adds to the CSRs of CAs. The
+// extensions of the CSR are supplied by the requester, so extensions which
+// can't be decoded are returned as errors of the request.
+func csrUsages(csr *x509.CertificateRequest, request *cmapi.CertificateRequest) (bool, []cmapi.KeyUsage, field.ErrorList) {
+	var (
+		el      field.ErrorList
+		fldPath = field.NewPath("spec", "evaluateCSRDirectly")
+	)
+
+	isCA, _, _, err := util.DecodeBasicConstraints(csr)
+	if err != nil {
+		el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+	} else if isCA != request.Spec.IsCA {
+		el = append(el, field.Forbidden(fldPath, fmt.Sprintf("request spec.isCA is %t, but the CSR requests isCA %t", request.Spec.IsCA, isCA)))
 	}
 
-	if len(csr.Subject.Province) > 0 {
-		if allowedSub == nil || allowedSub.Provinces == nil {
-			el = append(el, field.Invalid(fldPath.Child("provinces", "values"), csr.Subject.Province, "nil"))
-		} else if !util.WildcardSubset(*allowedSub.Provinces.Values, csr.Subject.Province) {
-			el = append(el, field.Invalid(fldPath.Child("provinces", "values"), csr.Subject.Province, strings.Join(*allowedSub.Provinces.Values, ", ")))
+	usages, err := util.DecodeUsages(csr)
+	if err != nil {
+		return isCA, nil, append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+	}
+	if len(request.Spec.Usages) > 0 {
+		specUsages := sets.New(request.Spec.Usages...)
+		var missing []string
+		for _, usage := range usages {
+			if !specUsages.Has(usage) && !(isCA && usage == cmapi.UsageCertSign) {
+				missing = append(missing, string(usage))
+			}
+		}
+		if len(missing) > 0 {
+			el = append(el, field.Forbidden(fldPath, fmt.Sprintf("request spec.usages doesn't contain usages requested by the CSR: %s", strings.Join(missing, ", "))))
 		}
-	} else if allowedSub != nil && allowedSub.Provinces != nil && allowedSub.Provinces.Required != nil && *allowedSub.Provinces.Required {
-		el = append(el, field.Required(fldPath.Child("provinces", "required"), strconv.FormatBool(*allowedSub.Provinces.Required)))
 	}
 
-	if len(csr.Subject.StreetAddress) > 0 {
-		if allowedSub == nil || allowedSub.StreetAddresses == nil {
-			el = append(el, field.Invalid(fldPath.Child("streetAddresses", "values"), csr.Subject.StreetAddress, "nil"))
-		} else if !util.WildcardSubset(*allowedSub.StreetAddresses.Values, csr.Subject.StreetAddress) {
-			el = append(el, field.Invalid(fldPath.Child("streetAddresses", "values"), csr.Subject.StreetAddress, strings.Join(*allowedSub.StreetAddresses.Values, ", ")))
+	return isCA, usages, el
+}
+
+// matchFunc returns the function which matches requested values with allowed
+// values of a field. A named matcher takes precedence over the match type, and
+// is dispatched to the matcher registered in the shared matcher registry. A
+// matcher which isn't registered never matches.
+func matchFunc(name *string, matchType *policyapi.AllowedMatchType, wildcard func(pattern, str string) bool) func(pattern, str string) bool {
+	if name == nil {
+		return util.MatchFunc(matchType, wildcard)
+	}
+	m, ok := matcher.Shared.Get(*name)
+	if !ok {
+		return func(string, string) bool { return false }
+	}
+	return m.Matches
+}
+
+// idnaProfile converts DNS names to their ASCII form for normalizeIDNA. Names
+// are mapped as for lookup, but without the STD3 rules, so that wildcard and
+// underscore labels may be converted.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.StrictDomainName(false), idna.Transitional(false))
+
+// normalizeIDNA returns the DNS names converted to their ASCII form with IDNA,
+// keeping the NegationPrefix of negated values. Values prefixed with
+// util.RegexPrefix are returned unchanged. An error is returned for each name
+// which can't be converted, which is itself returned unchanged.
+func normalizeIDNA(fldPath *field.Path, names []string) ([]string, field.ErrorList) {
+	var el field.ErrorList
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = name
+
+		negated := util.IsNegated(name)
+		name = strings.TrimPrefix(name, util.NegationPrefix)
+		if strings.HasPrefix(name, util.RegexPrefix) {
+			continue
+		}
+
+		ascii, err := idnaProfile.ToASCII(name)
+		if err != nil {
+			el = append(el, field.Invalid(fldPath, names[i], fmt.Sprintf("failed to convert to ASCII with IDNA: %s", err)))
+			continue
+		}
+		if negated {
+			ascii = util.NegationPrefix + ascii
 		}
-	} else if allowedSub != nil && allowedSub.StreetAddresses != nil && allowedSub.StreetAddresses.Required != nil && *allowedSub.StreetAddresses.Required {
-		el = append(el, field.Required(fldPath.Child("streetAddresses", "required"), strconv.FormatBool(*allowedSub.StreetAddresses.Required)))
+		normalized[i] = ascii
 	}
+	return normalized, el
+}
 
-	if len(csr.Subject.PostalCode) > 0 {
-		if allowedSub == nil || allowedSub.PostalCodes == nil {
-			el = append(el, field.Invalid(fldPath.Child("postalCodes", "values"), csr.Subject.PostalCode, "nil"))
-		} else if !util.WildcardSubset(*allowedSub.PostalCodes.Values, csr.Subject.PostalCode) {
-			el = append(el, field.Invalid(fldPath.Child("postalCodes", "values"), csr.Subject.PostalCode, strings.Join(*allowedSub.PostalCodes.Values, ", ")))
+// commonNameMatches returns whether the common name matches the Value of the
+// allowed commonName, with its Matcher or MatchType. If CaseInsensitive is
+// true, a regular expression is matched regardless of case, and otherwise both
+// the Value and common name are lower-cased before matching.
+func commonNameMatches(allowed *policyapi.CertificateRequestPolicyAllowedString, commonName string) bool {
+	pattern := *allowed.Value
+	if allowed.CaseInsensitive != nil && *allowed.CaseInsensitive {
+		if allowed.Matcher == nil && isMatchType(allowed.MatchType, policyapi.AllowedMatchTypeRegex) {
+			pattern = "(?i)" + pattern
+		} else {
+			pattern, commonName = strings.ToLower(pattern), strings.ToLower(commonName)
 		}
-	} else if allowedSub != nil && allowedSub.PostalCodes != nil && allowedSub.PostalCodes.Required != nil && *allowedSub.PostalCodes.Required {
-		el = append(el, field.Required(fldPath.Child("postalCodes", "required"), strconv.FormatBool(*allowedSub.PostalCodes.Required)))
 	}
+	return matchFunc(allowed.Matcher, allowed.MatchType, util.WildcardMatches)(pattern, commonName)
+}
 
-	if len(csr.Subject.SerialNumber) > 0 {
-		if allowedSub == nil || allowedSub.SerialNumber == nil {
-			el = append(el, field.Invalid(fldPath.Child("serialNumber", "value"), csr.Subject.SerialNumber, "nil"))
-		} else if !util.WildcardMatches(*allowedSub.SerialNumber.Value, csr.Subject.SerialNumber) {
-			el = append(el, field.Invalid(fldPath.Child("serialNumber", "value"), csr.Subject.SerialNumber, *allowedSub.SerialNumber.Value))
+// wildcardSubset returns whether the members are a subset of the wildcard
+// patterns. If caseInsensitive is true, both the patterns and members are
+// lower-cased before matching.
+func wildcardSubset(patterns, members []string, caseInsensitive *bool) bool {
+	if caseInsensitive != nil && *caseInsensitive {
+		patterns, members = toLower(patterns), toLower(members)
+	}
+	return util.WildcardSubset(patterns, members)
+}
+
+// emailAddressesAllowed returns whether every email address is permitted by
+// either the values or the allowed domains of the given allowed field.
+func emailAddressesAllowed(allowed *policyapi.CertificateRequestPolicyAllowedStringSlice, emails []string) bool {
+	for _, email := range emails {
+		if allowed.Values != nil && wildcardSubset(*allowed.Values, []string{email}, allowed.CaseInsensitive) {
+			continue
+		}
+		if emailDomainAllowed(allowed.AllowedDomains, email) {
+			continue
 		}
-	} else if allowedSub != nil && allowedSub.SerialNumber != nil && allowedSub.SerialNumber.Required != nil && *allowedSub.SerialNumber.Required {
-		el = append(el, field.Required(fldPath.Child("serialNumber", "required"), strconv.FormatBool(*allowedSub.SerialNumber.Required)))
+		return false
+	}
+	return true
+}
+
+// emailDomainAllowed returns whether the domain following the last "@" of the
+// email address matches any of the domains, regardless of case.
+func emailDomainAllowed(domains []string, email string) bool {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return false
 	}
+	return util.WildcardContains(toLower(domains), strings.ToLower(email[i+1:]))
+}
 
-	// If there are errors, then return not approved and the aggregated errors
-	if len(el) > 0 {
-		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error()}, nil
+// toLower returns a copy of the given slice with all strings lower-cased.
+func toLower(strs []string) []string {
+	lower := make([]string, len(strs))
+	for i, str := range strs {
+		lower[i] = strings.ToLower(str)
 	}
+	return lower
+}
 
-	// If no evaluation errors resulting from this policy, return not denied
-	return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+// denialCodes are the codes of the field errors returned by Evaluate, keyed
+// by their field path without subscripts. Field errors of `spec.allowed.subject`
+// are coded by subjectDenialCode.
+var denialCodes = map[string]approver.DenialCode{
+	"spec.request":                               approver.DenialCodeRequestInvalid,
+	"spec.evaluateCSRDirectly":                   approver.DenialCodeRequestInconsistent,
+	"spec.allowed.commonName.value":              approver.DenialCodeCommonNameNotAllowed,
+	"spec.allowed.commonName.required":           approver.DenialCodeCommonNameRequired,
+	"spec.allowed.dnsNames.values":               approver.DenialCodeDNSNameNotAllowed,
+	"spec.allowed.dnsNames.required":             approver.DenialCodeDNSNameRequired,
+	"spec.allowed.dnsNames.normalizeIDNA":        approver.DenialCodeDNSNameInvalid,
+	"spec.allowed.ipAddresses.values":            approver.DenialCodeIPAddressNotAllowed,
+	"spec.allowed.ipAddresses.required":          approver.DenialCodeIPAddressRequired,
+	"spec.allowed.uris.values":                   approver.DenialCodeURINotAllowed,
+	"spec.allowed.uris.required":                 approver.DenialCodeURIRequired,
+	"spec.allowed.emailAddresses.values":         approver.DenialCodeEmailAddressNotAllowed,
+	"spec.allowed.emailAddresses.allowedDomains": approver.DenialCodeEmailAddressNotAllowed,
+	"spec.allowed.emailAddresses.required":       approver.DenialCodeEmailAddressRequired,
+	"spec.allowed.otherNames":                    approver.DenialCodeOtherNameNotAllowed,
+	"spec.allowed.otherNames.values":             approver.DenialCodeOtherNameNotAllowed,
+	"spec.allowed.isCA":                          approver.DenialCodeIsCANotAllowed,
+	"spec.allowed.usages":                        approver.DenialCodeUsageNotAllowed,
+	"spec.allowed.annotations.value":             approver.DenialCodeAnnotationNotAllowed,
+	"spec.allowed.annotations.required":          approver.DenialCodeAnnotationRequired,
+}
+
+// DenialCode implements approver.DenialCoder, returning the code of a field
+// error returned by Evaluate.
+func (a *allowed) DenialCode(err *field.Error) approver.DenialCode {
+	path := util.FieldPathWithoutSubscripts(err.Field)
+	if code, ok := denialCodes[path]; ok {
+		return code
+	}
+	return subjectDenialCode(path)
+}
+
+// subjectDenialCode returns the code of a field error of an attribute of
+// `spec.allowed.subject`, or an empty code if the path isn't of the subject.
+func subjectDenialCode(path string) approver.DenialCode {
+	if !strings.HasPrefix(path, "spec.allowed.subject.") {
+		return ""
+	}
+	if strings.HasSuffix(path, ".required") {
+		return approver.DenialCodeSubjectRequired
+	}
+	return approver.DenialCodeSubjectNotAllowed
 }
@@ -19,18 +19,29 @@ package allowed
 import (
 	"context"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
 	"net"
 	"net/url"
+	"strings"
 	"testing"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	utilpki "github.com/cert-manager/cert-manager/pkg/util/pki"
 	"github.com/cert-manager/cert-manager/test/unit/gen"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+	"github.com/cert-manager/approver-policy/pkg/matcher"
 )
 
 func Test_Evaluate(t *testing.T) {
@@ -80,7 +91,7 @@ func Test_Evaluate(t *testing.T) {
 			},
 			expResponse: approver.EvaluationResponse{
 				Result: approver.ResultDenied,
-				Message: field.ErrorList{
+				Errors: field.ErrorList{
 					field.Invalid(field.NewPath("spec.allowed.commonName.value"), "hello-world", "nil"),
 					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"example.com", "foo.bar"}, "nil"),
 					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), []string{"1.1.1.1", "2.3.4.5"}, "nil"),
@@ -96,7 +107,7 @@ func Test_Evaluate(t *testing.T) {
 					field.Invalid(field.NewPath("spec.allowed.subject.streetAddresses.values"), []string{"street-1", "street-2"}, "nil"),
 					field.Invalid(field.NewPath("spec.allowed.subject.postalCodes.values"), []string{"post-1", "post-2"}, "nil"),
 					field.Invalid(field.NewPath("spec.allowed.subject.serialNumber.value"), "serial-1", "nil"),
-				}.ToAggregate().Error(),
+				},
 			},
 		},
 		"if all allowed defined, all attributes set in request but are different, return Denied": {
@@ -141,7 +152,7 @@ func Test_Evaluate(t *testing.T) {
 			},
 			expResponse: approver.EvaluationResponse{
 				Result: approver.ResultDenied,
-				Message: field.ErrorList{
+				Errors: field.ErrorList{
 					field.Invalid(field.NewPath("spec.allowed.commonName.value"), "hello-world", "hello-world2"),
 					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"example.com", "foo.bar"}, "example.com2, foo.bar2"),
 					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), []string{"1.1.1.1", "2.3.4.5"}, "1.1.1.12, 2.3.4.52"),
@@ -157,7 +168,7 @@ func Test_Evaluate(t *testing.T) {
 					field.Invalid(field.NewPath("spec.allowed.subject.streetAddresses.values"), []string{"street-1", "street-2"}, "street-3, street-4"),
 					field.Invalid(field.NewPath("spec.allowed.subject.postalCodes.values"), []string{"post-1", "post-2"}, "post-3, post-4"),
 					field.Invalid(field.NewPath("spec.allowed.subject.serialNumber.value"), "serial-1", "serial-2"),
-				}.ToAggregate().Error(),
+				},
 			},
 		},
 		"if all allowed defined, all attributes set in request and match exactly, return Not-Denied": {
@@ -273,7 +284,7 @@ func Test_Evaluate(t *testing.T) {
 			},
 			expResponse: approver.EvaluationResponse{
 				Result: approver.ResultDenied,
-				Message: field.ErrorList{
+				Errors: field.ErrorList{
 					field.Required(field.NewPath("spec.allowed.commonName.required"), "true"),
 					field.Required(field.NewPath("spec.allowed.dnsNames.required"), "true"),
 					field.Required(field.NewPath("spec.allowed.ipAddresses.required"), "true"),
@@ -287,7 +298,7 @@ func Test_Evaluate(t *testing.T) {
 					field.Required(field.NewPath("spec.allowed.subject.streetAddresses.required"), "true"),
 					field.Required(field.NewPath("spec.allowed.subject.postalCodes.required"), "true"),
 					field.Required(field.NewPath("spec.allowed.subject.serialNumber.required"), "true"),
-				}.ToAggregate().Error(),
+				},
 			},
 		},
 		"if all allowed defined as required, all of the attributes are set, return Not-Denied": {
@@ -330,29 +341,1815 @@ func Test_Evaluate(t *testing.T) {
 				Message: "",
 			},
 		},
-	}
-
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			response, err := allowed{}.Evaluate(context.TODO(), &policyapi.CertificateRequestPolicy{Spec: test.policy}, test.request)
-			assert.Equal(t, test.expErr, err != nil, "%v", err)
-			assert.Equal(t, test.expResponse, response, "unexpected evaluation response")
-		})
-	}
-}
-
-func noErrModifier(fn func(*x509.CertificateRequest)) func(*x509.CertificateRequest) error {
-	return func(csr *x509.CertificateRequest) error {
-		fn(csr)
-		return nil
-	}
-}
-
-func csrFrom(t *testing.T, keyAlgorithm x509.PublicKeyAlgorithm, mods ...gen.CSRModifier) []byte {
-	t.Helper()
-	csr, _, err := gen.CSR(keyAlgorithm, mods...)
-	if err != nil {
-		t.Fatal(err)
-	}
-	return csr
+		"if dnsNames allowed contains a regex which matches all requested dnsNames, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("example.com", "api-prod.example.com", "web-prod.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com", `regex:[a-z0-9-]+-prod\.example\.com`}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if dnsNames allowed contains a regex but a requested dnsName matches no literal, wildcard or regex value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("api-prod.example.com", "api-dev.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com", "*.foo.com", `regex:[a-z0-9-]+-prod\.example\.com`}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"api-prod.example.com", "api-dev.example.com"}, `example.com, *.foo.com, regex:[a-z0-9-]+-prod\.example\.com`),
+				},
+			},
+		},
+		"if commonName and emailAddresses are caseInsensitive, requested values differing only in case should return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("Admin.Example.com"),
+				gen.SetCSREmails([]string{"Admin@Example.com", "foo@EXAMPLE.com"}),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("*.example.com"), CaseInsensitive: pointer.Bool(true)},
+					EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"admin@example.com", "*@Example.com"}, CaseInsensitive: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if commonName and emailAddresses are not caseInsensitive, requested values differing only in case should return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("Admin.Example.com"),
+				gen.SetCSREmails([]string{"Admin@Example.com"}),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("*.example.com"), CaseInsensitive: pointer.Bool(false)},
+					EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"admin@example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.commonName.value"), "Admin.Example.com", "*.example.com"),
+					field.Invalid(field.NewPath("spec.allowed.emailAddresses.values"), []string{"Admin@Example.com"}, "admin@example.com"),
+				},
+			},
+		},
+		"if commonName has a Regex matchType, the expression should be anchored so that admin doesn't match superadmin": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("superadmin"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("admin"), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.commonName.value"), "superadmin", "admin"),
+				},
+			},
+		},
+		"if commonName has a Regex matchType and the expression matches the whole common name, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("admin-42"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("(admin|root)-[0-9]+"), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if commonName has a Regex matchType and is caseInsensitive, the expression should match regardless of case": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("Admin-42"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String(`admin-\d+`), MatchType: matchType(policyapi.AllowedMatchTypeRegex), CaseInsensitive: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if emailAddresses allowedDomains match the domains of requested emails, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSREmails([]string{"user@sub.example.com", "Admin@Other.Example.net"}),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{AllowedDomains: []string{"*.example.com", "other.example.net"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if emailAddresses allowedDomains don't match the domains of requested emails, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSREmails([]string{"user@sub.example.com", "user@example.com"}),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{AllowedDomains: []string{"*.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.emailAddresses.allowedDomains"), []string{"user@sub.example.com", "user@example.com"}, "*.example.com"),
+				},
+			},
+		},
+		"if requested emails are permitted by either emailAddresses values or allowedDomains, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSREmails([]string{"user@sub.example.com", "admin@example.org"}),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"admin@example.org"}, AllowedDomains: []string{"*.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if a requested email is permitted by neither emailAddresses values nor allowedDomains, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSREmails([]string{"user@sub.example.com", "user@example.org"}),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"admin@example.org"}, AllowedDomains: []string{"*.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.emailAddresses.values"), []string{"user@sub.example.com", "user@example.org"}, "admin@example.org"),
+				},
+			},
+		},
+		"if dnsNames, ipAddresses and uris allowed contain negated values matching requested values, return Denied even if positive values match": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com", "internal.example.com"),
+				gen.SetCSRIPAddresses(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.254")),
+				gen.SetCSRURIs(uri1),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com", "!internal.example.com"}},
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"!10.0.0.254", "10.0.0.*"}},
+					URIs:        &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/**", "!spiffe://cluster.local/ns/foo/**"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.example.com", "internal.example.com"}, "*.example.com, !internal.example.com"),
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), []string{"10.0.0.1", "10.0.0.254"}, "!10.0.0.254, 10.0.0.*"),
+					field.Invalid(field.NewPath("spec.allowed.uris.values"), []string{"spiffe://cluster.local/ns/foo/sa/bar"}, "spiffe://cluster.local/**, !spiffe://cluster.local/ns/foo/**"),
+				},
+			},
+		},
+		"if dnsNames, ipAddresses and uris allowed contain negated values not matching requested values, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com", "bar.example.com"),
+				gen.SetCSRIPAddresses(net.ParseIP("10.0.0.1")),
+				gen.SetCSRURIs(uri1),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com", "!internal.example.com"}},
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"!10.0.0.254", "10.0.0.*"}},
+					URIs:        &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/**", "!spiffe://cluster.local/ns/bar/**"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if exactUsages is true and request contains a usage not in the allowed usages, return Denied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Usages:      &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment},
+					ExactUsages: pointer.Bool(true),
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.usages"), []string{"digital signature", "key encipherment", "server auth"}, "exactly: digital signature, key encipherment"),
+				},
+			},
+		},
+		"if exactUsages is true and request omits an allowed usage, return Denied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageDigitalSignature),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Usages:      &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment},
+					ExactUsages: pointer.Bool(true),
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.usages"), []string{"digital signature"}, "exactly: digital signature, key encipherment"),
+				},
+			},
+		},
+		"if exactUsages is true and request contains no usages, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Usages:      &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment},
+					ExactUsages: pointer.Bool(true),
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.usages"), []string(nil), "exactly: digital signature, key encipherment"),
+				},
+			},
+		},
+		"if exactUsages is true and request contains exactly the allowed usages, return NotDenied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageKeyEncipherment, cmapi.UsageDigitalSignature),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Usages:      &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment},
+					ExactUsages: pointer.Bool(true),
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied, Message: ""},
+		},
+		"if includeDefaultUsages is true and request contains no usages, evaluate the default usages and return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Usages:               &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment},
+					ExactUsages:          pointer.Bool(true),
+					IncludeDefaultUsages: pointer.Bool(true),
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if includeDefaultUsages is true and request contains no usages but the default usages are not allowed, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Usages:               &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature},
+					IncludeDefaultUsages: pointer.Bool(true),
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.usages"), []string{"digital signature", "key encipherment"}, "digital signature"),
+				},
+			},
+		},
+		"if includeDefaultUsages is false and request contains no usages, return NotDenied when no usages are allowed": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					IncludeDefaultUsages: pointer.Bool(false),
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if includeDefaultUsages is true and request contains usages, evaluate only the requested usages": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageServerAuth),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Usages:               &[]cmapi.KeyUsage{cmapi.UsageServerAuth},
+					IncludeDefaultUsages: pointer.Bool(true),
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if ipAddresses allowed contains a mix of literal IPs and CIDRs which match requested IPv4 and IPv6 addresses, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRIPAddresses(net.ParseIP("10.20.30.40"), net.ParseIP("192.168.0.1"), net.ParseIP("fd12:3456::1")),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8", "192.168.0.1", "fd00::/8"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied, Message: ""},
+		},
+		"if ipAddresses allowed contains CIDRs which don't contain requested IPs, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRIPAddresses(net.ParseIP("10.20.30.40"), net.ParseIP("2001:db8::1")),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8", "fd00::/8"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), []string{"10.20.30.40", "2001:db8::1"}, "10.0.0.0/8, fd00::/8"),
+				},
+			},
+		},
+		"if ipAddresses allowed contains a negated CIDR containing a requested IP, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRIPAddresses(net.ParseIP("10.0.0.1"), net.ParseIP("10.1.0.1")),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8", "!10.1.0.0/16"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), []string{"10.0.0.1", "10.1.0.1"}, "10.0.0.0/8, !10.1.0.0/16"),
+				},
+			},
+		}, "if annotations allowed has a required annotation which is missing from the request, return Denied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestAnnotations(map[string]string{"foo": "bar"}),
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+						"example.com/ticket-id": {Value: pointer.String("TICKET-*"), Required: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.allowed.annotations").Key("example.com/ticket-id").Child("required"), "true"),
+				},
+			},
+		},
+		"if annotations allowed doesn't match the request annotation values, return Denied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestAnnotations(map[string]string{"example.com/ticket-id": "OTHER-123", "example.com/forbidden": "true"}),
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+						"example.com/ticket-id": {Value: pointer.String("TICKET-*"), Required: pointer.Bool(true)},
+						"example.com/forbidden": {},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.annotations").Key("example.com/forbidden").Child("value"), "true", "nil"),
+					field.Invalid(field.NewPath("spec.allowed.annotations").Key("example.com/ticket-id").Child("value"), "OTHER-123", "TICKET-*"),
+				},
+			},
+		},
+		"if annotations allowed matches the request annotations, return NotDenied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestAnnotations(map[string]string{"example.com/ticket-id": "TICKET-123", "foo": "bar"}),
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+						"example.com/ticket-id": {Value: pointer.String("TICKET-*"), Required: pointer.Bool(true)},
+						"example.com/optional":  {Value: pointer.String("*")},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied, Message: ""},
+		}, "if uris allowed use a single segment wildcard and requested SPIFFE ID has more segments, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRURIs(spiffeURI(t, "spiffe://cluster.local/ns/foo/sa/bar")),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					URIs: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/*"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.uris.values"), []string{"spiffe://cluster.local/ns/foo/sa/bar"}, "spiffe://cluster.local/*"),
+				},
+			},
+		},
+		"if uris allowed match requested SPIFFE IDs per segment, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRURIs(spiffeURI(t, "spiffe://cluster.local/ns/foo/sa/bar"), spiffeURI(t, "spiffe://example.org/workload/a/b")),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					URIs: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/*/sa/*", "spiffe://example.org/**"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if uris allowed use a segment wildcard and requested SPIFFE ID has an empty trailing segment, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRURIs(spiffeURI(t, "spiffe://cluster.local/ns/foo/sa/")),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					URIs: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/*/sa/*"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.uris.values"), []string{"spiffe://cluster.local/ns/foo/sa/"}, "spiffe://cluster.local/ns/*/sa/*"),
+				},
+			},
+		},
+		"if dnsNames normalizeIDNA is true, and a punycode DNS name matches a Unicode value, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("xn--bcher-kva.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"bücher.example.com"}, NormalizeIDNA: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if dnsNames normalizeIDNA is true, and a punycode DNS name matches a Unicode wildcard value, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("www.xn--bcher-kva.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.Bücher.example.com"}, NormalizeIDNA: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if dnsNames normalizeIDNA is true, and a punycode DNS name matches a negated Unicode value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("xn--bcher-kva.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com", "!bücher.example.com"}, NormalizeIDNA: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"xn--bcher-kva.example.com"}, "*.example.com, !xn--bcher-kva.example.com"),
+				},
+			},
+		},
+		"if dnsNames normalizeIDNA is true, and a Unicode commonName matches a punycode value with matchDNSNames, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("bücher.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(true)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"xn--bcher-kva.example.com"}, NormalizeIDNA: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if dnsNames normalizeIDNA is not set, and a punycode DNS name is requested for a Unicode value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("xn--bcher-kva.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"bücher.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"xn--bcher-kva.example.com"}, "bücher.example.com"),
+				},
+			},
+		},
+		"if dnsNames normalizeIDNA is true, and a DNS name is invalid punycode, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("xn--zz.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}, NormalizeIDNA: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.normalizeIDNA"), "xn--zz.example.com", `failed to convert to ASCII with IDNA: idna: invalid label "zz"`),
+				},
+			},
+		},
+		"if ipAddresses includeCommonName is true, and an IP commonName is within an ipAddresses CIDR, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("10.0.0.5"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8"}, IncludeCommonName: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if ipAddresses includeCommonName is true, and an IP commonName is outside the ipAddresses CIDRs, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("10.0.0.5"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName:  &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("*")},
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"192.168.0.0/16"}, IncludeCommonName: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), "10.0.0.5", "192.168.0.0/16"),
+				},
+			},
+		},
+		"if ipAddresses includeCommonName is true, and a non-IP commonName matches commonName, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName:  &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("example.com")},
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8"}, IncludeCommonName: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if ipAddresses includeCommonName is not set, and an IP commonName is within an ipAddresses CIDR, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("10.0.0.5"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.commonName.value"), "10.0.0.5", "nil"),
+				},
+			},
+		},
+		"if subject countries defines no values, and the request has countries, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Country = []string{"country-1"} }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Countries: &policyapi.CertificateRequestPolicyAllowedStringSlice{MaxEntries: pointer.Int(1)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.countries.values"), []string{"country-1"}, "nil"),
+				},
+			},
+		},
+		"if commonName matchDNSNames is true with no value, and commonName matches a dnsNames value, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(true)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if commonName matchDNSNames is true with no value, and commonName doesn't match a dnsNames value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("foo.example.net"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(true)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), "foo.example.net", "*.example.com"),
+				},
+			},
+		},
+		"if commonName matchDNSNames is true with no value, and commonName matches a negated dnsNames value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("internal.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(true)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com", "!internal.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), "internal.example.com", "*.example.com, !internal.example.com"),
+				},
+			},
+		},
+		"if commonName matchDNSNames is true with no value, and dnsNames is nil, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(true)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), "foo.example.com", "nil"),
+				},
+			},
+		},
+		"if commonName matchDNSNames is true with a value, and commonName only matches a dnsNames value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("bar.example.com"), MatchDNSNames: pointer.Bool(true)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.commonName.value"), "foo.example.com", "bar.example.com"),
+				},
+			},
+		},
+		"if commonName matchDNSNames is false with no value, and commonName matches a dnsNames value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(false)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.commonName.value"), "foo.example.com", "nil"),
+				},
+			},
+		},
+		"if dnsNames and uris matchType is Suffix and Prefix, and requested values match, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com"),
+				gen.SetCSRURIs(uri1),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{".example.com"}, MatchType: matchType(policyapi.AllowedMatchTypeSuffix)},
+					URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/foo/"}, MatchType: matchType(policyapi.AllowedMatchTypePrefix)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if dnsNames and uris matchType is Exact, and requested values only match as wildcards, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com"),
+				gen.SetCSRURIs(uri1),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}, MatchType: matchType(policyapi.AllowedMatchTypeExact)},
+					URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/*/sa/*"}, MatchType: matchType(policyapi.AllowedMatchTypeExact)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.example.com"}, "*.example.com"),
+					field.Invalid(field.NewPath("spec.allowed.uris.values"), []string{"spiffe://cluster.local/ns/foo/sa/bar"}, "spiffe://cluster.local/ns/*/sa/*"),
+				},
+			},
+		},
+		"if dnsNames matchType is Regex, and a requested value matches a negated value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("api.example.com", "internal.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{`[a-z]+\.example\.com`, `!internal\..*`}, MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"api.example.com", "internal.example.com"}, `[a-z]+\.example\.com, !internal\..*`),
+				},
+			},
+		},
+		"if commonName matchDNSNames is true, commonName is matched with the dnsNames matchType": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(true)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, MatchType: matchType(policyapi.AllowedMatchTypeSuffix)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if dnsNames values from a ConfigMap allow the request, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com", "baz.bar.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{ValuesFrom: valuesFrom("dnsNames")},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if dnsNames values and values from a ConfigMap together allow the request, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("example.com", "foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, ValuesFrom: valuesFrom("dnsNames")},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if commonName matches dnsNames values from a ConfigMap, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{MatchDNSNames: pointer.Bool(true)},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{ValuesFrom: valuesFrom("dnsNames")},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if dnsNames values from a ConfigMap don't allow the request, return Denied describing the ConfigMap": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("bar.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, ValuesFrom: valuesFrom("dnsNames")},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"bar.example.com"}, `example.com, values of ConfigMap cert-manager/allowed-dns-names key "dnsNames"`),
+				},
+			},
+		},
+		"if the request is not a valid PEM encoded CSR, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR([]byte("not a csr"))),
+			policy:  policyapi.CertificateRequestPolicySpec{},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.request"), "error decoding certificate request PEM block"),
+				},
+			},
+		},
+		"if the request exceeds the maximum CSR size, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(make([]byte, util.MaxCSRPEMSize+1))),
+			policy:  policyapi.CertificateRequestPolicySpec{},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.request"), "request of 65537 bytes exceeds the maximum size of 65536 bytes"),
+				},
+			},
+		},
+		"if dnsNames allowed is a template of a namespace-scoped wildcard which matches the request, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestNamespace("team-a"), gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.team-a.svc.cluster.local", "bar.payments.example.com"),
+				gen.SetCSRURIs(spiffeURI(t, "spiffe://cluster.local/ns/team-a/sa/foo")),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.{{ .Namespace }}.svc.cluster.local", "*.{{ .Labels.team }}.example.com"}},
+					URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/{{ .Namespace }}/sa/*"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if dnsNames allowed is a template of a namespace-scoped wildcard which doesn't match the request's namespace, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestNamespace("team-a"), gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.team-b.svc.cluster.local"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.{{ .Namespace }}.svc.cluster.local"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.team-b.svc.cluster.local"}, "*.team-a.svc.cluster.local"),
+				},
+			},
+		},
+		"if dnsNames allowed is a template referencing a label the namespace doesn't have, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestNamespace("team-a"), gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.{{ .Labels.owner }}.example.com"}},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.example.com"}, ""),
+				},
+			},
+		},
+		"if dnsNames allowed is a template but the request's namespace doesn't exist, return error": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestNamespace("not-found"), gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.not-found.svc.cluster.local"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.{{ .Namespace }}.svc.cluster.local"}},
+				},
+			},
+			expErr: true,
+		},
+		"if the dnsNames valuesFrom ConfigMap key doesn't exist, return error": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{ValuesFrom: valuesFrom("not-a-key")},
+				},
+			},
+			expErr: true,
+		},
+		"if the dnsNames valuesFrom ConfigMap doesn't exist, return error": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{
+						ValuesFrom: &policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "not-found", Key: "dnsNames"},
+					},
+				},
+			},
+			expErr: true,
+		},
+		"if serialNumber allowed is a wildcard which matches the requested device serialNumber, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "device-1234" }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String(`device-*`)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if serialNumber allowed is a wildcard which doesn't match the requested serialNumber, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "server-1234" }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String(`device-*`)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.serialNumber.value"), "server-1234", `device-*`),
+				},
+			},
+		},
+		"if serialNumber allowed is a regex which matches the requested device serialNumber, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "device-1234" }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String(`device-[0-9]+`), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if serialNumber allowed is a regex which doesn't wholly match the requested serialNumber, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "device-1234x" }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String(`device-[0-9]+`), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.serialNumber.value"), "device-1234x", `device-[0-9]+`),
+				},
+			},
+		},
+		"if serialNumber allowed is a prefix of the requested device serialNumber, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "device-1234" }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String(`device-`), MatchType: matchType(policyapi.AllowedMatchTypePrefix)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultNotDenied,
+				Message: "",
+			},
+		},
+		"if serialNumber allowed is an exact value, wildcards are not expanded, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "device-1234" }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String(`device-*`), MatchType: matchType(policyapi.AllowedMatchTypeExact)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.serialNumber.value"), "device-1234", `device-*`),
+				},
+			},
+		},
+		"if dnsNames and serialNumber use a registered custom matcher, and requested values match, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("FOO.example.com"),
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "DEVICE-1234" }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"foo.example.com"}, Matcher: pointer.String("test-case-insensitive")},
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("device-1234"), Matcher: pointer.String("test-case-insensitive")},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if dnsNames uses a registered custom matcher, and a requested value matches a negated value, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com", "BAR.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"FOO.example.com", "BAR.example.com", "!bar.example.com"}, Matcher: pointer.String("test-case-insensitive")},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.example.com", "BAR.example.com"}, "FOO.example.com, BAR.example.com, !bar.example.com"),
+				},
+			},
+		},
+		"if uris use the built-in wildcard matcher, and requested values match, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRURIs(uri1),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					URIs: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/*"}, Matcher: pointer.String(matcher.WildcardName)},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if dnsNames uses a matcher which isn't registered, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"foo.example.com"}, Matcher: pointer.String("unknown")},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.example.com"}, "foo.example.com"),
+				},
+			},
+		},
+		"if evaluateCSRDirectly, and the CSR requests usages which aren't allowed but spec.usages is empty, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrForCertificate(t,
+				gen.SetCertificateKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageClientAuth),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				EvaluateCSRDirectly: true,
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					Usages:     &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageServerAuth},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.usages"), []string{"digital signature", "client auth"}, "digital signature, server auth"),
+				},
+			},
+		},
+		"if not evaluateCSRDirectly, and the CSR requests usages which aren't allowed but spec.usages is empty, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrForCertificate(t,
+				gen.SetCertificateKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageClientAuth),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					Usages:     &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageServerAuth},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if evaluateCSRDirectly, and the CSR requests a CA but spec.isCA is false, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrForCertificate(t,
+				gen.SetCertificateIsCA(true),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				EvaluateCSRDirectly: true,
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					IsCA:       pointer.Bool(false),
+					Usages:     &[]cmapi.KeyUsage{"*"},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.evaluateCSRDirectly"), "request spec.isCA is false, but the CSR requests isCA true"),
+					field.Invalid(field.NewPath("spec.allowed.isCA"), true, "false"),
+				},
+			},
+		},
+		"if evaluateCSRDirectly, and the CSR's basicConstraints can't be decoded, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("hello-world"),
+				func(csr *x509.CertificateRequest) error {
+					csr.ExtraExtensions = append(csr.ExtraExtensions, pkix.Extension{Id: utilpki.OIDExtensionBasicConstraints, Value: []byte{0xff, 0x01}})
+					return nil
+				},
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				EvaluateCSRDirectly: true,
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					Usages:     &[]cmapi.KeyUsage{"*"},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.request"), "failed to decode basic constraints extension: asn1: syntax error: non-minimal tag"),
+				},
+			},
+		},
+		"if evaluateCSRDirectly, and the CSR requests usages not in spec.usages, return Denied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestCSR(csrForCertificate(t,
+					gen.SetCertificateKeyUsages(cmapi.UsageServerAuth, cmapi.UsageClientAuth),
+				)),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageServerAuth),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				EvaluateCSRDirectly: true,
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					Usages:     &[]cmapi.KeyUsage{cmapi.UsageServerAuth, cmapi.UsageClientAuth},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.evaluateCSRDirectly"), "request spec.usages doesn't contain usages requested by the CSR: client auth"),
+				},
+			},
+		},
+		"if evaluateCSRDirectly, and the fields of a CA request agree with its CSR, return NotDenied": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestCSR(csrForCertificate(t,
+					gen.SetCertificateIsCA(true),
+					gen.SetCertificateKeyUsages(cmapi.UsageDigitalSignature),
+				)),
+				gen.SetCertificateRequestIsCA(true),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageDigitalSignature),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				EvaluateCSRDirectly: true,
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					IsCA:       pointer.Bool(true),
+					Usages:     &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageCertSign},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if evaluateCSRDirectly, the SANs of the CSR should be evaluated": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrForCertificate(t,
+				gen.SetCertificateDNSNames("foo.example.net"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				EvaluateCSRDirectly: true,
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+					Usages:     &[]cmapi.KeyUsage{"*"},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"foo.example.net"}, "*.example.com"),
+				},
+			},
+		},
+		"if the request has more subject entries than maxEntries, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Organization = []string{"company-1", "company-2"} }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"company-*"}, MaxEntries: pointer.Int(1)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.organizations.maxEntries"), "2", "1"),
+				},
+			},
+		},
+		"if the request has fewer subject entries than minEntries, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRCommonName("hello-world"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("hello-world")},
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Countries: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, MinEntries: pointer.Int(1)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.countries.minEntries"), "0", "1"),
+				},
+			},
+		},
+		"if the request has exactly the number of subject entries required by minEntries and maxEntries, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Organization = []string{"company-1"} }),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"company-*"}, MinEntries: pointer.Int(1), MaxEntries: pointer.Int(1)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if organizationalUnits are unordered and the requested OUs match in any order, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				orderedOUs("team-a", "engineering"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "team-*"}},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if organizationalUnits are ordered and the requested OUs match each value by position, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				orderedOUs("engineering", "team-a"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "team-*"}, Ordered: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if organizationalUnits are ordered and the requested OUs match a prefix of the values, return NotDenied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				orderedOUs("engineering"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "team-*"}, Ordered: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if organizationalUnits are ordered and the requested OUs are out of order, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				orderedOUs("team-a", "engineering"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "team-*"}, Ordered: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.organizationalUnits.values"), []string{"team-a", "engineering"}, "in order: engineering, team-*"),
+				},
+			},
+		},
+		"if organizationalUnits are ordered and more OUs are requested than values, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				orderedOUs("engineering", "team-a", "team-b"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "team-*"}, Ordered: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.organizationalUnits.values"), []string{"engineering", "team-a", "team-b"}, "in order: engineering, team-*"),
+				},
+			},
+		},
+		"if otherNames allow the OID and value of a requested UPN otherName, return Not-Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				upnOtherNames(t, "alice@corp.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					OtherNames: []policyapi.CertificateRequestPolicyAllowedOtherName{
+						{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@corp.example.com"}},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if otherNames don't allow the value of a requested UPN otherName, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				upnOtherNames(t, "alice@corp.example.com", "mallory@evil.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					OtherNames: []policyapi.CertificateRequestPolicyAllowedOtherName{
+						{OID: "1.3.6.1.5.5.7.8.9", Values: []string{"*"}},
+						{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@corp.example.com"}},
+					},
+				},
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.otherNames[1].values"), []string{"alice@corp.example.com", "mallory@evil.example.com"}, "*@corp.example.com"),
+				},
+			},
+		},
+		"if otherNames don't allow the OID of a requested UPN otherName, return Denied": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				upnOtherNames(t, "alice@corp.example.com"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: nil,
+			},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.otherNames"), []string{"alice@corp.example.com"}, "nil for OID 1.3.6.1.4.1.311.20.2.3"),
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := &allowed{lister: valuesFromClient(t)}
+			response, err := a.Evaluate(context.TODO(), &policyapi.CertificateRequestPolicy{Spec: test.policy}, test.request)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			// The message of a denied response is the aggregate of its errors.
+			if len(test.expResponse.Errors) > 0 {
+				test.expResponse.Message = test.expResponse.Errors.ToAggregate().Error()
+			}
+			assert.Equal(t, test.expResponse, response, "unexpected evaluation response")
+			for _, err := range response.Errors {
+				assert.NotEmpty(t, a.DenialCode(err), "expected a denial code for %s", err.Field)
+			}
+		})
+	}
+}
+
+// Test_Evaluate_required ensures that every allowed attribute which is marked
+// as required denies requests which omit it, and permits requests which
+// include it.
+func Test_DenialCode(t *testing.T) {
+	tests := map[string]struct {
+		request  *cmapi.CertificateRequest
+		policy   policyapi.CertificateRequestPolicySpec
+		expCodes []approver.DenialCode
+	}{
+		"if the request has a DNS name which isn't allowed, return DNSNameNotAllowed": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+				gen.SetCSRDNSNames("foo.example.net"),
+			))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+				},
+			},
+			expCodes: []approver.DenialCode{approver.DenialCodeDNSNameNotAllowed},
+		},
+		"if the request has no DNS names but they are required, return DNSNameRequired": {
+			request: gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA))),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}, Required: pointer.Bool(true)},
+				},
+			},
+			expCodes: []approver.DenialCode{approver.DenialCodeDNSNameRequired},
+		},
+		"if the request has an organization which isn't allowed and isCA, return SubjectNotAllowed and IsCANotAllowed": {
+			request: gen.CertificateRequest("",
+				gen.SetCertificateRequestIsCA(true),
+				gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA,
+					noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Organization = []string{"bar"} }),
+				)),
+			),
+			policy: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"foo"}},
+					},
+				},
+			},
+			expCodes: []approver.DenialCode{approver.DenialCodeIsCANotAllowed, approver.DenialCodeSubjectNotAllowed},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := &allowed{}
+			response, err := a.Evaluate(context.TODO(), &policyapi.CertificateRequestPolicy{Spec: test.policy}, test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var codes []approver.DenialCode
+			for _, err := range response.Errors {
+				codes = append(codes, a.DenialCode(err))
+			}
+			assert.Equal(t, test.expCodes, codes)
+		})
+	}
+}
+
+func Test_Evaluate_required(t *testing.T) {
+	uri, err := url.Parse("spiffe://cluster.local/ns/foo/sa/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	required := func(values ...string) *policyapi.CertificateRequestPolicyAllowedStringSlice {
+		return &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &values}
+	}
+	requiredString := func(value string) *policyapi.CertificateRequestPolicyAllowedString {
+		return &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String(value)}
+	}
+
+	tests := map[string]struct {
+		allowed policyapi.CertificateRequestPolicyAllowed
+		// csrMod requests the attribute on the CSR.
+		csrMod gen.CSRModifier
+		// requestMod requests the attribute on the CertificateRequest.
+		requestMod gen.CertificateRequestModifier
+	}{
+		"commonName": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{CommonName: requiredString("*")},
+			csrMod:  gen.SetCSRCommonName("example.com"),
+		},
+		"dnsNames": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{DNSNames: required("*")},
+			csrMod:  gen.SetCSRDNSNames("example.com"),
+		},
+		"ipAddresses": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{IPAddresses: required("10.0.0.0/8")},
+			csrMod:  gen.SetCSRIPAddresses(net.ParseIP("10.0.0.1")),
+		},
+		"uris": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{URIs: required("spiffe://cluster.local/ns/*/sa/*")},
+			csrMod:  gen.SetCSRURIs(uri),
+		},
+		"emailAddresses": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{EmailAddresses: required("*@example.com")},
+			csrMod:  gen.SetCSREmails([]string{"foo@example.com"}),
+		},
+		"annotations": {
+			allowed:    policyapi.CertificateRequestPolicyAllowed{Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{"example.com/ticket": *requiredString("*")}},
+			requestMod: gen.AddCertificateRequestAnnotations(map[string]string{"example.com/ticket": "1234"}),
+		},
+		"subject.organizations": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{Organizations: required("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Organization = []string{"company"} }),
+		},
+		"subject.countries": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{Countries: required("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Country = []string{"country"} }),
+		},
+		"subject.organizationalUnits": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{OrganizationalUnits: required("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.OrganizationalUnit = []string{"unit"} }),
+		},
+		"subject.localities": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{Localities: required("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Locality = []string{"locality"} }),
+		},
+		"subject.provinces": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{Provinces: required("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.Province = []string{"province"} }),
+		},
+		"subject.streetAddresses": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{StreetAddresses: required("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.StreetAddress = []string{"street"} }),
+		},
+		"subject.postalCodes": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{PostalCodes: required("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.PostalCode = []string{"postal-code"} }),
+		},
+		"subject.serialNumber": {
+			allowed: policyapi.CertificateRequestPolicyAllowed{Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{SerialNumber: requiredString("*")}},
+			csrMod:  noErrModifier(func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "serial" }),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := &policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{Allowed: &test.allowed}}
+
+			path := field.NewPath("spec", "allowed")
+			for _, child := range strings.Split(name, ".") {
+				path = path.Child(child)
+			}
+			if test.allowed.Annotations != nil {
+				path = path.Key("example.com/ticket")
+			}
+			el := field.ErrorList{field.Required(path.Child("required"), "true")}
+
+			request := gen.CertificateRequest("", gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA)))
+			response, err := (&allowed{}).Evaluate(context.TODO(), policy, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, response, "request omitting the attribute")
+
+			var csrMods []gen.CSRModifier
+			if test.csrMod != nil {
+				csrMods = append(csrMods, test.csrMod)
+			}
+			requestMods := []gen.CertificateRequestModifier{gen.SetCertificateRequestCSR(csrFrom(t, x509.ECDSA, csrMods...))}
+			if test.requestMod != nil {
+				requestMods = append(requestMods, test.requestMod)
+			}
+			request = gen.CertificateRequest("", requestMods...)
+			response, err = (&allowed{}).Evaluate(context.TODO(), policy, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, approver.EvaluationResponse{Result: approver.ResultNotDenied}, response, "request including the attribute")
+		})
+	}
+}
+
+// FuzzEvaluateCSR ensures that arbitrary request bytes never cause Evaluate to
+// panic or error; a request which can't be decoded must be denied.
+func FuzzEvaluateCSR(f *testing.F) {
+	csr, _, err := gen.CSR(x509.ECDSA, gen.SetCSRDNSNames("foo.example.com"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(csr)
+	malformed, _, err := gen.CSR(x509.ECDSA, gen.SetCSRDNSNames("foo.example.com"), func(csr *x509.CertificateRequest) error {
+		csr.ExtraExtensions = append(csr.ExtraExtensions, pkix.Extension{Id: utilpki.OIDExtensionBasicConstraints, Value: []byte{0xff, 0x01}})
+		return nil
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(malformed)
+	f.Add([]byte("-----BEGIN CERTIFICATE REQUEST-----\nAAAA\n-----END CERTIFICATE REQUEST-----\n"))
+	f.Add([]byte(""))
+
+	// The policy evaluates the CSR directly, so that the extensions of the
+	// CSR are decoded.
+	policy := &policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+		EvaluateCSRDirectly: true,
+		Allowed: &policyapi.CertificateRequestPolicyAllowed{
+			DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+			IsCA:     pointer.Bool(true),
+			Usages:   &[]cmapi.KeyUsage{"*"},
+		},
+	}}
+
+	f.Fuzz(func(t *testing.T, csr []byte) {
+		request := gen.CertificateRequest("", gen.SetCertificateRequestCSR(csr))
+		response, err := (&allowed{}).Evaluate(context.TODO(), policy, request)
+		if err != nil {
+			t.Fatalf("unexpected error evaluating request: %v", err)
+		}
+
+		decoded, decodeErr := util.DecodeCSR(csr)
+		if decodeErr == nil {
+			if _, _, _, decodeErr = util.DecodeBasicConstraints(decoded); decodeErr == nil {
+				_, decodeErr = util.DecodeUsages(decoded)
+			}
+		}
+		if decodeErr != nil && response.Result != approver.ResultDenied {
+			t.Fatalf("expected malformed request to be denied: %v", decodeErr)
+		}
+	})
+}
+
+// Register the example custom matcher for the tests of this package.
+func init() {
+	matcher.Shared.Store(caseInsensitiveMatcher{})
+}
+
+// caseInsensitiveMatcher is an example custom matcher which matches values
+// which are equal regardless of case.
+type caseInsensitiveMatcher struct{}
+
+func (caseInsensitiveMatcher) Name() string {
+	return "test-case-insensitive"
+}
+
+func (caseInsensitiveMatcher) Matches(allowed, requested string) bool {
+	return strings.EqualFold(allowed, requested)
+}
+
+// valuesFromClient returns a client serving the "allowed-dns-names" ConfigMap
+// referenced by valuesFrom, and the "team-a" namespace labelled with its team.
+func valuesFromClient(t *testing.T) client.Reader {
+	t.Helper()
+	return fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "allowed-dns-names"},
+			Data:       map[string]string{"dnsNames": "# DNS names of team foo\n foo.example.com \n\n*.bar.example.com\n"},
+		}, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "payments"}},
+		}).
+		Build()
+}
+
+// valuesFrom returns a reference to the given key of the "allowed-dns-names"
+// ConfigMap.
+func valuesFrom(key string) *policyapi.CertificateRequestPolicyValuesFrom {
+	return &policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "allowed-dns-names", Key: key}
+}
+
+func noErrModifier(fn func(*x509.CertificateRequest)) func(*x509.CertificateRequest) error {
+	return func(csr *x509.CertificateRequest) error {
+		fn(csr)
+		return nil
+	}
+}
+
+// matchType returns a pointer to the given match type.
+func matchType(m policyapi.AllowedMatchType) *policyapi.AllowedMatchType {
+	return &m
+}
+
+func csrFrom(t *testing.T, keyAlgorithm x509.PublicKeyAlgorithm, mods ...gen.CSRModifier) []byte {
+	t.Helper()
+	csr, _, err := gen.CSR(keyAlgorithm, mods...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr
+}
+
+// csrForCertificate returns the PEM encoded CSR which cert-manager generates
+// for a Certificate with the common name "hello-world" and the given
+// modifiers.
+func csrForCertificate(t *testing.T, mods ...gen.CertificateModifier) []byte {
+	mods = append([]gen.CertificateModifier{gen.SetCertificateCommonName("hello-world")}, mods...)
+	crt := gen.Certificate("", mods...)
+	template, err := utilpki.GenerateCSR(crt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add the basic constraints extension cert-manager adds when the
+	// UseCertificateRequestBasicConstraints feature gate is enabled.
+	if crt.Spec.IsCA {
+		value, err := asn1.Marshal(struct {
+			IsCA bool `asn1:"optional"`
+		}{IsCA: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+			Id: utilpki.OIDExtensionBasicConstraints, Critical: true, Value: value,
+		})
+	}
+
+	key, err := utilpki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := utilpki.EncodeCSR(template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+}
+
+// upnOtherNames returns a CSR modifier which sets the subject alternative
+// names of the CSR to User Principal Name otherNames of the given values.
+func upnOtherNames(t *testing.T, upns ...string) gen.CSRModifier {
+	var names []asn1.RawValue
+	for _, upn := range upns {
+		value, err := asn1.MarshalWithParams(upn, "utf8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		otherName, err := asn1.MarshalWithParams(struct {
+			TypeID asn1.ObjectIdentifier
+			Value  asn1.RawValue
+		}{
+			TypeID: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3},
+			Value:  asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value},
+		}, "tag:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, asn1.RawValue{FullBytes: otherName})
+	}
+
+	value, err := asn1.Marshal(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return noErrModifier(func(csr *x509.CertificateRequest) {
+		csr.ExtraExtensions = append(csr.ExtraExtensions, pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: value})
+	})
+}
+
+func spiffeURI(t *testing.T, uri string) *url.URL {
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// Test_defaultUsages ensures the default usages are kept in sync with those
+// cert-manager applies to requests which don't request any.
+func Test_defaultUsages(t *testing.T) {
+	assert.ElementsMatch(t, cmapi.DefaultKeyUsages(), defaultUsages)
+}
+
+// orderedOUs returns a CSR modifier which sets the organizational units of the
+// subject, each in its own RDN. Organizational units set with
+// Subject.OrganizationalUnit are encoded in a single DER SET, which sorts them.
+func orderedOUs(ous ...string) gen.CSRModifier {
+	return noErrModifier(func(csr *x509.CertificateRequest) {
+		for _, ou := range ous {
+			csr.Subject.ExtraNames = append(csr.Subject.ExtraNames, pkix.AttributeTypeAndValue{Type: asn1.ObjectIdentifier{2, 5, 4, 11}, Value: ou})
+		}
+	})
 }
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allowed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateData is the context allowed values containing templates are
+// rendered with.
+type templateData struct {
+	// Namespace is the namespace of the request.
+	Namespace string
+
+	// Labels are the labels of the request's namespace.
+	Labels map[string]string
+}
+
+// isTemplate returns true if the allowed value contains a template which must
+// be rendered before matching.
+func isTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// parseTemplate parses the template of an allowed value. Rendering errors if
+// the template references a label the namespace doesn't have.
+func parseTemplate(value string) (*template.Template, error) {
+	return template.New("value").Option("missingkey=error").Parse(value)
+}
+
+// validateTemplate returns the allowed value rendered for an example
+// namespace without labels, so that the rendered value may be validated.
+// Returns an error if the template is invalid or references unknown fields.
+func validateTemplate(value string) (string, error) {
+	tmpl, err := parseTemplate(value)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Option("missingkey=zero").Execute(&rendered, templateData{Namespace: "namespace"}); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+// templateCache caches parsed templates, and the values they render for each
+// namespace. Rendered values are discarded once the namespace changes.
+type templateCache struct {
+	lock       sync.Mutex
+	templates  map[string]*template.Template
+	namespaces map[string]renderedNamespace
+}
+
+// renderedNamespace is the values rendered for a namespace at a resource
+// version. Templates which failed to render are cached as not ok.
+type renderedNamespace struct {
+	resourceVersion string
+	values          map[string]renderedValue
+}
+
+type renderedValue struct {
+	value string
+	ok    bool
+}
+
+// renderValues returns the allowed values with any templates rendered for the
+// given namespace. Values whose template fails to render, for example because
+// it references a label the namespace doesn't have, are omitted so never
+// match. Values are returned unchanged if none contain a template.
+func (a *allowed) renderValues(ctx context.Context, namespace string, values *[]string) (*[]string, error) {
+	if values == nil || !hasTemplate(*values) {
+		return values, nil
+	}
+
+	data := templateData{Namespace: namespace}
+	var resourceVersion string
+	if a.lister != nil {
+		var ns corev1.Namespace
+		if err := a.lister.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+			return nil, fmt.Errorf("failed to get request's namespace to render allowed values: %w", err)
+		}
+		data.Labels = ns.Labels
+		resourceVersion = ns.ResourceVersion
+	}
+
+	a.templates.lock.Lock()
+	defer a.templates.lock.Unlock()
+
+	if a.templates.templates == nil {
+		a.templates.templates = make(map[string]*template.Template)
+		a.templates.namespaces = make(map[string]renderedNamespace)
+	}
+
+	rendered, ok := a.templates.namespaces[namespace]
+	if !ok || rendered.resourceVersion != resourceVersion {
+		rendered = renderedNamespace{resourceVersion: resourceVersion, values: make(map[string]renderedValue)}
+		a.templates.namespaces[namespace] = rendered
+	}
+
+	result := make([]string, 0, len(*values))
+	for _, value := range *values {
+		if !isTemplate(value) {
+			result = append(result, value)
+			continue
+		}
+
+		r, ok := rendered.values[value]
+		if !ok {
+			r = a.templates.render(value, data)
+			rendered.values[value] = r
+		}
+		if r.ok {
+			result = append(result, r.value)
+		}
+	}
+
+	return &result, nil
+}
+
+// render renders the template of the allowed value. Must be called with the
+// lock held.
+func (t *templateCache) render(value string, data templateData) renderedValue {
+	tmpl, ok := t.templates[value]
+	if !ok {
+		var err error
+		if tmpl, err = parseTemplate(value); err != nil {
+			// Invalid templates are rejected by the webhook, so can only be
+			// present if the policy was admitted before it was validated.
+			return renderedValue{}
+		}
+		t.templates[value] = tmpl
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return renderedValue{}
+	}
+	return renderedValue{value: rendered.String(), ok: true}
+}
+
+// hasTemplate returns true if any of the allowed values contain a template.
+func hasTemplate(values []string) bool {
+	for _, value := range values {
+		if isTemplate(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allowed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_renderValues(t *testing.T) {
+	ctx := context.TODO()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "payments"}},
+	}
+	lister := fakeclient.NewClientBuilder().WithScheme(policyapi.GlobalScheme).WithObjects(namespace).Build()
+	a := &allowed{lister: lister}
+
+	values := &[]string{"foo.example.com", "*.{{ .Namespace }}.svc.cluster.local", "*.{{ .Labels.team }}.example.com", "*.{{ .Labels.owner }}.example.com"}
+
+	rendered, err := a.renderValues(ctx, "team-a", values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &[]string{"foo.example.com", "*.team-a.svc.cluster.local", "*.payments.example.com"}, rendered,
+		"templates should be rendered for the namespace, omitting those referencing a missing label")
+
+	// Values without templates are returned as is, without getting the
+	// namespace.
+	plain := &[]string{"foo.example.com"}
+	rendered, err = a.renderValues(ctx, "not-found", plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Same(t, plain, rendered)
+
+	// Rendered values are cached until the namespace changes.
+	if err := lister.Get(ctx, client.ObjectKeyFromObject(namespace), namespace); err != nil {
+		t.Fatal(err)
+	}
+	namespace.Labels = map[string]string{"team": "billing", "owner": "alice"}
+	if err := lister.Update(ctx, namespace); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err = a.renderValues(ctx, "team-a", values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &[]string{"foo.example.com", "*.team-a.svc.cluster.local", "*.billing.example.com", "*.alice.example.com"}, rendered,
+		"templates should be rendered again once the namespace's labels change")
+	assert.Len(t, a.templates.namespaces, 1)
+	assert.Len(t, a.templates.namespaces["team-a"].values, 3)
+}
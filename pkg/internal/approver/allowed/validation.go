@@ -18,16 +18,60 @@ package allowed
 
 import (
 	"context"
+	"net"
+	"net/url"
+	"strings"
 
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+	"github.com/cert-manager/approver-policy/pkg/matcher"
+)
+
+// knownUsages is the closed set of key usages supported by cert-manager.
+var knownUsages = sets.New(
+	cmapi.UsageSigning,
+	cmapi.UsageDigitalSignature,
+	cmapi.UsageContentCommitment,
+	cmapi.UsageKeyEncipherment,
+	cmapi.UsageKeyAgreement,
+	cmapi.UsageDataEncipherment,
+	cmapi.UsageCertSign,
+	cmapi.UsageCRLSign,
+	cmapi.UsageEncipherOnly,
+	cmapi.UsageDecipherOnly,
+	cmapi.UsageAny,
+	cmapi.UsageServerAuth,
+	cmapi.UsageClientAuth,
+	cmapi.UsageCodeSigning,
+	cmapi.UsageEmailProtection,
+	cmapi.UsageSMIME,
+	cmapi.UsageIPsecEndSystem,
+	cmapi.UsageIPsecTunnel,
+	cmapi.UsageIPsecUser,
+	cmapi.UsageTimestamping,
+	cmapi.UsageOCSPSigning,
+	cmapi.UsageMicrosoftSGC,
+	cmapi.UsageNetscapeSGC,
+)
+
+// knownMatchTypes is the closed set of match types of allowed values.
+var knownMatchTypes = sets.New(
+	policyapi.AllowedMatchTypeExact,
+	policyapi.AllowedMatchTypePrefix,
+	policyapi.AllowedMatchTypeSuffix,
+	policyapi.AllowedMatchTypeWildcard,
+	policyapi.AllowedMatchTypeRegex,
 )
 
 // Validate validates that the processed CertificateRequestPolicy has valid
 // allowed fields defined and there are no parsing errors in the values.
-func (a allowed) Validate(_ context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+func (a *allowed) Validate(ctx context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
 	// If no allowed fields are defined we can exit early
 	if policy.Spec.Allowed == nil {
 		return approver.WebhookValidationResponse{
@@ -37,58 +81,406 @@ func (a allowed) Validate(_ context.Context, policy *policyapi.CertificateReques
 	}
 
 	var (
-		el      field.ErrorList
-		allowed = policy.Spec.Allowed
-		fldPath = field.NewPath("spec", "allowed")
+		el       field.ErrorList
+		warnings []string
+		allowed  = policy.Spec.Allowed
+		fldPath  = field.NewPath("spec", "allowed")
 	)
 
+	// supportsCaseInsensitive marks whether the field may set the
+	// caseInsensitive option. supportsNegation marks whether the field's values
+	// may be negated with the "!" prefix. supportsAllowedDomains marks whether
+	// the field may set allowedDomains. supportsValuesFrom marks whether the
+	// field may set valuesFrom. supportsEntries marks whether the field may
+	// set minEntries and maxEntries. supportsIncludeCommonName marks whether
+	// the field may set includeCommonName. supportsNormalizeIDNA marks whether
+	// the field may set normalizeIDNA. supportsOrdered marks whether the field
+	// may set ordered.
 	type stringSlicePair struct {
-		path  *field.Path
-		slice *policyapi.CertificateRequestPolicyAllowedStringSlice
+		path                      *field.Path
+		slice                     *policyapi.CertificateRequestPolicyAllowedStringSlice
+		supportsCaseInsensitive   bool
+		supportsNegation          bool
+		supportsMatchType         bool
+		supportsAllowedDomains    bool
+		supportsValuesFrom        bool
+		supportsEntries           bool
+		supportsIncludeCommonName bool
+		supportsNormalizeIDNA     bool
+		supportsOrdered           bool
 	}
 	stringSlices := []stringSlicePair{
-		{fldPath.Child("dnsNames"), allowed.DNSNames},
-		{fldPath.Child("ipAddresses"), allowed.IPAddresses},
-		{fldPath.Child("uris"), allowed.URIs},
-		{fldPath.Child("emailAddresses"), allowed.EmailAddresses},
+		{fldPath.Child("dnsNames"), allowed.DNSNames, false, true, true, false, true, false, false, true, false},
+		{fldPath.Child("ipAddresses"), allowed.IPAddresses, false, true, false, false, false, false, true, false, false},
+		{fldPath.Child("uris"), allowed.URIs, false, true, true, false, false, false, false, false, false},
+		{fldPath.Child("emailAddresses"), allowed.EmailAddresses, true, false, false, true, false, false, false, false, false},
 	}
 
+	// rejectsEmptyRequired marks whether an empty required value is rejected
+	// since it can never match. An empty required commonName is permitted for
+	// compatibility.
 	type stringPair struct {
-		path   *field.Path
-		string *policyapi.CertificateRequestPolicyAllowedString
+		path                    *field.Path
+		string                  *policyapi.CertificateRequestPolicyAllowedString
+		supportsCaseInsensitive bool
+		supportsMatchDNSNames   bool
+		supportsMatchType       bool
+		rejectsEmptyRequired    bool
 	}
 	strings := []stringPair{
-		{fldPath.Child("commonName"), allowed.CommonName},
+		{fldPath.Child("commonName"), allowed.CommonName, true, true, true, false},
 	}
 
 	if allowedSub := allowed.Subject; allowedSub != nil {
 		fldPathSub := fldPath.Child("subject")
 
-		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("organizations"), allowedSub.Organizations})
-		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("countries"), allowedSub.Countries})
-		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("organizationalUnits"), allowedSub.OrganizationalUnits})
-		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("localities"), allowedSub.Localities})
-		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("provinces"), allowedSub.Provinces})
-		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("streetAddresses"), allowedSub.StreetAddresses})
-		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("postalCodes"), allowedSub.PostalCodes})
+		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("organizations"), allowedSub.Organizations, false, false, false, false, false, true, false, false, false})
+		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("countries"), allowedSub.Countries, false, false, false, false, false, true, false, false, false})
+		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("organizationalUnits"), allowedSub.OrganizationalUnits, false, false, false, false, false, true, false, false, true})
+		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("localities"), allowedSub.Localities, false, false, false, false, false, true, false, false, false})
+		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("provinces"), allowedSub.Provinces, false, false, false, false, false, true, false, false, false})
+		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("streetAddresses"), allowedSub.StreetAddresses, false, false, false, false, false, true, false, false, false})
+		stringSlices = append(stringSlices, stringSlicePair{fldPathSub.Child("postalCodes"), allowedSub.PostalCodes, false, false, false, false, false, true, false, false, false})
 
-		strings = append(strings, stringPair{fldPathSub.Child("serialNumber"), allowedSub.SerialNumber})
+		strings = append(strings, stringPair{fldPathSub.Child("serialNumber"), allowedSub.SerialNumber, false, false, true, true})
+	}
+
+	for _, key := range sets.List(sets.KeySet(allowed.Annotations)) {
+		fldPath := fldPath.Child("annotations").Key(key)
+		for _, msg := range validation.IsQualifiedName(key) {
+			el = append(el, field.Invalid(fldPath, key, msg))
+		}
+		annotation := allowed.Annotations[key]
+		strings = append(strings, stringPair{fldPath, &annotation, false, false, false, false})
 	}
 
 	for _, stringSlice := range stringSlices {
-		if stringSlice.slice != nil && stringSlice.slice.Required != nil && *stringSlice.slice.Required && stringSlice.slice.Values == nil {
-			el = append(el, field.Required(stringSlice.path.Child("values"), "values must be defined if required field"))
+		allowedDomains := stringSlice.supportsAllowedDomains && stringSlice.slice != nil && stringSlice.slice.AllowedDomains != nil
+		if stringSlice.slice != nil && stringSlice.slice.Required != nil && *stringSlice.slice.Required && stringSlice.slice.ValuesFrom == nil && !allowedDomains {
+			if stringSlice.slice.Values == nil {
+				el = append(el, field.Required(stringSlice.path.Child("values"), "values must be defined if required field"))
+			} else if len(*stringSlice.slice.Values) == 0 {
+				el = append(el, field.Invalid(stringSlice.path.Child("values"), *stringSlice.slice.Values, "values must not be empty if required field"))
+			}
+		}
+		if stringSlice.slice != nil && stringSlice.slice.AllowedDomains != nil {
+			if !stringSlice.supportsAllowedDomains {
+				el = append(el, field.Forbidden(stringSlice.path.Child("allowedDomains"), "allowedDomains is not supported on this field"))
+			} else {
+				el = append(el, validateAllowedDomains(stringSlice.path.Child("allowedDomains"), stringSlice.slice.AllowedDomains)...)
+			}
+		}
+		if stringSlice.slice != nil && stringSlice.slice.ValuesFrom != nil {
+			if !stringSlice.supportsValuesFrom {
+				el = append(el, field.Forbidden(stringSlice.path.Child("valuesFrom"), "valuesFrom is not supported on this field"))
+			} else if errs := util.ValidateValuesFrom(stringSlice.path.Child("valuesFrom"), stringSlice.slice.ValuesFrom); len(errs) > 0 {
+				el = append(el, errs...)
+			} else {
+				warning, err := a.valuesFromWarning(ctx, stringSlice.path.Child("valuesFrom"), stringSlice.slice.ValuesFrom)
+				if err != nil {
+					return approver.WebhookValidationResponse{}, err
+				}
+				if len(warning) > 0 {
+					warnings = append(warnings, warning)
+				}
+			}
+		}
+		if stringSlice.slice != nil {
+			el = append(el, validateEntries(stringSlice.path, stringSlice.slice, stringSlice.supportsEntries)...)
+		}
+		if stringSlice.slice != nil && stringSlice.slice.CaseInsensitive != nil && !stringSlice.supportsCaseInsensitive {
+			el = append(el, field.Forbidden(stringSlice.path.Child("caseInsensitive"), "caseInsensitive is not supported on this field"))
+		}
+		if stringSlice.slice != nil && stringSlice.slice.Ordered != nil {
+			if !stringSlice.supportsOrdered {
+				el = append(el, field.Forbidden(stringSlice.path.Child("ordered"), "ordered is not supported on this field"))
+			} else if *stringSlice.slice.Ordered && stringSlice.slice.MinEntries != nil && len(deref(stringSlice.slice.Values)) < *stringSlice.slice.MinEntries {
+				el = append(el, field.Invalid(stringSlice.path.Child("values"), deref(stringSlice.slice.Values), "values must contain at least minEntries values if ordered"))
+			}
+		}
+		if stringSlice.slice != nil && stringSlice.slice.IncludeCommonName != nil && !stringSlice.supportsIncludeCommonName {
+			el = append(el, field.Forbidden(stringSlice.path.Child("includeCommonName"), "includeCommonName is not supported on this field"))
+		}
+		if stringSlice.slice != nil && stringSlice.slice.NormalizeIDNA != nil {
+			if !stringSlice.supportsNormalizeIDNA {
+				el = append(el, field.Forbidden(stringSlice.path.Child("normalizeIDNA"), "normalizeIDNA is not supported on this field"))
+			} else if stringSlice.slice.MatchType != nil && *stringSlice.slice.MatchType == policyapi.AllowedMatchTypeRegex {
+				el = append(el, field.Forbidden(stringSlice.path.Child("normalizeIDNA"), "normalizeIDNA may not be set with a Regex matchType"))
+			}
+		}
+		if stringSlice.slice != nil && stringSlice.slice.MatchType != nil {
+			if !stringSlice.supportsMatchType {
+				el = append(el, field.Forbidden(stringSlice.path.Child("matchType"), "matchType is not supported on this field"))
+			} else if matchType := *stringSlice.slice.MatchType; !knownMatchTypes.Has(matchType) {
+				var supported []string
+				for _, known := range sets.List(knownMatchTypes) {
+					supported = append(supported, string(known))
+				}
+				el = append(el, field.NotSupported(stringSlice.path.Child("matchType"), matchType, supported))
+			}
+		}
+		if stringSlice.slice != nil {
+			el = append(el, validateMatcher(stringSlice.path, stringSlice.slice.Matcher, stringSlice.slice.MatchType, stringSlice.supportsMatchType)...)
+		}
+		// Values which contain only negated values would deny every request
+		// for that field.
+		if stringSlice.supportsNegation && stringSlice.slice != nil && stringSlice.slice.Values != nil && len(*stringSlice.slice.Values) > 0 {
+			if positive, _ := util.SplitNegated(*stringSlice.slice.Values); len(positive) == 0 {
+				el = append(el, field.Invalid(stringSlice.path.Child("values"), *stringSlice.slice.Values, "must contain at least one value which is not negated with \"!\", otherwise all requests are denied"))
+			}
 		}
 	}
 
 	for _, stringI := range strings {
-		if stringI.string != nil && stringI.string.Required != nil && *stringI.string.Required && stringI.string.Value == nil {
+		matchDNSNames := stringI.supportsMatchDNSNames && stringI.string != nil && stringI.string.MatchDNSNames != nil && *stringI.string.MatchDNSNames
+		if stringI.string != nil && stringI.string.Required != nil && *stringI.string.Required && stringI.string.Value == nil && !matchDNSNames {
 			el = append(el, field.Required(stringI.path.Child("value"), "value must be defined if required field"))
 		}
+		if stringI.string != nil && stringI.string.CaseInsensitive != nil && !stringI.supportsCaseInsensitive {
+			el = append(el, field.Forbidden(stringI.path.Child("caseInsensitive"), "caseInsensitive is not supported on this field"))
+		}
+		if stringI.string != nil && stringI.string.MatchDNSNames != nil && !stringI.supportsMatchDNSNames {
+			el = append(el, field.Forbidden(stringI.path.Child("matchDNSNames"), "matchDNSNames is not supported on this field"))
+		}
+		if stringI.string != nil && stringI.string.MatchType != nil {
+			if !stringI.supportsMatchType {
+				el = append(el, field.Forbidden(stringI.path.Child("matchType"), "matchType is not supported on this field"))
+			} else if matchType := *stringI.string.MatchType; !knownMatchTypes.Has(matchType) {
+				var supported []string
+				for _, known := range sets.List(knownMatchTypes) {
+					supported = append(supported, string(known))
+				}
+				el = append(el, field.NotSupported(stringI.path.Child("matchType"), matchType, supported))
+			}
+		}
+		if stringI.string != nil {
+			el = append(el, validateMatcher(stringI.path, stringI.string.Matcher, stringI.string.MatchType, stringI.supportsMatchType)...)
+		}
+		if stringI.rejectsEmptyRequired && stringI.string != nil && stringI.string.Required != nil && *stringI.string.Required && stringI.string.Value != nil && len(*stringI.string.Value) == 0 {
+			el = append(el, field.Invalid(stringI.path.Child("value"), "", "value must not be empty if required field"))
+		}
+		if stringI.supportsMatchType && stringI.string != nil && stringI.string.Value != nil && isMatchType(stringI.string.MatchType, policyapi.AllowedMatchTypeRegex) {
+			if err := util.ValidateRegex(*stringI.string.Value); err != nil {
+				el = append(el, field.Invalid(stringI.path.Child("value"), *stringI.string.Value, err.Error()))
+			}
+		}
+	}
+
+	// DNSNames and URIs values may be templates rendered for the request's
+	// namespace. Ensure they parse and only reference known fields.
+	for _, slice := range []struct {
+		path  *field.Path
+		slice *policyapi.CertificateRequestPolicyAllowedStringSlice
+	}{
+		{fldPath.Child("dnsNames", "values"), allowed.DNSNames},
+		{fldPath.Child("uris", "values"), allowed.URIs},
+	} {
+		if slice.slice == nil || slice.slice.Values == nil {
+			continue
+		}
+		for i, value := range *slice.slice.Values {
+			if !isTemplate(value) {
+				continue
+			}
+			if _, err := validateTemplate(value); err != nil {
+				el = append(el, field.Invalid(slice.path.Index(i), value, err.Error()))
+			}
+		}
+	}
+
+	// DNSNames values may contain regular expressions. Ensure they compile so
+	// they don't silently never match at evaluation time.
+	if allowed.DNSNames != nil && allowed.DNSNames.Values != nil && allowed.DNSNames.Matcher == nil {
+		fldPath := fldPath.Child("dnsNames", "values")
+		matchType := allowed.DNSNames.MatchType
+		for i, value := range *allowed.DNSNames.Values {
+			pattern, ok := renderedPattern(value)
+			if !ok {
+				continue
+			}
+			if util.IsNegated(pattern) {
+				pattern = pattern[len(util.NegationPrefix):]
+			}
+			switch {
+			case isMatchType(matchType, policyapi.AllowedMatchTypeRegex):
+			case isMatchType(matchType, policyapi.AllowedMatchTypeWildcard) && util.IsRegex(pattern):
+				pattern = pattern[len(util.RegexPrefix):]
+			default:
+				continue
+			}
+			if err := util.ValidateRegex(pattern); err != nil {
+				el = append(el, field.Invalid(fldPath.Index(i), value, err.Error()))
+			}
+		}
+	}
+
+	// IPAddresses values may contain CIDR ranges. Ensure they parse so they
+	// don't silently never match at evaluation time.
+	if allowed.IPAddresses != nil && allowed.IPAddresses.Values != nil {
+		fldPath := fldPath.Child("ipAddresses", "values")
+		for i, value := range *allowed.IPAddresses.Values {
+			pattern := value
+			if util.IsNegated(pattern) {
+				pattern = pattern[len(util.NegationPrefix):]
+			}
+			if !util.IsCIDR(pattern) {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(pattern); err != nil {
+				el = append(el, field.Invalid(fldPath.Index(i), value, err.Error()))
+			}
+		}
+	}
+
+	// URIs values are matched per path segment, so must be valid URIs, unless
+	// they are regular expressions which must compile. Values matched by a
+	// named matcher are opaque to the webhook.
+	if allowed.URIs != nil && allowed.URIs.Values != nil && allowed.URIs.Matcher == nil {
+		fldPath := fldPath.Child("uris", "values")
+		regex := isMatchType(allowed.URIs.MatchType, policyapi.AllowedMatchTypeRegex)
+		for i, value := range *allowed.URIs.Values {
+			pattern, ok := renderedPattern(value)
+			if !ok {
+				continue
+			}
+			if util.IsNegated(pattern) {
+				pattern = pattern[len(util.NegationPrefix):]
+			}
+			if regex {
+				if err := util.ValidateRegex(pattern); err != nil {
+					el = append(el, field.Invalid(fldPath.Index(i), value, err.Error()))
+				}
+			} else if _, err := url.Parse(pattern); err != nil {
+				el = append(el, field.Invalid(fldPath.Index(i), value, err.Error()))
+			}
+		}
+	}
+
+	// otherNames are matched by OID, so each entry must have a distinct valid
+	// OID.
+	if allowed.OtherNames != nil {
+		fldPath := fldPath.Child("otherNames")
+		seen := sets.New[string]()
+		for i, otherName := range allowed.OtherNames {
+			fldPath := fldPath.Index(i)
+			switch {
+			case !util.IsOID(otherName.OID):
+				el = append(el, field.Invalid(fldPath.Child("oid"), otherName.OID, "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.311.20.2.3"))
+			case seen.Has(otherName.OID):
+				el = append(el, field.Duplicate(fldPath.Child("oid"), otherName.OID))
+			}
+			seen.Insert(otherName.OID)
+			if len(otherName.Values) == 0 {
+				el = append(el, field.Required(fldPath.Child("values"), "values must contain at least one value"))
+			}
+		}
+	}
+
+	if allowed.Usages != nil {
+		fldPath := fldPath.Child("usages")
+		for i, usage := range *allowed.Usages {
+			if !knownUsages.Has(usage) {
+				var supported []string
+				for _, known := range sets.List(knownUsages) {
+					supported = append(supported, string(known))
+				}
+				el = append(el, field.NotSupported(fldPath.Index(i), usage, supported))
+			}
+		}
+	}
+
+	if allowed.ExactUsages != nil && *allowed.ExactUsages && (allowed.Usages == nil || len(*allowed.Usages) == 0) {
+		el = append(el, field.Required(fldPath.Child("usages"), "usages must be defined if exactUsages is true"))
 	}
 
 	return approver.WebhookValidationResponse{
-		Allowed: len(el) == 0,
-		Errors:  el,
+		Allowed:  len(el) == 0,
+		Errors:   el,
+		Warnings: warnings,
 	}, nil
 }
+
+// renderedPattern returns the allowed value to validate, which is the value
+// rendered for an example namespace if it is a template. Returns false if the
+// template is invalid, which is reported separately.
+func renderedPattern(value string) (string, bool) {
+	if !isTemplate(value) {
+		return value, true
+	}
+	rendered, err := validateTemplate(value)
+	return rendered, err == nil
+}
+
+// validateEntries validates the minEntries and maxEntries of an allowed field.
+// supported marks whether the field may set them, which is the case for the
+// subject fields.
+func validateEntries(fldPath *field.Path, slice *policyapi.CertificateRequestPolicyAllowedStringSlice, supported bool) field.ErrorList {
+	var el field.ErrorList
+	if !supported {
+		if slice.MinEntries != nil {
+			el = append(el, field.Forbidden(fldPath.Child("minEntries"), "minEntries is not supported on this field"))
+		}
+		if slice.MaxEntries != nil {
+			el = append(el, field.Forbidden(fldPath.Child("maxEntries"), "maxEntries is not supported on this field"))
+		}
+		return el
+	}
+
+	if slice.MinEntries != nil && *slice.MinEntries < 0 {
+		el = append(el, field.Invalid(fldPath.Child("minEntries"), *slice.MinEntries, "minEntries must be a value greater or equal to 0"))
+	}
+	if slice.MaxEntries != nil && *slice.MaxEntries < 0 {
+		el = append(el, field.Invalid(fldPath.Child("maxEntries"), *slice.MaxEntries, "maxEntries must be a value greater or equal to 0"))
+	}
+	if slice.MinEntries != nil && slice.MaxEntries != nil && *slice.MaxEntries < *slice.MinEntries {
+		el = append(el, field.Invalid(fldPath.Child("maxEntries"), *slice.MaxEntries, "maxEntries must be the same value as minEntries or larger"))
+	}
+	return el
+}
+
+// validateMatcher validates the matcher of an allowed field, which must be
+// registered in the shared matcher registry, and may not be set with a match
+// type. supported marks whether the field may set matcher, which is the case
+// for the fields which support matchType.
+func validateMatcher(fldPath *field.Path, name *string, matchType *policyapi.AllowedMatchType, supported bool) field.ErrorList {
+	if name == nil {
+		return nil
+	}
+
+	var el field.ErrorList
+	switch {
+	case !supported:
+		el = append(el, field.Forbidden(fldPath.Child("matcher"), "matcher is not supported on this field"))
+	case matchType != nil:
+		el = append(el, field.Forbidden(fldPath.Child("matcher"), "matcher may not be set with matchType"))
+	default:
+		if _, ok := matcher.Shared.Get(*name); !ok {
+			el = append(el, field.NotSupported(fldPath.Child("matcher"), *name, matcher.Shared.Names()))
+		}
+	}
+	return el
+}
+
+// isMatchType returns whether the given match type is the expected match
+// type. A nil match type is the Wildcard match type.
+func isMatchType(matchType *policyapi.AllowedMatchType, expected policyapi.AllowedMatchType) bool {
+	if matchType == nil {
+		return expected == policyapi.AllowedMatchTypeWildcard
+	}
+	return *matchType == expected
+}
+
+// validateAllowedDomains validates that the allowed email address domains are
+// not empty, and don't contain "@".
+func validateAllowedDomains(fldPath *field.Path, domains []string) field.ErrorList {
+	var el field.ErrorList
+	for i, domain := range domains {
+		switch {
+		case len(domain) == 0:
+			el = append(el, field.Invalid(fldPath.Index(i), domain, "domain must not be empty"))
+		case strings.Contains(domain, "@"):
+			el = append(el, field.Invalid(fldPath.Index(i), domain, "domain must not contain \"@\""))
+		}
+	}
+	return el
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"testing"
 
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
@@ -29,6 +30,15 @@ import (
 )
 
 func Test_Validate(t *testing.T) {
+	knownUsageStrings := []string{
+		"any", "cert sign", "client auth", "code signing", "content commitment",
+		"crl sign", "data encipherment", "decipher only", "digital signature",
+		"email protection", "encipher only", "ipsec end system", "ipsec tunnel",
+		"ipsec user", "key agreement", "key encipherment", "microsoft sgc",
+		"netscape sgc", "ocsp signing", "s/mime", "server auth", "signing",
+		"timestamping",
+	}
+
 	tests := map[string]struct {
 		policy      *policyapi.CertificateRequestPolicy
 		expResponse approver.WebhookValidationResponse
@@ -90,10 +100,10 @@ func Test_Validate(t *testing.T) {
 				Spec: policyapi.CertificateRequestPolicySpec{
 					Allowed: &policyapi.CertificateRequestPolicyAllowed{
 						CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("")},
-						DNSNames:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{}},
-						IPAddresses:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{}},
-						URIs:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{}},
-						EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{}},
+						DNSNames:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+						IPAddresses:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+						URIs:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+						EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
 						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
 							Organizations:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(false), Values: &[]string{}},
 							Countries:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(false), Values: &[]string{}},
@@ -102,7 +112,7 @@ func Test_Validate(t *testing.T) {
 							Provinces:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(false), Values: nil},
 							StreetAddresses:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(false), Values: &[]string{}},
 							PostalCodes:         &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(false), Values: nil},
-							SerialNumber:        &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("")},
+							SerialNumber:        &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("device-*")},
 						},
 					},
 				},
@@ -113,6 +123,33 @@ func Test_Validate(t *testing.T) {
 			},
 		},
 		"if policy contains all required but values are defined, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("")},
+						DNSNames:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+						IPAddresses:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+						URIs:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+						EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							Organizations:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+							Countries:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+							OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+							Localities:          &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+							Provinces:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+							StreetAddresses:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+							PostalCodes:         &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{"*"}},
+							SerialNumber:        &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("device-*")},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy contains all required but values are empty, expect a Allowed=false response": {
 			policy: &policyapi.CertificateRequestPolicy{
 				Spec: policyapi.CertificateRequestPolicySpec{
 					Allowed: &policyapi.CertificateRequestPolicyAllowed{
@@ -129,7 +166,154 @@ func Test_Validate(t *testing.T) {
 							Provinces:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{}},
 							StreetAddresses:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{}},
 							PostalCodes:         &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), Values: &[]string{}},
-							SerialNumber:        &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("")},
+							SerialNumber:        &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("device-*")},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.uris.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.emailAddresses.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.organizations.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.countries.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.organizationalUnits.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.localities.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.provinces.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.streetAddresses.values"), []string{}, "values must not be empty if required field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.postalCodes.values"), []string{}, "values must not be empty if required field"),
+				},
+			},
+		},
+		"if policy contains dnsNames values with a regex that fails to compile, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com", `regex:[a-z0-9-]+-prod\.example\.com`, "regex:[a-z"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values[2]"), "regex:[a-z", "error parsing regexp: missing closing ]: `[a-z`"),
+				},
+			},
+		},
+		"if policy contains dnsNames values with regexes that compile, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com", `regex:[a-z0-9-]+-prod\.example\.com`}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy sets caseInsensitive on commonName and emailAddresses, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("admin"), CaseInsensitive: pointer.Bool(true)},
+						EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"admin@example.com"}, CaseInsensitive: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy sets caseInsensitive on fields which don't support it, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, CaseInsensitive: pointer.Bool(true)},
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("serial"), CaseInsensitive: pointer.Bool(false)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.allowed.dnsNames.caseInsensitive"), "caseInsensitive is not supported on this field"),
+					field.Forbidden(field.NewPath("spec.allowed.subject.serialNumber.caseInsensitive"), "caseInsensitive is not supported on this field"),
+				},
+			},
+		},
+		"if policy sets includeCommonName on ipAddresses, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8"}, IncludeCommonName: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy sets includeCommonName on fields which don't support it, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, IncludeCommonName: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.allowed.dnsNames.includeCommonName"), "includeCommonName is not supported on this field"),
+				},
+			},
+		},
+		"if policy sets normalizeIDNA on dnsNames, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"bücher.example.com"}, NormalizeIDNA: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy sets normalizeIDNA on fields which don't support it, or with a Regex matchType, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{`[a-z]+\.example\.com`}, MatchType: matchType(policyapi.AllowedMatchTypeRegex), NormalizeIDNA: pointer.Bool(true)},
+						URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://example.com/*"}, NormalizeIDNA: pointer.Bool(true)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.allowed.dnsNames.normalizeIDNA"), "normalizeIDNA may not be set with a Regex matchType"),
+					field.Forbidden(field.NewPath("spec.allowed.uris.normalizeIDNA"), "normalizeIDNA is not supported on this field"),
+				},
+			},
+		},
+		"if policy sets otherNames with valid OIDs, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						OtherNames: []policyapi.CertificateRequestPolicyAllowedOtherName{
+							{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@example.com"}},
+							{OID: "1.3.6.1.5.5.7.8.9", Values: []string{"user@example.com"}},
 						},
 					},
 				},
@@ -139,11 +323,574 @@ func Test_Validate(t *testing.T) {
 				Errors:  nil,
 			},
 		},
+		"if policy sets otherNames with malformed or duplicate OIDs, or without values, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						OtherNames: []policyapi.CertificateRequestPolicyAllowedOtherName{
+							{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@example.com"}},
+							{OID: "upn", Values: []string{"*@example.com"}},
+							{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@example.org"}},
+							{OID: "1.3.6.1.5.5.7.8.9"},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.otherNames[1].oid"), "upn", "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.311.20.2.3"),
+					field.Duplicate(field.NewPath("spec.allowed.otherNames[2].oid"), "1.3.6.1.4.1.311.20.2.3"),
+					field.Required(field.NewPath("spec.allowed.otherNames[3].values"), "values must contain at least one value"),
+				},
+			},
+		},
+		"if policy contains dnsNames, ipAddresses or uris with only negated values, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"!internal.example.com"}},
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"!10.0.0.1", "!10.0.0.2"}},
+						URIs:        &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/*", "!spiffe://cluster.local/ns/foo/*"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values"), []string{"!internal.example.com"}, `must contain at least one value which is not negated with "!", otherwise all requests are denied`),
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values"), []string{"!10.0.0.1", "!10.0.0.2"}, `must contain at least one value which is not negated with "!", otherwise all requests are denied`),
+				},
+			},
+		},
+		"if policy contains a negated dnsNames regex that fails to compile, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com", "!regex:[a-z"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values[1]"), "!regex:[a-z", "error parsing regexp: missing closing ]: `[a-z`"),
+				},
+			},
+		},
+		"if policy contains usages which are not known key usages, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Usages: &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, "*", "foo"},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.allowed.usages[1]"), cmapi.KeyUsage("*"), knownUsageStrings),
+					field.NotSupported(field.NewPath("spec.allowed.usages[2]"), cmapi.KeyUsage("foo"), knownUsageStrings),
+				},
+			},
+		},
+		"if policy contains exactUsages but no usages, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Usages:      &[]cmapi.KeyUsage{},
+						ExactUsages: pointer.Bool(true),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.allowed.usages"), "usages must be defined if exactUsages is true"),
+				},
+			},
+		},
+		"if policy contains exactUsages with known usages, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Usages:      &[]cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment},
+						ExactUsages: pointer.Bool(true),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy contains ipAddresses with malformed CIDRs, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8", "10.0.0.0/33", "!fd00::/129", "10.0.0.1"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values[1]"), "10.0.0.0/33", "invalid CIDR address: 10.0.0.0/33"),
+					field.Invalid(field.NewPath("spec.allowed.ipAddresses.values[2]"), "!fd00::/129", "invalid CIDR address: fd00::/129"),
+				},
+			},
+		},
+		"if policy contains ipAddresses with valid IPv4 and IPv6 CIDRs, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8", "fd00::/8", "192.168.0.1"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		}, "if policy contains annotations with invalid keys or a required annotation without a value, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+							"example.com/ticket-id": {Required: pointer.Bool(true)},
+							"not a/valid/key":       {Value: pointer.String("*"), CaseInsensitive: pointer.Bool(true)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.annotations").Key("not a/valid/key"), "not a/valid/key", "a qualified name must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]') with an optional DNS subdomain prefix and '/' (e.g. 'example.com/MyName')"),
+					field.Required(field.NewPath("spec.allowed.annotations").Key("example.com/ticket-id").Child("value"), "value must be defined if required field"),
+					field.Forbidden(field.NewPath("spec.allowed.annotations").Key("not a/valid/key").Child("caseInsensitive"), "caseInsensitive is not supported on this field"),
+				},
+			},
+		},
+		"if policy contains valid annotations, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+							"example.com/ticket-id": {Value: pointer.String("TICKET-*"), Required: pointer.Bool(true)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		}, "if policy contains uris which don't parse as URIs, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						URIs: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/**", "spiffe://a b/ns/*", "!%zz"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.uris.values[1]"), "spiffe://a b/ns/*", `parse "spiffe://a b/ns/*": invalid character " " in host name`),
+					field.Invalid(field.NewPath("spec.allowed.uris.values[2]"), "!%zz", `parse "%zz": invalid URL escape "%zz"`),
+				},
+			},
+		},
+		"if policy contains valid uri patterns, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						URIs: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*", "spiffe://*/ns/*/sa/*", "spiffe://cluster.local/**", "!spiffe://cluster.local/ns/kube-system/**"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy sets commonName matchDNSNames and required without a value, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						CommonName: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), MatchDNSNames: pointer.Bool(true)},
+						DNSNames:   &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy sets emailAddresses allowedDomains and required without values, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), AllowedDomains: []string{"*.example.com", "example.com"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy sets invalid emailAddresses allowedDomains, or allowedDomains on fields which don't support it, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}, AllowedDomains: []string{"example.com"}},
+						EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{AllowedDomains: []string{"*.example.com", "user@example.com", ""}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.allowed.dnsNames.allowedDomains"), "allowedDomains is not supported on this field"),
+					field.Invalid(field.NewPath("spec.allowed.emailAddresses.allowedDomains").Index(1), "user@example.com", "domain must not contain \"@\""),
+					field.Invalid(field.NewPath("spec.allowed.emailAddresses.allowedDomains").Index(2), "", "domain must not be empty"),
+				},
+			},
+		},
+		"if policy sets matchDNSNames on fields which don't support it, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+							"example.com/ticket-id": {Required: pointer.Bool(true), MatchDNSNames: pointer.Bool(true)},
+						},
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("serial"), MatchDNSNames: pointer.Bool(false)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.allowed.subject.serialNumber.matchDNSNames"), "matchDNSNames is not supported on this field"),
+					field.Required(field.NewPath("spec.allowed.annotations").Key("example.com/ticket-id").Child("value"), "value must be defined if required field"),
+					field.Forbidden(field.NewPath("spec.allowed.annotations").Key("example.com/ticket-id").Child("matchDNSNames"), "matchDNSNames is not supported on this field"),
+				},
+			},
+		},
+		"if policy sets matchType on dnsNames and uris with valid values, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{`[a-z]+\.example\.com`, `!internal\..*`}, MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+						URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/"}, MatchType: matchType(policyapi.AllowedMatchTypePrefix)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy sets a Regex matchType with invalid patterns, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{`[a-z]+\.example\.com`, "!a)|(b"}, MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+						URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://[a-z"}, MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values[1]"), "!a)|(b", "error parsing regexp: unexpected ): `a)|(b`"),
+					field.Invalid(field.NewPath("spec.allowed.uris.values[0]"), "spiffe://[a-z", "error parsing regexp: missing closing ]: `[a-z`"),
+				},
+			},
+		},
+		"if policy sets an unknown matchType, or matchType on fields which don't support it, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, MatchType: matchType("Glob")},
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.1"}, MatchType: matchType(policyapi.AllowedMatchTypeExact)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.allowed.dnsNames.matchType"), policyapi.AllowedMatchType("Glob"), []string{"Exact", "Prefix", "Regex", "Suffix", "Wildcard"}),
+					field.Forbidden(field.NewPath("spec.allowed.ipAddresses.matchType"), "matchType is not supported on this field"),
+				},
+			},
+		},
+		"if dnsNames and uris values are templates referencing the namespace and its labels, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.{{ .Namespace }}.svc.cluster.local", `*.{{ index .Labels "team" }}.example.com`}},
+						URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/{{ .Namespace }}/sa/*"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if dnsNames and uris values are templates which are invalid or reference unknown fields, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.{{ .Namespace }.svc.cluster.local", "*.{{ .Name }}.example.com"}},
+						URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/ns/{{ .Namespace.Name }}/sa/*"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values[0]"), "*.{{ .Namespace }.svc.cluster.local", `template: value:1: unexpected "}" in operand`),
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.values[1]"), "*.{{ .Name }}.example.com", `template: value:1:5: executing "value" at <.Name>: can't evaluate field Name in type allowed.templateData`),
+					field.Invalid(field.NewPath("spec.allowed.uris.values[0]"), "spiffe://cluster.local/ns/{{ .Namespace.Name }}/sa/*", `template: value:1:39: executing "value" at <.Namespace.Name>: can't evaluate field Name in type string`),
+				},
+			},
+		},
+		"if dnsNames is required with only valuesFrom of an existing ConfigMap key, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true), ValuesFrom: valuesFrom("dnsNames")},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if dnsNames valuesFrom references a ConfigMap which doesn't exist yet, expect a Allowed=true response with a warning": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{
+							ValuesFrom: &policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "not-found", Key: "dnsNames"},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+				Warnings: []string{
+					"spec.allowed.dnsNames.valuesFrom: ConfigMap cert-manager/not-found does not exist, requests are not evaluated against this policy until it does",
+				},
+			},
+		},
+		"if dnsNames valuesFrom references a key which doesn't exist yet, expect a Allowed=true response with a warning": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{ValuesFrom: valuesFrom("not-a-key")},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+				Warnings: []string{
+					`spec.allowed.dnsNames.valuesFrom: ConfigMap cert-manager/allowed-dns-names has no key "not-a-key", requests are not evaluated against this policy until it does`,
+				},
+			},
+		},
+		"if valuesFrom is malformed or used on an unsupported field, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{
+							ValuesFrom: &policyapi.CertificateRequestPolicyValuesFrom{Namespace: "Cert_Manager", Name: "allowed-dns-names"},
+						},
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{ValuesFrom: valuesFrom("dnsNames")},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.dnsNames.valuesFrom.namespace"), "Cert_Manager", "a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')"),
+					field.Required(field.NewPath("spec.allowed.dnsNames.valuesFrom.key"), "must be defined"),
+					field.Forbidden(field.NewPath("spec.allowed.ipAddresses.valuesFrom"), "valuesFrom is not supported on this field"),
+				},
+			},
+		},
+		"if policy requires serialNumber with an empty value, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("")},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.serialNumber.value"), "", "value must not be empty if required field"),
+				},
+			},
+		},
+		"if policy defines a serialNumber regex that fails to compile, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("device-[0-9"), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.subject.serialNumber.value"), "device-[0-9", "error parsing regexp: missing closing ]: `[0-9`"),
+				},
+			},
+		},
+		"if policy defines a serialNumber regex that compiles, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Required: pointer.Bool(true), Value: pointer.String("device-[0-9]+"), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy defines a Regex matchType on commonName, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String(`(admin|root)-[0-9]+`), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy defines a Regex matchType on commonName which anchors only some alternatives, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						CommonName: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String(`^admin|root`), MatchType: matchType(policyapi.AllowedMatchTypeRegex)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.allowed.commonName.value"), `^admin|root`, `"^admin|root" anchors only some alternatives of an alternation with "^" or "$": expressions are always anchored to match the whole value, so remove the explicit anchors`),
+				},
+			},
+		},
+		"if policy sets registered matchers on fields which support them, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"regex:[a-z"}, Matcher: pointer.String("test-case-insensitive")},
+						URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/*"}, Matcher: pointer.String("wildcard")},
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							SerialNumber: &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("device-1234"), Matcher: pointer.String("test-case-insensitive")},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy sets an unregistered matcher, a matcher with matchType, or a matcher on fields which don't support it, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, Matcher: pointer.String("unknown")},
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.1"}, Matcher: pointer.String("wildcard")},
+						URIs:        &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://cluster.local/*"}, Matcher: pointer.String("wildcard"), MatchType: matchType(policyapi.AllowedMatchTypeExact)},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.allowed.dnsNames.matcher"), "unknown", []string{"test-case-insensitive", "wildcard"}),
+					field.Forbidden(field.NewPath("spec.allowed.ipAddresses.matcher"), "matcher is not supported on this field"),
+					field.Forbidden(field.NewPath("spec.allowed.uris.matcher"), "matcher may not be set with matchType"),
+				},
+			},
+		},
+		"if policy sets invalid minEntries and maxEntries, or sets them on fields which don't support them, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, MinEntries: pointer.Int(1), MaxEntries: pointer.Int(1)},
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, MinEntries: pointer.Int(2), MaxEntries: pointer.Int(1)},
+							Countries:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, MinEntries: pointer.Int(-1), MaxEntries: pointer.Int(-1)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.allowed.dnsNames.minEntries"), "minEntries is not supported on this field"),
+					field.Forbidden(field.NewPath("spec.allowed.dnsNames.maxEntries"), "maxEntries is not supported on this field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.organizations.maxEntries"), 1, "maxEntries must be the same value as minEntries or larger"),
+					field.Invalid(field.NewPath("spec.allowed.subject.countries.minEntries"), -1, "minEntries must be a value greater or equal to 0"),
+					field.Invalid(field.NewPath("spec.allowed.subject.countries.maxEntries"), -1, "maxEntries must be a value greater or equal to 0"),
+				},
+			},
+		},
+		"if policy sets ordered on fields which don't support it, or with fewer values than minEntries, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, Ordered: pointer.Bool(true)},
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							Organizations:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Ordered: pointer.Bool(false)},
+							OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering"}, MinEntries: pointer.Int(2), Ordered: pointer.Bool(true)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec.allowed.dnsNames.ordered"), "ordered is not supported on this field"),
+					field.Forbidden(field.NewPath("spec.allowed.subject.organizations.ordered"), "ordered is not supported on this field"),
+					field.Invalid(field.NewPath("spec.allowed.subject.organizationalUnits.values"), []string{"engineering"}, "values must contain at least minEntries values if ordered"),
+				},
+			},
+		},
+		"if policy sets ordered organizationalUnits with at least minEntries values, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "team-*"}, MinEntries: pointer.Int(2), Ordered: pointer.Bool(true)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy requires exactly one organization, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, MinEntries: pointer.Int(1), MaxEntries: pointer.Int(1)},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			response, err := allowed{}.Validate(context.TODO(), test.policy)
+			response, err := (&allowed{lister: valuesFromClient(t)}).Validate(context.TODO(), test.policy)
 			assert.NoError(t, err)
 			assert.Equal(t, test.expResponse, response)
 		})
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allowed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// values returns the allowed values of the string slice, including those
+// loaded from the ConfigMap key referenced by `valuesFrom`. Returns nil if
+// the slice defines neither.
+func (a *allowed) values(ctx context.Context, slice *policyapi.CertificateRequestPolicyAllowedStringSlice) (*[]string, error) {
+	if slice == nil {
+		return nil, nil
+	}
+	if slice.ValuesFrom == nil {
+		return slice.Values, nil
+	}
+
+	loaded, err := loadValuesFrom(ctx, a.lister, slice.ValuesFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	values := append(append([]string{}, deref(slice.Values)...), loaded...)
+	return &values, nil
+}
+
+// loadValuesFrom returns the values contained in the referenced ConfigMap
+// key. Returns an error if the ConfigMap or key doesn't exist, so that the
+// request is evaluated again once it does.
+func loadValuesFrom(ctx context.Context, lister client.Reader, ref *policyapi.CertificateRequestPolicyValuesFrom) ([]string, error) {
+	if lister == nil {
+		return nil, errors.New("valuesFrom can't be loaded since the allowed approver has not been prepared")
+	}
+
+	values, err := util.LoadValuesFrom(ctx, lister, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load valuesFrom: %w", err)
+	}
+	return values, nil
+}
+
+// valuesFromWarning returns a warning if the referenced ConfigMap or key
+// doesn't exist yet. Returns no warning if the approver has not been
+// prepared, so can't check.
+func (a *allowed) valuesFromWarning(ctx context.Context, fldPath *field.Path, ref *policyapi.CertificateRequestPolicyValuesFrom) (string, error) {
+	if a.lister == nil {
+		return "", nil
+	}
+
+	var cm corev1.ConfigMap
+	err := a.lister.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, &cm)
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Sprintf("%s: ConfigMap %s/%s does not exist, requests are not evaluated against this policy until it does", fldPath, ref.Namespace, ref.Name), nil
+	case err != nil:
+		return "", fmt.Errorf("failed to get valuesFrom ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	if _, ok := cm.Data[ref.Key]; !ok {
+		return fmt.Sprintf("%s: ConfigMap %s/%s has no key %q, requests are not evaluated against this policy until it does", fldPath, ref.Namespace, ref.Name, ref.Key), nil
+	}
+
+	return "", nil
+}
+
+// valuesDetail returns the detail of a denial by the allowed values of the
+// string slice, where values are the values returned by values. Values loaded
+// from a ConfigMap are described by their reference rather than listed, since
+// they may be numerous.
+func valuesDetail(slice *policyapi.CertificateRequestPolicyAllowedStringSlice, values []string) string {
+	if slice == nil || slice.ValuesFrom == nil {
+		return strings.Join(values, ", ")
+	}
+	ref := slice.ValuesFrom
+	return strings.Join(append(append([]string{}, deref(slice.Values)...), fmt.Sprintf("values of ConfigMap %s/%s key %q", ref.Namespace, ref.Name, ref.Key)), ", ")
+}
+
+// deref returns the values of the given pointer, or nil.
+func deref(values *[]string) []string {
+	if values == nil {
+		return nil
+	}
+	return *values
+}
@@ -21,6 +21,8 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/pflag"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
@@ -30,42 +32,54 @@ import (
 
 // Load the constraints approver.
 func init() {
-	registry.Shared.Store(constraints{})
+	registry.Shared.Store(&constraints{clock: clock.RealClock{}})
 }
 
 // Approver returns an instance on the constraints approver.
 func Approver() approver.Interface {
-	return constraints{}
+	return &constraints{clock: clock.RealClock{}}
 }
 
 // constraints is a base approver-policy Approver that is responsible for
 // ensuring incoming requests satisfy the constraints defined on
 // CertificateRequestPolicies. It is expected that constraints must _always_ be
 // registered for all approver-policy builds.
-type constraints struct{}
+type constraints struct {
+	// lister is used to get the issuer referenced by requests, whose
+	// IssuerMaxDurationAnnotationKey annotation caps the requested duration,
+	// and to load `blockedPublicKeyHashes`. Set once the approver is prepared.
+	// If unset, the annotation is ignored.
+	lister client.Reader
+
+	// clock is used to estimate the expiry of requests which have not yet
+	// been created, for `expiryAlignment`.
+	clock clock.Clock
+}
 
 // Name of Approver is "constraints"
-func (c constraints) Name() string {
+func (c *constraints) Name() string {
 	return "constraints"
 }
 
 // RegisterFlags is a no-op, constraints doesn't need any flags.
-func (c constraints) RegisterFlags(_ *pflag.FlagSet) {
+func (c *constraints) RegisterFlags(_ *pflag.FlagSet) {
 	return
 }
 
-// Prepare is a no-op, constraints doesn't need to prepare anything.
-func (c constraints) Prepare(_ context.Context, _ logr.Logger, _ manager.Manager) error {
+// Prepare sets the lister which gets the issuers referenced by requests, and
+// the ConfigMaps referenced by policies, from the manager's cache.
+func (c *constraints) Prepare(_ context.Context, _ logr.Logger, mgr manager.Manager) error {
+	c.lister = mgr.GetCache()
 	return nil
 }
 
 // Ready always returns ready, constraints doesn't have any dependencies to
 // block readiness.
-func (c constraints) Ready(_ context.Context, _ *policyapi.CertificateRequestPolicy) (approver.ReconcilerReadyResponse, error) {
+func (c *constraints) Ready(_ context.Context, _ *policyapi.CertificateRequestPolicy) (approver.ReconcilerReadyResponse, error) {
 	return approver.ReconcilerReadyResponse{Ready: true}, nil
 }
 
 // constraints never needs to manually enqueue policies.
-func (c constraints) EnqueueChan() <-chan string {
+func (c *constraints) EnqueueChan() <-chan string {
 	return nil
 }
@@ -21,16 +21,24 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
-	utilpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 )
 
 // Evaluate evaluates whether the given CertificateRequest satisfies the
@@ -39,12 +47,7 @@ import (
 // permitted by the passed policy.
 // If the request is denied by the constraints an explanation is returned.
 // An error signals that the policy couldn't be evaluated to completion.
-func (c constraints) Evaluate(_ context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
-	// If no constraints defined, exit early.
-	if policy.Spec.Constraints == nil {
-		return approver.EvaluationResponse{Result: approver.ResultNotDenied, Message: ""}, nil
-	}
-
+func (c *constraints) Evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
 	var (
 		// el will contain a list of policy violations for fields, if there are
 		// items in the list, then the request does not meet the constraints.
@@ -53,6 +56,31 @@ func (c constraints) Evaluate(_ context.Context, policy *policyapi.CertificateRe
 		fldPath = field.NewPath("spec", "constraints")
 	)
 
+	// The maximum duration set by the issuer applies regardless of the
+	// constraints defined.
+	issuerErrs, err := c.issuerMaxDuration(ctx, request)
+	if err != nil {
+		return approver.EvaluationResponse{}, err
+	}
+	el = append(el, issuerErrs...)
+
+	// If no constraints defined, no other constraint applies.
+	if consts == nil {
+		consts = new(policyapi.CertificateRequestPolicyConstraints)
+	}
+
+	// Only decode the CSR from the CertificateRequest if a defined constraint
+	// requires it.
+	var csr *x509.CertificateRequest
+	if needsCSR(consts) {
+		var err error
+		csr, err = util.DecodeCSR(request.Spec.Request)
+		if err != nil {
+			el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+			return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+		}
+	}
+
 	if consts.MaxDuration != nil {
 		// If the request contains no duration or the maxDuration is smaller than requested, append error.
 		if request.Spec.Duration == nil {
@@ -71,15 +99,89 @@ func (c constraints) Evaluate(_ context.Context, policy *policyapi.CertificateRe
 		}
 	}
 
-	if consts.PrivateKey != nil {
-		fldPath := fldPath.Child("privateKey")
+	if durations := consts.AllowedDurations; durations != nil {
+		fldPath := fldPath.Child("allowedDurations")
+		var tolerance time.Duration
+		if consts.AllowedDurationsTolerance != nil {
+			tolerance = consts.AllowedDurationsTolerance.Duration
+		}
+		// If the request contains no duration, or the duration isn't one of
+		// the allowed durations within the tolerance, append error.
+		if request.Spec.Duration == nil {
+			el = append(el, field.Invalid(fldPath, request.Spec.Duration.String(), allowedDurationsDetail(durations, tolerance)))
+		} else if !durationAllowed(request.Spec.Duration.Duration, durations, tolerance) {
+			el = append(el, field.Invalid(fldPath, request.Spec.Duration.Duration.String(), allowedDurationsDetail(durations, tolerance)))
+		}
+	}
 
-		// Decode CSR from CertificateRequest
-		csr, err := utilpki.DecodeX509CertificateRequestBytes(request.Spec.Request)
+	if namespaces := consts.AllowedNamespaces; namespaces != nil {
+		if !util.WildcardContains(namespaces, request.Namespace) {
+			el = append(el, field.Invalid(fldPath.Child("allowedNamespaces"), request.Namespace, strings.Join(namespaces, ", ")))
+		}
+	}
+
+	if consts.ExpiryAlignment != nil {
+		fldPath := fldPath.Child("expiryAlignment")
+		alignment := *consts.ExpiryAlignment
+		period, ok := alignmentPeriod(alignment)
+		switch {
+		case !ok:
+			el = append(el, field.NotSupported(fldPath, alignment, []string{string(policyapi.ExpiryAlignmentMidnight), string(policyapi.ExpiryAlignmentHour)}))
+		case request.Spec.Duration == nil:
+			// If the request contains no duration, its expiry can't be known.
+			el = append(el, field.Invalid(fldPath, request.Spec.Duration.String(), string(alignment)))
+		default:
+			tolerance := policyapi.DefaultExpiryAlignmentTolerance
+			if consts.ExpiryAlignmentTolerance != nil {
+				tolerance = consts.ExpiryAlignmentTolerance.Duration
+			}
+			// The request has not yet been signed, so the expiry is estimated
+			// from when the request was created.
+			created := request.CreationTimestamp.Time
+			if created.IsZero() {
+				created = c.clock.Now()
+			}
+			notAfter := created.Add(request.Spec.Duration.Duration)
+			if alignmentOffset(notAfter, period) > tolerance {
+				el = append(el, field.Invalid(fldPath, notAfter.UTC().Format(time.RFC3339), fmt.Sprintf("expiry must be aligned to %s within a tolerance of %s", alignment, tolerance)))
+			}
+		}
+	}
+
+	if consts.MaxBackdate != nil {
+		// Requests which don't declare a backdate are taken to not be
+		// backdated. A negative backdate sets notBefore in the future.
+		if value, ok := request.Annotations[policyapi.BackdateAnnotationKey]; ok {
+			fldPath := fldPath.Child("maxBackdate")
+			backdate, err := time.ParseDuration(value)
+			if err != nil {
+				el = append(el, field.Invalid(fldPath, value, fmt.Sprintf("%s annotation must be a duration", policyapi.BackdateAnnotationKey)))
+			} else if backdate > consts.MaxBackdate.Duration {
+				el = append(el, field.Invalid(fldPath, backdate.String(), consts.MaxBackdate.Duration.String()))
+			}
+		}
+	}
+
+	if len(consts.DurationByKeySize) > 0 {
+		alg, size, err := decodePublicKey(csr.PublicKey)
 		if err != nil {
 			return approver.EvaluationResponse{}, err
 		}
 
+		if i, ok := durationByKeySizeRule(consts.DurationByKeySize, alg, size); ok {
+			fldPath := fldPath.Child("durationByKeySize").Index(i).Child("maxDuration")
+			maxDuration := consts.DurationByKeySize[i].MaxDuration.Duration
+			if request.Spec.Duration == nil {
+				el = append(el, field.Invalid(fldPath, request.Spec.Duration.String(), maxDuration.String()))
+			} else if maxDuration < request.Spec.Duration.Duration {
+				el = append(el, field.Invalid(fldPath, request.Spec.Duration.Duration.String(), maxDuration.String()))
+			}
+		}
+	}
+
+	if consts.PrivateKey != nil {
+		fldPath := fldPath.Child("privateKey")
+
 		alg, size, err := decodePublicKey(csr.PublicKey)
 		if err != nil {
 			return approver.EvaluationResponse{}, err
@@ -89,24 +191,553 @@ func (c constraints) Evaluate(_ context.Context, policy *policyapi.CertificateRe
 			el = append(el, field.Invalid(fldPath.Child("algorithm"), string(alg), string(*consts.PrivateKey.Algorithm)))
 		}
 
-		if consts.PrivateKey.MaxSize != nil && *consts.PrivateKey.MaxSize < size {
-			el = append(el, field.Invalid(fldPath.Child("maxSize"), strconv.Itoa(size), strconv.Itoa(*consts.PrivateKey.MaxSize)))
+		// Ed25519 keys have a fixed size, so size constraints are ignored.
+		if alg != cmapi.Ed25519KeyAlgorithm {
+			if consts.PrivateKey.MaxSize != nil && *consts.PrivateKey.MaxSize < size {
+				el = append(el, field.Invalid(fldPath.Child("maxSize"), strconv.Itoa(size), strconv.Itoa(*consts.PrivateKey.MaxSize)))
+			}
+
+			if consts.PrivateKey.MinSize != nil && *consts.PrivateKey.MinSize > size {
+				el = append(el, field.Invalid(fldPath.Child("minSize"), strconv.Itoa(size), strconv.Itoa(*consts.PrivateKey.MinSize)))
+			}
+		}
+
+		if exponents := consts.PrivateKey.AllowedRSAPublicExponents; exponents != nil {
+			if rsapub, ok := csr.PublicKey.(*rsa.PublicKey); ok {
+				var (
+					allowed   bool
+					expectedE []string
+				)
+				for _, e := range *exponents {
+					if e == rsapub.E {
+						allowed = true
+					}
+					expectedE = append(expectedE, strconv.Itoa(e))
+				}
+				if !allowed {
+					el = append(el, field.Invalid(fldPath.Child("allowedRSAPublicExponents"), strconv.Itoa(rsapub.E), strings.Join(expectedE, ", ")))
+				}
+			}
+		}
+
+		if curves := consts.PrivateKey.AllowedECDSACurves; curves != nil {
+			if ecdsapub, ok := csr.PublicKey.(*ecdsa.PublicKey); ok {
+				curve := ecdsapub.Curve.Params().Name
+				var allowed bool
+				for _, c := range *curves {
+					if c == curve {
+						allowed = true
+						break
+					}
+				}
+				if !allowed {
+					el = append(el, field.Invalid(fldPath.Child("allowedECDSACurves"), curve, strings.Join(*curves, ", ")))
+				}
+			}
+		}
+	}
+
+	if consts.MaxSANCount != nil {
+		maxCount := *consts.MaxSANCount
+		sanCounts := []struct {
+			name  string
+			count int
+		}{
+			{"dnsNames", len(csr.DNSNames)},
+			{"ipAddresses", len(csr.IPAddresses)},
+			{"uris", len(csr.URIs)},
+			{"emailAddresses", len(csr.EmailAddresses)},
+		}
+
+		if consts.MaxSANCountPerType != nil && *consts.MaxSANCountPerType {
+			for _, san := range sanCounts {
+				if san.count > maxCount {
+					el = append(el, field.Invalid(fldPath.Child("maxSANCount"), fmt.Sprintf("%s: %d", san.name, san.count), strconv.Itoa(maxCount)))
+				}
+			}
+		} else {
+			var total int
+			for _, san := range sanCounts {
+				total += san.count
+			}
+			if total > maxCount {
+				el = append(el, field.Invalid(fldPath.Child("maxSANCount"), strconv.Itoa(total), strconv.Itoa(maxCount)))
+			}
+		}
+	}
+
+	if consts.ForbidDuplicateSANs != nil && *consts.ForbidDuplicateSANs {
+		var ips, uris []string
+		for _, ip := range csr.IPAddresses {
+			ips = append(ips, ip.String())
+		}
+		for _, uri := range csr.URIs {
+			uris = append(uris, uri.String())
+		}
+
+		sans := []struct {
+			sanType string
+			values  []string
+			// caseInsensitive marks whether values are compared regardless of
+			// case.
+			caseInsensitive bool
+		}{
+			{"DNS", csr.DNSNames, true},
+			{"IP", ips, false},
+			{"URI", uris, false},
+			{"Email", csr.EmailAddresses, true},
+		}
+		for _, san := range sans {
+			for _, value := range duplicates(san.values, san.caseInsensitive) {
+				el = append(el, field.Forbidden(fldPath.Child("forbidDuplicateSANs"), fmt.Sprintf("%s SAN %q is duplicated", san.sanType, value)))
+			}
+		}
+	}
+
+	if consts.ForbidWildcardDNSNames != nil && *consts.ForbidWildcardDNSNames {
+		fldPath := fldPath.Child("forbidWildcardDNSNames")
+		if strings.HasPrefix(csr.Subject.CommonName, "*.") {
+			el = append(el, field.Forbidden(fldPath, fmt.Sprintf("common name %q is a wildcard", csr.Subject.CommonName)))
+		}
+		for _, dnsName := range csr.DNSNames {
+			if strings.HasPrefix(dnsName, "*.") {
+				el = append(el, field.Forbidden(fldPath, fmt.Sprintf("DNS SAN %q is a wildcard", dnsName)))
+			}
+		}
+	}
+
+	if consts.RequireSANs != nil && *consts.RequireSANs {
+		// otherNames and other SAN types aren't decoded by the standard
+		// library, so the SANs of the extension are counted directly.
+		count, err := util.CountSANs(csr)
+		if err != nil {
+			el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+		} else if count == 0 {
+			el = append(el, field.Forbidden(fldPath.Child("requireSANs"), "request has no subject alternative names, which are required regardless of the common name"))
+		}
+	}
+
+	if consts.MaxDNSNameLabels != nil {
+		fldPath := fldPath.Child("maxDNSNameLabels")
+		for _, dnsName := range csr.DNSNames {
+			// The trailing dot of a fully qualified DNS name is not a label.
+			if labels := len(strings.Split(strings.TrimSuffix(dnsName, "."), ".")); labels > *consts.MaxDNSNameLabels {
+				el = append(el, field.Forbidden(fldPath, fmt.Sprintf("DNS SAN %q has %d labels, more than the maximum of %d", dnsName, labels, *consts.MaxDNSNameLabels)))
+			}
+		}
+	}
+
+	if len(consts.MaxSubjectEntries) > 0 {
+		fldPath := fldPath.Child("maxSubjectEntries")
+		for _, key := range sets.List(sets.KeySet(consts.MaxSubjectEntries)) {
+			entries, ok := subjectEntries[key]
+			if !ok {
+				continue
+			}
+			maxCount := consts.MaxSubjectEntries[key]
+			if count := len(entries(csr.Subject)); count > maxCount {
+				el = append(el, field.Invalid(fldPath.Key(key), strconv.Itoa(count), strconv.Itoa(maxCount)))
+			}
+		}
+	}
+
+	if consts.MaxCommonNameLength != nil {
+		if length := len(csr.Subject.CommonName); length > *consts.MaxCommonNameLength {
+			el = append(el, field.Invalid(fldPath.Child("maxCommonNameLength"), strconv.Itoa(length), strconv.Itoa(*consts.MaxCommonNameLength)))
+		}
+	}
+
+	if consts.MaxSubjectTotalLength != nil {
+		if length := len(csr.RawSubject); length > *consts.MaxSubjectTotalLength {
+			el = append(el, field.Invalid(fldPath.Child("maxSubjectTotalLength"), strconv.Itoa(length), strconv.Itoa(*consts.MaxSubjectTotalLength)))
+		}
+	}
+
+	if algs := consts.AllowedSignatureAlgorithms; algs != nil {
+		alg := signatureAlgorithmName(csr.SignatureAlgorithm)
+		if !sets.New(algs...).Has(alg) {
+			el = append(el, field.Invalid(fldPath.Child("allowedSignatureAlgorithms"), alg, strings.Join(algs, ", ")))
+		}
+	}
+
+	if sanTypes := consts.AllowedSANTypes; sanTypes != nil {
+		allowed := sets.New(sanTypes...)
+		for _, sanType := range sets.List(sets.KeySet(sanTypeCounts)) {
+			if !allowed.Has(sanType) && sanTypeCounts[sanType](csr) > 0 {
+				el = append(el, field.Forbidden(fldPath.Child("allowedSANTypes"), fmt.Sprintf("%s SANs are forbidden by this policy", sanType)))
+			}
+		}
+	}
+
+	if oids := consts.AllowedExtensionOIDs; oids != nil {
+		allowed := sets.New(oids...).Insert(defaultExtensionOIDs...)
+		for _, ext := range csr.Extensions {
+			if oid := ext.Id.String(); !allowed.Has(oid) {
+				el = append(el, field.Forbidden(fldPath.Child("allowedExtensionOIDs"), fmt.Sprintf("extension %s is forbidden by this policy", oid)))
+			}
+		}
+	}
+
+	if ref := consts.BlockedPublicKeyHashes; ref != nil {
+		errs, err := c.blockedPublicKeyHash(ctx, fldPath.Child("blockedPublicKeyHashes"), ref, csr)
+		if err != nil {
+			return approver.EvaluationResponse{}, err
+		}
+		el = append(el, errs...)
+	}
+
+	if consts.IsCA != nil {
+		expected := strconv.FormatBool(*consts.IsCA)
+		if request.Spec.IsCA != *consts.IsCA {
+			el = append(el, field.Invalid(fldPath.Child("isCA"), request.Spec.IsCA, expected))
+		}
+
+		// The extensions of the CSR are supplied by the requester, so a
+		// request whose basicConstraints can't be decoded is denied.
+		csrIsCA, _, ok, err := util.DecodeBasicConstraints(csr)
+		if err != nil {
+			el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+		} else if ok && csrIsCA != *consts.IsCA {
+			el = append(el, field.Invalid(fldPath.Child("isCA"), fmt.Sprintf("csr: %t", csrIsCA), expected))
 		}
+	}
+
+	if consts.MaxPathLen != nil && *consts.MaxPathLen >= 0 {
+		csrIsCA, pathLen, present, err := util.DecodeBasicConstraints(csr)
 
-		if consts.PrivateKey.MinSize != nil && *consts.PrivateKey.MinSize > size {
-			el = append(el, field.Invalid(fldPath.Child("minSize"), strconv.Itoa(size), strconv.Itoa(*consts.PrivateKey.MinSize)))
+		// Only CA requests have a path length. A CA request which doesn't
+		// request a path length has an unlimited path length, and one whose
+		// CSR has no basicConstraints extension can't request one.
+		if err != nil {
+			el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+		} else if request.Spec.IsCA || csrIsCA {
+			switch {
+			case !present:
+				el = append(el, field.Invalid(fldPath.Child("maxPathLen"), "missing basicConstraints", fmt.Sprintf("CA request must include a basicConstraints extension with a path length of at most %d", *consts.MaxPathLen)))
+			case !csrIsCA || pathLen < 0:
+				el = append(el, field.Invalid(fldPath.Child("maxPathLen"), "unlimited", strconv.Itoa(*consts.MaxPathLen)))
+			case pathLen > *consts.MaxPathLen:
+				el = append(el, field.Invalid(fldPath.Child("maxPathLen"), strconv.Itoa(pathLen), strconv.Itoa(*consts.MaxPathLen)))
+			}
 		}
 	}
 
+	if consts.RequireCASignUsageWhenCA != nil || consts.RequireCRLSignUsageWhenCA != nil {
+		el = append(el, caUsagesRequired(fldPath, consts, request, csr)...)
+	}
+
 	// If there are errors, then return not approved and the aggregated errors
 	if len(el) > 0 {
-		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error()}, nil
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
 	}
 
 	// If no evaluation errors resulting from this policy, return not denied
 	return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
 }
 
+// needsCSR returns whether any of the defined constraints inspects the CSR of
+// the request, so that the CSR need only be decoded when it is required. New
+// constraints which inspect the CSR must be added here.
+func needsCSR(consts *policyapi.CertificateRequestPolicyConstraints) bool {
+	csrConstraints := []bool{
+		consts.PrivateKey != nil,
+		consts.MaxSANCount != nil,
+		consts.IsCA != nil,
+		consts.MaxPathLen != nil,
+		len(consts.MaxSubjectEntries) > 0,
+		consts.MaxCommonNameLength != nil,
+		consts.MaxSubjectTotalLength != nil,
+		consts.AllowedSignatureAlgorithms != nil,
+		consts.AllowedSANTypes != nil,
+		consts.AllowedExtensionOIDs != nil,
+		consts.ForbidDuplicateSANs != nil,
+		consts.ForbidWildcardDNSNames != nil,
+		consts.MaxDNSNameLabels != nil,
+		consts.RequireSANs != nil,
+		len(consts.DurationByKeySize) > 0,
+		consts.BlockedPublicKeyHashes != nil,
+		consts.RequireCASignUsageWhenCA != nil,
+		consts.RequireCRLSignUsageWhenCA != nil,
+	}
+	for _, needs := range csrConstraints {
+		if needs {
+			return true
+		}
+	}
+	return false
+}
+
+// issuerMaxDuration returns an error if the duration of the request exceeds
+// the IssuerMaxDurationAnnotationKey annotation of the cert-manager.io Issuer
+// or ClusterIssuer it references. Requests of issuers without the annotation,
+// or which don't exist, are not capped. Requests which don't request a
+// duration are issued with cert-manager's default duration, so that is
+// compared with the cap instead. If the approver isn't prepared, the
+// annotation is ignored.
+// The issuer is shared by every policy evaluated for the request, so is got
+// from the request's issuer metadata cache when the manager provides one.
+func (c *constraints) issuerMaxDuration(ctx context.Context, request *cmapi.CertificateRequest) (field.ErrorList, error) {
+	if c.lister == nil {
+		return nil, nil
+	}
+
+	issuer, found, err := util.GetIssuerMetadata(ctx, c.lister, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get request's issuer to determine its maximum duration: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	value, ok := issuer.Annotations[policyapi.IssuerMaxDurationAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+
+	fldPath := field.NewPath("spec", "duration")
+	maxDuration, err := time.ParseDuration(value)
+	if err != nil {
+		return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("%s annotation of issuer %q must be a duration: %q", policyapi.IssuerMaxDurationAnnotationKey, issuer.Name, value))}, nil
+	}
+	duration := cmapi.DefaultCertificateDuration
+	if request.Spec.Duration != nil {
+		duration = request.Spec.Duration.Duration
+	}
+	if duration > maxDuration {
+		return field.ErrorList{field.Invalid(fldPath, duration.String(), fmt.Sprintf("issuer %q has a maximum duration of %s", issuer.Name, maxDuration))}, nil
+	}
+	return nil, nil
+}
+
+// blockedPublicKeyHash returns an error if the SHA-256 hash of the public key
+// of the CSR is listed in the referenced ConfigMap key. Returns an error if
+// the hashes can't be loaded, so that the request is evaluated again once they
+// can.
+func (c *constraints) blockedPublicKeyHash(ctx context.Context, fldPath *field.Path, ref *policyapi.CertificateRequestPolicyValuesFrom, csr *x509.CertificateRequest) (field.ErrorList, error) {
+	if c.lister == nil {
+		return nil, errors.New("blockedPublicKeyHashes can't be loaded since the constraints approver has not been prepared")
+	}
+
+	hashes, err := util.LoadValuesFrom(ctx, c.lister, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blockedPublicKeyHashes: %w", err)
+	}
+
+	hash := publicKeyHash(csr)
+	for _, blocked := range hashes {
+		if strings.EqualFold(blocked, hash) {
+			return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("public key with SHA-256 hash %s is blocked by this policy", hash))}, nil
+		}
+	}
+	return nil, nil
+}
+
+// publicKeyHash returns the hex encoded SHA-256 hash of the DER encoded
+// SubjectPublicKeyInfo of the CSR.
+func publicKeyHash(csr *x509.CertificateRequest) string {
+	sum := sha256.Sum256(csr.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:])
+}
+
+// caUsagesRequired returns errors if the request is a CA request, but doesn't
+// request the usages which the constraints require of CA requests, in either
+// `spec.usages` or the CSR. Extensions of the CSR which can't be decoded are
+// returned as errors of the request.
+func caUsagesRequired(fldPath *field.Path, consts *policyapi.CertificateRequestPolicyConstraints, request *cmapi.CertificateRequest, csr *x509.CertificateRequest) field.ErrorList {
+	csrIsCA, _, _, err := util.DecodeBasicConstraints(csr)
+	if err != nil {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "request"), err.Error())}
+	}
+	if !request.Spec.IsCA && !csrIsCA {
+		return nil
+	}
+
+	csrUsages, err := util.DecodeUsages(csr)
+	if err != nil {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "request"), err.Error())}
+	}
+	usages := sets.New(request.Spec.Usages...).Insert(csrUsages...)
+
+	var el field.ErrorList
+	for _, required := range []struct {
+		name     string
+		required *bool
+		usage    cmapi.KeyUsage
+	}{
+		{"requireCASignUsageWhenCA", consts.RequireCASignUsageWhenCA, cmapi.UsageCertSign},
+		{"requireCRLSignUsageWhenCA", consts.RequireCRLSignUsageWhenCA, cmapi.UsageCRLSign},
+	} {
+		if required.required != nil && *required.required && !usages.Has(required.usage) {
+			el = append(el, field.Forbidden(fldPath.Child(required.name), fmt.Sprintf("CA requests must request the %q usage", required.usage)))
+		}
+	}
+	return el
+}
+
+// durationAllowed returns whether the duration is within the tolerance of any
+// of the allowed durations.
+func durationAllowed(duration time.Duration, allowed []metav1.Duration, tolerance time.Duration) bool {
+	for _, allowed := range allowed {
+		offset := duration - allowed.Duration
+		if offset < 0 {
+			offset = -offset
+		}
+		if offset <= tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedDurationsDetail returns the detail of an error for a duration which
+// isn't one of the allowed durations.
+func allowedDurationsDetail(allowed []metav1.Duration, tolerance time.Duration) string {
+	values := make([]string, 0, len(allowed))
+	for _, duration := range allowed {
+		values = append(values, duration.Duration.String())
+	}
+	if tolerance > 0 {
+		return fmt.Sprintf("must be within %s of one of %s", tolerance, strings.Join(values, ", "))
+	}
+	return fmt.Sprintf("must be one of %s", strings.Join(values, ", "))
+}
+
+// alignmentPeriod returns the period between the boundaries of the given
+// expiry alignment. Returns false if the alignment is unknown.
+func alignmentPeriod(alignment policyapi.ExpiryAlignment) (time.Duration, bool) {
+	switch alignment {
+	case policyapi.ExpiryAlignmentMidnight:
+		return 24 * time.Hour, true
+	case policyapi.ExpiryAlignmentHour:
+		return time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
+// alignmentOffset returns how far the given time is from the nearest boundary
+// of the given period, either before or after it. Boundaries are aligned to
+// the zero time, i.e. midnight UTC.
+func alignmentOffset(t time.Time, period time.Duration) time.Duration {
+	offset := t.Sub(t.Truncate(period))
+	if offset > period/2 {
+		offset = period - offset
+	}
+	return offset
+}
+
+// durationByKeySizeRule returns the index of the most specific
+// durationByKeySize rule which matches a key of the given algorithm and size.
+// Rules with an algorithm are more specific than rules without, and of those,
+// rules with a larger minSize are more specific. Keys without a size, i.e.
+// Ed25519 keys, only match rules without a minSize. Returns false if no rule
+// matches.
+func durationByKeySizeRule(rules []policyapi.CertificateRequestPolicyDurationByKeySize, alg cmapi.PrivateKeyAlgorithm, size int) (int, bool) {
+	var (
+		match   = -1
+		matchBy struct {
+			algorithm bool
+			minSize   int
+		}
+	)
+	for i, rule := range rules {
+		if rule.Algorithm != nil && *rule.Algorithm != alg {
+			continue
+		}
+		minSize := -1
+		if rule.MinSize != nil {
+			minSize = *rule.MinSize
+			if size < minSize {
+				continue
+			}
+		}
+
+		algorithm := rule.Algorithm != nil
+		if match >= 0 && ((matchBy.algorithm && !algorithm) || (matchBy.algorithm == algorithm && matchBy.minSize >= minSize)) {
+			continue
+		}
+		match = i
+		matchBy.algorithm, matchBy.minSize = algorithm, minSize
+	}
+	return match, match >= 0
+}
+
+// subjectEntries maps the supported keys of the maxSubjectEntries constraint
+// to the entries of that subject field.
+var subjectEntries = map[string]func(pkix.Name) []string{
+	"organizations":       func(n pkix.Name) []string { return n.Organization },
+	"countries":           func(n pkix.Name) []string { return n.Country },
+	"organizationalUnits": func(n pkix.Name) []string { return n.OrganizationalUnit },
+	"localities":          func(n pkix.Name) []string { return n.Locality },
+	"provinces":           func(n pkix.Name) []string { return n.Province },
+	"streetAddresses":     func(n pkix.Name) []string { return n.StreetAddress },
+	"postalCodes":         func(n pkix.Name) []string { return n.PostalCode },
+}
+
+// sanTypeCounts maps the supported values of the allowedSANTypes constraint to
+// the number of SANs of that type in a CSR.
+var sanTypeCounts = map[string]func(*x509.CertificateRequest) int{
+	"DNS":   func(csr *x509.CertificateRequest) int { return len(csr.DNSNames) },
+	"IP":    func(csr *x509.CertificateRequest) int { return len(csr.IPAddresses) },
+	"URI":   func(csr *x509.CertificateRequest) int { return len(csr.URIs) },
+	"Email": func(csr *x509.CertificateRequest) int { return len(csr.EmailAddresses) },
+}
+
+// defaultExtensionOIDs are the OIDs of the extensions which are always
+// permitted by the allowedExtensionOIDs constraint, since their values are
+// constrained by other fields: Subject Alternative Name, basic constraints, key
+// usage and extended key usage.
+var defaultExtensionOIDs = []string{"2.5.29.17", "2.5.29.19", "2.5.29.15", "2.5.29.37"}
+
+// duplicates returns the values which appear more than once, in the order in
+// which they are first duplicated. If caseInsensitive is true, values which
+// differ only by case are duplicates.
+func duplicates(values []string, caseInsensitive bool) []string {
+	var (
+		dups []string
+		seen = make(map[string]int)
+	)
+	for _, value := range values {
+		key := value
+		if caseInsensitive {
+			key = strings.ToLower(value)
+		}
+		seen[key]++
+		if seen[key] == 2 {
+			dups = append(dups, value)
+		}
+	}
+	return dups
+}
+
+// signatureAlgorithms maps the supported values of the
+// allowedSignatureAlgorithms constraint to their signature algorithm.
+var signatureAlgorithms = map[string]x509.SignatureAlgorithm{
+	"SHA1WithRSA":      x509.SHA1WithRSA,
+	"SHA256WithRSA":    x509.SHA256WithRSA,
+	"SHA384WithRSA":    x509.SHA384WithRSA,
+	"SHA512WithRSA":    x509.SHA512WithRSA,
+	"SHA256WithRSAPSS": x509.SHA256WithRSAPSS,
+	"SHA384WithRSAPSS": x509.SHA384WithRSAPSS,
+	"SHA512WithRSAPSS": x509.SHA512WithRSAPSS,
+	"ECDSAWithSHA1":    x509.ECDSAWithSHA1,
+	"ECDSAWithSHA256":  x509.ECDSAWithSHA256,
+	"ECDSAWithSHA384":  x509.ECDSAWithSHA384,
+	"ECDSAWithSHA512":  x509.ECDSAWithSHA512,
+	"PureEd25519":      x509.PureEd25519,
+}
+
+// signatureAlgorithmName returns the allowedSignatureAlgorithms constraint
+// value of the given signature algorithm. Algorithms which are not supported
+// by the constraint are named by crypto/x509, and so never match.
+func signatureAlgorithmName(alg x509.SignatureAlgorithm) string {
+	for name, a := range signatureAlgorithms {
+		if a == alg {
+			return name
+		}
+	}
+	return alg.String()
+}
+
 // decodePublicKey will return the algorithm and size of the given public key.
 // If the public key cannot be decoded, an error is returned.
 func decodePublicKey(pub interface{}) (cmapi.PrivateKeyAlgorithm, int, error) {
@@ -125,13 +756,52 @@ func decodePublicKey(pub interface{}) (cmapi.PrivateKeyAlgorithm, int, error) {
 		}
 		return cmapi.ECDSAKeyAlgorithm, ecdsapub.Curve.Params().BitSize, nil
 
-	case *ed25519.PublicKey:
-		if _, ok := pub.(*ed25519.PublicKey); !ok {
-			return "", -1, errors.New("failed to decode Ed25519 public key")
-		}
+	// crypto/x509 parses Ed25519 public keys as values rather than pointers.
+	case ed25519.PublicKey, *ed25519.PublicKey:
 		return cmapi.Ed25519KeyAlgorithm, -1, nil
 
 	default:
 		return "", -1, fmt.Errorf("unrecognised public key type %T", pub)
 	}
 }
+
+// denialCodes are the codes of the field errors returned by Evaluate, keyed
+// by their field path without subscripts.
+var denialCodes = map[string]approver.DenialCode{
+	"spec.request":                                          approver.DenialCodeRequestInvalid,
+	"spec.duration":                                         approver.DenialCodeDurationTooLong,
+	"spec.constraints.maxDuration":                          approver.DenialCodeDurationTooLong,
+	"spec.constraints.minDuration":                          approver.DenialCodeDurationTooShort,
+	"spec.constraints.durationByKeySize.maxDuration":        approver.DenialCodeDurationTooLong,
+	"spec.constraints.allowedDurations":                     approver.DenialCodeDurationNotAllowed,
+	"spec.constraints.expiryAlignment":                      approver.DenialCodeExpiryNotAligned,
+	"spec.constraints.maxBackdate":                          approver.DenialCodeBackdateTooLong,
+	"spec.constraints.allowedNamespaces":                    approver.DenialCodeNamespaceNotAllowed,
+	"spec.constraints.privateKey.algorithm":                 approver.DenialCodeKeyAlgorithmNotAllowed,
+	"spec.constraints.privateKey.minSize":                   approver.DenialCodeKeyTooWeak,
+	"spec.constraints.privateKey.maxSize":                   approver.DenialCodeKeyTooLarge,
+	"spec.constraints.privateKey.allowedRSAPublicExponents": approver.DenialCodeKeyParameterNotAllowed,
+	"spec.constraints.privateKey.allowedECDSACurves":        approver.DenialCodeKeyParameterNotAllowed,
+	"spec.constraints.blockedPublicKeyHashes":               approver.DenialCodeKeyBlocked,
+	"spec.constraints.maxSANCount":                          approver.DenialCodeTooManySANs,
+	"spec.constraints.forbidDuplicateSANs":                  approver.DenialCodeDuplicateSAN,
+	"spec.constraints.forbidWildcardDNSNames":               approver.DenialCodeWildcardDNSName,
+	"spec.constraints.requireSANs":                          approver.DenialCodeSANRequired,
+	"spec.constraints.maxDNSNameLabels":                     approver.DenialCodeTooManyDNSNameLabels,
+	"spec.constraints.maxSubjectEntries":                    approver.DenialCodeTooManySubjectEntries,
+	"spec.constraints.maxCommonNameLength":                  approver.DenialCodeCommonNameTooLong,
+	"spec.constraints.maxSubjectTotalLength":                approver.DenialCodeSubjectTooLong,
+	"spec.constraints.allowedSignatureAlgorithms":           approver.DenialCodeSignatureAlgorithmNotAllowed,
+	"spec.constraints.allowedSANTypes":                      approver.DenialCodeSANTypeNotAllowed,
+	"spec.constraints.allowedExtensionOIDs":                 approver.DenialCodeExtensionNotAllowed,
+	"spec.constraints.isCA":                                 approver.DenialCodeIsCANotAllowed,
+	"spec.constraints.maxPathLen":                           approver.DenialCodePathLenTooLong,
+	"spec.constraints.requireCASignUsageWhenCA":             approver.DenialCodeCAUsageRequired,
+	"spec.constraints.requireCRLSignUsageWhenCA":            approver.DenialCodeCAUsageRequired,
+}
+
+// DenialCode implements approver.DenialCoder, returning the code of a field
+// error returned by Evaluate.
+func (c *constraints) DenialCode(err *field.Error) approver.DenialCode {
+	return denialCodes[util.FieldPathWithoutSubscripts(err.Field)]
+}
@@ -18,18 +18,30 @@ package constraints
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strconv"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 )
 
+// knownECDSACurves is the set of elliptic curves supported for ECDSA keys by
+// cert-manager.
+var knownECDSACurves = sets.New("P-256", "P-384", "P-521")
+
 // Validate validates that the processed CertificateRequestPolicy has valid
 // constraint fields defined and there are no parsing errors in the values.
-func (c constraints) Validate(_ context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+func (c *constraints) Validate(ctx context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
 	// If no constraints are defined we can exit early
 	if policy.Spec.Constraints == nil {
 		return approver.WebhookValidationResponse{
@@ -39,9 +51,10 @@ func (c constraints) Validate(_ context.Context, policy *policyapi.CertificateRe
 	}
 
 	var (
-		el      field.ErrorList
-		consts  = policy.Spec.Constraints
-		fldPath = field.NewPath("spec", "constraints")
+		el       field.ErrorList
+		warnings []string
+		consts   = policy.Spec.Constraints
+		fldPath  = field.NewPath("spec", "constraints")
 	)
 
 	if consts.PrivateKey != nil {
@@ -55,12 +68,13 @@ func (c constraints) Validate(_ context.Context, policy *policyapi.CertificateRe
 				el = append(el, field.NotSupported(fldPath.Child("algorithm"), alg, []string{string(cmapi.RSAKeyAlgorithm), string(cmapi.ECDSAKeyAlgorithm), string(cmapi.Ed25519KeyAlgorithm)}))
 			}
 
+			// Ed25519 keys have a fixed size, so size constraints have no effect.
 			if *consts.PrivateKey.Algorithm == cmapi.Ed25519KeyAlgorithm {
 				if consts.PrivateKey.MaxSize != nil {
-					el = append(el, field.Invalid(fldPath.Child("maxSize"), *consts.PrivateKey.MaxSize, fmt.Sprintf("maxSize cannot be defined with algorithm constraint %s", cmapi.Ed25519KeyAlgorithm)))
+					warnings = append(warnings, fmt.Sprintf("%s: maxSize is ignored with algorithm constraint %s", fldPath.Child("maxSize"), cmapi.Ed25519KeyAlgorithm))
 				}
 				if consts.PrivateKey.MinSize != nil {
-					el = append(el, field.Invalid(fldPath.Child("minSize"), *consts.PrivateKey.MinSize, fmt.Sprintf("minSize cannot be defined with algorithm constraint %s", cmapi.Ed25519KeyAlgorithm)))
+					warnings = append(warnings, fmt.Sprintf("%s: minSize is ignored with algorithm constraint %s", fldPath.Child("minSize"), cmapi.Ed25519KeyAlgorithm))
 				}
 			}
 		}
@@ -78,20 +92,298 @@ func (c constraints) Validate(_ context.Context, policy *policyapi.CertificateRe
 		if maxSize != nil && minSize != nil && *maxSize < *minSize {
 			el = append(el, field.Invalid(fldPath.Child("maxSize"), *maxSize, "maxSize must be the same value as minSize or larger"))
 		}
+
+		if exponents := consts.PrivateKey.AllowedRSAPublicExponents; exponents != nil {
+			fldPath := fldPath.Child("allowedRSAPublicExponents")
+			if len(*exponents) == 0 {
+				el = append(el, field.Required(fldPath, "allowedRSAPublicExponents must contain at least one exponent if defined"))
+			}
+			for i, e := range *exponents {
+				if e < 3 || e%2 == 0 {
+					el = append(el, field.Invalid(fldPath.Index(i), e, "RSA public exponents must be an odd number greater than or equal to 3"))
+				}
+			}
+			if alg := consts.PrivateKey.Algorithm; alg != nil && *alg != cmapi.RSAKeyAlgorithm {
+				el = append(el, field.Invalid(fldPath, *exponents, fmt.Sprintf("allowedRSAPublicExponents cannot be defined with algorithm constraint %s", *alg)))
+			}
+		}
+
+		if curves := consts.PrivateKey.AllowedECDSACurves; curves != nil {
+			fldPath := fldPath.Child("allowedECDSACurves")
+			if len(*curves) == 0 {
+				el = append(el, field.Required(fldPath, "allowedECDSACurves must contain at least one curve if defined"))
+			}
+			for i, curve := range *curves {
+				if !knownECDSACurves.Has(curve) {
+					el = append(el, field.NotSupported(fldPath.Index(i), curve, sets.List(knownECDSACurves)))
+				}
+			}
+			if alg := consts.PrivateKey.Algorithm; alg != nil && *alg != cmapi.ECDSAKeyAlgorithm {
+				el = append(el, field.Invalid(fldPath, *curves, fmt.Sprintf("allowedECDSACurves cannot be defined with algorithm constraint %s", *alg)))
+			}
+		}
+	}
+
+	if consts.MaxSANCount != nil && *consts.MaxSANCount <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("maxSANCount"), *consts.MaxSANCount, "maxSANCount must be a value greater than 0, omit the field to permit any number of SANs"))
+	}
+
+	if consts.MaxSANCountPerType != nil && consts.MaxSANCount == nil {
+		el = append(el, field.Required(fldPath.Child("maxSANCount"), "maxSANCount must be defined if maxSANCountPerType is defined"))
+	}
+
+	if consts.MaxPathLen != nil && *consts.MaxPathLen < -1 {
+		el = append(el, field.Invalid(fldPath.Child("maxPathLen"), *consts.MaxPathLen, "maxPathLen must be a value greater or equal to 0, or -1 to permit any path length"))
+	}
+
+	if len(consts.MaxSubjectEntries) > 0 {
+		fldPath := fldPath.Child("maxSubjectEntries")
+		supported := sets.List(sets.KeySet(subjectEntries))
+		for _, key := range sets.List(sets.KeySet(consts.MaxSubjectEntries)) {
+			if _, ok := subjectEntries[key]; !ok {
+				el = append(el, field.NotSupported(fldPath.Key(key), key, supported))
+				continue
+			}
+			if value := consts.MaxSubjectEntries[key]; value < 0 {
+				el = append(el, field.Invalid(fldPath.Key(key), value, "must be a value greater or equal to 0"))
+			}
+		}
+	}
+
+	if consts.MaxCommonNameLength != nil && *consts.MaxCommonNameLength <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("maxCommonNameLength"), *consts.MaxCommonNameLength, "maxCommonNameLength must be a value greater than 0, omit the field to permit a common name of any length"))
+	}
+
+	if consts.MaxSubjectTotalLength != nil && *consts.MaxSubjectTotalLength <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("maxSubjectTotalLength"), *consts.MaxSubjectTotalLength, "maxSubjectTotalLength must be a value greater than 0, omit the field to permit a subject of any length"))
+	}
+
+	if consts.MaxDNSNameLabels != nil && *consts.MaxDNSNameLabels <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("maxDNSNameLabels"), *consts.MaxDNSNameLabels, "maxDNSNameLabels must be a value greater than 0, omit the field to permit DNS names with any number of labels"))
+	}
+
+	if algs := consts.AllowedSignatureAlgorithms; algs != nil {
+		fldPath := fldPath.Child("allowedSignatureAlgorithms")
+		if len(algs) == 0 {
+			el = append(el, field.Required(fldPath, "allowedSignatureAlgorithms must contain at least one signature algorithm if defined"))
+		}
+		supported := sets.List(sets.KeySet(signatureAlgorithms))
+		for i, alg := range algs {
+			if _, ok := signatureAlgorithms[alg]; !ok {
+				el = append(el, field.NotSupported(fldPath.Index(i), alg, supported))
+			}
+		}
+	}
+
+	if sanTypes := consts.AllowedSANTypes; sanTypes != nil {
+		fldPath := fldPath.Child("allowedSANTypes")
+		if len(sanTypes) == 0 {
+			el = append(el, field.Required(fldPath, "allowedSANTypes must contain at least one SAN type if defined"))
+		}
+		supported := sets.List(sets.KeySet(sanTypeCounts))
+		for i, sanType := range sanTypes {
+			if _, ok := sanTypeCounts[sanType]; !ok {
+				el = append(el, field.NotSupported(fldPath.Index(i), sanType, supported))
+			}
+		}
+	}
+
+	if oids := consts.AllowedExtensionOIDs; oids != nil {
+		fldPath := fldPath.Child("allowedExtensionOIDs")
+		if len(oids) == 0 {
+			el = append(el, field.Required(fldPath, "allowedExtensionOIDs must contain at least one OID if defined"))
+		}
+		for i, oid := range oids {
+			if !util.IsOID(oid) {
+				el = append(el, field.Invalid(fldPath.Index(i), oid, "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.11129.2.4.2"))
+			}
+		}
+	}
+
+	if namespaces := consts.AllowedNamespaces; namespaces != nil {
+		fldPath := fldPath.Child("allowedNamespaces")
+		if len(namespaces) == 0 {
+			el = append(el, field.Required(fldPath, "allowedNamespaces must contain at least one namespace if defined"))
+		}
+		for i, namespace := range namespaces {
+			if len(namespace) == 0 {
+				el = append(el, field.Invalid(fldPath.Index(i), namespace, "namespace must not be empty"))
+			}
+		}
+	}
+
+	if ref := consts.BlockedPublicKeyHashes; ref != nil {
+		fldPath := fldPath.Child("blockedPublicKeyHashes")
+		if errs := util.ValidateValuesFrom(fldPath, ref); len(errs) > 0 {
+			el = append(el, errs...)
+		} else {
+			errs, err := c.validateBlockedPublicKeyHashes(ctx, fldPath, ref)
+			if err != nil {
+				return approver.WebhookValidationResponse{}, err
+			}
+			el = append(el, errs...)
+		}
+	}
+
+	if rules := consts.DurationByKeySize; len(rules) > 0 {
+		fldPath := fldPath.Child("durationByKeySize")
+
+		// Rules with the same algorithm and minSize match the same keys with
+		// the same specificity, so would contradict each other.
+		type ruleKey struct {
+			algorithm cmapi.PrivateKeyAlgorithm
+			minSize   int
+		}
+		seen := make(map[ruleKey]int)
+
+		for i, rule := range rules {
+			fldPath := fldPath.Index(i)
+			key := ruleKey{minSize: -1}
+
+			if rule.Algorithm != nil {
+				key.algorithm = *rule.Algorithm
+				switch *rule.Algorithm {
+				case cmapi.RSAKeyAlgorithm, cmapi.ECDSAKeyAlgorithm, cmapi.Ed25519KeyAlgorithm:
+				default:
+					el = append(el, field.NotSupported(fldPath.Child("algorithm"), *rule.Algorithm, []string{string(cmapi.RSAKeyAlgorithm), string(cmapi.ECDSAKeyAlgorithm), string(cmapi.Ed25519KeyAlgorithm)}))
+				}
+			}
+
+			if rule.MinSize != nil {
+				key.minSize = *rule.MinSize
+				if *rule.MinSize < 0 || *rule.MinSize > 8192 {
+					el = append(el, field.Invalid(fldPath.Child("minSize"), *rule.MinSize, "must be between 0 and 8192 inclusive"))
+				}
+				if rule.Algorithm != nil && *rule.Algorithm == cmapi.Ed25519KeyAlgorithm {
+					el = append(el, field.Invalid(fldPath.Child("minSize"), *rule.MinSize, fmt.Sprintf("minSize cannot be defined with algorithm %s, which has no size", cmapi.Ed25519KeyAlgorithm)))
+				}
+			}
+
+			if rule.MaxDuration.Duration <= 0 {
+				el = append(el, field.Invalid(fldPath.Child("maxDuration"), rule.MaxDuration.Duration.String(), "maxDuration must be a value greater than 0"))
+			} else if consts.MaxDuration != nil && rule.MaxDuration.Duration > consts.MaxDuration.Duration {
+				warnings = append(warnings, fmt.Sprintf("%s: has no effect since it is larger than %s", fldPath.Child("maxDuration"), field.NewPath("spec", "constraints", "maxDuration")))
+			}
+
+			if j, ok := seen[key]; ok {
+				el = append(el, field.Invalid(fldPath, fmt.Sprintf("algorithm: %s, minSize: %s", ruleAlgorithm(rule), ruleMinSize(rule)), fmt.Sprintf("must not have the same algorithm and minSize as rule %d", j)))
+			} else {
+				seen[key] = i
+			}
+		}
 	}
 
 	if consts.MaxDuration != nil && consts.MinDuration != nil && consts.MaxDuration.Duration < consts.MinDuration.Duration {
 		el = append(el, field.Invalid(fldPath.Child("maxDuration"), consts.MaxDuration.Duration.String(), "maxDuration must be the same value as minDuration or larger"))
 	}
+	if durations := consts.AllowedDurations; durations != nil {
+		fldPath := fldPath.Child("allowedDurations")
+		if len(durations) == 0 {
+			el = append(el, field.Required(fldPath, "allowedDurations must contain at least one duration if defined"))
+		}
+		for i, duration := range durations {
+			switch {
+			case duration.Duration <= 0:
+				el = append(el, field.Invalid(fldPath.Index(i), duration.Duration.String(), "allowedDurations must be values greater than 0"))
+			case consts.MinDuration != nil && duration.Duration < consts.MinDuration.Duration,
+				consts.MaxDuration != nil && duration.Duration > consts.MaxDuration.Duration:
+				warnings = append(warnings, fmt.Sprintf("%s: can never be requested since it is outside of %s and %s", fldPath.Index(i), field.NewPath("spec", "constraints", "minDuration"), field.NewPath("spec", "constraints", "maxDuration")))
+			}
+		}
+	}
+	if consts.AllowedDurationsTolerance != nil {
+		if consts.AllowedDurations == nil {
+			el = append(el, field.Required(fldPath.Child("allowedDurations"), "allowedDurations must be defined if allowedDurationsTolerance is defined"))
+		}
+		if tolerance := consts.AllowedDurationsTolerance.Duration; tolerance < 0 {
+			el = append(el, field.Invalid(fldPath.Child("allowedDurationsTolerance"), tolerance.String(), "allowedDurationsTolerance must be a value greater or equal to 0"))
+		}
+	}
+	if consts.ExpiryAlignment != nil {
+		if _, ok := alignmentPeriod(*consts.ExpiryAlignment); !ok {
+			el = append(el, field.NotSupported(fldPath.Child("expiryAlignment"), *consts.ExpiryAlignment, []string{string(policyapi.ExpiryAlignmentMidnight), string(policyapi.ExpiryAlignmentHour)}))
+		}
+	}
+	if consts.ExpiryAlignmentTolerance != nil {
+		if consts.ExpiryAlignment == nil {
+			el = append(el, field.Required(fldPath.Child("expiryAlignment"), "expiryAlignment must be defined if expiryAlignmentTolerance is defined"))
+		}
+		fldPath := fldPath.Child("expiryAlignmentTolerance")
+		tolerance := consts.ExpiryAlignmentTolerance.Duration
+		if tolerance < 0 {
+			el = append(el, field.Invalid(fldPath, tolerance.String(), "expiryAlignmentTolerance must be a value greater or equal to 0"))
+		} else if consts.ExpiryAlignment != nil {
+			if period, ok := alignmentPeriod(*consts.ExpiryAlignment); ok && tolerance >= period/2 {
+				el = append(el, field.Invalid(fldPath, tolerance.String(), fmt.Sprintf("expiryAlignmentTolerance must be less than %s, half the period of expiryAlignment %s, otherwise any expiry is aligned", period/2, *consts.ExpiryAlignment)))
+			}
+		}
+	}
 	if consts.MaxDuration != nil && consts.MaxDuration.Duration < 0 {
 		el = append(el, field.Invalid(fldPath.Child("maxDuration"), consts.MaxDuration.Duration.String(), "maxDuration must be a value greater or equal to 0"))
 	}
 	if consts.MinDuration != nil && consts.MinDuration.Duration < 0 {
 		el = append(el, field.Invalid(fldPath.Child("minDuration"), consts.MinDuration.Duration.String(), "minDuration must be a value greater or equal to 0"))
 	}
+	if consts.MaxBackdate != nil && consts.MaxBackdate.Duration < 0 {
+		el = append(el, field.Invalid(fldPath.Child("maxBackdate"), consts.MaxBackdate.Duration.String(), "maxBackdate must be a value greater or equal to 0"))
+	}
+	if consts.MinReissueInterval != nil && consts.MinReissueInterval.Duration < 0 {
+		el = append(el, field.Invalid(fldPath.Child("minReissueInterval"), consts.MinReissueInterval.Duration.String(), "minReissueInterval must be a value greater or equal to 0"))
+	}
 
 	return approver.WebhookValidationResponse{
-		Allowed: len(el) == 0,
-		Errors:  el,
+		Allowed:  len(el) == 0,
+		Errors:   el,
+		Warnings: warnings,
 	}, nil
 }
+
+// validateBlockedPublicKeyHashes validates that the referenced ConfigMap key
+// exists and contains only hex encoded SHA-256 hashes, so that requests can be
+// evaluated against the policy. Returns no errors if the approver has not
+// been prepared, so can't check.
+func (c *constraints) validateBlockedPublicKeyHashes(ctx context.Context, fldPath *field.Path, ref *policyapi.CertificateRequestPolicyValuesFrom) (field.ErrorList, error) {
+	if c.lister == nil {
+		return nil, nil
+	}
+
+	var cm corev1.ConfigMap
+	err := c.lister.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, &cm)
+	switch {
+	case apierrors.IsNotFound(err):
+		return field.ErrorList{field.Invalid(fldPath.Child("name"), ref.Name, fmt.Sprintf("ConfigMap %s/%s does not exist", ref.Namespace, ref.Name))}, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get blockedPublicKeyHashes ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	data, ok := cm.Data[ref.Key]
+	if !ok {
+		return field.ErrorList{field.Invalid(fldPath.Child("key"), ref.Key, fmt.Sprintf("ConfigMap %s/%s has no key %q", ref.Namespace, ref.Name, ref.Key))}, nil
+	}
+
+	var el field.ErrorList
+	for _, hash := range util.ParseValues(data) {
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			el = append(el, field.Invalid(fldPath.Child("key"), hash, "must contain only hex encoded SHA-256 hashes"))
+		}
+	}
+	return el, nil
+}
+
+// ruleAlgorithm returns the algorithm of a durationByKeySize rule, or "*" if
+// the rule applies to any algorithm.
+func ruleAlgorithm(rule policyapi.CertificateRequestPolicyDurationByKeySize) string {
+	if rule.Algorithm == nil {
+		return "*"
+	}
+	return string(*rule.Algorithm)
+}
+
+// ruleMinSize returns the minSize of a durationByKeySize rule, or "*" if the
+// rule applies to any size.
+func ruleMinSize(rule policyapi.CertificateRequestPolicyDurationByKeySize) string {
+	if rule.MinSize == nil {
+		return "*"
+	}
+	return strconv.Itoa(*rule.MinSize)
+}
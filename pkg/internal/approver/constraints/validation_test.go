@@ -23,12 +23,15 @@ import (
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 )
 
 func Test_Validate(t *testing.T) {
@@ -78,7 +81,7 @@ func Test_Validate(t *testing.T) {
 				},
 			},
 		},
-		"if policy is using Ed25519 constraints but defined min and max key sizes, expect a Allowed=false response": {
+		"if policy is using Ed25519 constraints but defined min and max key sizes, expect a Allowed=true response with warnings": {
 			policy: &policyapi.CertificateRequestPolicy{
 				Spec: policyapi.CertificateRequestPolicySpec{
 					Constraints: &policyapi.CertificateRequestPolicyConstraints{
@@ -91,10 +94,11 @@ func Test_Validate(t *testing.T) {
 				},
 			},
 			expResponse: approver.WebhookValidationResponse{
-				Allowed: false,
-				Errors: field.ErrorList{
-					field.Invalid(field.NewPath("spec.constraints.privateKey.maxSize"), 500, "maxSize cannot be defined with algorithm constraint Ed25519"),
-					field.Invalid(field.NewPath("spec.constraints.privateKey.minSize"), 100, "minSize cannot be defined with algorithm constraint Ed25519"),
+				Allowed: true,
+				Errors:  nil,
+				Warnings: []string{
+					"spec.constraints.privateKey.maxSize: maxSize is ignored with algorithm constraint Ed25519",
+					"spec.constraints.privateKey.minSize: minSize is ignored with algorithm constraint Ed25519",
 				},
 			},
 		},
@@ -117,11 +121,747 @@ func Test_Validate(t *testing.T) {
 				Errors:  nil,
 			},
 		},
-	}
-
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			response, err := constraints{}.Validate(context.TODO(), test.policy)
+		"if policy contains an empty allowed RSA public exponents list, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+							AllowedRSAPublicExponents: &[]int{},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.privateKey.allowedRSAPublicExponents"), "allowedRSAPublicExponents must contain at least one exponent if defined"),
+				},
+			},
+		},
+		"if policy contains invalid allowed RSA public exponents, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+							Algorithm:                 &edAlg,
+							AllowedRSAPublicExponents: &[]int{65537, 65536, 1},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.privateKey.allowedRSAPublicExponents[1]"), 65536, "RSA public exponents must be an odd number greater than or equal to 3"),
+					field.Invalid(field.NewPath("spec.constraints.privateKey.allowedRSAPublicExponents[2]"), 1, "RSA public exponents must be an odd number greater than or equal to 3"),
+					field.Invalid(field.NewPath("spec.constraints.privateKey.allowedRSAPublicExponents"), []int{65537, 65536, 1}, "allowedRSAPublicExponents cannot be defined with algorithm constraint Ed25519"),
+				},
+			},
+		},
+		"if policy contains valid allowed RSA public exponents, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+							Algorithm:                 &rsaAlg,
+							AllowedRSAPublicExponents: &[]int{65537},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy contains a maxSANCount of zero, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxSANCount: pointer.Int(0),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.maxSANCount"), 0, "maxSANCount must be a value greater than 0, omit the field to permit any number of SANs"),
+				},
+			},
+		},
+		"if policy contains a negative maxSANCount, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxSANCount:        pointer.Int(-1),
+						MaxSANCountPerType: pointer.Bool(true),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.maxSANCount"), -1, "maxSANCount must be a value greater than 0, omit the field to permit any number of SANs"),
+				},
+			},
+		},
+		"if policy contains maxSANCountPerType but no maxSANCount, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxSANCountPerType: pointer.Bool(true),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.maxSANCount"), "maxSANCount must be defined if maxSANCountPerType is defined"),
+				},
+			},
+		}, "if policy contains an isCA constraint, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						IsCA: pointer.Bool(false),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		}, "if policy contains maxSubjectEntries with unknown keys or negative values, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxSubjectEntries: map[string]int{"organizations": -1, "commonName": 1, "countries": 1},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.constraints.maxSubjectEntries").Key("commonName"), "commonName", []string{"countries", "localities", "organizationalUnits", "organizations", "postalCodes", "provinces", "streetAddresses"}),
+					field.Invalid(field.NewPath("spec.constraints.maxSubjectEntries").Key("organizations"), -1, "must be a value greater or equal to 0"),
+				},
+			},
+		},
+		"if policy contains valid maxSubjectEntries, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxSubjectEntries: map[string]int{"organizations": 1, "countries": 0},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		}, "if policy contains an empty allowed ECDSA curves list, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+							AllowedECDSACurves: &[]string{},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.privateKey.allowedECDSACurves"), "allowedECDSACurves must contain at least one curve if defined"),
+				},
+			},
+		},
+		"if policy contains unknown allowed ECDSA curves with a non-ECDSA algorithm, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+							Algorithm:          &rsaAlg,
+							AllowedECDSACurves: &[]string{"P-256", "P-224"},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.constraints.privateKey.allowedECDSACurves[1]"), "P-224", []string{"P-256", "P-384", "P-521"}),
+					field.Invalid(field.NewPath("spec.constraints.privateKey.allowedECDSACurves"), []string{"P-256", "P-224"}, "allowedECDSACurves cannot be defined with algorithm constraint RSA"),
+				},
+			},
+		},
+		"if policy contains valid allowed ECDSA curves, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+							AllowedECDSACurves: &[]string{"P-256", "P-384"},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		}, "if policy contains an empty list of allowed signature algorithms, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedSignatureAlgorithms: []string{},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.allowedSignatureAlgorithms"), "allowedSignatureAlgorithms must contain at least one signature algorithm if defined"),
+				},
+			},
+		},
+		"if policy contains unknown allowed signature algorithms, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedSignatureAlgorithms: []string{"SHA256WithRSA", "MD5WithRSA", "SHA256-RSA"},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.constraints.allowedSignatureAlgorithms[1]"), "MD5WithRSA", []string{
+						"ECDSAWithSHA1", "ECDSAWithSHA256", "ECDSAWithSHA384", "ECDSAWithSHA512", "PureEd25519",
+						"SHA1WithRSA", "SHA256WithRSA", "SHA256WithRSAPSS", "SHA384WithRSA", "SHA384WithRSAPSS", "SHA512WithRSA", "SHA512WithRSAPSS",
+					}),
+					field.NotSupported(field.NewPath("spec.constraints.allowedSignatureAlgorithms[2]"), "SHA256-RSA", []string{
+						"ECDSAWithSHA1", "ECDSAWithSHA256", "ECDSAWithSHA384", "ECDSAWithSHA512", "PureEd25519",
+						"SHA1WithRSA", "SHA256WithRSA", "SHA256WithRSAPSS", "SHA384WithRSA", "SHA384WithRSAPSS", "SHA512WithRSA", "SHA512WithRSAPSS",
+					}),
+				},
+			},
+		},
+		"if policy contains valid allowed signature algorithms, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedSignatureAlgorithms: []string{"SHA256WithRSA", "ECDSAWithSHA384", "PureEd25519"},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if policy contains an empty list of allowed SAN types, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedSANTypes: []string{},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.allowedSANTypes"), "allowedSANTypes must contain at least one SAN type if defined"),
+				},
+			},
+		},
+		"if policy contains unknown allowed SAN types, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedSANTypes: []string{"DNS", "dnsNames", "IPAddress"},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.constraints.allowedSANTypes[1]"), "dnsNames", []string{"DNS", "Email", "IP", "URI"}),
+					field.NotSupported(field.NewPath("spec.constraints.allowedSANTypes[2]"), "IPAddress", []string{"DNS", "Email", "IP", "URI"}),
+				},
+			},
+		},
+		"if policy contains valid allowed SAN types, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedSANTypes: []string{"DNS", "IP", "URI", "Email"},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy contains a maxPathLen of -1 or greater, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxPathLen: pointer.Int(-1),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy contains a maxPathLen less than -1, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxPathLen: pointer.Int(-2),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.maxPathLen"), -2, "maxPathLen must be a value greater or equal to 0, or -1 to permit any path length"),
+				},
+			},
+		},
+		"if durationByKeySize rules are valid, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						DurationByKeySize: []policyapi.CertificateRequestPolicyDurationByKeySize{
+							{Algorithm: &rsaAlg, MaxDuration: metav1.Duration{Duration: time.Hour * 24 * 90}},
+							{Algorithm: &rsaAlg, MinSize: pointer.Int(4096), MaxDuration: metav1.Duration{Duration: time.Hour * 24 * 365}},
+							{MinSize: pointer.Int(4096), MaxDuration: metav1.Duration{Duration: time.Hour * 24 * 30}},
+							{MaxDuration: metav1.Duration{Duration: time.Hour * 24 * 30}},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: true,
+				Errors:  nil,
+			},
+		},
+		"if durationByKeySize rules are invalid or contradict each other, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxDuration: &metav1.Duration{Duration: time.Hour * 24 * 180},
+						DurationByKeySize: []policyapi.CertificateRequestPolicyDurationByKeySize{
+							{Algorithm: &rsaAlg, MinSize: pointer.Int(2048), MaxDuration: metav1.Duration{Duration: time.Hour * 24 * 90}},
+							{Algorithm: &rsaAlg, MinSize: pointer.Int(2048), MaxDuration: metav1.Duration{Duration: time.Hour * 24 * 365}},
+							{Algorithm: &badAlg, MinSize: pointer.Int(-1), MaxDuration: metav1.Duration{Duration: 0}},
+							{Algorithm: &edAlg, MinSize: pointer.Int(256), MaxDuration: metav1.Duration{Duration: time.Hour}},
+						},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.durationByKeySize[1]"), "algorithm: RSA, minSize: 2048", "must not have the same algorithm and minSize as rule 0"),
+					field.NotSupported(field.NewPath("spec.constraints.durationByKeySize[2].algorithm"), badAlg, []string{"RSA", "ECDSA", "Ed25519"}),
+					field.Invalid(field.NewPath("spec.constraints.durationByKeySize[2].minSize"), -1, "must be between 0 and 8192 inclusive"),
+					field.Invalid(field.NewPath("spec.constraints.durationByKeySize[2].maxDuration"), "0s", "maxDuration must be a value greater than 0"),
+					field.Invalid(field.NewPath("spec.constraints.durationByKeySize[3].minSize"), 256, "minSize cannot be defined with algorithm Ed25519, which has no size"),
+				},
+				Warnings: []string{
+					"spec.constraints.durationByKeySize[1].maxDuration: has no effect since it is larger than spec.constraints.maxDuration",
+				},
+			},
+		},
+		"if policy contains an empty list of allowed extension OIDs, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedExtensionOIDs: []string{},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.allowedExtensionOIDs"), "allowedExtensionOIDs must contain at least one OID if defined"),
+				},
+			},
+		},
+		"if policy contains an empty list of allowed namespaces, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedNamespaces: []string{},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.allowedNamespaces"), "allowedNamespaces must contain at least one namespace if defined"),
+				},
+			},
+		},
+		"if policy contains an empty allowed namespace, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedNamespaces: []string{"team-*", ""},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.allowedNamespaces[1]"), "", "namespace must not be empty"),
+				},
+			},
+		},
+		"if policy contains malformed extension OIDs, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedExtensionOIDs: []string{"1.3.6.1.4.1.11129.2.4.2", "1", "1..2", "3.1", "1.02", "1.2a", "subjectKeyId"},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.allowedExtensionOIDs[1]"), "1", "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.11129.2.4.2"),
+					field.Invalid(field.NewPath("spec.constraints.allowedExtensionOIDs[2]"), "1..2", "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.11129.2.4.2"),
+					field.Invalid(field.NewPath("spec.constraints.allowedExtensionOIDs[3]"), "3.1", "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.11129.2.4.2"),
+					field.Invalid(field.NewPath("spec.constraints.allowedExtensionOIDs[4]"), "1.02", "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.11129.2.4.2"),
+					field.Invalid(field.NewPath("spec.constraints.allowedExtensionOIDs[5]"), "1.2a", "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.11129.2.4.2"),
+					field.Invalid(field.NewPath("spec.constraints.allowedExtensionOIDs[6]"), "subjectKeyId", "must be an object identifier in dotted decimal form, e.g. 1.3.6.1.4.1.11129.2.4.2"),
+				},
+			},
+		},
+		"if policy contains a valid expiryAlignment and tolerance, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						ExpiryAlignment:          expiryAlignment(policyapi.ExpiryAlignmentHour),
+						ExpiryAlignmentTolerance: &metav1.Duration{Duration: time.Minute*30 - time.Second},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy contains an unknown expiryAlignment, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						ExpiryAlignment: expiryAlignment("Minute"),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(field.NewPath("spec.constraints.expiryAlignment"), policyapi.ExpiryAlignment("Minute"), []string{"Midnight", "Hour"}),
+				},
+			},
+		},
+		"if policy contains an expiryAlignmentTolerance which is negative and has no expiryAlignment, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						ExpiryAlignmentTolerance: &metav1.Duration{Duration: -time.Minute},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.expiryAlignment"), "expiryAlignment must be defined if expiryAlignmentTolerance is defined"),
+					field.Invalid(field.NewPath("spec.constraints.expiryAlignmentTolerance"), "-1m0s", "expiryAlignmentTolerance must be a value greater or equal to 0"),
+				},
+			},
+		},
+		"if policy contains an expiryAlignmentTolerance of half the expiryAlignment period, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						ExpiryAlignment:          expiryAlignment(policyapi.ExpiryAlignmentMidnight),
+						ExpiryAlignmentTolerance: &metav1.Duration{Duration: time.Hour * 12},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.expiryAlignmentTolerance"), "12h0m0s", "expiryAlignmentTolerance must be less than 12h0m0s, half the period of expiryAlignment Midnight, otherwise any expiry is aligned"),
+				},
+			},
+		},
+		"if policy contains an allowedDuration which isn't positive, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedDurations: []metav1.Duration{{Duration: time.Hour}, {Duration: 0}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.allowedDurations[1]"), "0s", "allowedDurations must be values greater than 0"),
+				},
+			},
+		},
+		"if policy contains an empty allowedDurations, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedDurations: []metav1.Duration{},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.allowedDurations"), "allowedDurations must contain at least one duration if defined"),
+				},
+			},
+		},
+		"if policy contains a negative allowedDurationsTolerance without allowedDurations, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						AllowedDurationsTolerance: &metav1.Duration{Duration: -time.Minute},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(field.NewPath("spec.constraints.allowedDurations"), "allowedDurations must be defined if allowedDurationsTolerance is defined"),
+					field.Invalid(field.NewPath("spec.constraints.allowedDurationsTolerance"), "-1m0s", "allowedDurationsTolerance must be a value greater or equal to 0"),
+				},
+			},
+		},
+		"if policy contains an allowedDuration outside of minDuration and maxDuration, expect a Allowed=true response with a warning": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxDuration:      &metav1.Duration{Duration: time.Hour * 24 * 180},
+						AllowedDurations: []metav1.Duration{{Duration: time.Hour * 24 * 90}, {Duration: time.Hour * 24 * 365}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed:  true,
+				Warnings: []string{"spec.constraints.allowedDurations[1]: can never be requested since it is outside of spec.constraints.minDuration and spec.constraints.maxDuration"},
+			},
+		},
+		"if policy contains a negative maxBackdate, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxBackdate: &metav1.Duration{Duration: -time.Minute},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.maxBackdate"), "-1m0s", "maxBackdate must be a value greater or equal to 0"),
+				},
+			},
+		},
+		"if policy contains a negative minReissueInterval, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MinReissueInterval: &metav1.Duration{Duration: -time.Minute},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.minReissueInterval"), "-1m0s", "minReissueInterval must be a value greater or equal to 0"),
+				},
+			},
+		},
+		"if policy contains maxCommonNameLength and maxSubjectTotalLength greater than 0, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxCommonNameLength:   pointer.Int(64),
+						MaxSubjectTotalLength: pointer.Int(256),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy contains zero or negative maxCommonNameLength and maxSubjectTotalLength, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxCommonNameLength:   pointer.Int(0),
+						MaxSubjectTotalLength: pointer.Int(-1),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.maxCommonNameLength"), 0, "maxCommonNameLength must be a value greater than 0, omit the field to permit a common name of any length"),
+					field.Invalid(field.NewPath("spec.constraints.maxSubjectTotalLength"), -1, "maxSubjectTotalLength must be a value greater than 0, omit the field to permit a subject of any length"),
+				},
+			},
+		},
+		"if policy contains a positive maxDNSNameLabels, expect a Allowed=true response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxDNSNameLabels: pointer.Int(1),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if policy contains zero maxDNSNameLabels, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxDNSNameLabels: pointer.Int(0),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.maxDNSNameLabels"), 0, "maxDNSNameLabels must be a value greater than 0, omit the field to permit DNS names with any number of labels"),
+				},
+			},
+		},
+		"if policy contains negative maxDNSNameLabels, expect a Allowed=false response": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxDNSNameLabels: pointer.Int(-1),
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec.constraints.maxDNSNameLabels"), -1, "maxDNSNameLabels must be a value greater than 0, omit the field to permit DNS names with any number of labels"),
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, err := (&constraints{}).Validate(context.TODO(), test.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
+
+func Test_Validate_blockedPublicKeyHashes(t *testing.T) {
+	blocklist := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "blocked-keys"},
+		Data: map[string]string{
+			"hashes":    "# Known compromised keys\n" + blockedKeyHash + "\n",
+			"malformed": blockedKeyHash + "\nnot-a-hash\n" + blockedKeyHash[:32] + "\n",
+		},
+	}
+
+	fldPath := field.NewPath("spec", "constraints", "blockedPublicKeyHashes")
+	tests := map[string]struct {
+		ref                 policyapi.CertificateRequestPolicyValuesFrom
+		valuesFromNamespace string
+		unprepared          bool
+		expResponse         approver.WebhookValidationResponse
+	}{
+		"if the referenced key exists and contains only hashes, expect a Allowed=true response": {
+			ref:         policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "blocked-keys", Key: "hashes"},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if the reference is malformed, expect a Allowed=false response": {
+			ref: policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "Blocked_Keys"},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Child("name"), "Blocked_Keys", "a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')"),
+					field.Required(fldPath.Child("key"), "must be defined"),
+				},
+			},
+		},
+		"if the referenced ConfigMap doesn't exist, expect a Allowed=false response": {
+			ref: policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "not-found", Key: "hashes"},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Child("name"), "not-found", "ConfigMap cert-manager/not-found does not exist"),
+				},
+			},
+		},
+		"if the referenced key doesn't exist, expect a Allowed=false response": {
+			ref: policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "blocked-keys", Key: "not-a-key"},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Child("key"), "not-a-key", `ConfigMap cert-manager/blocked-keys has no key "not-a-key"`),
+				},
+			},
+		},
+		"if the referenced key contains values which aren't SHA-256 hashes, expect a Allowed=false response": {
+			ref: policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "blocked-keys", Key: "malformed"},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Child("key"), "not-a-hash", "must contain only hex encoded SHA-256 hashes"),
+					field.Invalid(fldPath.Child("key"), blockedKeyHash[:32], "must contain only hex encoded SHA-256 hashes"),
+				},
+			},
+		},
+		"if the referenced ConfigMap is in the namespace values may be loaded from, expect a Allowed=true response": {
+			ref:                 policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "blocked-keys", Key: "hashes"},
+			valuesFromNamespace: "cert-manager",
+			expResponse:         approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if the referenced ConfigMap is not in the namespace values may be loaded from, expect a Allowed=false response": {
+			ref:                 policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "blocked-keys", Key: "hashes"},
+			valuesFromNamespace: "approver-policy",
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.NotSupported(fldPath.Child("namespace"), "cert-manager", []string{"approver-policy"}),
+				},
+			},
+		},
+		"if the approver has not been prepared, expect the ConfigMap not to be checked": {
+			ref:         policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "not-found", Key: "hashes"},
+			unprepared:  true,
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			util.ConfigureValuesFromNamespace(test.valuesFromNamespace)
+			t.Cleanup(func() { util.ConfigureValuesFromNamespace("") })
+
+			c := new(constraints)
+			if !test.unprepared {
+				c.lister = fakeclient.NewClientBuilder().
+					WithScheme(policyapi.GlobalScheme).
+					WithObjects(blocklist).
+					Build()
+			}
+
+			policy := &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{BlockedPublicKeyHashes: &test.ref},
+				},
+			}
+			response, err := c.Validate(context.TODO(), policy)
 			assert.NoError(t, err)
 			assert.Equal(t, test.expResponse, response)
 		})
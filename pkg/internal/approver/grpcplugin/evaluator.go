@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+// Evaluate calls the policy service configured in the grpc plugin values of
+// the policy, and denies the request if the policy service does not allow
+// it. If the policy service could not be reached, a TransientError is
+// returned so that the request is re-evaluated, rather than denied. Policies
+// which do not define the grpc plugin are not evaluated.
+func (g *grpcPlugin) Evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+	plugin, ok := policy.Spec.Plugins[g.Name()]
+	if !ok {
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	}
+
+	fldPath := field.NewPath("spec", "plugins", g.Name())
+
+	cfg, el := parseConfig(fldPath.Child("values"), plugin.Values)
+	if len(el) > 0 {
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+	}
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return approver.EvaluationResponse{}, fmt.Errorf("failed to encode policy: %w", err)
+	}
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return approver.EvaluationResponse{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	client, err := g.client(cfg.endpoint)
+	if err != nil {
+		return approver.EvaluationResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	resp, err := client.EvaluateRequest(ctx, &evaluateRequestRequest{policy: policyJSON, certificateRequest: requestJSON})
+	if err != nil {
+		transient := isTransient(err)
+		err = fmt.Errorf("failed to evaluate request with policy service: %w", err)
+		if transient {
+			return approver.EvaluationResponse{}, approver.NewTransientError(err)
+		}
+		return approver.EvaluationResponse{}, err
+	}
+
+	if resp.allowed {
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	}
+
+	for _, message := range resp.messages {
+		el = append(el, field.Forbidden(fldPath, message))
+	}
+	if len(el) == 0 {
+		el = append(el, field.Forbidden(fldPath, "request denied by policy service"))
+	}
+
+	return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+}
+
+// isTransient returns whether the gRPC error is caused by the policy service
+// being temporarily unable to respond, such as a connection failure or
+// timeout.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/registry"
+)
+
+// Load the grpc approver.
+func init() {
+	registry.Shared.Store(Approver())
+}
+
+// Approver returns an instance on the grpc approver.
+func Approver() approver.Interface {
+	return &grpcPlugin{log: logr.Discard(), conns: make(map[string]*grpc.ClientConn)}
+}
+
+// grpcPlugin is an approver-policy Approver plugin that defers the validation
+// of policies, and the decision of whether a request should be denied, to an
+// external policy service implementing the ApproverPlugin gRPC service defined
+// in plugin.proto. The endpoint is configured per CertificateRequestPolicy
+// using the values of the "grpc" plugin, and TLS is configured with flags.
+// Policies which do not define the "grpc" plugin are ignored.
+type grpcPlugin struct {
+	// log is the logger for the grpc approver, set on Prepare.
+	log logr.Logger
+
+	// caFile, certFile and keyFile are the paths of the CA bundle used to
+	// verify policy services, and the client certificate and key presented
+	// to them.
+	caFile, certFile, keyFile string
+
+	// insecure marks whether policy services are connected to without TLS.
+	insecure bool
+
+	// creds are the transport credentials used to connect to policy
+	// services, built on Prepare.
+	creds credentials.TransportCredentials
+
+	// lock protects conns.
+	lock sync.Mutex
+
+	// conns are the connections to policy services, keyed by endpoint.
+	conns map[string]*grpc.ClientConn
+}
+
+// Name of Approver is "grpc"
+func (g *grpcPlugin) Name() string {
+	return "grpc"
+}
+
+// RegisterFlags registers the flags configuring TLS to policy services.
+func (g *grpcPlugin) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&g.caFile, "grpc-plugin-ca-file", "",
+		"File containing the PEM encoded CA bundle used to verify gRPC policy services. "+
+			"If empty, the system roots are used.")
+	fs.StringVar(&g.certFile, "grpc-plugin-cert-file", "",
+		"File containing the PEM encoded client certificate presented to gRPC policy services. "+
+			"Must be set along with --grpc-plugin-key-file.")
+	fs.StringVar(&g.keyFile, "grpc-plugin-key-file", "",
+		"File containing the PEM encoded private key of the client certificate presented to "+
+			"gRPC policy services.")
+	fs.BoolVar(&g.insecure, "grpc-plugin-insecure", false,
+		"If true, gRPC policy services are connected to without TLS.")
+}
+
+// Prepare stores the logger, and builds the transport credentials used to
+// connect to policy services.
+func (g *grpcPlugin) Prepare(_ context.Context, log logr.Logger, _ manager.Manager) error {
+	g.log = log.WithName("grpc")
+
+	creds, err := g.transportCredentials()
+	if err != nil {
+		return err
+	}
+	g.creds = creds
+
+	return nil
+}
+
+// transportCredentials returns the transport credentials configured by the
+// flags.
+func (g *grpcPlugin) transportCredentials() (credentials.TransportCredentials, error) {
+	if g.insecure {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(g.caFile) > 0 {
+		pem, err := os.ReadFile(g.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --grpc-plugin-ca-file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("--grpc-plugin-ca-file contains no PEM encoded certificates")
+		}
+	}
+
+	if len(g.certFile) > 0 || len(g.keyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(g.certFile, g.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load --grpc-plugin-cert-file and --grpc-plugin-key-file: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+// Ready always returns ready, plugin values are validated by the webhook and
+// policy services are only called at validation and evaluation time.
+func (g *grpcPlugin) Ready(_ context.Context, _ *policyapi.CertificateRequestPolicy) (approver.ReconcilerReadyResponse, error) {
+	return approver.ReconcilerReadyResponse{Ready: true}, nil
+}
+
+// grpc never needs to manually enqueue policies.
+func (g *grpcPlugin) EnqueueChan() <-chan string {
+	return nil
+}
+
+// client returns a client of the policy service at the given endpoint. The
+// connection is shared by all policies using the same endpoint, and is
+// established lazily so that a policy service which is unavailable surfaces
+// as an error of the call.
+func (g *grpcPlugin) client(endpoint string) (*client, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if conn, ok := g.conns[endpoint]; ok {
+		return &client{conn: conn}, nil
+	}
+
+	if g.creds == nil {
+		return nil, errors.New("grpc approver has not been prepared")
+	}
+
+	conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(g.creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial policy service: %w", err)
+	}
+	g.conns[endpoint] = conn
+
+	return &client{conn: conn}, nil
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+// testServer is an in-process ApproverPlugin service which responds using the
+// given functions.
+type testServer struct {
+	validate func(*policyapi.CertificateRequestPolicy) (*validatePolicyResponse, error)
+	evaluate func(*policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (*evaluateRequestResponse, error)
+}
+
+// serviceDesc describes the ApproverPlugin service, so that testServer may be
+// registered with a gRPC server.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "approverpolicy.plugin.v1.ApproverPlugin",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidatePolicy",
+			Handler: func(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(validatePolicyRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				var policy policyapi.CertificateRequestPolicy
+				if err := json.Unmarshal(req.policy, &policy); err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+				return srv.(*testServer).validate(&policy)
+			},
+		},
+		{
+			MethodName: "EvaluateRequest",
+			Handler: func(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(evaluateRequestRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				var policy policyapi.CertificateRequestPolicy
+				if err := json.Unmarshal(req.policy, &policy); err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+				var request cmapi.CertificateRequest
+				if err := json.Unmarshal(req.certificateRequest, &request); err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+				return srv.(*testServer).evaluate(&policy, &request)
+			},
+		},
+	},
+}
+
+// startServer starts an in-process gRPC server serving the test server,
+// returning its endpoint.
+func startServer(t *testing.T, srv *testServer) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(grpc.ForceServerCodec(codec{}))
+	server.RegisterService(&serviceDesc, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+// newPlugin returns a grpc plugin which connects to policy services without
+// TLS.
+func newPlugin() *grpcPlugin {
+	g := Approver().(*grpcPlugin)
+	g.creds = insecure.NewCredentials()
+	return g
+}
+
+func policyWithEndpoint(endpoint string) *policyapi.CertificateRequestPolicy {
+	return &policyapi.CertificateRequestPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+		Spec: policyapi.CertificateRequestPolicySpec{
+			Plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"grpc": {Values: map[string]string{"endpoint": endpoint, "timeout": "1s"}},
+			},
+		},
+	}
+}
+
+func Test_Evaluate(t *testing.T) {
+	fldPath := field.NewPath("spec", "plugins", "grpc")
+
+	endpoint := startServer(t, &testServer{
+		evaluate: func(policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (*evaluateRequestResponse, error) {
+			switch request.Name {
+			case "allowed":
+				return &evaluateRequestResponse{allowed: policy.Name == "test-policy"}, nil
+			case "denied":
+				return &evaluateRequestResponse{messages: []string{"dns name not allowed", "too long"}}, nil
+			case "denied-without-messages":
+				return &evaluateRequestResponse{}, nil
+			case "unavailable":
+				return nil, status.Error(codes.Unavailable, "overloaded")
+			default:
+				return nil, status.Error(codes.Internal, "unexpected request")
+			}
+		},
+	})
+
+	// Reserve an address which nothing is listening on.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedEndpoint := lis.Addr().String()
+	lis.Close()
+
+	tests := map[string]struct {
+		policy       *policyapi.CertificateRequestPolicy
+		requestName  string
+		expResponse  approver.EvaluationResponse
+		expErr       bool
+		expTransient bool
+	}{
+		"if the policy doesn't define the grpc plugin, return NotDenied": {
+			policy:      &policyapi.CertificateRequestPolicy{},
+			requestName: "denied",
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if the grpc plugin values are invalid, return Denied": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Plugins: map[string]policyapi.CertificateRequestPolicyPluginData{"grpc": {}},
+				},
+			},
+			requestName: "allowed",
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultDenied,
+				Message: "spec.plugins.grpc.values[endpoint]: Required value: the host:port address of the policy service must be defined",
+				Errors: field.ErrorList{
+					field.Required(fldPath.Child("values").Key("endpoint"), "the host:port address of the policy service must be defined"),
+				},
+			},
+		},
+		"if the policy service allows the request, return NotDenied": {
+			policy:      policyWithEndpoint(endpoint),
+			requestName: "allowed",
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if the policy service denies the request, return Denied with its messages": {
+			policy:      policyWithEndpoint(endpoint),
+			requestName: "denied",
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultDenied,
+				Message: "[spec.plugins.grpc: Forbidden: dns name not allowed, spec.plugins.grpc: Forbidden: too long]",
+				Errors: field.ErrorList{
+					field.Forbidden(fldPath, "dns name not allowed"),
+					field.Forbidden(fldPath, "too long"),
+				},
+			},
+		},
+		"if the policy service denies the request without messages, return Denied": {
+			policy:      policyWithEndpoint(endpoint),
+			requestName: "denied-without-messages",
+			expResponse: approver.EvaluationResponse{
+				Result:  approver.ResultDenied,
+				Message: "spec.plugins.grpc: Forbidden: request denied by policy service",
+				Errors: field.ErrorList{
+					field.Forbidden(fldPath, "request denied by policy service"),
+				},
+			},
+		},
+		"if the policy service is unavailable, return a transient error": {
+			policy:       policyWithEndpoint(endpoint),
+			requestName:  "unavailable",
+			expErr:       true,
+			expTransient: true,
+		},
+		"if the policy service can't be connected to, return a transient error": {
+			policy:       policyWithEndpoint(closedEndpoint),
+			requestName:  "allowed",
+			expErr:       true,
+			expTransient: true,
+		},
+		"if the policy service fails to evaluate the request, return an error which is not transient": {
+			policy:      policyWithEndpoint(endpoint),
+			requestName: "error",
+			expErr:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := gen.CertificateRequest(test.requestName)
+
+			response, err := newPlugin().Evaluate(context.TODO(), test.policy, request)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expTransient, approver.IsTransientError(err), "%v", err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	fldPath := field.NewPath("spec", "plugins", "grpc")
+
+	endpoint := startServer(t, &testServer{
+		validate: func(policy *policyapi.CertificateRequestPolicy) (*validatePolicyResponse, error) {
+			switch policy.Name {
+			case "allowed":
+				return &validatePolicyResponse{allowed: true, warnings: []string{"deprecated issuer"}}, nil
+			case "rejected":
+				return &validatePolicyResponse{errors: []string{"unknown team"}}, nil
+			default:
+				return nil, errors.New("unexpected policy")
+			}
+		},
+	})
+
+	namedPolicy := func(name string) *policyapi.CertificateRequestPolicy {
+		policy := policyWithEndpoint(endpoint)
+		policy.Name = name
+		return policy
+	}
+
+	tests := map[string]struct {
+		policy      *policyapi.CertificateRequestPolicy
+		expResponse approver.WebhookValidationResponse
+		expErr      bool
+	}{
+		"if the policy doesn't define the grpc plugin, return allowed": {
+			policy:      &policyapi.CertificateRequestPolicy{},
+			expResponse: approver.WebhookValidationResponse{Allowed: true},
+		},
+		"if the grpc plugin values are invalid, return not allowed": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+						"grpc": {Values: map[string]string{"endpoint": "no-port", "timeout": "0s", "foo": "bar"}},
+					},
+				},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Child("values").Key("endpoint"), "no-port", "address no-port: missing port in address"),
+					field.NotSupported(fldPath.Child("values").Key("foo"), "foo", []string{"endpoint", "timeout"}),
+					field.Invalid(fldPath.Child("values").Key("timeout"), "0s", "must be greater than 0"),
+				},
+			},
+		},
+		"if the policy service allows the policy, return allowed with its warnings": {
+			policy:      namedPolicy("allowed"),
+			expResponse: approver.WebhookValidationResponse{Allowed: true, Warnings: []string{"deprecated issuer"}},
+		},
+		"if the policy service rejects the policy, return not allowed with its errors": {
+			policy: namedPolicy("rejected"),
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors:  field.ErrorList{field.Forbidden(fldPath, "unknown team")},
+			},
+		},
+		"if the policy service fails to validate the policy, return an error": {
+			policy: namedPolicy("error"),
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, err := newPlugin().Validate(context.TODO(), test.policy)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
+
+func Test_messages(t *testing.T) {
+	// Messages should be unchanged by a round trip through the wire format.
+	messages := []struct {
+		in, out message
+	}{
+		{&validatePolicyRequest{policy: []byte(`{"a":1}`)}, new(validatePolicyRequest)},
+		{&validatePolicyResponse{allowed: true, errors: []string{"a", "b"}, warnings: []string{"c"}}, new(validatePolicyResponse)},
+		{&evaluateRequestRequest{policy: []byte("policy"), certificateRequest: []byte("request")}, new(evaluateRequestRequest)},
+		{&evaluateRequestResponse{allowed: true, messages: []string{"a"}}, new(evaluateRequestResponse)},
+	}
+
+	for _, m := range messages {
+		b, err := codec{}.Marshal(m.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := (codec{}).Unmarshal(b, m.out); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, m.in, m.out)
+	}
+
+	// Unknown fields should be skipped.
+	var resp evaluateRequestResponse
+	if err := (codec{}).Unmarshal([]byte{0x1d, 0x01, 0x02, 0x03, 0x04, 0x08, 0x01}, &resp); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, evaluateRequestResponse{allowed: true}, resp)
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The full method names of the ApproverPlugin service defined in
+// plugin.proto.
+const (
+	methodValidatePolicy  = "/approverpolicy.plugin.v1.ApproverPlugin/ValidatePolicy"
+	methodEvaluateRequest = "/approverpolicy.plugin.v1.ApproverPlugin/EvaluateRequest"
+)
+
+// message is a message of the ApproverPlugin service which is encoded in the
+// protobuf wire format.
+type message interface {
+	marshal() []byte
+	unmarshal([]byte) error
+}
+
+// validatePolicyRequest is the ValidatePolicyRequest message.
+type validatePolicyRequest struct {
+	policy []byte
+}
+
+func (m *validatePolicyRequest) marshal() []byte {
+	return appendBytes(nil, 1, m.policy)
+}
+
+func (m *validatePolicyRequest) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		if num == 1 && typ == protowire.BytesType {
+			m.policy = append([]byte(nil), v...)
+		}
+	})
+}
+
+// validatePolicyResponse is the ValidatePolicyResponse message.
+type validatePolicyResponse struct {
+	allowed  bool
+	errors   []string
+	warnings []string
+}
+
+func (m *validatePolicyResponse) marshal() []byte {
+	b := appendBool(nil, 1, m.allowed)
+	b = appendStrings(b, 2, m.errors)
+	return appendStrings(b, 3, m.warnings)
+}
+
+func (m *validatePolicyResponse) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, typ protowire.Type, v []byte, x uint64) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			m.allowed = protowire.DecodeBool(x)
+		case num == 2 && typ == protowire.BytesType:
+			m.errors = append(m.errors, string(v))
+		case num == 3 && typ == protowire.BytesType:
+			m.warnings = append(m.warnings, string(v))
+		}
+	})
+}
+
+// evaluateRequestRequest is the EvaluateRequestRequest message.
+type evaluateRequestRequest struct {
+	policy             []byte
+	certificateRequest []byte
+}
+
+func (m *evaluateRequestRequest) marshal() []byte {
+	b := appendBytes(nil, 1, m.policy)
+	return appendBytes(b, 2, m.certificateRequest)
+}
+
+func (m *evaluateRequestRequest) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			m.policy = append([]byte(nil), v...)
+		case num == 2 && typ == protowire.BytesType:
+			m.certificateRequest = append([]byte(nil), v...)
+		}
+	})
+}
+
+// evaluateRequestResponse is the EvaluateRequestResponse message.
+type evaluateRequestResponse struct {
+	allowed  bool
+	messages []string
+}
+
+func (m *evaluateRequestResponse) marshal() []byte {
+	b := appendBool(nil, 1, m.allowed)
+	return appendStrings(b, 2, m.messages)
+}
+
+func (m *evaluateRequestResponse) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, typ protowire.Type, v []byte, x uint64) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			m.allowed = protowire.DecodeBool(x)
+		case num == 2 && typ == protowire.BytesType:
+			m.messages = append(m.messages, string(v))
+		}
+	})
+}
+
+// appendBytes appends the bytes field to b, omitting it if empty as proto3
+// does.
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendBool appends the bool field to b, omitting it if false as proto3
+// does.
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeBool(v))
+}
+
+// appendStrings appends the repeated string field to b.
+func appendStrings(b []byte, num protowire.Number, vs []string) []byte {
+	for _, v := range vs {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	}
+	return b
+}
+
+// unmarshalFields decodes the fields of the protobuf encoded message b,
+// calling fn with the value of every length delimited or varint field.
+// Fields of other types are skipped.
+func unmarshalFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, x uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var (
+			v []byte
+			x uint64
+		)
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			x, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if typ == protowire.BytesType || typ == protowire.VarintType {
+			fn(num, typ, v, x)
+		}
+	}
+	return nil
+}
+
+// codec is a gRPC codec which encodes the messages of the ApproverPlugin
+// service in the protobuf wire format. It is named "proto" so that it is
+// interchangeable with servers using generated protobuf code.
+type codec struct{}
+
+// Marshal implements encoding.Codec.
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(message)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return m.marshal(), nil
+}
+
+// Unmarshal implements encoding.Codec.
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(message)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	return m.unmarshal(data)
+}
+
+// Name implements encoding.Codec.
+func (codec) Name() string {
+	return "proto"
+}
+
+// client is a client of the ApproverPlugin service.
+type client struct {
+	conn grpc.ClientConnInterface
+}
+
+// ValidatePolicy calls the ValidatePolicy method of the ApproverPlugin
+// service.
+func (c *client) ValidatePolicy(ctx context.Context, req *validatePolicyRequest) (*validatePolicyResponse, error) {
+	resp := new(validatePolicyResponse)
+	if err := c.conn.Invoke(ctx, methodValidatePolicy, req, resp, grpc.ForceCodec(codec{})); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// EvaluateRequest calls the EvaluateRequest method of the ApproverPlugin
+// service.
+func (c *client) EvaluateRequest(ctx context.Context, req *evaluateRequestRequest) (*evaluateRequestResponse, error) {
+	resp := new(evaluateRequestResponse)
+	if err := c.conn.Invoke(ctx, methodEvaluateRequest, req, resp, grpc.ForceCodec(codec{})); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
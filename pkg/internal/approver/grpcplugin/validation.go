@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+const (
+	// valueEndpoint is the plugin value key for the `host:port` address of the
+	// policy service, e.g. `policy.policy.svc:9443`. Required.
+	valueEndpoint = "endpoint"
+
+	// valueTimeout is the plugin value key for the timeout of calling the
+	// policy service, e.g. `5s`. Defaults to defaultTimeout.
+	valueTimeout = "timeout"
+
+	// defaultTimeout is the default timeout of calling the policy service.
+	defaultTimeout = time.Second * 5
+)
+
+// config is the parsed configuration of the grpc plugin for a policy.
+type config struct {
+	endpoint string
+	timeout  time.Duration
+}
+
+// Validate validates that the values of the grpc plugin are valid, and that
+// the policy is allowed by the policy service, if the plugin is defined on
+// the CertificateRequestPolicy. An error is returned if the policy service
+// could not be called.
+func (g *grpcPlugin) Validate(ctx context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+	plugin, ok := policy.Spec.Plugins[g.Name()]
+	if !ok {
+		return approver.WebhookValidationResponse{
+			Allowed: true,
+			Errors:  nil,
+		}, nil
+	}
+
+	fldPath := field.NewPath("spec", "plugins", g.Name())
+
+	cfg, el := parseConfig(fldPath.Child("values"), plugin.Values)
+	if len(el) > 0 {
+		return approver.WebhookValidationResponse{Allowed: false, Errors: el}, nil
+	}
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return approver.WebhookValidationResponse{}, fmt.Errorf("failed to encode policy: %w", err)
+	}
+
+	client, err := g.client(cfg.endpoint)
+	if err != nil {
+		return approver.WebhookValidationResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	resp, err := client.ValidatePolicy(ctx, &validatePolicyRequest{policy: policyJSON})
+	if err != nil {
+		return approver.WebhookValidationResponse{}, fmt.Errorf("failed to validate policy with policy service: %w", err)
+	}
+
+	for _, message := range resp.errors {
+		el = append(el, field.Forbidden(fldPath, message))
+	}
+	if !resp.allowed && len(el) == 0 {
+		el = append(el, field.Forbidden(fldPath, "policy rejected by policy service"))
+	}
+
+	return approver.WebhookValidationResponse{
+		Allowed:  resp.allowed,
+		Errors:   el,
+		Warnings: resp.warnings,
+	}, nil
+}
+
+// parseConfig parses the grpc plugin configuration from the given plugin
+// values.
+func parseConfig(fldPath *field.Path, values map[string]string) (config, field.ErrorList) {
+	var (
+		el  field.ErrorList
+		cfg = config{timeout: defaultTimeout}
+	)
+
+	// Sort keys so that errors are deterministic.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		switch key {
+		case valueEndpoint:
+			if host, port, err := net.SplitHostPort(value); err != nil {
+				el = append(el, field.Invalid(fldPath.Key(key), value, err.Error()))
+			} else if len(host) == 0 || len(port) == 0 {
+				el = append(el, field.Invalid(fldPath.Key(key), value, "must be a host:port address"))
+			}
+			cfg.endpoint = value
+
+		case valueTimeout:
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				el = append(el, field.Invalid(fldPath.Key(key), value, err.Error()))
+			} else if timeout <= 0 {
+				el = append(el, field.Invalid(fldPath.Key(key), value, "must be greater than 0"))
+			}
+			cfg.timeout = timeout
+
+		default:
+			el = append(el, field.NotSupported(fldPath.Key(key), key, []string{valueEndpoint, valueTimeout}))
+		}
+	}
+
+	if _, ok := values[valueEndpoint]; !ok {
+		el = append(el, field.Required(fldPath.Key(valueEndpoint), "the host:port address of the policy service must be defined"))
+	}
+
+	return cfg, el
+}
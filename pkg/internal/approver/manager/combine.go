@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
+)
+
+// CombineMode is how the decisions of the highest priority
+// CertificateRequestPolicies which select a CertificateRequest are combined
+// into the decision for the request.
+type CombineMode string
+
+const (
+	// CombineModeUnion approves a request if any selecting policy approves it,
+	// unless an authoritative policy denies it. This is the default.
+	CombineModeUnion CombineMode = "union"
+
+	// CombineModeIntersection approves a request only if every selecting
+	// policy approves it, as if every policy were authoritative. Only the
+	// selecting policies with the highest priority are evaluated, so policies
+	// of a lower priority are still ignored. Denials by policies with the
+	// Warn enforcement never deny requests, and are returned as warnings.
+	CombineModeIntersection CombineMode = "intersection"
+)
+
+// reviewIntersection returns the review response for the results of
+// evaluating the highest priority policies in the intersection combine mode.
+// No result is skipped in this mode, so every policy has a result.
+func (m *mngr) reviewIntersection(cr *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy, results []policyResult) (manager.ReviewResponse, error) {
+	var (
+		evaluations       []policyEvaluation
+		evaluatedPolicies []string
+	)
+	for i, result := range results {
+		if result.err != nil {
+			return manager.ReviewResponse{}, result.err
+		}
+		evaluations = append(evaluations, policyEvaluation{policy: &policies[i], denied: result.denied, message: result.message, reasons: result.reasons})
+		evaluatedPolicies = append(evaluatedPolicies, policies[i].Name)
+	}
+
+	approvers, policyMessages, warnMessages := intersection(evaluations)
+
+	// If any policy which doesn't have the Warn enforcement denied the
+	// request, the request is denied regardless of which policies approve it.
+	if len(policyMessages) > 0 {
+		if !m.unobserved {
+			for _, policyMessage := range policyMessages {
+				metrics.ObserveDenied(policyMessage.name, policyMessage.metricsLabels, cr)
+			}
+		}
+		return manager.ReviewResponse{
+			Result:            manager.ResultDenied,
+			Message:           deniedMessage(policyMessages),
+			Reasons:           deniedReasons(policyMessages),
+			EvaluatedPolicies: evaluatedPolicies,
+		}, nil
+	}
+
+	if !m.unobserved {
+		for _, policyMessage := range warnMessages {
+			metrics.ObserveWouldDeny(policyMessage.name, cr)
+		}
+	}
+
+	// If every policy has the Warn enforcement and would have denied the
+	// request, approve the request with warnings, as in the union combine
+	// mode.
+	if len(approvers) == 0 {
+		return manager.ReviewResponse{
+			Result:            manager.ResultApproved,
+			Message:           warnApprovedMessage(warnMessages[0].name),
+			ApprovedBy:        warnMessages[0].name,
+			Warnings:          warnings(warnMessages),
+			EvaluatedPolicies: evaluatedPolicies,
+		}, nil
+	}
+
+	// The request is approved by the first approving policy, so that its rate
+	// limit and reissue settings apply to the request.
+	if !m.unobserved {
+		metrics.ObserveApproved(approvers[0].Name, approvers[0].Spec.MetricsLabels, cr)
+	}
+	return manager.ReviewResponse{
+		Result:            manager.ResultApproved,
+		Message:           intersectionApprovedMessage(approvers),
+		ApprovedBy:        approvers[0].Name,
+		Warnings:          warnings(warnMessages),
+		EvaluatedPolicies: evaluatedPolicies,
+	}, nil
+}
+
+// decideIntersection returns the result, message and warnings that Review
+// would give for the given evaluations of the highest priority policies in
+// the intersection combine mode.
+func decideIntersection(evaluations []policyEvaluation) (DryRunResult, string, []string) {
+	approvers, policyMessages, warnMessages := intersection(evaluations)
+	switch {
+	case len(policyMessages) > 0:
+		return DryRunResultDenied, deniedMessage(policyMessages), nil
+	case len(approvers) == 0:
+		return DryRunResultApproved, warnApprovedMessage(warnMessages[0].name), warnings(warnMessages)
+	default:
+		return DryRunResultApproved, intersectionApprovedMessage(approvers), warnings(warnMessages)
+	}
+}
+
+// intersection returns the policies which approved the request, in policy
+// order, along with the messages of the policies which denied it,
+// separated by whether the policy has the Warn enforcement.
+func intersection(evaluations []policyEvaluation) ([]*policyapi.CertificateRequestPolicy, []policyMessage, []policyMessage) {
+	var (
+		approvers                    []*policyapi.CertificateRequestPolicy
+		policyMessages, warnMessages []policyMessage
+	)
+	for _, evaluation := range evaluations {
+		if !evaluation.denied {
+			approvers = append(approvers, evaluation.policy)
+			continue
+		}
+
+		message := policyMessage{name: evaluation.policy.Name, message: evaluation.message, reasons: evaluation.reasons, metricsLabels: evaluation.policy.Spec.MetricsLabels}
+		if enforcementWarn(evaluation.policy) {
+			warnMessages = append(warnMessages, message)
+		} else {
+			policyMessages = append(policyMessages, message)
+		}
+	}
+	return approvers, policyMessages, warnMessages
+}
+
+// intersectionApprovedMessage returns the review message for a request
+// approved by all of the given policies in the intersection combine mode.
+func intersectionApprovedMessage(policies []*policyapi.CertificateRequestPolicy) string {
+	if len(policies) == 1 {
+		return approvedMessage(policies[0].Name)
+	}
+	quoted := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		quoted = append(quoted, fmt.Sprintf("%q", policy.Name))
+	}
+	return fmt.Sprintf("Approved by all CertificateRequestPolicies: %s", strings.Join(quoted, ", "))
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// DryRunResult is the result of a dry run review of a CertificateRequest.
+type DryRunResult string
+
+const (
+	// DryRunResultApproved is the result of a dry run where the request would
+	// be approved.
+	DryRunResultApproved DryRunResult = "Approved"
+
+	// DryRunResultDenied is the result of a dry run where the request would be
+	// denied.
+	DryRunResultDenied DryRunResult = "Denied"
+
+	// DryRunResultUnprocessed is the result of a dry run where the request
+	// would be neither approved or denied, since no policies are applicable.
+	DryRunResultUnprocessed DryRunResult = "Unprocessed"
+)
+
+// DryRunResponse is the response of a dry run review of a CertificateRequest.
+type DryRunResponse struct {
+	// Result is the decision that would be made for the request.
+	Result DryRunResult `json:"result"`
+
+	// Message is the message that would be set on the request's condition.
+	Message string `json:"message"`
+
+	// Warnings are why policies with the Warn enforcement would have denied
+	// the request, if the request would be approved because of them.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Policies are the CertificateRequestPolicies which matched the request
+	// and were evaluated. Matching policies of a lower priority are not
+	// evaluated.
+	Policies []DryRunPolicy `json:"policies"`
+}
+
+// DryRunPolicy is the evaluation result of a single CertificateRequestPolicy
+// in a dry run.
+type DryRunPolicy struct {
+	// Name is the name of the CertificateRequestPolicy.
+	Name string `json:"name"`
+
+	// Approved is true if the policy would approve the request.
+	Approved bool `json:"approved"`
+
+	// Message is the aggregated message of all evaluators for this policy,
+	// containing the field errors if the policy would deny the request.
+	Message string `json:"message,omitempty"`
+}
+
+// DryRunner reviews CertificateRequests without approving or denying them.
+type DryRunner interface {
+	// DryRun reviews the given CertificateRequest in the same way as the
+	// approver Manager, and returns the decision that would be made. If
+	// policyName is not empty, only the CertificateRequestPolicy with that
+	// name is considered. DryRun never mutates cluster state.
+	DryRun(ctx context.Context, cr *cmapi.CertificateRequest, policyName string) (DryRunResponse, error)
+}
+
+// NewDryRunner constructs a new DryRunner that uses the same predicates,
+// evaluators and combine mode as the approver Manager.
+func NewDryRunner(lister client.Reader, client client.Client, evaluators []approver.Evaluator, combineMode CombineMode) DryRunner {
+	m := newManager(lister, client, evaluators)
+	m.combineMode = combineMode
+	return m
+}
+
+// DryRun implements DryRunner. Unlike Review, all matching policies are
+// evaluated so that each policy's result may be reported. The decision is
+// identical to Review.
+func (m *mngr) DryRun(ctx context.Context, cr *cmapi.CertificateRequest, policyName string) (DryRunResponse, error) {
+	ctx = util.WithIssuerMetadataCache(ctx)
+
+	policyList := new(policyapi.CertificateRequestPolicyList)
+	if err := m.lister.List(ctx, policyList); err != nil {
+		return DryRunResponse{}, err
+	}
+
+	policies := policyList.Items
+	if len(policyName) > 0 {
+		policies = nil
+		for _, policy := range policyList.Items {
+			if policy.Name == policyName {
+				policies = append(policies, policy)
+			}
+		}
+	}
+
+	if len(policies) == 0 {
+		return DryRunResponse{Result: DryRunResultUnprocessed, Message: messageNoPolicies, Policies: []DryRunPolicy{}}, nil
+	}
+
+	policies, err := m.filter(ctx, cr, policies)
+	if err != nil {
+		return DryRunResponse{}, err
+	}
+
+	// Only the policies with the highest priority are evaluated, as with
+	// Review.
+	policies = highestPriority(policies)
+
+	if len(policies) == 0 {
+		return DryRunResponse{Result: DryRunResultUnprocessed, Message: messageNoApplicablePolicies, Policies: []DryRunPolicy{}}, nil
+	}
+
+	evaluations, err := m.evaluateAll(ctx, policies, cr)
+	if err != nil {
+		return DryRunResponse{}, err
+	}
+
+	response := DryRunResponse{Policies: []DryRunPolicy{}}
+	for _, evaluation := range evaluations {
+		response.Policies = append(response.Policies, DryRunPolicy{Name: evaluation.policy.Name, Approved: !evaluation.denied, Message: evaluation.message})
+	}
+	response.Result, response.Message, response.Warnings = decide(evaluations, m.combineMode)
+
+	return response, nil
+}
+
+// policyEvaluation is the result of evaluating a single policy against a
+// request in a dry run.
+type policyEvaluation struct {
+	policy  *policyapi.CertificateRequestPolicy
+	denied  bool
+	message string
+	reasons []manager.DenialReason
+}
+
+// evaluateAll evaluates all of the given policies against the request, in
+// order. Unlike evaluatePolicies, policies after an approving policy are
+// still evaluated, so that each policy's result may be reported.
+func (m *mngr) evaluateAll(ctx context.Context, policies []policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) ([]policyEvaluation, error) {
+	evaluations := make([]policyEvaluation, 0, len(policies))
+	for i := range policies {
+		denied, message, reasons, err := m.evaluate(ctx, &policies[i], cr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate policy %q: %w", policies[i].Name, err)
+		}
+		evaluations = append(evaluations, policyEvaluation{policy: &policies[i], denied: denied, message: message, reasons: reasons})
+	}
+	return evaluations, nil
+}
+
+// decide returns the result, message and warnings that Review would give for
+// the given evaluations of the highest priority policies.
+func decide(evaluations []policyEvaluation, combineMode CombineMode) (DryRunResult, string, []string) {
+	if combineMode == CombineModeIntersection {
+		return decideIntersection(evaluations)
+	}
+
+	// A denial by any authoritative policy is final, as with Review.
+	var authoritativeMessages []policyMessage
+	for _, evaluation := range evaluations {
+		if authoritative(evaluation.policy) && evaluation.denied {
+			authoritativeMessages = append(authoritativeMessages, policyMessage{name: evaluation.policy.Name, message: evaluation.message})
+		}
+	}
+	if len(authoritativeMessages) > 0 {
+		return DryRunResultDenied, deniedMessage(authoritativeMessages), nil
+	}
+
+	var policyMessages, warnMessages []policyMessage
+	for _, evaluation := range evaluations {
+		// The first policy to approve makes the decision, as with Review.
+		if !evaluation.denied {
+			return DryRunResultApproved, approvedMessage(evaluation.policy.Name), nil
+		}
+
+		message := policyMessage{name: evaluation.policy.Name, message: evaluation.message}
+		if enforcementWarn(evaluation.policy) {
+			warnMessages = append(warnMessages, message)
+		} else {
+			policyMessages = append(policyMessages, message)
+		}
+	}
+
+	if len(warnMessages) > 0 {
+		return DryRunResultApproved, warnApprovedMessage(warnMessages[0].name), warnings(warnMessages)
+	}
+
+	return DryRunResultDenied, deniedMessage(policyMessages), nil
+}
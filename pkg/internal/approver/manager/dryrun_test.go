@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/manager/predicate"
+)
+
+func Test_DryRun(t *testing.T) {
+	policyA := &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test-policy-a"}}
+	policyB := &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test-policy-b"}}
+
+	warn := policyapi.EnforcementWarn
+
+	allPredicate := func(_ context.Context, _ *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		return policies, nil
+	}
+	denyPolicyA := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+		if policy.Name == "test-policy-a" {
+			return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "spec.allowed.dnsNames: Invalid value"}, nil
+		}
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	})
+	denyAll := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied " + policy.Name}, nil
+	})
+
+	tests := map[string]struct {
+		existingPolicies []runtime.Object
+		predicate        predicate.Predicate
+		evaluator        approver.Evaluator
+		policyName       string
+		expResponse      DryRunResponse
+		expErr           bool
+	}{
+		"if no CertificateRequestPolicies exist, return Unprocessed": {
+			predicate:   allPredicate,
+			evaluator:   denyAll,
+			expResponse: DryRunResponse{Result: DryRunResultUnprocessed, Message: "No CertificateRequestPolicies exist", Policies: []DryRunPolicy{}},
+		},
+		"if named policy doesn't exist, return Unprocessed": {
+			existingPolicies: []runtime.Object{policyA, policyB},
+			predicate:        allPredicate,
+			evaluator:        denyAll,
+			policyName:       "test-policy-c",
+			expResponse:      DryRunResponse{Result: DryRunResultUnprocessed, Message: "No CertificateRequestPolicies exist", Policies: []DryRunPolicy{}},
+		},
+		"if no policies pass the predicates, return Unprocessed": {
+			existingPolicies: []runtime.Object{policyA, policyB},
+			predicate: func(_ context.Context, _ *cmapi.CertificateRequest, _ []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+				return nil, nil
+			},
+			evaluator:   denyAll,
+			expResponse: DryRunResponse{Result: DryRunResultUnprocessed, Message: "No CertificateRequestPolicies bound or applicable", Policies: []DryRunPolicy{}},
+		},
+		"if predicate errors, return error": {
+			existingPolicies: []runtime.Object{policyA},
+			predicate: func(_ context.Context, _ *cmapi.CertificateRequest, _ []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+				return nil, errors.New("this is an error")
+			},
+			evaluator: denyAll,
+			expErr:    true,
+		},
+		"if one policy denies and the other approves, return Approved listing both policies": {
+			existingPolicies: []runtime.Object{policyA, policyB},
+			predicate:        allPredicate,
+			evaluator:        denyPolicyA,
+			expResponse: DryRunResponse{
+				Result:  DryRunResultApproved,
+				Message: `Approved by CertificateRequestPolicy: "test-policy-b"`,
+				Policies: []DryRunPolicy{
+					{Name: "test-policy-a", Approved: false, Message: "spec.allowed.dnsNames: Invalid value"},
+					{Name: "test-policy-b", Approved: true},
+				},
+			},
+		},
+		"if only the named policy is evaluated and denies, return Denied": {
+			existingPolicies: []runtime.Object{policyA, policyB},
+			predicate:        allPredicate,
+			evaluator:        denyPolicyA,
+			policyName:       "test-policy-a",
+			expResponse: DryRunResponse{
+				Result:  DryRunResultDenied,
+				Message: "No policy approved this request: [test-policy-a: spec.allowed.dnsNames: Invalid value]",
+				Policies: []DryRunPolicy{
+					{Name: "test-policy-a", Approved: false, Message: "spec.allowed.dnsNames: Invalid value"},
+				},
+			},
+		},
+		"if all policies deny, return Denied with the same message as Review": {
+			existingPolicies: []runtime.Object{policyA, policyB},
+			predicate:        allPredicate,
+			evaluator:        denyAll,
+			expResponse: DryRunResponse{
+				Result:  DryRunResultDenied,
+				Message: "No policy approved this request: [test-policy-a: denied test-policy-a] [test-policy-b: denied test-policy-b]",
+				Policies: []DryRunPolicy{
+					{Name: "test-policy-a", Approved: false, Message: "denied test-policy-a"},
+					{Name: "test-policy-b", Approved: false, Message: "denied test-policy-b"},
+				},
+			},
+		},
+		"if all policies deny and one has Warn enforcement, return Approved with warnings": {
+			existingPolicies: []runtime.Object{
+				policyA,
+				&policyapi.CertificateRequestPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-policy-b"},
+					Spec:       policyapi.CertificateRequestPolicySpec{Enforcement: &warn},
+				},
+			},
+			predicate: allPredicate,
+			evaluator: denyAll,
+			expResponse: DryRunResponse{
+				Result:   DryRunResultApproved,
+				Message:  `Approved by CertificateRequestPolicy: "test-policy-b" with Warn enforcement, which would have denied this request`,
+				Warnings: []string{`CertificateRequestPolicy "test-policy-b" would deny this request: denied test-policy-b`},
+				Policies: []DryRunPolicy{
+					{Name: "test-policy-a", Approved: false, Message: "denied test-policy-a"},
+					{Name: "test-policy-b", Approved: false, Message: "denied test-policy-b"},
+				},
+			},
+		},
+		"if a higher priority policy denies, return Denied even though a lower priority policy would approve": {
+			existingPolicies: []runtime.Object{
+				&policyapi.CertificateRequestPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-policy-a"},
+					Spec:       policyapi.CertificateRequestPolicySpec{Priority: pointer.Int(10)},
+				},
+				policyB,
+			},
+			predicate: allPredicate,
+			evaluator: denyPolicyA,
+			expResponse: DryRunResponse{
+				Result:  DryRunResultDenied,
+				Message: "No policy approved this request: [test-policy-a: spec.allowed.dnsNames: Invalid value]",
+				Policies: []DryRunPolicy{
+					{Name: "test-policy-a", Approved: false, Message: "spec.allowed.dnsNames: Invalid value"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingPolicies...).
+				Build()
+
+			mngr := &mngr{
+				lister:     fakeclient,
+				predicates: []predicate.Predicate{test.predicate},
+				evaluators: []approver.Evaluator{test.evaluator},
+			}
+
+			response, err := mngr.DryRun(context.TODO(), &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Name: "test-req"}}, test.policyName)
+			assert.Equalf(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
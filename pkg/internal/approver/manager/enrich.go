@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+// maxEnrichedValues is the maximum number of values that enrichers may add to
+// a single allowed field of a policy, for a single request.
+const maxEnrichedValues = 1024
+
+// enrichers returns the subset of evaluators which implement
+// approver.Enricher, in the order they are given.
+func enrichers(evaluators []approver.Evaluator) []approver.Enricher {
+	var enrichers []approver.Enricher
+	for _, evaluator := range evaluators {
+		if enricher, ok := evaluator.(approver.Enricher); ok {
+			enrichers = append(enrichers, enricher)
+		}
+	}
+	return enrichers
+}
+
+// enrich returns the policy with the values returned by all enrichers merged
+// into `spec.allowed`. If no enricher returns values, the given policy is
+// returned unchanged, otherwise a copy is returned.
+// Every enricher is called exactly once, with the original policy, so that
+// enrichers can't observe or compound each other's values. Enriched values
+// are de-duplicated, sorted, and appended after the policy's own values so
+// that the merge is deterministic regardless of enricher order.
+func (m *mngr) enrich(ctx context.Context, policy *policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (*policyapi.CertificateRequestPolicy, error) {
+	if len(m.enrichers) == 0 {
+		return policy, nil
+	}
+
+	var dnsNames, ipAddresses, uris, emailAddresses []string
+	for _, enricher := range m.enrichers {
+		response, err := enricher.Enrich(ctx, policy, cr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to enrich policy %q: %w", policy.Name, err)
+		}
+		dnsNames = append(dnsNames, response.DNSNames...)
+		ipAddresses = append(ipAddresses, response.IPAddresses...)
+		uris = append(uris, response.URIs...)
+		emailAddresses = append(emailAddresses, response.EmailAddresses...)
+	}
+
+	if len(dnsNames)+len(ipAddresses)+len(uris)+len(emailAddresses) == 0 {
+		return policy, nil
+	}
+
+	enriched := policy.DeepCopy()
+	if enriched.Spec.Allowed == nil {
+		enriched.Spec.Allowed = new(policyapi.CertificateRequestPolicyAllowed)
+	}
+	allowed := enriched.Spec.Allowed
+
+	for _, field := range []struct {
+		name   string
+		slice  **policyapi.CertificateRequestPolicyAllowedStringSlice
+		values []string
+	}{
+		{"dnsNames", &allowed.DNSNames, dnsNames},
+		{"ipAddresses", &allowed.IPAddresses, ipAddresses},
+		{"uris", &allowed.URIs, uris},
+		{"emailAddresses", &allowed.EmailAddresses, emailAddresses},
+	} {
+		if len(field.values) == 0 {
+			continue
+		}
+		if err := mergeValues(field.slice, field.values); err != nil {
+			return nil, fmt.Errorf("failed to enrich policy %q field spec.allowed.%s: %w", policy.Name, field.name, err)
+		}
+	}
+
+	return enriched, nil
+}
+
+// mergeValues appends the given values to the values of the allowed string
+// slice, creating it if it is nil. Values which are already allowed are
+// skipped, and the remaining values are appended in sorted order.
+func mergeValues(slice **policyapi.CertificateRequestPolicyAllowedStringSlice, values []string) error {
+	if *slice == nil {
+		*slice = new(policyapi.CertificateRequestPolicyAllowedStringSlice)
+	}
+	if (*slice).Values == nil {
+		(*slice).Values = new([]string)
+	}
+
+	existing := sets.New[string](*(*slice).Values...)
+	added := sets.New[string](values...).Difference(existing)
+	if added.Len() > maxEnrichedValues {
+		return fmt.Errorf("enrichers returned %d values, which exceeds the maximum of %d", added.Len(), maxEnrichedValues)
+	}
+
+	*(*slice).Values = append(*(*slice).Values, sets.List(added)...)
+
+	return nil
+}
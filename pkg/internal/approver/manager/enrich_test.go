@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+)
+
+func Test_enrich(t *testing.T) {
+	enricher := func(response approver.EnrichResponse, err error) approver.Evaluator {
+		return fake.NewFakeEvaluator().WithEnrich(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EnrichResponse, error) {
+			// Enrichers must always be given the original policy.
+			assert.Equal(t, &[]string{"a.example.com"}, policy.Spec.Allowed.DNSNames.Values)
+			return response, err
+		})
+	}
+
+	basePolicy := &policyapi.CertificateRequestPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+		Spec: policyapi.CertificateRequestPolicySpec{
+			Allowed: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"a.example.com"}},
+			},
+		},
+	}
+
+	tooManyValues := make([]string, maxEnrichedValues+1)
+	for i := range tooManyValues {
+		tooManyValues[i] = fmt.Sprintf("%d.example.com", i)
+	}
+
+	tests := map[string]struct {
+		evaluators []approver.Evaluator
+		expPolicy  *policyapi.CertificateRequestPolicy
+		expErr     bool
+	}{
+		"if no evaluators are enrichers, return the policy unchanged": {
+			evaluators: []approver.Evaluator{struct{ approver.Evaluator }{fake.NewFakeEvaluator()}},
+			expPolicy:  basePolicy,
+		},
+		"if enrichers return no values, return the policy unchanged": {
+			evaluators: []approver.Evaluator{enricher(approver.EnrichResponse{}, nil)},
+			expPolicy:  basePolicy,
+		},
+		"if an enricher errors, return error": {
+			evaluators: []approver.Evaluator{enricher(approver.EnrichResponse{}, errors.New("error"))},
+			expErr:     true,
+		},
+		"if enrichers return too many values, return error": {
+			evaluators: []approver.Evaluator{enricher(approver.EnrichResponse{DNSNames: tooManyValues}, nil)},
+			expErr:     true,
+		},
+		"if enrichers return values, merge de-duplicated and sorted values after the policy's values": {
+			evaluators: []approver.Evaluator{
+				enricher(approver.EnrichResponse{DNSNames: []string{"c.example.com", "a.example.com"}, IPAddresses: []string{"10.0.0.1"}}, nil),
+				enricher(approver.EnrichResponse{DNSNames: []string{"b.example.com", "c.example.com"}, URIs: []string{"spiffe://example.com/foo"}}, nil),
+			},
+			expPolicy: &policyapi.CertificateRequestPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"a.example.com", "b.example.com", "c.example.com"}},
+						IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.1"}},
+						URIs:        &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://example.com/foo"}},
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := basePolicy.DeepCopy()
+			m := newManager(nil, nil, test.evaluators)
+
+			enriched, err := m.enrich(context.TODO(), policy, new(cmapi.CertificateRequest))
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expPolicy, enriched)
+			assert.Equal(t, basePolicy, policy, "the given policy must not be modified")
+		})
+	}
+}
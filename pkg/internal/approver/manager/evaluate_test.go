@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+)
+
+// policies returns n policies named policy-000, policy-001, ...
+func policies(n int) []policyapi.CertificateRequestPolicy {
+	policies := make([]policyapi.CertificateRequestPolicy, n)
+	for i := range policies {
+		policies[i] = policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("policy-%03d", i)}}
+	}
+	return policies
+}
+
+func Test_evaluatePolicies(t *testing.T) {
+	tests := map[string]struct {
+		workers  int
+		approve  map[string]bool
+		errors   map[string]bool
+		delay    map[string]time.Duration
+		policies int
+
+		expResponse manager.ReviewResponse
+		expErr      bool
+	}{
+		"if all policies deny, all should be evaluated and return denied": {
+			workers:  3,
+			policies: 3,
+			expResponse: manager.ReviewResponse{
+				Result:  manager.ResultDenied,
+				Message: "No policy approved this request: [policy-000: denied] [policy-001: denied] [policy-002: denied]",
+				Reasons: []manager.DenialReason{
+					{Policy: "policy-000", Detail: "denied"},
+					{Policy: "policy-001", Detail: "denied"},
+					{Policy: "policy-002", Detail: "denied"},
+				},
+				EvaluatedPolicies: []string{"policy-000", "policy-001", "policy-002"},
+			},
+		},
+		"if a later policy approves before an earlier one, the first approving policy by name should be returned": {
+			workers:  4,
+			policies: 4,
+			approve:  map[string]bool{"policy-001": true, "policy-003": true},
+			delay:    map[string]time.Duration{"policy-001": time.Millisecond * 50},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-001"`,
+				ApprovedBy:        "policy-001",
+				EvaluatedPolicies: []string{"policy-000", "policy-001"},
+			},
+		},
+		"if a policy errors after an approving policy, the request should be approved": {
+			workers:  2,
+			policies: 3,
+			approve:  map[string]bool{"policy-000": true},
+			errors:   map[string]bool{"policy-001": true},
+			delay:    map[string]time.Duration{"policy-000": time.Millisecond * 50},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-000"`,
+				ApprovedBy:        "policy-000",
+				EvaluatedPolicies: []string{"policy-000"},
+			},
+		},
+		"if a policy errors before an approving policy, an error should be returned": {
+			workers:     2,
+			policies:    2,
+			approve:     map[string]bool{"policy-001": true},
+			errors:      map[string]bool{"policy-000": true},
+			delay:       map[string]time.Duration{"policy-000": time.Millisecond * 50},
+			expResponse: manager.ReviewResponse{},
+			expErr:      true,
+		},
+		"if evaluated sequentially, policies after the approving policy should not be evaluated": {
+			workers:  1,
+			policies: 3,
+			approve:  map[string]bool{"policy-000": true},
+			errors:   map[string]bool{"policy-001": true, "policy-002": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-000"`,
+				ApprovedBy:        "policy-000",
+				EvaluatedPolicies: []string{"policy-000"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+				time.Sleep(test.delay[policy.Name])
+				if test.errors[policy.Name] {
+					return approver.EvaluationResponse{}, errors.New("this is an error")
+				}
+				if test.approve[policy.Name] {
+					return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+				}
+				return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied"}, nil
+			})
+
+			mngr := &mngr{
+				lister:     newPolicyLister(policies(test.policies)),
+				evaluators: []approver.Evaluator{evaluator},
+				workers:    test.workers,
+			}
+
+			response, err := mngr.Review(context.TODO(), new(cmapi.CertificateRequest))
+			assert.Equalf(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
+
+// newPolicyLister returns a fake client which lists the given policies.
+func newPolicyLister(policies []policyapi.CertificateRequestPolicy) client.Reader {
+	objs := make([]client.Object, len(policies))
+	for i := range policies {
+		objs[i] = &policies[i]
+	}
+	return fakeclient.NewClientBuilder().WithScheme(policyapi.GlobalScheme).WithObjects(objs...).Build()
+}
+
+// Benchmark_Review reviews a request against 500 policies, all of which take
+// some time to evaluate. Only the last policy approves the request.
+func Benchmark_Review(b *testing.B) {
+	const numPolicies = 500
+
+	policies := policies(numPolicies)
+	lastPolicy := policies[numPolicies-1].Name
+
+	evaluator := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+		time.Sleep(time.Microsecond * 50)
+		if policy.Name == lastPolicy {
+			return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+		}
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied"}, nil
+	})
+
+	for _, workers := range []int{1, defaultEvaluationWorkers} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			mngr := &mngr{
+				lister:     newPolicyLister(policies),
+				evaluators: []approver.Evaluator{evaluator},
+				workers:    workers,
+			}
+
+			durations := make([]time.Duration, 0, b.N)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				response, err := mngr.Review(context.TODO(), new(cmapi.CertificateRequest))
+				durations = append(durations, time.Since(start))
+				if err != nil || response.Result != manager.ResultApproved {
+					b.Fatalf("unexpected review response: %v %v", response, err)
+				}
+			}
+			b.StopTimer()
+
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			b.ReportMetric(float64(durations[len(durations)*99/100].Microseconds()), "p99-µs")
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// ExplainResponse explains how a CertificateRequest is reviewed against every
+// CertificateRequestPolicy in the cluster.
+type ExplainResponse struct {
+	// Result is the decision that would be made for the request.
+	Result DryRunResult `json:"result"`
+
+	// Message is the message that would be set on the request's condition.
+	Message string `json:"message"`
+
+	// Warnings are why policies with the Warn enforcement would have denied
+	// the request, if the request would be approved because of them.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Policies are all CertificateRequestPolicies in the cluster, sorted by
+	// name.
+	Policies []ExplainPolicy `json:"policies"`
+}
+
+// ExplainPolicy explains how a single CertificateRequestPolicy reviewed a
+// CertificateRequest.
+type ExplainPolicy struct {
+	// Name is the name of the CertificateRequestPolicy.
+	Name string `json:"name"`
+
+	// Matched is true if the policy passed all predicates for the request.
+	Matched bool `json:"matched"`
+
+	// Evaluated is true if the policy was evaluated against the request. A
+	// matching policy isn't evaluated if a matching policy of a higher
+	// priority exists.
+	Evaluated bool `json:"evaluated"`
+
+	// Approved is true if the policy was evaluated and would approve the
+	// request.
+	Approved bool `json:"approved"`
+
+	// Reason is why the policy didn't match, or wasn't evaluated.
+	Reason string `json:"reason,omitempty"`
+
+	// Denials are the policy fields which caused the policy to deny the
+	// request.
+	Denials []manager.DenialReason `json:"denials,omitempty"`
+}
+
+// Explainer explains how CertificateRequests are reviewed, without approving
+// or denying them.
+type Explainer interface {
+	// Explain reviews the given CertificateRequest in the same way as the
+	// approver Manager, and returns the decision that would be made along with
+	// why each CertificateRequestPolicy did or didn't match, and the fields of
+	// each evaluated policy that denied the request. Explain never mutates
+	// cluster state.
+	Explain(ctx context.Context, cr *cmapi.CertificateRequest) (ExplainResponse, error)
+}
+
+// NewExplainer constructs a new Explainer that uses the same predicates,
+// evaluators and combine mode as the approver Manager.
+func NewExplainer(lister client.Reader, client client.Client, evaluators []approver.Evaluator, combineMode CombineMode) Explainer {
+	m := newManager(lister, client, evaluators)
+	m.combineMode = combineMode
+	return m
+}
+
+// messageHigherPriority is the reason a matching policy isn't evaluated.
+const messageHigherPriority = "a matching policy of a higher priority is evaluated instead"
+
+// Explain implements Explainer. Each policy is run through the predicates on
+// its own, so that the first predicate which excluded it may be reported.
+func (m *mngr) Explain(ctx context.Context, cr *cmapi.CertificateRequest) (ExplainResponse, error) {
+	ctx = util.WithIssuerMetadataCache(ctx)
+
+	policyList := new(policyapi.CertificateRequestPolicyList)
+	if err := m.lister.List(ctx, policyList); err != nil {
+		return ExplainResponse{}, err
+	}
+
+	policies := policyList.Items
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	response := ExplainResponse{Policies: make([]ExplainPolicy, len(policies))}
+	if len(policies) == 0 {
+		response.Result, response.Message = DryRunResultUnprocessed, messageNoPolicies
+		return response, nil
+	}
+
+	var (
+		matched   []policyapi.CertificateRequestPolicy
+		explained = make(map[string]*ExplainPolicy, len(policies))
+	)
+	for i, policy := range policies {
+		response.Policies[i].Name = policy.Name
+		explained[policy.Name] = &response.Policies[i]
+
+		reason, err := m.excludedReason(ctx, cr, policy)
+		if err != nil {
+			return ExplainResponse{}, err
+		}
+		if len(reason) > 0 {
+			response.Policies[i].Reason = reason
+			continue
+		}
+		response.Policies[i].Matched = true
+		matched = append(matched, policy)
+	}
+
+	// Only the policies with the highest priority are evaluated, as with
+	// Review.
+	evaluated := highestPriority(matched)
+	if len(evaluated) == 0 {
+		response.Result, response.Message = DryRunResultUnprocessed, messageNoApplicablePolicies
+		return response, nil
+	}
+
+	for _, policy := range matched {
+		explained[policy.Name].Reason = messageHigherPriority
+	}
+
+	evaluations, err := m.evaluateAll(ctx, evaluated, cr)
+	if err != nil {
+		return ExplainResponse{}, err
+	}
+
+	for _, evaluation := range evaluations {
+		policy := explained[evaluation.policy.Name]
+		policy.Evaluated = true
+		policy.Approved = !evaluation.denied
+		policy.Reason = ""
+		policy.Denials = evaluation.reasons
+	}
+	response.Result, response.Message, response.Warnings = decide(evaluations, m.combineMode)
+
+	return response, nil
+}
+
+// excludedReason returns why the first predicate which excludes the policy
+// from the request did so. Returns an empty string if the policy passes all
+// predicates.
+func (m *mngr) excludedReason(ctx context.Context, cr *cmapi.CertificateRequest, policy policyapi.CertificateRequestPolicy) (string, error) {
+	for i, predicate := range m.predicates {
+		policies, err := predicate(ctx, cr, []policyapi.CertificateRequestPolicy{policy})
+		if err != nil {
+			return "", fmt.Errorf("failed to perform predicate on policy %q: %w", policy.Name, err)
+		}
+		if len(policies) > 0 {
+			continue
+		}
+		if i < len(m.predicateReasons) {
+			return m.predicateReasons[i], nil
+		}
+		return fmt.Sprintf("excluded by predicate %d", i), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/manager/predicate"
+)
+
+func Test_Explain(t *testing.T) {
+	policy := func(name string, priority int) *policyapi.CertificateRequestPolicy {
+		return &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       policyapi.CertificateRequestPolicySpec{Priority: pointer.Int(priority)},
+		}
+	}
+
+	// excludeNamed returns a predicate which excludes the policies with the
+	// given name.
+	excludeNamed := func(name string) predicate.Predicate {
+		return func(_ context.Context, _ *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+			var matched []policyapi.CertificateRequestPolicy
+			for _, policy := range policies {
+				if policy.Name != name {
+					matched = append(matched, policy)
+				}
+			}
+			return matched, nil
+		}
+	}
+
+	denyPolicyA := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+		if policy.Name == "test-policy-a" {
+			return approver.EvaluationResponse{
+				Result:  approver.ResultDenied,
+				Message: "spec.allowed.dnsNames.values: Invalid value",
+				Errors:  field.ErrorList{field.Invalid(field.NewPath("spec", "allowed", "dnsNames", "values"), []string{"example.com"}, "*.example.net")},
+			}, nil
+		}
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	})
+
+	tests := map[string]struct {
+		existingPolicies []runtime.Object
+		predicates       []predicate.Predicate
+		expResponse      ExplainResponse
+	}{
+		"if no CertificateRequestPolicies exist, return Unprocessed": {
+			predicates:  []predicate.Predicate{excludeNamed("")},
+			expResponse: ExplainResponse{Result: DryRunResultUnprocessed, Message: "No CertificateRequestPolicies exist", Policies: []ExplainPolicy{}},
+		},
+		"if no policies match, return Unprocessed with the reason of the first predicate which excluded each policy": {
+			existingPolicies: []runtime.Object{policy("test-policy-a", 0), policy("test-policy-b", 0)},
+			predicates:       []predicate.Predicate{excludeNamed("test-policy-b"), excludeNamed("test-policy-a"), excludeNamed("test-policy-b")},
+			expResponse: ExplainResponse{
+				Result:  DryRunResultUnprocessed,
+				Message: "No CertificateRequestPolicies bound or applicable",
+				Policies: []ExplainPolicy{
+					{Name: "test-policy-a", Reason: "second"},
+					{Name: "test-policy-b", Reason: "first"},
+				},
+			},
+		},
+		"only the highest priority matching policies should be evaluated, with the fields which denied the request": {
+			existingPolicies: []runtime.Object{policy("test-policy-a", 10), policy("test-policy-b", 0), policy("test-policy-c", 10), policy("test-policy-d", 20)},
+			predicates:       []predicate.Predicate{excludeNamed("test-policy-d")},
+			expResponse: ExplainResponse{
+				Result:  DryRunResultApproved,
+				Message: `Approved by CertificateRequestPolicy: "test-policy-c"`,
+				Policies: []ExplainPolicy{
+					{
+						Name: "test-policy-a", Matched: true, Evaluated: true, Approved: false,
+						Denials: []manager.DenialReason{{Policy: "test-policy-a", Field: "spec.allowed.dnsNames.values", Detail: `Invalid value: []string{"example.com"}: *.example.net`}},
+					},
+					{Name: "test-policy-b", Matched: true, Reason: "a matching policy of a higher priority is evaluated instead"},
+					{Name: "test-policy-c", Matched: true, Evaluated: true, Approved: true},
+					{Name: "test-policy-d", Reason: "first"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingPolicies...).
+				Build()
+
+			mngr := &mngr{
+				lister:           fakeclient,
+				predicates:       test.predicates,
+				predicateReasons: []string{"first", "second"},
+				evaluators:       []approver.Evaluator{denyPolicyA},
+			}
+
+			response, err := mngr.Explain(context.TODO(), &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Name: "test-req"}})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
+
+func Test_newManagerPredicateReasons(t *testing.T) {
+	mngr := newManager(nil, nil, nil)
+	assert.Len(t, mngr.predicateReasons, len(mngr.predicates), "every default predicate should have a reason")
+}
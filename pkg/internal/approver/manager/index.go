@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+const (
+	// IndexSelector is the field index of CertificateRequestPolicies by the
+	// issuer names selected by `spec.selector.issuerRef.name` or
+	// `spec.selector.issuerRefs[*].name`, and the namespaces selected by
+	// `spec.selector.namespace.matchNames`, as values of
+	// `<issuer name>/<namespace>`.
+	IndexSelector = "spec.selector.issuerRef.name/namespace.matchNames"
+
+	// indexAny is the index value of policies which may select any issuer
+	// name, or namespace, because the selector is omitted or contains a
+	// wildcard. "*" is not a valid name of an issuer or namespace, so never
+	// collides with a selected name.
+	indexAny = "*"
+)
+
+// RegisterIndexes registers the field indexes of CertificateRequestPolicies
+// used to select candidate policies for a request without listing all
+// policies. Must be called before the cache is started, and before any
+// manager constructed with NewIndexed lists policies.
+func RegisterIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, new(policyapi.CertificateRequestPolicy), IndexSelector, selectorIndex); err != nil {
+		return fmt.Errorf("failed to index CertificateRequestPolicies by %s: %w", IndexSelector, err)
+	}
+	return nil
+}
+
+// selectorIndex returns the index values of every issuer name and namespace
+// pair that the policy may select, where indexAny is used if the policy may
+// select any issuer name, or any namespace. The issuer names of every
+// `issuerRefs` entry are indexed, unless any entry may select any name. Namespaces selected by name may
+// be further filtered by the label selector of the policy.
+func selectorIndex(obj client.Object) []string {
+	policy, ok := obj.(*policyapi.CertificateRequestPolicy)
+	if !ok {
+		return nil
+	}
+
+	issuerNames := []string{indexAny}
+	if issRefSels := util.IssuerRefSelectors(&policy.Spec.Selector); len(issRefSels) > 0 {
+		names := sets.New[string]()
+		for _, issRefSel := range issRefSels {
+			if issRefSel.Name == nil || strings.Contains(*issRefSel.Name, "*") {
+				names = nil
+				break
+			}
+			names.Insert(*issRefSel.Name)
+		}
+		if names != nil {
+			issuerNames = sets.List(names)
+		}
+	}
+
+	namespaces := []string{indexAny}
+	if nsSel := policy.Spec.Selector.Namespace; nsSel != nil && len(nsSel.MatchNames) > 0 {
+		namespaces = nsSel.MatchNames
+		for _, name := range nsSel.MatchNames {
+			if strings.Contains(name, "*") {
+				namespaces = []string{indexAny}
+				break
+			}
+		}
+	}
+
+	values := make([]string, 0, len(issuerNames)*len(namespaces))
+	for _, issuerName := range issuerNames {
+		for _, namespace := range namespaces {
+			values = append(values, selectorIndexValue(issuerName, namespace))
+		}
+	}
+	return values
+}
+
+// selectorIndexValue returns the value of IndexSelector for the issuer name
+// and namespace.
+func selectorIndexValue(issuerName, namespace string) string {
+	return issuerName + "/" + namespace
+}
+
+// candidates returns the policies which may select the request, along with
+// whether any policy exists. If the manager is indexed, only the policies
+// whose issuer name and namespace selectors may match the request are
+// listed, otherwise all policies are returned. Candidates must still be
+// filtered by the predicates.
+func (m *mngr) candidates(ctx context.Context, cr *cmapi.CertificateRequest) ([]policyapi.CertificateRequestPolicy, bool, error) {
+	if !m.indexed {
+		policyList := new(policyapi.CertificateRequestPolicyList)
+		if err := m.lister.List(ctx, policyList); err != nil {
+			return nil, false, err
+		}
+		return policyList.Items, len(policyList.Items) > 0, nil
+	}
+
+	// Every policy has exactly one index value for each distinct issuer name
+	// and namespace it selects, and a request's issuer name matches at most one
+	// of them, so the policies listed for each value are distinct.
+	var policies []policyapi.CertificateRequestPolicy
+	for _, issuerName := range []string{cr.Spec.IssuerRef.Name, indexAny} {
+		for _, namespace := range []string{cr.Namespace, indexAny} {
+			policyList := new(policyapi.CertificateRequestPolicyList)
+			if err := m.lister.List(ctx, policyList, client.MatchingFields{IndexSelector: selectorIndexValue(issuerName, namespace)}); err != nil {
+				return nil, false, err
+			}
+			policies = append(policies, policyList.Items...)
+		}
+	}
+	if len(policies) > 0 {
+		return policies, true, nil
+	}
+
+	// Whether any policy exists changes the message of unprocessed requests.
+	policyList := new(policyapi.CertificateRequestPolicyList)
+	if err := m.lister.List(ctx, policyList, client.Limit(1)); err != nil {
+		return nil, false, err
+	}
+	return nil, len(policyList.Items) > 0, nil
+}
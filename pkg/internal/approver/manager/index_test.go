@@ -0,0 +1,265 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+)
+
+// newIndexedPolicyLister returns a fake lister of the given policies with the
+// indexes registered by RegisterIndexes.
+func newIndexedPolicyLister(policies []policyapi.CertificateRequestPolicy) client.Reader {
+	objs := make([]client.Object, len(policies))
+	for i := range policies {
+		objs[i] = &policies[i]
+	}
+	return fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithObjects(objs...).
+		WithIndex(new(policyapi.CertificateRequestPolicy), IndexSelector, selectorIndex).
+		Build()
+}
+
+// indexerLister lists policies from a client-go Indexer using the indexes
+// registered by RegisterIndexes, as the informer cache does.
+type indexerLister struct {
+	indexer toolscache.Indexer
+}
+
+func newIndexerLister(policies []policyapi.CertificateRequestPolicy) (client.Reader, error) {
+	indexer := toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, toolscache.Indexers{
+		IndexSelector: func(obj interface{}) ([]string, error) {
+			return selectorIndex(obj.(client.Object)), nil
+		},
+	})
+	for i := range policies {
+		if err := indexer.Add(policies[i].DeepCopy()); err != nil {
+			return nil, err
+		}
+	}
+	return &indexerLister{indexer: indexer}, nil
+}
+
+func (i *indexerLister) Get(context.Context, client.ObjectKey, client.Object, ...client.GetOption) error {
+	return errors.New("not implemented")
+}
+
+func (i *indexerLister) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := new(client.ListOptions)
+	listOpts.ApplyOptions(opts)
+
+	objs := i.indexer.List()
+	if listOpts.FieldSelector != nil {
+		requirements := listOpts.FieldSelector.Requirements()
+		if len(requirements) != 1 || requirements[0].Field != IndexSelector {
+			return fmt.Errorf("unsupported field selector %q", listOpts.FieldSelector)
+		}
+		var err error
+		if objs, err = i.indexer.ByIndex(IndexSelector, requirements[0].Value); err != nil {
+			return err
+		}
+	}
+	if listOpts.Limit > 0 && int64(len(objs)) > listOpts.Limit {
+		objs = objs[:listOpts.Limit]
+	}
+
+	policyList := list.(*policyapi.CertificateRequestPolicyList)
+	policyList.Items = make([]policyapi.CertificateRequestPolicy, 0, len(objs))
+	for _, obj := range objs {
+		policyList.Items = append(policyList.Items, *obj.(*policyapi.CertificateRequestPolicy).DeepCopy())
+	}
+	return nil
+}
+
+// countingLister counts the List calls made, and the policies listed.
+type countingLister struct {
+	client.Reader
+	lists, listed int
+}
+
+func (c *countingLister) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.Reader.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	c.lists++
+	if policyList, ok := list.(*policyapi.CertificateRequestPolicyList); ok {
+		c.listed += len(policyList.Items)
+	}
+	return nil
+}
+
+func Test_candidates(t *testing.T) {
+	policy := func(name string, issuerRef *policyapi.CertificateRequestPolicySelectorIssuerRef, namespace *policyapi.CertificateRequestPolicySelectorNamespace) policyapi.CertificateRequestPolicy {
+		return policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: issuerRef, Namespace: namespace},
+			},
+		}
+	}
+
+	issuerRefs := func(name string, issuerNames ...string) policyapi.CertificateRequestPolicy {
+		policy := policy(name, nil, nil)
+		for _, issuerName := range issuerNames {
+			policy.Spec.Selector.IssuerRefs = append(policy.Spec.Selector.IssuerRefs, policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String(issuerName)})
+		}
+		return policy
+	}
+
+	request := gen.CertificateRequest("test",
+		gen.SetCertificateRequestNamespace("team-a"),
+		gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "my-issuer", Kind: "Issuer", Group: "cert-manager.io"}),
+	)
+
+	tests := map[string]struct {
+		policies      []policyapi.CertificateRequestPolicy
+		expCandidates []string
+		expExist      bool
+	}{
+		"if no policies exist, return no candidates and that none exist": {
+			policies:      nil,
+			expCandidates: nil,
+			expExist:      false,
+		},
+		"if policies select the issuer name and namespace exactly or with wildcards, return them": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("no-selectors", nil, nil),
+				policy("exact-issuer", &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-issuer")}, nil),
+				policy("wildcard-issuer", &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-*")}, nil),
+				policy("kind-only-issuer", &policyapi.CertificateRequestPolicySelectorIssuerRef{Kind: pointer.String("ClusterIssuer")}, nil),
+				policy("exact-namespace", nil, &policyapi.CertificateRequestPolicySelectorNamespace{MatchNames: []string{"team-b", "team-a"}}),
+				policy("wildcard-namespace", nil, &policyapi.CertificateRequestPolicySelectorNamespace{MatchNames: []string{"team-b", "team-*"}}),
+				policy("labels-namespace", nil, &policyapi.CertificateRequestPolicySelectorNamespace{MatchLabels: map[string]string{"team": "a"}}),
+			},
+			expCandidates: []string{"exact-issuer", "exact-namespace", "kind-only-issuer", "labels-namespace", "no-selectors", "wildcard-issuer", "wildcard-namespace"},
+			expExist:      true,
+		},
+		"if policies select the issuer name with any of their issuerRefs, return them once": {
+			policies: []policyapi.CertificateRequestPolicy{
+				issuerRefs("two-issuers", "other-issuer", "my-issuer"),
+				issuerRefs("duplicate-issuers", "my-issuer", "my-issuer"),
+				issuerRefs("wildcard-issuer", "other-issuer", "my-*"),
+				issuerRefs("other-issuers", "other-issuer", "another-issuer"),
+			},
+			expCandidates: []string{"duplicate-issuers", "two-issuers", "wildcard-issuer"},
+			expExist:      true,
+		},
+		"if policies select other issuer names or namespaces, don't return them": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("other-issuer", &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("other-issuer")}, nil),
+				policy("other-namespace", nil, &policyapi.CertificateRequestPolicySelectorNamespace{MatchNames: []string{"team-b"}}),
+				policy("other-issuer-and-namespace", &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-issuer")}, &policyapi.CertificateRequestPolicySelectorNamespace{MatchNames: []string{"team-b"}}),
+			},
+			expCandidates: nil,
+			expExist:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mngr{lister: newIndexedPolicyLister(test.policies), indexed: true}
+			candidates, exist, err := m.candidates(context.TODO(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, policy := range candidates {
+				names = append(names, policy.Name)
+			}
+			sort.Strings(names)
+
+			assert.Equal(t, test.expCandidates, names)
+			assert.Equal(t, test.expExist, exist)
+		})
+	}
+}
+
+// Benchmark_Review_indexed reviews a request against 1000 policies which each
+// select a different issuer, of which only one selects the request. Policies
+// are listed from an indexer, as from the informer cache. Reports the List
+// calls made and policies listed per review, with and without indexes.
+func Benchmark_Review_indexed(b *testing.B) {
+	const numPolicies = 1000
+
+	policies := policies(numPolicies)
+	for i := range policies {
+		policies[i].Spec.Selector.IssuerRef = &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String(fmt.Sprintf("issuer-%03d", i))}
+		policies[i].Status.Conditions = []policyapi.CertificateRequestPolicyCondition{{Type: policyapi.CertificateRequestPolicyConditionReady, Status: corev1.ConditionTrue}}
+	}
+
+	request := gen.CertificateRequest("test",
+		gen.SetCertificateRequestNamespace("test"),
+		gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer-500"}),
+	)
+
+	evaluator := fake.NewFakeEvaluator().WithEvaluate(func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	})
+
+	for _, indexed := range []bool{false, true} {
+		b.Run(fmt.Sprintf("indexed=%t", indexed), func(b *testing.B) {
+			reader, err := newIndexerLister(policies)
+			if err != nil {
+				b.Fatal(err)
+			}
+			lister := &countingLister{Reader: reader}
+			mngr := newManager(lister, nil, []approver.Evaluator{evaluator})
+			mngr.indexed = indexed
+			// Only the issuerRef selector is relevant to the benchmark.
+			mngr.predicates = mngr.predicates[:3]
+
+			durations := make([]time.Duration, 0, b.N)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				response, err := mngr.Review(context.TODO(), request)
+				durations = append(durations, time.Since(start))
+				if err != nil || response.Result != manager.ResultApproved {
+					b.Fatalf("unexpected review response: %v %v", response, err)
+				}
+			}
+			b.StopTimer()
+
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			b.ReportMetric(float64(durations[len(durations)*99/100].Microseconds()), "p99-µs")
+			b.ReportMetric(float64(lister.lists)/float64(b.N), "lists/op")
+			b.ReportMetric(float64(lister.listed)/float64(b.N), "policies-listed/op")
+		})
+	}
+}
@@ -19,12 +19,18 @@ package predicate
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/cert-manager/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	authzv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
@@ -53,37 +59,140 @@ func Ready(_ context.Context, _ *cmapi.CertificateRequest, policies []policyapi.
 	return readyPolicies, nil
 }
 
+// ActiveSchedule is a Predicate that returns the subset of given policies
+// which are active at the current time of the given clock, according to their
+// `spec.activeSchedule`. Policies which don't define a schedule are always
+// active. Policies whose schedule can't be parsed are never active. The time
+// is read once per call, so all policies are evaluated at the same time.
+func ActiveSchedule(clock clock.PassiveClock) Predicate {
+	return func(_ context.Context, _ *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		var activePolicies []policyapi.CertificateRequestPolicy
+
+		now := clock.Now()
+		for _, policy := range policies {
+			if active, err := util.ScheduleActive(policy.Spec.ActiveSchedule, now); err == nil && active {
+				activePolicies = append(activePolicies, policy)
+			}
+		}
+
+		return activePolicies, nil
+	}
+}
+
 // SelectorIssuerRef is a Predicate that returns the subset of given policies
 // that have an `spec.selector.issuerRef` matching the `spec.issuerRef` in the
 // request. PredicateSelectorIssuerRef will match on strings using wilcards
 // "*". Empty selector is equivalent to "*" and will match on anything.
-func SelectorIssuerRef(_ context.Context, cr *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
-	var matchingPolicies []policyapi.CertificateRequestPolicy
+// If `issuerRef.matchLabels` is defined, the referenced Issuer or
+// ClusterIssuer is fetched and must have matching labels. Policies are
+// skipped if the referenced issuer does not exist or is not a cert-manager.io
+// Issuer or ClusterIssuer. Policies which define `spec.selector.issuerRefs`
+// match if any of its entries matches the request.
+func SelectorIssuerRef(lister client.Reader) Predicate {
+	// selectors caches compiled matchLabels across evaluations.
+	var selectors util.SelectorCache
 
-	for _, policy := range policies {
-		issRefSel := policy.Spec.Selector.IssuerRef
-		// If the issuerRef selector is nil, we match the policy and continue
-		// early.
-		if issRefSel == nil {
-			matchingPolicies = append(matchingPolicies, policy)
-			continue
-		}
+	return func(ctx context.Context, cr *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		var matchingPolicies []policyapi.CertificateRequestPolicy
 
-		issRef := cr.Spec.IssuerRef
+		// issuerLabels are the labels of the issuer referenced by the request. We
+		// use a pointer here so we can lazily fetch the issuer as necessary.
+		// issuerFound is false if the issuer doesn't exist or is not supported.
+		var (
+			issuerLabels *map[string]string
+			issuerFound  bool
+		)
+
+		// matches returns whether the issuerRef selector of the given field
+		// matches the request.
+		matches := func(policy *policyapi.CertificateRequestPolicy, fieldName string, issRefSel policyapi.CertificateRequestPolicySelectorIssuerRef) (bool, error) {
+			issRef := cr.Spec.IssuerRef
+
+			if issRefSel.Name != nil && !util.WildcardMatches(*issRefSel.Name, issRef.Name) {
+				return false, nil
+			}
+			if issRefSel.Kind != nil && !util.WildcardMatches(*issRefSel.Kind, issRef.Kind) {
+				return false, nil
+			}
+			if issRefSel.Group != nil && !util.WildcardMatches(*issRefSel.Group, issRef.Group) {
+				return false, nil
+			}
 
-		if issRefSel.Name != nil && !util.WildcardMatches(*issRefSel.Name, issRef.Name) {
-			continue
-		}
-		if issRefSel.Kind != nil && !util.WildcardMatches(*issRefSel.Kind, issRef.Kind) {
-			continue
+			// Match by Label Selector.
+			if issRefSel.MatchLabels != nil {
+				if issuerLabels == nil {
+					issLabels, found, err := getIssuerLabels(ctx, lister, cr)
+					if err != nil {
+						return false, err
+					}
+					issuerLabels, issuerFound = &issLabels, found
+				}
+
+				// Issuer couldn't be fetched so the selector doesn't match.
+				if !issuerFound {
+					return false, nil
+				}
+
+				selector, err := selectors.Selector(policy, fieldName, issRefSel.MatchLabels)
+				if err != nil {
+					return false, fmt.Errorf("failed to parse %s label selector: %w", fieldName, err)
+				}
+				if !selector.Matches(labels.Set(*issuerLabels)) {
+					return false, nil
+				}
+			}
+
+			return true, nil
 		}
-		if issRefSel.Group != nil && !util.WildcardMatches(*issRefSel.Group, issRef.Group) {
-			continue
+
+		for _, policy := range policies {
+			// If no issuerRef selector is defined, we match the policy and
+			// continue early.
+			if policy.Spec.Selector.IssuerRef == nil && len(policy.Spec.Selector.IssuerRefs) == 0 {
+				matchingPolicies = append(matchingPolicies, policy)
+				continue
+			}
+
+			if issRefSel := policy.Spec.Selector.IssuerRef; issRefSel != nil {
+				matched, err := matches(&policy, "issuerRef", *issRefSel)
+				if err != nil {
+					return nil, err
+				}
+				if matched {
+					matchingPolicies = append(matchingPolicies, policy)
+				}
+				continue
+			}
+
+			// The policy matches if any of the issuerRefs entries matches.
+			for i, issRefSel := range policy.Spec.Selector.IssuerRefs {
+				matched, err := matches(&policy, fmt.Sprintf("issuerRefs[%d]", i), issRefSel)
+				if err != nil {
+					return nil, err
+				}
+				if matched {
+					matchingPolicies = append(matchingPolicies, policy)
+					break
+				}
+			}
 		}
-		matchingPolicies = append(matchingPolicies, policy)
+
+		return matchingPolicies, nil
 	}
+}
 
-	return matchingPolicies, nil
+// getIssuerLabels returns the labels of the cert-manager.io Issuer or
+// ClusterIssuer referenced by the request. Returns false if the referenced
+// issuer doesn't exist or is not a cert-manager.io Issuer or ClusterIssuer.
+func getIssuerLabels(ctx context.Context, lister client.Reader, cr *cmapi.CertificateRequest) (map[string]string, bool, error) {
+	issuer, found, err := util.GetIssuerMetadata(ctx, lister, cr)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get request's issuer to determine issuerRef selector: %w", err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	return issuer.Labels, true, nil
 }
 
 // SelectorNamespace is a Predicate that returns the subset of given policies
@@ -92,6 +201,9 @@ func SelectorIssuerRef(_ context.Context, cr *cmapi.CertificateRequest, policies
 // namespaces using wilcards "*". Empty selector is equivalent to "*" and will
 // match on any Namespace.
 func SelectorNamespace(lister client.Reader) Predicate {
+	// selectors caches compiled matchLabels across evaluations.
+	var selectors util.SelectorCache
+
 	return func(ctx context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
 		var matchingPolicies []policyapi.CertificateRequestPolicy
 
@@ -142,9 +254,7 @@ func SelectorNamespace(lister client.Reader) Predicate {
 					namespaceLabels = &namespace.Labels
 				}
 
-				selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
-					MatchLabels: nsSel.MatchLabels,
-				})
+				selector, err := selectors.Selector(&policy, "namespace", nsSel.MatchLabels)
 				if err != nil {
 					return nil, fmt.Errorf("failed to parse namespace label selector: %w", err)
 				}
@@ -161,6 +271,562 @@ func SelectorNamespace(lister client.Reader) Predicate {
 	}
 }
 
+// serviceAccountUsernamePrefix is the prefix of usernames which belong to
+// ServiceAccounts, in the form "system:serviceaccount:<namespace>:<name>".
+const serviceAccountUsernamePrefix = "system:serviceaccount:"
+
+// SelectorServiceAccount is a Predicate that returns the subset of given
+// policies that have an `spec.selector.serviceAccount` matching the
+// ServiceAccount which created the request, resolved from the request's
+// `spec.username`. SelectorServiceAccount will match with
+// `serviceAccount.matchNames` on ServiceAccount names using wilcards "*".
+// Empty selector is equivalent to "*" and will match on any ServiceAccount.
+// Requests not created by a ServiceAccount will never match a policy defining
+// a ServiceAccount selector.
+func SelectorServiceAccount(lister client.Reader) Predicate {
+	// selectors caches compiled matchLabels across evaluations.
+	var selectors util.SelectorCache
+
+	return func(ctx context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		var matchingPolicies []policyapi.CertificateRequestPolicy
+
+		saNamespace, saName, isServiceAccount := splitServiceAccountUsername(request.Spec.Username)
+
+		// serviceAccountLabels are the labels of the ServiceAccount which created
+		// the request. We use a pointer here so we can lazily fetch the
+		// ServiceAccount as necessary.
+		var serviceAccountLabels *map[string]string
+
+		for _, policy := range policies {
+			saSel := policy.Spec.Selector.ServiceAccount
+
+			// ServiceAccount Selector is nil so we always match.
+			if saSel == nil {
+				matchingPolicies = append(matchingPolicies, policy)
+				continue
+			}
+
+			// The request was not created by a ServiceAccount, so can never match a
+			// ServiceAccount selector.
+			if !isServiceAccount {
+				continue
+			}
+
+			// If no strings are in matchNames, then we mark as matched here, the
+			// same as the Namespace selector.
+			matched := len(saSel.MatchNames) == 0
+
+			// Match by name.
+			for _, matchName := range saSel.MatchNames {
+				if serviceAccountNameMatches(matchName, request.Namespace, saNamespace, saName) {
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				continue
+			}
+
+			// Match by Label Selector.
+			if saSel.MatchLabels != nil {
+
+				if serviceAccountLabels == nil {
+					var serviceAccount corev1.ServiceAccount
+					err := lister.Get(ctx, client.ObjectKey{Namespace: saNamespace, Name: saName}, &serviceAccount)
+					// A ServiceAccount may have been deleted since the request was
+					// created, in which case it has no labels to match on.
+					if err != nil && !apierrors.IsNotFound(err) {
+						return nil, fmt.Errorf("failed to get request's serviceaccount to determine serviceaccount selector: %w", err)
+					}
+					serviceAccountLabels = &serviceAccount.Labels
+				}
+
+				selector, err := selectors.Selector(&policy, "serviceAccount", saSel.MatchLabels)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse serviceaccount label selector: %w", err)
+				}
+				// If the selector doesn't match, then we continue to the next policy.
+				if !selector.Matches(labels.Set(*serviceAccountLabels)) {
+					continue
+				}
+			}
+
+			matchingPolicies = append(matchingPolicies, policy)
+		}
+
+		return matchingPolicies, nil
+	}
+}
+
+// SelectorCertificateLabels is a Predicate that returns the subset of given
+// policies that have an `spec.selector.certificateLabels` matching the labels
+// of the Certificate which owns the request. Requests which are not owned by
+// a Certificate, or whose Certificate no longer exists, will never match a
+// policy defining a Certificate labels selector. Empty selector will match
+// on any request.
+func SelectorCertificateLabels(lister client.Reader) Predicate {
+	// selectors caches compiled matchLabels across evaluations.
+	var selectors util.SelectorCache
+
+	return func(ctx context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		var matchingPolicies []policyapi.CertificateRequestPolicy
+
+		// certificateLabels are the labels of the Certificate which owns the
+		// request. We use a pointer here so we can lazily fetch the Certificate
+		// as necessary.
+		var (
+			certificateLabels *map[string]string
+			hasCertificate    bool
+		)
+
+		for _, policy := range policies {
+			certSel := policy.Spec.Selector.CertificateLabels
+
+			// Certificate labels Selector is nil so we always match.
+			if certSel == nil {
+				matchingPolicies = append(matchingPolicies, policy)
+				continue
+			}
+
+			if certificateLabels == nil {
+				certLabels, ok, err := getCertificateLabels(ctx, lister, request)
+				if err != nil {
+					return nil, err
+				}
+				certificateLabels, hasCertificate = &certLabels, ok
+			}
+
+			// The request is not owned by a Certificate, so can never match a
+			// Certificate labels selector.
+			if !hasCertificate {
+				continue
+			}
+
+			selector, err := selectors.Selector(&policy, "certificateLabels", certSel.MatchLabels)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse certificate label selector: %w", err)
+			}
+			// If the selector doesn't match, then we continue to the next policy.
+			if !selector.Matches(labels.Set(*certificateLabels)) {
+				continue
+			}
+
+			matchingPolicies = append(matchingPolicies, policy)
+		}
+
+		return matchingPolicies, nil
+	}
+}
+
+// getCertificateLabels returns the labels of the cert-manager.io Certificate
+// which owns the request. Returns false if the request is not owned by a
+// Certificate, or the owning Certificate doesn't exist.
+func getCertificateLabels(ctx context.Context, lister client.Reader, cr *cmapi.CertificateRequest) (map[string]string, bool, error) {
+	owner := certificateOwner(cr)
+	if owner == nil {
+		return nil, false, nil
+	}
+
+	certificate := new(metav1.PartialObjectMetadata)
+	certificate.SetGroupVersionKind(cmapi.SchemeGroupVersion.WithKind(cmapi.CertificateKind))
+	if err := lister.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: owner.Name}, certificate); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get request's certificate to determine certificateLabels selector: %w", err)
+	}
+
+	// The Certificate may have been deleted and recreated since the request was
+	// created, in which case it no longer owns the request.
+	if certificate.UID != owner.UID {
+		return nil, false, nil
+	}
+
+	return certificate.Labels, true, nil
+}
+
+// certificateOwner returns the owner reference of the cert-manager.io
+// Certificate which owns the request. Returns nil if the request is not owned
+// by a Certificate.
+func certificateOwner(cr *cmapi.CertificateRequest) *metav1.OwnerReference {
+	for i, ref := range cr.OwnerReferences {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != certmanager.GroupName || ref.Kind != cmapi.CertificateKind {
+			continue
+		}
+		return &cr.OwnerReferences[i]
+	}
+	return nil
+}
+
+// SelectorSecret is a Predicate that returns the subset of given policies
+// that have an `spec.selector.secretName` and `spec.selector.secretLabels`
+// matching the `spec.secretName` and `spec.secretTemplate.labels` of the
+// Certificate which owns the request. Requests which are not owned by a
+// Certificate, or whose Certificate no longer exists, will never match a
+// policy defining either selector. Policies which define neither match on any
+// request.
+func SelectorSecret(lister client.Reader) Predicate {
+	// selectors caches compiled matchLabels across evaluations.
+	var selectors util.SelectorCache
+
+	return func(ctx context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		var matchingPolicies []policyapi.CertificateRequestPolicy
+
+		// certificate is the Certificate which owns the request, if any. We
+		// use a bool here so we can lazily fetch the Certificate as necessary.
+		var (
+			certificate *cmapi.Certificate
+			fetched     bool
+		)
+
+		for _, policy := range policies {
+			nameSel, labelsSel := policy.Spec.Selector.SecretName, policy.Spec.Selector.SecretLabels
+
+			// Secret selectors are nil so we always match.
+			if nameSel == nil && labelsSel == nil {
+				matchingPolicies = append(matchingPolicies, policy)
+				continue
+			}
+
+			if !fetched {
+				var err error
+				certificate, err = getCertificate(ctx, lister, request)
+				if err != nil {
+					return nil, err
+				}
+				fetched = true
+			}
+
+			// The request is not owned by a Certificate, so can never match a
+			// Secret selector.
+			if certificate == nil {
+				continue
+			}
+
+			if nameSel != nil && !util.WildcardMatches(*nameSel, certificate.Spec.SecretName) {
+				continue
+			}
+
+			if labelsSel != nil {
+				selector, err := selectors.Selector(&policy, "secretLabels", labelsSel.MatchLabels)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse secret label selector: %w", err)
+				}
+				var secretLabels map[string]string
+				if certificate.Spec.SecretTemplate != nil {
+					secretLabels = certificate.Spec.SecretTemplate.Labels
+				}
+				// If the selector doesn't match, then we continue to the next policy.
+				if !selector.Matches(labels.Set(secretLabels)) {
+					continue
+				}
+			}
+
+			matchingPolicies = append(matchingPolicies, policy)
+		}
+
+		return matchingPolicies, nil
+	}
+}
+
+// getCertificate returns the cert-manager.io Certificate which owns the
+// request. Returns nil if the request is not owned by a Certificate, or the
+// owning Certificate doesn't exist.
+func getCertificate(ctx context.Context, lister client.Reader, cr *cmapi.CertificateRequest) (*cmapi.Certificate, error) {
+	owner := certificateOwner(cr)
+	if owner == nil {
+		return nil, nil
+	}
+
+	certificate := new(cmapi.Certificate)
+	if err := lister.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: owner.Name}, certificate); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get request's certificate to determine secret selectors: %w", err)
+	}
+
+	// The Certificate may have been deleted and recreated since the request was
+	// created, in which case it no longer owns the request.
+	if certificate.UID != owner.UID {
+		return nil, nil
+	}
+
+	return certificate, nil
+}
+
+// SelectorIsRenewal is a Predicate that returns the subset of given policies
+// whose `spec.selector.isRenewal` matches whether the request is a renewal of
+// a Certificate. Policies which don't define isRenewal match on any request.
+// Whether a request is a renewal is determined by isRenewal. Requests for
+// which this can't be determined never match a policy which defines
+// isRenewal.
+func SelectorIsRenewal(lister client.Reader) Predicate {
+	return func(ctx context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		var matchingPolicies []policyapi.CertificateRequestPolicy
+
+		// renewal is whether the request is a renewal, if known. We use a
+		// pointer here so we can lazily determine it as necessary.
+		var (
+			renewal *bool
+			known   bool
+		)
+
+		for _, policy := range policies {
+			selector := policy.Spec.Selector.IsRenewal
+			if selector == nil {
+				matchingPolicies = append(matchingPolicies, policy)
+				continue
+			}
+
+			if renewal == nil {
+				isRenewal, ok, err := isRenewal(ctx, lister, request)
+				if err != nil {
+					return nil, err
+				}
+				renewal, known = &isRenewal, ok
+			}
+
+			if known && *renewal == *selector {
+				matchingPolicies = append(matchingPolicies, policy)
+			}
+		}
+
+		return matchingPolicies, nil
+	}
+}
+
+// isRenewal returns whether the request is a renewal of a Certificate, i.e.
+// not the first request for the Certificate. This is determined by:
+//   - the `cert-manager.io/certificate-revision` annotation of the request,
+//     which is set by cert-manager to the revision of the Certificate being
+//     requested. A revision greater than 1 is a renewal, and revision 1 is
+//     not;
+//   - otherwise, whether the Certificate which owns the request owns another
+//     request which was created before it.
+//
+// Returns false for ok if this can't be determined, i.e. the revision
+// annotation is malformed, or the request has no revision annotation and isn't
+// owned by a Certificate.
+func isRenewal(ctx context.Context, lister client.Reader, cr *cmapi.CertificateRequest) (bool, bool, error) {
+	if revision, ok := cr.Annotations[cmapi.CertificateRequestRevisionAnnotationKey]; ok {
+		rev, err := strconv.Atoi(revision)
+		if err != nil || rev < 1 {
+			return false, false, nil
+		}
+		return rev > 1, true, nil
+	}
+
+	owner := certificateOwner(cr)
+	if owner == nil {
+		return false, false, nil
+	}
+
+	var requests cmapi.CertificateRequestList
+	if err := lister.List(ctx, &requests, client.InNamespace(cr.Namespace)); err != nil {
+		return false, false, fmt.Errorf("failed to list requests to determine isRenewal selector: %w", err)
+	}
+
+	for _, other := range requests.Items {
+		if other.Name == cr.Name {
+			continue
+		}
+		otherOwner := certificateOwner(&other)
+		if otherOwner == nil || otherOwner.UID != owner.UID {
+			continue
+		}
+		if other.CreationTimestamp.Before(&cr.CreationTimestamp) {
+			return true, true, nil
+		}
+	}
+
+	return false, true, nil
+}
+
+// splitServiceAccountUsername returns the namespace and name of the
+// ServiceAccount that the given username belongs to. Returns false if the
+// username does not belong to a ServiceAccount.
+func splitServiceAccountUsername(username string) (string, string, bool) {
+	if !strings.HasPrefix(username, serviceAccountUsernamePrefix) {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(username, serviceAccountUsernamePrefix), ":")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// serviceAccountNameMatches returns whether the given ServiceAccount selector
+// name matches the ServiceAccount. Selector names in the form `<name>` only
+// match ServiceAccounts in the same Namespace as the request, whilst names in
+// the form `<namespace>:<name>` match ServiceAccounts in the given Namespace.
+func serviceAccountNameMatches(matchName, requestNamespace, saNamespace, saName string) bool {
+	nsPattern, namePattern, ok := strings.Cut(matchName, ":")
+	if !ok {
+		return saNamespace == requestNamespace && util.WildcardMatches(matchName, saName)
+	}
+	return util.WildcardMatches(nsPattern, saNamespace) && util.WildcardMatches(namePattern, saName)
+}
+
+// SelectorRequester is a Predicate that returns the subset of given policies
+// that have an `spec.selector.requester` matching the user which created the
+// request, as recorded in the `spec.username` and `spec.groups` of the
+// request. Empty selector will match on any request.
+func SelectorRequester(_ context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+	var matchingPolicies []policyapi.CertificateRequestPolicy
+
+	for _, policy := range policies {
+		reqSel := policy.Spec.Selector.Requester
+
+		// Requester Selector is nil so we always match.
+		if reqSel == nil {
+			matchingPolicies = append(matchingPolicies, policy)
+			continue
+		}
+
+		// Requests whose username wasn't recorded never match on usernames,
+		// including the wildcard "*".
+		if len(reqSel.Usernames) > 0 && (len(request.Spec.Username) == 0 || !anyWildcardMatches(reqSel.Usernames, request.Spec.Username)) {
+			continue
+		}
+
+		if len(reqSel.Groups) > 0 {
+			var matched bool
+			for _, group := range request.Spec.Groups {
+				if anyWildcardMatches(reqSel.Groups, group) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		matchingPolicies = append(matchingPolicies, policy)
+	}
+
+	return matchingPolicies, nil
+}
+
+// anyWildcardMatches returns whether any of the given wildcard patterns match
+// the value.
+func anyWildcardMatches(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if util.WildcardMatches(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectorRequestAnnotations is a Predicate that returns the subset of given
+// policies that have an `spec.selector.requestAnnotations` matching the
+// annotations of the request. The request must have all annotation keys of
+// the selector, with values matching using wildcards "*". Empty selector will
+// match on any request.
+func SelectorRequestAnnotations(_ context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+	var matchingPolicies []policyapi.CertificateRequestPolicy
+
+	for _, policy := range policies {
+		matched := true
+		for key, pattern := range policy.Spec.Selector.RequestAnnotations {
+			value, ok := request.Annotations[key]
+			if !ok || !util.WildcardMatches(pattern, value) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			matchingPolicies = append(matchingPolicies, policy)
+		}
+	}
+
+	return matchingPolicies, nil
+}
+
+// SelectorRequestLabels is a Predicate that returns the subset of given
+// policies that have an `spec.selector.requestLabels` matching the labels of
+// the request itself. Both matchLabels and matchExpressions must match. Empty
+// selector will match on any request.
+func SelectorRequestLabels() Predicate {
+	// selectors caches compiled label selectors across evaluations.
+	var selectors util.SelectorCache
+
+	return func(_ context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+		var matchingPolicies []policyapi.CertificateRequestPolicy
+
+		for _, policy := range policies {
+			reqSel := policy.Spec.Selector.RequestLabels
+
+			// Request labels Selector is nil so we always match.
+			if reqSel == nil {
+				matchingPolicies = append(matchingPolicies, policy)
+				continue
+			}
+
+			selector, err := selectors.LabelSelector(&policy, "requestLabels", &metav1.LabelSelector{
+				MatchLabels:      reqSel.MatchLabels,
+				MatchExpressions: reqSel.MatchExpressions,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse request label selector: %w", err)
+			}
+			// If the selector doesn't match, then we continue to the next policy.
+			if !selector.Matches(labels.Set(request.Labels)) {
+				continue
+			}
+
+			matchingPolicies = append(matchingPolicies, policy)
+		}
+
+		return matchingPolicies, nil
+	}
+}
+
+// SelectorDuration is a Predicate that returns the subset of given policies
+// whose `spec.selector.minDuration` and `spec.selector.maxDuration` window
+// contains the requested duration of the request. Requests which don't
+// request a duration only match policies which define a window if
+// `spec.selector.selectOnMissingDuration` is true. Policies which don't define
+// a window match on any request.
+func SelectorDuration(_ context.Context, request *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+	var matchingPolicies []policyapi.CertificateRequestPolicy
+
+	for _, policy := range policies {
+		selector := policy.Spec.Selector
+		if selector.MinDuration == nil && selector.MaxDuration == nil {
+			matchingPolicies = append(matchingPolicies, policy)
+			continue
+		}
+
+		if request.Spec.Duration == nil {
+			if selector.SelectOnMissingDuration != nil && *selector.SelectOnMissingDuration {
+				matchingPolicies = append(matchingPolicies, policy)
+			}
+			continue
+		}
+
+		duration := request.Spec.Duration.Duration
+		if selector.MinDuration != nil && duration < selector.MinDuration.Duration {
+			continue
+		}
+		if selector.MaxDuration != nil && duration > selector.MaxDuration.Duration {
+			continue
+		}
+
+		matchingPolicies = append(matchingPolicies, policy)
+	}
+
+	return matchingPolicies, nil
+}
+
 // RBACBoundPolicies is a Predicate that returns the subset of
 // CertificateRequestPolicies that have been RBAC bound to the user in the
 // CertificateRequest. Achieved using SubjectAccessReviews.
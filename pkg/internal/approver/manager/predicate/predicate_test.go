@@ -20,6 +20,7 @@ import (
 	"context"
 	"path/filepath"
 	"testing"
+	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
@@ -29,6 +30,8 @@ import (
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -701,7 +704,11 @@ func Test_SelectorIssuerRef(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			policies, err := SelectorIssuerRef(context.TODO(), baseRequest, test.policies)
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				Build()
+
+			policies, err := SelectorIssuerRef(fakeclient)(context.TODO(), baseRequest, test.policies)
 			assert.NoError(t, err)
 			if !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
 				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
@@ -710,6 +717,205 @@ func Test_SelectorIssuerRef(t *testing.T) {
 	}
 }
 
+func Test_SelectorIssuerRef_MatchLabels(t *testing.T) {
+	var (
+		issuer = &cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-namespace", Name: "issuer-abc123", Labels: map[string]string{"team": "foo"},
+		}}
+		clusterIssuer = &cmapi.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{
+			Name: "cluster-issuer-abc123", Labels: map[string]string{"team": "bar"},
+		}}
+
+		requestFor = func(name, kind, group string) *cmapi.CertificateRequest {
+			return &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec: cmapi.CertificateRequestSpec{
+					IssuerRef: cmmeta.ObjectReference{Name: name, Kind: kind, Group: group},
+				},
+			}
+		}
+
+		policyFor = func(name *string, matchLabels map[string]string) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{
+					Name: name, MatchLabels: matchLabels,
+				}},
+			}}
+		}
+	)
+
+	tests := map[string]struct {
+		request         *cmapi.CertificateRequest
+		policies        []policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+		expPolicies     []policyapi.CertificateRequestPolicy
+		expErr          bool
+	}{
+		"if issuer labels match, return policy": {
+			request:         requestFor("issuer-abc123", "Issuer", "cert-manager.io"),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(nil, map[string]string{"team": "foo"})},
+			existingObjects: []runtime.Object{issuer, clusterIssuer},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(nil, map[string]string{"team": "foo"})},
+		},
+		"if issuer with empty kind and group labels match, return policy": {
+			request:         requestFor("issuer-abc123", "", ""),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(nil, map[string]string{"team": "foo"})},
+			existingObjects: []runtime.Object{issuer, clusterIssuer},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(nil, map[string]string{"team": "foo"})},
+		},
+		"if cluster issuer labels match one of two policies, return that policy": {
+			request: requestFor("cluster-issuer-abc123", "ClusterIssuer", "cert-manager.io"),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyFor(nil, map[string]string{"team": "foo"}),
+				policyFor(nil, map[string]string{"team": "bar"}),
+			},
+			existingObjects: []runtime.Object{issuer, clusterIssuer},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(nil, map[string]string{"team": "bar"})},
+		},
+		"if name and labels both match, return policy": {
+			request:         requestFor("issuer-abc123", "Issuer", "cert-manager.io"),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(pointer.String("issuer-*"), map[string]string{"team": "foo"})},
+			existingObjects: []runtime.Object{issuer, clusterIssuer},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(pointer.String("issuer-*"), map[string]string{"team": "foo"})},
+		},
+		"if labels match but name doesn't, return no policies": {
+			request:         requestFor("issuer-abc123", "Issuer", "cert-manager.io"),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(pointer.String("other-*"), map[string]string{"team": "foo"})},
+			existingObjects: []runtime.Object{issuer, clusterIssuer},
+			expPolicies:     nil,
+		},
+		"if issuer doesn't exist, skip policy": {
+			request:         requestFor("issuer-abc123", "Issuer", "cert-manager.io"),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(nil, map[string]string{"team": "foo"})},
+			existingObjects: nil,
+			expPolicies:     nil,
+		},
+		"if issuer is an external issuer, skip policy": {
+			request:         requestFor("issuer-abc123", "AWSPCAIssuer", "awspca.cert-manager.io"),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(nil, map[string]string{"team": "foo"})},
+			existingObjects: []runtime.Object{issuer, clusterIssuer},
+			expPolicies:     nil,
+		},
+		"if issuer doesn't exist, policies without matchLabels still match": {
+			request: requestFor("issuer-abc123", "Issuer", "cert-manager.io"),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyFor(nil, map[string]string{"team": "foo"}),
+				policyFor(pointer.String("issuer-*"), nil),
+			},
+			existingObjects: nil,
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(pointer.String("issuer-*"), nil)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			policies, err := SelectorIssuerRef(fakeclient)(context.TODO(), test.request, test.policies)
+			assert.Equal(t, err != nil, test.expErr, "%v", err)
+			if !test.expErr && !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorIssuerRefs(t *testing.T) {
+	var (
+		issuer = &cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-namespace", Name: "issuer-abc123", Labels: map[string]string{"team": "foo"},
+		}}
+
+		requestFor = func(name, kind string) *cmapi.CertificateRequest {
+			return &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec: cmapi.CertificateRequestSpec{
+					IssuerRef: cmmeta.ObjectReference{Name: name, Kind: kind, Group: "cert-manager.io"},
+				},
+			}
+		}
+
+		policyFor = func(issRefSels ...policyapi.CertificateRequestPolicySelectorIssuerRef) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{IssuerRefs: issRefSels},
+			}}
+		}
+
+		twoIssuers = policyFor(
+			policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("issuer-a"), Kind: pointer.String("Issuer")},
+			policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("cluster-issuer-b"), Kind: pointer.String("ClusterIssuer")},
+		)
+		labelled = policyFor(
+			policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("cluster-issuer-b")},
+			policyapi.CertificateRequestPolicySelectorIssuerRef{MatchLabels: map[string]string{"team": "foo"}},
+		)
+	)
+
+	tests := map[string]struct {
+		request         *cmapi.CertificateRequest
+		policies        []policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+		expPolicies     []policyapi.CertificateRequestPolicy
+	}{
+		"if the request matches the first entry, return policy": {
+			request:     requestFor("issuer-a", "Issuer"),
+			policies:    []policyapi.CertificateRequestPolicy{twoIssuers},
+			expPolicies: []policyapi.CertificateRequestPolicy{twoIssuers},
+		},
+		"if the request matches the second entry, return policy": {
+			request:     requestFor("cluster-issuer-b", "ClusterIssuer"),
+			policies:    []policyapi.CertificateRequestPolicy{twoIssuers},
+			expPolicies: []policyapi.CertificateRequestPolicy{twoIssuers},
+		},
+		"if the request matches the name of one entry and the kind of the other, return no policies": {
+			request:     requestFor("issuer-a", "ClusterIssuer"),
+			policies:    []policyapi.CertificateRequestPolicy{twoIssuers},
+			expPolicies: nil,
+		},
+		"if the request matches no entry, return no policies": {
+			request:     requestFor("issuer-c", "Issuer"),
+			policies:    []policyapi.CertificateRequestPolicy{twoIssuers},
+			expPolicies: nil,
+		},
+		"if the issuer labels match an entry, return policy": {
+			request:         requestFor("issuer-abc123", "Issuer"),
+			policies:        []policyapi.CertificateRequestPolicy{labelled},
+			existingObjects: []runtime.Object{issuer},
+			expPolicies:     []policyapi.CertificateRequestPolicy{labelled},
+		},
+		"if the issuer doesn't exist, entries without matchLabels still match": {
+			request:     requestFor("cluster-issuer-b", "ClusterIssuer"),
+			policies:    []policyapi.CertificateRequestPolicy{labelled},
+			expPolicies: []policyapi.CertificateRequestPolicy{labelled},
+		},
+		"if the issuer doesn't exist and only an entry with matchLabels may match, return no policies": {
+			request:     requestFor("issuer-abc123", "Issuer"),
+			policies:    []policyapi.CertificateRequestPolicy{labelled},
+			expPolicies: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			policies, err := SelectorIssuerRef(fakeclient)(context.TODO(), test.request, test.policies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
 func Test_SelectorNamespace(t *testing.T) {
 	var (
 		baseRequest = &cmapi.CertificateRequest{
@@ -1036,3 +1242,917 @@ func Test_SelectorNamespace(t *testing.T) {
 		})
 	}
 }
+
+func Test_SelectorServiceAccount(t *testing.T) {
+	var (
+		baseRequest = &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-namespace",
+			},
+			Spec: cmapi.CertificateRequestSpec{
+				Username: "system:serviceaccount:test-namespace:app-1",
+			},
+		}
+		testsa = &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "app-1", Labels: map[string]string{"team": "foo"}}}
+
+		policyApp1 = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+					MatchNames: []string{"app-1"},
+				},
+			},
+		}}
+		policyApp2 = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+					MatchNames: []string{"app-2"},
+				},
+			},
+		}}
+	)
+
+	tests := map[string]struct {
+		request                *cmapi.CertificateRequest
+		policies               []policyapi.CertificateRequestPolicy
+		existingServiceAccount runtime.Object
+		expPolicies            []policyapi.CertificateRequestPolicy
+		expErr                 bool
+	}{
+		"if no policies given, return no policies": {
+			request:                baseRequest,
+			policies:               nil,
+			existingServiceAccount: testsa,
+			expPolicies:            nil,
+			expErr:                 false,
+		},
+		"if policy has no serviceAccount selector, return policy": {
+			request: baseRequest,
+			policies: []policyapi.CertificateRequestPolicy{
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef)},
+				}},
+			},
+			existingServiceAccount: testsa,
+			expPolicies: []policyapi.CertificateRequestPolicy{
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef)},
+				}},
+			},
+			expErr: false,
+		},
+		"if two policies differ only by serviceAccount selector, return the matching policy": {
+			request:                baseRequest,
+			policies:               []policyapi.CertificateRequestPolicy{policyApp1, policyApp2},
+			existingServiceAccount: testsa,
+			expPolicies:            []policyapi.CertificateRequestPolicy{policyApp1},
+			expErr:                 false,
+		},
+		"if two policies differ only by serviceAccount selector and request from other serviceaccount, return the other policy": {
+			request: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec:       cmapi.CertificateRequestSpec{Username: "system:serviceaccount:test-namespace:app-2"},
+			},
+			policies:               []policyapi.CertificateRequestPolicy{policyApp1, policyApp2},
+			existingServiceAccount: testsa,
+			expPolicies:            []policyapi.CertificateRequestPolicy{policyApp2},
+			expErr:                 false,
+		},
+		"if two policies differ only by serviceAccount selector and request not from a serviceaccount, return no policies": {
+			request: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec:       cmapi.CertificateRequestSpec{Username: "app-1"},
+			},
+			policies:               []policyapi.CertificateRequestPolicy{policyApp1, policyApp2},
+			existingServiceAccount: testsa,
+			expPolicies:            nil,
+			expErr:                 false,
+		},
+		"if serviceaccount name matches but is in a different namespace to the request, return no policies": {
+			request: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec:       cmapi.CertificateRequestSpec{Username: "system:serviceaccount:other-namespace:app-1"},
+			},
+			policies:               []policyapi.CertificateRequestPolicy{policyApp1, policyApp2},
+			existingServiceAccount: testsa,
+			expPolicies:            nil,
+			expErr:                 false,
+		},
+		"if serviceaccount matches with namespace qualified wildcard name, return policy": {
+			request: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec:       cmapi.CertificateRequestSpec{Username: "system:serviceaccount:other-namespace:app-1"},
+			},
+			policies: []policyapi.CertificateRequestPolicy{
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+						MatchNames: []string{"other-*:app-*"},
+					}},
+				}},
+			},
+			existingServiceAccount: testsa,
+			expPolicies: []policyapi.CertificateRequestPolicy{
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+						MatchNames: []string{"other-*:app-*"},
+					}},
+				}},
+			},
+			expErr: false,
+		},
+		"if one of two policies match serviceaccount labels, return policy": {
+			request: baseRequest,
+			policies: []policyapi.CertificateRequestPolicy{
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+						MatchLabels: map[string]string{"team": "foo"},
+					}},
+				}},
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+						MatchLabels: map[string]string{"team": "bar"},
+					}},
+				}},
+			},
+			existingServiceAccount: testsa,
+			expPolicies: []policyapi.CertificateRequestPolicy{
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+						MatchLabels: map[string]string{"team": "foo"},
+					}},
+				}},
+			},
+			expErr: false,
+		},
+		"if serviceaccount doesn't exist and using match labels, return no policies": {
+			request: baseRequest,
+			policies: []policyapi.CertificateRequestPolicy{
+				{Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{
+						MatchLabels: map[string]string{"team": "foo"},
+					}},
+				}},
+			},
+			existingServiceAccount: nil,
+			expPolicies:            nil,
+			expErr:                 false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme)
+			if test.existingServiceAccount != nil {
+				builder = builder.WithRuntimeObjects(test.existingServiceAccount)
+			}
+			fakeclient := builder.Build()
+
+			policies, err := SelectorServiceAccount(fakeclient)(context.TODO(), test.request, test.policies)
+			assert.Equal(t, err != nil, test.expErr, "%v", err)
+			if !test.expErr && !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorCertificateLabels(t *testing.T) {
+	var (
+		certificate = &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-namespace", Name: "cert-1", UID: "cert-1-uid", Labels: map[string]string{"team": "payments"},
+		}}
+
+		requestOwnedBy = func(refs ...metav1.OwnerReference) *cmapi.CertificateRequest {
+			return &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", OwnerReferences: refs}}
+		}
+		certificateOwner = func(name, uid string) metav1.OwnerReference {
+			return metav1.OwnerReference{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Name: name, UID: types.UID(uid), Controller: pointer.Bool(true)}
+		}
+
+		policyFor = func(matchLabels map[string]string) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{
+					IssuerRef:         new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+					CertificateLabels: &policyapi.CertificateRequestPolicySelectorCertificateLabels{MatchLabels: matchLabels},
+				},
+			}}
+		}
+		policyNoSelector = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef)},
+		}}
+	)
+
+	tests := map[string]struct {
+		request         *cmapi.CertificateRequest
+		policies        []policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+		expPolicies     []policyapi.CertificateRequestPolicy
+		expErr          bool
+	}{
+		"if policy has no certificateLabels selector, return policy": {
+			request:         requestOwnedBy(),
+			policies:        []policyapi.CertificateRequestPolicy{policyNoSelector},
+			existingObjects: nil,
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if owning certificate labels match, return policy": {
+			request:         requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(map[string]string{"team": "payments"})},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(map[string]string{"team": "payments"})},
+		},
+		"if owning certificate labels match one of two policies, return that policy": {
+			request: requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyFor(map[string]string{"team": "payments"}),
+				policyFor(map[string]string{"team": "search"}),
+			},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(map[string]string{"team": "payments"})},
+		},
+		"if empty selector and request owned by certificate, return policy": {
+			request:         requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(nil)},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyFor(nil)},
+		},
+		"if request has no certificate owner, return only policies without selector": {
+			request: requestOwnedBy(),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyFor(nil),
+				policyNoSelector,
+			},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if request is owned by a non cert-manager Certificate, return no policies": {
+			request: requestOwnedBy(metav1.OwnerReference{
+				APIVersion: "example.com/v1", Kind: "Certificate", Name: "cert-1", UID: "cert-1-uid",
+			}),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(map[string]string{"team": "payments"})},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     nil,
+		},
+		"if owning certificate doesn't exist, return no policies": {
+			request:         requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(map[string]string{"team": "payments"})},
+			existingObjects: nil,
+			expPolicies:     nil,
+		},
+		"if owning certificate has been recreated, return no policies": {
+			request:         requestOwnedBy(certificateOwner("cert-1", "old-uid")),
+			policies:        []policyapi.CertificateRequestPolicy{policyFor(map[string]string{"team": "payments"})},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			policies, err := SelectorCertificateLabels(fakeclient)(context.TODO(), test.request, test.policies)
+			assert.Equal(t, err != nil, test.expErr, "%v", err)
+			if !test.expErr && !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorSecret(t *testing.T) {
+	var (
+		certificate = &cmapi.Certificate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "cert-1", UID: "cert-1-uid"},
+			Spec: cmapi.CertificateSpec{
+				SecretName:     "payments-api-tls",
+				SecretTemplate: &cmapi.CertificateSecretTemplate{Labels: map[string]string{"team": "payments"}},
+			},
+		}
+
+		requestOwnedBy = func(refs ...metav1.OwnerReference) *cmapi.CertificateRequest {
+			return &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", OwnerReferences: refs}}
+		}
+		certificateOwner = func(name, uid string) metav1.OwnerReference {
+			return metav1.OwnerReference{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Name: name, UID: types.UID(uid), Controller: pointer.Bool(true)}
+		}
+
+		policyForName = func(secretName string) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{
+					IssuerRef:  new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+					SecretName: pointer.String(secretName),
+				},
+			}}
+		}
+		policyForLabels = func(matchLabels map[string]string) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{
+					IssuerRef:    new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+					SecretLabels: &policyapi.CertificateRequestPolicySelectorSecretLabels{MatchLabels: matchLabels},
+				},
+			}}
+		}
+		policyNoSelector = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef)},
+		}}
+	)
+
+	tests := map[string]struct {
+		request         *cmapi.CertificateRequest
+		policies        []policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+		expPolicies     []policyapi.CertificateRequestPolicy
+		expErr          bool
+	}{
+		"if policy has no secret selectors, return policy": {
+			request:         requestOwnedBy(),
+			policies:        []policyapi.CertificateRequestPolicy{policyNoSelector},
+			existingObjects: nil,
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if owning certificate's secret name matches one of two patterns, return that policy": {
+			request: requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyForName("payments-*-tls"),
+				policyForName("search-*-tls"),
+			},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyForName("payments-*-tls")},
+		},
+		"if owning certificate's secret template labels match, return policy": {
+			request: requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyForLabels(map[string]string{"team": "payments"}),
+				policyForLabels(map[string]string{"team": "search"}),
+			},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyForLabels(map[string]string{"team": "payments"})},
+		},
+		"if owning certificate has no secret template, return only policies with an empty labels selector": {
+			request: requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyForLabels(nil),
+				policyForLabels(map[string]string{"team": "payments"}),
+			},
+			existingObjects: []runtime.Object{&cmapi.Certificate{
+				ObjectMeta: certificate.ObjectMeta,
+				Spec:       cmapi.CertificateSpec{SecretName: "payments-api-tls"},
+			}},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyForLabels(nil)},
+		},
+		"if request has no certificate owner, return only policies without selectors": {
+			request: requestOwnedBy(),
+			policies: []policyapi.CertificateRequestPolicy{
+				policyForName("*"),
+				policyForLabels(nil),
+				policyNoSelector,
+			},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if owning certificate doesn't exist, return no policies": {
+			request:         requestOwnedBy(certificateOwner("cert-1", "cert-1-uid")),
+			policies:        []policyapi.CertificateRequestPolicy{policyForName("*")},
+			existingObjects: nil,
+			expPolicies:     nil,
+		},
+		"if owning certificate has been recreated, return no policies": {
+			request:         requestOwnedBy(certificateOwner("cert-1", "old-uid")),
+			policies:        []policyapi.CertificateRequestPolicy{policyForName("*")},
+			existingObjects: []runtime.Object{certificate},
+			expPolicies:     nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			policies, err := SelectorSecret(fakeclient)(context.TODO(), test.request, test.policies)
+			assert.Equal(t, err != nil, test.expErr, "%v", err)
+			if !test.expErr && !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorIsRenewal(t *testing.T) {
+	var (
+		now     = metav1.Now()
+		earlier = metav1.NewTime(now.Add(-time.Hour))
+
+		certificateOwner = func(uid string) metav1.OwnerReference {
+			return metav1.OwnerReference{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Name: "cert-1", UID: types.UID(uid), Controller: pointer.Bool(true)}
+		}
+		request = func(name string, created metav1.Time, annotations map[string]string, refs ...metav1.OwnerReference) *cmapi.CertificateRequest {
+			return &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-namespace", Name: name, CreationTimestamp: created, Annotations: annotations, OwnerReferences: refs,
+			}}
+		}
+		revision = func(rev string) map[string]string {
+			return map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: rev}
+		}
+
+		policyFor = func(isRenewal *bool) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef), IsRenewal: isRenewal},
+			}}
+		}
+		policyNoSelector = policyFor(nil)
+		policyRenewal    = policyFor(pointer.Bool(true))
+		policyNotRenewal = policyFor(pointer.Bool(false))
+		allPolicies      = []policyapi.CertificateRequestPolicy{policyNoSelector, policyRenewal, policyNotRenewal}
+	)
+
+	tests := map[string]struct {
+		request         *cmapi.CertificateRequest
+		existingObjects []runtime.Object
+		expPolicies     []policyapi.CertificateRequestPolicy
+	}{
+		"if request is revision 1, return policies selecting non-renewals": {
+			request:     request("cr-1", now, revision("1"), certificateOwner("cert-1-uid")),
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector, policyNotRenewal},
+		},
+		"if request is revision 3, return policies selecting renewals": {
+			request:     request("cr-3", now, revision("3"), certificateOwner("cert-1-uid")),
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector, policyRenewal},
+		},
+		"if request is revision 1 and an earlier request exists, the revision takes precedence": {
+			request:         request("cr-2", now, revision("1"), certificateOwner("cert-1-uid")),
+			existingObjects: []runtime.Object{request("cr-1", earlier, revision("1"), certificateOwner("cert-1-uid"))},
+			expPolicies:     []policyapi.CertificateRequestPolicy{policyNoSelector, policyNotRenewal},
+		},
+		"if request has a malformed revision, return only policies without selector": {
+			request:     request("cr-1", now, revision("one"), certificateOwner("cert-1-uid")),
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if request has a revision of 0, return only policies without selector": {
+			request:     request("cr-1", now, revision("0"), certificateOwner("cert-1-uid")),
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if request has no revision and no certificate owner, return only policies without selector": {
+			request:     request("cr-1", now, nil),
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if request has no revision and the certificate owns an earlier request, return policies selecting renewals": {
+			request: request("cr-2", now, nil, certificateOwner("cert-1-uid")),
+			existingObjects: []runtime.Object{
+				request("cr-1", earlier, nil, certificateOwner("cert-1-uid")),
+			},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector, policyRenewal},
+		},
+		"if request has no revision and only other certificates own earlier requests, return policies selecting non-renewals": {
+			request: request("cr-2", now, nil, certificateOwner("cert-1-uid")),
+			existingObjects: []runtime.Object{
+				request("cr-1", earlier, nil, certificateOwner("old-uid")),
+				request("cr-3", earlier, nil),
+			},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector, policyNotRenewal},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			policies, err := SelectorIsRenewal(fakeclient)(context.TODO(), test.request, allPolicies)
+			assert.NoError(t, err)
+			if !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorRequestAnnotations(t *testing.T) {
+	var (
+		baseRequest = &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test-namespace",
+				Annotations: map[string]string{"example.com/ticket-id": "TICKET-123", "foo": "bar"},
+			},
+		}
+
+		policyNoSelector = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef)},
+		}}
+		policyTicket = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef:          new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestAnnotations: map[string]string{"example.com/ticket-id": "TICKET-*"},
+			},
+		}}
+		policyTicketAndFoo = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef:          new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestAnnotations: map[string]string{"example.com/ticket-id": "*", "foo": "bar"},
+			},
+		}}
+		policyOtherTicket = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef:          new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestAnnotations: map[string]string{"example.com/ticket-id": "OTHER-*"},
+			},
+		}}
+		policyMissingKey = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef:          new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestAnnotations: map[string]string{"example.com/ticket-id": "*", "example.com/team": "*"},
+			},
+		}}
+	)
+
+	tests := map[string]struct {
+		request     *cmapi.CertificateRequest
+		policies    []policyapi.CertificateRequestPolicy
+		expPolicies []policyapi.CertificateRequestPolicy
+	}{
+		"if no policies given, return no policies": {
+			request:     baseRequest,
+			policies:    nil,
+			expPolicies: nil,
+		},
+		"if policy has no requestAnnotations selector, return policy": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSelector},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if policy requestAnnotations match, return policies": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyTicket, policyTicketAndFoo},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyTicket, policyTicketAndFoo},
+		},
+		"if policy requestAnnotations value doesn't match, return no policies": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyOtherTicket},
+			expPolicies: nil,
+		},
+		"if request is missing one of the annotation keys, return no policies": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyMissingKey},
+			expPolicies: nil,
+		},
+		"if request has no annotations, only return policies without a selector": {
+			request:     &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"}},
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSelector, policyTicket},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policies, err := SelectorRequestAnnotations(context.TODO(), test.request, test.policies)
+			assert.NoError(t, err)
+			if !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorRequestLabels(t *testing.T) {
+	var (
+		baseRequest = &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-namespace",
+				Labels:    map[string]string{"team": "payments", "environment": "production"},
+			},
+		}
+
+		policyNoSelector = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef)},
+		}}
+		policyTeam = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef:     new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{MatchLabels: map[string]string{"team": "payments"}},
+			},
+		}}
+		policyOtherTeam = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef:     new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{MatchLabels: map[string]string{"team": "identity"}},
+			},
+		}}
+		policyTeamInEnvironments = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+					MatchLabels: map[string]string{"team": "payments"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "environment", Operator: metav1.LabelSelectorOpIn, Values: []string{"production", "staging"}},
+					},
+				},
+			},
+		}}
+		policyNotProduction = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "environment", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"production"}},
+					},
+				},
+			},
+		}}
+		policyInvalid = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+				RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "environment", Operator: metav1.LabelSelectorOpIn},
+					},
+				},
+			},
+		}}
+	)
+
+	tests := map[string]struct {
+		request     *cmapi.CertificateRequest
+		policies    []policyapi.CertificateRequestPolicy
+		expPolicies []policyapi.CertificateRequestPolicy
+		expErr      bool
+	}{
+		"if no policies given, return no policies": {
+			request:     baseRequest,
+			policies:    nil,
+			expPolicies: nil,
+		},
+		"if policy has no requestLabels selector, return policy": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSelector},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if policy requestLabels matchLabels match, return policy": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyTeam, policyOtherTeam},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyTeam},
+		},
+		"if policy requestLabels matchLabels and matchExpressions match, return policy": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyTeamInEnvironments},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyTeamInEnvironments},
+		},
+		"if policy requestLabels matchExpressions don't match, return no policies": {
+			request:     baseRequest,
+			policies:    []policyapi.CertificateRequestPolicy{policyNotProduction},
+			expPolicies: nil,
+		},
+		"if request has no labels, only return policies whose selectors match no labels": {
+			request:     &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"}},
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSelector, policyTeam, policyNotProduction},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector, policyNotProduction},
+		},
+		"if policy requestLabels selector is invalid, return error": {
+			request:  baseRequest,
+			policies: []policyapi.CertificateRequestPolicy{policyInvalid},
+			expErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policies, err := SelectorRequestLabels()(context.TODO(), test.request, test.policies)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			if !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorRequester(t *testing.T) {
+	var (
+		requestFrom = func(username string, groups ...string) *cmapi.CertificateRequest {
+			return &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec:       cmapi.CertificateRequestSpec{Username: username, Groups: groups},
+			}
+		}
+
+		policyFor = func(usernames, groups []string) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{
+					IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+					Requester: &policyapi.CertificateRequestPolicySelectorRequester{Usernames: usernames, Groups: groups},
+				},
+			}}
+		}
+		policyNoSelector = policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: new(policyapi.CertificateRequestPolicySelectorIssuerRef)},
+		}}
+	)
+
+	tests := map[string]struct {
+		request     *cmapi.CertificateRequest
+		policies    []policyapi.CertificateRequestPolicy
+		expPolicies []policyapi.CertificateRequestPolicy
+	}{
+		"if policy has no requester selector, return policy": {
+			request:     requestFrom("alice", "team-a"),
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSelector},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if empty requester selector, return policy": {
+			request:     requestFrom("alice"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor(nil, nil)},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyFor(nil, nil)},
+		},
+		"if requester is a member of a selected group, return policy": {
+			request:     requestFrom("alice", "system:authenticated", "team-a"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor(nil, []string{"team-b", "team-a"})},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyFor(nil, []string{"team-b", "team-a"})},
+		},
+		"if requester is a member of a group matching a wildcard, return policy": {
+			request:     requestFrom("alice", "team-a"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor(nil, []string{"team-*"})},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyFor(nil, []string{"team-*"})},
+		},
+		"if requester is not a member of a selected group, return no policies": {
+			request:     requestFrom("alice", "team-a"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor(nil, []string{"team-b"})},
+			expPolicies: nil,
+		},
+		"if requester has no recorded groups, return only policies without a groups selector": {
+			request:     requestFrom("alice"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor(nil, []string{"*"}), policyFor([]string{"alice"}, nil)},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyFor([]string{"alice"}, nil)},
+		},
+		"if requester username matches, return policy": {
+			request:     requestFrom("system:serviceaccount:test-namespace:deployer"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor([]string{"system:serviceaccount:test-namespace:*"}, nil)},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyFor([]string{"system:serviceaccount:test-namespace:*"}, nil)},
+		},
+		"if requester username doesn't match, return no policies": {
+			request:     requestFrom("bob", "team-a"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor([]string{"alice"}, nil)},
+			expPolicies: nil,
+		},
+		"if requester has no recorded username, return no policies with a usernames selector": {
+			request:     requestFrom(""),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor([]string{"*"}, nil)},
+			expPolicies: nil,
+		},
+		"if requester matches username but not groups, return no policies": {
+			request:     requestFrom("alice", "team-a"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor([]string{"alice"}, []string{"team-b"})},
+			expPolicies: nil,
+		},
+		"if requester matches both username and groups, return policy": {
+			request:     requestFrom("alice", "team-a"),
+			policies:    []policyapi.CertificateRequestPolicy{policyFor([]string{"alice"}, []string{"team-a"}), policyFor(nil, []string{"team-b"})},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyFor([]string{"alice"}, []string{"team-a"})},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policies, err := SelectorRequester(context.TODO(), test.request, test.policies)
+			assert.NoError(t, err)
+			if !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_SelectorDuration(t *testing.T) {
+	var (
+		requestWithDuration = func(d time.Duration) *cmapi.CertificateRequest {
+			return &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+				Spec:       cmapi.CertificateRequestSpec{Duration: &metav1.Duration{Duration: d}},
+			}
+		}
+		requestNoDuration = &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"}}
+
+		policyWithSelector = func(min, max *metav1.Duration, selectOnMissing *bool) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{
+					IssuerRef:               new(policyapi.CertificateRequestPolicySelectorIssuerRef),
+					MinDuration:             min,
+					MaxDuration:             max,
+					SelectOnMissingDuration: selectOnMissing,
+				},
+			}}
+		}
+
+		policyNoSelector   = policyWithSelector(nil, nil, nil)
+		policyShort        = policyWithSelector(nil, &metav1.Duration{Duration: time.Hour * 24}, nil)
+		policyLong         = policyWithSelector(&metav1.Duration{Duration: time.Hour * 24 * 30}, nil, nil)
+		policyMedium       = policyWithSelector(&metav1.Duration{Duration: time.Hour * 24}, &metav1.Duration{Duration: time.Hour * 24 * 30}, nil)
+		policyShortMissing = policyWithSelector(nil, &metav1.Duration{Duration: time.Hour * 24}, pointer.Bool(true))
+		policyLongMissing  = policyWithSelector(&metav1.Duration{Duration: time.Hour * 24 * 30}, nil, pointer.Bool(false))
+	)
+
+	tests := map[string]struct {
+		request     *cmapi.CertificateRequest
+		policies    []policyapi.CertificateRequestPolicy
+		expPolicies []policyapi.CertificateRequestPolicy
+	}{
+		"if no policies given, return no policies": {
+			request:     requestWithDuration(time.Hour),
+			policies:    nil,
+			expPolicies: nil,
+		},
+		"if policy has no duration selector, return policy": {
+			request:     requestWithDuration(time.Hour),
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSelector},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector},
+		},
+		"if requested duration is within the window, return only matching policies": {
+			request:     requestWithDuration(time.Hour),
+			policies:    []policyapi.CertificateRequestPolicy{policyShort, policyMedium, policyLong},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyShort},
+		},
+		"if requested duration is equal to the window bounds, return matching policies": {
+			request:     requestWithDuration(time.Hour * 24),
+			policies:    []policyapi.CertificateRequestPolicy{policyShort, policyMedium, policyLong},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyShort, policyMedium},
+		},
+		"if requested duration is larger than all windows, return only unbounded policies": {
+			request:     requestWithDuration(time.Hour * 24 * 365),
+			policies:    []policyapi.CertificateRequestPolicy{policyShort, policyMedium, policyLong},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyLong},
+		},
+		"if request has no duration, return policies without a window or selecting on missing duration": {
+			request:     requestNoDuration,
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSelector, policyShort, policyShortMissing, policyLongMissing},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSelector, policyShortMissing},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policies, err := SelectorDuration(context.TODO(), test.request, test.policies)
+			assert.NoError(t, err)
+			if !apiequality.Semantic.DeepEqual(test.expPolicies, policies) {
+				t.Errorf("unexpected policies returned:\nexp=%#+v\ngot=%#+v", test.expPolicies, policies)
+			}
+		})
+	}
+}
+
+func Test_ActiveSchedule(t *testing.T) {
+	var (
+		policyWithSchedule = func(name string, schedule *policyapi.CertificateRequestPolicyActiveSchedule) policyapi.CertificateRequestPolicy {
+			return policyapi.CertificateRequestPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       policyapi.CertificateRequestPolicySpec{ActiveSchedule: schedule},
+			}
+		}
+
+		policyNoSchedule  = policyWithSchedule("no-schedule", nil)
+		policyOutOfHours  = policyWithSchedule("out-of-hours", &policyapi.CertificateRequestPolicyActiveSchedule{Windows: []policyapi.CertificateRequestPolicyActiveWindow{{Start: "18:00", End: "09:00"}}})
+		policyInHours     = policyWithSchedule("in-hours", &policyapi.CertificateRequestPolicyActiveSchedule{Windows: []policyapi.CertificateRequestPolicyActiveWindow{{Start: "09:00", End: "18:00"}}})
+		policyBadSchedule = policyWithSchedule("bad-schedule", &policyapi.CertificateRequestPolicyActiveSchedule{TimeZone: "Not/AZone", Windows: []policyapi.CertificateRequestPolicyActiveWindow{{Start: "00:00", End: "00:00"}}})
+	)
+
+	tests := map[string]struct {
+		now         time.Time
+		policies    []policyapi.CertificateRequestPolicy
+		expPolicies []policyapi.CertificateRequestPolicy
+	}{
+		"if no policies given, return no policies": {
+			now:         time.Date(2023, time.June, 5, 12, 0, 0, 0, time.UTC),
+			policies:    nil,
+			expPolicies: nil,
+		},
+		"if during business hours, return policies without a schedule or active in hours": {
+			now:         time.Date(2023, time.June, 5, 12, 0, 0, 0, time.UTC),
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSchedule, policyOutOfHours, policyInHours},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSchedule, policyInHours},
+		},
+		"if at the boundary minute, return policies without a schedule or starting at that minute": {
+			now:         time.Date(2023, time.June, 5, 18, 0, 30, 0, time.UTC),
+			policies:    []policyapi.CertificateRequestPolicy{policyNoSchedule, policyOutOfHours, policyInHours},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSchedule, policyOutOfHours},
+		},
+		"if policy schedule can't be parsed, don't return policy": {
+			now:         time.Date(2023, time.June, 5, 12, 0, 0, 0, time.UTC),
+			policies:    []policyapi.CertificateRequestPolicy{policyBadSchedule, policyNoSchedule},
+			expPolicies: []policyapi.CertificateRequestPolicy{policyNoSchedule},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policies, err := ActiveSchedule(fakeclock.NewFakePassiveClock(test.now))(context.TODO(), new(cmapi.CertificateRequest), test.policies)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expPolicies, policies)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+)
+
+// Revalidator reviews CertificateRequests which have already been approved,
+// to check whether they still pass policy.
+type Revalidator interface {
+	// Review reviews the request in the same way as the approver Manager.
+	// Reviews are not observed in the approval, denial and evaluation
+	// metrics, since the request was already observed when it was approved.
+	manager.Interface
+
+	// Selects returns whether the policy passes all of the predicates for the
+	// request, i.e. whether the policy is considered when the request is
+	// reviewed.
+	Selects(ctx context.Context, cr *cmapi.CertificateRequest, policy *policyapi.CertificateRequestPolicy) (bool, error)
+}
+
+// NewRevalidator constructs a new Revalidator which uses the same predicates,
+// evaluators and combine mode as a Manager constructed with NewIndexed.
+func NewRevalidator(lister client.Reader, client client.Client, evaluators []approver.Evaluator, combineMode CombineMode) Revalidator {
+	m := newManager(lister, client, evaluators)
+	m.indexed = true
+	m.combineMode = combineMode
+	m.unobserved = true
+	return m
+}
+
+// Selects implements Revalidator.
+func (m *mngr) Selects(ctx context.Context, cr *cmapi.CertificateRequest, policy *policyapi.CertificateRequestPolicy) (bool, error) {
+	policies, err := m.filter(ctx, cr, []policyapi.CertificateRequestPolicy{*policy})
+	if err != nil {
+		return false, err
+	}
+	return len(policies) > 0, nil
+}
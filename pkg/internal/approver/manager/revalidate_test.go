@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/manager/predicate"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
+)
+
+func Test_Review_unobserved(t *testing.T) {
+	policies := []policyapi.CertificateRequestPolicy{
+		{ObjectMeta: metav1.ObjectMeta{Name: "policy-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "policy-b"}},
+	}
+
+	tests := map[string]struct {
+		approve     bool
+		unobserved  bool
+		expApproved int
+		expDenied   int
+	}{
+		"if the manager is observed and approves the request, expect the approval to be observed": {
+			approve:     true,
+			expApproved: 1,
+		},
+		"if the manager is observed and denies the request, expect the denials to be observed": {
+			approve:   false,
+			expDenied: 2,
+		},
+		"if the manager is unobserved and approves the request, expect no approval to be observed": {
+			approve:    true,
+			unobserved: true,
+		},
+		"if the manager is unobserved and denies the request, expect no denial to be observed": {
+			approve:    false,
+			unobserved: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			metrics.CertificateRequestApproved.Reset()
+			metrics.CertificateRequestDenied.Reset()
+			metrics.CertificateRequestEvaluationDuration.Reset()
+
+			evaluator := fake.NewFakeEvaluator().WithEvaluate(func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+				if test.approve {
+					return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+				}
+				return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied"}, nil
+			})
+
+			mngr := &mngr{
+				lister:     newPolicyLister(policies),
+				evaluators: []approver.Evaluator{evaluator},
+				workers:    1,
+				unobserved: test.unobserved,
+			}
+
+			_, err := mngr.Review(context.TODO(), new(cmapi.CertificateRequest))
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expApproved, testutil.CollectAndCount(metrics.CertificateRequestApproved), "unexpected approvals")
+			assert.Equal(t, test.expDenied, testutil.CollectAndCount(metrics.CertificateRequestDenied), "unexpected denials")
+			expEvaluations := 1
+			if test.unobserved {
+				expEvaluations = 0
+			}
+			assert.Equal(t, expEvaluations, testutil.CollectAndCount(metrics.CertificateRequestEvaluationDuration), "unexpected evaluations")
+		})
+	}
+}
+
+func Test_Selects(t *testing.T) {
+	var (
+		policy = &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test-policy"}}
+
+		selectsNamed = func(name string) predicate.Predicate {
+			return func(_ context.Context, _ *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+				var selected []policyapi.CertificateRequestPolicy
+				for _, policy := range policies {
+					if policy.Name == name {
+						selected = append(selected, policy)
+					}
+				}
+				return selected, nil
+			}
+		}
+	)
+
+	tests := map[string]struct {
+		predicates []predicate.Predicate
+		expSelects bool
+		expErr     bool
+	}{
+		"if all predicates pass the policy, return true": {
+			predicates: []predicate.Predicate{selectsNamed("test-policy"), selectsNamed("test-policy")},
+			expSelects: true,
+		},
+		"if any predicate excludes the policy, return false": {
+			predicates: []predicate.Predicate{selectsNamed("test-policy"), selectsNamed("other-policy")},
+			expSelects: false,
+		},
+		"if a predicate errors, return an error": {
+			predicates: []predicate.Predicate{
+				func(context.Context, *cmapi.CertificateRequest, []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+					return nil, errors.New("this is an error")
+				},
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mngr := &mngr{predicates: test.predicates}
+
+			selects, err := mngr.Selects(context.TODO(), new(cmapi.CertificateRequest), policy)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expSelects, selects)
+		})
+	}
+}
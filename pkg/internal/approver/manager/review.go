@@ -21,14 +21,23 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
 	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/evaluator"
 	"github.com/cert-manager/approver-policy/pkg/internal/approver/manager/predicate"
+	"github.com/cert-manager/approver-policy/pkg/internal/inherit"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 )
 
 var _ manager.Interface = &mngr{}
@@ -40,8 +49,36 @@ type mngr struct {
 	lister     client.Reader
 	predicates []predicate.Predicate
 	evaluators []approver.Evaluator
+	enrichers  []approver.Enricher
+	coders     []approver.DenialCoder
+
+	// predicateReasons are why a policy is excluded by each of the
+	// predicates, by index. Only used to explain reviews.
+	predicateReasons []string
+
+	// workers is the maximum number of policies evaluated concurrently for a
+	// single request. Defaults to defaultEvaluationWorkers.
+	workers int
+
+	// combineMode is how the decisions of the highest priority policies are
+	// combined. Defaults to CombineModeUnion.
+	combineMode CombineMode
+
+	// indexed marks whether the lister has the indexes registered by
+	// RegisterIndexes, so that only candidate policies are listed for a
+	// request.
+	indexed bool
+
+	// unobserved marks whether reviews are not observed in the approval,
+	// denial and evaluation metrics, since they don't approve or deny the
+	// request.
+	unobserved bool
 }
 
+// defaultEvaluationWorkers is the default maximum number of policies that are
+// evaluated concurrently for a single request.
+const defaultEvaluationWorkers = 8
+
 // policyMessage holds the name of the CertificateRequestPolicy and aggregated
 // message when running the evaluators against the CertificateRequest.
 type policyMessage struct {
@@ -52,6 +89,13 @@ type policyMessage struct {
 	// message is the aggregated messages returned from the evaluators for this
 	// policy.
 	message string
+
+	// reasons are the reasons of the evaluators which denied the request for
+	// this policy.
+	reasons []manager.DenialReason
+
+	// metricsLabels are the metrics labels of the CertificateRequestPolicy.
+	metricsLabels map[string]string
 }
 
 // New constructs a new approver Manager that evaluates whether
@@ -59,21 +103,67 @@ type policyMessage struct {
 // evaluators.
 // CertificateRequestPolicies will be filtered on Review for evaluation with the predicates:
 //   - CertificateRequestPolicy is ready
-//   - CertificateRequestPolicy Selector.IssuerRef matches the CertificateRequest
+//   - CertificateRequestPolicy ActiveSchedule contains the current time
+//   - CertificateRequestPolicy Selector.IssuerRef or Selector.IssuerRefs matches the CertificateRequest
 //
 // IssuerRef
 //   - CertificateRequestPolicy is bound to the user that appears in the
 //     CertificateRequest
 func New(lister client.Reader, client client.Client, evaluators []approver.Evaluator) manager.Interface {
+	return newManager(lister, client, evaluators)
+}
+
+// NewIndexed constructs a new approver Manager as New, which lists only the
+// candidate CertificateRequestPolicies of a request using the field indexes
+// of the lister, rather than all policies. The indexes must have been
+// registered with RegisterIndexes. Policies are read from the lister's
+// informer cache, which is kept up to date by watching policies and is
+// resynced with the API server every sync period of the cache. The decisions
+// of the highest priority policies are combined with the given combine mode.
+func NewIndexed(lister client.Reader, client client.Client, evaluators []approver.Evaluator, combineMode CombineMode) manager.Interface {
+	m := newManager(lister, client, evaluators)
+	m.indexed = true
+	m.combineMode = combineMode
+	return m
+}
+
+// newManager constructs a new approver Manager with the default predicates.
+func newManager(lister client.Reader, client client.Client, evaluators []approver.Evaluator) *mngr {
 	return &mngr{
 		lister: lister,
 		predicates: []predicate.Predicate{
 			predicate.Ready,
-			predicate.SelectorIssuerRef,
+			predicate.ActiveSchedule(clock.RealClock{}),
+			predicate.SelectorIssuerRef(lister),
 			predicate.SelectorNamespace(lister),
+			predicate.SelectorServiceAccount(lister),
+			predicate.SelectorCertificateLabels(lister),
+			predicate.SelectorSecret(lister),
+			predicate.SelectorIsRenewal(lister),
+			predicate.SelectorRequester,
+			predicate.SelectorRequestAnnotations,
+			predicate.SelectorRequestLabels(),
+			predicate.SelectorDuration,
 			predicate.RBACBound(client),
 		},
+		predicateReasons: []string{
+			"status.conditions: policy is not Ready",
+			"spec.activeSchedule: policy is not active at this time",
+			"spec.selector.issuerRef, spec.selector.issuerRefs: does not match the request's issuer",
+			"spec.selector.namespace: does not match the request's namespace",
+			"spec.selector.serviceAccount: does not match the request's ServiceAccount",
+			"spec.selector.certificateLabels: does not match the labels of the request's Certificate",
+			"spec.selector.secretName, spec.selector.secretLabels: does not match the Secret of the request's Certificate",
+			"spec.selector.isRenewal: does not match whether the request is a renewal",
+			"spec.selector.requester: does not match the request's requester",
+			"spec.selector.requestAnnotations: does not match the request's annotations",
+			"spec.selector.requestLabels: does not match the request's labels",
+			"spec.selector.minDuration, spec.selector.maxDuration: does not match the request's duration",
+			"policy is not bound to the requester with RBAC",
+		},
 		evaluators: evaluators,
+		enrichers:  enrichers(evaluators),
+		coders:     denialCoders(evaluators),
 	}
 }
 
@@ -81,81 +171,428 @@ func New(lister client.Reader, client client.Client, evaluators []approver.Evalu
 // approved. All evaluators will be called with CertificateRequestPolicys that
 // have passed all of the predicates.
 func (m *mngr) Review(ctx context.Context, cr *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
-	policyList := new(policyapi.CertificateRequestPolicyList)
-	if err := m.lister.List(ctx, policyList); err != nil {
+	start := time.Now()
+	// The issuer of the request is shared by every policy evaluated, so is
+	// only got once.
+	ctx = util.WithIssuerMetadataCache(ctx)
+	response, err := m.review(ctx, cr)
+	if !m.unobserved {
+		metrics.ObserveEvaluationDuration(resultLabel(response.Result, err), start)
+	}
+	return response, err
+}
+
+// resultLabel returns the metrics label value for the given review result.
+func resultLabel(result manager.ReviewResult, err error) string {
+	if err != nil {
+		return "error"
+	}
+	switch result {
+	case manager.ResultApproved:
+		return "approved"
+	case manager.ResultDenied:
+		return "denied"
+	case manager.ResultUnprocessed:
+		return "unprocessed"
+	default:
+		return "unknown"
+	}
+}
+
+// review performs the review of the CertificateRequest for Review.
+func (m *mngr) review(ctx context.Context, cr *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+	candidates, exist, err := m.candidates(ctx, cr)
+	if err != nil {
 		return manager.ReviewResponse{}, err
 	}
 
 	// If no CertificateRequestPolicies exist in the cluster, return
 	// ResultUnprocessed. A CertificateRequest may be re-evaluated at a later
 	// time if a CertificateRequestPolicy is created.
-	if len(policyList.Items) == 0 {
-		return manager.ReviewResponse{Result: manager.ResultUnprocessed, Message: "No CertificateRequestPolicies exist"}, nil
+	if !exist {
+		return manager.ReviewResponse{Result: manager.ResultUnprocessed, Message: messageNoPolicies}, nil
 	}
 
-	var (
-		policies = policyList.Items
-		err      error
-	)
-	for _, predicate := range m.predicates {
-		policies, err = predicate(ctx, cr, policies)
-		if err != nil {
-			return manager.ReviewResponse{}, fmt.Errorf("failed to perform predicate on policies: %w", err)
-		}
+	policies, err := m.filter(ctx, cr, candidates)
+	if err != nil {
+		return manager.ReviewResponse{}, err
 	}
 
+	// Only evaluate the policies with the highest priority.
+	policies = highestPriority(policies)
+
 	// If no policies are appropriate, return ResultUnprocessed.
 	if len(policies) == 0 {
 		return manager.ReviewResponse{
 			Result:  manager.ResultUnprocessed,
-			Message: "No CertificateRequestPolicies bound or applicable",
+			Message: messageNoApplicablePolicies,
 		}, nil
 	}
 
 	// policyMessages hold the aggregated messages of each evaluator response,
 	// keyed by the policy name that was executed.
-	var policyMessages []policyMessage
+	// Messages of policies with the Warn enforcement are kept separately,
+	// since they don't deny the request.
+	var (
+		policyMessages    []policyMessage
+		warnMessages      []policyMessage
+		evaluatedPolicies []string
+	)
 
-	// Run every evaluators against ever policy which is bound to the requesting
-	// user.
-	for _, policy := range policies {
-		var (
-			evaluatorDenied   bool
-			evaluatorMessages []string
-		)
-
-		for _, evaluator := range m.evaluators {
-			response, err := evaluator.Evaluate(ctx, &policy, cr)
-			if err != nil {
-				// if a single evaluator errors, then return early without trying
-				// others.
-				return manager.ReviewResponse{}, err
-			}
+	results := m.evaluatePolicies(ctx, policies, cr)
 
-			if len(response.Message) > 0 {
-				evaluatorMessages = append(evaluatorMessages, response.Message)
-			}
+	// In the intersection combine mode, every policy must approve the request.
+	if m.combineMode == CombineModeIntersection {
+		return m.reviewIntersection(cr, policies, results)
+	}
 
-			// evaluatorDenied will be set to true if any evaluator denies. We don't
-			// break early so that we can capture the responses from _all_
-			// evaluators.
-			if response.Result == approver.ResultDenied {
-				evaluatorDenied = true
+	// If any authoritative policy denied the request, the request is denied
+	// regardless of whether other policies approve it.
+	var (
+		authoritativeMessages []policyMessage
+		authoritativePolicies []string
+	)
+	for i, result := range results {
+		if !authoritative(&policies[i]) {
+			break
+		}
+		if result.err != nil {
+			return manager.ReviewResponse{}, result.err
+		}
+		authoritativePolicies = append(authoritativePolicies, policies[i].Name)
+		if result.denied {
+			authoritativeMessages = append(authoritativeMessages, policyMessage{name: policies[i].Name, message: result.message, reasons: result.reasons, metricsLabels: policies[i].Spec.MetricsLabels})
+		}
+	}
+	if len(authoritativeMessages) > 0 {
+		if !m.unobserved {
+			for _, policyMessage := range authoritativeMessages {
+				metrics.ObserveDenied(policyMessage.name, policyMessage.metricsLabels, cr)
 			}
 		}
+		return manager.ReviewResponse{
+			Result:            manager.ResultDenied,
+			Message:           deniedMessage(authoritativeMessages),
+			Reasons:           deniedReasons(authoritativeMessages),
+			EvaluatedPolicies: authoritativePolicies,
+		}, nil
+	}
+
+	// Results are considered in policy order, so that the request is approved
+	// by the first approving policy, regardless of which policy finished
+	// evaluating first.
+	for i, result := range results {
+		if result.err != nil {
+			return manager.ReviewResponse{}, result.err
+		}
+
+		evaluatedPolicies = append(evaluatedPolicies, policies[i].Name)
 
 		// If no evaluator denied the request, return with approved response.
-		if !evaluatorDenied {
+		if !result.denied {
+			if !m.unobserved {
+				metrics.ObserveApproved(policies[i].Name, policies[i].Spec.MetricsLabels, cr)
+			}
 			return manager.ReviewResponse{
-				Result:  manager.ResultApproved,
-				Message: fmt.Sprintf("Approved by CertificateRequestPolicy: %q", policy.Name),
+				Result:            manager.ResultApproved,
+				Message:           approvedMessage(policies[i].Name),
+				ApprovedBy:        policies[i].Name,
+				EvaluatedPolicies: evaluatedPolicies,
 			}, nil
 		}
 
 		// Collect evaluator messages that were executed for this policy.
-		policyMessages = append(policyMessages, policyMessage{name: policy.Name, message: strings.Join(evaluatorMessages, ", ")})
+		message := policyMessage{name: policies[i].Name, message: result.message, reasons: result.reasons, metricsLabels: policies[i].Spec.MetricsLabels}
+		if enforcementWarn(&policies[i]) {
+			warnMessages = append(warnMessages, message)
+		} else {
+			policyMessages = append(policyMessages, message)
+		}
+	}
+
+	// If no policy approved the request, but a policy with the Warn
+	// enforcement would have denied it, approve the request with warnings.
+	if len(warnMessages) > 0 {
+		if !m.unobserved {
+			for _, policyMessage := range warnMessages {
+				metrics.ObserveWouldDeny(policyMessage.name, cr)
+			}
+		}
+		return manager.ReviewResponse{
+			Result:            manager.ResultApproved,
+			Message:           warnApprovedMessage(warnMessages[0].name),
+			ApprovedBy:        warnMessages[0].name,
+			Warnings:          warnings(warnMessages),
+			EvaluatedPolicies: evaluatedPolicies,
+		}, nil
+	}
+
+	if !m.unobserved {
+		for _, policyMessage := range policyMessages {
+			metrics.ObserveDenied(policyMessage.name, policyMessage.metricsLabels, cr)
+		}
+	}
+
+	// Return with all policies that we consulted, and their errors to why the
+	// request was denied.
+	return manager.ReviewResponse{
+		Result:            manager.ResultDenied,
+		Message:           deniedMessage(policyMessages),
+		Reasons:           deniedReasons(policyMessages),
+		EvaluatedPolicies: evaluatedPolicies,
+	}, nil
+}
+
+const (
+	// messageNoPolicies is the review message when no
+	// CertificateRequestPolicies exist.
+	messageNoPolicies = "No CertificateRequestPolicies exist"
+
+	// messageNoApplicablePolicies is the review message when no
+	// CertificateRequestPolicies pass the predicates for a request.
+	messageNoApplicablePolicies = "No CertificateRequestPolicies bound or applicable"
+)
+
+// filter returns the subset of policies which pass all of the manager's
+// predicates for the request.
+func (m *mngr) filter(ctx context.Context, cr *cmapi.CertificateRequest, policies []policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+	var err error
+	for _, predicate := range m.predicates {
+		policies, err = predicate(ctx, cr, policies)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perform predicate on policies: %w", err)
+		}
+	}
+	return policies, nil
+}
+
+// highestPriority returns the subset of policies which have the highest
+// priority, with authoritative policies first, then sorted by name. A `nil`
+// priority is equivalent to 0.
+func highestPriority(policies []policyapi.CertificateRequestPolicy) []policyapi.CertificateRequestPolicy {
+	if len(policies) == 0 {
+		return policies
+	}
+
+	priorityOf := func(policy policyapi.CertificateRequestPolicy) int {
+		if policy.Spec.Priority == nil {
+			return 0
+		}
+		return *policy.Spec.Priority
 	}
 
+	highest := priorityOf(policies[0])
+	for _, policy := range policies[1:] {
+		if p := priorityOf(policy); p > highest {
+			highest = p
+		}
+	}
+
+	var highestPolicies []policyapi.CertificateRequestPolicy
+	for _, policy := range policies {
+		if priorityOf(policy) == highest {
+			highestPolicies = append(highestPolicies, policy)
+		}
+	}
+
+	// Authoritative policies are evaluated first, so that all of them are
+	// evaluated before any other policy may approve the request. Tie-break
+	// policies by name.
+	sort.SliceStable(highestPolicies, func(i, j int) bool {
+		if a, b := authoritative(&highestPolicies[i]), authoritative(&highestPolicies[j]); a != b {
+			return a
+		}
+		return highestPolicies[i].Name < highestPolicies[j].Name
+	})
+
+	return highestPolicies
+}
+
+// evaluate runs all evaluators against the request for the given policy,
+// once its base policies have been resolved.
+// Returns whether any evaluator denied the request, along with the aggregated
+// messages of all evaluators, and the reasons of the evaluators which denied.
+func (m *mngr) evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (bool, string, []manager.DenialReason, error) {
+	resolved, err := inherit.Resolve(ctx, m.lister, policy)
+	if err != nil {
+		return false, "", nil, fmt.Errorf("failed to resolve base policies of %q: %w", policy.Name, err)
+	}
+
+	policy, err = m.enrich(ctx, resolved, cr)
+	if err != nil {
+		return false, "", nil, err
+	}
+
+	response, err := evaluator.New(m.evaluators...).Review(ctx, policy, cr)
+	if err != nil {
+		return false, "", nil, err
+	}
+
+	var reasons []manager.DenialReason
+	for _, denial := range response.Denials {
+		reasons = append(reasons, denialReasons(policy.Name, denial, m.coders)...)
+	}
+
+	return response.Decision == evaluator.DecisionDenied, response.Message, reasons, nil
+}
+
+// policyResult is the result of evaluating a single policy against a
+// request.
+type policyResult struct {
+	denied  bool
+	message string
+	reasons []manager.DenialReason
+	err     error
+}
+
+// evaluatePolicies evaluates the given policies against the request using a
+// bounded pool of workers, returning the results in policy order.
+// Once a policy which is not authoritative has approved the request, or a
+// policy has failed to evaluate, the results of all later policies can't
+// change the outcome of the review, so those policies are skipped and their
+// results are truncated. Authoritative policies are ordered first by
+// highestPriority, so are never skipped because of an approval. In the
+// intersection combine mode, no policy is skipped because of an approval.
+func (m *mngr) evaluatePolicies(ctx context.Context, policies []policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) []policyResult {
+	results := make([]policyResult, len(policies))
+
+	workers := m.workers
+	if workers <= 0 {
+		workers = defaultEvaluationWorkers
+	}
+	if workers > len(policies) {
+		workers = len(policies)
+	}
+
+	// decided is the lowest index of a policy which approved the request or
+	// failed to evaluate.
+	var decided atomic.Int64
+	decided.Store(int64(len(policies)))
+
+	var (
+		wg      sync.WaitGroup
+		indexes = make(chan int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if int64(i) > decided.Load() {
+					continue
+				}
+
+				denied, message, reasons, err := m.evaluate(ctx, &policies[i], cr)
+				results[i] = policyResult{denied: denied, message: message, reasons: reasons, err: err}
+				if err == nil && (denied || authoritative(&policies[i]) || m.combineMode == CombineModeIntersection) {
+					continue
+				}
+
+				for {
+					current := decided.Load()
+					if int64(i) >= current || decided.CompareAndSwap(current, int64(i)) {
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range policies {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if i := decided.Load(); i < int64(len(policies)) {
+		return results[:i+1]
+	}
+	return results
+}
+
+// denialReasons returns the machine readable reasons of an evaluator response
+// which denied the request. Evaluators which don't give field errors have
+// their message used as the detail of a single reason. Each field error is
+// coded by the first of the coders which recognises it.
+func denialReasons(policyName string, response approver.EvaluationResponse, coders []approver.DenialCoder) []manager.DenialReason {
+	if len(response.Errors) == 0 {
+		if len(response.Message) == 0 {
+			return nil
+		}
+		return []manager.DenialReason{{Policy: policyName, Detail: response.Message}}
+	}
+
+	reasons := make([]manager.DenialReason, 0, len(response.Errors))
+	for _, err := range response.Errors {
+		reasons = append(reasons, manager.DenialReason{Policy: policyName, Field: err.Field, Code: denialCode(coders, err), Detail: err.ErrorBody()})
+	}
+	return reasons
+}
+
+// denialCoders returns the subset of evaluators which implement
+// approver.DenialCoder, in the order they are given.
+func denialCoders(evaluators []approver.Evaluator) []approver.DenialCoder {
+	var coders []approver.DenialCoder
+	for _, evaluator := range evaluators {
+		if coder, ok := evaluator.(approver.DenialCoder); ok {
+			coders = append(coders, coder)
+		}
+	}
+	return coders
+}
+
+// denialCode returns the code of the field error given by the first of the
+// coders which recognises it, or an empty code if none do.
+func denialCode(coders []approver.DenialCoder, err *field.Error) approver.DenialCode {
+	for _, coder := range coders {
+		if code := coder.DenialCode(err); len(code) > 0 {
+			return code
+		}
+	}
+	return ""
+}
+
+// approvedMessage returns the review message for a request approved by the
+// given policy.
+func approvedMessage(policyName string) string {
+	return fmt.Sprintf("Approved by CertificateRequestPolicy: %q", policyName)
+}
+
+// warnApprovedMessage returns the review message for a request approved
+// because the given policy, and any other policy which would have denied it,
+// has the Warn enforcement.
+func warnApprovedMessage(policyName string) string {
+	return fmt.Sprintf("Approved by CertificateRequestPolicy: %q with Warn enforcement, which would have denied this request", policyName)
+}
+
+// warnings returns why each of the given policies would have denied the
+// request, sorted by policy name.
+func warnings(policyMessages []policyMessage) []string {
+	sorted := append([]policyMessage(nil), policyMessages...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].name < sorted[j].name
+	})
+	var warnings []string
+	for _, policyMessage := range sorted {
+		warnings = append(warnings, fmt.Sprintf("CertificateRequestPolicy %q would deny this request: %s", policyMessage.name, policyMessage.message))
+	}
+	return warnings
+}
+
+// authoritative returns whether a denial by the given policy is final. Denials
+// by policies with the Warn enforcement are never final.
+func authoritative(policy *policyapi.CertificateRequestPolicy) bool {
+	return policy.Spec.Selector.Authoritative && !enforcementWarn(policy)
+}
+
+// enforcementWarn returns whether the given policy has the Warn enforcement.
+func enforcementWarn(policy *policyapi.CertificateRequestPolicy) bool {
+	return policy.Spec.Enforcement != nil && *policy.Spec.Enforcement == policyapi.EnforcementWarn
+}
+
+// deniedMessage returns the review message for a request denied by all of
+// the given policies.
+func deniedMessage(policyMessages []policyMessage) string {
 	// Sort messages by policy name and build message string.
 	sort.SliceStable(policyMessages, func(i, j int) bool {
 		return policyMessages[i].name < policyMessages[j].name
@@ -164,11 +601,18 @@ func (m *mngr) Review(ctx context.Context, cr *cmapi.CertificateRequest) (manage
 	for _, policyMessage := range policyMessages {
 		messages = append(messages, fmt.Sprintf("[%s: %s]", policyMessage.name, policyMessage.message))
 	}
+	return fmt.Sprintf("No policy approved this request: %s", strings.Join(messages, " "))
+}
 
-	// Return with all policies that we consulted, and their errors to why the
-	// request was denied.
-	return manager.ReviewResponse{
-		Result:  manager.ResultDenied,
-		Message: fmt.Sprintf("No policy approved this request: %s", strings.Join(messages, " ")),
-	}, nil
+// deniedReasons returns the reasons of all of the given policies which denied
+// the request, sorted by policy name.
+func deniedReasons(policyMessages []policyMessage) []manager.DenialReason {
+	sort.SliceStable(policyMessages, func(i, j int) bool {
+		return policyMessages[i].name < policyMessages[j].name
+	})
+	var reasons []manager.DenialReason
+	for _, policyMessage := range policyMessages {
+		reasons = append(reasons, policyMessage.reasons...)
+	}
+	return reasons
 }
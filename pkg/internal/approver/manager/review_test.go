@@ -24,14 +24,18 @@ import (
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
 	"github.com/cert-manager/approver-policy/pkg/approver/fake"
 	"github.com/cert-manager/approver-policy/pkg/approver/manager"
 	"github.com/cert-manager/approver-policy/pkg/internal/approver/manager/predicate"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
 	"github.com/cert-manager/approver-policy/test/env"
 )
 
@@ -119,8 +123,13 @@ func Test_Review(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Name: "test-policy-a"},
 				Spec:       policyapi.CertificateRequestPolicySpec{Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}}},
 			}},
-			expResponse: manager.ReviewResponse{Result: manager.ResultDenied, Message: "No policy approved this request: [test-policy-a: this is a denied response]"},
-			expErr:      false,
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultDenied,
+				Message:           "No policy approved this request: [test-policy-a: this is a denied response]",
+				Reasons:           []manager.DenialReason{{Policy: "test-policy-a", Detail: "this is a denied response"}},
+				EvaluatedPolicies: []string{"test-policy-a"},
+			},
+			expErr: false,
 		},
 		"if single policy returns and evaluator returns not-denied, return ResultApproved": {
 			evaluator: func(t *testing.T) approver.Evaluator {
@@ -140,8 +149,13 @@ func Test_Review(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Name: "test-policy-a"},
 				Spec:       policyapi.CertificateRequestPolicySpec{Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}}},
 			}},
-			expResponse: manager.ReviewResponse{Result: manager.ResultApproved, Message: `Approved by CertificateRequestPolicy: "test-policy-a"`},
-			expErr:      false,
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "test-policy-a"`,
+				ApprovedBy:        "test-policy-a",
+				EvaluatedPolicies: []string{"test-policy-a"},
+			},
+			expErr: false,
 		},
 		"if two policies returned and evaluator returns one not-denied, return ResultApproved": {
 			evaluator: func(t *testing.T) approver.Evaluator {
@@ -176,8 +190,13 @@ func Test_Review(t *testing.T) {
 					Spec:       policyapi.CertificateRequestPolicySpec{Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}}},
 				},
 			},
-			expResponse: manager.ReviewResponse{Result: manager.ResultApproved, Message: `Approved by CertificateRequestPolicy: "test-policy-b"`},
-			expErr:      false,
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "test-policy-b"`,
+				ApprovedBy:        "test-policy-b",
+				EvaluatedPolicies: []string{"test-policy-a", "test-policy-b"},
+			},
+			expErr: false,
 		},
 		"if two policies returned and both return denied, return ResultDenied": {
 			evaluator: func(t *testing.T) approver.Evaluator {
@@ -209,8 +228,16 @@ func Test_Review(t *testing.T) {
 					Spec:       policyapi.CertificateRequestPolicySpec{Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}}},
 				},
 			},
-			expResponse: manager.ReviewResponse{Result: manager.ResultDenied, Message: "No policy approved this request: [test-policy-a: this is a denied response] [test-policy-b: this is a denied response]"},
-			expErr:      false,
+			expResponse: manager.ReviewResponse{
+				Result:  manager.ResultDenied,
+				Message: "No policy approved this request: [test-policy-a: this is a denied response] [test-policy-b: this is a denied response]",
+				Reasons: []manager.DenialReason{
+					{Policy: "test-policy-a", Detail: "this is a denied response"},
+					{Policy: "test-policy-b", Detail: "this is a denied response"},
+				},
+				EvaluatedPolicies: []string{"test-policy-a", "test-policy-b"},
+			},
+			expErr: false,
 		},
 	}
 
@@ -255,3 +282,470 @@ func Test_Review(t *testing.T) {
 		})
 	}
 }
+
+func Test_Review_enforcement(t *testing.T) {
+	policy := func(name string, enforcement *policyapi.CertificateRequestPolicyEnforcement) policyapi.CertificateRequestPolicy {
+		return policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       policyapi.CertificateRequestPolicySpec{Enforcement: enforcement},
+		}
+	}
+	enforcement := func(e policyapi.CertificateRequestPolicyEnforcement) *policyapi.CertificateRequestPolicyEnforcement {
+		return &e
+	}
+
+	tests := map[string]struct {
+		policies     []policyapi.CertificateRequestPolicy
+		approve      map[string]bool
+		expResponse  manager.ReviewResponse
+		expWouldDeny map[string]float64
+	}{
+		"if a policy with Warn enforcement would deny, return ResultApproved with warnings": {
+			policies: []policyapi.CertificateRequestPolicy{policy("policy-warn", enforcement(policyapi.EnforcementWarn))},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-warn" with Warn enforcement, which would have denied this request`,
+				ApprovedBy:        "policy-warn",
+				Warnings:          []string{`CertificateRequestPolicy "policy-warn" would deny this request: denied`},
+				EvaluatedPolicies: []string{"policy-warn"},
+			},
+			expWouldDeny: map[string]float64{"policy-warn": 1},
+		},
+		"if a policy with Deny enforcement would deny, return ResultDenied": {
+			policies: []policyapi.CertificateRequestPolicy{policy("policy-deny", enforcement(policyapi.EnforcementDeny))},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultDenied,
+				Message:           "No policy approved this request: [policy-deny: denied]",
+				Reasons:           []manager.DenialReason{{Policy: "policy-deny", Detail: "denied"}},
+				EvaluatedPolicies: []string{"policy-deny"},
+			},
+			expWouldDeny: map[string]float64{"policy-deny": 0},
+		},
+		"if policies with Warn and Deny enforcement would deny, return ResultApproved with warnings of the Warn policy": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("policy-a-deny", nil),
+				policy("policy-b-warn", enforcement(policyapi.EnforcementWarn)),
+			},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b-warn" with Warn enforcement, which would have denied this request`,
+				ApprovedBy:        "policy-b-warn",
+				Warnings:          []string{`CertificateRequestPolicy "policy-b-warn" would deny this request: denied`},
+				EvaluatedPolicies: []string{"policy-a-deny", "policy-b-warn"},
+			},
+			expWouldDeny: map[string]float64{"policy-a-deny": 0, "policy-b-warn": 1},
+		},
+		"if a policy with Warn enforcement would deny but another policy approves, return ResultApproved without warnings": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("policy-a-warn", enforcement(policyapi.EnforcementWarn)),
+				policy("policy-b", nil),
+			},
+			approve: map[string]bool{"policy-b": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b"`,
+				ApprovedBy:        "policy-b",
+				EvaluatedPolicies: []string{"policy-a-warn", "policy-b"},
+			},
+			expWouldDeny: map[string]float64{"policy-a-warn": 0},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			metrics.CertificateRequestWouldDeny.Reset()
+
+			evaluator := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+				if test.approve[policy.Name] {
+					return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+				}
+				return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied"}, nil
+			})
+
+			mngr := &mngr{
+				lister:     newPolicyLister(test.policies),
+				evaluators: []approver.Evaluator{evaluator},
+				workers:    1,
+			}
+
+			response, err := mngr.Review(context.TODO(), new(cmapi.CertificateRequest))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+
+			for policyName, exp := range test.expWouldDeny {
+				assert.Equal(t, exp, testutil.ToFloat64(metrics.CertificateRequestWouldDeny.WithLabelValues(policyName, cmapi.IssuerKind)), "would deny %s", policyName)
+			}
+		})
+	}
+}
+
+func Test_Review_authoritative(t *testing.T) {
+	policy := func(name string, authoritative bool, mods ...func(*policyapi.CertificateRequestPolicy)) policyapi.CertificateRequestPolicy {
+		policy := policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{Authoritative: authoritative},
+			},
+		}
+		for _, mod := range mods {
+			mod(&policy)
+		}
+		return policy
+	}
+	warn := func(policy *policyapi.CertificateRequestPolicy) {
+		enforcement := policyapi.EnforcementWarn
+		policy.Spec.Enforcement = &enforcement
+	}
+	priority := func(p int) func(*policyapi.CertificateRequestPolicy) {
+		return func(policy *policyapi.CertificateRequestPolicy) {
+			policy.Spec.Priority = &p
+		}
+	}
+
+	tests := map[string]struct {
+		policies    []policyapi.CertificateRequestPolicy
+		approve     map[string]bool
+		expResponse manager.ReviewResponse
+	}{
+		"if an authoritative policy denies and a permissive policy approves, return ResultDenied by the authoritative policy": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("policy-a-permissive", false),
+				policy("policy-b-authoritative", true),
+			},
+			approve: map[string]bool{"policy-a-permissive": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultDenied,
+				Message:           "No policy approved this request: [policy-b-authoritative: denied]",
+				Reasons:           []manager.DenialReason{{Policy: "policy-b-authoritative", Detail: "denied"}},
+				EvaluatedPolicies: []string{"policy-b-authoritative"},
+			},
+		},
+		"if an authoritative policy approves and a permissive policy approves, return ResultApproved by the authoritative policy": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("policy-a-permissive", false),
+				policy("policy-b-authoritative", true),
+			},
+			approve: map[string]bool{"policy-a-permissive": true, "policy-b-authoritative": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b-authoritative"`,
+				ApprovedBy:        "policy-b-authoritative",
+				EvaluatedPolicies: []string{"policy-b-authoritative"},
+			},
+		},
+		"if one authoritative policy approves and another denies, return ResultDenied": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("policy-a-authoritative", true),
+				policy("policy-b-authoritative", true),
+				policy("policy-c-permissive", false),
+			},
+			approve: map[string]bool{"policy-a-authoritative": true, "policy-c-permissive": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultDenied,
+				Message:           "No policy approved this request: [policy-b-authoritative: denied]",
+				Reasons:           []manager.DenialReason{{Policy: "policy-b-authoritative", Detail: "denied"}},
+				EvaluatedPolicies: []string{"policy-a-authoritative", "policy-b-authoritative"},
+			},
+		},
+		"if an authoritative policy with Warn enforcement denies and a permissive policy approves, return ResultApproved by the permissive policy": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("policy-a-authoritative", true, warn),
+				policy("policy-b-permissive", false),
+			},
+			approve: map[string]bool{"policy-b-permissive": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b-permissive"`,
+				ApprovedBy:        "policy-b-permissive",
+				EvaluatedPolicies: []string{"policy-a-authoritative", "policy-b-permissive"},
+			},
+		},
+		"if an authoritative policy denies but a permissive policy of a higher priority approves, return ResultApproved by the permissive policy": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("policy-a-authoritative", true),
+				policy("policy-b-permissive", false, priority(10)),
+			},
+			approve: map[string]bool{"policy-b-permissive": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b-permissive"`,
+				ApprovedBy:        "policy-b-permissive",
+				EvaluatedPolicies: []string{"policy-b-permissive"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+				if test.approve[policy.Name] {
+					return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+				}
+				return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied"}, nil
+			})
+
+			mngr := &mngr{
+				lister:     newPolicyLister(test.policies),
+				evaluators: []approver.Evaluator{evaluator},
+				workers:    len(test.policies),
+			}
+
+			response, err := mngr.Review(context.TODO(), new(cmapi.CertificateRequest))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+
+			dryRun, err := mngr.DryRun(context.TODO(), new(cmapi.CertificateRequest), "")
+			assert.NoError(t, err)
+			// Dry runs decide the same as Review.
+			expDryRunResult := map[manager.ReviewResult]DryRunResult{manager.ResultApproved: DryRunResultApproved, manager.ResultDenied: DryRunResultDenied}
+			assert.Equal(t, expDryRunResult[test.expResponse.Result], dryRun.Result)
+			assert.Equal(t, test.expResponse.Message, dryRun.Message)
+		})
+	}
+}
+
+func Test_Review_combineMode(t *testing.T) {
+	policy := func(name string, mods ...func(*policyapi.CertificateRequestPolicy)) policyapi.CertificateRequestPolicy {
+		policy := policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		for _, mod := range mods {
+			mod(&policy)
+		}
+		return policy
+	}
+	warn := func(policy *policyapi.CertificateRequestPolicy) {
+		enforcement := policyapi.EnforcementWarn
+		policy.Spec.Enforcement = &enforcement
+	}
+	priority := func(p int) func(*policyapi.CertificateRequestPolicy) {
+		return func(policy *policyapi.CertificateRequestPolicy) {
+			policy.Spec.Priority = &p
+		}
+	}
+
+	tests := map[string]struct {
+		combineMode CombineMode
+		policies    []policyapi.CertificateRequestPolicy
+		approve     map[string]bool
+		expResponse manager.ReviewResponse
+	}{
+		"if one of two overlapping policies approves in union mode, return ResultApproved by the approving policy": {
+			combineMode: CombineModeUnion,
+			policies:    []policyapi.CertificateRequestPolicy{policy("policy-a"), policy("policy-b")},
+			approve:     map[string]bool{"policy-b": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b"`,
+				ApprovedBy:        "policy-b",
+				EvaluatedPolicies: []string{"policy-a", "policy-b"},
+			},
+		},
+		"if one of two overlapping policies approves in intersection mode, return ResultDenied by the denying policy": {
+			combineMode: CombineModeIntersection,
+			policies:    []policyapi.CertificateRequestPolicy{policy("policy-a"), policy("policy-b")},
+			approve:     map[string]bool{"policy-b": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultDenied,
+				Message:           "No policy approved this request: [policy-a: denied]",
+				Reasons:           []manager.DenialReason{{Policy: "policy-a", Detail: "denied"}},
+				EvaluatedPolicies: []string{"policy-a", "policy-b"},
+			},
+		},
+		"if all overlapping policies approve in intersection mode, return ResultApproved by the first policy": {
+			combineMode: CombineModeIntersection,
+			policies:    []policyapi.CertificateRequestPolicy{policy("policy-a"), policy("policy-b"), policy("policy-c")},
+			approve:     map[string]bool{"policy-a": true, "policy-b": true, "policy-c": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by all CertificateRequestPolicies: "policy-a", "policy-b", "policy-c"`,
+				ApprovedBy:        "policy-a",
+				EvaluatedPolicies: []string{"policy-a", "policy-b", "policy-c"},
+			},
+		},
+		"if all overlapping policies deny in intersection mode, return ResultDenied by all policies": {
+			combineMode: CombineModeIntersection,
+			policies:    []policyapi.CertificateRequestPolicy{policy("policy-a"), policy("policy-b")},
+			expResponse: manager.ReviewResponse{
+				Result:  manager.ResultDenied,
+				Message: "No policy approved this request: [policy-a: denied] [policy-b: denied]",
+				Reasons: []manager.DenialReason{
+					{Policy: "policy-a", Detail: "denied"},
+					{Policy: "policy-b", Detail: "denied"},
+				},
+				EvaluatedPolicies: []string{"policy-a", "policy-b"},
+			},
+		},
+		"if a policy with Warn enforcement denies and the other policy approves in intersection mode, return ResultApproved with warnings": {
+			combineMode: CombineModeIntersection,
+			policies:    []policyapi.CertificateRequestPolicy{policy("policy-a", warn), policy("policy-b")},
+			approve:     map[string]bool{"policy-b": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b"`,
+				ApprovedBy:        "policy-b",
+				Warnings:          []string{`CertificateRequestPolicy "policy-a" would deny this request: denied`},
+				EvaluatedPolicies: []string{"policy-a", "policy-b"},
+			},
+		},
+		"if a policy of a lower priority denies in intersection mode, return ResultApproved by the higher priority policy": {
+			combineMode: CombineModeIntersection,
+			policies:    []policyapi.CertificateRequestPolicy{policy("policy-a"), policy("policy-b", priority(10))},
+			approve:     map[string]bool{"policy-b": true},
+			expResponse: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				Message:           `Approved by CertificateRequestPolicy: "policy-b"`,
+				ApprovedBy:        "policy-b",
+				EvaluatedPolicies: []string{"policy-b"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, _ *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+				if test.approve[policy.Name] {
+					return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+				}
+				return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied"}, nil
+			})
+
+			mngr := &mngr{
+				lister:      newPolicyLister(test.policies),
+				evaluators:  []approver.Evaluator{evaluator},
+				workers:     len(test.policies),
+				combineMode: test.combineMode,
+			}
+
+			response, err := mngr.Review(context.TODO(), new(cmapi.CertificateRequest))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+
+			dryRun, err := mngr.DryRun(context.TODO(), new(cmapi.CertificateRequest), "")
+			assert.NoError(t, err)
+			// Dry runs decide the same as Review.
+			expDryRunResult := map[manager.ReviewResult]DryRunResult{manager.ResultApproved: DryRunResultApproved, manager.ResultDenied: DryRunResultDenied}
+			assert.Equal(t, expDryRunResult[test.expResponse.Result], dryRun.Result)
+			assert.Equal(t, test.expResponse.Message, dryRun.Message)
+			assert.Equal(t, test.expResponse.Warnings, dryRun.Warnings)
+		})
+	}
+}
+
+func Test_highestPriority(t *testing.T) {
+	policy := func(name string, priority *int) policyapi.CertificateRequestPolicy {
+		return policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       policyapi.CertificateRequestPolicySpec{Priority: priority},
+		}
+	}
+
+	authoritativePolicy := func(name string) policyapi.CertificateRequestPolicy {
+		policy := policy(name, nil)
+		policy.Spec.Selector.Authoritative = true
+		return policy
+	}
+
+	tests := map[string]struct {
+		policies    []policyapi.CertificateRequestPolicy
+		expPolicies []policyapi.CertificateRequestPolicy
+	}{
+		"no policies should return no policies": {
+			policies:    nil,
+			expPolicies: nil,
+		},
+		"policies with no priority should all be returned sorted by name": {
+			policies:    []policyapi.CertificateRequestPolicy{policy("c", nil), policy("a", nil), policy("b", nil)},
+			expPolicies: []policyapi.CertificateRequestPolicy{policy("a", nil), policy("b", nil), policy("c", nil)},
+		},
+		"a nil priority should be treated the same as 0": {
+			policies:    []policyapi.CertificateRequestPolicy{policy("b", pointer.Int(0)), policy("a", nil)},
+			expPolicies: []policyapi.CertificateRequestPolicy{policy("a", nil), policy("b", pointer.Int(0))},
+		},
+		"only the policy with the highest priority should be returned": {
+			policies:    []policyapi.CertificateRequestPolicy{policy("a", nil), policy("b", pointer.Int(10)), policy("c", pointer.Int(5))},
+			expPolicies: []policyapi.CertificateRequestPolicy{policy("b", pointer.Int(10))},
+		},
+		"authoritative policies should be returned before other policies": {
+			policies:    []policyapi.CertificateRequestPolicy{policy("a", nil), authoritativePolicy("c"), policy("b", nil), authoritativePolicy("d")},
+			expPolicies: []policyapi.CertificateRequestPolicy{authoritativePolicy("c"), authoritativePolicy("d"), policy("a", nil), policy("b", nil)},
+		},
+		"policies tied on the highest priority should be returned sorted by name": {
+			policies:    []policyapi.CertificateRequestPolicy{policy("d", pointer.Int(10)), policy("a", nil), policy("b", pointer.Int(10)), policy("c", pointer.Int(5))},
+			expPolicies: []policyapi.CertificateRequestPolicy{policy("b", pointer.Int(10)), policy("d", pointer.Int(10))},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expPolicies, highestPriority(test.policies))
+		})
+	}
+}
+
+func Test_denialReasons(t *testing.T) {
+	tests := map[string]struct {
+		response   approver.EvaluationResponse
+		coders     []approver.DenialCoder
+		expReasons []manager.DenialReason
+	}{
+		"if response has no message or errors, return no reasons": {
+			response:   approver.EvaluationResponse{Result: approver.ResultDenied},
+			expReasons: nil,
+		},
+		"if response has only a message, return a reason with the message as detail": {
+			response: approver.EvaluationResponse{Result: approver.ResultDenied, Message: "this is a denied response"},
+			expReasons: []manager.DenialReason{
+				{Policy: "test-policy", Detail: "this is a denied response"},
+			},
+		},
+		"if response has errors, return a reason for each error": {
+			response: approver.EvaluationResponse{
+				Result:  approver.ResultDenied,
+				Message: "ignored",
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "allowed", "dnsNames", "values"), []string{"foo.example.com"}, "*.bar.com"),
+					field.Required(field.NewPath("spec", "allowed", "commonName", "required"), "true"),
+				},
+			},
+			expReasons: []manager.DenialReason{
+				{Policy: "test-policy", Field: "spec.allowed.dnsNames.values", Detail: `Invalid value: []string{"foo.example.com"}: *.bar.com`},
+				{Policy: "test-policy", Field: "spec.allowed.commonName.required", Detail: "Required value: true"},
+			},
+		},
+		"if coders are given, each error should be coded by the first coder which recognises it": {
+			response: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "allowed", "dnsNames", "values"), []string{"foo.example.com"}, "*.bar.com"),
+					field.Invalid(field.NewPath("spec", "constraints", "maxDuration"), "2h0m0s", "1h0m0s"),
+					field.Invalid(field.NewPath("spec", "plugins", "foo"), "bar", "baz"),
+				},
+			},
+			coders: []approver.DenialCoder{
+				fake.NewFakeEvaluator(),
+				fake.NewFakeEvaluator().WithDenialCode(func(err *field.Error) approver.DenialCode {
+					if err.Field == "spec.allowed.dnsNames.values" {
+						return approver.DenialCodeDNSNameNotAllowed
+					}
+					return ""
+				}),
+				fake.NewFakeEvaluator().WithDenialCode(func(err *field.Error) approver.DenialCode {
+					if err.Field == "spec.plugins.foo" {
+						return ""
+					}
+					return approver.DenialCodeDurationTooLong
+				}),
+			},
+			expReasons: []manager.DenialReason{
+				{Policy: "test-policy", Field: "spec.allowed.dnsNames.values", Code: approver.DenialCodeDNSNameNotAllowed, Detail: `Invalid value: []string{"foo.example.com"}: *.bar.com`},
+				{Policy: "test-policy", Field: "spec.constraints.maxDuration", Code: approver.DenialCodeDurationTooLong, Detail: `Invalid value: "2h0m0s": 1h0m0s`},
+				{Policy: "test-policy", Field: "spec.plugins.foo", Detail: `Invalid value: "bar": baz`},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expReasons, denialReasons("test-policy", test.response, test.coders))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+// maxResponseSize is the maximum number of bytes read from an OPA response.
+const maxResponseSize = 1 << 20
+
+// decisionInput is the input document sent to OPA.
+type decisionInput struct {
+	CertificateRequest *cmapi.CertificateRequest           `json:"certificateRequest"`
+	Policy             *policyapi.CertificateRequestPolicy `json:"policy"`
+}
+
+// decisionRequest is the body of the request sent to the OPA Data API.
+type decisionRequest struct {
+	Input decisionInput `json:"input"`
+}
+
+// decision is the result expected from the OPA decision endpoint.
+type decision struct {
+	// Allow is whether the request should not be denied.
+	Allow bool `json:"allow"`
+
+	// Messages are the reasons the request was denied.
+	Messages []string `json:"messages,omitempty"`
+}
+
+// decisionResponse is the body of the response from the OPA Data API. Result
+// is nil if the decision is undefined.
+type decisionResponse struct {
+	Result *decision `json:"result"`
+}
+
+// Evaluate queries the OPA endpoint configured in the opa plugin values of
+// the policy, and denies the request if the decision does not allow it. If
+// the OPA endpoint fails to return a decision, the request is denied unless
+// the plugin is configured to fail open. Policies which do not define the opa
+// plugin are not evaluated.
+func (o *opa) Evaluate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+	plugin, ok := policy.Spec.Plugins[o.Name()]
+	if !ok {
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	}
+
+	fldPath := field.NewPath("spec", "plugins", o.Name())
+
+	cfg, el := parseConfig(fldPath.Child("values"), plugin.Values)
+	if len(el) > 0 {
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+	}
+
+	result, err := o.query(ctx, cfg, policy, request)
+	if err != nil {
+		o.log.Error(err, "failed to query OPA decision", "policy", policy.Name, "failOpen", cfg.failOpen)
+		if cfg.failOpen {
+			return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+		}
+		// Don't expose the error to the requester, since it may contain details
+		// of the OPA endpoint.
+		el = append(el, field.InternalError(fldPath, errors.New("failed to query OPA decision")))
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+	}
+
+	if result.Allow {
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	}
+
+	for _, message := range result.Messages {
+		el = append(el, field.Forbidden(fldPath, message))
+	}
+	if len(el) == 0 {
+		el = append(el, field.Forbidden(fldPath, "request denied by OPA decision"))
+	}
+
+	return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+}
+
+// query sends the request and policy as input to the OPA endpoint, and
+// returns the decision. An error is returned if the endpoint could not be
+// reached, responded with a non 200 status code, or the decision was
+// undefined or malformed.
+func (o *opa) query(ctx context.Context, cfg config, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (*decision, error) {
+	body, err := json.Marshal(decisionRequest{
+		Input: decisionInput{CertificateRequest: request, Policy: policy},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OPA input: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OPA request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OPA: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected OPA response status code: %d", resp.StatusCode)
+	}
+
+	var response decisionResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode OPA response: %w", err)
+	}
+
+	if response.Result == nil {
+		return nil, errors.New("OPA decision is undefined")
+	}
+
+	return response.Result, nil
+}
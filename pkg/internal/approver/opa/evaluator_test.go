@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+func Test_Evaluate(t *testing.T) {
+	fldPath := field.NewPath("spec", "plugins", "opa")
+
+	// respond returns a handler which responds with the given status code and
+	// body, after asserting the input document sent to OPA.
+	respond := func(statusCode int, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var req decisionRequest
+			if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) {
+				return
+			}
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "test-request", req.Input.CertificateRequest.Name)
+			assert.Equal(t, "test-policy", req.Input.Policy.Name)
+			w.WriteHeader(statusCode)
+			w.Write([]byte(body))
+		}
+	}
+
+	tests := map[string]struct {
+		handler http.HandlerFunc
+		// values are the plugin values, where url is set to the test server if
+		// not defined. If nil, the opa plugin is not defined on the policy.
+		values      map[string]string
+		expResponse approver.EvaluationResponse
+	}{
+		"if the policy doesn't define the opa plugin, return NotDenied": {
+			handler:     respond(http.StatusOK, `{"result":{"allow":false}}`),
+			values:      nil,
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if the decision allows the request, return NotDenied": {
+			handler:     respond(http.StatusOK, `{"result":{"allow":true}}`),
+			values:      map[string]string{},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if the decision doesn't allow the request, return Denied with the decision messages": {
+			handler: respond(http.StatusOK, `{"result":{"allow":false,"messages":["commonName is not allowed","duration is too long"]}}`),
+			values:  map[string]string{},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(fldPath, "commonName is not allowed"),
+					field.Forbidden(fldPath, "duration is too long"),
+				},
+			},
+		},
+		"if the decision doesn't allow the request without messages, return Denied with a default message": {
+			handler: respond(http.StatusOK, `{"result":{"allow":false}}`),
+			values:  map[string]string{},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(fldPath, "request denied by OPA decision"),
+				},
+			},
+		},
+		"if the decision is undefined, return Denied": {
+			handler: respond(http.StatusOK, `{}`),
+			values:  map[string]string{},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.InternalError(fldPath, errors.New("failed to query OPA decision")),
+				},
+			},
+		},
+		"if OPA responds with an error status code, return Denied": {
+			handler: respond(http.StatusInternalServerError, `{"result":{"allow":true}}`),
+			values:  map[string]string{},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.InternalError(fldPath, errors.New("failed to query OPA decision")),
+				},
+			},
+		},
+		"if OPA responds with an error status code and failOpen is true, return NotDenied": {
+			handler:     respond(http.StatusInternalServerError, `{"result":{"allow":true}}`),
+			values:      map[string]string{"failOpen": "true"},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if OPA responds with malformed JSON, return Denied": {
+			handler: respond(http.StatusOK, `{"result":`),
+			values:  map[string]string{},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.InternalError(fldPath, errors.New("failed to query OPA decision")),
+				},
+			},
+		},
+		"if OPA doesn't respond within the timeout, return Denied": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(time.Millisecond * 200)
+			},
+			values: map[string]string{"timeout": "10ms"},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.InternalError(fldPath, errors.New("failed to query OPA decision")),
+				},
+			},
+		},
+		"if OPA doesn't respond within the timeout and failOpen is true, return NotDenied": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(time.Millisecond * 200)
+			},
+			values:      map[string]string{"timeout": "10ms", "failOpen": "true"},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if OPA can't be reached, return Denied": {
+			handler: respond(http.StatusOK, `{"result":{"allow":true}}`),
+			values:  map[string]string{"url": "http://127.0.0.1:0/v1/data/certmanager"},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.InternalError(fldPath, errors.New("failed to query OPA decision")),
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			policy := &policyapi.CertificateRequestPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+			}
+			if test.values != nil {
+				values := map[string]string{"url": server.URL + "/v1/data/certmanager"}
+				for k, v := range test.values {
+					values[k] = v
+				}
+				policy.Spec.Plugins = map[string]policyapi.CertificateRequestPolicyPluginData{
+					"opa": {Values: values},
+				}
+			}
+
+			request := gen.CertificateRequest("test-request", gen.SetCertificateRequestDuration(&metav1.Duration{Duration: time.Hour}))
+
+			response, err := Approver().Evaluate(context.TODO(), policy, request)
+			assert.NoError(t, err)
+			// The message of a denied response is the aggregate of its errors.
+			if len(test.expResponse.Errors) > 0 {
+				test.expResponse.Message = test.expResponse.Errors.ToAggregate().Error()
+			}
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opa
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/registry"
+)
+
+// Load the opa approver.
+func init() {
+	registry.Shared.Store(Approver())
+}
+
+// Approver returns an instance on the opa approver.
+func Approver() approver.Interface {
+	return &opa{log: logr.Discard(), client: new(http.Client)}
+}
+
+// opa is an approver-policy Approver plugin that defers the decision of
+// whether a request should be denied to an external Open Policy Agent (OPA)
+// endpoint. The endpoint is configured per CertificateRequestPolicy using the
+// values of the "opa" plugin. Policies which do not define the "opa" plugin
+// are ignored.
+type opa struct {
+	// log is the logger for the opa approver, set on Prepare.
+	log logr.Logger
+
+	// client is the HTTP client used to query OPA endpoints.
+	client *http.Client
+}
+
+// Name of Approver is "opa"
+func (o *opa) Name() string {
+	return "opa"
+}
+
+// RegisterFlags is a no-op, opa is configured per policy with plugin values.
+func (o *opa) RegisterFlags(_ *pflag.FlagSet) {
+	return
+}
+
+// Prepare stores the logger, which is used to log failed OPA queries.
+func (o *opa) Prepare(_ context.Context, log logr.Logger, _ manager.Manager) error {
+	o.log = log.WithName("opa")
+	return nil
+}
+
+// Ready always returns ready, plugin values are validated by the webhook and
+// the OPA endpoint is only queried at evaluation time.
+func (o *opa) Ready(_ context.Context, _ *policyapi.CertificateRequestPolicy) (approver.ReconcilerReadyResponse, error) {
+	return approver.ReconcilerReadyResponse{Ready: true}, nil
+}
+
+// opa never needs to manually enqueue policies.
+func (o *opa) EnqueueChan() <-chan string {
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opa
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+const (
+	// valueURL is the plugin value key for the URL of the OPA decision
+	// endpoint, e.g. `http://opa.opa.svc:8181/v1/data/certmanager/approve`.
+	// Required.
+	valueURL = "url"
+
+	// valueTimeout is the plugin value key for the timeout of querying the OPA
+	// endpoint, e.g. `5s`. Defaults to defaultTimeout.
+	valueTimeout = "timeout"
+
+	// valueFailOpen is the plugin value key for whether requests should not be
+	// denied if the OPA endpoint fails to return a decision. Defaults to
+	// `false`, denying requests.
+	valueFailOpen = "failOpen"
+
+	// defaultTimeout is the default timeout of querying the OPA endpoint.
+	defaultTimeout = time.Second * 5
+)
+
+// config is the parsed configuration of the opa plugin for a policy.
+type config struct {
+	url      string
+	timeout  time.Duration
+	failOpen bool
+}
+
+// Validate validates that the values of the opa plugin are valid, if the
+// plugin is defined on the CertificateRequestPolicy.
+func (o *opa) Validate(_ context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+	plugin, ok := policy.Spec.Plugins[o.Name()]
+	if !ok {
+		return approver.WebhookValidationResponse{
+			Allowed: true,
+			Errors:  nil,
+		}, nil
+	}
+
+	_, el := parseConfig(field.NewPath("spec", "plugins", o.Name(), "values"), plugin.Values)
+
+	return approver.WebhookValidationResponse{
+		Allowed: len(el) == 0,
+		Errors:  el,
+	}, nil
+}
+
+// parseConfig parses the opa plugin configuration from the given plugin
+// values.
+func parseConfig(fldPath *field.Path, values map[string]string) (config, field.ErrorList) {
+	var (
+		el  field.ErrorList
+		cfg = config{timeout: defaultTimeout}
+	)
+
+	// Sort keys so that errors are deterministic.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		switch key {
+		case valueURL:
+			u, err := url.Parse(value)
+			if err != nil {
+				el = append(el, field.Invalid(fldPath.Key(key), value, err.Error()))
+			} else if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+				el = append(el, field.Invalid(fldPath.Key(key), value, "must be an absolute http or https URL"))
+			}
+			cfg.url = value
+
+		case valueTimeout:
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				el = append(el, field.Invalid(fldPath.Key(key), value, err.Error()))
+			} else if timeout <= 0 {
+				el = append(el, field.Invalid(fldPath.Key(key), value, "must be greater than 0"))
+			}
+			cfg.timeout = timeout
+
+		case valueFailOpen:
+			failOpen, err := strconv.ParseBool(value)
+			if err != nil {
+				el = append(el, field.Invalid(fldPath.Key(key), value, err.Error()))
+			}
+			cfg.failOpen = failOpen
+
+		default:
+			el = append(el, field.NotSupported(fldPath.Key(key), key, []string{valueFailOpen, valueTimeout, valueURL}))
+		}
+	}
+
+	if _, ok := values[valueURL]; !ok {
+		el = append(el, field.Required(fldPath.Key(valueURL), "the URL of the OPA decision endpoint must be defined"))
+	}
+
+	return cfg, el
+}
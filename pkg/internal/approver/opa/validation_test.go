@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+func Test_Validate(t *testing.T) {
+	fldPath := field.NewPath("spec", "plugins", "opa", "values")
+
+	tests := map[string]struct {
+		plugins     map[string]policyapi.CertificateRequestPolicyPluginData
+		expResponse approver.WebhookValidationResponse
+	}{
+		"if policy doesn't define the opa plugin, expect a Allowed=true response": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"rego": {Values: map[string]string{"foo": "bar"}},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true, Errors: nil},
+		},
+		"if the opa plugin defines valid values, expect a Allowed=true response": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"opa": {Values: map[string]string{
+					"url":      "https://opa.opa.svc:8181/v1/data/certmanager/approve",
+					"timeout":  "2s",
+					"failOpen": "true",
+				}},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true, Errors: nil},
+		},
+		"if the opa plugin doesn't define a url, expect a Allowed=false response": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"opa": {Values: map[string]string{}},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(fldPath.Key("url"), "the URL of the OPA decision endpoint must be defined"),
+				},
+			},
+		},
+		"if the opa plugin defines invalid values, expect a Allowed=false response": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"opa": {Values: map[string]string{
+					"url":      "opa.opa.svc/v1/data",
+					"timeout":  "-1s",
+					"failOpen": "maybe",
+					"foo":      "bar",
+				}},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Key("failOpen"), "maybe", `strconv.ParseBool: parsing "maybe": invalid syntax`),
+					field.NotSupported(fldPath.Key("foo"), "foo", []string{"failOpen", "timeout", "url"}),
+					field.Invalid(fldPath.Key("timeout"), "-1s", "must be greater than 0"),
+					field.Invalid(fldPath.Key("url"), "opa.opa.svc/v1/data", "must be an absolute http or https URL"),
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{Plugins: test.plugins},
+			}
+			response, err := Approver().Validate(context.TODO(), policy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/google/cel-go/cel"
+	"k8s.io/apiserver/pkg/cel/library"
+)
+
+const (
+	// requestVariable is the name of the variable which holds the request that
+	// expressions are evaluated against.
+	requestVariable = "request"
+
+	// costLimit is the maximum cost of evaluating a single expression, the same
+	// as the per call limit of CEL validation rules of Kubernetes.
+	costLimit = 1000000
+)
+
+var (
+	envOnce sync.Once
+	envErr  error
+	celEnv  *cel.Env
+)
+
+// env returns the CEL environment which expressions are compiled with. The
+// environment is built once, with the Kubernetes CEL libraries.
+func env() (*cel.Env, error) {
+	envOnce.Do(func() {
+		options := append([]cel.EnvOption{
+			cel.Variable(requestVariable, cel.MapType(cel.StringType, cel.DynType)),
+		}, library.ExtensionLibs...)
+		celEnv, envErr = cel.NewEnv(options...)
+	})
+	return celEnv, envErr
+}
+
+// compile compiles the given expression into a program. Returns an error if
+// the expression is invalid, or can't evaluate to a bool.
+func compile(expression string) (cel.Program, error) {
+	env, err := env()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if outputType := ast.OutputType(); outputType != cel.BoolType && outputType != cel.DynType {
+		return nil, fmt.Errorf("must evaluate to a bool, not %s", outputType)
+	}
+
+	return env.Program(ast, cel.CostLimit(costLimit), cel.OptimizeRegex(library.ExtensionLibRegexOptimizations...))
+}
+
+// requestInput returns the structured representation of the request and its
+// decoded CSR which expressions are evaluated against.
+func requestInput(request *cmapi.CertificateRequest, csr *x509.CertificateRequest) map[string]interface{} {
+	ipAddresses := make([]string, 0, len(csr.IPAddresses))
+	for _, ip := range csr.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+	uris := make([]string, 0, len(csr.URIs))
+	for _, uri := range csr.URIs {
+		uris = append(uris, uri.String())
+	}
+	usages := make([]string, 0, len(request.Spec.Usages))
+	for _, usage := range request.Spec.Usages {
+		usages = append(usages, string(usage))
+	}
+
+	input := map[string]interface{}{
+		"commonName": csr.Subject.CommonName,
+		"subject": map[string]interface{}{
+			"organizations":       nonNil(csr.Subject.Organization),
+			"countries":           nonNil(csr.Subject.Country),
+			"organizationalUnits": nonNil(csr.Subject.OrganizationalUnit),
+			"localities":          nonNil(csr.Subject.Locality),
+			"provinces":           nonNil(csr.Subject.Province),
+			"streetAddresses":     nonNil(csr.Subject.StreetAddress),
+			"postalCodes":         nonNil(csr.Subject.PostalCode),
+			"serialNumber":        csr.Subject.SerialNumber,
+		},
+		"dnsNames":       nonNil(csr.DNSNames),
+		"ipAddresses":    ipAddresses,
+		"uris":           uris,
+		"emailAddresses": nonNil(csr.EmailAddresses),
+		"isCA":           request.Spec.IsCA,
+		"usages":         usages,
+		"namespace":      request.Namespace,
+		"username":       request.Spec.Username,
+		"groups":         nonNil(request.Spec.Groups),
+	}
+	if request.Spec.Duration != nil {
+		input["duration"] = request.Spec.Duration.Duration
+	}
+	return input
+}
+
+// nonNil returns the given slice, or an empty slice if it is nil.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
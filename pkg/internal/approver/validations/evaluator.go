@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"context"
+	"fmt"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// Evaluate evaluates whether the given CertificateRequest satisfies the CEL
+// expressions of all validations defined in the CertificateRequestPolicy.
+// The request is denied with the message of every validation whose expression
+// doesn't evaluate to true, or fails to evaluate.
+func (v *validations) Evaluate(_ context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+	if len(policy.Spec.Validations) == 0 {
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	}
+
+	var (
+		el      field.ErrorList
+		fldPath = field.NewPath("spec", "validations")
+	)
+
+	// A request which can't be decoded is denied rather than erroring, since
+	// re-evaluating it will never succeed.
+	csr, err := util.DecodeCSR(request.Spec.Request)
+	if err != nil {
+		el = append(el, field.Forbidden(field.NewPath("spec", "request"), err.Error()))
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+	}
+
+	activation := map[string]interface{}{requestVariable: requestInput(request, csr)}
+
+	for i, validation := range policy.Spec.Validations {
+		fldPath := fldPath.Index(i)
+
+		// Expressions are compiled when the policy is admitted, so should
+		// never fail to compile here.
+		program, err := compile(validation.Expression)
+		if err != nil {
+			el = append(el, field.Invalid(fldPath.Child("expression"), validation.Expression, err.Error()))
+			continue
+		}
+
+		out, _, err := program.Eval(activation)
+		if err != nil {
+			el = append(el, field.Invalid(fldPath.Child("expression"), validation.Expression, fmt.Sprintf("failed to evaluate: %s", err)))
+			continue
+		}
+
+		if out.Value() != true {
+			el = append(el, field.Forbidden(fldPath, validation.Message))
+		}
+	}
+
+	if len(el) > 0 {
+		return approver.EvaluationResponse{Result: approver.ResultDenied, Message: el.ToAggregate().Error(), Errors: el}, nil
+	}
+
+	return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+}
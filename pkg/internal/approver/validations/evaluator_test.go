@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+func Test_Evaluate(t *testing.T) {
+	fldPath := field.NewPath("spec", "validations")
+
+	// caValidation requires requests with a common name ending in "-ca" to be
+	// for a CA.
+	caValidation := policyapi.CertificateRequestPolicyValidation{
+		Expression: `!request.commonName.endsWith("-ca") || request.isCA`,
+		Message:    "requests for a common name ending in -ca must be for a CA",
+	}
+
+	request := func(commonName string, isCA bool, mods ...gen.CSRModifier) *cmapi.CertificateRequest {
+		csr, _, err := gen.CSR(x509.ECDSA, append(mods, gen.SetCSRCommonName(commonName))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: cmapi.CertificateRequestSpec{
+				Request:  csr,
+				IsCA:     isCA,
+				Duration: &metav1.Duration{Duration: time.Hour},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		validations []policyapi.CertificateRequestPolicyValidation
+		request     *cmapi.CertificateRequest
+		expResponse approver.EvaluationResponse
+	}{
+		"if the policy defines no validations, return NotDenied": {
+			validations: nil,
+			request:     &cmapi.CertificateRequest{Spec: cmapi.CertificateRequestSpec{Request: []byte("bad-request")}},
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if the request can't be decoded, return Denied": {
+			validations: []policyapi.CertificateRequestPolicyValidation{caValidation},
+			request:     &cmapi.CertificateRequest{Spec: cmapi.CertificateRequestSpec{Request: []byte("bad-request")}},
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(field.NewPath("spec", "request"), "error decoding certificate request PEM block"),
+				},
+				Message: "spec.request: Forbidden: error decoding certificate request PEM block",
+			},
+		},
+		"if a common name ending in -ca is requested for a CA, return NotDenied": {
+			validations: []policyapi.CertificateRequestPolicyValidation{caValidation},
+			request:     request("issuing-ca", true),
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if a common name not ending in -ca is requested for a leaf, return NotDenied": {
+			validations: []policyapi.CertificateRequestPolicyValidation{caValidation},
+			request:     request("example.com", false),
+			expResponse: approver.EvaluationResponse{Result: approver.ResultNotDenied},
+		},
+		"if a common name ending in -ca is requested for a leaf, return Denied with the message": {
+			validations: []policyapi.CertificateRequestPolicyValidation{caValidation},
+			request:     request("issuing-ca", false),
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(fldPath.Index(0), "requests for a common name ending in -ca must be for a CA"),
+				},
+				Message: "spec.validations[0]: Forbidden: requests for a common name ending in -ca must be for a CA",
+			},
+		},
+		"if expressions use the SANs, namespace and duration of the request, return Denied for each false expression": {
+			validations: []policyapi.CertificateRequestPolicyValidation{
+				{Expression: `request.dnsNames.all(name, name.endsWith("." + request.namespace + ".svc"))`, Message: "DNS names must be of the namespace's services"},
+				{Expression: `has(request.duration) && request.duration <= duration("24h")`, Message: "duration must be at most 24h"},
+				{Expression: `size(request.dnsNames) <= 1`, Message: "at most one DNS name may be requested"},
+			},
+			request: request("example", false, gen.SetCSRDNSNames("api.team-a.svc", "api.team-b.svc")),
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Forbidden(fldPath.Index(0), "DNS names must be of the namespace's services"),
+					field.Forbidden(fldPath.Index(2), "at most one DNS name may be requested"),
+				},
+				Message: "[spec.validations[0]: Forbidden: DNS names must be of the namespace's services, spec.validations[2]: Forbidden: at most one DNS name may be requested]",
+			},
+		},
+		"if an expression fails to evaluate, return Denied": {
+			validations: []policyapi.CertificateRequestPolicyValidation{
+				{Expression: `request.doesNotExist == "foo"`, Message: "unreachable"},
+			},
+			request: request("example", false),
+			expResponse: approver.EvaluationResponse{
+				Result: approver.ResultDenied,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Index(0).Child("expression"), `request.doesNotExist == "foo"`, "failed to evaluate: no such key: doesNotExist"),
+				},
+				Message: `spec.validations[0].expression: Invalid value: "request.doesNotExist == \"foo\"": failed to evaluate: no such key: doesNotExist`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{Validations: test.validations},
+			}
+
+			response, err := Approver().Evaluate(context.TODO(), policy, test.request)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
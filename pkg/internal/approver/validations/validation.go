@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+// Validate validates that the validations of the processed
+// CertificateRequestPolicy define a message, and an expression which
+// compiles to a program evaluating to a bool.
+func (v *validations) Validate(_ context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+	var (
+		el      field.ErrorList
+		fldPath = field.NewPath("spec", "validations")
+	)
+
+	for i, validation := range policy.Spec.Validations {
+		fldPath := fldPath.Index(i)
+
+		if len(validation.Expression) == 0 {
+			el = append(el, field.Required(fldPath.Child("expression"), "must define a CEL expression"))
+		} else if _, err := compile(validation.Expression); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("expression"), validation.Expression, err.Error()))
+		}
+
+		if len(validation.Message) == 0 {
+			el = append(el, field.Required(fldPath.Child("message"), "must define the message the request is denied with"))
+		}
+	}
+
+	return approver.WebhookValidationResponse{
+		Allowed: len(el) == 0,
+		Errors:  el,
+	}, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+)
+
+func Test_Validate(t *testing.T) {
+	fldPath := field.NewPath("spec", "validations")
+
+	tests := map[string]struct {
+		validations []policyapi.CertificateRequestPolicyValidation
+		expResponse approver.WebhookValidationResponse
+	}{
+		"if policy defines no validations, expect a Allowed=true response": {
+			validations: nil,
+			expResponse: approver.WebhookValidationResponse{Allowed: true, Errors: nil},
+		},
+		"if policy defines a valid cross-field validation, expect a Allowed=true response": {
+			validations: []policyapi.CertificateRequestPolicyValidation{
+				{Expression: `!request.commonName.endsWith("-ca") || request.isCA`, Message: "requests for a common name ending in -ca must be for a CA"},
+			},
+			expResponse: approver.WebhookValidationResponse{Allowed: true, Errors: nil},
+		},
+		"if policy defines a validation without an expression or message, expect a Allowed=false response": {
+			validations: []policyapi.CertificateRequestPolicyValidation{{}},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Required(fldPath.Index(0).Child("expression"), "must define a CEL expression"),
+					field.Required(fldPath.Index(0).Child("message"), "must define the message the request is denied with"),
+				},
+			},
+		},
+		"if policy defines expressions which don't compile or evaluate to a bool, expect a Allowed=false response": {
+			validations: []policyapi.CertificateRequestPolicyValidation{
+				{Expression: `request.commonName ==`, Message: "invalid syntax"},
+				{Expression: `size(request.dnsNames)`, Message: "not a bool"},
+				{Expression: `unknown.isCA`, Message: "undeclared variable"},
+			},
+			expResponse: approver.WebhookValidationResponse{
+				Allowed: false,
+				Errors: field.ErrorList{
+					field.Invalid(fldPath.Index(0).Child("expression"), `request.commonName ==`, "ERROR: <input>:1:22: Syntax error: mismatched input '<EOF>' expecting {'[', '{', '(', '.', '-', '!', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER}\n | request.commonName ==\n | .....................^"),
+					field.Invalid(fldPath.Index(1).Child("expression"), `size(request.dnsNames)`, "must evaluate to a bool, not int"),
+					field.Invalid(fldPath.Index(2).Child("expression"), `unknown.isCA`, "ERROR: <input>:1:1: undeclared reference to 'unknown' (in container '')\n | unknown.isCA\n | ^"),
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{Validations: test.validations},
+			}
+
+			response, err := Approver().(*validations).Validate(context.TODO(), policy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expResponse, response)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/registry"
+)
+
+// Load the validations approver.
+func init() {
+	registry.Shared.Store(&validations{})
+}
+
+// Approver returns an instance on the validations approver.
+func Approver() approver.Interface {
+	return &validations{}
+}
+
+// validations is a base approver-policy Approver that is responsible for
+// ensuring incoming requests satisfy the CEL expressions of the validations
+// defined on CertificateRequestPolicies. It is expected that validations must
+// _always_ be registered for all approver-policy builds.
+type validations struct{}
+
+// Name of Approver is "validations"
+func (v *validations) Name() string {
+	return "validations"
+}
+
+// RegisterFlags is a no-op, validations doesn't need any flags.
+func (v *validations) RegisterFlags(_ *pflag.FlagSet) {
+	return
+}
+
+// Prepare is a no-op, validations doesn't need to prepare.
+func (v *validations) Prepare(_ context.Context, _ logr.Logger, _ manager.Manager) error {
+	return nil
+}
+
+// Ready always returns ready, validations doesn't have any dependencies to
+// block readiness.
+func (v *validations) Ready(_ context.Context, _ *policyapi.CertificateRequestPolicy) (approver.ReconcilerReadyResponse, error) {
+	return approver.ReconcilerReadyResponse{Ready: true}, nil
+}
+
+// validations never needs to manually enqueue policies.
+func (v *validations) EnqueueChan() <-chan string {
+	return nil
+}
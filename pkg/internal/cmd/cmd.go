@@ -22,11 +22,17 @@ import (
 
 	logf "github.com/cert-manager/cert-manager/pkg/logs"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
 	"github.com/cert-manager/approver-policy/pkg/internal/cmd/options"
 	"github.com/cert-manager/approver-policy/pkg/internal/controllers"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 	"github.com/cert-manager/approver-policy/pkg/internal/webhook"
 	"github.com/cert-manager/approver-policy/pkg/registry"
 )
@@ -52,6 +58,7 @@ func NewCommand(ctx context.Context) *cobra.Command {
 
 			mgr, err := ctrl.NewManager(opts.RestConfig, ctrl.Options{
 				Scheme:                        policyapi.GlobalScheme,
+				SyncPeriod:                    &opts.CacheSyncPeriod,
 				LeaderElection:                true,
 				LeaderElectionID:              "policy.cert-manager.io",
 				LeaderElectionReleaseOnCancel: true,
@@ -64,18 +71,32 @@ func NewCommand(ctx context.Context) *cobra.Command {
 				Host:                          opts.Webhook.Host,
 				CertDir:                       opts.Webhook.CertDir,
 				Logger:                        opts.Logr.WithName("controller-manager"),
+				NewCache:                      newCache(opts.ValuesFromNamespace),
 			})
 			if err != nil {
 				return fmt.Errorf("unable to create controller manager: %w", err)
 			}
 
+			util.ConfigureValuesFromNamespace(opts.ValuesFromNamespace)
+
+			if err := metrics.ConfigurePolicyLabels(opts.MetricsLabelKeys, opts.MetricsLabelMaxValues); err != nil {
+				return fmt.Errorf("failed to configure metrics labels: %w", err)
+			}
+
 			if err := webhook.Register(ctx, webhook.Options{
-				Log:                    opts.Logr,
-				Webhooks:               registry.Shared.Webhooks(),
-				WebhookCertificatesDir: opts.Webhook.CertDir,
-				ServiceName:            opts.Webhook.ServiceName,
-				CASecretNamespace:      opts.Webhook.CASecretNamespace,
-				Manager:                mgr,
+				Log:                     opts.Logr,
+				Webhooks:                registry.Shared.Webhooks(),
+				Evaluators:              registry.Shared.Evaluators(),
+				WebhookCertificatesDir:  opts.Webhook.CertDir,
+				ServiceName:             opts.Webhook.ServiceName,
+				CASecretNamespace:       opts.Webhook.CASecretNamespace,
+				AllowedIssuerKinds:      opts.Webhook.AllowedIssuerKinds,
+				AllowedMetricsLabelKeys: opts.MetricsLabelKeys,
+				PolicyNamePattern:       opts.Webhook.PolicyNamePattern,
+				ImpactWarnings:          opts.Webhook.ImpactWarnings,
+				MaxRequestBytes:         opts.MaxRequestBytes,
+				PolicyCombineMode:       internalmanager.CombineMode(opts.PolicyCombineMode),
+				Manager:                 mgr,
 			}); err != nil {
 				return fmt.Errorf("failed to register webhook: %w", err)
 			}
@@ -94,6 +115,12 @@ func NewCommand(ctx context.Context) *cobra.Command {
 				Manager:     mgr,
 				Evaluators:  registry.Shared.Evaluators(),
 				Reconcilers: registry.Shared.Reconcilers(),
+
+				DefaultDeny:                opts.DefaultDeny,
+				DefaultDenyGracePeriod:     opts.DefaultDenyGracePeriod,
+				RevalidateApprovedRequests: opts.RevalidateApprovedRequests,
+				MaxRequestBytes:            opts.MaxRequestBytes,
+				PolicyCombineMode:          internalmanager.CombineMode(opts.PolicyCombineMode),
 			}); err != nil {
 				return fmt.Errorf("failed to add controllers: %w", err)
 			}
@@ -104,6 +131,21 @@ func NewCommand(ctx context.Context) *cobra.Command {
 	}
 
 	opts.Prepare(cmd, registry.Shared.Approvers()...)
+	cmd.AddCommand(newLintCommand(ctx))
 
 	return cmd
 }
+
+// newCache returns a cache which only watches ConfigMaps in the given
+// namespace, which are the only ConfigMaps that CertificateRequestPolicies may
+// reference. If empty, ConfigMaps in all namespaces are watched.
+func newCache(valuesFromNamespace string) cache.NewCacheFunc {
+	if len(valuesFromNamespace) == 0 {
+		return cache.New
+	}
+	return cache.BuilderWithOptions(cache.Options{
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.namespace", valuesFromNamespace)},
+		},
+	})
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	policyhub "github.com/cert-manager/approver-policy/pkg/apis/policy/v1beta1"
+	"github.com/cert-manager/approver-policy/pkg/internal/cmd/options"
+	"github.com/cert-manager/approver-policy/pkg/internal/lint"
+)
+
+const (
+	lintHelpOutput = `Reports CertificateRequestPolicies which can never decide a CertificateRequest.
+
+A policy is reported if every request it selects is also selected by another
+policy which is always evaluated instead of it, if it can't approve any
+request once its base policies are inherited, or if its base policies can't be
+resolved. Policies are read from the cluster, or from files with --filename.
+The analysis is static, and doesn't change any resource. Exits with an error if
+any policy is reported.`
+)
+
+// newLintCommand returns the lint command, which reports policies that can
+// never decide a request.
+func newLintCommand(ctx context.Context) *cobra.Command {
+	opts := new(options.LintOptions)
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Reports CertificateRequestPolicies which can never decide a CertificateRequest",
+		Long:  lintHelpOutput,
+		Args:  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Complete()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				policies []policyapi.CertificateRequestPolicy
+				err      error
+			)
+			if len(opts.Filenames) > 0 {
+				policies, err = readPolicyFiles(opts.Filenames)
+			} else {
+				policies, err = listPolicies(ctx, opts)
+			}
+			if err != nil {
+				return err
+			}
+
+			findings, err := lint.Lint(ctx, policies)
+			if err != nil {
+				return fmt.Errorf("failed to lint policies: %w", err)
+			}
+
+			if err := writeFindings(cmd.OutOrStdout(), opts.Output, findings); err != nil {
+				return err
+			}
+
+			// The findings have been written, so usage doesn't help, and the
+			// error is written once by the caller.
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			if len(findings) > 0 {
+				return fmt.Errorf("found %d problems in %d CertificateRequestPolicies", len(findings), len(policies))
+			}
+			return nil
+		},
+	}
+
+	opts.Prepare(cmd)
+
+	return cmd
+}
+
+// listPolicies lists the CertificateRequestPolicies of the cluster.
+func listPolicies(ctx context.Context, opts *options.LintOptions) ([]policyapi.CertificateRequestPolicy, error) {
+	cl, err := client.New(opts.RestConfig, client.Options{Scheme: policyapi.GlobalScheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubernetes client: %w", err)
+	}
+
+	policyList := new(policyapi.CertificateRequestPolicyList)
+	if err := cl.List(ctx, policyList); err != nil {
+		return nil, fmt.Errorf("failed to list CertificateRequestPolicies: %w", err)
+	}
+	return policyList.Items, nil
+}
+
+// readPolicyFiles reads the CertificateRequestPolicies of the YAML or JSON
+// files, which may contain many documents of policies or lists of policies.
+func readPolicyFiles(filenames []string) ([]policyapi.CertificateRequestPolicy, error) {
+	decoder := serializer.NewCodecFactory(policyapi.GlobalScheme).UniversalDeserializer()
+
+	var policies []policyapi.CertificateRequestPolicy
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %q: %w", filename, err)
+		}
+		filePolicies, err := decodePolicies(decoder, f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", filename, err)
+		}
+		policies = append(policies, filePolicies...)
+	}
+	return policies, nil
+}
+
+// decodePolicies decodes the CertificateRequestPolicies of every document of
+// the reader, converting them to v1alpha1.
+func decodePolicies(decoder runtime.Decoder, r io.Reader) ([]policyapi.CertificateRequestPolicy, error) {
+	var policies []policyapi.CertificateRequestPolicy
+
+	yamlDecoder := k8syaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw runtime.RawExtension
+		if err := yamlDecoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return policies, nil
+			}
+			return nil, err
+		}
+		if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+			continue
+		}
+
+		obj, gvk, err := decoder.Decode(raw.Raw, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		switch obj := obj.(type) {
+		case *policyapi.CertificateRequestPolicy:
+			policies = append(policies, *obj)
+		case *policyapi.CertificateRequestPolicyList:
+			policies = append(policies, obj.Items...)
+		case *policyhub.CertificateRequestPolicy:
+			policy := new(policyapi.CertificateRequestPolicy)
+			if err := policy.ConvertFrom(obj); err != nil {
+				return nil, err
+			}
+			policies = append(policies, *policy)
+		case *policyhub.CertificateRequestPolicyList:
+			for i := range obj.Items {
+				policy := new(policyapi.CertificateRequestPolicy)
+				if err := policy.ConvertFrom(&obj.Items[i]); err != nil {
+					return nil, err
+				}
+				policies = append(policies, *policy)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported object of kind %s", gvk)
+		}
+	}
+}
+
+// writeFindings writes the findings in the output format.
+func writeFindings(w io.Writer, output string, findings []lint.Finding) error {
+	if output == options.LintOutputJSON {
+		if findings == nil {
+			findings = []lint.Finding{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(findings)
+	}
+
+	for _, finding := range findings {
+		if _, err := fmt.Fprintf(w, "%s: %s: %s\n", finding.Policy, finding.Reason, finding.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
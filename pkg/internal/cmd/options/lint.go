@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	cliflag "k8s.io/component-base/cli/flag"
+)
+
+const (
+	// LintOutputText is the human readable output format of the lint command.
+	LintOutputText = "text"
+
+	// LintOutputJSON is the JSON output format of the lint command.
+	LintOutputJSON = "json"
+)
+
+// LintOptions are the options of the lint command. Populated via processing
+// command line flags.
+type LintOptions struct {
+	// kubeConfigFlags handles the Kubernetes client flags.
+	kubeConfigFlags *genericclioptions.ConfigFlags
+
+	// Filenames are the files of CertificateRequestPolicies to lint. If empty,
+	// the CertificateRequestPolicies of the cluster are linted.
+	Filenames []string
+
+	// Output is the format findings are written in.
+	Output string
+
+	// RestConfig is the shared rest config to connect to the Kubernetes API
+	// server. Only built if Filenames is empty.
+	RestConfig *rest.Config
+}
+
+func (o *LintOptions) Prepare(cmd *cobra.Command) *LintOptions {
+	var nfs cliflag.NamedFlagSets
+
+	o.addLintFlags(nfs.FlagSet("Lint"))
+	o.kubeConfigFlags = genericclioptions.NewConfigFlags(true)
+	o.kubeConfigFlags.AddFlags(nfs.FlagSet("Kubernetes"))
+
+	addNamedFlagSets(cmd, nfs)
+
+	return o
+}
+
+func (o *LintOptions) Complete() error {
+	if o.Output != LintOutputText && o.Output != LintOutputJSON {
+		return fmt.Errorf("--output must be %q or %q: %q", LintOutputText, LintOutputJSON, o.Output)
+	}
+
+	if len(o.Filenames) > 0 {
+		return nil
+	}
+
+	var err error
+	o.RestConfig, err = o.kubeConfigFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to build kubernetes rest config: %s", err)
+	}
+
+	return nil
+}
+
+func (o *LintOptions) addLintFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVarP(&o.Filenames, "filename", "f", nil,
+		"Comma-separated list of YAML or JSON files of CertificateRequestPolicies to lint. "+
+			"If empty, the CertificateRequestPolicies of the cluster are linted.")
+
+	fs.StringVarP(&o.Output, "output", "o", LintOutputText,
+		"Format that findings are written in, either 'text' or 'json'.")
+}
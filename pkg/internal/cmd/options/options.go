@@ -19,6 +19,7 @@ package options
 import (
 	"flag"
 	"fmt"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -33,7 +34,10 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 )
 
 // Options are the main options for the approver-policy. Populated via
@@ -59,6 +63,52 @@ type Options struct {
 	// which will be served on the HTTP path '/readyz'.
 	ReadyzAddress string
 
+	// DefaultDeny will cause CertificateRequests which are not matched by any
+	// CertificateRequestPolicy to be denied, once DefaultDenyGracePeriod has
+	// elapsed since the CertificateRequest was created.
+	DefaultDeny bool
+
+	// DefaultDenyGracePeriod is the duration since creation that an unmatched
+	// CertificateRequest will be left unprocessed before being denied, when
+	// DefaultDeny is enabled.
+	DefaultDenyGracePeriod time.Duration
+
+	// MaxRequestBytes is the maximum size in bytes of CertificateRequestPolicy
+	// objects admitted by the webhook, and of the `spec.request` of
+	// CertificateRequests evaluated by the approver. Larger objects are denied
+	// before being parsed. If 0, the size is not limited.
+	MaxRequestBytes int
+
+	// PolicyCombineMode is how the decisions of the highest priority
+	// CertificateRequestPolicies which select a CertificateRequest are
+	// combined, either "union" or "intersection".
+	PolicyCombineMode string
+
+	// RevalidateApprovedRequests enables the re-evaluation of
+	// CertificateRequests which have been approved but not yet issued, when
+	// triggered by annotating a CertificateRequestPolicy which selects them.
+	RevalidateApprovedRequests bool
+
+	// CacheSyncPeriod is the period at which the informer cache of watched
+	// resources, including CertificateRequestPolicies, is resynced. Bounds
+	// the staleness of policies read from the cache if watch events are
+	// missed.
+	CacheSyncPeriod time.Duration
+
+	// ValuesFromNamespace is the namespace which ConfigMaps referenced by
+	// CertificateRequestPolicies must be in. Only ConfigMaps in this namespace
+	// are watched. If empty, ConfigMaps in all namespaces are watched.
+	ValuesFromNamespace string
+
+	// MetricsLabelKeys are the keys which CertificateRequestPolicies may use
+	// in `spec.metricsLabels`, and which approval and denial metrics are
+	// labelled with.
+	MetricsLabelKeys []string
+
+	// MetricsLabelMaxValues is the maximum number of distinct values recorded
+	// in metrics for each of MetricsLabelKeys.
+	MetricsLabelMaxValues int
+
 	// RestConfig is the shared base rest config to connect to the Kubernetes
 	// API.
 	RestConfig *rest.Config
@@ -89,6 +139,19 @@ type Webhook struct {
 	// CASecretNamespace is the namespace that the
 	// cert-manager-approver-policy-tls Secret is stored.
 	CASecretNamespace string
+
+	// AllowedIssuerKinds are the issuer kinds that CertificateRequestPolicies
+	// may select on. If empty, all issuer kinds are allowed.
+	AllowedIssuerKinds []string
+
+	// PolicyNamePattern is a regular expression that the names of created
+	// CertificateRequestPolicies must match. If empty, any name is allowed.
+	PolicyNamePattern string
+
+	// ImpactWarnings marks whether created and updated
+	// CertificateRequestPolicies are evaluated against pending
+	// CertificateRequests, with the impact returned as an admission warning.
+	ImpactWarnings bool
 }
 
 func New() *Options {
@@ -112,6 +175,32 @@ func (o *Options) Complete() error {
 		return fmt.Errorf("failed to build kubernetes rest config: %s", err)
 	}
 
+	if o.MetricsLabelMaxValues <= 0 {
+		return fmt.Errorf("--metrics-label-max-values must be positive: %d", o.MetricsLabelMaxValues)
+	}
+
+	if o.DefaultDenyGracePeriod < 0 {
+		return fmt.Errorf("--default-deny-grace-period must not be negative: %s", o.DefaultDenyGracePeriod)
+	}
+
+	if o.MaxRequestBytes < 0 {
+		return fmt.Errorf("--max-request-bytes must not be negative: %d", o.MaxRequestBytes)
+	}
+
+	if o.PolicyCombineMode != string(internalmanager.CombineModeUnion) && o.PolicyCombineMode != string(internalmanager.CombineModeIntersection) {
+		return fmt.Errorf("--policy-combine-mode must be %q or %q: %q", internalmanager.CombineModeUnion, internalmanager.CombineModeIntersection, o.PolicyCombineMode)
+	}
+
+	if o.CacheSyncPeriod <= 0 {
+		return fmt.Errorf("--cache-sync-period must be positive: %s", o.CacheSyncPeriod)
+	}
+
+	if len(o.Webhook.PolicyNamePattern) > 0 {
+		if err := util.ValidateRegex(o.Webhook.PolicyNamePattern); err != nil {
+			return fmt.Errorf("--policy-name-pattern is not a valid regular expression: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -127,6 +216,12 @@ func (o *Options) addFlags(cmd *cobra.Command, approvers ...approver.Interface)
 		approver.RegisterFlags(nfs.FlagSet(approver.Name()))
 	}
 
+	addNamedFlagSets(cmd, nfs)
+}
+
+// addNamedFlagSets adds the named flag sets to the command, and prints them
+// by section in the usage and help of the command.
+func addNamedFlagSets(cmd *cobra.Command, nfs cliflag.NamedFlagSets) {
 	usageFmt := "Usage:\n  %s\n"
 	cmd.SetUsageFunc(func(cmd *cobra.Command) error {
 		fmt.Fprintf(cmd.OutOrStderr(), usageFmt, cmd.UseLine())
@@ -156,8 +251,53 @@ func (o *Options) addAppFlags(fs *pflag.FlagSet) {
 		`TCP address for exposing HTTP Prometheus metrics which will be served on the HTTP path '/metrics'. The value "0" will
 	 disable exposing metrics.`)
 
+	fs.StringSliceVar(&o.MetricsLabelKeys, "metrics-label-keys", nil,
+		"Comma-separated list of keys which CertificateRequestPolicies may use in `spec.metricsLabels`. "+
+			"Approval and denial metrics are labelled with the value of each key, as the label "+
+			"'label_<key>' with invalid characters replaced by '_'. Policies using other keys are rejected.")
+
+	fs.IntVar(&o.MetricsLabelMaxValues, "metrics-label-max-values", 100,
+		"Maximum number of distinct values recorded in metrics for each of --metrics-label-keys. "+
+			"Further values are recorded as '_other'.")
+
 	fs.StringVar(&o.ReadyzAddress, "readiness-probe-bind-address", ":6060",
 		"TCP address for exposing the HTTP readiness probe which will be served on the HTTP path '/readyz'.")
+
+	fs.BoolVar(&o.DefaultDeny, "default-deny", false,
+		"If true, CertificateRequests which are not matched by any CertificateRequestPolicy will be denied once the "+
+			"default deny grace period has elapsed since their creation.")
+
+	fs.DurationVar(&o.DefaultDenyGracePeriod, "default-deny-grace-period", time.Minute,
+		"Duration since creation that a CertificateRequest which is not matched by any CertificateRequestPolicy will be "+
+			"left unprocessed before being denied. Only used if --default-deny is true.")
+
+	fs.IntVar(&o.MaxRequestBytes, "max-request-bytes", 0,
+		"Maximum size in bytes of CertificateRequestPolicies admitted by the webhook, and of the PEM encoded "+
+			"`spec.request` of CertificateRequests. Larger objects are denied before being parsed. If 0, the size "+
+			"is not limited.")
+
+	fs.StringVar(&o.PolicyCombineMode, "policy-combine-mode", string(internalmanager.CombineModeUnion),
+		"How the decisions of the CertificateRequestPolicies with the highest priority which select a "+
+			"CertificateRequest are combined. If 'union', the request is approved if any policy approves it, unless "+
+			"an authoritative policy denies it. If 'intersection', the request is denied if any policy denies it, "+
+			"as if every policy were authoritative. Policies of a lower priority are never evaluated, and denials by "+
+			"policies with the Warn enforcement are only returned as warnings, in either mode.")
+
+	fs.BoolVar(&o.RevalidateApprovedRequests, "revalidate-approved-requests", false,
+		"If true, annotating a CertificateRequestPolicy with '"+policyapi.RevalidateAnnotationKey+"' re-evaluates the "+
+			"CertificateRequests which have been approved but not yet issued, and which the policy selects or was "+
+			"evaluated for. Requests which no longer pass policy are annotated with '"+
+			policyapi.RevalidationFailedAnnotationKey+"', since an approval may not be revoked.")
+
+	fs.DurationVar(&o.CacheSyncPeriod, "cache-sync-period", 10*time.Minute,
+		"Period at which the informer cache of CertificateRequestPolicies and other watched resources is resynced. "+
+			"Policies are evaluated from the cache, so this bounds how stale a policy may be if watch events are missed.")
+
+	fs.StringVar(&o.ValuesFromNamespace, "values-from-namespace", "cert-manager",
+		"Namespace which ConfigMaps referenced by CertificateRequestPolicies, with `valuesFrom` or "+
+			"`blockedPublicKeyHashes`, must be in. Only ConfigMaps in this namespace are watched, so approver-policy "+
+			"only needs permission to list and watch ConfigMaps in this namespace. If empty, ConfigMaps may be in "+
+			"any namespace, and ConfigMaps in all namespaces are watched.")
 }
 
 func (o *Options) addWebhookFlags(fs *pflag.FlagSet) {
@@ -182,4 +322,23 @@ func (o *Options) addWebhookFlags(fs *pflag.FlagSet) {
 		"Directory where the Webhook certificate and private key are located. "+
 			"Certificate and private key must be named 'tls.crt' and 'tls.key' "+
 			"respectively.")
+
+	fs.StringSliceVar(&o.Webhook.AllowedIssuerKinds,
+		"allowed-issuer-kinds", nil,
+		"Comma-separated list of issuer kinds that CertificateRequestPolicies may "+
+			"select on with `spec.selector.issuerRef.kind`, e.g. 'Issuer,ClusterIssuer'. "+
+			"Policies which don't select on one of these kinds are rejected. If empty, "+
+			"all issuer kinds are allowed.")
+
+	fs.StringVar(&o.Webhook.PolicyNamePattern,
+		"policy-name-pattern", "",
+		"Regular expression that the whole name of created CertificateRequestPolicies "+
+			"must match, e.g. 'team-.+'. Existing policies which don't match may still "+
+			"be updated. If empty, any name is allowed.")
+
+	fs.BoolVar(&o.Webhook.ImpactWarnings,
+		"policy-impact-warnings", false,
+		"If true, created and updated CertificateRequestPolicies are evaluated against "+
+			"up to 100 pending CertificateRequests, and the number of requests they "+
+			"would newly allow or deny is returned as an admission warning.")
 }
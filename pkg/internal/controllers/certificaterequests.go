@@ -18,33 +18,74 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	"github.com/cert-manager/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
 	"github.com/cert-manager/approver-policy/pkg/approver/manager"
 	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
 	"github.com/cert-manager/approver-policy/pkg/internal/controllers/ssa_client"
+	"github.com/cert-manager/approver-policy/pkg/internal/inherit"
+)
+
+// Backoff of requests which failed evaluation because of a transient error.
+// Requests which still fail after transientRetryMax retries are denied.
+const (
+	transientRetryInitialBackoff = 5 * time.Second
+	transientRetryMaxBackoff     = 5 * time.Minute
+	transientRetryMax            = 10
+)
+
+// indexCertificateOwner is the field index of CertificateRequests by the UID
+// of the cert-manager Certificate which owns them, used to find the previous
+// requests of a Certificate for the minimum reissue interval.
+const indexCertificateOwner = "metadata.ownerReferences.certificate"
+
+// Event reasons recorded on CertificateRequests by the certificaterequests
+// controller. These reasons are stable and may be relied upon by users to
+// filter Events.
+const (
+	eventReasonApproved         = "Approved"
+	eventReasonDenied           = "Denied"
+	eventReasonNoMatchingPolicy = "NoMatchingPolicy"
+	eventReasonEvaluationError  = "EvaluationError"
+	eventReasonEvaluationRetry  = "EvaluationRetry"
+	eventReasonUnknownResponse  = "UnknownResponse"
+	eventReasonWouldDeny        = "WouldDeny"
+	eventReasonRateLimited      = "RateLimited"
+	eventReasonReissueThrottled = "ReissueThrottled"
 )
 
 // certificaterequests is a controller-runtime Reconciler which evaluates
@@ -70,6 +111,51 @@ type certificaterequests struct {
 	// to manage all approvers which have been registered and active for this
 	// controller.
 	manager manager.Interface
+
+	// clock returns time which can be overwritten for testing.
+	clock clock.Clock
+
+	// elected is closed once this replica has been elected leader. Only the
+	// leader approves or denies CertificateRequests.
+	elected <-chan struct{}
+
+	// defaultDeny marks whether CertificateRequests which are not matched by
+	// any policy should be denied once defaultDenyGracePeriod has elapsed
+	// since their creation.
+	defaultDeny bool
+
+	// defaultDenyGracePeriod is the duration since creation that unmatched
+	// CertificateRequests are left unprocessed, before being denied when
+	// defaultDeny is enabled.
+	defaultDenyGracePeriod time.Duration
+
+	// maxRequestBytes is the maximum size in bytes of the `spec.request` of
+	// CertificateRequests. Larger requests are denied without being
+	// evaluated. If 0, the size is not limited.
+	maxRequestBytes int
+
+	// lastEventReasonsLock protects lastEventReasons.
+	lastEventReasonsLock sync.Mutex
+
+	// lastEventReasons holds the reason of the last Event recorded for each
+	// CertificateRequest that has not yet been Approved or Denied. Used to
+	// prevent recording duplicate Events when a CertificateRequest is
+	// re-reconciled with the same outcome, for example when any policy or RBAC
+	// changes in the cluster.
+	lastEventReasons map[types.NamespacedName]string
+
+	// approvals limits the rate of approvals of CertificateRequestPolicies
+	// which define a rate limit.
+	approvals rateLimiter
+
+	// approvedAnnotationsLock protects approvedAnnotations.
+	approvedAnnotationsLock sync.Mutex
+
+	// approvedAnnotations holds the review annotations of each
+	// CertificateRequest being Approved, which are only written once the
+	// request has been Approved. Kept until they have been written, so that
+	// they are written when the request is reconciled again.
+	approvedAnnotations map[types.NamespacedName]map[string]string
 }
 
 // addCertificateRequestController will register the certificaterequests
@@ -80,7 +166,17 @@ func addCertificateRequestController(ctx context.Context, opts Options) error {
 		recorder: opts.Manager.GetEventRecorderFor("policy.cert-manager.io"),
 		client:   opts.Manager.GetClient(),
 		lister:   opts.Manager.GetCache(),
-		manager:  internalmanager.New(opts.Manager.GetCache(), opts.Manager.GetClient(), opts.Evaluators),
+		manager:  internalmanager.NewIndexed(opts.Manager.GetCache(), opts.Manager.GetClient(), opts.Evaluators, opts.PolicyCombineMode),
+		clock:    clock.RealClock{},
+		elected:  opts.Manager.Elected(),
+
+		defaultDeny:            opts.DefaultDeny,
+		defaultDenyGracePeriod: opts.DefaultDenyGracePeriod,
+		maxRequestBytes:        opts.MaxRequestBytes,
+	}
+
+	if err := opts.Manager.GetFieldIndexer().IndexField(ctx, new(cmapi.CertificateRequest), indexCertificateOwner, certificateOwnerIndex); err != nil {
+		return fmt.Errorf("failed to index CertificateRequests by %s: %w", indexCertificateOwner, err)
 	}
 
 	enqueueRequestFromMapFunc := func(_ client.Object) []reconcile.Request {
@@ -117,6 +213,11 @@ func addCertificateRequestController(ctx context.Context, opts Options) error {
 				cr := obj.(*cmapi.CertificateRequest)
 				return !apiutil.CertificateRequestIsApproved(cr) && !apiutil.CertificateRequestIsDenied(cr)
 			}),
+			// Ignore updates which only record an evaluation retry, so that
+			// retries are backed off.
+			predicate.Funcs{UpdateFunc: func(e event.UpdateEvent) bool {
+				return !onlyRetriesChanged(e.ObjectOld, e.ObjectNew)
+			}},
 		)).
 
 		// Watch CertificateRequestPolicies. If a policy is created or updated,
@@ -124,6 +225,15 @@ func addCertificateRequestController(ctx context.Context, opts Options) error {
 		// approved or denied condition since they may be relevant for the policy.
 		Watches(&source.Kind{Type: new(policyapi.CertificateRequestPolicy)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc)).
 
+		// Forget the rate limit of CertificateRequestPolicies once they are
+		// deleted, so that their buckets aren't held for the lifetime of the
+		// controller.
+		Watches(&source.Kind{Type: new(policyapi.CertificateRequestPolicy)}, handler.Funcs{
+			DeleteFunc: func(e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+				c.approvals.forget(e.Object.GetName())
+			},
+		}).
+
 		// Watch Roles, RoleBindings, ClusterRoles, and ClusterRoleBindings. If
 		// RBAC changes in the cluster then CertificateRequestPolicies may become
 		// appropriate for a CertificateRequest. On RBAC events, Reconcile all
@@ -135,6 +245,10 @@ func addCertificateRequestController(ctx context.Context, opts Options) error {
 		Watches(&source.Kind{Type: new(rbacv1.ClusterRole)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc), builder.OnlyMetadata).
 		Watches(&source.Kind{Type: new(rbacv1.ClusterRoleBinding)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc), builder.OnlyMetadata).
 		Watches(&source.Kind{Type: new(corev1.Namespace)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc), builder.OnlyMetadata).
+		Watches(&source.Kind{Type: new(corev1.ServiceAccount)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc), builder.OnlyMetadata).
+		Watches(&source.Kind{Type: new(cmapi.Issuer)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc), builder.OnlyMetadata).
+		Watches(&source.Kind{Type: new(cmapi.ClusterIssuer)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc), builder.OnlyMetadata).
+		Watches(&source.Kind{Type: new(cmapi.Certificate)}, handler.EnqueueRequestsFromMapFunc(enqueueRequestFromMapFunc), builder.OnlyMetadata).
 
 		// Complete the controller builder.
 		Complete(c)
@@ -146,10 +260,20 @@ func addCertificateRequestController(ctx context.Context, opts Options) error {
 // function will call the approver manager to evaluate whether a
 // CertificateRequest should be approved, denied, or left alone.
 func (c *certificaterequests) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// The controller is only started once this replica is elected leader, but
+	// guard against a follower ever approving or denying a request, since
+	// every replica of approver-policy runs the same controllers.
+	if !c.leading() {
+		c.log.V(2).Info("not the leader, skipping certificaterequest", "namespace", req.Namespace, "name", req.Name)
+		return ctrl.Result{}, nil
+	}
+
 	result, patch, resultErr := c.reconcileStatusPatch(ctx, req)
 	if patch != nil {
 		con, patch, err := ssa_client.GenerateCertificateRequestStatusPatch(req.Name, req.Namespace, patch)
 		if err != nil {
+			c.approvals.release(req.NamespacedName)
+			c.takeApprovedAnnotations(req.NamespacedName)
 			err = fmt.Errorf("failed to generate connection patch: %w", err)
 			return ctrl.Result{}, utilerrors.NewAggregate([]error{resultErr, err})
 		}
@@ -160,41 +284,173 @@ func (c *certificaterequests) Reconcile(ctx context.Context, req ctrl.Request) (
 				Force:        pointer.Bool(true),
 			},
 		}); err != nil {
+			c.approvals.release(req.NamespacedName)
+			c.takeApprovedAnnotations(req.NamespacedName)
 			err = fmt.Errorf("failed to apply connection patch: %w", err)
 			return ctrl.Result{}, utilerrors.NewAggregate([]error{resultErr, err})
 		}
 	}
 
+	// Rate limited approvals are only kept once the request has been
+	// Approved.
+	c.approvals.commit(req.NamespacedName)
+
+	// The review annotations of an approval are only written once the request
+	// has been Approved, so that they are never present on requests which
+	// aren't. Failing to write them re-queues the request to write them again.
+	if annotations := c.takeApprovedAnnotations(req.NamespacedName); len(annotations) > 0 {
+		if err := c.annotateApproved(ctx, req.NamespacedName, annotations); err != nil {
+			c.storeApprovedAnnotations(req.NamespacedName, annotations)
+			return ctrl.Result{}, utilerrors.NewAggregate([]error{resultErr, err})
+		}
+	}
+
 	return result, resultErr
 }
 
+// leading returns whether this replica has been elected leader.
+func (c *certificaterequests) leading() bool {
+	select {
+	case <-c.elected:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *certificaterequests) reconcileStatusPatch(ctx context.Context, req ctrl.Request) (ctrl.Result, *cmapi.CertificateRequestStatus, error) {
 	log := c.log.WithValues("namespace", req.NamespacedName.Namespace, "name", req.NamespacedName.Name)
 	log.V(2).Info("syncing certificaterequest")
 
 	cr := new(cmapi.CertificateRequest)
 	if err := c.lister.Get(ctx, req.NamespacedName, cr); err != nil {
+		if apierrors.IsNotFound(err) {
+			c.forgetEvents(req.NamespacedName)
+			c.takeApprovedAnnotations(req.NamespacedName)
+		}
 		return ctrl.Result{}, nil, client.IgnoreNotFound(err)
 	}
 
+	// Approved requests are final, so are only reconciled again to write the
+	// review annotations of their approval.
+	if apiutil.CertificateRequestIsApproved(cr) {
+		return ctrl.Result{}, nil, nil
+	}
+
+	crPatch := &cmapi.CertificateRequestStatus{}
+
+	// Oversized requests are denied before being parsed by any evaluator, so
+	// that they can't be used to exhaust the approver.
+	if c.maxRequestBytes > 0 && len(cr.Spec.Request) > c.maxRequestBytes {
+		log.V(2).Info("denying oversized request", "bytes", len(cr.Spec.Request))
+		message := fmt.Sprintf("spec.request is %d bytes, which is larger than the maximum of %d bytes", len(cr.Spec.Request), c.maxRequestBytes)
+		if deniedWith(cr, message) {
+			log.V(2).Info("request is already denied for the same reason")
+			return ctrl.Result{}, nil, nil
+		}
+		c.recordEvent(cr, corev1.EventTypeWarning, eventReasonDenied, message)
+
+		c.setCertificateRequestStatusCondition(
+			&crPatch.Conditions,
+			cmapi.CertificateRequestConditionDenied,
+			cmmeta.ConditionTrue,
+			"policy.cert-manager.io",
+			message,
+		)
+
+		return ctrl.Result{}, crPatch, nil
+	}
+
 	// Query review on the approver manager.
 	response, err := c.manager.Review(ctx, cr)
+	if approver.IsTransientError(err) {
+		// Transient errors are retried with backoff rather than returned, so
+		// that the number of retries can be recorded on the request.
+		retries := evaluationRetries(cr) + 1
+		if retries > transientRetryMax {
+			// Here we don't send the error context in the denial to protect
+			// information about the approver configuration being exposed to
+			// the client.
+			message := fmt.Sprintf("approver-policy failed to review the request because of a transient error after %d retries", transientRetryMax)
+			if deniedWith(cr, message) {
+				log.V(2).Info("request is already denied for the same reason")
+				return ctrl.Result{}, nil, nil
+			}
+
+			log.Error(err, "failed to review request because of a transient error, denying as the maximum number of retries has been exceeded", "retries", transientRetryMax)
+			c.recordEvent(cr, corev1.EventTypeWarning, eventReasonEvaluationError, message)
+
+			c.setCertificateRequestStatusCondition(
+				&crPatch.Conditions,
+				cmapi.CertificateRequestConditionDenied,
+				cmmeta.ConditionTrue,
+				"policy.cert-manager.io",
+				message,
+			)
+
+			return ctrl.Result{}, crPatch, nil
+		}
+
+		log.V(2).Info("failed to review request because of a transient error, will retry", "retries", retries, "error", err.Error())
+		if err := c.annotate(ctx, cr, map[string]string{policyapi.EvaluationRetriesAnnotationKey: strconv.Itoa(retries)}); err != nil {
+			return ctrl.Result{}, nil, err
+		}
+
+		c.recordEvent(cr, corev1.EventTypeWarning, eventReasonEvaluationRetry, "approver-policy failed to review the request because of a transient error and will retry")
+		return ctrl.Result{RequeueAfter: transientRetryBackoff(retries)}, nil, nil
+	}
 	if err != nil {
 		// If an error occurs when evaluating, we fire an event on the
 		// CertificateRequest and return err to try again.
 		// Here we don't send the error context in the Kubernetes Event to protect
 		// information about the approver configuration being exposed to the
 		// client.
-		c.recorder.Eventf(cr, corev1.EventTypeWarning, "EvaluationError", "approver-policy failed to review the request and will retry")
+		c.recordEvent(cr, corev1.EventTypeWarning, eventReasonEvaluationError, "approver-policy failed to review the request and will retry")
 		return ctrl.Result{}, nil, err
 	}
 
-	crPatch := &cmapi.CertificateRequestStatus{}
+	log = log.WithValues(reviewLogValues(response)...)
 
 	switch response.Result {
 	case manager.ResultApproved:
+		// Requests approved by a policy within its minimum reissue interval
+		// of the last approved request for the same Certificate are neither
+		// approved nor denied, but retried once the interval has elapsed.
+		wait, err := c.reissueWait(ctx, response.ApprovedBy, cr)
+		if err != nil {
+			return ctrl.Result{}, nil, err
+		}
+		if wait > 0 {
+			log.V(2).Info("approval is within the minimum reissue interval, will retry", "policy", response.ApprovedBy, "retryAfter", wait)
+			c.recordEvent(cr, corev1.EventTypeWarning, eventReasonReissueThrottled, fmt.Sprintf("Approval by CertificateRequestPolicy %q is throttled by its minimum reissue interval and will be retried in %s", response.ApprovedBy, wait.Round(time.Millisecond)))
+			return ctrl.Result{RequeueAfter: wait}, nil, nil
+		}
+
+		// Requests approved by a policy whose rate limit is exhausted are
+		// neither approved nor denied, but retried once the policy has
+		// capacity.
+		wait, err = c.rateLimitWait(ctx, response.ApprovedBy, cr)
+		if err != nil {
+			return ctrl.Result{}, nil, err
+		}
+		if wait > 0 {
+			log.V(2).Info("approval is rate limited, will retry", "policy", response.ApprovedBy, "retryAfter", wait)
+			c.recordEvent(cr, corev1.EventTypeWarning, eventReasonRateLimited, fmt.Sprintf("Approval by CertificateRequestPolicy %q is throttled by its rate limit and will be retried in %s", response.ApprovedBy, wait.Round(time.Millisecond)))
+			return ctrl.Result{RequeueAfter: wait}, nil, nil
+		}
+
 		log.V(2).Info("approving request")
-		c.recorder.Event(cr, corev1.EventTypeNormal, "Approved", response.Message)
+
+		// The approving policy is recorded once the request has been
+		// Approved.
+		c.storeApprovedAnnotations(req.NamespacedName, reviewAnnotations(response))
+
+		// Policies with the Warn enforcement record why they would have denied
+		// the request.
+		if len(response.Warnings) > 0 {
+			c.recordEvent(cr, corev1.EventTypeWarning, eventReasonWouldDeny, strings.Join(response.Warnings, "; "))
+		}
+		c.recordEvent(cr, corev1.EventTypeNormal, eventReasonApproved, response.Message)
 
 		c.setCertificateRequestStatusCondition(
 			&crPatch.Conditions,
@@ -207,8 +463,28 @@ func (c *certificaterequests) reconcileStatusPatch(ctx context.Context, req ctrl
 		return ctrl.Result{}, crPatch, nil
 
 	case manager.ResultDenied:
+		if deniedWith(cr, response.Message) {
+			log.V(2).Info("request is already denied for the same reason")
+			return ctrl.Result{}, nil, nil
+		}
+
 		log.V(2).Info("denying request")
-		c.recorder.Event(cr, corev1.EventTypeWarning, "Denied", response.Message)
+
+		// Denied requests are not reconciled again, so the denial reasons must
+		// be set before the request is Denied.
+		annotations := reviewAnnotations(response)
+		if len(response.Reasons) > 0 {
+			data, err := json.Marshal(response.Reasons)
+			if err != nil {
+				return ctrl.Result{}, nil, fmt.Errorf("failed to encode denial reasons: %w", err)
+			}
+			annotations[policyapi.DenialReasonsAnnotationKey] = string(data)
+		}
+		if err := c.annotate(ctx, cr, annotations); err != nil {
+			return ctrl.Result{}, nil, err
+		}
+
+		c.recordEvent(cr, corev1.EventTypeWarning, eventReasonDenied, response.Message)
 
 		c.setCertificateRequestStatusCondition(
 			&crPatch.Conditions,
@@ -221,14 +497,45 @@ func (c *certificaterequests) reconcileStatusPatch(ctx context.Context, req ctrl
 		return ctrl.Result{}, crPatch, nil
 
 	case manager.ResultUnprocessed:
-		log.V(2).Info("request was unprocessed")
-		c.recorder.Event(cr, corev1.EventTypeNormal, "Unprocessed", "Request is not applicable for any policy so ignoring")
+		if !c.defaultDeny {
+			log.V(2).Info("request was unprocessed")
+			c.recordEvent(cr, corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "Request is not applicable for any policy so ignoring")
 
-		return ctrl.Result{}, nil, nil
+			return ctrl.Result{}, nil, nil
+		}
+
+		// Give policies the chance to be created for the request before it is
+		// denied. The request will be reconciled again when the grace period
+		// has elapsed, or sooner if any policy or RBAC changes.
+		if remaining := c.defaultDenyGracePeriod - c.clock.Since(cr.CreationTimestamp.Time); remaining > 0 {
+			log.V(2).Info("request was unprocessed, will be denied if still unprocessed after default deny grace period", "remaining", remaining)
+			c.recordEvent(cr, corev1.EventTypeNormal, eventReasonNoMatchingPolicy, fmt.Sprintf("Request is not applicable for any policy and will be denied in %s if still not applicable", remaining.Round(time.Second)))
+
+			return ctrl.Result{RequeueAfter: remaining}, nil, nil
+		}
+
+		message := fmt.Sprintf("No policy matched the request within the default deny grace period of %s: %s", c.defaultDenyGracePeriod, response.Message)
+		if deniedWith(cr, message) {
+			log.V(2).Info("request is already denied for the same reason")
+			return ctrl.Result{}, nil, nil
+		}
+
+		log.V(2).Info("denying unprocessed request as default deny grace period has elapsed")
+		c.recordEvent(cr, corev1.EventTypeWarning, eventReasonDenied, message)
+
+		c.setCertificateRequestStatusCondition(
+			&crPatch.Conditions,
+			cmapi.CertificateRequestConditionDenied,
+			cmmeta.ConditionTrue,
+			"policy.cert-manager.io",
+			message,
+		)
+
+		return ctrl.Result{}, crPatch, nil
 
 	default:
 		log.Error(errors.New(response.Message), "manager responded with an unknown result", "result", response.Result)
-		c.recorder.Event(cr, corev1.EventTypeWarning, "UnknownResponse", "Policy returned an unknown result. This is a bug. Please check the approver-policy logs and file an issue")
+		c.recordEvent(cr, corev1.EventTypeWarning, eventReasonUnknownResponse, "Policy returned an unknown result. This is a bug. Please check the approver-policy logs and file an issue")
 
 		// We can do nothing but keep retrying the review here.
 		return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil, nil
@@ -236,6 +543,323 @@ func (c *certificaterequests) reconcileStatusPatch(ctx context.Context, req ctrl
 	}
 }
 
+// deniedWith returns whether the request already has a Denied condition set
+// by approver-policy with the given message. A request which has already been
+// denied for the same reason is not patched or evented again when it is
+// reconciled, which happens if it was queued again before it was denied.
+func deniedWith(cr *cmapi.CertificateRequest, message string) bool {
+	condition := apiutil.GetCertificateRequestCondition(cr, cmapi.CertificateRequestConditionDenied)
+	return condition != nil &&
+		condition.Status == cmmeta.ConditionTrue &&
+		condition.Reason == "policy.cert-manager.io" &&
+		condition.Message == message
+}
+
+// reviewAnnotations returns the annotations recording which policies were
+// considered for the request, and which policy approved it, if any.
+func reviewAnnotations(response manager.ReviewResponse) map[string]string {
+	annotations := make(map[string]string)
+	if len(response.ApprovedBy) > 0 {
+		annotations[policyapi.ApprovedByAnnotationKey] = response.ApprovedBy
+	}
+	if len(response.EvaluatedPolicies) > 0 {
+		annotations[policyapi.EvaluatedPoliciesAnnotationKey] = strings.Join(response.EvaluatedPolicies, ",")
+	}
+	return annotations
+}
+
+// reviewLogValues returns the structured log fields describing the decision
+// of a review: the decision, the number and names of the evaluated policies,
+// and the field path of the first denial reason. Only policy names and field
+// paths are logged, never the contents of the request's CSR.
+func reviewLogValues(response manager.ReviewResponse) []interface{} {
+	var decision string
+	switch response.Result {
+	case manager.ResultApproved:
+		decision = "approved"
+	case manager.ResultDenied:
+		decision = "denied"
+	case manager.ResultUnprocessed:
+		decision = "unprocessed"
+	default:
+		decision = "unknown"
+	}
+
+	values := []interface{}{
+		"decision", decision,
+		"policyCount", len(response.EvaluatedPolicies),
+		"policies", response.EvaluatedPolicies,
+	}
+	for _, reason := range response.Reasons {
+		if len(reason.Field) > 0 {
+			values = append(values, "failedField", reason.Field)
+			break
+		}
+	}
+
+	return values
+}
+
+// rateLimitWait reserves an approval of the request from the rate limit of
+// the named policy. Returns the duration until the policy may approve a
+// request if its rate limit is exhausted, otherwise 0. The reservation must be
+// committed once the request is approved, or released if it fails to be.
+// Policies which no longer exist are not rate limited, as with any other
+// change to a policy made after it was evaluated.
+func (c *certificaterequests) rateLimitWait(ctx context.Context, policyName string, cr *cmapi.CertificateRequest) (time.Duration, error) {
+	policy := new(policyapi.CertificateRequestPolicy)
+	err := c.lister.Get(ctx, types.NamespacedName{Name: policyName}, policy)
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get approving policy %q: %w", policyName, err)
+	}
+	if policy.Spec.RateLimit == nil {
+		return 0, nil
+	}
+	return c.approvals.reserve(c.clock.Now(), client.ObjectKeyFromObject(cr), policyName, *policy.Spec.RateLimit), nil
+}
+
+// reissueWait returns the duration until the named policy may approve the
+// request, if the policy defines a minimum reissue interval and the most
+// recent approval of a request owned by the same Certificate was within that
+// interval, otherwise 0. Requests which are not owned by a Certificate,
+// and policies which no longer exist, are not throttled.
+func (c *certificaterequests) reissueWait(ctx context.Context, policyName string, cr *cmapi.CertificateRequest) (time.Duration, error) {
+	owner := certificateOwner(cr)
+	if owner == nil {
+		return 0, nil
+	}
+
+	policy := new(policyapi.CertificateRequestPolicy)
+	err := c.lister.Get(ctx, types.NamespacedName{Name: policyName}, policy)
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get approving policy %q: %w", policyName, err)
+	}
+
+	// The interval may be inherited from a base policy.
+	policy, err = inherit.Resolve(ctx, c.lister, policy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve approving policy %q: %w", policyName, err)
+	}
+	if policy.Spec.Constraints == nil || policy.Spec.Constraints.MinReissueInterval == nil {
+		return 0, nil
+	}
+
+	var requests cmapi.CertificateRequestList
+	if err := c.lister.List(ctx, &requests, client.InNamespace(cr.Namespace), client.MatchingFields{indexCertificateOwner: string(owner.UID)}); err != nil {
+		return 0, fmt.Errorf("failed to list CertificateRequests: %w", err)
+	}
+
+	var latest *metav1.Time
+	for i := range requests.Items {
+		other := &requests.Items[i]
+		if other.Name == cr.Name {
+			continue
+		}
+		approved := apiutil.GetCertificateRequestCondition(other, cmapi.CertificateRequestConditionApproved)
+		if approved == nil || approved.Status != cmmeta.ConditionTrue {
+			continue
+		}
+		// Requests may be approved long after they are created, so the
+		// interval is from when they were approved.
+		approvedAt := approved.LastTransitionTime
+		if approvedAt == nil {
+			approvedAt = &other.CreationTimestamp
+		}
+		if latest == nil || latest.Before(approvedAt) {
+			latest = approvedAt
+		}
+	}
+	if latest == nil {
+		return 0, nil
+	}
+
+	if remaining := policy.Spec.Constraints.MinReissueInterval.Duration - c.clock.Since(latest.Time); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// certificateOwnerIndex returns the index value of indexCertificateOwner of
+// the request, i.e. the UID of the Certificate which owns it.
+func certificateOwnerIndex(obj client.Object) []string {
+	cr, ok := obj.(*cmapi.CertificateRequest)
+	if !ok {
+		return nil
+	}
+	owner := certificateOwner(cr)
+	if owner == nil {
+		return nil
+	}
+	return []string{string(owner.UID)}
+}
+
+// certificateOwner returns the owner reference of the request to the
+// cert-manager Certificate it was created for, or nil if it isn't owned by a
+// Certificate.
+func certificateOwner(cr *cmapi.CertificateRequest) *metav1.OwnerReference {
+	for i, ref := range cr.OwnerReferences {
+		if ref.Kind == cmapi.CertificateKind && strings.HasPrefix(ref.APIVersion, certmanager.GroupName+"/") {
+			return &cr.OwnerReferences[i]
+		}
+	}
+	return nil
+}
+
+// evaluationRetries returns the number of times evaluation of the request has
+// been retried because of a transient error, as recorded by its annotation.
+func evaluationRetries(cr *cmapi.CertificateRequest) int {
+	retries, err := strconv.Atoi(cr.Annotations[policyapi.EvaluationRetriesAnnotationKey])
+	if err != nil || retries < 0 {
+		return 0
+	}
+	return retries
+}
+
+// transientRetryBackoff returns the duration to wait before the given retry
+// of an evaluation which failed because of a transient error. The backoff
+// doubles with each retry, up to transientRetryMaxBackoff.
+func transientRetryBackoff(retries int) time.Duration {
+	backoff := transientRetryInitialBackoff
+	for i := 1; i < retries && backoff < transientRetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > transientRetryMaxBackoff {
+		backoff = transientRetryMaxBackoff
+	}
+	return backoff
+}
+
+// onlyRetriesChanged returns whether the only change between the given
+// objects is to the evaluation retries annotation, ignoring the resource
+// version and managed fields.
+func onlyRetriesChanged(oldObj, newObj client.Object) bool {
+	if oldObj.GetAnnotations()[policyapi.EvaluationRetriesAnnotationKey] == newObj.GetAnnotations()[policyapi.EvaluationRetriesAnnotationKey] {
+		return false
+	}
+
+	oldObj, newObj = oldObj.DeepCopyObject().(client.Object), newObj.DeepCopyObject().(client.Object)
+	for _, obj := range []client.Object{oldObj, newObj} {
+		annotations := obj.GetAnnotations()
+		delete(annotations, policyapi.EvaluationRetriesAnnotationKey)
+		obj.SetAnnotations(annotations)
+		obj.SetResourceVersion("")
+		obj.SetManagedFields(nil)
+	}
+
+	return apiequality.Semantic.DeepEqual(oldObj, newObj)
+}
+
+// annotate sets the given annotations on the CertificateRequest. The
+// CertificateRequest is only patched if any of the annotations are not
+// already set to the given value, so that re-reconciling a request does not
+// cause further updates.
+func (c *certificaterequests) annotate(ctx context.Context, cr *cmapi.CertificateRequest, annotations map[string]string) error {
+	var changed bool
+	for key, value := range annotations {
+		if current, ok := cr.Annotations[key]; !ok || current != value {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	patch := client.MergeFrom(cr.DeepCopy())
+	cr = cr.DeepCopy()
+	for key, value := range annotations {
+		metav1.SetMetaDataAnnotation(&cr.ObjectMeta, key, value)
+	}
+	if err := c.client.Patch(ctx, cr, patch); err != nil {
+		return fmt.Errorf("failed to patch review annotations: %w", err)
+	}
+
+	return nil
+}
+
+// annotateApproved sets the given review annotations on the Approved
+// CertificateRequest with a merge patch, which is idempotent so may be retried.
+func (c *certificaterequests) annotateApproved(ctx context.Context, key types.NamespacedName, annotations map[string]string) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode review annotations: %w", err)
+	}
+
+	cr := &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	if err := c.client.Patch(ctx, cr, client.RawPatch(types.MergePatchType, data)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to patch review annotations: %w", err)
+	}
+
+	return nil
+}
+
+// storeApprovedAnnotations stores the review annotations to be written once
+// the CertificateRequest has been Approved.
+func (c *certificaterequests) storeApprovedAnnotations(key types.NamespacedName, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+
+	c.approvedAnnotationsLock.Lock()
+	defer c.approvedAnnotationsLock.Unlock()
+	if c.approvedAnnotations == nil {
+		c.approvedAnnotations = make(map[types.NamespacedName]map[string]string)
+	}
+	c.approvedAnnotations[key] = annotations
+}
+
+// takeApprovedAnnotations returns and forgets the stored review annotations of
+// the CertificateRequest.
+func (c *certificaterequests) takeApprovedAnnotations(key types.NamespacedName) map[string]string {
+	c.approvedAnnotationsLock.Lock()
+	defer c.approvedAnnotationsLock.Unlock()
+	annotations := c.approvedAnnotations[key]
+	delete(c.approvedAnnotations, key)
+	return annotations
+}
+
+// recordEvent records an Event on the CertificateRequest, unless the last
+// Event recorded for this CertificateRequest had the same reason. Approved and
+// Denied are final decisions, so the CertificateRequest is forgotten once
+// either has been recorded.
+func (c *certificaterequests) recordEvent(cr *cmapi.CertificateRequest, eventtype, reason, message string) {
+	key := types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}
+
+	c.lastEventReasonsLock.Lock()
+	if c.lastEventReasons == nil {
+		c.lastEventReasons = make(map[types.NamespacedName]string)
+	}
+	lastReason, ok := c.lastEventReasons[key]
+	if reason == eventReasonApproved || reason == eventReasonDenied {
+		delete(c.lastEventReasons, key)
+	} else {
+		c.lastEventReasons[key] = reason
+	}
+	c.lastEventReasonsLock.Unlock()
+
+	if ok && lastReason == reason {
+		return
+	}
+
+	c.recorder.Event(cr, eventtype, reason, message)
+}
+
+// forgetEvents removes the last recorded Event reason for the given
+// CertificateRequest.
+func (c *certificaterequests) forgetEvents(key types.NamespacedName) {
+	c.lastEventReasonsLock.Lock()
+	defer c.lastEventReasonsLock.Unlock()
+	delete(c.lastEventReasons, key)
+}
+
 // Update the status with the provided condition details & return
 // the added condition.
 // NOTE: this code is just a workaround for apiutil only accepting the certificaterequest object
@@ -19,6 +19,8 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -26,6 +28,8 @@ import (
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,12 +37,17 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2/klogr"
 	fakeclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
 	"github.com/cert-manager/approver-policy/pkg/approver/manager"
 	fakemanager "github.com/cert-manager/approver-policy/pkg/approver/manager/fake"
+	"github.com/cert-manager/approver-policy/pkg/evaluator"
 )
 
 func Test_certificaterequests_Reconcile(t *testing.T) {
@@ -52,6 +61,10 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 		fixedmetatime = &metav1.Time{Time: fixedTime}
 		fixedclock    = fakeclock.NewFakeClock(fixedTime)
 
+		unprocessedManager = fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+			return manager.ReviewResponse{Result: manager.ResultUnprocessed, Message: "No CertificateRequestPolicies exist"}, nil
+		})
+
 		baseRequest = gen.CertificateRequest(requestName,
 			gen.SetCertificateRequestTypeMeta(metav1.TypeMeta{
 				Kind:       "CertificateRequest",
@@ -68,10 +81,23 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 		existingObjects []runtime.Object
 		manager         manager.Interface
 
+		defaultDeny            bool
+		defaultDenyGracePeriod time.Duration
+		maxRequestBytes        int
+
 		expResult      ctrl.Result
 		expError       bool
 		expStatusPatch *cmapi.CertificateRequestStatus
 		expEvent       string
+		// expAnnotations are the expected annotations of the request after
+		// reconcile, if the request exists.
+		expAnnotations map[string]string
+		// expApprovedAnnotations are the expected review annotations to be
+		// written once the request has been Approved.
+		expApprovedAnnotations map[string]string
+		// expResourceVersion is the expected resource version of the request
+		// after reconcile, if not empty.
+		expResourceVersion string
 	}{
 		"if request doesn't exist, no nothing": {
 			existingObjects: nil,
@@ -90,6 +116,30 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 			expStatusPatch: nil,
 			expEvent:       "Warning EvaluationError approver-policy failed to review the request and will retry",
 		},
+		"if manager review returns a transient error, record the retry, fire event and return a backed off re-queue response": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest)},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{}, approver.NewTransientError(errors.New("this is a transient error"))
+			}),
+			expResult:      ctrl.Result{RequeueAfter: time.Second * 5},
+			expError:       false,
+			expStatusPatch: nil,
+			expEvent:       "Warning EvaluationRetry approver-policy failed to review the request because of a transient error and will retry",
+			expAnnotations: map[string]string{policyapi.EvaluationRetriesAnnotationKey: "1"},
+		},
+		"if manager review returns a wrapped transient error on a retried request, increment the retries and back off further": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest,
+				gen.AddCertificateRequestAnnotations(map[string]string{policyapi.EvaluationRetriesAnnotationKey: "2"}),
+			)},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{}, fmt.Errorf("failed to evaluate: %w", approver.NewTransientError(errors.New("this is a transient error")))
+			}),
+			expResult:      ctrl.Result{RequeueAfter: time.Second * 20},
+			expError:       false,
+			expStatusPatch: nil,
+			expEvent:       "Warning EvaluationRetry approver-policy failed to review the request because of a transient error and will retry",
+			expAnnotations: map[string]string{policyapi.EvaluationRetriesAnnotationKey: "3"},
+		},
 		"if manager review returns an empty response, fire event and return a re-queue response": {
 			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest)},
 			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
@@ -118,7 +168,7 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 			expResult:      ctrl.Result{},
 			expError:       false,
 			expStatusPatch: nil,
-			expEvent:       "Normal Unprocessed Request is not applicable for any policy so ignoring",
+			expEvent:       "Normal NoMatchingPolicy Request is not applicable for any policy so ignoring",
 		},
 		"if manager review returns denied, fire event and update request with denied": {
 			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest)},
@@ -140,6 +190,135 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 			},
 			expEvent: "Warning Denied denied due to some violation",
 		},
+		"if manager review returns denied with reasons, annotate request with reasons and update request with denied": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest)},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{
+					Result:  manager.ResultDenied,
+					Message: "denied due to some violation",
+					Reasons: []manager.DenialReason{
+						{Policy: "test-policy", Field: "spec.allowed.dnsNames.values", Code: approver.DenialCodeDNSNameNotAllowed, Detail: "Invalid value: []string{\"foo.example.com\"}: *.bar.com"},
+					},
+					EvaluatedPolicies: []string{"test-policy"},
+				}, nil
+			}),
+			expResult: ctrl.Result{},
+			expError:  false,
+			expStatusPatch: &cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{
+					cmapi.CertificateRequestCondition{
+						Type:               cmapi.CertificateRequestConditionDenied,
+						Status:             cmmeta.ConditionTrue,
+						LastTransitionTime: fixedmetatime,
+						Reason:             "policy.cert-manager.io",
+						Message:            "denied due to some violation",
+					},
+				},
+			},
+			expEvent: "Warning Denied denied due to some violation",
+			expAnnotations: map[string]string{
+				"policy.cert-manager.io/denial-reasons":     `[{"policy":"test-policy","field":"spec.allowed.dnsNames.values","code":"DNSNameNotAllowed","detail":"Invalid value: []string{\"foo.example.com\"}: *.bar.com"}]`,
+				"policy.cert-manager.io/evaluated-policies": "test-policy",
+			},
+		},
+		"if manager review returns approved by a policy, store the approving and evaluated policies to annotate once approved and update request with approved": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest)},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{
+					Result:            manager.ResultApproved,
+					Message:           "policy is happy :)",
+					ApprovedBy:        "test-policy-b",
+					EvaluatedPolicies: []string{"test-policy-a", "test-policy-b"},
+				}, nil
+			}),
+			expResult: ctrl.Result{},
+			expError:  false,
+			expStatusPatch: &cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{
+					cmapi.CertificateRequestCondition{
+						Type:               cmapi.CertificateRequestConditionApproved,
+						Status:             cmmeta.ConditionTrue,
+						LastTransitionTime: fixedmetatime,
+						Reason:             "policy.cert-manager.io",
+						Message:            "policy is happy :)",
+					},
+				},
+			},
+			expEvent: "Normal Approved policy is happy :)",
+			expApprovedAnnotations: map[string]string{
+				"policy.cert-manager.io/approved-by":        "test-policy-b",
+				"policy.cert-manager.io/evaluated-policies": "test-policy-a,test-policy-b",
+			},
+		},
+		"if manager review returns approved with warnings, fire a would deny event and update request with approved": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest)},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{
+					Result:            manager.ResultApproved,
+					Message:           "policy would not be happy",
+					ApprovedBy:        "test-policy-a",
+					Warnings:          []string{"test-policy-a would deny", "test-policy-b would deny"},
+					EvaluatedPolicies: []string{"test-policy-a", "test-policy-b"},
+				}, nil
+			}),
+			expResult: ctrl.Result{},
+			expError:  false,
+			expStatusPatch: &cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{
+					cmapi.CertificateRequestCondition{
+						Type:               cmapi.CertificateRequestConditionApproved,
+						Status:             cmmeta.ConditionTrue,
+						LastTransitionTime: fixedmetatime,
+						Reason:             "policy.cert-manager.io",
+						Message:            "policy would not be happy",
+					},
+				},
+			},
+			expEvent: "Warning WouldDeny test-policy-a would deny; test-policy-b would deny",
+			expApprovedAnnotations: map[string]string{
+				"policy.cert-manager.io/approved-by":        "test-policy-a",
+				"policy.cert-manager.io/evaluated-policies": "test-policy-a,test-policy-b",
+			},
+		},
+		"if manager review returns approved and request is already annotated, don't patch the request": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest,
+				gen.AddCertificateRequestAnnotations(map[string]string{
+					"policy.cert-manager.io/approved-by":        "test-policy-b",
+					"policy.cert-manager.io/evaluated-policies": "test-policy-a,test-policy-b",
+				}),
+			)},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{
+					Result:            manager.ResultApproved,
+					Message:           "policy is happy :)",
+					ApprovedBy:        "test-policy-b",
+					EvaluatedPolicies: []string{"test-policy-a", "test-policy-b"},
+				}, nil
+			}),
+			expResult: ctrl.Result{},
+			expError:  false,
+			expStatusPatch: &cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{
+					cmapi.CertificateRequestCondition{
+						Type:               cmapi.CertificateRequestConditionApproved,
+						Status:             cmmeta.ConditionTrue,
+						LastTransitionTime: fixedmetatime,
+						Reason:             "policy.cert-manager.io",
+						Message:            "policy is happy :)",
+					},
+				},
+			},
+			expEvent: "Normal Approved policy is happy :)",
+			expAnnotations: map[string]string{
+				"policy.cert-manager.io/approved-by":        "test-policy-b",
+				"policy.cert-manager.io/evaluated-policies": "test-policy-a,test-policy-b",
+			},
+			expApprovedAnnotations: map[string]string{
+				"policy.cert-manager.io/approved-by":        "test-policy-b",
+				"policy.cert-manager.io/evaluated-policies": "test-policy-a,test-policy-b",
+			},
+			expResourceVersion: "999",
+		},
 		"if manager review returns true, fire event and update request with approved": {
 			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest)},
 			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
@@ -159,6 +338,86 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 				},
 			},
 			expEvent: "Normal Approved policy is happy :)",
+		}, "if default deny is enabled and an unprocessed request is within the grace period, fire event and re-queue for the remaining period": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest, func(cr *cmapi.CertificateRequest) {
+				cr.CreationTimestamp = metav1.Time{Time: fixedTime.Add(-time.Minute)}
+			})},
+			manager:                unprocessedManager,
+			defaultDeny:            true,
+			defaultDenyGracePeriod: time.Minute * 5,
+			expResult:              ctrl.Result{RequeueAfter: time.Minute * 4},
+			expError:               false,
+			expStatusPatch:         nil,
+			expEvent:               "Normal NoMatchingPolicy Request is not applicable for any policy and will be denied in 4m0s if still not applicable",
+		},
+		"if default deny is enabled and an unprocessed request is past the grace period, fire event and update request with denied": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest, func(cr *cmapi.CertificateRequest) {
+				cr.CreationTimestamp = metav1.Time{Time: fixedTime.Add(-time.Minute * 10)}
+			})},
+			manager:                unprocessedManager,
+			defaultDeny:            true,
+			defaultDenyGracePeriod: time.Minute * 5,
+			expResult:              ctrl.Result{},
+			expError:               false,
+			expStatusPatch: &cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{
+					{
+						Type:               cmapi.CertificateRequestConditionDenied,
+						Status:             cmmeta.ConditionTrue,
+						LastTransitionTime: fixedmetatime,
+						Reason:             "policy.cert-manager.io",
+						Message:            "No policy matched the request within the default deny grace period of 5m0s: No CertificateRequestPolicies exist",
+					},
+				},
+			},
+			expEvent: "Warning Denied No policy matched the request within the default deny grace period of 5m0s: No CertificateRequestPolicies exist",
+		},
+		"if the request is larger than the maximum request size, fire event and update request with denied without reviewing": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest, func(cr *cmapi.CertificateRequest) {
+				cr.Spec.Request = make([]byte, 2048)
+			})},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{}, errors.New("oversized request must not be reviewed")
+			}),
+			maxRequestBytes: 1024,
+			expResult:       ctrl.Result{},
+			expError:        false,
+			expStatusPatch: &cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{
+					{
+						Type:               cmapi.CertificateRequestConditionDenied,
+						Status:             cmmeta.ConditionTrue,
+						LastTransitionTime: fixedmetatime,
+						Reason:             "policy.cert-manager.io",
+						Message:            "spec.request is 2048 bytes, which is larger than the maximum of 1024 bytes",
+					},
+				},
+			},
+			expEvent: "Warning Denied spec.request is 2048 bytes, which is larger than the maximum of 1024 bytes",
+		},
+		"if default deny is enabled and a request within the grace period is approved, update request with approved": {
+			existingObjects: []runtime.Object{gen.CertificateRequestFrom(baseRequest, func(cr *cmapi.CertificateRequest) {
+				cr.CreationTimestamp = metav1.Time{Time: fixedTime.Add(-time.Minute)}
+			})},
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{Result: manager.ResultApproved, Message: "policy is happy :)"}, nil
+			}),
+			defaultDeny:            true,
+			defaultDenyGracePeriod: time.Minute * 5,
+			expResult:              ctrl.Result{},
+			expError:               false,
+			expStatusPatch: &cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{
+					{
+						Type:               cmapi.CertificateRequestConditionApproved,
+						Status:             cmmeta.ConditionTrue,
+						LastTransitionTime: fixedmetatime,
+						Reason:             "policy.cert-manager.io",
+						Message:            "policy is happy :)",
+					},
+				},
+			},
+			expEvent: "Normal Approved policy is happy :)",
 		},
 	}
 
@@ -171,7 +430,7 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 				WithRuntimeObjects(test.existingObjects...).
 				Build()
 
-			fakerecorder := record.NewFakeRecorder(1)
+			fakerecorder := record.NewFakeRecorder(2)
 
 			c := &certificaterequests{
 				client:   fakeclient,
@@ -179,6 +438,11 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 				recorder: fakerecorder,
 				manager:  test.manager,
 				log:      klogr.New(),
+				clock:    fixedclock,
+
+				defaultDeny:            test.defaultDeny,
+				defaultDenyGracePeriod: test.defaultDenyGracePeriod,
+				maxRequestBytes:        test.maxRequestBytes,
 			}
 
 			resp, statusPatch, err := c.reconcileStatusPatch(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}})
@@ -202,6 +466,787 @@ func Test_certificaterequests_Reconcile(t *testing.T) {
 			if !apiequality.Semantic.DeepEqual(statusPatch, test.expStatusPatch) {
 				t.Errorf("unexpected Reconcile response, exp=%v got=%v", test.expStatusPatch, statusPatch)
 			}
+
+			if len(test.existingObjects) > 0 {
+				var cr cmapi.CertificateRequest
+				if err := fakeclient.Get(context.TODO(), types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}, &cr); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, test.expAnnotations, cr.Annotations)
+				if len(test.expResourceVersion) > 0 {
+					assert.Equal(t, test.expResourceVersion, cr.ResourceVersion)
+				}
+			}
+
+			assert.Equal(t, test.expApprovedAnnotations, c.takeApprovedAnnotations(types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}))
+		})
+	}
+}
+
+func Test_certificaterequests_Reconcile_leader(t *testing.T) {
+	const requestName = "test-bundle"
+
+	closed := make(chan struct{})
+	close(closed)
+
+	tests := map[string]struct {
+		elected       <-chan struct{}
+		expReviewed   bool
+		expApproved   bool
+		expAnnotation string
+		expEvent      string
+	}{
+		"if not the leader, reconcile should be a no-op": {
+			elected:     make(chan struct{}),
+			expReviewed: false,
+			expApproved: false,
+		},
+		"if the leader, the request should be approved": {
+			elected:       closed,
+			expReviewed:   true,
+			expApproved:   true,
+			expAnnotation: "test-policy",
+			expEvent:      "Normal Approved policy is happy :)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fixedclock := fakeclock.NewFakeClock(time.Date(2021, 01, 01, 01, 0, 0, 0, time.UTC))
+			apiutil.Clock = fixedclock
+
+			var reviewed bool
+			mngr := fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				reviewed = true
+				return manager.ReviewResponse{Result: manager.ResultApproved, Message: "policy is happy :)", ApprovedBy: "test-policy"}, nil
+			})
+
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(gen.CertificateRequest(requestName,
+					gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+					gen.SetCertificateRequestTypeMeta(metav1.TypeMeta{Kind: "CertificateRequest", APIVersion: "cert-manager.io/v1"}),
+				)).
+				Build()
+
+			fakerecorder := record.NewFakeRecorder(2)
+			c := &certificaterequests{
+				client:   fakeclient,
+				lister:   fakeclient,
+				recorder: fakerecorder,
+				manager:  mngr,
+				log:      klogr.New(),
+				clock:    fixedclock,
+				elected:  test.elected,
+			}
+
+			result, err := c.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}})
+			assert.NoError(t, err)
+			assert.Equal(t, ctrl.Result{}, result)
+			assert.Equal(t, test.expReviewed, reviewed)
+
+			var event string
+			select {
+			case event = <-fakerecorder.Events:
+			default:
+			}
+			assert.Equal(t, test.expEvent, event)
+
+			var cr cmapi.CertificateRequest
+			if err := fakeclient.Get(context.TODO(), types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}, &cr); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expApproved, apiutil.CertificateRequestIsApproved(&cr))
+			assert.Equal(t, test.expAnnotation, cr.Annotations[policyapi.ApprovedByAnnotationKey])
+		})
+	}
+}
+
+// statusPatchCounter counts the patches made to the status of objects.
+type statusPatchCounter struct {
+	client.Client
+	patches int
+}
+
+func (s *statusPatchCounter) Status() client.SubResourceWriter {
+	return &countingStatusWriter{SubResourceWriter: s.Client.Status(), counter: s}
+}
+
+type countingStatusWriter struct {
+	client.SubResourceWriter
+	counter *statusPatchCounter
+}
+
+func (w *countingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	w.counter.patches++
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func Test_certificaterequests_Reconcile_deniedOnce(t *testing.T) {
+	const requestName = "test-bundle"
+
+	closed := make(chan struct{})
+	close(closed)
+
+	fixedclock := fakeclock.NewFakeClock(time.Date(2021, 01, 01, 01, 0, 0, 0, time.UTC))
+	apiutil.Clock = fixedclock
+
+	var reviews int
+	mngr := fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+		reviews++
+		return manager.ReviewResponse{Result: manager.ResultDenied, Message: "policy is sad :("}, nil
+	})
+
+	fakeclient := fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithRuntimeObjects(gen.CertificateRequest(requestName,
+			gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+			gen.SetCertificateRequestTypeMeta(metav1.TypeMeta{Kind: "CertificateRequest", APIVersion: "cert-manager.io/v1"}),
+		)).
+		Build()
+	counter := &statusPatchCounter{Client: fakeclient}
+
+	fakerecorder := record.NewFakeRecorder(2)
+	c := &certificaterequests{
+		client:   counter,
+		lister:   fakeclient,
+		recorder: fakerecorder,
+		manager:  mngr,
+		log:      klogr.New(),
+		clock:    fixedclock,
+		elected:  closed,
+	}
+
+	// The request is reconciled again after it has been denied, as if it had
+	// been queued again before the denial.
+	for i := 0; i < 2; i++ {
+		result, err := c.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}})
+		assert.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+	}
+
+	assert.Equal(t, 2, reviews)
+	assert.Equal(t, 1, counter.patches, "expected the status to be patched once")
+	assert.Len(t, fakerecorder.Events, 1, "expected a single Denied event")
+
+	var cr cmapi.CertificateRequest
+	if err := fakeclient.Get(context.TODO(), types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}, &cr); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, apiutil.CertificateRequestIsDenied(&cr))
+}
+
+// failingClient fails the patches made to objects, or to the status of
+// objects, while marked to fail.
+type failingClient struct {
+	client.Client
+	failPatch, failStatusPatch bool
+}
+
+func (f *failingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if f.failPatch {
+		return errors.New("patch failed")
+	}
+	return f.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (f *failingClient) Status() client.SubResourceWriter {
+	return &failingStatusWriter{SubResourceWriter: f.Client.Status(), client: f}
+}
+
+type failingStatusWriter struct {
+	client.SubResourceWriter
+	client *failingClient
+}
+
+func (w *failingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if w.client.failStatusPatch {
+		return errors.New("status patch failed")
+	}
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func Test_certificaterequests_Reconcile_approvedAnnotations(t *testing.T) {
+	const requestName = "test-bundle"
+
+	closed := make(chan struct{})
+	close(closed)
+
+	type step struct {
+		failPatch, failStatusPatch bool
+
+		expError       bool
+		expApproved    bool
+		expAnnotations map[string]string
+	}
+
+	annotations := map[string]string{
+		policyapi.ApprovedByAnnotationKey:        "test-policy",
+		policyapi.EvaluatedPoliciesAnnotationKey: "test-policy",
+	}
+
+	tests := map[string]struct {
+		steps []step
+	}{
+		"if the request is approved, expect it to be annotated": {
+			steps: []step{
+				{expApproved: true, expAnnotations: annotations},
+			},
+		},
+		"if the status patch fails, expect the request to not be annotated until it is approved": {
+			steps: []step{
+				{failStatusPatch: true, expError: true, expApproved: false, expAnnotations: nil},
+				{expApproved: true, expAnnotations: annotations},
+			},
+		},
+		"if annotating the approved request fails, expect the request to be annotated when reconciled again": {
+			steps: []step{
+				{failPatch: true, expError: true, expApproved: true, expAnnotations: nil},
+				{expApproved: true, expAnnotations: annotations},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fixedclock := fakeclock.NewFakeClock(time.Date(2021, 01, 01, 01, 0, 0, 0, time.UTC))
+			apiutil.Clock = fixedclock
+
+			mngr := fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{
+					Result:            manager.ResultApproved,
+					Message:           "policy is happy :)",
+					ApprovedBy:        "test-policy",
+					EvaluatedPolicies: []string{"test-policy"},
+				}, nil
+			})
+
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(gen.CertificateRequest(requestName,
+					gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+					gen.SetCertificateRequestTypeMeta(metav1.TypeMeta{Kind: "CertificateRequest", APIVersion: "cert-manager.io/v1"}),
+				)).
+				Build()
+			failing := &failingClient{Client: fakeclient}
+
+			c := &certificaterequests{
+				client:   failing,
+				lister:   fakeclient,
+				recorder: record.NewFakeRecorder(10),
+				manager:  mngr,
+				log:      klogr.New(),
+				clock:    fixedclock,
+				elected:  closed,
+			}
+
+			for i, step := range test.steps {
+				failing.failPatch, failing.failStatusPatch = step.failPatch, step.failStatusPatch
+
+				_, err := c.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}})
+				assert.Equal(t, step.expError, err != nil, "step %d: unexpected error: %v", i, err)
+
+				var cr cmapi.CertificateRequest
+				if err := fakeclient.Get(context.TODO(), types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}, &cr); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, step.expApproved, apiutil.CertificateRequestIsApproved(&cr), "step %d: unexpected approval", i)
+				assert.Equal(t, step.expAnnotations, cr.Annotations, "step %d: unexpected annotations", i)
+			}
+		})
+	}
+}
+
+func Test_certificaterequests_transientEvaluationError(t *testing.T) {
+	const requestName = "test-bundle"
+
+	fixedclock := fakeclock.NewFakeClock(time.Date(2021, 01, 01, 01, 0, 0, 0, time.UTC))
+	apiutil.Clock = fixedclock
+
+	// flaky is a plugin evaluator which fails with a transient error twice,
+	// then passes.
+	var calls int
+	flaky := fake.NewFakeEvaluator().WithEvaluate(func(context.Context, *policyapi.CertificateRequestPolicy, *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+		calls++
+		if calls <= 2 {
+			return approver.EvaluationResponse{}, approver.NewTransientError(errors.New("plugin backend unavailable"))
+		}
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	})
+
+	policy := &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test-policy"}}
+	mngr := fakemanager.NewFakeManager().WithReview(func(ctx context.Context, cr *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+		response, err := evaluator.New(flaky).Review(ctx, policy, cr)
+		if err != nil {
+			return manager.ReviewResponse{}, err
+		}
+		if response.Decision == evaluator.DecisionDenied {
+			return manager.ReviewResponse{Result: manager.ResultDenied, Message: response.Message}, nil
+		}
+		return manager.ReviewResponse{Result: manager.ResultApproved, Message: "Approved by CertificateRequestPolicy: \"test-policy\"", ApprovedBy: policy.Name}, nil
+	})
+
+	fakeclient := fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithRuntimeObjects(gen.CertificateRequest(requestName, gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace))).
+		Build()
+
+	c := &certificaterequests{
+		client:   fakeclient,
+		lister:   fakeclient,
+		recorder: record.NewFakeRecorder(3),
+		manager:  mngr,
+		log:      klogr.New(),
+		clock:    fixedclock,
+	}
+
+	steps := []struct {
+		expResult  ctrl.Result
+		expRetries string
+		expDecided bool
+	}{
+		{expResult: ctrl.Result{RequeueAfter: time.Second * 5}, expRetries: "1"},
+		{expResult: ctrl.Result{RequeueAfter: time.Second * 10}, expRetries: "2"},
+		{expResult: ctrl.Result{}, expRetries: "2", expDecided: true},
+	}
+
+	for i, step := range steps {
+		result, statusPatch, err := c.reconcileStatusPatch(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}})
+		if err != nil {
+			t.Fatalf("reconcile %d: unexpected error: %s", i, err)
+		}
+		assert.Equal(t, step.expResult, result, "reconcile %d", i)
+
+		var cr cmapi.CertificateRequest
+		if err := fakeclient.Get(context.TODO(), types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}, &cr); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, step.expRetries, cr.Annotations[policyapi.EvaluationRetriesAnnotationKey], "reconcile %d", i)
+
+		if step.expDecided {
+			if statusPatch == nil || len(statusPatch.Conditions) != 1 || statusPatch.Conditions[0].Type != cmapi.CertificateRequestConditionApproved {
+				t.Errorf("reconcile %d: expected request to be approved, got status patch %v", i, statusPatch)
+			}
+		} else if statusPatch != nil {
+			t.Errorf("reconcile %d: expected request to not be decided, got status patch %v", i, statusPatch)
+		}
+	}
+}
+
+func Test_certificaterequests_transientEvaluationErrorMaxRetries(t *testing.T) {
+	const requestName = "test-bundle"
+
+	fixedclock := fakeclock.NewFakeClock(time.Date(2021, 01, 01, 01, 0, 0, 0, time.UTC))
+	apiutil.Clock = fixedclock
+
+	mngr := fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+		return manager.ReviewResponse{}, approver.NewTransientError(errors.New("plugin backend unavailable"))
+	})
+
+	// The request has already been retried one fewer times than the maximum.
+	fakeclient := fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithRuntimeObjects(gen.CertificateRequest(requestName,
+			gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+			gen.AddCertificateRequestAnnotations(map[string]string{policyapi.EvaluationRetriesAnnotationKey: strconv.Itoa(transientRetryMax - 1)}),
+		)).
+		Build()
+
+	fakerecorder := record.NewFakeRecorder(3)
+	c := &certificaterequests{
+		client:   fakeclient,
+		lister:   fakeclient,
+		recorder: fakerecorder,
+		manager:  mngr,
+		log:      klogr.New(),
+		clock:    fixedclock,
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: requestName}}
+
+	// The last retry is still backed off.
+	result, statusPatch, err := c.reconcileStatusPatch(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: transientRetryBackoff(transientRetryMax)}, result)
+	assert.Nil(t, statusPatch, "expected request to not be decided")
+	assert.Equal(t, "Warning EvaluationRetry approver-policy failed to review the request because of a transient error and will retry", <-fakerecorder.Events)
+
+	// Once the retries are exhausted, the request is denied.
+	result, statusPatch, err = c.reconcileStatusPatch(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	if statusPatch == nil || len(statusPatch.Conditions) != 1 || statusPatch.Conditions[0].Type != cmapi.CertificateRequestConditionDenied {
+		t.Fatalf("expected request to be denied, got status patch %v", statusPatch)
+	}
+	assert.Equal(t, "approver-policy failed to review the request because of a transient error after 10 retries", statusPatch.Conditions[0].Message)
+	assert.Equal(t, "Warning EvaluationError approver-policy failed to review the request because of a transient error after 10 retries", <-fakerecorder.Events)
+}
+
+func Test_certificaterequests_rateLimit(t *testing.T) {
+	fixedclock := fakeclock.NewFakeClock(time.Date(2021, 01, 01, 01, 0, 0, 0, time.UTC))
+	apiutil.Clock = fixedclock
+
+	policy := &policyapi.CertificateRequestPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+		Spec: policyapi.CertificateRequestPolicySpec{
+			RateLimit: &policyapi.CertificateRequestPolicyRateLimit{Requests: 2, Window: metav1.Duration{Duration: time.Minute}},
+		},
+	}
+	mngr := fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+		return manager.ReviewResponse{Result: manager.ResultApproved, Message: "Approved by CertificateRequestPolicy: \"test-policy\"", ApprovedBy: policy.Name}, nil
+	})
+
+	var requests []runtime.Object
+	for _, name := range []string{"request-1", "request-2", "request-3"} {
+		requests = append(requests, gen.CertificateRequest(name, gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace)))
+	}
+
+	fakeclient := fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithRuntimeObjects(append(requests, policy)...).
+		Build()
+
+	fakerecorder := record.NewFakeRecorder(10)
+	c := &certificaterequests{
+		client:   fakeclient,
+		lister:   fakeclient,
+		recorder: fakerecorder,
+		manager:  mngr,
+		log:      klogr.New(),
+		clock:    fixedclock,
+	}
+
+	steps := []struct {
+		// step is the duration to step the clock by before reconciling.
+		step       time.Duration
+		request    string
+		expResult  ctrl.Result
+		expEvent   string
+		expDecided bool
+	}{
+		{request: "request-1", expDecided: true, expEvent: "Normal Approved Approved by CertificateRequestPolicy: \"test-policy\""},
+		{request: "request-2", expDecided: true, expEvent: "Normal Approved Approved by CertificateRequestPolicy: \"test-policy\""},
+		{
+			request:   "request-3",
+			expResult: ctrl.Result{RequeueAfter: time.Second * 30},
+			expEvent:  "Warning RateLimited Approval by CertificateRequestPolicy \"test-policy\" is throttled by its rate limit and will be retried in 30s",
+		},
+		{
+			step:      time.Second * 10,
+			request:   "request-3",
+			expResult: ctrl.Result{RequeueAfter: time.Second * 20},
+		},
+		{step: time.Second * 20, request: "request-3", expDecided: true, expEvent: "Normal Approved Approved by CertificateRequestPolicy: \"test-policy\""},
+	}
+
+	for i, step := range steps {
+		fixedclock.Step(step.step)
+
+		result, statusPatch, err := c.reconcileStatusPatch(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: step.request}})
+		if err != nil {
+			t.Fatalf("reconcile %d: unexpected error: %s", i, err)
+		}
+		assert.Equal(t, step.expResult, result, "reconcile %d", i)
+
+		var event string
+		select {
+		case event = <-fakerecorder.Events:
+		default:
+		}
+		assert.Equal(t, step.expEvent, event, "reconcile %d", i)
+
+		if step.expDecided {
+			if statusPatch == nil || len(statusPatch.Conditions) != 1 || statusPatch.Conditions[0].Type != cmapi.CertificateRequestConditionApproved {
+				t.Errorf("reconcile %d: expected request to be approved, got status patch %v", i, statusPatch)
+			}
+		} else if statusPatch != nil {
+			t.Errorf("reconcile %d: expected request to not be decided, got status patch %v", i, statusPatch)
+		}
+	}
+}
+
+func Test_certificaterequests_minReissueInterval(t *testing.T) {
+	fixedclock := fakeclock.NewFakeClock(time.Date(2021, 01, 01, 01, 0, 0, 0, time.UTC))
+	apiutil.Clock = fixedclock
+
+	policy := &policyapi.CertificateRequestPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+		Spec: policyapi.CertificateRequestPolicySpec{
+			Constraints: &policyapi.CertificateRequestPolicyConstraints{
+				MinReissueInterval: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+	mngr := fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+		return manager.ReviewResponse{Result: manager.ResultApproved, Message: "Approved by CertificateRequestPolicy: \"test-policy\"", ApprovedBy: policy.Name}, nil
+	})
+
+	ownedBy := func(uid types.UID) gen.CertificateRequestModifier {
+		return func(cr *cmapi.CertificateRequest) {
+			cr.OwnerReferences = []metav1.OwnerReference{
+				{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Name: string(uid), UID: uid, Controller: pointer.Bool(true)},
+			}
+		}
+	}
+	createdAgo := func(ago time.Duration) gen.CertificateRequestModifier {
+		return func(cr *cmapi.CertificateRequest) {
+			cr.CreationTimestamp = metav1.NewTime(fixedclock.Now().Add(-ago))
+		}
+	}
+
+	approvedAgo := func(ago time.Duration) gen.CertificateRequestModifier {
+		transition := metav1.NewTime(fixedclock.Now().Add(-ago))
+		return gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+			Type:               cmapi.CertificateRequestConditionApproved,
+			Status:             cmmeta.ConditionTrue,
+			LastTransitionTime: &transition,
+		})
+	}
+
+	fakeclient := fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithIndex(new(cmapi.CertificateRequest), indexCertificateOwner, certificateOwnerIndex).
+		WithRuntimeObjects(
+			policy,
+			// cert-a-1 was created long before it was approved, so the
+			// interval is from its approval.
+			gen.CertificateRequest("cert-a-1",
+				gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+				ownedBy("cert-a"), createdAgo(time.Hour), approvedAgo(time.Second*10),
+			),
+			gen.CertificateRequest("cert-a-2",
+				gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+				ownedBy("cert-a"), createdAgo(0),
+			),
+			gen.CertificateRequest("cert-b-1",
+				gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+				ownedBy("cert-b"), createdAgo(0),
+			),
+			gen.CertificateRequest("unowned",
+				gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+				createdAgo(0),
+			),
+		).
+		Build()
+
+	fakerecorder := record.NewFakeRecorder(10)
+	c := &certificaterequests{
+		client:   fakeclient,
+		lister:   fakeclient,
+		recorder: fakerecorder,
+		manager:  mngr,
+		log:      klogr.New(),
+		clock:    fixedclock,
+	}
+
+	steps := []struct {
+		// step is the duration to step the clock by before reconciling.
+		step       time.Duration
+		request    string
+		expResult  ctrl.Result
+		expEvent   string
+		expDecided bool
+	}{
+		{
+			request:   "cert-a-2",
+			expResult: ctrl.Result{RequeueAfter: time.Second * 50},
+			expEvent:  "Warning ReissueThrottled Approval by CertificateRequestPolicy \"test-policy\" is throttled by its minimum reissue interval and will be retried in 50s",
+		},
+		{request: "cert-b-1", expDecided: true, expEvent: "Normal Approved Approved by CertificateRequestPolicy: \"test-policy\""},
+		{request: "unowned", expDecided: true, expEvent: "Normal Approved Approved by CertificateRequestPolicy: \"test-policy\""},
+		{
+			step:      time.Second * 20,
+			request:   "cert-a-2",
+			expResult: ctrl.Result{RequeueAfter: time.Second * 30},
+		},
+		{step: time.Second * 30, request: "cert-a-2", expDecided: true, expEvent: "Normal Approved Approved by CertificateRequestPolicy: \"test-policy\""},
+	}
+
+	for i, step := range steps {
+		fixedclock.Step(step.step)
+
+		result, statusPatch, err := c.reconcileStatusPatch(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: gen.DefaultTestNamespace, Name: step.request}})
+		if err != nil {
+			t.Fatalf("reconcile %d: unexpected error: %s", i, err)
+		}
+		assert.Equal(t, step.expResult, result, "reconcile %d", i)
+
+		var event string
+		select {
+		case event = <-fakerecorder.Events:
+		default:
+		}
+		assert.Equal(t, step.expEvent, event, "reconcile %d", i)
+
+		if step.expDecided {
+			if statusPatch == nil || len(statusPatch.Conditions) != 1 || statusPatch.Conditions[0].Type != cmapi.CertificateRequestConditionApproved {
+				t.Errorf("reconcile %d: expected request to be approved, got status patch %v", i, statusPatch)
+			}
+		} else if statusPatch != nil {
+			t.Errorf("reconcile %d: expected request to not be decided, got status patch %v", i, statusPatch)
+		}
+	}
+}
+
+func Test_transientRetryBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  time.Second * 5,
+		2:  time.Second * 10,
+		3:  time.Second * 20,
+		6:  time.Second * 160,
+		7:  time.Minute * 5,
+		50: time.Minute * 5,
+	}
+
+	for retries, exp := range tests {
+		assert.Equal(t, exp, transientRetryBackoff(retries), "retries %d", retries)
+	}
+}
+
+func Test_reviewLogValues(t *testing.T) {
+	tests := map[string]struct {
+		response  manager.ReviewResponse
+		expValues []interface{}
+	}{
+		"approved request should log the decision and evaluated policies": {
+			response: manager.ReviewResponse{
+				Result:            manager.ResultApproved,
+				ApprovedBy:        "policy-b",
+				EvaluatedPolicies: []string{"policy-a", "policy-b"},
+			},
+			expValues: []interface{}{"decision", "approved", "policyCount", 2, "policies", []string{"policy-a", "policy-b"}},
+		},
+		"denied request should log the first denial reason with a field": {
+			response: manager.ReviewResponse{
+				Result: manager.ResultDenied,
+				Reasons: []manager.DenialReason{
+					{Policy: "policy-a", Detail: "denied by plugin"},
+					{Policy: "policy-a", Field: "spec.allowed.dnsNames.values", Detail: "example.com"},
+					{Policy: "policy-b", Field: "spec.allowed.uris.values", Detail: "spiffe://example.com"},
+				},
+				EvaluatedPolicies: []string{"policy-a", "policy-b"},
+			},
+			expValues: []interface{}{"decision", "denied", "policyCount", 2, "policies", []string{"policy-a", "policy-b"}, "failedField", "spec.allowed.dnsNames.values"},
+		},
+		"unprocessed request should log no policies": {
+			response:  manager.ReviewResponse{Result: manager.ResultUnprocessed},
+			expValues: []interface{}{"decision", "unprocessed", "policyCount", 0, "policies", []string(nil)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expValues, reviewLogValues(test.response))
+		})
+	}
+}
+
+func Test_onlyRetriesChanged(t *testing.T) {
+	withAnnotations := func(annotations map[string]string) *cmapi.CertificateRequest {
+		return gen.CertificateRequest("test-bundle",
+			gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+			gen.AddCertificateRequestAnnotations(annotations),
+		)
+	}
+
+	tests := map[string]struct {
+		oldObj, newObj *cmapi.CertificateRequest
+		exp            bool
+	}{
+		"no change should return false": {
+			oldObj: withAnnotations(map[string]string{"foo": "bar"}),
+			newObj: withAnnotations(map[string]string{"foo": "bar"}),
+			exp:    false,
+		},
+		"only retries added should return true": {
+			oldObj: withAnnotations(map[string]string{"foo": "bar"}),
+			newObj: withAnnotations(map[string]string{"foo": "bar", policyapi.EvaluationRetriesAnnotationKey: "1"}),
+			exp:    true,
+		},
+		"only retries incremented should return true": {
+			oldObj: withAnnotations(map[string]string{policyapi.EvaluationRetriesAnnotationKey: "1"}),
+			newObj: withAnnotations(map[string]string{policyapi.EvaluationRetriesAnnotationKey: "2"}),
+			exp:    true,
+		},
+		"retries and another annotation changed should return false": {
+			oldObj: withAnnotations(map[string]string{"foo": "bar"}),
+			newObj: withAnnotations(map[string]string{"foo": "baz", policyapi.EvaluationRetriesAnnotationKey: "1"}),
+			exp:    false,
+		},
+		"another annotation changed should return false": {
+			oldObj: withAnnotations(map[string]string{"foo": "bar"}),
+			newObj: withAnnotations(map[string]string{"foo": "baz"}),
+			exp:    false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			oldObj := test.oldObj.DeepCopy()
+			assert.Equal(t, test.exp, onlyRetriesChanged(test.oldObj, test.newObj))
+			assert.Equal(t, oldObj, test.oldObj, "expected old object to not be mutated")
+		})
+	}
+}
+
+func Test_certificaterequests_recordEvent(t *testing.T) {
+	cr := gen.CertificateRequest("test-bundle", gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace))
+
+	type event struct {
+		eventtype, reason, message string
+	}
+
+	tests := map[string]struct {
+		events    []event
+		expEvents []string
+	}{
+		"a single event should be recorded": {
+			events: []event{
+				{corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "no match"},
+			},
+			expEvents: []string{"Normal NoMatchingPolicy no match"},
+		},
+		"repeated events with the same reason should only be recorded once": {
+			events: []event{
+				{corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "no match"},
+				{corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "no match"},
+				{corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "no match"},
+			},
+			expEvents: []string{"Normal NoMatchingPolicy no match"},
+		},
+		"events with a different reason should be recorded": {
+			events: []event{
+				{corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "no match"},
+				{corev1.EventTypeWarning, eventReasonEvaluationError, "error"},
+				{corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "no match"},
+			},
+			expEvents: []string{"Normal NoMatchingPolicy no match", "Warning EvaluationError error", "Normal NoMatchingPolicy no match"},
+		},
+		"a decision should always be recorded": {
+			events: []event{
+				{corev1.EventTypeNormal, eventReasonNoMatchingPolicy, "no match"},
+				{corev1.EventTypeNormal, eventReasonApproved, "approved"},
+				{corev1.EventTypeNormal, eventReasonApproved, "approved"},
+			},
+			expEvents: []string{"Normal NoMatchingPolicy no match", "Normal Approved approved", "Normal Approved approved"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakerecorder := record.NewFakeRecorder(len(test.events))
+			c := &certificaterequests{recorder: fakerecorder}
+
+			for _, e := range test.events {
+				c.recordEvent(cr, e.eventtype, e.reason, e.message)
+			}
+			close(fakerecorder.Events)
+
+			var events []string
+			for event := range fakerecorder.Events {
+				events = append(events, event)
+			}
+			assert.Equal(t, test.expEvents, events)
 		})
 	}
 }
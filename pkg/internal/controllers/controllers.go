@@ -19,11 +19,14 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
 )
 
 // Options hold options for the internal approver-policy controllers.
@@ -42,10 +45,53 @@ type Options struct {
 	// Reconcilers is the list of registered Approver Reconcilers that  will be
 	// used to manager CertificateRequestPolicy Ready conditions.
 	Reconcilers []approver.Reconciler
+
+	// DefaultDeny will cause CertificateRequests which are not matched by any
+	// CertificateRequestPolicy to be denied, once DefaultDenyGracePeriod has
+	// elapsed since the CertificateRequest was created.
+	DefaultDeny bool
+
+	// DefaultDenyGracePeriod is the duration since creation that an unmatched
+	// CertificateRequest will be left unprocessed before being denied, when
+	// DefaultDeny is enabled.
+	DefaultDenyGracePeriod time.Duration
+
+	// RevalidateApprovedRequests enables the revalidation controller, which
+	// re-evaluates CertificateRequests that have been approved but not yet
+	// issued when a CertificateRequestPolicy is annotated with
+	// RevalidateAnnotationKey.
+	RevalidateApprovedRequests bool
+
+	// MaxRequestBytes is the maximum size in bytes of the `spec.request` of
+	// CertificateRequests. Larger requests are denied without being evaluated.
+	// If 0, the size is not limited.
+	MaxRequestBytes int
+
+	// PolicyCombineMode is how the decisions of the highest priority
+	// CertificateRequestPolicies which select a CertificateRequest are
+	// combined.
+	PolicyCombineMode internalmanager.CombineMode
 }
 
 // AddControllers adds all internal controllers.
 func AddControllers(ctx context.Context, opts Options) error {
+	// Record whether this replica is the leader. Runnables which don't opt
+	// out of leader election are only started once elected.
+	if err := opts.Manager.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		metrics.SetLeader(true)
+		<-ctx.Done()
+		metrics.SetLeader(false)
+		return nil
+	})); err != nil {
+		return fmt.Errorf("failed to add leader metric: %w", err)
+	}
+
+	// Index policies so that only the candidate policies of a request are
+	// listed for review.
+	if err := internalmanager.RegisterIndexes(ctx, opts.Manager.GetFieldIndexer()); err != nil {
+		return err
+	}
+
 	if err := addCertificateRequestController(ctx, opts); err != nil {
 		return fmt.Errorf("failed to add certificaterequest controller: %w", err)
 	}
@@ -54,5 +100,11 @@ func AddControllers(ctx context.Context, opts Options) error {
 		return fmt.Errorf("failed to add certificaterequestpolicy controller: %w", err)
 	}
 
+	if opts.RevalidateApprovedRequests {
+		if err := addRevalidationController(ctx, opts); err != nil {
+			return fmt.Errorf("failed to add revalidation controller: %w", err)
+		}
+	}
+
 	return nil
 }
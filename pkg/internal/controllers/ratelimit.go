@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// rateLimiter limits the rate of approvals of each CertificateRequestPolicy
+// with a token bucket per policy, keyed by policy name. Buckets are held in
+// memory until the policy is deleted, so survive reconciles but are reset on
+// restart. The zero value is ready to use.
+type rateLimiter struct {
+	// lock protects buckets and reservations.
+	lock sync.Mutex
+
+	// buckets holds the token bucket of each rate limited policy.
+	buckets map[string]*tokenBucket
+
+	// reservations holds the tokens reserved for requests whose approval has
+	// not yet been committed or released, keyed by request.
+	reservations map[types.NamespacedName]reservation
+}
+
+// reservation is a token taken from the bucket of a policy for the approval
+// of a request.
+type reservation struct {
+	// policyName is the name of the policy whose bucket the token was taken
+	// from.
+	policyName string
+
+	// perToken is the time taken to refill the token at the time it was
+	// taken.
+	perToken time.Duration
+}
+
+// tokenBucket is the token bucket of a policy, tracked as the time at which
+// the bucket will have been refilled to full. Tracking time rather than a
+// count of tokens keeps the bucket exact without floating point arithmetic.
+type tokenBucket struct {
+	full time.Time
+}
+
+// reserve takes a token from the bucket of the named policy, which is limited
+// by the given rate limit, at the given time. Returns 0 if a token was taken
+// and the policy may approve the request, otherwise the duration until a
+// token is available. The token is held for the request until either commit
+// or release is called for it. A request which already holds a reservation
+// has it released first.
+// Buckets start full. If the limit of a policy changes, the tokens already
+// taken are refilled at the rate of the new limit.
+func (r *rateLimiter) reserve(now time.Time, request types.NamespacedName, policyName string, limit policyapi.CertificateRequestPolicyRateLimit) time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.releaseLocked(request)
+
+	wait, perToken := r.take(now, policyName, limit)
+	if wait == 0 && perToken > 0 {
+		if r.reservations == nil {
+			r.reservations = make(map[types.NamespacedName]reservation)
+		}
+		r.reservations[request] = reservation{policyName: policyName, perToken: perToken}
+	}
+	return wait
+}
+
+// commit keeps the token reserved for the request, once the request has been
+// approved.
+func (r *rateLimiter) commit(request types.NamespacedName) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.reservations, request)
+}
+
+// release returns the token reserved for the request to the bucket of its
+// policy, since the request failed to be approved.
+func (r *rateLimiter) release(request types.NamespacedName) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.releaseLocked(request)
+}
+
+// forget removes the bucket of the named policy, once it has been deleted.
+func (r *rateLimiter) forget(policyName string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.buckets, policyName)
+	for request, reserved := range r.reservations {
+		if reserved.policyName == policyName {
+			delete(r.reservations, request)
+		}
+	}
+}
+
+// take takes a token for reserve, returning the duration until a token is
+// available, or the time taken to refill the taken token. Must be called with
+// the lock held.
+func (r *rateLimiter) take(now time.Time, policyName string, limit policyapi.CertificateRequestPolicyRateLimit) (time.Duration, time.Duration) {
+	// Limits which would never permit an approval are rejected by the webhook,
+	// so treat them as no limit.
+	if limit.Requests < 1 || limit.Window.Duration <= 0 {
+		return 0, 0
+	}
+
+	// perToken is the time taken to refill a single token.
+	perToken := limit.Window.Duration / time.Duration(limit.Requests)
+	if perToken <= 0 {
+		return 0, 0
+	}
+
+	if r.buckets == nil {
+		r.buckets = make(map[string]*tokenBucket)
+	}
+	bucket, ok := r.buckets[policyName]
+	if !ok {
+		bucket = new(tokenBucket)
+		r.buckets[policyName] = bucket
+	}
+	if bucket.full.Before(now) {
+		bucket.full = now
+	}
+
+	// burst is how far ahead of now the bucket may be refilled, while still
+	// holding a token.
+	burst := perToken * time.Duration(limit.Requests-1)
+	if wait := bucket.full.Sub(now) - burst; wait > 0 {
+		return wait, 0
+	}
+
+	bucket.full = bucket.full.Add(perToken)
+	return 0, perToken
+}
+
+// releaseLocked implements release. Must be called with the lock held.
+func (r *rateLimiter) releaseLocked(request types.NamespacedName) {
+	reserved, ok := r.reservations[request]
+	if !ok {
+		return
+	}
+	delete(r.reservations, request)
+
+	// A bucket which has since been refilled past the token is left full.
+	if bucket, ok := r.buckets[reserved.policyName]; ok {
+		bucket.full = bucket.full.Add(-reserved.perToken)
+	}
+}
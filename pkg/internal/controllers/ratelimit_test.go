@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_rateLimiter_reserve(t *testing.T) {
+	// limit permits 3 approvals per minute, so refills a token every 20
+	// seconds.
+	limit := policyapi.CertificateRequestPolicyRateLimit{Requests: 3, Window: metav1.Duration{Duration: time.Minute}}
+
+	type step struct {
+		// step is the duration to step the clock by before taking a token.
+		step    time.Duration
+		policy  string
+		limit   policyapi.CertificateRequestPolicyRateLimit
+		expWait time.Duration
+	}
+
+	tests := map[string][]step{
+		"a burst of up to requests is permitted, then exhausted until a token is refilled": {
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: time.Second * 20},
+			{step: time.Second * 5, policy: "a", limit: limit, expWait: time.Second * 15},
+			{step: time.Second * 15, policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: time.Second * 20},
+		},
+		"buckets are kept per policy": {
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: time.Second * 20},
+			{policy: "b", limit: limit, expWait: 0},
+		},
+		"buckets are refilled to at most requests": {
+			{policy: "a", limit: limit, expWait: 0},
+			{step: time.Hour, policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: time.Second * 20},
+		},
+		"if the limit of a policy is lowered, tokens already taken are refilled at the new rate": {
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: limit, expWait: 0},
+			{policy: "a", limit: policyapi.CertificateRequestPolicyRateLimit{Requests: 1, Window: metav1.Duration{Duration: time.Minute}}, expWait: time.Second * 40},
+			{step: time.Second * 40, policy: "a", limit: policyapi.CertificateRequestPolicyRateLimit{Requests: 1, Window: metav1.Duration{Duration: time.Minute}}, expWait: 0},
+			{policy: "a", limit: policyapi.CertificateRequestPolicyRateLimit{Requests: 1, Window: metav1.Duration{Duration: time.Minute}}, expWait: time.Minute},
+		},
+		"limits which never permit approvals are not enforced": {
+			{policy: "a", limit: policyapi.CertificateRequestPolicyRateLimit{Requests: 0, Window: metav1.Duration{Duration: time.Minute}}, expWait: 0},
+			{policy: "a", limit: policyapi.CertificateRequestPolicyRateLimit{Requests: 1}, expWait: 0},
+			{policy: "a", limit: policyapi.CertificateRequestPolicyRateLimit{Requests: 1}, expWait: 0},
+		},
+	}
+
+	for name, steps := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				clock   = fakeclock.NewFakeClock(time.Date(2023, 6, 5, 12, 0, 0, 0, time.UTC))
+				limiter rateLimiter
+			)
+			for i, step := range steps {
+				clock.Step(step.step)
+				request := types.NamespacedName{Namespace: "test-ns", Name: strconv.Itoa(i)}
+				assert.Equal(t, step.expWait, limiter.reserve(clock.Now(), request, step.policy, step.limit), "step %d", i)
+				limiter.commit(request)
+			}
+		})
+	}
+}
+
+func Test_rateLimiter_release(t *testing.T) {
+	var (
+		// limit permits 2 approvals per minute, so refills a token every 30
+		// seconds.
+		limit = policyapi.CertificateRequestPolicyRateLimit{Requests: 2, Window: metav1.Duration{Duration: time.Minute}}
+		now   = time.Date(2023, 6, 5, 12, 0, 0, 0, time.UTC)
+
+		request1 = types.NamespacedName{Namespace: "test-ns", Name: "request-1"}
+		request2 = types.NamespacedName{Namespace: "test-ns", Name: "request-2"}
+		request3 = types.NamespacedName{Namespace: "test-ns", Name: "request-3"}
+	)
+
+	var limiter rateLimiter
+	assert.Equal(t, time.Duration(0), limiter.reserve(now, request1, "a", limit))
+	limiter.commit(request1)
+	assert.Equal(t, time.Duration(0), limiter.reserve(now, request2, "a", limit))
+	assert.Equal(t, time.Second*30, limiter.reserve(now, request3, "a", limit), "expected the bucket to be exhausted")
+
+	limiter.release(request2)
+	assert.Equal(t, time.Duration(0), limiter.reserve(now, request3, "a", limit), "expected the released token to be returned to the bucket")
+	limiter.commit(request3)
+	limiter.release(request3)
+	assert.Equal(t, time.Second*30, limiter.reserve(now, request2, "a", limit), "expected a committed token to not be released")
+
+	assert.Equal(t, time.Duration(0), limiter.reserve(now.Add(time.Second*30), request2, "a", limit))
+	assert.Equal(t, time.Duration(0), limiter.reserve(now.Add(time.Second*30), request2, "a", limit), "expected reserving again for the same request to release its previous reservation")
+}
+
+func Test_rateLimiter_forget(t *testing.T) {
+	var (
+		limit   = policyapi.CertificateRequestPolicyRateLimit{Requests: 1, Window: metav1.Duration{Duration: time.Minute}}
+		now     = time.Date(2023, 6, 5, 12, 0, 0, 0, time.UTC)
+		request = types.NamespacedName{Namespace: "test-ns", Name: "request-1"}
+	)
+
+	var limiter rateLimiter
+	assert.Equal(t, time.Duration(0), limiter.reserve(now, request, "a", limit))
+	assert.Equal(t, time.Duration(0), limiter.reserve(now, types.NamespacedName{Namespace: "test-ns", Name: "request-2"}, "b", limit))
+
+	limiter.forget("a")
+	assert.NotContains(t, limiter.buckets, "a", "expected the bucket of the deleted policy to be removed")
+	assert.Contains(t, limiter.buckets, "b", "expected the buckets of other policies to be kept")
+	assert.NotContains(t, limiter.reservations, request, "expected the reservations of the deleted policy to be removed")
+}
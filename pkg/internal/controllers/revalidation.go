@@ -0,0 +1,259 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
+)
+
+// Event reasons recorded by the revalidation controller.
+const (
+	eventReasonRevalidationFailed = "RevalidationFailed"
+	eventReasonRevalidated        = "Revalidated"
+)
+
+// revalidation is a controller-runtime Reconciler which re-evaluates
+// CertificateRequests that have been approved but not yet issued, when a
+// CertificateRequestPolicy is annotated with RevalidateAnnotationKey. This
+// allows policy changes to be checked against requests which were approved
+// under the previous policy.
+// The Approved condition may not be modified once set, and the Ready
+// condition is owned by issuers, so requests which no longer pass policy are
+// instead annotated with RevalidationFailedAnnotationKey and a warning event.
+type revalidation struct {
+	// log is logger for the revalidation controller.
+	log logr.Logger
+
+	// recorder is used for creating Kubernetes events on resources.
+	recorder record.EventRecorder
+
+	// client is a Kubernetes REST client to interact with objects in the API
+	// server.
+	client client.Client
+
+	// lister makes requests to the informer cache for getting and listing
+	// objects.
+	lister client.Reader
+
+	// revalidator reviews whether CertificateRequests are still approved,
+	// and whether the annotated policy selects them.
+	revalidator internalmanager.Revalidator
+
+	// defaultDeny marks whether CertificateRequests which are no longer
+	// matched by any policy should be failed.
+	defaultDeny bool
+}
+
+// addRevalidationController will register the revalidation controller with
+// the controller-runtime Manager.
+func addRevalidationController(_ context.Context, opts Options) error {
+	return ctrl.NewControllerManagedBy(opts.Manager).
+		Named("revalidation").
+		For(new(policyapi.CertificateRequestPolicy), builder.WithPredicates(
+			// Only process CertificateRequestPolicies which have been annotated
+			// to trigger a revalidation.
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				_, ok := obj.GetAnnotations()[policyapi.RevalidateAnnotationKey]
+				return ok
+			}),
+		)).
+		Complete(&revalidation{
+			log:         opts.Log.WithName("revalidation"),
+			recorder:    opts.Manager.GetEventRecorderFor("policy.cert-manager.io"),
+			client:      opts.Manager.GetClient(),
+			lister:      opts.Manager.GetCache(),
+			revalidator: internalmanager.NewRevalidator(opts.Manager.GetCache(), opts.Manager.GetClient(), opts.Evaluators, opts.PolicyCombineMode),
+			defaultDeny: opts.DefaultDeny,
+		})
+}
+
+// Reconcile re-evaluates the CertificateRequests which are pending issuance,
+// and which the CertificateRequestPolicy selects or was evaluated for, if the
+// policy is annotated to trigger a revalidation. The annotation is removed
+// once all requests have been re-evaluated, so that a revalidation which fails
+// part way through is retried.
+func (r *revalidation) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.log.WithValues("name", req.Name)
+
+	policy := new(policyapi.CertificateRequestPolicy)
+	if err := r.lister.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if _, ok := policy.Annotations[policyapi.RevalidateAnnotationKey]; !ok {
+		return ctrl.Result{}, nil
+	}
+
+	log.V(2).Info("revalidating approved certificaterequests pending issuance")
+
+	var crList cmapi.CertificateRequestList
+	if err := r.lister.List(ctx, &crList); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list CertificateRequests: %w", err)
+	}
+
+	var (
+		reevaluated, denied int
+		errs                []error
+	)
+	for i := range crList.Items {
+		cr := &crList.Items[i]
+		if !pendingIssuance(cr) {
+			continue
+		}
+
+		candidate, err := r.candidate(ctx, policy, cr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", cr.Namespace, cr.Name, err))
+			continue
+		}
+		if !candidate {
+			continue
+		}
+
+		ok, err := r.revalidate(ctx, cr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", cr.Namespace, cr.Name, err))
+			continue
+		}
+		reevaluated++
+		if !ok {
+			denied++
+		}
+	}
+
+	metrics.ObserveRevalidation(reevaluated, denied)
+	log.V(2).Info("revalidated approved certificaterequests pending issuance", "reevaluated", reevaluated, "denied", denied)
+
+	if len(errs) > 0 {
+		return ctrl.Result{}, fmt.Errorf("failed to revalidate CertificateRequests: %w", utilerrors.NewAggregate(errs))
+	}
+
+	r.recorder.Event(policy, corev1.EventTypeNormal, eventReasonRevalidated, fmt.Sprintf("Re-evaluated %d approved CertificateRequests pending issuance, of which %d no longer pass policy and were annotated with %q", reevaluated, denied, policyapi.RevalidationFailedAnnotationKey))
+
+	patch := client.MergeFrom(policy.DeepCopy())
+	policy = policy.DeepCopy()
+	delete(policy.Annotations, policyapi.RevalidateAnnotationKey)
+	if err := r.client.Patch(ctx, policy, patch); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to remove revalidate annotation: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// candidate returns whether the CertificateRequest is re-evaluated when the
+// policy is revalidated, i.e. whether the policy currently selects the
+// request, or was evaluated for the request when it was approved. Requests
+// which the policy no longer selects are still re-evaluated, since the
+// policy may have approved them.
+func (r *revalidation) candidate(ctx context.Context, policy *policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (bool, error) {
+	if cr.Annotations[policyapi.ApprovedByAnnotationKey] == policy.Name {
+		return true, nil
+	}
+	for _, name := range strings.Split(cr.Annotations[policyapi.EvaluatedPoliciesAnnotationKey], ",") {
+		if name == policy.Name {
+			return true, nil
+		}
+	}
+	return r.revalidator.Selects(ctx, cr, policy)
+}
+
+// revalidate reviews the CertificateRequest against current policy,
+// annotating it with RevalidationFailedAnnotationKey if it is no longer
+// approved, or removing the annotation if it is. Returns whether the request
+// still passes policy.
+func (r *revalidation) revalidate(ctx context.Context, cr *cmapi.CertificateRequest) (bool, error) {
+	response, err := r.revalidator.Review(ctx, cr)
+	if err != nil {
+		return false, err
+	}
+
+	var message string
+	switch {
+	case response.Result == manager.ResultDenied:
+		message = response.Message
+	case response.Result == manager.ResultUnprocessed && r.defaultDeny:
+		message = fmt.Sprintf("No policy matches the request: %s", response.Message)
+	default:
+		if _, ok := cr.Annotations[policyapi.RevalidationFailedAnnotationKey]; ok {
+			patch := client.MergeFrom(cr.DeepCopy())
+			cr = cr.DeepCopy()
+			delete(cr.Annotations, policyapi.RevalidationFailedAnnotationKey)
+			delete(cr.Annotations, policyapi.DenialReasonsAnnotationKey)
+			if err := r.client.Patch(ctx, cr, patch); err != nil {
+				return false, fmt.Errorf("failed to remove revalidation failed annotation: %w", err)
+			}
+		}
+		return true, nil
+	}
+	message = fmt.Sprintf("Request no longer passes policy since it was approved: %s", message)
+
+	patch := client.MergeFrom(cr.DeepCopy())
+	cr = cr.DeepCopy()
+	metav1.SetMetaDataAnnotation(&cr.ObjectMeta, policyapi.RevalidationFailedAnnotationKey, message)
+	if len(response.Reasons) > 0 {
+		data, err := json.Marshal(response.Reasons)
+		if err != nil {
+			return false, fmt.Errorf("failed to encode denial reasons: %w", err)
+		}
+		metav1.SetMetaDataAnnotation(&cr.ObjectMeta, policyapi.DenialReasonsAnnotationKey, string(data))
+	} else {
+		delete(cr.Annotations, policyapi.DenialReasonsAnnotationKey)
+	}
+	if err := r.client.Patch(ctx, cr, patch); err != nil {
+		return false, fmt.Errorf("failed to annotate request as failing revalidation: %w", err)
+	}
+
+	r.recorder.Event(cr, corev1.EventTypeWarning, eventReasonRevalidationFailed, message)
+
+	return false, nil
+}
+
+// pendingIssuance returns whether the CertificateRequest has been approved,
+// but has not yet been issued or failed.
+func pendingIssuance(cr *cmapi.CertificateRequest) bool {
+	if !apiutil.CertificateRequestIsApproved(cr) || apiutil.CertificateRequestIsDenied(cr) {
+		return false
+	}
+	if len(cr.Status.Certificate) > 0 || cr.Status.FailureTime != nil {
+		return false
+	}
+	switch apiutil.CertificateRequestReadyReason(cr) {
+	case cmapi.CertificateRequestReasonIssued, cmapi.CertificateRequestReasonFailed, cmapi.CertificateRequestReasonDenied:
+		return false
+	}
+	return true
+}
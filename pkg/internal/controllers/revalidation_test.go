@@ -0,0 +1,227 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2/klogr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	fakemanager "github.com/cert-manager/approver-policy/pkg/approver/manager/fake"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
+)
+
+// fakeRevalidator is a Revalidator which reviews with the given manager, and
+// whose policies select requests whose name doesn't begin with "unselected-".
+type fakeRevalidator struct {
+	manager.Interface
+}
+
+func (f fakeRevalidator) Selects(_ context.Context, cr *cmapi.CertificateRequest, _ *policyapi.CertificateRequestPolicy) (bool, error) {
+	return !strings.HasPrefix(cr.Name, "unselected-"), nil
+}
+
+func Test_revalidation_Reconcile(t *testing.T) {
+	const policyName = "test-policy"
+
+	var (
+		// reviewManager denies requests whose name begins with "deny-" or
+		// "unselected-", leaves requests whose name begins with "unmatched-"
+		// unprocessed, and approves all others.
+		reviewManager = fakemanager.NewFakeManager().WithReview(func(_ context.Context, cr *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+			switch {
+			case strings.HasPrefix(cr.Name, "deny-"), strings.HasPrefix(cr.Name, "unselected-"):
+				return manager.ReviewResponse{Result: manager.ResultDenied, Message: "dns name not allowed", Reasons: []manager.DenialReason{{Policy: policyName, Field: "spec.allowed.dnsNames.values", Detail: "*.example.com"}}}, nil
+			case strings.HasPrefix(cr.Name, "unmatched-"):
+				return manager.ReviewResponse{Result: manager.ResultUnprocessed, Message: "No CertificateRequestPolicies exist"}, nil
+			default:
+				return manager.ReviewResponse{Result: manager.ResultApproved, Message: "approved"}, nil
+			}
+		})
+
+		approved = gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionApproved, Status: cmmeta.ConditionTrue})
+		request  = func(name string, mods ...gen.CertificateRequestModifier) runtime.Object {
+			return gen.CertificateRequest(name, append([]gen.CertificateRequestModifier{gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace)}, mods...)...)
+		}
+		failedMessage = "Request no longer passes policy since it was approved: dns name not allowed"
+
+		// requests are the existing requests, of which only "allow-pending",
+		// "allow-previously-failed", "deny-pending", "unmatched-pending",
+		// "unselected-approved-by" and "unselected-evaluated" are pending
+		// issuance and are candidates of the policy.
+		requests = []runtime.Object{
+			request("allow-pending", approved),
+			request("allow-previously-failed", approved, gen.AddCertificateRequestAnnotations(map[string]string{
+				policyapi.RevalidationFailedAnnotationKey: failedMessage,
+				policyapi.DenialReasonsAnnotationKey:      "[]",
+			})),
+			request("deny-pending", approved),
+			request("unmatched-pending", approved),
+			request("deny-issued", approved, gen.SetCertificateRequestCertificate([]byte("cert"))),
+			request("deny-failed", approved, gen.SetCertificateRequestFailureTime(metav1.Now())),
+			request("deny-unapproved"),
+			request("unselected-pending", approved),
+			request("unselected-approved-by", approved, gen.AddCertificateRequestAnnotations(map[string]string{
+				policyapi.ApprovedByAnnotationKey: policyName,
+			})),
+			request("unselected-evaluated", approved, gen.AddCertificateRequestAnnotations(map[string]string{
+				policyapi.EvaluatedPoliciesAnnotationKey: "other-policy," + policyName,
+			})),
+		}
+
+		annotatedPolicy = &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{
+			Name:        policyName,
+			Annotations: map[string]string{policyapi.RevalidateAnnotationKey: "", "foo": "bar"},
+		}}
+	)
+
+	tests := map[string]struct {
+		policy      *policyapi.CertificateRequestPolicy
+		manager     manager.Interface
+		defaultDeny bool
+
+		expError bool
+		// expFailed are the names of the requests expected to be annotated
+		// as failing revalidation.
+		expFailed []string
+		// expReevaluated and expDenied are the expected increases of the
+		// revalidation metrics.
+		expReevaluated, expDenied float64
+		// expAnnotations are the expected annotations of the policy after
+		// reconcile.
+		expAnnotations map[string]string
+	}{
+		"if policy doesn't exist, do nothing": {
+			manager:   reviewManager,
+			expFailed: []string{"allow-previously-failed"},
+		},
+		"if policy isn't annotated to revalidate, do nothing": {
+			policy:         &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: policyName, Annotations: map[string]string{"foo": "bar"}}},
+			manager:        reviewManager,
+			expFailed:      []string{"allow-previously-failed"},
+			expAnnotations: map[string]string{"foo": "bar"},
+		},
+		"if policy is annotated to revalidate, annotate candidate pending requests which are denied, unannotate those which pass, and remove the annotation": {
+			policy:         annotatedPolicy,
+			manager:        reviewManager,
+			expFailed:      []string{"deny-pending", "unselected-approved-by", "unselected-evaluated"},
+			expReevaluated: 6,
+			expDenied:      3,
+			expAnnotations: map[string]string{"foo": "bar"},
+		},
+		"if policy is annotated to revalidate with default deny, also annotate candidate pending requests which are unprocessed": {
+			policy:         annotatedPolicy,
+			manager:        reviewManager,
+			defaultDeny:    true,
+			expFailed:      []string{"deny-pending", "unmatched-pending", "unselected-approved-by", "unselected-evaluated"},
+			expReevaluated: 6,
+			expDenied:      4,
+			expAnnotations: map[string]string{"foo": "bar"},
+		},
+		"if review returns an error, return an error and keep the annotation so the revalidation is retried": {
+			policy: annotatedPolicy,
+			manager: fakemanager.NewFakeManager().WithReview(func(context.Context, *cmapi.CertificateRequest) (manager.ReviewResponse, error) {
+				return manager.ReviewResponse{}, errors.New("this is an error")
+			}),
+			expError:       true,
+			expFailed:      []string{"allow-previously-failed"},
+			expAnnotations: map[string]string{policyapi.RevalidateAnnotationKey: "", "foo": "bar"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			objects := append([]runtime.Object{}, requests...)
+			if test.policy != nil {
+				objects = append(objects, test.policy.DeepCopy())
+			}
+
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(objects...).
+				Build()
+
+			r := &revalidation{
+				client:      fakeclient,
+				lister:      fakeclient,
+				recorder:    record.NewFakeRecorder(10),
+				revalidator: fakeRevalidator{test.manager},
+				log:         klogr.New(),
+				defaultDeny: test.defaultDeny,
+			}
+
+			reevaluated, denied := testutil.ToFloat64(metrics.RevalidationReevaluated), testutil.ToFloat64(metrics.RevalidationDenied)
+
+			_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: policyName}})
+			assert.Equal(t, test.expError, err != nil, "%v", err)
+
+			assert.Equal(t, test.expReevaluated, testutil.ToFloat64(metrics.RevalidationReevaluated)-reevaluated, "unexpected number of re-evaluated requests")
+			assert.Equal(t, test.expDenied, testutil.ToFloat64(metrics.RevalidationDenied)-denied, "unexpected number of denied requests")
+
+			var crList cmapi.CertificateRequestList
+			if err := fakeclient.List(context.TODO(), &crList); err != nil {
+				t.Fatal(err)
+			}
+			var failed []string
+			for _, cr := range crList.Items {
+				assert.True(t, len(apiutil.CertificateRequestReadyReason(&cr)) == 0, "ready condition must not be modified")
+				assert.Equal(t, strings.HasPrefix(cr.Name, "deny-unapproved"), !apiutil.CertificateRequestIsApproved(&cr), "approval must not be modified")
+
+				message, ok := cr.Annotations[policyapi.RevalidationFailedAnnotationKey]
+				if !ok {
+					assert.NotContains(t, cr.Annotations, policyapi.DenialReasonsAnnotationKey)
+					continue
+				}
+				failed = append(failed, cr.Name)
+				if cr.Name == "unmatched-pending" {
+					assert.Equal(t, "Request no longer passes policy since it was approved: No policy matches the request: No CertificateRequestPolicies exist", message)
+					continue
+				}
+				assert.Equal(t, failedMessage, message)
+				if cr.Name != "allow-previously-failed" {
+					assert.Equal(t, `[{"policy":"test-policy","field":"spec.allowed.dnsNames.values","detail":"*.example.com"}]`, cr.Annotations[policyapi.DenialReasonsAnnotationKey])
+				}
+			}
+			assert.ElementsMatch(t, test.expFailed, failed, "unexpected failed requests")
+
+			if test.policy != nil {
+				var policy policyapi.CertificateRequestPolicy
+				if err := fakeclient.Get(context.TODO(), types.NamespacedName{Name: policyName}, &policy); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, test.expAnnotations, policy.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inherit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// MaxDepth is the maximum number of base policies that may be inherited by a
+// single policy.
+const MaxDepth = 5
+
+var (
+	// ErrCycle is returned when the base policies of a policy reference a
+	// policy which is already in the chain.
+	ErrCycle = errors.New("baseRef forms a cycle")
+
+	// ErrMaxDepth is returned when a policy inherits more than MaxDepth base
+	// policies.
+	ErrMaxDepth = fmt.Errorf("baseRef chain exceeds the maximum depth of %d", MaxDepth)
+)
+
+// Chain returns the base policies inherited by the given policy, nearest
+// first. Returns an error wrapping ErrCycle if a base policy references a
+// policy already in the chain, or ErrMaxDepth if the chain is longer than
+// MaxDepth. If a base policy doesn't exist, the NotFound error of the lister
+// is returned.
+func Chain(ctx context.Context, lister client.Reader, policy *policyapi.CertificateRequestPolicy) ([]policyapi.CertificateRequestPolicy, error) {
+	var (
+		chain []policyapi.CertificateRequestPolicy
+		seen  = map[string]bool{policy.Name: true}
+		ref   = policy.Spec.BaseRef
+	)
+
+	for ref != nil {
+		if seen[ref.Name] {
+			return nil, fmt.Errorf("%w: %q", ErrCycle, ref.Name)
+		}
+		if len(chain) == MaxDepth {
+			return nil, ErrMaxDepth
+		}
+		seen[ref.Name] = true
+
+		var base policyapi.CertificateRequestPolicy
+		if err := lister.Get(ctx, client.ObjectKey{Name: ref.Name}, &base); err != nil {
+			return nil, fmt.Errorf("failed to get base policy %q: %w", ref.Name, err)
+		}
+
+		chain = append(chain, base)
+		ref = base.Spec.BaseRef
+	}
+
+	return chain, nil
+}
+
+// Resolve returns the given policy with the Allowed and Constraints of all of
+// its base policies merged in. If the policy doesn't reference a base policy,
+// the given policy is returned unchanged, otherwise a copy is returned.
+func Resolve(ctx context.Context, lister client.Reader, policy *policyapi.CertificateRequestPolicy) (*policyapi.CertificateRequestPolicy, error) {
+	if policy.Spec.BaseRef == nil {
+		return policy, nil
+	}
+
+	chain, err := Chain(ctx, lister, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := policy.DeepCopy()
+
+	// Merge from the root of the chain down, so that nearer policies override
+	// further ones.
+	allowed, consts := chain[len(chain)-1].Spec.Allowed, chain[len(chain)-1].Spec.Constraints
+	for i := len(chain) - 2; i >= 0; i-- {
+		allowed = MergeAllowed(allowed, chain[i].Spec.Allowed)
+		consts = MergeConstraints(consts, chain[i].Spec.Constraints)
+	}
+	resolved.Spec.Allowed = MergeAllowed(allowed, resolved.Spec.Allowed)
+	resolved.Spec.Constraints = MergeConstraints(consts, resolved.Spec.Constraints)
+
+	return resolved, nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inherit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// policyWithBase returns a policy with the given name, which references the
+// given base policy name if not empty.
+func policyWithBase(name, base string) *policyapi.CertificateRequestPolicy {
+	policy := &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if len(base) > 0 {
+		policy.Spec.BaseRef = &policyapi.CertificateRequestPolicyBaseRef{Name: base}
+	}
+	return policy
+}
+
+func Test_Chain(t *testing.T) {
+	tests := map[string]struct {
+		policy          *policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+
+		expChain    []string
+		expNotFound bool
+		expErr      error
+	}{
+		"if policy has no baseRef, return no chain": {
+			policy:   policyWithBase("child", ""),
+			expChain: nil,
+		},
+		"if policy has a two level chain, return base policies nearest first": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{policyWithBase("team", "org"), policyWithBase("org", "")},
+			expChain:        []string{"team", "org"},
+		},
+		"if base policy doesn't exist, return not found error": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{policyWithBase("org", "")},
+			expNotFound:     true,
+		},
+		"if base policy of base policy doesn't exist, return not found error": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{policyWithBase("team", "org")},
+			expNotFound:     true,
+		},
+		"if policy references itself, return cycle error": {
+			policy:          policyWithBase("child", "child"),
+			existingObjects: []runtime.Object{policyWithBase("child", "")},
+			expErr:          ErrCycle,
+		},
+		"if chain references the policy, return cycle error": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{policyWithBase("team", "org"), policyWithBase("org", "child"), policyWithBase("child", "")},
+			expErr:          ErrCycle,
+		},
+		"if chain forms a cycle not including the policy, return cycle error": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{policyWithBase("team", "org"), policyWithBase("org", "team")},
+			expErr:          ErrCycle,
+		},
+		"if chain is the maximum depth, return chain": {
+			policy: policyWithBase("child", "base-1"),
+			existingObjects: []runtime.Object{
+				policyWithBase("base-1", "base-2"), policyWithBase("base-2", "base-3"), policyWithBase("base-3", "base-4"),
+				policyWithBase("base-4", "base-5"), policyWithBase("base-5", ""),
+			},
+			expChain: []string{"base-1", "base-2", "base-3", "base-4", "base-5"},
+		},
+		"if chain exceeds the maximum depth, return max depth error": {
+			policy: policyWithBase("child", "base-1"),
+			existingObjects: []runtime.Object{
+				policyWithBase("base-1", "base-2"), policyWithBase("base-2", "base-3"), policyWithBase("base-3", "base-4"),
+				policyWithBase("base-4", "base-5"), policyWithBase("base-5", "base-6"), policyWithBase("base-6", ""),
+			},
+			expErr: ErrMaxDepth,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			chain, err := Chain(context.TODO(), fakeclient, test.policy)
+			assert.Equal(t, test.expNotFound, apierrors.IsNotFound(err), "%v", err)
+			if test.expErr != nil {
+				assert.Truef(t, errors.Is(err, test.expErr), "expected error %v, got %v", test.expErr, err)
+			}
+			if test.expNotFound || test.expErr != nil {
+				return
+			}
+
+			assert.NoError(t, err)
+			var names []string
+			for _, policy := range chain {
+				names = append(names, policy.Name)
+			}
+			assert.Equal(t, test.expChain, names)
+		})
+	}
+}
+
+func Test_Resolve(t *testing.T) {
+	var (
+		org = &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "org"},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"Example Org"}, Required: pointer.Bool(true)},
+					},
+				},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{
+					MaxDuration: &metav1.Duration{Duration: time.Hour * 24 * 90},
+					MaxSANCount: pointer.Int(10),
+				},
+			},
+		}
+		team = &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				BaseRef: &policyapi.CertificateRequestPolicyBaseRef{Name: "org"},
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.payments.example.net"}},
+				},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{
+					MaxDuration: &metav1.Duration{Duration: time.Hour * 24 * 30},
+				},
+			},
+		}
+	)
+
+	tests := map[string]struct {
+		policy          *policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+
+		expSpec policyapi.CertificateRequestPolicySpec
+		expErr  bool
+	}{
+		"if policy has no baseRef, return policy unchanged": {
+			policy:  org,
+			expSpec: org.Spec,
+		},
+		"if base policy doesn't exist, return error": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{org},
+			expErr:          true,
+		},
+		"if policy inherits a two level chain, merge allowed and constraints of all base policies": {
+			policy: &policyapi.CertificateRequestPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "child"},
+				Spec: policyapi.CertificateRequestPolicySpec{
+					BaseRef: &policyapi.CertificateRequestPolicyBaseRef{Name: "team"},
+					Allowed: &policyapi.CertificateRequestPolicyAllowed{
+						DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"api.example.com"}, Required: pointer.Bool(true)},
+						Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+							Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(false)},
+						},
+					},
+					Constraints: &policyapi.CertificateRequestPolicyConstraints{
+						MaxDuration: &metav1.Duration{Duration: time.Hour * 24 * 60},
+						MaxSANCount: pointer.Int(20),
+					},
+					Priority: pointer.Int(5),
+				},
+			},
+			existingObjects: []runtime.Object{org, team},
+			expSpec: policyapi.CertificateRequestPolicySpec{
+				BaseRef: &policyapi.CertificateRequestPolicyBaseRef{Name: "team"},
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{
+						Values:   &[]string{"*.example.com", "*.payments.example.net", "api.example.com"},
+						Required: pointer.Bool(true),
+					},
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"Example Org"}, Required: pointer.Bool(false)},
+					},
+				},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{
+					MaxDuration: &metav1.Duration{Duration: time.Hour * 24 * 30},
+					MaxSANCount: pointer.Int(10),
+				},
+				Priority: pointer.Int(5),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			original := test.policy.DeepCopy()
+
+			resolved, err := Resolve(context.TODO(), fakeclient, test.policy)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, original, test.policy, "policy should not be modified")
+			if test.expErr {
+				return
+			}
+
+			assert.Equal(t, test.policy.Name, resolved.Name)
+			assert.Equal(t, test.expSpec, resolved.Spec)
+		})
+	}
+}
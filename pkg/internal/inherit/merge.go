@@ -0,0 +1,424 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inherit
+
+import (
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// MergeAllowed returns the child allowed merged over the parent allowed.
+// Lists of values are the union of both, with the parent's values first, and
+// all other fields of the child override those of the parent. Neither
+// argument is modified.
+func MergeAllowed(parent, child *policyapi.CertificateRequestPolicyAllowed) *policyapi.CertificateRequestPolicyAllowed {
+	if parent == nil || child == nil {
+		return override(parent.DeepCopy(), child.DeepCopy())
+	}
+
+	merged := &policyapi.CertificateRequestPolicyAllowed{
+		CommonName:           mergeString(parent.CommonName, child.CommonName),
+		DNSNames:             mergeStringSlice(parent.DNSNames, child.DNSNames),
+		IPAddresses:          mergeStringSlice(parent.IPAddresses, child.IPAddresses),
+		URIs:                 mergeStringSlice(parent.URIs, child.URIs),
+		EmailAddresses:       mergeStringSlice(parent.EmailAddresses, child.EmailAddresses),
+		IsCA:                 override(copyPtr(parent.IsCA), copyPtr(child.IsCA)),
+		ExactUsages:          override(copyPtr(parent.ExactUsages), copyPtr(child.ExactUsages)),
+		Subject:              mergeSubject(parent.Subject, child.Subject),
+		IncludeDefaultUsages: override(copyPtr(parent.IncludeDefaultUsages), copyPtr(child.IncludeDefaultUsages)),
+	}
+
+	if parent.Usages != nil || child.Usages != nil {
+		var usages []cmapi.KeyUsage
+		if parent.Usages != nil {
+			usages = append(usages, *parent.Usages...)
+		}
+		if child.Usages != nil {
+			usages = append(usages, *child.Usages...)
+		}
+		usages = union(usages)
+		merged.Usages = &usages
+	}
+
+	if parent.OtherNames != nil || child.OtherNames != nil {
+		merged.OtherNames = mergeOtherNames(parent.OtherNames, child.OtherNames)
+	}
+
+	if parent.Annotations != nil || child.Annotations != nil {
+		merged.Annotations = make(map[string]policyapi.CertificateRequestPolicyAllowedString)
+		for key, value := range parent.Annotations {
+			merged.Annotations[key] = *value.DeepCopy()
+		}
+		for key, value := range child.Annotations {
+			if parentValue, ok := merged.Annotations[key]; ok {
+				value := value
+				merged.Annotations[key] = *mergeString(&parentValue, &value)
+			} else {
+				merged.Annotations[key] = *value.DeepCopy()
+			}
+		}
+	}
+
+	return merged
+}
+
+// MergeConstraints returns the stricter of the parent and child constraints.
+// Neither argument is modified.
+func MergeConstraints(parent, child *policyapi.CertificateRequestPolicyConstraints) *policyapi.CertificateRequestPolicyConstraints {
+	if parent == nil || child == nil {
+		return override(parent.DeepCopy(), child.DeepCopy())
+	}
+
+	merged := &policyapi.CertificateRequestPolicyConstraints{
+		MinDuration:                stricterDuration(parent.MinDuration, child.MinDuration, func(a, b metav1.Duration) bool { return a.Duration > b.Duration }),
+		MaxDuration:                stricterDuration(parent.MaxDuration, child.MaxDuration, func(a, b metav1.Duration) bool { return a.Duration < b.Duration }),
+		MaxBackdate:                stricterDuration(parent.MaxBackdate, child.MaxBackdate, func(a, b metav1.Duration) bool { return a.Duration < b.Duration }),
+		MinReissueInterval:         stricterDuration(parent.MinReissueInterval, child.MinReissueInterval, func(a, b metav1.Duration) bool { return a.Duration > b.Duration }),
+		IsCA:                       override(copyPtr(parent.IsCA), copyPtr(child.IsCA)),
+		MaxPathLen:                 stricterInt(parent.MaxPathLen, child.MaxPathLen, stricterPathLen),
+		MaxCommonNameLength:        stricterInt(parent.MaxCommonNameLength, child.MaxCommonNameLength, func(a, b int) bool { return a < b }),
+		MaxSubjectTotalLength:      stricterInt(parent.MaxSubjectTotalLength, child.MaxSubjectTotalLength, func(a, b int) bool { return a < b }),
+		AllowedDurations:           intersect(parent.AllowedDurations, child.AllowedDurations),
+		AllowedSignatureAlgorithms: intersect(parent.AllowedSignatureAlgorithms, child.AllowedSignatureAlgorithms),
+		AllowedSANTypes:            intersect(parent.AllowedSANTypes, child.AllowedSANTypes),
+		AllowedExtensionOIDs:       intersect(parent.AllowedExtensionOIDs, child.AllowedExtensionOIDs),
+		AllowedNamespaces:          intersect(parent.AllowedNamespaces, child.AllowedNamespaces),
+		BlockedPublicKeyHashes:     override(parent.BlockedPublicKeyHashes.DeepCopy(), child.BlockedPublicKeyHashes.DeepCopy()),
+		ForbidDuplicateSANs:        stricterBool(parent.ForbidDuplicateSANs, child.ForbidDuplicateSANs),
+		ForbidWildcardDNSNames:     stricterBool(parent.ForbidWildcardDNSNames, child.ForbidWildcardDNSNames),
+		MaxDNSNameLabels:           stricterInt(parent.MaxDNSNameLabels, child.MaxDNSNameLabels, func(a, b int) bool { return a < b }),
+		RequireSANs:                stricterBool(parent.RequireSANs, child.RequireSANs),
+		RequireCASignUsageWhenCA:   stricterBool(parent.RequireCASignUsageWhenCA, child.RequireCASignUsageWhenCA),
+		RequireCRLSignUsageWhenCA:  stricterBool(parent.RequireCRLSignUsageWhenCA, child.RequireCRLSignUsageWhenCA),
+		ExpiryAlignment:            stricterExpiryAlignment(parent.ExpiryAlignment, child.ExpiryAlignment),
+		PrivateKey:                 mergePrivateKey(parent.PrivateKey, child.PrivateKey),
+	}
+
+	// The smaller expiryAlignmentTolerance is kept, where a policy which aligns
+	// expiries without a tolerance has the default tolerance.
+	if parent.ExpiryAlignmentTolerance != nil || child.ExpiryAlignmentTolerance != nil {
+		merged.ExpiryAlignmentTolerance = stricterDuration(expiryAlignmentTolerance(parent), expiryAlignmentTolerance(child), func(a, b metav1.Duration) bool { return a.Duration < b.Duration })
+	}
+
+	// The smaller allowedDurationsTolerance is kept, where a policy which
+	// allows durations without a tolerance has a tolerance of 0.
+	if parent.AllowedDurationsTolerance != nil || child.AllowedDurationsTolerance != nil {
+		merged.AllowedDurationsTolerance = stricterDuration(allowedDurationsTolerance(parent), allowedDurationsTolerance(child), func(a, b metav1.Duration) bool { return a.Duration < b.Duration })
+	}
+
+	// Rules of the child replace those of the parent, since the most specific
+	// rule applies to each key.
+	if child.DurationByKeySize != nil {
+		merged.DurationByKeySize = child.DeepCopy().DurationByKeySize
+	} else {
+		merged.DurationByKeySize = parent.DeepCopy().DurationByKeySize
+	}
+
+	// The maxSANCountPerType of the smaller maxSANCount is kept, since it
+	// changes what the count applies to. Counting SANs in aggregate is stricter
+	// than counting per type.
+	switch {
+	case parent.MaxSANCount == nil:
+		merged.MaxSANCount, merged.MaxSANCountPerType = copyPtr(child.MaxSANCount), copyPtr(child.MaxSANCountPerType)
+	case child.MaxSANCount == nil || *parent.MaxSANCount < *child.MaxSANCount:
+		merged.MaxSANCount, merged.MaxSANCountPerType = copyPtr(parent.MaxSANCount), copyPtr(parent.MaxSANCountPerType)
+	case *child.MaxSANCount < *parent.MaxSANCount:
+		merged.MaxSANCount, merged.MaxSANCountPerType = copyPtr(child.MaxSANCount), copyPtr(child.MaxSANCountPerType)
+	default:
+		merged.MaxSANCount = copyPtr(child.MaxSANCount)
+		switch {
+		case parent.MaxSANCountPerType != nil && *parent.MaxSANCountPerType && child.MaxSANCountPerType != nil && *child.MaxSANCountPerType:
+			merged.MaxSANCountPerType = pointer.Bool(true)
+		case parent.MaxSANCountPerType != nil || child.MaxSANCountPerType != nil:
+			merged.MaxSANCountPerType = pointer.Bool(false)
+		}
+	}
+
+	if parent.MaxSubjectEntries != nil || child.MaxSubjectEntries != nil {
+		merged.MaxSubjectEntries = make(map[string]int)
+		for key, value := range parent.MaxSubjectEntries {
+			merged.MaxSubjectEntries[key] = value
+		}
+		for key, value := range child.MaxSubjectEntries {
+			if parentValue, ok := merged.MaxSubjectEntries[key]; !ok || value < parentValue {
+				merged.MaxSubjectEntries[key] = value
+			}
+		}
+	}
+
+	return merged
+}
+
+// mergePrivateKey returns the stricter of the parent and child private key
+// constraints.
+func mergePrivateKey(parent, child *policyapi.CertificateRequestPolicyConstraintsPrivateKey) *policyapi.CertificateRequestPolicyConstraintsPrivateKey {
+	if parent == nil || child == nil {
+		return override(parent.DeepCopy(), child.DeepCopy())
+	}
+
+	merged := &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+		Algorithm: override(copyPtr(parent.Algorithm), copyPtr(child.Algorithm)),
+		MinSize:   stricterInt(parent.MinSize, child.MinSize, func(a, b int) bool { return a > b }),
+		MaxSize:   stricterInt(parent.MaxSize, child.MaxSize, func(a, b int) bool { return a < b }),
+	}
+
+	if parent.AllowedRSAPublicExponents != nil || child.AllowedRSAPublicExponents != nil {
+		exponents := intersect(deref(parent.AllowedRSAPublicExponents), deref(child.AllowedRSAPublicExponents))
+		merged.AllowedRSAPublicExponents = &exponents
+	}
+	if parent.AllowedECDSACurves != nil || child.AllowedECDSACurves != nil {
+		curves := intersect(deref(parent.AllowedECDSACurves), deref(child.AllowedECDSACurves))
+		merged.AllowedECDSACurves = &curves
+	}
+
+	return merged
+}
+
+// mergeOtherNames returns the otherNames of both parent and child, where the
+// values of entries of the same OID are the union of both, with the parent's
+// entries and values first.
+func mergeOtherNames(parent, child []policyapi.CertificateRequestPolicyAllowedOtherName) []policyapi.CertificateRequestPolicyAllowedOtherName {
+	merged := make([]policyapi.CertificateRequestPolicyAllowedOtherName, 0, len(parent)+len(child))
+	index := make(map[string]int)
+	for _, otherName := range append(append([]policyapi.CertificateRequestPolicyAllowedOtherName{}, parent...), child...) {
+		if i, ok := index[otherName.OID]; ok {
+			merged[i].Values = union(append(merged[i].Values, otherName.Values...))
+			continue
+		}
+		index[otherName.OID] = len(merged)
+		merged = append(merged, *otherName.DeepCopy())
+	}
+	return merged
+}
+
+// mergeSubject returns the child subject merged over the parent subject.
+func mergeSubject(parent, child *policyapi.CertificateRequestPolicyAllowedX509Subject) *policyapi.CertificateRequestPolicyAllowedX509Subject {
+	if parent == nil || child == nil {
+		return override(parent.DeepCopy(), child.DeepCopy())
+	}
+
+	return &policyapi.CertificateRequestPolicyAllowedX509Subject{
+		Organizations:       mergeStringSlice(parent.Organizations, child.Organizations),
+		Countries:           mergeStringSlice(parent.Countries, child.Countries),
+		OrganizationalUnits: mergeStringSlice(parent.OrganizationalUnits, child.OrganizationalUnits),
+		Localities:          mergeStringSlice(parent.Localities, child.Localities),
+		Provinces:           mergeStringSlice(parent.Provinces, child.Provinces),
+		StreetAddresses:     mergeStringSlice(parent.StreetAddresses, child.StreetAddresses),
+		PostalCodes:         mergeStringSlice(parent.PostalCodes, child.PostalCodes),
+		SerialNumber:        mergeString(parent.SerialNumber, child.SerialNumber),
+	}
+}
+
+// mergeStringSlice returns the union of the parent and child values, with the
+// child's other fields overriding those of the parent. If the merged field is
+// ordered, the child values override those of the parent.
+func mergeStringSlice(parent, child *policyapi.CertificateRequestPolicyAllowedStringSlice) *policyapi.CertificateRequestPolicyAllowedStringSlice {
+	if parent == nil || child == nil {
+		return override(parent.DeepCopy(), child.DeepCopy())
+	}
+
+	merged := &policyapi.CertificateRequestPolicyAllowedStringSlice{
+		ValuesFrom:        override(parent.ValuesFrom.DeepCopy(), child.ValuesFrom.DeepCopy()),
+		Required:          override(copyPtr(parent.Required), copyPtr(child.Required)),
+		MinEntries:        override(copyPtr(parent.MinEntries), copyPtr(child.MinEntries)),
+		MaxEntries:        override(copyPtr(parent.MaxEntries), copyPtr(child.MaxEntries)),
+		Ordered:           override(copyPtr(parent.Ordered), copyPtr(child.Ordered)),
+		CaseInsensitive:   override(copyPtr(parent.CaseInsensitive), copyPtr(child.CaseInsensitive)),
+		IncludeCommonName: override(copyPtr(parent.IncludeCommonName), copyPtr(child.IncludeCommonName)),
+		NormalizeIDNA:     override(copyPtr(parent.NormalizeIDNA), copyPtr(child.NormalizeIDNA)),
+	}
+	merged.MatchType, merged.Matcher = mergeMatch(parent.MatchType, parent.Matcher, child.MatchType, child.Matcher)
+	if merged.Ordered != nil && *merged.Ordered && child.Values != nil {
+		// Ordered values are matched by position, so can't be combined.
+		values := append([]string{}, *child.Values...)
+		merged.Values = &values
+	} else if parent.Values != nil || child.Values != nil {
+		values := union(append(append([]string{}, deref(parent.Values)...), deref(child.Values)...))
+		merged.Values = &values
+	}
+	if parent.AllowedDomains != nil || child.AllowedDomains != nil {
+		merged.AllowedDomains = union(append(append([]string{}, parent.AllowedDomains...), child.AllowedDomains...))
+	}
+
+	return merged
+}
+
+// mergeString returns the child merged over the parent, where any field
+// defined on the child overrides that of the parent.
+func mergeString(parent, child *policyapi.CertificateRequestPolicyAllowedString) *policyapi.CertificateRequestPolicyAllowedString {
+	if parent == nil || child == nil {
+		return override(parent.DeepCopy(), child.DeepCopy())
+	}
+
+	merged := &policyapi.CertificateRequestPolicyAllowedString{
+		Value:           override(copyPtr(parent.Value), copyPtr(child.Value)),
+		Required:        override(copyPtr(parent.Required), copyPtr(child.Required)),
+		CaseInsensitive: override(copyPtr(parent.CaseInsensitive), copyPtr(child.CaseInsensitive)),
+		MatchDNSNames:   override(copyPtr(parent.MatchDNSNames), copyPtr(child.MatchDNSNames)),
+	}
+	merged.MatchType, merged.Matcher = mergeMatch(parent.MatchType, parent.Matcher, child.MatchType, child.Matcher)
+	return merged
+}
+
+// mergeMatch returns copies of the child's matchType and matcher if the child
+// sets either, otherwise those of the parent. Both are overridden together
+// since they may not be set together.
+func mergeMatch(parentMatchType *policyapi.AllowedMatchType, parentMatcher *string, childMatchType *policyapi.AllowedMatchType, childMatcher *string) (*policyapi.AllowedMatchType, *string) {
+	if childMatchType != nil || childMatcher != nil {
+		return copyPtr(childMatchType), copyPtr(childMatcher)
+	}
+	return copyPtr(parentMatchType), copyPtr(parentMatcher)
+}
+
+// stricterDuration returns a copy of whichever of a or b is stricter. If
+// either is nil, the other is returned.
+func stricterDuration(a, b *metav1.Duration, stricter func(a, b metav1.Duration) bool) *metav1.Duration {
+	if a == nil || (b != nil && stricter(*b, *a)) {
+		return copyPtr(b)
+	}
+	return copyPtr(a)
+}
+
+// stricterInt returns a copy of whichever of a or b is stricter. If either
+// is nil, the other is returned.
+func stricterInt(a, b *int, stricter func(a, b int) bool) *int {
+	if a == nil || (b != nil && stricter(*b, *a)) {
+		return copyPtr(b)
+	}
+	return copyPtr(a)
+}
+
+// stricterBool returns a copy of whichever of a or b is true, since enabling
+// a constraint is stricter. If neither is true, b overrides a.
+func stricterBool(a, b *bool) *bool {
+	if a != nil && *a {
+		return copyPtr(a)
+	}
+	return override(copyPtr(a), copyPtr(b))
+}
+
+// stricterExpiryAlignment returns a copy of whichever of a or b aligns expiries
+// to the longer period, since expiries aligned to midnight are also aligned to
+// an hour. If either is nil, the other is returned.
+func stricterExpiryAlignment(a, b *policyapi.ExpiryAlignment) *policyapi.ExpiryAlignment {
+	if a == nil || (b != nil && *b == policyapi.ExpiryAlignmentMidnight) {
+		return copyPtr(b)
+	}
+	return copyPtr(a)
+}
+
+// expiryAlignmentTolerance returns the expiryAlignmentTolerance of the given
+// constraints, or the default tolerance if expiries are aligned without one.
+// Returns nil if expiries are not aligned.
+func expiryAlignmentTolerance(consts *policyapi.CertificateRequestPolicyConstraints) *metav1.Duration {
+	switch {
+	case consts.ExpiryAlignmentTolerance != nil:
+		return consts.ExpiryAlignmentTolerance
+	case consts.ExpiryAlignment != nil:
+		return &metav1.Duration{Duration: policyapi.DefaultExpiryAlignmentTolerance}
+	default:
+		return nil
+	}
+}
+
+// allowedDurationsTolerance returns the allowedDurationsTolerance of the given
+// constraints, or a tolerance of 0 if durations are allowed without one.
+// Returns nil if durations are not restricted to a set.
+func allowedDurationsTolerance(consts *policyapi.CertificateRequestPolicyConstraints) *metav1.Duration {
+	switch {
+	case consts.AllowedDurationsTolerance != nil:
+		return consts.AllowedDurationsTolerance
+	case consts.AllowedDurations != nil:
+		return &metav1.Duration{}
+	default:
+		return nil
+	}
+}
+
+// stricterPathLen returns whether the maximum path length a is stricter than
+// b, where a negative path length permits any path length.
+func stricterPathLen(a, b int) bool {
+	return a >= 0 && (b < 0 || a < b)
+}
+
+// override returns child if not nil, otherwise parent.
+func override[T any](parent, child *T) *T {
+	if child != nil {
+		return child
+	}
+	return parent
+}
+
+// copyPtr returns a pointer to a copy of the value of p, or nil if p is nil.
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// deref returns the slice pointed to by p, or nil if p is nil.
+func deref[T any](p *[]T) []T {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// union returns the given values with duplicates removed, preserving order.
+func union[T comparable](values []T) []T {
+	seen := make(map[T]bool, len(values))
+	result := make([]T, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// intersect returns the values of a which are also in b, preserving order. If
+// either is nil, the other is returned, since a nil list doesn't restrict any
+// values.
+func intersect[T comparable](a, b []T) []T {
+	if a == nil && b == nil {
+		return nil
+	}
+	if a == nil {
+		return append(make([]T, 0, len(b)), b...)
+	}
+	if b == nil {
+		return append(make([]T, 0, len(a)), a...)
+	}
+
+	inB := make(map[T]bool, len(b))
+	for _, value := range b {
+		inB[value] = true
+	}
+	result := make([]T, 0, len(a))
+	for _, value := range a {
+		if inB[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,397 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inherit
+
+import (
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_MergeAllowed(t *testing.T) {
+	prefixMatch := policyapi.AllowedMatchTypePrefix
+
+	tests := map[string]struct {
+		parent, child *policyapi.CertificateRequestPolicyAllowed
+		expAllowed    *policyapi.CertificateRequestPolicyAllowed
+	}{
+		"if both are nil, return nil": {
+			parent:     nil,
+			child:      nil,
+			expAllowed: nil,
+		},
+		"if child is nil, return parent": {
+			parent:     &policyapi.CertificateRequestPolicyAllowed{IsCA: pointer.Bool(true)},
+			child:      nil,
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{IsCA: pointer.Bool(true)},
+		},
+		"if parent is nil, return child": {
+			parent:     nil,
+			child:      &policyapi.CertificateRequestPolicyAllowed{IsCA: pointer.Bool(false)},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{IsCA: pointer.Bool(false)},
+		},
+		"values should be the union of both, and other fields of the child should override the parent": {
+			parent: &policyapi.CertificateRequestPolicyAllowed{
+				CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("*.example.com"), Required: pointer.Bool(true)},
+				DNSNames:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com", "example.com"}, CaseInsensitive: pointer.Bool(true)},
+				IPAddresses:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8"}},
+				EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{AllowedDomains: []string{"example.com"}},
+				IsCA:           pointer.Bool(true),
+				Usages:         &[]cmapi.KeyUsage{cmapi.UsageServerAuth, cmapi.UsageDigitalSignature},
+				Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+					"example.com/team":  {Value: pointer.String("*")},
+					"example.com/owner": {Value: pointer.String("*"), Required: pointer.Bool(true)},
+				},
+			},
+			child: &policyapi.CertificateRequestPolicyAllowed{
+				CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("api.example.com")},
+				DNSNames:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com", "api.example.net"}, Required: pointer.Bool(true)},
+				URIs:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://example.com/**"}},
+				EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"admin@example.org"}, AllowedDomains: []string{"*.example.com", "example.com"}},
+				IsCA:           pointer.Bool(false),
+				Usages:         &[]cmapi.KeyUsage{cmapi.UsageClientAuth, cmapi.UsageDigitalSignature},
+				Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+					"example.com/owner":  {Required: pointer.Bool(false)},
+					"example.com/ticket": {Value: pointer.String("TICKET-*")},
+				},
+			},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{
+				CommonName:     &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("api.example.com"), Required: pointer.Bool(true)},
+				DNSNames:       &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com", "example.com", "api.example.net"}, Required: pointer.Bool(true), CaseInsensitive: pointer.Bool(true)},
+				IPAddresses:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"10.0.0.0/8"}},
+				URIs:           &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://example.com/**"}},
+				EmailAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"admin@example.org"}, AllowedDomains: []string{"example.com", "*.example.com"}},
+				IsCA:           pointer.Bool(false),
+				Usages:         &[]cmapi.KeyUsage{cmapi.UsageServerAuth, cmapi.UsageDigitalSignature, cmapi.UsageClientAuth},
+				Annotations: map[string]policyapi.CertificateRequestPolicyAllowedString{
+					"example.com/team":   {Value: pointer.String("*")},
+					"example.com/owner":  {Value: pointer.String("*"), Required: pointer.Bool(false)},
+					"example.com/ticket": {Value: pointer.String("TICKET-*")},
+				},
+			},
+		},
+		"subject fields should be merged": {
+			parent: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"Example Org"}},
+					SerialNumber:  &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("*")},
+				},
+			},
+			child: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"Payments"}},
+					Countries:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"GB"}},
+					SerialNumber:  &policyapi.CertificateRequestPolicyAllowedString{MatchType: &prefixMatch},
+				},
+			},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"Example Org", "Payments"}},
+					Countries:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"GB"}},
+					SerialNumber:  &policyapi.CertificateRequestPolicyAllowedString{Value: pointer.String("*"), MatchType: &prefixMatch},
+				},
+			},
+		},
+		"otherNames values of the same OID should be the union of both": {
+			parent: &policyapi.CertificateRequestPolicyAllowed{
+				OtherNames: []policyapi.CertificateRequestPolicyAllowedOtherName{
+					{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@example.com"}},
+				},
+			},
+			child: &policyapi.CertificateRequestPolicyAllowed{
+				OtherNames: []policyapi.CertificateRequestPolicyAllowedOtherName{
+					{OID: "1.3.6.1.5.5.7.8.9", Values: []string{"user@example.com"}},
+					{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@example.com", "*@example.org"}},
+				},
+			},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{
+				OtherNames: []policyapi.CertificateRequestPolicyAllowedOtherName{
+					{OID: "1.3.6.1.4.1.311.20.2.3", Values: []string{"*@example.com", "*@example.org"}},
+					{OID: "1.3.6.1.5.5.7.8.9", Values: []string{"user@example.com"}},
+				},
+			},
+		},
+		"a matcher of the child should override both the matchType and matcher of the parent": {
+			parent: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com"}, MatchType: &prefixMatch},
+				URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://example.com/*"}, Matcher: pointer.String("wildcard")},
+			},
+			child: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"foo.example.com"}, Matcher: pointer.String("wildcard")},
+				URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://example.net/*"}},
+			},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"example.com", "foo.example.com"}, Matcher: pointer.String("wildcard")},
+				URIs:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"spiffe://example.com/*", "spiffe://example.net/*"}, Matcher: pointer.String("wildcard")},
+			},
+		},
+		"valuesFrom of the child should override the parent, and values should still be the union of both": {
+			parent: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{
+					Values:     &[]string{"example.com"},
+					ValuesFrom: &policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "base", Key: "dnsNames"},
+				},
+			},
+			child: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{
+					Values:     &[]string{"foo.example.com"},
+					ValuesFrom: &policyapi.CertificateRequestPolicyValuesFrom{Namespace: "team-foo", Name: "foo", Key: "dnsNames"},
+				},
+			},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{
+				DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{
+					Values:     &[]string{"example.com", "foo.example.com"},
+					ValuesFrom: &policyapi.CertificateRequestPolicyValuesFrom{Namespace: "team-foo", Name: "foo", Key: "dnsNames"},
+				},
+			},
+		},
+		"minEntries and maxEntries of the child should override the parent": {
+			parent: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, MinEntries: pointer.Int(1), MaxEntries: pointer.Int(3)},
+				},
+			},
+			child: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{MaxEntries: pointer.Int(1)},
+				},
+			},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, MinEntries: pointer.Int(1), MaxEntries: pointer.Int(1)},
+				},
+			},
+		},
+		"ordered values of the child should override the parent, rather than being combined": {
+			parent: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "*"}, Ordered: pointer.Bool(true)},
+				},
+			},
+			child: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "platform"}},
+				},
+			},
+			expAllowed: &policyapi.CertificateRequestPolicyAllowed{
+				Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+					OrganizationalUnits: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"engineering", "platform"}, Ordered: pointer.Bool(true)},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent, child := test.parent.DeepCopy(), test.child.DeepCopy()
+			assert.Equal(t, test.expAllowed, MergeAllowed(test.parent, test.child))
+			assert.Equal(t, parent, test.parent, "parent should not be modified")
+			assert.Equal(t, child, test.child, "child should not be modified")
+		})
+	}
+}
+
+func Test_MergeConstraints(t *testing.T) {
+	var (
+		rsaAlg   = cmapi.RSAKeyAlgorithm
+		ecdsaAlg = cmapi.ECDSAKeyAlgorithm
+		midnight = policyapi.ExpiryAlignmentMidnight
+		hour     = policyapi.ExpiryAlignmentHour
+	)
+
+	tests := map[string]struct {
+		parent, child  *policyapi.CertificateRequestPolicyConstraints
+		expConstraints *policyapi.CertificateRequestPolicyConstraints
+	}{
+		"if both are nil, return nil": {
+			parent:         nil,
+			child:          nil,
+			expConstraints: nil,
+		},
+		"if child is nil, return parent": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(5)},
+			child:          nil,
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(5)},
+		},
+		"the stricter of both constraints should be returned": {
+			parent: &policyapi.CertificateRequestPolicyConstraints{
+				MinDuration:                &metav1.Duration{Duration: time.Hour},
+				MaxDuration:                &metav1.Duration{Duration: time.Hour * 24},
+				MaxSubjectEntries:          map[string]int{"organizations": 2, "countries": 1},
+				AllowedSignatureAlgorithms: []string{"SHA256WithRSA", "SHA384WithRSA"},
+				AllowedSANTypes:            []string{"DNS", "IP"},
+				AllowedExtensionOIDs:       []string{"1.3.6.1.4.1.11129.2.4.2", "1.3.6.1.4.1.99999.1"},
+				AllowedNamespaces:          []string{"team-a", "team-b"},
+				IsCA:                       pointer.Bool(true),
+				PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+					Algorithm:                 &rsaAlg,
+					MinSize:                   pointer.Int(2048),
+					MaxSize:                   pointer.Int(4096),
+					AllowedRSAPublicExponents: &[]int{3, 65537},
+				},
+			},
+			child: &policyapi.CertificateRequestPolicyConstraints{
+				MinDuration:                &metav1.Duration{Duration: time.Minute},
+				MaxDuration:                &metav1.Duration{Duration: time.Hour * 12},
+				MaxSubjectEntries:          map[string]int{"organizations": 1, "localities": 0},
+				AllowedSignatureAlgorithms: []string{"SHA384WithRSA", "ECDSAWithSHA384"},
+				AllowedSANTypes:            []string{"DNS", "URI"},
+				AllowedExtensionOIDs:       []string{"1.3.6.1.4.1.99999.1"},
+				AllowedNamespaces:          []string{"team-b", "team-c"},
+				IsCA:                       pointer.Bool(false),
+				PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+					MinSize:                   pointer.Int(3072),
+					AllowedRSAPublicExponents: &[]int{65537},
+				},
+			},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{
+				MinDuration:                &metav1.Duration{Duration: time.Hour},
+				MaxDuration:                &metav1.Duration{Duration: time.Hour * 12},
+				MaxSubjectEntries:          map[string]int{"organizations": 1, "countries": 1, "localities": 0},
+				AllowedSignatureAlgorithms: []string{"SHA384WithRSA"},
+				AllowedSANTypes:            []string{"DNS"},
+				AllowedExtensionOIDs:       []string{"1.3.6.1.4.1.99999.1"},
+				AllowedNamespaces:          []string{"team-b"},
+				IsCA:                       pointer.Bool(false),
+				PrivateKey: &policyapi.CertificateRequestPolicyConstraintsPrivateKey{
+					Algorithm:                 &rsaAlg,
+					MinSize:                   pointer.Int(3072),
+					MaxSize:                   pointer.Int(4096),
+					AllowedRSAPublicExponents: &[]int{65537},
+				},
+			},
+		},
+		"if allowed lists have no values in common, an empty list should be returned": {
+			parent: &policyapi.CertificateRequestPolicyConstraints{
+				AllowedSignatureAlgorithms: []string{"SHA256WithRSA"},
+				PrivateKey:                 &policyapi.CertificateRequestPolicyConstraintsPrivateKey{AllowedECDSACurves: &[]string{"P-256"}},
+			},
+			child: &policyapi.CertificateRequestPolicyConstraints{
+				AllowedSignatureAlgorithms: []string{"ECDSAWithSHA384"},
+				PrivateKey:                 &policyapi.CertificateRequestPolicyConstraintsPrivateKey{Algorithm: &ecdsaAlg, AllowedECDSACurves: &[]string{"P-384"}},
+			},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{
+				AllowedSignatureAlgorithms: []string{},
+				PrivateKey:                 &policyapi.CertificateRequestPolicyConstraintsPrivateKey{Algorithm: &ecdsaAlg, AllowedECDSACurves: &[]string{}},
+			},
+		},
+		"the smaller maxSANCount should be returned with its maxSANCountPerType": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(10)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(2), MaxSANCountPerType: pointer.Bool(true)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(2), MaxSANCountPerType: pointer.Bool(true)},
+		},
+		"the smaller non-negative maxPathLen should be returned": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MaxPathLen: pointer.Int(2)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{MaxPathLen: pointer.Int(-1)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MaxPathLen: pointer.Int(2)},
+		},
+		"if maxSANCount is equal, SANs should be counted in aggregate unless both count per type": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(5), MaxSANCountPerType: pointer.Bool(true)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(5)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(5), MaxSANCountPerType: pointer.Bool(false)},
+		},
+		"forbidDuplicateSANs should be true if set to true by either policy": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{ForbidDuplicateSANs: pointer.Bool(true)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{ForbidDuplicateSANs: pointer.Bool(false)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{ForbidDuplicateSANs: pointer.Bool(true)},
+		},
+		"forbidWildcardDNSNames should be true if set to true by either policy": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{ForbidWildcardDNSNames: pointer.Bool(false)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{ForbidWildcardDNSNames: pointer.Bool(true)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{ForbidWildcardDNSNames: pointer.Bool(true)},
+		},
+		"the longer expiryAlignment and the smaller expiryAlignmentTolerance should be returned, where an unset tolerance is the default": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{ExpiryAlignment: &midnight},
+			child:          &policyapi.CertificateRequestPolicyConstraints{ExpiryAlignment: &hour, ExpiryAlignmentTolerance: &metav1.Duration{Duration: time.Minute * 10}},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{ExpiryAlignment: &midnight, ExpiryAlignmentTolerance: &metav1.Duration{Duration: time.Minute * 5}},
+		},
+		"the intersection of allowedDurations and the smaller allowedDurationsTolerance should be returned, where an unset tolerance is 0": {
+			parent: &policyapi.CertificateRequestPolicyConstraints{
+				AllowedDurations:          []metav1.Duration{{Duration: time.Hour}, {Duration: time.Hour * 24}},
+				AllowedDurationsTolerance: &metav1.Duration{Duration: time.Minute},
+			},
+			child:          &policyapi.CertificateRequestPolicyConstraints{AllowedDurations: []metav1.Duration{{Duration: time.Hour * 24}, {Duration: time.Hour * 48}}},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{AllowedDurations: []metav1.Duration{{Duration: time.Hour * 24}}, AllowedDurationsTolerance: &metav1.Duration{}},
+		},
+		"the smaller maxBackdate should be returned": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MaxBackdate: &metav1.Duration{Duration: time.Hour}},
+			child:          &policyapi.CertificateRequestPolicyConstraints{MaxBackdate: &metav1.Duration{Duration: time.Minute}},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MaxBackdate: &metav1.Duration{Duration: time.Minute}},
+		},
+		"the larger minReissueInterval should be returned": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MinReissueInterval: &metav1.Duration{Duration: time.Hour}},
+			child:          &policyapi.CertificateRequestPolicyConstraints{MinReissueInterval: &metav1.Duration{Duration: time.Minute}},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MinReissueInterval: &metav1.Duration{Duration: time.Hour}},
+		},
+		"the smaller maxCommonNameLength and maxSubjectTotalLength should be returned": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MaxCommonNameLength: pointer.Int(64), MaxSubjectTotalLength: pointer.Int(128)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{MaxCommonNameLength: pointer.Int(128), MaxSubjectTotalLength: pointer.Int(64)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MaxCommonNameLength: pointer.Int(64), MaxSubjectTotalLength: pointer.Int(64)},
+		},
+		"the smaller maxDNSNameLabels should be returned": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{MaxDNSNameLabels: pointer.Int(3)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{MaxDNSNameLabels: pointer.Int(4)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{MaxDNSNameLabels: pointer.Int(3)},
+		},
+		"requireSANs should be true if set to true by either policy": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{RequireSANs: pointer.Bool(true)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{RequireSANs: pointer.Bool(true)},
+		},
+		"requireCASignUsageWhenCA should be true if set to true by either policy": {
+			parent:         &policyapi.CertificateRequestPolicyConstraints{RequireCASignUsageWhenCA: pointer.Bool(false)},
+			child:          &policyapi.CertificateRequestPolicyConstraints{RequireCASignUsageWhenCA: pointer.Bool(true)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{RequireCASignUsageWhenCA: pointer.Bool(true)},
+		},
+		"durationByKeySize rules of the child should replace those of the parent": {
+			parent: &policyapi.CertificateRequestPolicyConstraints{
+				DurationByKeySize: []policyapi.CertificateRequestPolicyDurationByKeySize{{Algorithm: &rsaAlg, MaxDuration: metav1.Duration{Duration: time.Hour}}},
+			},
+			child: &policyapi.CertificateRequestPolicyConstraints{
+				DurationByKeySize: []policyapi.CertificateRequestPolicyDurationByKeySize{{MinSize: pointer.Int(4096), MaxDuration: metav1.Duration{Duration: time.Hour * 2}}},
+			},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{
+				DurationByKeySize: []policyapi.CertificateRequestPolicyDurationByKeySize{{MinSize: pointer.Int(4096), MaxDuration: metav1.Duration{Duration: time.Hour * 2}}},
+			},
+		},
+		"if the child has no durationByKeySize rules, those of the parent should be returned": {
+			parent: &policyapi.CertificateRequestPolicyConstraints{
+				DurationByKeySize: []policyapi.CertificateRequestPolicyDurationByKeySize{{Algorithm: &rsaAlg, MaxDuration: metav1.Duration{Duration: time.Hour}}},
+			},
+			child: &policyapi.CertificateRequestPolicyConstraints{IsCA: pointer.Bool(false)},
+			expConstraints: &policyapi.CertificateRequestPolicyConstraints{
+				IsCA:              pointer.Bool(false),
+				DurationByKeySize: []policyapi.CertificateRequestPolicyDurationByKeySize{{Algorithm: &rsaAlg, MaxDuration: metav1.Duration{Duration: time.Hour}}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent, child := test.parent.DeepCopy(), test.child.DeepCopy()
+			assert.Equal(t, test.expConstraints, MergeConstraints(test.parent, test.child))
+			assert.Equal(t, parent, test.parent, "parent should not be modified")
+			assert.Equal(t, child, test.child, "child should not be modified")
+		})
+	}
+}
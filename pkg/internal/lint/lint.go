@@ -0,0 +1,387 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint analyses a set of CertificateRequestPolicies for policies which
+// can never decide a CertificateRequest. The analysis is static: it doesn't
+// consider RBAC, the readiness of policies, or the requests which exist, so
+// only reports policies which are unreachable for every possible request.
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/internal/inherit"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// Reason is the machine readable reason of a Finding.
+type Reason string
+
+const (
+	// ReasonShadowed is the reason of a policy which selects only requests
+	// that are also selected by a policy which is always evaluated in its
+	// place.
+	ReasonShadowed Reason = "Shadowed"
+
+	// ReasonEmptyAllowed is the reason of a policy which, once its base
+	// policies are inherited, can't approve any request.
+	ReasonEmptyAllowed Reason = "EmptyAllowed"
+
+	// ReasonInvalidBaseRef is the reason of a policy whose base policies
+	// can't be resolved, so can't be evaluated.
+	ReasonInvalidBaseRef Reason = "InvalidBaseRef"
+)
+
+// Finding is a policy which can never be the deciding policy of a request.
+type Finding struct {
+	// Policy is the name of the policy.
+	Policy string `json:"policy"`
+
+	// Reason is the machine readable reason the policy can't decide requests.
+	Reason Reason `json:"reason"`
+
+	// Message is the human readable explanation of the finding.
+	Message string `json:"message"`
+
+	// ShadowedBy is the name of the policy which shadows the policy, if the
+	// Reason is ReasonShadowed.
+	ShadowedBy string `json:"shadowedBy,omitempty"`
+}
+
+// Lint returns the findings of the given policies, ordered by policy name.
+// A policy is shadowed by another policy, which is always evaluated instead of
+// it, if the other policy:
+//   - selects every request the policy selects;
+//   - is always active, i.e. has no `activeSchedule`;
+//   - has a higher priority, or the same priority and is authoritative while
+//     the policy isn't.
+//
+// Selectors are compared conservatively: a policy is only reported as
+// shadowed if a single other policy is known to select all of its requests.
+// A policy may still be unreachable if it is shadowed by several policies
+// together, which isn't reported.
+func Lint(ctx context.Context, policies []policyapi.CertificateRequestPolicy) ([]Finding, error) {
+	sorted := make([]policyapi.CertificateRequestPolicy, len(policies))
+	copy(sorted, policies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	// Candidates to shadow other policies are considered in the order they
+	// are evaluated in, so the reported policy is the one which is evaluated
+	// first.
+	byPrecedence := make([]policyapi.CertificateRequestPolicy, len(sorted))
+	copy(byPrecedence, sorted)
+	sort.SliceStable(byPrecedence, func(i, j int) bool {
+		return priority(&byPrecedence[i]) > priority(&byPrecedence[j])
+	})
+
+	lister := newPolicyReader(sorted)
+
+	var findings []Finding
+	for i := range sorted {
+		policy := &sorted[i]
+
+		for j := range byPrecedence {
+			other := &byPrecedence[j]
+			if other.Name == policy.Name || !shadows(other, policy) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Policy:     policy.Name,
+				Reason:     ReasonShadowed,
+				Message:    fmt.Sprintf("every request selected by this policy is also selected by %q, which %s", other.Name, shadowsDetail(other, policy)),
+				ShadowedBy: other.Name,
+			})
+			break
+		}
+
+		resolved, err := inherit.Resolve(ctx, lister, policy)
+		if err != nil {
+			if !errors.Is(err, inherit.ErrCycle) && !errors.Is(err, inherit.ErrMaxDepth) && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			findings = append(findings, Finding{
+				Policy:  policy.Name,
+				Reason:  ReasonInvalidBaseRef,
+				Message: fmt.Sprintf("base policies can't be resolved: %s", err),
+			})
+			continue
+		}
+
+		if reasons := emptyAllowed(resolved); len(reasons) > 0 {
+			findings = append(findings, Finding{
+				Policy:  policy.Name,
+				Reason:  ReasonEmptyAllowed,
+				Message: fmt.Sprintf("this policy can't approve any request: %s", strings.Join(reasons, "; ")),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// priority returns the priority of the policy, where nil is 0.
+func priority(policy *policyapi.CertificateRequestPolicy) int {
+	if policy.Spec.Priority == nil {
+		return 0
+	}
+	return *policy.Spec.Priority
+}
+
+// shadows returns whether the other policy is always evaluated instead of the
+// policy for every request the policy selects.
+func shadows(other, policy *policyapi.CertificateRequestPolicy) bool {
+	if other.Spec.ActiveSchedule != nil {
+		return false
+	}
+	switch {
+	case priority(other) > priority(policy):
+	case priority(other) == priority(policy) && other.Spec.Selector.Authoritative && !policy.Spec.Selector.Authoritative:
+	default:
+		return false
+	}
+	return selectorCovers(&other.Spec.Selector, &policy.Spec.Selector)
+}
+
+// shadowsDetail returns why the other policy, which shadows the policy, is
+// evaluated instead of it.
+func shadowsDetail(other, policy *policyapi.CertificateRequestPolicy) string {
+	if priority(other) > priority(policy) {
+		return fmt.Sprintf("has a higher priority of %d", priority(other))
+	}
+	return "is authoritative with the same priority"
+}
+
+// selectorCovers returns whether the selector a is known to select every
+// request that the selector b selects. The issuerRef, issuerRefs and
+// namespace selectors are compared by their patterns and labels, and all
+// other selectors of a must be omitted or equal to those of b.
+func selectorCovers(a, b *policyapi.CertificateRequestPolicySelector) bool {
+	if !issuerRefsCover(util.IssuerRefSelectors(a), util.IssuerRefSelectors(b)) || !namespaceCovers(a.Namespace, b.Namespace) {
+		return false
+	}
+
+	switch {
+	case a.ServiceAccount != nil && !apiequality.Semantic.DeepEqual(a.ServiceAccount, b.ServiceAccount),
+		a.CertificateLabels != nil && !apiequality.Semantic.DeepEqual(a.CertificateLabels, b.CertificateLabels),
+		a.SecretName != nil && !apiequality.Semantic.DeepEqual(a.SecretName, b.SecretName),
+		a.SecretLabels != nil && !apiequality.Semantic.DeepEqual(a.SecretLabels, b.SecretLabels),
+		a.Requester != nil && !apiequality.Semantic.DeepEqual(a.Requester, b.Requester),
+		a.RequestLabels != nil && !apiequality.Semantic.DeepEqual(a.RequestLabels, b.RequestLabels),
+		a.MinDuration != nil && !apiequality.Semantic.DeepEqual(a.MinDuration, b.MinDuration),
+		a.MaxDuration != nil && !apiequality.Semantic.DeepEqual(a.MaxDuration, b.MaxDuration),
+		a.SelectOnMissingDuration != nil && !apiequality.Semantic.DeepEqual(a.SelectOnMissingDuration, b.SelectOnMissingDuration),
+		a.IsRenewal != nil && !apiequality.Semantic.DeepEqual(a.IsRenewal, b.IsRenewal):
+		return false
+	}
+	return labelsCover(a.RequestAnnotations, b.RequestAnnotations)
+}
+
+// issuerRefsCover returns whether the issuerRef selectors a select every
+// issuer that the issuerRef selectors b select, where each selector of b must
+// be covered by one of a. Omitted selectors select every issuer.
+func issuerRefsCover(a, b []policyapi.CertificateRequestPolicySelectorIssuerRef) bool {
+	if len(a) == 0 {
+		return true
+	}
+	if len(b) == 0 {
+		b = []policyapi.CertificateRequestPolicySelectorIssuerRef{{}}
+	}
+	for i := range b {
+		var covered bool
+		for j := range a {
+			if issuerRefCovers(&a[j], &b[i]) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// issuerRefCovers returns whether the issuerRef selector a selects every
+// issuer that b selects.
+func issuerRefCovers(a, b *policyapi.CertificateRequestPolicySelectorIssuerRef) bool {
+	if a == nil {
+		return true
+	}
+	if b == nil {
+		b = new(policyapi.CertificateRequestPolicySelectorIssuerRef)
+	}
+	return patternCovers(a.Name, b.Name) && patternCovers(a.Kind, b.Kind) && patternCovers(a.Group, b.Group) &&
+		labelsCover(a.MatchLabels, b.MatchLabels)
+}
+
+// namespaceCovers returns whether the namespace selector a selects every
+// namespace that b selects.
+func namespaceCovers(a, b *policyapi.CertificateRequestPolicySelectorNamespace) bool {
+	if a == nil {
+		return true
+	}
+	if b == nil {
+		b = new(policyapi.CertificateRequestPolicySelectorNamespace)
+	}
+	if len(a.MatchNames) > 0 && (len(b.MatchNames) == 0 || !util.WildcardSubset(a.MatchNames, b.MatchNames)) {
+		return false
+	}
+	return labelsCover(a.MatchLabels, b.MatchLabels)
+}
+
+// patternCovers returns whether the wildcard pattern a matches every value
+// that the wildcard pattern b matches. An omitted pattern matches any value.
+// Patterns of b are matched literally by a, which is conservative: "*" in b
+// is only covered by a pattern which matches "*" itself.
+func patternCovers(a, b *string) bool {
+	if a == nil || *a == "*" {
+		return true
+	}
+	return b != nil && util.WildcardMatches(*a, *b)
+}
+
+// labelsCover returns whether every label of a is also a label of b with the
+// same value, so that objects selected by b are also selected by a.
+func labelsCover(a, b map[string]string) bool {
+	for key, value := range a {
+		if bValue, ok := b[key]; !ok || bValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// emptyAllowed returns the reasons the resolved policy can't approve any
+// request, which is the case if it permits no identity at all, or if its
+// constraints contradict each other once inherited.
+func emptyAllowed(policy *policyapi.CertificateRequestPolicy) []string {
+	var reasons []string
+
+	if !permitsIdentity(policy.Spec.Allowed) {
+		reasons = append(reasons, "allowed permits no common name, subject alternative name or subject attribute")
+	}
+
+	if consts := policy.Spec.Constraints; consts != nil {
+		if consts.MinDuration != nil && consts.MaxDuration != nil && consts.MinDuration.Duration > consts.MaxDuration.Duration {
+			reasons = append(reasons, fmt.Sprintf("constraints.minDuration %s is larger than constraints.maxDuration %s", consts.MinDuration.Duration, consts.MaxDuration.Duration))
+		}
+		// Base policies which allow disjoint sets of durations leave none.
+		if consts.AllowedDurations != nil && len(consts.AllowedDurations) == 0 {
+			reasons = append(reasons, "constraints.allowedDurations permits no duration")
+		}
+		if pk := consts.PrivateKey; pk != nil && pk.MinSize != nil && pk.MaxSize != nil && *pk.MinSize > *pk.MaxSize {
+			reasons = append(reasons, fmt.Sprintf("constraints.privateKey.minSize %d is larger than constraints.privateKey.maxSize %d", *pk.MinSize, *pk.MaxSize))
+		}
+	}
+
+	return reasons
+}
+
+// permitsIdentity returns whether the allowed attributes permit a request to
+// have at least one common name, subject alternative name or subject
+// attribute. Values which are all negated permit nothing.
+func permitsIdentity(allowed *policyapi.CertificateRequestPolicyAllowed) bool {
+	if allowed == nil {
+		return false
+	}
+
+	if cn := allowed.CommonName; cn != nil && ((cn.Value != nil && len(*cn.Value) > 0) || (cn.MatchDNSNames != nil && *cn.MatchDNSNames && permitsValues(allowed.DNSNames))) {
+		return true
+	}
+	for _, slice := range []*policyapi.CertificateRequestPolicyAllowedStringSlice{allowed.DNSNames, allowed.IPAddresses, allowed.URIs, allowed.EmailAddresses} {
+		if permitsValues(slice) {
+			return true
+		}
+	}
+	if allowed.EmailAddresses != nil && len(allowed.EmailAddresses.AllowedDomains) > 0 {
+		return true
+	}
+	for _, otherName := range allowed.OtherNames {
+		if len(otherName.Values) > 0 {
+			return true
+		}
+	}
+
+	if sub := allowed.Subject; sub != nil {
+		for _, slice := range []*policyapi.CertificateRequestPolicyAllowedStringSlice{sub.Organizations, sub.Countries, sub.OrganizationalUnits, sub.Localities, sub.Provinces, sub.StreetAddresses, sub.PostalCodes} {
+			if permitsValues(slice) {
+				return true
+			}
+		}
+		if sub.SerialNumber != nil && sub.SerialNumber.Value != nil && len(*sub.SerialNumber.Value) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// permitsValues returns whether the allowed field permits at least one value,
+// either because it has a value which isn't negated, or loads values from a
+// ConfigMap.
+func permitsValues(slice *policyapi.CertificateRequestPolicyAllowedStringSlice) bool {
+	if slice == nil {
+		return false
+	}
+	if slice.ValuesFrom != nil {
+		return true
+	}
+	if slice.Values == nil {
+		return false
+	}
+	positive, _ := util.SplitNegated(*slice.Values)
+	return len(positive) > 0
+}
+
+// policyReader is a client.Reader of policies held in memory, used to resolve
+// the base policies of the linted policies.
+type policyReader struct {
+	policies map[string]*policyapi.CertificateRequestPolicy
+}
+
+func newPolicyReader(policies []policyapi.CertificateRequestPolicy) client.Reader {
+	reader := &policyReader{policies: make(map[string]*policyapi.CertificateRequestPolicy, len(policies))}
+	for i := range policies {
+		reader.policies[policies[i].Name] = &policies[i]
+	}
+	return reader
+}
+
+func (p *policyReader) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	out, ok := obj.(*policyapi.CertificateRequestPolicy)
+	if !ok {
+		return fmt.Errorf("unsupported object %T", obj)
+	}
+	policy, ok := p.policies[key.Name]
+	if !ok {
+		return apierrors.NewNotFound(policyapi.SchemeGroupVersion.WithResource("certificaterequestpolicies").GroupResource(), key.Name)
+	}
+	policy.DeepCopyInto(out)
+	return nil
+}
+
+func (p *policyReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return errors.New("not implemented")
+}
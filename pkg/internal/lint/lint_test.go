@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_Lint(t *testing.T) {
+	policy := func(name string, mods ...func(*policyapi.CertificateRequestPolicy)) policyapi.CertificateRequestPolicy {
+		policy := policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}},
+				},
+			},
+		}
+		for _, mod := range mods {
+			mod(&policy)
+		}
+		return policy
+	}
+	withPriority := func(priority int) func(*policyapi.CertificateRequestPolicy) {
+		return func(policy *policyapi.CertificateRequestPolicy) { policy.Spec.Priority = pointer.Int(priority) }
+	}
+	withIssuerName := func(name string) func(*policyapi.CertificateRequestPolicy) {
+		return func(policy *policyapi.CertificateRequestPolicy) {
+			policy.Spec.Selector.IssuerRef = &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String(name)}
+		}
+	}
+	withIssuerNames := func(names ...string) func(*policyapi.CertificateRequestPolicy) {
+		return func(policy *policyapi.CertificateRequestPolicy) {
+			for _, name := range names {
+				policy.Spec.Selector.IssuerRefs = append(policy.Spec.Selector.IssuerRefs, policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String(name)})
+			}
+		}
+	}
+	withNamespaces := func(names ...string) func(*policyapi.CertificateRequestPolicy) {
+		return func(policy *policyapi.CertificateRequestPolicy) {
+			policy.Spec.Selector.Namespace = &policyapi.CertificateRequestPolicySelectorNamespace{MatchNames: names}
+		}
+	}
+
+	tests := map[string]struct {
+		policies    []policyapi.CertificateRequestPolicy
+		expFindings []Finding
+	}{
+		"if no policies exist, return no findings": {
+			policies:    nil,
+			expFindings: nil,
+		},
+		"if a policy with a higher priority selects every request of a policy, return it as shadowed": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("broad", withPriority(10), withIssuerName("team-*")),
+				policy("narrow", withIssuerName("team-a"), withNamespaces("team-a")),
+			},
+			expFindings: []Finding{
+				{
+					Policy:     "narrow",
+					Reason:     ReasonShadowed,
+					Message:    `every request selected by this policy is also selected by "broad", which has a higher priority of 10`,
+					ShadowedBy: "broad",
+				},
+			},
+		},
+		"if an authoritative policy with the same priority selects every request of a policy, return it as shadowed": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("authoritative", withNamespaces("team-*"), func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.Selector.Authoritative = true
+				}),
+				policy("team-a", withNamespaces("team-a")),
+			},
+			expFindings: []Finding{
+				{
+					Policy:     "team-a",
+					Reason:     ReasonShadowed,
+					Message:    `every request selected by this policy is also selected by "authoritative", which is authoritative with the same priority`,
+					ShadowedBy: "authoritative",
+				},
+			},
+		},
+		"if the policy with a higher priority selects only some requests of a policy, or has an active schedule, return no findings": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("team-a-issuer", withPriority(10), withIssuerName("team-a")),
+				policy("scheduled", withPriority(10), func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.ActiveSchedule = &policyapi.CertificateRequestPolicyActiveSchedule{}
+				}),
+				policy("team-namespaces", withPriority(10), withNamespaces("team-*")),
+				policy("any-issuer", withNamespaces("team-a", "other")),
+			},
+			expFindings: nil,
+		},
+		"if the issuerRefs of a policy with a higher priority cover every issuerRefs entry of a policy, return it as shadowed": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("broad", withPriority(10), withIssuerNames("team-a-*", "team-b-*")),
+				policy("narrow", withIssuerNames("team-b-ca", "team-a-ca")),
+				policy("partly", withIssuerNames("team-a-ca", "team-c-ca")),
+			},
+			expFindings: []Finding{
+				{
+					Policy:     "narrow",
+					Reason:     ReasonShadowed,
+					Message:    `every request selected by this policy is also selected by "broad", which has a higher priority of 10`,
+					ShadowedBy: "broad",
+				},
+			},
+		},
+		"if policies have the same priority and selectors, return no findings": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("a", withIssuerName("team-a")),
+				policy("b", withIssuerName("team-a")),
+			},
+			expFindings: nil,
+		},
+		"if a policy permits no identity or has contradicting constraints, return it as empty": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("negated", func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.Allowed.DNSNames.Values = &[]string{"!*.example.com"}
+				}),
+				policy("durations", func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.Constraints = &policyapi.CertificateRequestPolicyConstraints{
+						MinDuration: &metav1.Duration{Duration: time.Hour * 2},
+						MaxDuration: &metav1.Duration{Duration: time.Hour},
+					}
+				}),
+			},
+			expFindings: []Finding{
+				{
+					Policy:  "durations",
+					Reason:  ReasonEmptyAllowed,
+					Message: "this policy can't approve any request: constraints.minDuration 2h0m0s is larger than constraints.maxDuration 1h0m0s",
+				},
+				{
+					Policy:  "negated",
+					Reason:  ReasonEmptyAllowed,
+					Message: "this policy can't approve any request: allowed permits no common name, subject alternative name or subject attribute",
+				},
+			},
+		},
+		"if a policy permits identities only through its base policy, return no findings": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("base", withIssuerName("does-not-exist")),
+				policy("derived", func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.Allowed = nil
+					policy.Spec.BaseRef = &policyapi.CertificateRequestPolicyBaseRef{Name: "base"}
+				}),
+			},
+			expFindings: nil,
+		},
+		"if a policy and its base policy allow disjoint sets of durations, return it as empty": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("base", withIssuerName("does-not-exist"), func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.Constraints = &policyapi.CertificateRequestPolicyConstraints{
+						AllowedDurations: []metav1.Duration{{Duration: time.Hour * 24 * 90}},
+					}
+				}),
+				policy("derived", func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.BaseRef = &policyapi.CertificateRequestPolicyBaseRef{Name: "base"}
+					policy.Spec.Constraints = &policyapi.CertificateRequestPolicyConstraints{
+						AllowedDurations: []metav1.Duration{{Duration: time.Hour * 24 * 365}},
+					}
+				}),
+			},
+			expFindings: []Finding{
+				{
+					Policy:  "derived",
+					Reason:  ReasonEmptyAllowed,
+					Message: "this policy can't approve any request: constraints.allowedDurations permits no duration",
+				},
+			},
+		},
+		"if the base policy of a policy doesn't exist, return the invalid base reference": {
+			policies: []policyapi.CertificateRequestPolicy{
+				policy("derived", func(policy *policyapi.CertificateRequestPolicy) {
+					policy.Spec.BaseRef = &policyapi.CertificateRequestPolicyBaseRef{Name: "missing"}
+				}),
+			},
+			expFindings: []Finding{
+				{
+					Policy:  "derived",
+					Reason:  ReasonInvalidBaseRef,
+					Message: `base policies can't be resolved: failed to get base policy "missing": certificaterequestpolicies.policy.cert-manager.io "missing" not found`,
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			findings, err := Lint(context.TODO(), test.policies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expFindings, findings)
+		})
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// namespace is the prefix of all approver-policy metrics.
+	namespace = "approverpolicy"
+
+	// labelPolicy is the label holding the name of the
+	// CertificateRequestPolicy.
+	labelPolicy = "policy"
+
+	// labelIssuerKind is the label holding the `spec.issuerRef.kind` of the
+	// CertificateRequest.
+	labelIssuerKind = "issuer_kind"
+
+	// labelResult is the label holding the result of a CertificateRequest
+	// evaluation.
+	labelResult = "result"
+
+	// policyLabelPrefix is the prefix of the labels holding the
+	// `spec.metricsLabels` of the CertificateRequestPolicy.
+	policyLabelPrefix = "label_"
+
+	// policyLabelOverflowValue is the value of a policy metrics label once the
+	// maximum number of distinct values of its key has been observed.
+	policyLabelOverflowValue = "_other"
+)
+
+// Metrics are deliberately never labelled with the name of a
+// CertificateRequest, so that cardinality stays bounded by the number of
+// CertificateRequestPolicies and issuer kinds in the cluster.
+var (
+	// CertificateRequestApproved counts CertificateRequests which have been
+	// approved, labelled by the CertificateRequestPolicy which approved the
+	// request, along with any configured policy metrics labels.
+	CertificateRequestApproved = newCertificateRequestApproved(nil)
+
+	// CertificateRequestDenied counts CertificateRequests which have been
+	// denied, labelled by each CertificateRequestPolicy which denied the
+	// request, along with any configured policy metrics labels. A single
+	// request which is denied by multiple policies will increment the counter
+	// of each policy.
+	CertificateRequestDenied = newCertificateRequestDenied(nil)
+
+	// CertificateRequestWouldDeny counts CertificateRequests which have been
+	// approved only because each CertificateRequestPolicy which would have
+	// denied the request has the Warn enforcement, labelled by each of those
+	// policies.
+	CertificateRequestWouldDeny = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "certificaterequest",
+		Name:      "would_deny_total",
+		Help:      "Number of CertificateRequests approved which would have been denied, by each CertificateRequestPolicy with Warn enforcement and issuer kind.",
+	}, []string{labelPolicy, labelIssuerKind})
+
+	// CertificateRequestEvaluationDuration observes the time taken to review a
+	// CertificateRequest against all CertificateRequestPolicies.
+	CertificateRequestEvaluationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "certificaterequest",
+		Name:      "evaluation_duration_seconds",
+		Help:      "Time taken to review a CertificateRequest against CertificateRequestPolicies, by result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{labelResult})
+
+	// CertificateRequestPolicyAdmissionAllowed counts CertificateRequestPolicy
+	// admission requests which were allowed by the webhook.
+	CertificateRequestPolicyAdmissionAllowed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "certificaterequestpolicy",
+		Name:      "admission_allowed_total",
+		Help:      "Number of CertificateRequestPolicy admission requests allowed by the webhook.",
+	})
+
+	// CertificateRequestPolicyAdmissionDenied counts CertificateRequestPolicy
+	// admission requests which were denied by the webhook.
+	CertificateRequestPolicyAdmissionDenied = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "certificaterequestpolicy",
+		Name:      "admission_denied_total",
+		Help:      "Number of CertificateRequestPolicy admission requests denied by the webhook.",
+	})
+
+	// RevalidationReevaluated counts CertificateRequests which were approved
+	// but not yet issued, and have been re-evaluated by a revalidation.
+	RevalidationReevaluated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "revalidation",
+		Name:      "reevaluated_total",
+		Help:      "Number of approved CertificateRequests pending issuance which were re-evaluated by a revalidation.",
+	})
+
+	// RevalidationDenied counts CertificateRequests which were approved but
+	// not yet issued, and have been marked as Failed by a revalidation since
+	// they no longer pass policy.
+	RevalidationDenied = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "revalidation",
+		Name:      "denied_total",
+		Help:      "Number of approved CertificateRequests pending issuance which were marked as Failed by a revalidation since they no longer pass policy.",
+	})
+
+	// Leader is 1 if this replica is the elected leader, which is the only
+	// replica that approves or denies CertificateRequests, and 0 otherwise.
+	// Webhooks are served by all replicas.
+	Leader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "leader",
+		Help:      "Whether this replica is the elected leader which approves and denies CertificateRequests (1), or not (0).",
+	})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		policyCounters{},
+		CertificateRequestWouldDeny,
+		CertificateRequestEvaluationDuration,
+		CertificateRequestPolicyAdmissionAllowed,
+		CertificateRequestPolicyAdmissionDenied,
+		RevalidationReevaluated,
+		RevalidationDenied,
+		Leader,
+	)
+}
+
+// newCertificateRequestApproved returns the approved counter, with a label
+// for each of the given policy label names.
+func newCertificateRequestApproved(policyLabelNames []string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "certificaterequest",
+		Name:      "approved_total",
+		Help:      "Number of CertificateRequests approved, by the approving CertificateRequestPolicy and issuer kind.",
+	}, append([]string{labelPolicy, labelIssuerKind}, policyLabelNames...))
+}
+
+// newCertificateRequestDenied returns the denied counter, with a label for
+// each of the given policy label names.
+func newCertificateRequestDenied(policyLabelNames []string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "certificaterequest",
+		Name:      "denied_total",
+		Help:      "Number of CertificateRequests denied, by each denying CertificateRequestPolicy and issuer kind.",
+	}, append([]string{labelPolicy, labelIssuerKind}, policyLabelNames...))
+}
+
+// policyCounters collects the approved and denied counters. The counters are
+// replaced when policy labels are configured, and the registry doesn't allow a
+// metric to be re-registered with different labels, so policyCounters is
+// registered as an unchecked collector which describes no metrics.
+type policyCounters struct{}
+
+func (policyCounters) Describe(chan<- *prometheus.Desc) {}
+
+func (policyCounters) Collect(ch chan<- prometheus.Metric) {
+	policyLabels.lock.Lock()
+	approved, denied := CertificateRequestApproved, CertificateRequestDenied
+	policyLabels.lock.Unlock()
+
+	approved.Collect(ch)
+	denied.Collect(ch)
+}
+
+// policyLabels holds the configured `spec.metricsLabels` keys which the
+// approved and denied counters are labelled with, along with the distinct
+// values observed for each key.
+var policyLabels struct {
+	lock sync.Mutex
+
+	// keys are the permitted metrics label keys, in label order.
+	keys []string
+
+	// maxValues is the maximum number of distinct values observed for each
+	// key, after which values are recorded as policyLabelOverflowValue.
+	maxValues int
+
+	// values are the distinct values observed for each key.
+	values map[string]sets.Set[string]
+}
+
+// invalidLabelNameChars matches characters which are not valid in a
+// Prometheus label name.
+var invalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// PolicyLabelName returns the sanitized Prometheus label name of the given
+// `spec.metricsLabels` key.
+func PolicyLabelName(key string) string {
+	return policyLabelPrefix + invalidLabelNameChars.ReplaceAllString(key, "_")
+}
+
+// ConfigurePolicyLabels labels the approved and denied counters with the given
+// `spec.metricsLabels` keys of CertificateRequestPolicies. To bound
+// cardinality, at most maxValues distinct values are recorded for each key.
+// Should be called before any request is observed, since the counters are
+// replaced.
+// Returns an error if two keys have the same sanitized label name, or
+// maxValues is not positive.
+func ConfigurePolicyLabels(keys []string, maxValues int) error {
+	if maxValues <= 0 {
+		return fmt.Errorf("maximum number of metrics label values must be positive: %d", maxValues)
+	}
+
+	var (
+		names  []string
+		byName = make(map[string]string)
+	)
+	for _, key := range keys {
+		name := PolicyLabelName(key)
+		if other, ok := byName[name]; ok {
+			return fmt.Errorf("metrics label keys %q and %q have the same label name %q", other, key, name)
+		}
+		byName[name] = key
+		names = append(names, name)
+	}
+
+	policyLabels.lock.Lock()
+	defer policyLabels.lock.Unlock()
+
+	policyLabels.keys = append([]string(nil), keys...)
+	policyLabels.maxValues = maxValues
+	policyLabels.values = make(map[string]sets.Set[string])
+
+	CertificateRequestApproved = newCertificateRequestApproved(names)
+	CertificateRequestDenied = newCertificateRequestDenied(names)
+
+	return nil
+}
+
+// policyLabelValues returns the values of the configured policy labels for the
+// given `spec.metricsLabels`. Keys which are not set are labelled with an
+// empty value. Must be called with the policyLabels lock held.
+func policyLabelValues(metricsLabels map[string]string) []string {
+	values := make([]string, len(policyLabels.keys))
+	for i, key := range policyLabels.keys {
+		value, ok := metricsLabels[key]
+		if !ok || len(value) == 0 {
+			continue
+		}
+
+		seen, ok := policyLabels.values[key]
+		if !ok {
+			seen = sets.New[string]()
+			policyLabels.values[key] = seen
+		}
+		if !seen.Has(value) {
+			if seen.Len() >= policyLabels.maxValues {
+				value = policyLabelOverflowValue
+			} else {
+				seen.Insert(value)
+			}
+		}
+
+		values[i] = value
+	}
+
+	return values
+}
+
+// ObserveApproved increments the approved counter for the given policy and
+// request. The policy's metricsLabels are recorded for the configured keys.
+func ObserveApproved(policyName string, metricsLabels map[string]string, cr *cmapi.CertificateRequest) {
+	policyLabels.lock.Lock()
+	defer policyLabels.lock.Unlock()
+	CertificateRequestApproved.WithLabelValues(append([]string{policyName, issuerKind(cr)}, policyLabelValues(metricsLabels)...)...).Inc()
+}
+
+// ObserveDenied increments the denied counter for the given policy and
+// request. The policy's metricsLabels are recorded for the configured keys.
+func ObserveDenied(policyName string, metricsLabels map[string]string, cr *cmapi.CertificateRequest) {
+	policyLabels.lock.Lock()
+	defer policyLabels.lock.Unlock()
+	CertificateRequestDenied.WithLabelValues(append([]string{policyName, issuerKind(cr)}, policyLabelValues(metricsLabels)...)...).Inc()
+}
+
+// ObserveWouldDeny increments the would deny counter for the given policy and
+// request.
+func ObserveWouldDeny(policyName string, cr *cmapi.CertificateRequest) {
+	CertificateRequestWouldDeny.WithLabelValues(policyName, issuerKind(cr)).Inc()
+}
+
+// ObserveRevalidation adds the number of requests re-evaluated by a
+// revalidation, and the number of those which were denied.
+func ObserveRevalidation(reevaluated, denied int) {
+	RevalidationReevaluated.Add(float64(reevaluated))
+	RevalidationDenied.Add(float64(denied))
+}
+
+// SetLeader sets whether this replica is the elected leader.
+func SetLeader(leader bool) {
+	if leader {
+		Leader.Set(1)
+	} else {
+		Leader.Set(0)
+	}
+}
+
+// ObserveEvaluationDuration observes the time taken since start to review a
+// request with the given result.
+func ObserveEvaluationDuration(result string, start time.Time) {
+	CertificateRequestEvaluationDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
+
+// issuerKind returns the issuer kind of the request. An empty kind is
+// defaulted to "Issuer", matching cert-manager.
+func issuerKind(cr *cmapi.CertificateRequest) string {
+	if len(cr.Spec.IssuerRef.Kind) == 0 {
+		return cmapi.IssuerKind
+	}
+	return cr.Spec.IssuerRef.Kind
+}
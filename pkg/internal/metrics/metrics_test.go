@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func Test_Observe(t *testing.T) {
+	tests := map[string]struct {
+		observe           func()
+		expApproved       map[[2]string]float64
+		expDenied         map[[2]string]float64
+		expDurationSeries int
+	}{
+		"approved request with issuer kind should be labelled with policy and kind": {
+			observe: func() {
+				cr := gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca", Kind: "ClusterIssuer"}))
+				ObserveApproved("policy-a", nil, cr)
+				ObserveApproved("policy-a", nil, cr)
+			},
+			expApproved: map[[2]string]float64{{"policy-a", "ClusterIssuer"}: 2},
+			expDenied:   map[[2]string]float64{{"policy-a", "ClusterIssuer"}: 0},
+		},
+		"denied request with no issuer kind should be labelled as Issuer": {
+			observe: func() {
+				cr := gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"}))
+				ObserveDenied("policy-b", nil, cr)
+				ObserveDenied("policy-c", nil, cr)
+			},
+			expApproved: map[[2]string]float64{{"policy-b", cmapi.IssuerKind}: 0},
+			expDenied: map[[2]string]float64{
+				{"policy-b", cmapi.IssuerKind}: 1,
+				{"policy-c", cmapi.IssuerKind}: 1,
+			},
+		},
+		"evaluation duration should be observed by result": {
+			observe: func() {
+				ObserveEvaluationDuration("approved", time.Now())
+				ObserveEvaluationDuration("denied", time.Now())
+				ObserveEvaluationDuration("denied", time.Now())
+			},
+			expDurationSeries: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			CertificateRequestApproved.Reset()
+			CertificateRequestDenied.Reset()
+			CertificateRequestEvaluationDuration.Reset()
+
+			test.observe()
+
+			for labels, exp := range test.expApproved {
+				assert.Equal(t, exp, testutil.ToFloat64(CertificateRequestApproved.WithLabelValues(labels[0], labels[1])), "approved %v", labels)
+			}
+			for labels, exp := range test.expDenied {
+				assert.Equal(t, exp, testutil.ToFloat64(CertificateRequestDenied.WithLabelValues(labels[0], labels[1])), "denied %v", labels)
+			}
+			assert.Equal(t, test.expDurationSeries, testutil.CollectAndCount(CertificateRequestEvaluationDuration), "number of observed results")
+		})
+	}
+}
+
+func Test_ConfigurePolicyLabels(t *testing.T) {
+	t.Cleanup(func() {
+		if err := ConfigurePolicyLabels(nil, 1); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	assert.Error(t, ConfigurePolicyLabels([]string{"team"}, 0), "expected error for non-positive max values")
+	assert.Error(t, ConfigurePolicyLabels([]string{"cost-centre", "cost_centre"}, 1), "expected error for keys with the same label name")
+
+	if err := ConfigurePolicyLabels([]string{"team", "example.com/cost-centre"}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	cr := gen.CertificateRequest("", gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca", Kind: "ClusterIssuer"}))
+	ObserveApproved("policy-a", map[string]string{"team": "payments", "example.com/cost-centre": "cc-1", "ignored": "foo"}, cr)
+	ObserveApproved("policy-b", map[string]string{"team": "search"}, cr)
+	// A third distinct value of team exceeds the maximum of 2 values, so is
+	// recorded as the overflow value.
+	ObserveApproved("policy-c", map[string]string{"team": "billing"}, cr)
+	ObserveApproved("policy-a", map[string]string{"team": "payments", "example.com/cost-centre": "cc-1"}, cr)
+	ObserveDenied("policy-d", nil, cr)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(CertificateRequestApproved.WithLabelValues("policy-a", "ClusterIssuer", "payments", "cc-1")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(CertificateRequestApproved.WithLabelValues("policy-b", "ClusterIssuer", "search", "")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(CertificateRequestApproved.WithLabelValues("policy-c", "ClusterIssuer", "_other", "")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(CertificateRequestDenied.WithLabelValues("policy-d", "ClusterIssuer", "", "")))
+
+	assert.Equal(t, "label_example_com_cost_centre", PolicyLabelName("example.com/cost-centre"))
+
+	_, err := ctrlmetrics.Registry.Gather()
+	assert.NoError(t, err, "expected reconfigured counters to be gathered")
+}
+
+func Test_SetLeader(t *testing.T) {
+	SetLeader(true)
+	assert.Equal(t, 1.0, testutil.ToFloat64(Leader))
+	SetLeader(false)
+	assert.Equal(t, 0.0, testutil.ToFloat64(Leader))
+}
+
+func Test_ObserveRevalidation(t *testing.T) {
+	reevaluated, denied := testutil.ToFloat64(RevalidationReevaluated), testutil.ToFloat64(RevalidationDenied)
+	ObserveRevalidation(3, 1)
+	assert.Equal(t, reevaluated+3, testutil.ToFloat64(RevalidationReevaluated))
+	assert.Equal(t, denied+1, testutil.ToFloat64(RevalidationDenied))
+}
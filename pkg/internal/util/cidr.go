@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net"
+	"strings"
+)
+
+// IsCIDR returns whether the given pattern should be treated as a CIDR range,
+// i.e. contains a "/". This does not check whether the CIDR is valid.
+func IsCIDR(pattern string) bool {
+	return strings.Contains(pattern, "/")
+}
+
+// IPMatches will return true if the given IP address matches the pattern. If
+// the pattern is a CIDR range (e.g. "10.0.0.0/8" or "fd00::/8"), the IP
+// address matches if it is contained within the range. Otherwise the pattern
+// is matched as a wildcard pattern ('*'). A CIDR which fails to parse, or an
+// IP address which fails to parse, never matches a CIDR pattern.
+func IPMatches(pattern, ip string) bool {
+	if !IsCIDR(pattern) {
+		return WildcardMatches(pattern, ip)
+	}
+
+	_, ipNet, err := net.ParseCIDR(pattern)
+	if err != nil {
+		return false
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	return ipNet.Contains(parsed)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func Test_IPMatches(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		ip      string
+		exp     bool
+	}{
+		"literal IP: true": {
+			pattern: "10.0.0.1",
+			ip:      "10.0.0.1",
+			exp:     true,
+		},
+		"wildcard IP: true": {
+			pattern: "10.0.0.*",
+			ip:      "10.0.0.1",
+			exp:     true,
+		},
+		"IPv4 CIDR containing IP: true": {
+			pattern: "10.0.0.0/8",
+			ip:      "10.20.30.40",
+			exp:     true,
+		},
+		"IPv4 CIDR not containing IP: false": {
+			pattern: "10.0.0.0/8",
+			ip:      "11.0.0.1",
+			exp:     false,
+		},
+		"IPv6 CIDR containing IP: true": {
+			pattern: "fd00::/8",
+			ip:      "fd12:3456::1",
+			exp:     true,
+		},
+		"IPv6 CIDR not containing IP: false": {
+			pattern: "fd00::/8",
+			ip:      "2001:db8::1",
+			exp:     false,
+		},
+		"IPv4 CIDR with IPv6 IP: false": {
+			pattern: "10.0.0.0/8",
+			ip:      "2001:db8::1",
+			exp:     false,
+		},
+		"invalid CIDR: false": {
+			pattern: "10.0.0.0/33",
+			ip:      "10.0.0.1",
+			exp:     false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if match := IPMatches(test.pattern, test.ip); match != test.exp {
+				t.Errorf("unexpected match (%q, %q): exp=%t got=%t",
+					test.pattern, test.ip, test.exp, match)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	utilpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+)
+
+// MaxCSRPEMSize is the maximum size in bytes of a PEM encoded CSR which will be
+// decoded. Requests larger than this are rejected without being parsed to
+// avoid excessive memory use on adversarial input.
+const MaxCSRPEMSize = 64 * 1024
+
+// oidExtensionSubjectAltName is the object identifier of the subject
+// alternative name extension.
+var oidExtensionSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+// OtherName is an otherName SAN, identified by the object identifier of its
+// type, such as the User Principal Name (UPN) 1.3.6.1.4.1.311.20.2.3.
+type OtherName struct {
+	OID   string
+	Value string
+}
+
+// DecodeCSR decodes the given PEM encoded CSR of a CertificateRequest. An
+// error is returned if the request is larger than MaxCSRPEMSize, or is not a
+// valid PEM encoded CSR. Panics raised while parsing malformed input are
+// recovered and returned as an error.
+func DecodeCSR(request []byte) (csr *x509.CertificateRequest, err error) {
+	if len(request) > MaxCSRPEMSize {
+		return nil, fmt.Errorf("request of %d bytes exceeds the maximum size of %d bytes", len(request), MaxCSRPEMSize)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			csr, err = nil, fmt.Errorf("failed to decode request: %v", r)
+		}
+	}()
+
+	return utilpki.DecodeX509CertificateRequestBytes(request)
+}
+
+// DecodeBasicConstraints returns the CA value and maximum path length of the
+// basic constraints extension in the given CSR. The maximum path length is -1
+// if not present. Returns false for ok if the CSR does not contain a basic
+// constraints extension.
+func DecodeBasicConstraints(csr *x509.CertificateRequest) (bool, int, bool, error) {
+	for _, ext := range csr.Extensions {
+		if !ext.Id.Equal(utilpki.OIDExtensionBasicConstraints) {
+			continue
+		}
+
+		var constraint struct {
+			IsCA       bool `asn1:"optional"`
+			MaxPathLen int  `asn1:"optional,default:-1"`
+		}
+		if _, err := asn1.Unmarshal(ext.Value, &constraint); err != nil {
+			return false, -1, false, fmt.Errorf("failed to decode basic constraints extension: %w", err)
+		}
+		return constraint.IsCA, constraint.MaxPathLen, true, nil
+	}
+
+	return false, -1, false, nil
+}
+
+// DecodeUsages returns the usages the CSR requests in its key usage and
+// extended key usage extensions, as cert-manager usages. Extended key usages
+// which have no cert-manager usage are returned as their dotted OID.
+func DecodeUsages(csr *x509.CertificateRequest) ([]cmapi.KeyUsage, error) {
+	var usages []cmapi.KeyUsage
+	for _, ext := range csr.Extensions {
+		switch {
+		case ext.Id.Equal(utilpki.OIDExtensionKeyUsage):
+			var bits asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &bits); err != nil {
+				return nil, fmt.Errorf("failed to decode key usage extension: %w", err)
+			}
+			var ku x509.KeyUsage
+			for i := 0; i < bits.BitLength; i++ {
+				if bits.At(i) == 1 {
+					ku |= 1 << uint(i)
+				}
+			}
+			usages = append(usages, apiutil.KeyUsageStrings(ku)...)
+
+		case ext.Id.Equal(utilpki.OIDExtensionExtendedKeyUsage):
+			var oids []asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(ext.Value, &oids); err != nil {
+				return nil, fmt.Errorf("failed to decode extended key usage extension: %w", err)
+			}
+			for _, oid := range oids {
+				if eku, ok := utilpki.ExtKeyUsageFromOID(oid); ok {
+					usages = append(usages, apiutil.ExtKeyUsageStrings([]x509.ExtKeyUsage{eku})...)
+				} else {
+					usages = append(usages, cmapi.KeyUsage(oid.String()))
+				}
+			}
+		}
+	}
+
+	return usages, nil
+}
+
+// DecodeOtherNames returns the otherName SANs of the subject alternative name
+// extension in the given CSR, which are ignored by the standard library. An
+// error is returned if the extension is malformed, or if the value of an
+// otherName is not an ASN.1 string.
+func DecodeOtherNames(csr *x509.CertificateRequest) ([]OtherName, error) {
+	names, err := decodeGeneralNames(csr)
+	if err != nil {
+		return nil, err
+	}
+
+	var otherNames []OtherName
+	for _, name := range names {
+		// otherName is the GeneralName of context-specific tag 0.
+		if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+			continue
+		}
+
+		var otherName struct {
+			TypeID asn1.ObjectIdentifier
+			Value  asn1.RawValue
+		}
+		if _, err := asn1.UnmarshalWithParams(name.FullBytes, &otherName, "tag:0"); err != nil {
+			return nil, fmt.Errorf("failed to decode otherName: %w", err)
+		}
+		// The value is explicitly tagged with context-specific tag 0.
+		if otherName.Value.Class != asn1.ClassContextSpecific || otherName.Value.Tag != 0 || !otherName.Value.IsCompound {
+			return nil, fmt.Errorf("failed to decode otherName %s: value is not explicitly tagged", otherName.TypeID)
+		}
+
+		var value string
+		if _, err := asn1.Unmarshal(otherName.Value.Bytes, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode value of otherName %s as a string: %w", otherName.TypeID, err)
+		}
+		otherNames = append(otherNames, OtherName{OID: otherName.TypeID.String(), Value: value})
+	}
+
+	return otherNames, nil
+}
+
+// CountSANs returns the number of subject alternative names of any type in
+// the given CSR, including those ignored by the standard library such as
+// otherNames. An error is returned if the extension is malformed.
+func CountSANs(csr *x509.CertificateRequest) (int, error) {
+	names, err := decodeGeneralNames(csr)
+	if err != nil {
+		return 0, err
+	}
+	return len(names), nil
+}
+
+// decodeGeneralNames returns the GeneralNames of the subject alternative name
+// extension in the given CSR, undecoded.
+func decodeGeneralNames(csr *x509.CertificateRequest) ([]asn1.RawValue, error) {
+	var generalNames []asn1.RawValue
+	for _, ext := range csr.Extensions {
+		if !ext.Id.Equal(oidExtensionSubjectAltName) {
+			continue
+		}
+
+		var names []asn1.RawValue
+		if rest, err := asn1.Unmarshal(ext.Value, &names); err != nil {
+			return nil, fmt.Errorf("failed to decode subject alternative name extension: %w", err)
+		} else if len(rest) > 0 {
+			return nil, errors.New("failed to decode subject alternative name extension: trailing data")
+		}
+		generalNames = append(generalNames, names...)
+	}
+	return generalNames, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	utilpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DecodeUsages(t *testing.T) {
+	// csrFor returns the CSR cert-manager generates for a Certificate with the
+	// given usages.
+	csrFor := func(t *testing.T, isCA bool, usages ...cmapi.KeyUsage) *x509.CertificateRequest {
+		csr, err := utilpki.GenerateCSR(gen.Certificate("",
+			gen.SetCertificateCommonName("example.com"),
+			gen.SetCertificateIsCA(isCA),
+			gen.SetCertificateKeyUsages(usages...),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return csr
+	}
+
+	unknownEKU, err := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 4, 1, 99999, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		csr *x509.CertificateRequest
+
+		expUsages []cmapi.KeyUsage
+		expErr    bool
+	}{
+		"if the CSR has no usage extensions, return no usages": {
+			csr:       new(x509.CertificateRequest),
+			expUsages: nil,
+		},
+		"if the CSR has key usages and extended key usages, return both": {
+			csr:       csrFor(t, false, cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth, cmapi.UsageClientAuth),
+			expUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth, cmapi.UsageClientAuth},
+		},
+		"if the CSR is of a CA, return the cert sign usage": {
+			csr:       csrFor(t, true, cmapi.UsageDigitalSignature),
+			expUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageCertSign},
+		},
+		"if the CSR has an unknown extended key usage, return its OID": {
+			csr: &x509.CertificateRequest{Extensions: []pkix.Extension{
+				{Id: utilpki.OIDExtensionExtendedKeyUsage, Value: unknownEKU},
+			}},
+			expUsages: []cmapi.KeyUsage{"1.3.6.1.4.1.99999.1"},
+		},
+		"if the CSR has a malformed key usage extension, return error": {
+			csr: &x509.CertificateRequest{Extensions: []pkix.Extension{
+				{Id: utilpki.OIDExtensionKeyUsage, Value: []byte{0x05, 0x00}},
+			}},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Extensions of generated CSRs are only populated once parsed.
+			csr := test.csr
+			if len(csr.ExtraExtensions) > 0 {
+				csr.Extensions = csr.ExtraExtensions
+			}
+
+			usages, err := DecodeUsages(csr)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expUsages, usages)
+		})
+	}
+}
+
+func Test_DecodeOtherNames(t *testing.T) {
+	upn := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+
+	// otherName returns the GeneralName of an otherName with the given value.
+	otherName := func(t *testing.T, oid asn1.ObjectIdentifier, value interface{}, params string) asn1.RawValue {
+		encoded, err := asn1.MarshalWithParams(value, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		full, err := asn1.MarshalWithParams(struct {
+			TypeID asn1.ObjectIdentifier
+			Value  asn1.RawValue
+		}{oid, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: encoded}}, "tag:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return asn1.RawValue{FullBytes: full}
+	}
+
+	// csrWith returns a CSR with a subject alternative name extension of the
+	// given names.
+	csrWith := func(t *testing.T, names ...asn1.RawValue) *x509.CertificateRequest {
+		value, err := asn1.Marshal(names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &x509.CertificateRequest{Extensions: []pkix.Extension{
+			{Id: oidExtensionSubjectAltName, Value: value},
+		}}
+	}
+
+	dnsName := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte("example.com")}
+
+	tests := map[string]struct {
+		csr *x509.CertificateRequest
+
+		expOtherNames []OtherName
+		expErr        bool
+	}{
+		"if the CSR has no subject alternative name extension, return no otherNames": {
+			csr:           new(x509.CertificateRequest),
+			expOtherNames: nil,
+		},
+		"if the CSR has no otherNames, return no otherNames": {
+			csr:           csrWith(t, dnsName),
+			expOtherNames: nil,
+		},
+		"if the CSR has a UPN otherName among other SANs, return it": {
+			csr:           csrWith(t, dnsName, otherName(t, upn, "user@example.com", "utf8")),
+			expOtherNames: []OtherName{{OID: "1.3.6.1.4.1.311.20.2.3", Value: "user@example.com"}},
+		},
+		"if the CSR has an otherName whose value is not a string, return error": {
+			csr:    csrWith(t, otherName(t, upn, 42, "")),
+			expErr: true,
+		},
+		"if the CSR has a malformed subject alternative name extension, return error": {
+			csr: &x509.CertificateRequest{Extensions: []pkix.Extension{
+				{Id: oidExtensionSubjectAltName, Value: []byte{0x05, 0x00}},
+			}},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			otherNames, err := DecodeOtherNames(test.csr)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expOtherNames, otherNames)
+		})
+	}
+}
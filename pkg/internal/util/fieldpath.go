@@ -0,0 +1,33 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"regexp"
+)
+
+// fieldPathSubscript matches the index or key subscripts of a field path.
+var fieldPathSubscript = regexp.MustCompile(`\[[^\]]*\]`)
+
+// FieldPathWithoutSubscripts returns the given field path with all index and
+// key subscripts removed, so that it identifies the field of the policy
+// regardless of which entry caused an error. For example,
+// "spec.constraints.durationByKeySize[0].maxDuration" becomes
+// "spec.constraints.durationByKeySize.maxDuration".
+func FieldPathWithoutSubscripts(path string) string {
+	return fieldPathSubscript.ReplaceAllString(path, "")
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func Test_FieldPathWithoutSubscripts(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		exp  string
+	}{
+		"a path without subscripts should be unchanged": {
+			path: "spec.constraints.maxDuration",
+			exp:  "spec.constraints.maxDuration",
+		},
+		"a path with an index should have the index removed": {
+			path: "spec.constraints.durationByKeySize[0].maxDuration",
+			exp:  "spec.constraints.durationByKeySize.maxDuration",
+		},
+		"a path with a key should have the key removed": {
+			path: "spec.allowed.annotations[example.com/team].value",
+			exp:  "spec.allowed.annotations.value",
+		},
+		"a path with several subscripts should have all removed": {
+			path: "spec.allowed.otherNames[1].values[2]",
+			exp:  "spec.allowed.otherNames.values",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := FieldPathWithoutSubscripts(test.path); got != test.exp {
+				t.Errorf("unexpected path (%s): exp=%s got=%s", test.path, test.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cert-manager/cert-manager/pkg/apis/certmanager"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// IssuerRefSelectors returns the issuerRef selectors of the policy selector:
+// either the single `issuerRef`, or the entries of `issuerRefs`. Returns nil
+// if neither is defined, in which case the selector matches any issuer.
+func IssuerRefSelectors(selector *policyapi.CertificateRequestPolicySelector) []policyapi.CertificateRequestPolicySelectorIssuerRef {
+	if selector.IssuerRef != nil {
+		return []policyapi.CertificateRequestPolicySelectorIssuerRef{*selector.IssuerRef}
+	}
+	return selector.IssuerRefs
+}
+
+// issuerMetadataCacheKey is the context key of an issuerMetadataCache.
+type issuerMetadataCacheKey struct{}
+
+// issuerMetadataCache holds the result of getting the issuer of a single
+// request.
+type issuerMetadataCache struct {
+	once   sync.Once
+	issuer *metav1.PartialObjectMetadata
+	found  bool
+	err    error
+}
+
+// WithIssuerMetadataCache returns a context in which GetIssuerMetadata only
+// gets the issuer once, however many policies are evaluated. The returned
+// context must only be used to review a single request.
+func WithIssuerMetadataCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, issuerMetadataCacheKey{}, new(issuerMetadataCache))
+}
+
+// GetIssuerMetadata returns the metadata of the cert-manager.io Issuer or
+// ClusterIssuer referenced by the request. Returns false if the referenced
+// issuer doesn't exist or is not a cert-manager.io Issuer or ClusterIssuer.
+// If the context was returned by WithIssuerMetadataCache, the issuer is only
+// got once for the context, and the returned metadata must not be modified.
+func GetIssuerMetadata(ctx context.Context, lister client.Reader, cr *cmapi.CertificateRequest) (*metav1.PartialObjectMetadata, bool, error) {
+	cache, ok := ctx.Value(issuerMetadataCacheKey{}).(*issuerMetadataCache)
+	if !ok {
+		return getIssuerMetadata(ctx, lister, cr)
+	}
+	cache.once.Do(func() {
+		cache.issuer, cache.found, cache.err = getIssuerMetadata(ctx, lister, cr)
+	})
+	return cache.issuer, cache.found, cache.err
+}
+
+// getIssuerMetadata gets the issuer referenced by the request for
+// GetIssuerMetadata.
+func getIssuerMetadata(ctx context.Context, lister client.Reader, cr *cmapi.CertificateRequest) (*metav1.PartialObjectMetadata, bool, error) {
+	issRef := cr.Spec.IssuerRef
+	if len(issRef.Group) > 0 && issRef.Group != certmanager.GroupName {
+		return nil, false, nil
+	}
+
+	var key client.ObjectKey
+	switch issRef.Kind {
+	case "", cmapi.IssuerKind:
+		key = client.ObjectKey{Namespace: cr.Namespace, Name: issRef.Name}
+		issRef.Kind = cmapi.IssuerKind
+	case cmapi.ClusterIssuerKind:
+		key = client.ObjectKey{Name: issRef.Name}
+	default:
+		return nil, false, nil
+	}
+
+	issuer := new(metav1.PartialObjectMetadata)
+	issuer.SetGroupVersionKind(cmapi.SchemeGroupVersion.WithKind(issRef.Kind))
+	if err := lister.Get(ctx, key, issuer); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return issuer, true, nil
+}
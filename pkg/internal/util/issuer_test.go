@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// countingReader is a client.Reader which counts the number of Gets.
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (c *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	c.gets++
+	return c.Reader.Get(ctx, key, obj, opts...)
+}
+
+func Test_GetIssuerMetadata(t *testing.T) {
+	request := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test-req"},
+		Spec: cmapi.CertificateRequestSpec{
+			IssuerRef: cmmeta.ObjectReference{Name: "test-issuer", Kind: "Issuer", Group: "cert-manager.io"},
+		},
+	}
+
+	tests := map[string]struct {
+		cached  bool
+		expGets int
+	}{
+		"if the context has no issuer metadata cache, expect the issuer to be got every call": {
+			cached:  false,
+			expGets: 3,
+		},
+		"if the context has an issuer metadata cache, expect the issuer to be got once": {
+			cached:  true,
+			expGets: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lister := &countingReader{Reader: fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithObjects(&cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test-issuer"}}).
+				Build(),
+			}
+
+			ctx := context.TODO()
+			if test.cached {
+				ctx = WithIssuerMetadataCache(ctx)
+			}
+
+			for i := 0; i < 3; i++ {
+				issuer, found, err := GetIssuerMetadata(ctx, lister, request)
+				assert.NoError(t, err)
+				assert.True(t, found)
+				assert.Equal(t, "test-issuer", issuer.Name)
+			}
+			assert.Equal(t, test.expGets, lister.gets, "unexpected number of gets")
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// MatchFunc returns the function which matches requested values with allowed
+// values of the given match type. The wildcard function of the field is used
+// for the Wildcard match type, or if the match type is nil. Unknown match
+// types never match.
+func MatchFunc(matchType *policyapi.AllowedMatchType, wildcard func(pattern, str string) bool) func(pattern, str string) bool {
+	if matchType == nil {
+		return wildcard
+	}
+
+	switch *matchType {
+	case policyapi.AllowedMatchTypeWildcard:
+		return wildcard
+	case policyapi.AllowedMatchTypeExact:
+		return func(pattern, str string) bool { return pattern == str }
+	case policyapi.AllowedMatchTypePrefix:
+		return func(pattern, str string) bool { return strings.HasPrefix(str, pattern) }
+	case policyapi.AllowedMatchTypeSuffix:
+		return func(pattern, str string) bool { return strings.HasSuffix(str, pattern) }
+	case policyapi.AllowedMatchTypeRegex:
+		return RegexMatches
+	default:
+		return func(string, string) bool { return false }
+	}
+}
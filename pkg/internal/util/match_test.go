@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_MatchFunc(t *testing.T) {
+	matchType := func(m policyapi.AllowedMatchType) *policyapi.AllowedMatchType {
+		return &m
+	}
+
+	type matchCase struct {
+		pattern string
+		str     string
+	}
+
+	var (
+		// dnsNames are matched with PatternMatches as their wildcard function,
+		// and uris with URIMatches.
+		dnsExact       = matchCase{"foo.example.com", "foo.example.com"}
+		dnsSubstring   = matchCase{"example", "foo.example.com"}
+		dnsPrefix      = matchCase{"foo.", "foo.example.com"}
+		dnsSuffix      = matchCase{".example.com", "foo.example.com"}
+		dnsWildcard    = matchCase{"*.example.com", "foo.example.com"}
+		dnsRegex       = matchCase{`[a-z]+\.example\.com`, "foo.example.com"}
+		dnsPrefixRegex = matchCase{`regex:[a-z]+\.example\.com`, "foo.example.com"}
+		uriPrefix      = matchCase{"spiffe://cluster.local/ns/foo/", "spiffe://cluster.local/ns/foo/sa/bar"}
+		uriWildcard    = matchCase{"spiffe://cluster.local/ns/*/sa/*", "spiffe://cluster.local/ns/foo/sa/bar"}
+		uriRegex       = matchCase{`spiffe://cluster\.local/ns/[^/]+/sa/bar`, "spiffe://cluster.local/ns/foo/sa/bar"}
+	)
+
+	// tests is a matrix of match types, and whether each case matches with
+	// that match type.
+	tests := map[string]struct {
+		matchType *policyapi.AllowedMatchType
+		wildcard  func(pattern, str string) bool
+		exp       map[matchCase]bool
+	}{
+		"dnsNames nil": {
+			matchType: nil,
+			wildcard:  PatternMatches,
+			exp: map[matchCase]bool{
+				dnsExact: true, dnsSubstring: false, dnsPrefix: false, dnsSuffix: false,
+				dnsWildcard: true, dnsRegex: false, dnsPrefixRegex: true,
+			},
+		},
+		"dnsNames Wildcard": {
+			matchType: matchType(policyapi.AllowedMatchTypeWildcard),
+			wildcard:  PatternMatches,
+			exp: map[matchCase]bool{
+				dnsExact: true, dnsSubstring: false, dnsPrefix: false, dnsSuffix: false,
+				dnsWildcard: true, dnsRegex: false, dnsPrefixRegex: true,
+			},
+		},
+		"dnsNames Exact": {
+			matchType: matchType(policyapi.AllowedMatchTypeExact),
+			wildcard:  PatternMatches,
+			exp: map[matchCase]bool{
+				dnsExact: true, dnsSubstring: false, dnsPrefix: false, dnsSuffix: false,
+				dnsWildcard: false, dnsRegex: false, dnsPrefixRegex: false,
+			},
+		},
+		"dnsNames Prefix": {
+			matchType: matchType(policyapi.AllowedMatchTypePrefix),
+			wildcard:  PatternMatches,
+			exp: map[matchCase]bool{
+				dnsExact: true, dnsSubstring: false, dnsPrefix: true, dnsSuffix: false,
+				dnsWildcard: false, dnsRegex: false, dnsPrefixRegex: false,
+			},
+		},
+		"dnsNames Suffix": {
+			matchType: matchType(policyapi.AllowedMatchTypeSuffix),
+			wildcard:  PatternMatches,
+			exp: map[matchCase]bool{
+				dnsExact: true, dnsSubstring: false, dnsPrefix: false, dnsSuffix: true,
+				dnsWildcard: false, dnsRegex: false, dnsPrefixRegex: false,
+			},
+		},
+		"dnsNames Regex": {
+			matchType: matchType(policyapi.AllowedMatchTypeRegex),
+			wildcard:  PatternMatches,
+			exp: map[matchCase]bool{
+				// "." in the exact pattern matches any character.
+				dnsExact: true, dnsSubstring: false, dnsPrefix: false, dnsSuffix: false,
+				dnsWildcard: false, dnsRegex: true, dnsPrefixRegex: false,
+			},
+		},
+		"dnsNames unknown": {
+			matchType: matchType("Unknown"),
+			wildcard:  PatternMatches,
+			exp: map[matchCase]bool{
+				dnsExact: false, dnsSubstring: false, dnsPrefix: false, dnsSuffix: false,
+				dnsWildcard: false, dnsRegex: false, dnsPrefixRegex: false,
+			},
+		},
+		"uris nil": {
+			matchType: nil,
+			wildcard:  URIMatches,
+			exp:       map[matchCase]bool{uriPrefix: false, uriWildcard: true, uriRegex: false},
+		},
+		"uris Exact": {
+			matchType: matchType(policyapi.AllowedMatchTypeExact),
+			wildcard:  URIMatches,
+			exp:       map[matchCase]bool{uriPrefix: false, uriWildcard: false, uriRegex: false},
+		},
+		"uris Prefix": {
+			matchType: matchType(policyapi.AllowedMatchTypePrefix),
+			wildcard:  URIMatches,
+			exp:       map[matchCase]bool{uriPrefix: true, uriWildcard: false, uriRegex: false},
+		},
+		"uris Suffix": {
+			matchType: matchType(policyapi.AllowedMatchTypeSuffix),
+			wildcard:  URIMatches,
+			exp:       map[matchCase]bool{uriPrefix: false, uriWildcard: false, uriRegex: false},
+		},
+		"uris Wildcard": {
+			matchType: matchType(policyapi.AllowedMatchTypeWildcard),
+			wildcard:  URIMatches,
+			exp:       map[matchCase]bool{uriPrefix: false, uriWildcard: true, uriRegex: false},
+		},
+		"uris Regex": {
+			matchType: matchType(policyapi.AllowedMatchTypeRegex),
+			wildcard:  URIMatches,
+			exp:       map[matchCase]bool{uriPrefix: false, uriWildcard: false, uriRegex: true},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matches := MatchFunc(test.matchType, test.wildcard)
+			for c, exp := range test.exp {
+				if match := matches(c.pattern, c.str); match != exp {
+					t.Errorf("unexpected match (%q, %q): exp=%t got=%t", c.pattern, c.str, exp, match)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+)
+
+// NegationPrefix is the prefix of a pattern which marks that pattern as a
+// negated, or deny, pattern. For example, "!internal.example.com".
+const NegationPrefix = "!"
+
+// IsNegated returns whether the given pattern is a negated pattern, i.e. is
+// prefixed with NegationPrefix.
+func IsNegated(pattern string) bool {
+	return strings.HasPrefix(pattern, NegationPrefix)
+}
+
+// SplitNegated splits the given patterns into the positive (allow) patterns,
+// and the negated (deny) patterns. The NegationPrefix is removed from the
+// returned negated patterns.
+func SplitNegated(patterns []string) ([]string, []string) {
+	var positive, negated []string
+	for _, pattern := range patterns {
+		if IsNegated(pattern) {
+			negated = append(negated, strings.TrimPrefix(pattern, NegationPrefix))
+		} else {
+			positive = append(positive, pattern)
+		}
+	}
+	return positive, negated
+}
+
+// NegatedSubset returns whether the members is a subset of patterns, where
+// patterns may contain negated patterns prefixed with NegationPrefix. The
+// matches func is used to match a single pattern against a member.
+//
+// Each member is first checked against all negated patterns. If any negated
+// pattern matches, the member is rejected immediately, regardless of any
+// positive pattern also matching. Only then is the member checked against the
+// positive patterns, where at least one must match for the member to be
+// accepted. This means that a negated pattern always takes precedence over a
+// positive pattern, regardless of the order they are defined in.
+func NegatedSubset(patterns, members []string, matches func(pattern, str string) bool) bool {
+	positive, negated := SplitNegated(patterns)
+
+	for _, member := range members {
+		for _, pattern := range negated {
+			if matches(pattern, member) {
+				return false
+			}
+		}
+
+		var matched bool
+		for _, pattern := range positive {
+			if matches(pattern, member) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func Test_NegatedSubset(t *testing.T) {
+	tests := map[string]struct {
+		patterns []string
+		texts    []string
+		exp      bool
+	}{
+		"no negated patterns behaves as a wildcard subset: true": {
+			patterns: []string{"*.example.com"},
+			texts:    []string{"foo.example.com", "bar.example.com"},
+			exp:      true,
+		},
+		"member matching a positive wildcard and a negated literal: false": {
+			patterns: []string{"*.example.com", "!internal.example.com"},
+			texts:    []string{"foo.example.com", "internal.example.com"},
+			exp:      false,
+		},
+		"negated pattern takes precedence regardless of order: false": {
+			patterns: []string{"!internal.example.com", "*.example.com"},
+			texts:    []string{"internal.example.com"},
+			exp:      false,
+		},
+		"negated wildcard pattern: false": {
+			patterns: []string{"*.example.com", "!*.internal.example.com"},
+			texts:    []string{"foo.internal.example.com"},
+			exp:      false,
+		},
+		"member not matching negated pattern but matching positive: true": {
+			patterns: []string{"*.example.com", "!internal.example.com"},
+			texts:    []string{"foo.example.com"},
+			exp:      true,
+		},
+		"member matching neither negated nor positive pattern: false": {
+			patterns: []string{"*.example.com", "!internal.example.com"},
+			texts:    []string{"foo.example.net"},
+			exp:      false,
+		},
+		"only negated patterns: false": {
+			patterns: []string{"!internal.example.com"},
+			texts:    []string{"foo.example.com"},
+			exp:      false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if match := NegatedSubset(test.patterns, test.texts, WildcardMatches); match != test.exp {
+				t.Errorf("unexpected subset (%v, %v): exp=%t got=%t",
+					test.patterns, test.texts, test.exp, match)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+)
+
+// IsOID returns whether the given string is an object identifier in dotted
+// decimal form. Object identifiers have at least two arcs, the first of which
+// is 0, 1 or 2.
+func IsOID(oid string) bool {
+	arcs := strings.Split(oid, ".")
+	if len(arcs) < 2 {
+		return false
+	}
+	for i, arc := range arcs {
+		if len(arc) == 0 || (len(arc) > 1 && arc[0] == '0') {
+			return false
+		}
+		for _, r := range arc {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+		if i == 0 && arc != "0" && arc != "1" && arc != "2" {
+			return false
+		}
+	}
+	return true
+}
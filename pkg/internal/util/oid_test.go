@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsOID(t *testing.T) {
+	tests := map[string]bool{
+		"1.3.6.1.4.1.311.20.2.3": true,
+		"2.5.29.17":              true,
+		"0.0":                    true,
+		"":                       false,
+		"1":                      false,
+		"3.1":                    false,
+		"1..3":                   false,
+		"1.3.":                   false,
+		"1.03":                   false,
+		"1.3.a":                  false,
+		"1.3.-6":                 false,
+	}
+
+	for oid, exp := range tests {
+		t.Run(oid, func(t *testing.T) {
+			assert.Equal(t, exp, IsOID(oid))
+		})
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"k8s.io/utils/lru"
+)
+
+// RegexPrefix is the prefix of a pattern which marks that pattern as a
+// regular expression, rather than a wildcard pattern. For example,
+// "regex:^[a-z]+-prod\.example\.com$".
+const RegexPrefix = "regex:"
+
+// regexCacheSize is the maximum number of compiled regular expressions held
+// in regexCache.
+const regexCacheSize = 1024
+
+// regexCache holds compiled regular expressions, keyed by their expression.
+// Policies are evaluated against every CertificateRequest, so expressions are
+// only compiled once. The cache is bounded so that expressions of updated or
+// deleted policies, or rendered from templates, are eventually evicted.
+var regexCache = lru.New(regexCacheSize)
+
+// IsRegex returns whether the given pattern is a regular expression pattern,
+// i.e. is prefixed with RegexPrefix.
+func IsRegex(pattern string) bool {
+	return strings.HasPrefix(pattern, RegexPrefix)
+}
+
+// CompileRegex compiles the given regular expression, returning a cached
+// result if the expression has been compiled before. The expression is
+// anchored so that it must match the whole of a string, rather than any
+// substring, e.g. "admin" doesn't match "superadmin".
+// The expression should not include the RegexPrefix.
+func CompileRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Get(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	// Compile the expression on its own first so that unbalanced expressions
+	// (e.g. "a)|(b") are rejected, rather than escaping the anchoring.
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Add(expr, re)
+	return re, nil
+}
+
+// ValidateRegex returns an error if the given regular expression doesn't
+// compile, or contains an alternation of which only some alternatives are
+// explicitly anchored, e.g. "^admin|root". The latter read as though the
+// other alternatives match any substring, whereas expressions are always
+// anchored. ValidateRegex is stricter than CompileRegex, and should only be
+// used when admitting new expressions so that existing expressions continue
+// to be evaluated.
+// The expression should not include the RegexPrefix.
+func ValidateRegex(expr string) error {
+	if _, err := CompileRegex(expr); err != nil {
+		return err
+	}
+
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return err
+	}
+	if partiallyAnchored(parsed) {
+		return fmt.Errorf("%q anchors only some alternatives of an alternation with \"^\" or \"$\": expressions are always anchored to match the whole value, so remove the explicit anchors", expr)
+	}
+
+	return nil
+}
+
+// PatternMatches will return true if the given string matches the pattern.
+// If the pattern is prefixed with RegexPrefix it is matched as a regular
+// expression, otherwise it is matched as a wildcard pattern ('*'). A regular
+// expression which fails to compile never matches.
+func PatternMatches(pattern, str string) bool {
+	if !IsRegex(pattern) {
+		return WildcardMatches(pattern, str)
+	}
+
+	return RegexMatches(strings.TrimPrefix(pattern, RegexPrefix), str)
+}
+
+// RegexMatches will return true if the whole of the given string matches the
+// regular expression. A regular expression which fails to compile never
+// matches.
+func RegexMatches(expr, str string) bool {
+	re, err := CompileRegex(expr)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(str)
+}
+
+// partiallyAnchored returns whether the parsed expression contains an
+// alternation of which some, but not all, alternatives contain an anchor.
+func partiallyAnchored(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpAlternate {
+		var anchored int
+		for _, sub := range re.Sub {
+			if hasAnchor(sub) {
+				anchored++
+			}
+		}
+		if anchored > 0 && anchored < len(re.Sub) {
+			return true
+		}
+	}
+
+	for _, sub := range re.Sub {
+		if partiallyAnchored(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnchor returns whether the parsed expression contains an anchor to the
+// beginning or end of the text or a line.
+func hasAnchor(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasAnchor(sub) {
+			return true
+		}
+	}
+	return false
+}
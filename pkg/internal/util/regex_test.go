@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_CompileRegex(t *testing.T) {
+	tests := map[string]struct {
+		expr   string
+		expErr bool
+	}{
+		"valid expression should compile": {
+			expr:   `[a-z]+\.example\.com`,
+			expErr: false,
+		},
+		"invalid expression should error": {
+			expr:   `[a-z+\.example\.com`,
+			expErr: true,
+		},
+		"unbalanced expression which would escape anchoring should error": {
+			expr:   `a)|(b`,
+			expErr: true,
+		},
+		"alternation of which only some alternatives are anchored should compile": {
+			expr:   `^admin|root`,
+			expErr: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			re, err := CompileRegex(test.expr)
+			if (err != nil) != test.expErr {
+				t.Fatalf("unexpected error: exp=%t got=%v", test.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			cached, err := CompileRegex(test.expr)
+			if err != nil {
+				t.Fatalf("unexpected error compiling cached expression: %v", err)
+			}
+			if re != cached {
+				t.Errorf("expected the same compiled expression to be returned from the cache")
+			}
+		})
+	}
+}
+
+func Test_CompileRegex_bounded(t *testing.T) {
+	for i := 0; i < regexCacheSize+10; i++ {
+		if _, err := CompileRegex(fmt.Sprintf("bounded-%d", i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if l := regexCache.Len(); l != regexCacheSize {
+		t.Errorf("expected the cache to hold at most %d expressions, got=%d", regexCacheSize, l)
+	}
+}
+
+func Test_ValidateRegex(t *testing.T) {
+	tests := map[string]struct {
+		expr   string
+		expErr bool
+	}{
+		"valid expression should not error": {
+			expr:   `[a-z]+\.example\.com`,
+			expErr: false,
+		},
+		"invalid expression should error": {
+			expr:   `[a-z+\.example\.com`,
+			expErr: true,
+		},
+		"alternation of which only some alternatives are anchored should error": {
+			expr:   `^admin|root`,
+			expErr: true,
+		},
+		"nested alternation of which only some alternatives are anchored should error": {
+			expr:   `user-(admin$|root)`,
+			expErr: true,
+		},
+		"alternation of which all alternatives are anchored should not error": {
+			expr:   `^admin$|^root$`,
+			expErr: false,
+		},
+		"alternation without anchors should not error": {
+			expr:   `admin|root`,
+			expErr: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateRegex(test.expr)
+			if (err != nil) != test.expErr {
+				t.Fatalf("unexpected error: exp=%t got=%v", test.expErr, err)
+			}
+		})
+	}
+}
+
+func Test_PatternMatches(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		text    string
+		exp     bool
+	}{
+		"wildcard pattern: true": {
+			pattern: "*.example.com",
+			text:    "foo.example.com",
+			exp:     true,
+		},
+		"wildcard pattern: false": {
+			pattern: "*.example.com",
+			text:    "foo.example.net",
+			exp:     false,
+		},
+		"regex pattern: true": {
+			pattern: `regex:[a-z.]+-prod\.example\.com`,
+			text:    "foo.bar-prod.example.com",
+			exp:     true,
+		},
+		"regex pattern: false": {
+			pattern: `regex:[a-z.]+-prod\.example\.com`,
+			text:    "foo.bar-dev.example.com",
+			exp:     false,
+		},
+		"regex alternation of which only some alternatives are anchored still matches: true": {
+			pattern: `regex:^admin|root`,
+			text:    "root",
+			exp:     true,
+		},
+		"regex pattern is anchored at the start: false": {
+			pattern: `regex:[a-z]+-prod\.example\.com`,
+			text:    "evil.com.foo-prod.example.com",
+			exp:     false,
+		},
+		"regex pattern matches the whole string rather than a substring: false": {
+			pattern: `regex:admin`,
+			text:    "superadmin",
+			exp:     false,
+		},
+		"regex alternation is anchored as a whole: false": {
+			pattern: `regex:admin|root`,
+			text:    "superadmin",
+			exp:     false,
+		},
+		"regex pattern is anchored at the end: false": {
+			pattern: `regex:[a-z]+-prod\.example\.com`,
+			text:    "foo-prod.example.com.evil.com",
+			exp:     false,
+		},
+		"invalid regex pattern: false": {
+			pattern: `regex:[a-z+`,
+			text:    "[a-z+",
+			exp:     false,
+		},
+		"regex prefix is not treated as a wildcard pattern: false": {
+			pattern: "regex:*",
+			text:    "regex:foo",
+			exp:     false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if match := PatternMatches(test.pattern, test.text); match != test.exp {
+				t.Errorf("unexpected match (%q, %q): exp=%t got=%t",
+					test.pattern, test.text, test.exp, match)
+			}
+		})
+	}
+}
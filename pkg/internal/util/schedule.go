@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	// Embed the IANA time zone database so that schedule time zones can be
+	// loaded in images which don't ship one.
+	_ "time/tzdata"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// Weekdays are the accepted values of the days of an active window, in
+// order.
+var Weekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// weekdays maps the accepted values of the days of an active window to their
+// weekday.
+var weekdays = map[string]time.Weekday{
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+	"Sun": time.Sunday,
+}
+
+// ParseWeekday returns the weekday of the given active window day. Returns
+// false if the day is not one of Weekdays.
+func ParseWeekday(day string) (time.Weekday, bool) {
+	weekday, ok := weekdays[day]
+	return weekday, ok
+}
+
+// ParseTimeOfDay returns the minute of the day of the given "HH:MM" time.
+func ParseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("must be a time of day in the form \"HH:MM\": %w", err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// LoadTimeZone returns the location of the given IANA time zone name. An
+// empty name is UTC.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if len(name) == 0 {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// ScheduleActive returns whether the given time is within any window of the
+// schedule. The time is evaluated to the minute, in the time zone of the
+// schedule, so that a window's start minute is always within the window and
+// its end minute never is, regardless of the seconds of the time. A nil
+// schedule is always active.
+// Returns an error if the schedule can't be parsed.
+func ScheduleActive(schedule *policyapi.CertificateRequestPolicyActiveSchedule, now time.Time) (bool, error) {
+	if schedule == nil {
+		return true, nil
+	}
+
+	loc, err := LoadTimeZone(schedule.TimeZone)
+	if err != nil {
+		return false, err
+	}
+
+	now = now.In(loc)
+	var (
+		minute    = now.Hour()*60 + now.Minute()
+		today     = now.Weekday()
+		yesterday = (today + 6) % 7
+	)
+
+	for _, window := range schedule.Windows {
+		start, err := ParseTimeOfDay(window.Start)
+		if err != nil {
+			return false, err
+		}
+		end, err := ParseTimeOfDay(window.End)
+		if err != nil {
+			return false, err
+		}
+
+		if start < end {
+			if minute >= start && minute < end && windowStartsOn(window, today) {
+				return true, nil
+			}
+			continue
+		}
+
+		// The window ends on the day after it starts, so may either have
+		// started today, or be ending after starting yesterday.
+		if minute >= start && windowStartsOn(window, today) {
+			return true, nil
+		}
+		if minute < end && windowStartsOn(window, yesterday) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// windowStartsOn returns whether the window starts on the given weekday.
+// Windows without days start on every day. Unknown days are ignored.
+func windowStartsOn(window policyapi.CertificateRequestPolicyActiveWindow, weekday time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, day := range window.Days {
+		if d, ok := ParseWeekday(day); ok && d == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_ScheduleActive(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// outOfHours is active outside of business hours in London, i.e. from
+	// 18:00 to 09:00 on weekdays, and all weekend.
+	outOfHours := &policyapi.CertificateRequestPolicyActiveSchedule{
+		TimeZone: "Europe/London",
+		Windows: []policyapi.CertificateRequestPolicyActiveWindow{
+			{Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "18:00", End: "09:00"},
+			{Days: []string{"Sat", "Sun"}, Start: "09:00", End: "09:00"},
+		},
+	}
+
+	// 2023-06-05 is a Monday.
+	at := func(day, hour, min, sec int) time.Time {
+		return time.Date(2023, time.June, day, hour, min, sec, 0, london)
+	}
+
+	tests := map[string]struct {
+		schedule *policyapi.CertificateRequestPolicyActiveSchedule
+		now      time.Time
+		exp      bool
+		expErr   bool
+	}{
+		"nil schedule: true": {
+			schedule: nil,
+			now:      at(5, 12, 0, 0),
+			exp:      true,
+		},
+		"during business hours: false": {
+			schedule: outOfHours,
+			now:      at(5, 12, 0, 0),
+			exp:      false,
+		},
+		"last second before window start minute: false": {
+			schedule: outOfHours,
+			now:      at(5, 17, 59, 59),
+			exp:      false,
+		},
+		"first second of window start minute: true": {
+			schedule: outOfHours,
+			now:      at(5, 18, 0, 0),
+			exp:      true,
+		},
+		"last second of window start minute: true": {
+			schedule: outOfHours,
+			now:      at(5, 18, 0, 59),
+			exp:      true,
+		},
+		"overnight window after midnight: true": {
+			schedule: outOfHours,
+			now:      at(6, 8, 59, 59),
+			exp:      true,
+		},
+		"first second of window end minute: false": {
+			schedule: outOfHours,
+			now:      at(6, 9, 0, 0),
+			exp:      false,
+		},
+		"last second of window end minute: false": {
+			schedule: outOfHours,
+			now:      at(6, 9, 0, 59),
+			exp:      false,
+		},
+		"friday overnight window on saturday morning: true": {
+			schedule: outOfHours,
+			now:      at(10, 8, 0, 0),
+			exp:      true,
+		},
+		"24 hour sunday window on monday morning: true": {
+			schedule: outOfHours,
+			now:      at(12, 8, 0, 0),
+			exp:      true,
+		},
+		"time in another time zone is converted to the schedule time zone: true": {
+			schedule: outOfHours,
+			now:      time.Date(2023, time.June, 5, 17, 0, 0, 0, time.UTC),
+			exp:      true,
+		},
+		"window without days is active every day: true": {
+			schedule: &policyapi.CertificateRequestPolicyActiveSchedule{
+				Windows: []policyapi.CertificateRequestPolicyActiveWindow{{Start: "10:00", End: "11:00"}},
+			},
+			now: time.Date(2023, time.June, 10, 10, 30, 0, 0, time.UTC),
+			exp: true,
+		},
+		"unknown time zone: error": {
+			schedule: &policyapi.CertificateRequestPolicyActiveSchedule{
+				TimeZone: "Not/AZone",
+				Windows:  []policyapi.CertificateRequestPolicyActiveWindow{{Start: "10:00", End: "11:00"}},
+			},
+			now:    at(5, 10, 30, 0),
+			expErr: true,
+		},
+		"malformed window: error": {
+			schedule: &policyapi.CertificateRequestPolicyActiveSchedule{
+				Windows: []policyapi.CertificateRequestPolicyActiveWindow{{Start: "10:00", End: "25:00"}},
+			},
+			now:    at(5, 10, 30, 0),
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			active, err := ScheduleActive(test.schedule, test.now)
+			if (err != nil) != test.expErr {
+				t.Fatalf("unexpected error: exp=%t got=%v", test.expErr, err)
+			}
+			if active != test.exp {
+				t.Errorf("unexpected active (%s): exp=%t got=%t", test.now, test.exp, active)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SelectorCache caches compiled label selectors, so that the label selectors
+// of a CertificateRequestPolicy are not recompiled every time the policy is
+// validated or evaluated. Compiled selectors are keyed by their serialized
+// label selector. The cached selector of a policy field is evicted when the
+// resourceVersion of the policy changes.
+// The zero value is ready for use.
+type SelectorCache struct {
+	lock sync.Mutex
+
+	// selectors maps serialized label selectors to their compiled selector.
+	selectors map[string]compiledSelector
+
+	// policies maps a policy name and field to the resourceVersion of the
+	// policy and serialized matchLabels which were last requested.
+	policies map[string]policySelector
+}
+
+// compiledSelector is the result of compiling a label selector.
+type compiledSelector struct {
+	selector labels.Selector
+	err      error
+}
+
+// policySelector is the serialized label selector last requested for a
+// policy field, at the resourceVersion of the policy.
+type policySelector struct {
+	resourceVersion string
+	key             string
+}
+
+// Selector returns the compiled label selector of the given matchLabels, for
+// the named field of the given policy. An error is returned if the
+// matchLabels are not a valid label selector.
+func (c *SelectorCache) Selector(policy metav1.Object, field string, matchLabels map[string]string) (labels.Selector, error) {
+	return c.LabelSelector(policy, field, &metav1.LabelSelector{MatchLabels: matchLabels})
+}
+
+// LabelSelector returns the compiled label selector of the given matchLabels
+// and matchExpressions, for the named field of the given policy. An error is
+// returned if the label selector is not valid.
+func (c *SelectorCache) LabelSelector(policy metav1.Object, field string, labelSelector *metav1.LabelSelector) (labels.Selector, error) {
+	// json serializes maps with sorted keys, and unlike the label selector
+	// string form, is unambiguous for invalid keys and values.
+	serialized, err := json.Marshal(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	key := string(serialized)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.selectors == nil {
+		c.selectors = make(map[string]compiledSelector)
+		c.policies = make(map[string]policySelector)
+	}
+
+	policyKey := policy.GetName() + "/" + field
+	if last, ok := c.policies[policyKey]; ok && (last.resourceVersion != policy.GetResourceVersion() || last.key != key) {
+		delete(c.selectors, last.key)
+	}
+	c.policies[policyKey] = policySelector{resourceVersion: policy.GetResourceVersion(), key: key}
+
+	compiled, ok := c.selectors[key]
+	if !ok {
+		compiled.selector, compiled.err = metav1.LabelSelectorAsSelector(labelSelector)
+		c.selectors[key] = compiled
+	}
+
+	return compiled.selector, compiled.err
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func Test_SelectorCache(t *testing.T) {
+	policy := func(name, resourceVersion string) *metav1.ObjectMeta {
+		return &metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion}
+	}
+
+	tests := map[string]struct {
+		run func(t *testing.T, c *SelectorCache)
+	}{
+		"a compiled selector should match the same as an uncached selector": {
+			run: func(t *testing.T, c *SelectorCache) {
+				selector, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"team": "foo"})
+				assert.NoError(t, err)
+				assert.True(t, selector.Matches(labels.Set{"team": "foo", "env": "prod"}))
+				assert.False(t, selector.Matches(labels.Set{"team": "bar"}))
+			},
+		},
+		"an invalid selector should return an error every time": {
+			run: func(t *testing.T, c *SelectorCache) {
+				for i := 0; i < 2; i++ {
+					_, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"team": "foo bar"})
+					assert.Error(t, err)
+				}
+			},
+		},
+		"the same selector at the same resourceVersion should be reused": {
+			run: func(t *testing.T, c *SelectorCache) {
+				first, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"team": "foo"})
+				assert.NoError(t, err)
+				second, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"team": "foo"})
+				assert.NoError(t, err)
+				assert.Equal(t, selectorPointer(first), selectorPointer(second))
+			},
+		},
+		"a changed resourceVersion should recompile the selector": {
+			run: func(t *testing.T, c *SelectorCache) {
+				first, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"team": "foo"})
+				assert.NoError(t, err)
+				second, err := c.Selector(policy("a", "2"), "namespace", map[string]string{"team": "foo"})
+				assert.NoError(t, err)
+				assert.NotEqual(t, selectorPointer(first), selectorPointer(second))
+			},
+		},
+		"a changed selector should evict the policy's previous selector": {
+			run: func(t *testing.T, c *SelectorCache) {
+				_, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"team": "foo"})
+				assert.NoError(t, err)
+				selector, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"team": "bar"})
+				assert.NoError(t, err)
+				assert.True(t, selector.Matches(labels.Set{"team": "bar"}))
+				assert.Len(t, c.selectors, 1)
+			},
+		},
+		"selectors which serialize to the same string form should not collide": {
+			run: func(t *testing.T, c *SelectorCache) {
+				_, err := c.Selector(policy("a", "1"), "namespace", map[string]string{"a": "b", "c": "d"})
+				assert.NoError(t, err)
+				_, err = c.Selector(policy("b", "1"), "namespace", map[string]string{"a": "b,c=d"})
+				assert.Error(t, err)
+			},
+		},
+		"a selector with matchExpressions should match on the expressions": {
+			run: func(t *testing.T, c *SelectorCache) {
+				selector, err := c.LabelSelector(policy("a", "1"), "requestLabels", &metav1.LabelSelector{
+					MatchLabels:      map[string]string{"team": "foo"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"prod", "staging"}}},
+				})
+				assert.NoError(t, err)
+				assert.True(t, selector.Matches(labels.Set{"team": "foo", "env": "staging"}))
+				assert.False(t, selector.Matches(labels.Set{"team": "foo", "env": "dev"}))
+			},
+		},
+		"a selector with an invalid matchExpression should return an error": {
+			run: func(t *testing.T, c *SelectorCache) {
+				_, err := c.LabelSelector(policy("a", "1"), "requestLabels", &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: metav1.LabelSelectorOpIn}},
+				})
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.run(t, new(SelectorCache))
+		})
+	}
+}
+
+// selectorPointer returns the address of the underlying compiled selector, so
+// that tests can determine whether a selector was reused or recompiled.
+func selectorPointer(selector labels.Selector) uintptr {
+	return reflect.ValueOf(selector).Pointer()
+}
+
+// Benchmark_SelectorCache compares evaluating the same policy selector
+// thousands of times with and without the cache.
+func Benchmark_SelectorCache(b *testing.B) {
+	var (
+		policy      = &metav1.ObjectMeta{Name: "policy", ResourceVersion: "1"}
+		matchLabels = map[string]string{"team": "foo", "env": "prod", "tier": "frontend"}
+		set         = labels.Set{"team": "foo", "env": "prod", "tier": "frontend"}
+	)
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: matchLabels})
+			if err != nil || !selector.Matches(set) {
+				b.Fatal("expected selector to match")
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		var c SelectorCache
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			selector, err := c.Selector(policy, "namespace", matchLabels)
+			if err != nil || !selector.Matches(set) {
+				b.Fatal("expected selector to match")
+			}
+		}
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+)
+
+// URIMatches will return true if the given URI matches the pattern. The
+// pattern and URI are split into segments on "/", and each segment is matched
+// in turn:
+//   - "**" matches zero or more whole segments;
+//   - any other segment is matched as a wildcard pattern ('*'), where the
+//     wildcard never spans a "/".
+//
+// Empty segments, e.g. from a trailing "/", are only matched by an empty
+// pattern segment or "**". A pattern of only "*" matches any URI.
+func URIMatches(pattern, uri string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(uri, "/"))
+}
+
+// matchSegments will return whether the given URI segments match the given
+// pattern segments.
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive "**" to avoid redundant branching.
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if len(pattern[0]) == 0 || len(segments[0]) == 0 {
+			if pattern[0] != segments[0] {
+				return false
+			}
+		} else if !WildcardMatches(pattern[0], segments[0]) {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(segments) == 0
+}
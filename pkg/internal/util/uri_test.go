@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func Test_URIMatches(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		uri     string
+		exp     bool
+	}{
+		"literal: true": {
+			pattern: "spiffe://cluster.local/ns/foo/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     true,
+		},
+		"literal different: false": {
+			pattern: "spiffe://cluster.local/ns/foo/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/sa/baz",
+			exp:     false,
+		},
+		"only wildcard: true": {
+			pattern: "*",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     true,
+		},
+		"wildcard segment: true": {
+			pattern: "spiffe://cluster.local/ns/*/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     true,
+		},
+		"wildcard segments: true": {
+			pattern: "spiffe://cluster.local/ns/*/sa/*",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     true,
+		},
+		"partial wildcard segment: true": {
+			pattern: "spiffe://cluster.local/ns/team-*/sa/bar",
+			uri:     "spiffe://cluster.local/ns/team-foo/sa/bar",
+			exp:     true,
+		},
+		"wildcard trust domain: true": {
+			pattern: "spiffe://*/ns/foo/sa/bar",
+			uri:     "spiffe://example.org/ns/foo/sa/bar",
+			exp:     true,
+		},
+		"wildcard segment doesn't span multiple segments: false": {
+			pattern: "spiffe://cluster.local/ns/*/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/extra/sa/bar",
+			exp:     false,
+		},
+		"trailing wildcard segment doesn't span multiple segments: false": {
+			pattern: "spiffe://cluster.local/*",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     false,
+		},
+		"double wildcard spans multiple segments: true": {
+			pattern: "spiffe://cluster.local/**",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     true,
+		},
+		"double wildcard in the middle spans multiple segments: true": {
+			pattern: "spiffe://cluster.local/ns/**/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/extra/sa/bar",
+			exp:     true,
+		},
+		"double wildcard matches zero segments: true": {
+			pattern: "spiffe://cluster.local/ns/foo/**/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     true,
+		},
+		"double wildcard with following segment not matching: false": {
+			pattern: "spiffe://cluster.local/**/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/sa/baz",
+			exp:     false,
+		},
+		"double wildcard in a different trust domain: false": {
+			pattern: "spiffe://cluster.local/**",
+			uri:     "spiffe://example.org/ns/foo/sa/bar",
+			exp:     false,
+		},
+		"trailing slash on uri with literal pattern: false": {
+			pattern: "spiffe://cluster.local/ns/foo/sa/bar",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar/",
+			exp:     false,
+		},
+		"trailing slash on pattern with literal uri: false": {
+			pattern: "spiffe://cluster.local/ns/foo/sa/bar/",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar",
+			exp:     false,
+		},
+		"trailing slash on both: true": {
+			pattern: "spiffe://cluster.local/ns/foo/sa/bar/",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar/",
+			exp:     true,
+		},
+		"wildcard segment doesn't match empty trailing segment: false": {
+			pattern: "spiffe://cluster.local/ns/foo/sa/*",
+			uri:     "spiffe://cluster.local/ns/foo/sa/",
+			exp:     false,
+		},
+		"double wildcard matches trailing slash: true": {
+			pattern: "spiffe://cluster.local/ns/foo/**",
+			uri:     "spiffe://cluster.local/ns/foo/sa/bar/",
+			exp:     true,
+		},
+		"non-URI literal: true": {
+			pattern: "foo.bar.com",
+			uri:     "foo.bar.com",
+			exp:     true,
+		},
+		"empty pattern: false": {
+			pattern: "",
+			uri:     "spiffe://cluster.local",
+			exp:     false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if match := URIMatches(test.pattern, test.uri); match != test.exp {
+				t.Errorf("unexpected match (%q, %q): exp=%t got=%t",
+					test.pattern, test.uri, test.exp, match)
+			}
+		})
+	}
+}
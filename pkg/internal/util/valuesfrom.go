@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// valuesFromNamespace is the namespace which ConfigMaps referenced by
+// CertificateRequestPolicies must be in. If empty, ConfigMaps may be in any
+// namespace.
+var valuesFromNamespace string
+
+// ConfigureValuesFromNamespace restricts the ConfigMaps which
+// CertificateRequestPolicies may reference to those in the given namespace,
+// so that approver-policy need only watch ConfigMaps in that namespace, and
+// policy authors can't read ConfigMaps from other namespaces. If empty,
+// ConfigMaps may be in any namespace. Should be called before any policy is
+// validated or evaluated.
+func ConfigureValuesFromNamespace(namespace string) {
+	valuesFromNamespace = namespace
+}
+
+// LoadValuesFrom returns the values contained in the referenced ConfigMap
+// key, parsed by ParseValues. Returns an error if the ConfigMap or key doesn't
+// exist, or the ConfigMap is not in the configured namespace.
+func LoadValuesFrom(ctx context.Context, lister client.Reader, ref *policyapi.CertificateRequestPolicyValuesFrom) ([]string, error) {
+	if len(valuesFromNamespace) > 0 && ref.Namespace != valuesFromNamespace {
+		return nil, fmt.Errorf("ConfigMap %s/%s is not in the namespace %q which values may be loaded from", ref.Namespace, ref.Name, valuesFromNamespace)
+	}
+
+	var cm corev1.ConfigMap
+	if err := lister.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, &cm); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	data, ok := cm.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap %s/%s has no key %q", ref.Namespace, ref.Name, ref.Key)
+	}
+
+	return ParseValues(data), nil
+}
+
+// ParseValues returns the values of ConfigMap data containing one value per
+// line. Surrounding whitespace is trimmed, and blank lines and lines beginning
+// with "#" are ignored.
+func ParseValues(data string) []string {
+	var values []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values = append(values, line)
+	}
+	return values
+}
+
+// ValidateValuesFrom validates that the ConfigMap key reference is
+// well-formed, and references a ConfigMap in the configured namespace.
+func ValidateValuesFrom(fldPath *field.Path, ref *policyapi.CertificateRequestPolicyValuesFrom) field.ErrorList {
+	var el field.ErrorList
+	if len(valuesFromNamespace) > 0 && len(ref.Namespace) > 0 && ref.Namespace != valuesFromNamespace {
+		el = append(el, field.NotSupported(fldPath.Child("namespace"), ref.Namespace, []string{valuesFromNamespace}))
+	}
+	for _, f := range []struct {
+		name     string
+		value    string
+		validate func(string) []string
+	}{
+		{"namespace", ref.Namespace, validation.IsDNS1123Label},
+		{"name", ref.Name, validation.IsDNS1123Subdomain},
+		{"key", ref.Key, validation.IsConfigMapKey},
+	} {
+		if len(f.value) == 0 {
+			el = append(el, field.Required(fldPath.Child(f.name), "must be defined"))
+			continue
+		}
+		for _, msg := range f.validate(f.value) {
+			el = append(el, field.Invalid(fldPath.Child(f.name), f.value, msg))
+		}
+	}
+	return el
+}
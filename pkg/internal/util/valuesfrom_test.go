@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_LoadValuesFrom(t *testing.T) {
+	lister := fakeclient.NewClientBuilder().
+		WithScheme(policyapi.GlobalScheme).
+		WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "values"},
+			Data:       map[string]string{"values": "# comment\n  foo  \n\nbar\n"},
+		}).
+		Build()
+
+	tests := map[string]struct {
+		namespace string
+		ref       policyapi.CertificateRequestPolicyValuesFrom
+		expValues []string
+		expErr    bool
+	}{
+		"if the key exists, return its values without blank lines or comments": {
+			ref:       policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "values", Key: "values"},
+			expValues: []string{"foo", "bar"},
+		},
+		"if the ConfigMap doesn't exist, return an error": {
+			ref:    policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "not-found", Key: "values"},
+			expErr: true,
+		},
+		"if the key doesn't exist, return an error": {
+			ref:    policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "values", Key: "not-a-key"},
+			expErr: true,
+		},
+		"if the ConfigMap is in the configured namespace, return its values": {
+			namespace: "cert-manager",
+			ref:       policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "values", Key: "values"},
+			expValues: []string{"foo", "bar"},
+		},
+		"if the ConfigMap is not in the configured namespace, return an error": {
+			namespace: "other-ns",
+			ref:       policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "values", Key: "values"},
+			expErr:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ConfigureValuesFromNamespace(test.namespace)
+			t.Cleanup(func() { ConfigureValuesFromNamespace("") })
+
+			values, err := LoadValuesFrom(context.TODO(), lister, &test.ref)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expValues, values)
+		})
+	}
+}
+
+func Test_ValidateValuesFrom(t *testing.T) {
+	fldPath := field.NewPath("spec", "allowed", "dnsNames", "valuesFrom")
+
+	tests := map[string]struct {
+		namespace string
+		ref       policyapi.CertificateRequestPolicyValuesFrom
+		expErrs   field.ErrorList
+	}{
+		"if the reference is well-formed, return no errors": {
+			ref: policyapi.CertificateRequestPolicyValuesFrom{Namespace: "team-a", Name: "values", Key: "values"},
+		},
+		"if the reference is missing fields, return required errors": {
+			ref: policyapi.CertificateRequestPolicyValuesFrom{Namespace: "team-a"},
+			expErrs: field.ErrorList{
+				field.Required(fldPath.Child("name"), "must be defined"),
+				field.Required(fldPath.Child("key"), "must be defined"),
+			},
+		},
+		"if the reference is in the configured namespace, return no errors": {
+			namespace: "cert-manager",
+			ref:       policyapi.CertificateRequestPolicyValuesFrom{Namespace: "cert-manager", Name: "values", Key: "values"},
+		},
+		"if the reference is not in the configured namespace, return a not supported error": {
+			namespace: "cert-manager",
+			ref:       policyapi.CertificateRequestPolicyValuesFrom{Namespace: "team-a", Name: "values", Key: "values"},
+			expErrs: field.ErrorList{
+				field.NotSupported(fldPath.Child("namespace"), "team-a", []string{"cert-manager"}),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ConfigureValuesFromNamespace(test.namespace)
+			t.Cleanup(func() { ConfigureValuesFromNamespace("") })
+
+			assert.Equal(t, test.expErrs, ValidateValuesFrom(fldPath, &test.ref))
+		})
+	}
+}
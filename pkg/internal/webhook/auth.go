@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errUnauthenticated is returned by an authorizer when the request doesn't
+// carry valid credentials.
+var errUnauthenticated = errors.New("unauthenticated")
+
+// authorizer authenticates and authorizes the callers of the webhook's HTTP
+// endpoints which expose cluster state, i.e. the evaluate, explain and
+// policies endpoints.
+type authorizer interface {
+	// Authenticate returns the identity of the caller of the request. Returns
+	// errUnauthenticated if the request carries no valid credentials.
+	Authenticate(ctx context.Context, r *http.Request) (authnv1.UserInfo, error)
+
+	// Authorize returns whether the user may perform the action described by
+	// the resource attributes.
+	Authorize(ctx context.Context, user authnv1.UserInfo, attrs authzv1.ResourceAttributes) (bool, error)
+}
+
+// delegatingAuthorizer is an authorizer which delegates to the API server
+// using TokenReviews and SubjectAccessReviews, so callers are subject to the
+// same authentication and RBAC as if they were calling the API server
+// directly.
+type delegatingAuthorizer struct {
+	client client.Client
+}
+
+// Authenticate authenticates the bearer token of the request using a
+// TokenReview.
+func (d *delegatingAuthorizer) Authenticate(ctx context.Context, r *http.Request) (authnv1.UserInfo, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return authnv1.UserInfo{}, errUnauthenticated
+	}
+
+	rev := &authnv1.TokenReview{Spec: authnv1.TokenReviewSpec{Token: token}}
+	if err := d.client.Create(ctx, rev); err != nil {
+		return authnv1.UserInfo{}, fmt.Errorf("failed to create tokenreview: %w", err)
+	}
+	if !rev.Status.Authenticated {
+		return authnv1.UserInfo{}, errUnauthenticated
+	}
+
+	return rev.Status.User, nil
+}
+
+// Authorize authorizes the user using a SubjectAccessReview.
+func (d *delegatingAuthorizer) Authorize(ctx context.Context, user authnv1.UserInfo, attrs authzv1.ResourceAttributes) (bool, error) {
+	extra := make(map[string]authzv1.ExtraValue)
+	for k, v := range user.Extra {
+		extra[k] = authzv1.ExtraValue(v)
+	}
+
+	rev := &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			User:               user.Username,
+			Groups:             user.Groups,
+			Extra:              extra,
+			UID:                user.UID,
+			ResourceAttributes: &attrs,
+		},
+	}
+	if err := d.client.Create(ctx, rev); err != nil {
+		return false, fmt.Errorf("failed to create subjectaccessreview: %w", err)
+	}
+
+	return rev.Status.Allowed, nil
+}
+
+// bearerToken returns the bearer token of the request's Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, len(token) > 0
+}
+
+// authenticate authenticates the caller of the request, writing an error
+// response and returning false if they can't be authenticated.
+func authenticate(log logr.Logger, w http.ResponseWriter, r *http.Request, auth authorizer) (authnv1.UserInfo, bool) {
+	user, err := auth.Authenticate(r.Context(), r)
+	if errors.Is(err, errUnauthenticated) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return authnv1.UserInfo{}, false
+	}
+	if err != nil {
+		log.Error(err, "failed to authenticate request")
+		http.Error(w, "failed to authenticate request, check the approver-policy logs", http.StatusInternalServerError)
+		return authnv1.UserInfo{}, false
+	}
+	return user, true
+}
+
+// authorize authorizes the user to perform the action described by the
+// resource attributes, writing an error response and returning false if they
+// aren't allowed.
+func authorize(log logr.Logger, w http.ResponseWriter, r *http.Request, auth authorizer, user authnv1.UserInfo, attrs authzv1.ResourceAttributes) bool {
+	allowed, err := auth.Authorize(r.Context(), user, attrs)
+	if err != nil {
+		log.Error(err, "failed to authorize request")
+		http.Error(w, "failed to authorize request, check the approver-policy logs", http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		http.Error(w, fmt.Sprintf("user %q cannot %s", user.Username, describeAttributes(attrs)), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// describeAttributes returns a human readable description of the action
+// described by the resource attributes, for forbidden responses.
+func describeAttributes(attrs authzv1.ResourceAttributes) string {
+	resource := attrs.Resource
+	if len(attrs.Group) > 0 {
+		resource += "." + attrs.Group
+	}
+	if len(attrs.Name) > 0 {
+		resource += fmt.Sprintf(" %q", attrs.Name)
+	}
+	if len(attrs.Namespace) > 0 {
+		return fmt.Sprintf("%s %s in namespace %q", attrs.Verb, resource, attrs.Namespace)
+	}
+	return fmt.Sprintf("%s %s at the cluster scope", attrs.Verb, resource)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeAuthorizer is an authorizer which authenticates every request as the
+// given user, or returns the given error, and authorizes the given resource
+// attributes.
+type fakeAuthorizer struct {
+	user    authnv1.UserInfo
+	err     error
+	allowed []authzv1.ResourceAttributes
+}
+
+func (f *fakeAuthorizer) Authenticate(context.Context, *http.Request) (authnv1.UserInfo, error) {
+	return f.user, f.err
+}
+
+func (f *fakeAuthorizer) Authorize(_ context.Context, user authnv1.UserInfo, attrs authzv1.ResourceAttributes) (bool, error) {
+	if user.Username != f.user.Username {
+		return false, errors.New("unexpected user")
+	}
+	for _, allowed := range f.allowed {
+		if allowed == attrs {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeReviewClient is a client which calls the given func on Create.
+type fakeReviewClient struct {
+	client.Client
+	create func(client.Object) error
+}
+
+func (f *fakeReviewClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	return f.create(obj)
+}
+
+func Test_delegatingAuthorizerAuthenticate(t *testing.T) {
+	tests := map[string]struct {
+		authorization string
+		create        func(client.Object) error
+		expUser       authnv1.UserInfo
+		expErr        error
+	}{
+		"if the request has no authorization header, return unauthenticated": {
+			authorization: "",
+			expErr:        errUnauthenticated,
+		},
+		"if the request has a non bearer authorization header, return unauthenticated": {
+			authorization: "Basic Zm9vOmJhcg==",
+			expErr:        errUnauthenticated,
+		},
+		"if the token review fails, return an error": {
+			authorization: "Bearer my-token",
+			create: func(client.Object) error {
+				return errors.New("this is an error")
+			},
+			expErr: errors.New("failed to create tokenreview: this is an error"),
+		},
+		"if the token is not authenticated, return unauthenticated": {
+			authorization: "Bearer my-token",
+			create: func(obj client.Object) error {
+				assert.Equal(t, "my-token", obj.(*authnv1.TokenReview).Spec.Token)
+				return nil
+			},
+			expErr: errUnauthenticated,
+		},
+		"if the token is authenticated, return the user": {
+			authorization: "bearer my-token",
+			create: func(obj client.Object) error {
+				rev := obj.(*authnv1.TokenReview)
+				assert.Equal(t, "my-token", rev.Spec.Token)
+				rev.Status.Authenticated = true
+				rev.Status.User = authnv1.UserInfo{Username: "example", Groups: []string{"group-1"}}
+				return nil
+			},
+			expUser: authnv1.UserInfo{Username: "example", Groups: []string{"group-1"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/policies", nil)
+			if len(test.authorization) > 0 {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			d := &delegatingAuthorizer{client: &fakeReviewClient{create: test.create}}
+			user, err := d.Authenticate(context.TODO(), req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expUser, user)
+		})
+	}
+}
+
+func Test_delegatingAuthorizerAuthorize(t *testing.T) {
+	user := authnv1.UserInfo{
+		Username: "example",
+		UID:      "abc",
+		Groups:   []string{"group-1"},
+		Extra:    map[string]authnv1.ExtraValue{"scopes": {"a"}},
+	}
+	attrs := authzv1.ResourceAttributes{Verb: "list", Group: "policy.cert-manager.io", Resource: "certificaterequestpolicies"}
+
+	tests := map[string]struct {
+		create     func(client.Object) error
+		expAllowed bool
+		expErr     error
+	}{
+		"if the subject access review fails, return an error": {
+			create: func(client.Object) error {
+				return errors.New("this is an error")
+			},
+			expErr: errors.New("failed to create subjectaccessreview: this is an error"),
+		},
+		"if the subject access review denies the user, return false": {
+			create:     func(client.Object) error { return nil },
+			expAllowed: false,
+		},
+		"if the subject access review allows the user, return true": {
+			create: func(obj client.Object) error {
+				rev := obj.(*authzv1.SubjectAccessReview)
+				assert.Equal(t, authzv1.SubjectAccessReviewSpec{
+					User:               "example",
+					UID:                "abc",
+					Groups:             []string{"group-1"},
+					Extra:              map[string]authzv1.ExtraValue{"scopes": {"a"}},
+					ResourceAttributes: &attrs,
+				}, rev.Spec)
+				rev.Status.Allowed = true
+				return nil
+			},
+			expAllowed: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &delegatingAuthorizer{client: &fakeReviewClient{create: test.create}}
+			allowed, err := d.Authorize(context.TODO(), user, attrs)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expAllowed, allowed)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+)
+
+// maxEvaluateRequestBytes is the maximum size of a request body accepted by
+// the evaluate endpoint.
+const maxEvaluateRequestBytes = 3 * 1024 * 1024
+
+// evaluateRequest is the body of a request to the evaluate endpoint.
+type evaluateRequest struct {
+	// CertificateRequest is the CertificateRequest to evaluate.
+	CertificateRequest runtime.RawExtension `json:"certificateRequest"`
+
+	// PolicyName optionally restricts evaluation to the
+	// CertificateRequestPolicy with this name.
+	PolicyName string `json:"policyName,omitempty"`
+}
+
+// evaluator is a HTTP handler which performs a dry run review of a
+// CertificateRequest against CertificateRequestPolicies, returning the
+// decision which would be made by the approver controller. The handler never
+// mutates cluster state.
+//
+// Callers must be authenticated. The CertificateRequest is evaluated as
+// requested by the caller, unless it names a different requester in
+// `spec.username`, `spec.groups`, `spec.uid` or `spec.extra`, in which case the
+// caller must be allowed to impersonate that requester.
+type evaluator struct {
+	log       logr.Logger
+	auth      authorizer
+	dryRunner internalmanager.DryRunner
+	decoder   *admission.Decoder
+}
+
+// ServeHTTP implements http.Handler.
+func (e *evaluator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := authenticate(e.log, w, r, e.auth)
+	if !ok {
+		return
+	}
+
+	var req evaluateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEvaluateRequestBytes)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var cr cmapi.CertificateRequest
+	if err := e.decoder.DecodeRaw(req.CertificateRequest, &cr); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode CertificateRequest: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	log := e.log.WithValues("namespace", cr.Namespace, "name", cr.Name, "policy", req.PolicyName, "user", user.Username)
+	log.V(2).Info("received evaluate request")
+
+	// Default the requester to the caller, otherwise the caller must be
+	// allowed to impersonate the requester, since the dry run reveals which
+	// policies the requester is bound to.
+	if len(cr.Spec.Username) == 0 && len(cr.Spec.Groups) == 0 && len(cr.Spec.UID) == 0 && len(cr.Spec.Extra) == 0 {
+		cr.Spec.Username, cr.Spec.Groups, cr.Spec.UID = user.Username, user.Groups, user.UID
+		cr.Spec.Extra = make(map[string][]string, len(user.Extra))
+		for k, v := range user.Extra {
+			cr.Spec.Extra[k] = v
+		}
+	} else if !isRequester(user, &cr) {
+		for _, attrs := range impersonationAttributes(&cr) {
+			if !authorize(log, w, r, e.auth, user, attrs) {
+				return
+			}
+		}
+	}
+
+	response, err := e.dryRunner.DryRun(r.Context(), &cr, req.PolicyName)
+	if err != nil {
+		log.Error(err, "failed to evaluate CertificateRequest")
+		http.Error(w, "failed to evaluate CertificateRequest, check the approver-policy logs", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err, "failed to write evaluate response")
+	}
+}
+
+// isRequester returns whether the user is the requester of the
+// CertificateRequest.
+func isRequester(user authnv1.UserInfo, cr *cmapi.CertificateRequest) bool {
+	if user.Username != cr.Spec.Username || user.UID != cr.Spec.UID || !reflect.DeepEqual(user.Groups, cr.Spec.Groups) {
+		return false
+	}
+	if len(user.Extra) != len(cr.Spec.Extra) {
+		return false
+	}
+	for k, v := range user.Extra {
+		if !reflect.DeepEqual([]string(v), cr.Spec.Extra[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// impersonationAttributes returns the resource attributes which a user must be
+// authorized for to impersonate the requester of the CertificateRequest, in
+// the same way as the API server authorizes impersonation.
+func impersonationAttributes(cr *cmapi.CertificateRequest) []authzv1.ResourceAttributes {
+	var attrs []authzv1.ResourceAttributes
+	if len(cr.Spec.Username) > 0 {
+		attrs = append(attrs, authzv1.ResourceAttributes{Verb: "impersonate", Resource: "users", Name: cr.Spec.Username})
+	}
+	for _, group := range cr.Spec.Groups {
+		attrs = append(attrs, authzv1.ResourceAttributes{Verb: "impersonate", Resource: "groups", Name: group})
+	}
+	if len(cr.Spec.UID) > 0 {
+		attrs = append(attrs, authzv1.ResourceAttributes{Verb: "impersonate", Group: "authentication.k8s.io", Resource: "uids", Name: cr.Spec.UID})
+	}
+	keys := make([]string, 0, len(cr.Spec.Extra))
+	for key := range cr.Spec.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range cr.Spec.Extra[key] {
+			attrs = append(attrs, authzv1.ResourceAttributes{Verb: "impersonate", Group: "authentication.k8s.io", Resource: "userextras", Subresource: key, Name: value})
+		}
+	}
+	return attrs
+}
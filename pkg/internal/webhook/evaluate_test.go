@@ -0,0 +1,224 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+)
+
+// fakeDryRunner is a DryRunner which calls the given func.
+type fakeDryRunner func(context.Context, *cmapi.CertificateRequest, string) (internalmanager.DryRunResponse, error)
+
+func (f fakeDryRunner) DryRun(ctx context.Context, cr *cmapi.CertificateRequest, policyName string) (internalmanager.DryRunResponse, error) {
+	return f(ctx, cr, policyName)
+}
+
+func Test_evaluatorServeHTTP(t *testing.T) {
+	const crJSON = `{"apiVersion": "cert-manager.io/v1", "kind": "CertificateRequest", "metadata": {"name": "test-req", "namespace": "test-ns"}, "spec": {"username": "example"}}`
+
+	expNoDryRun := func(t *testing.T) internalmanager.DryRunner {
+		return fakeDryRunner(func(context.Context, *cmapi.CertificateRequest, string) (internalmanager.DryRunResponse, error) {
+			t.Fatal("unexpected dry run call")
+			return internalmanager.DryRunResponse{}, nil
+		})
+	}
+
+	caller := &fakeAuthorizer{user: authnv1.UserInfo{Username: "example"}}
+
+	tests := map[string]struct {
+		method    string
+		body      string
+		auth      *fakeAuthorizer
+		dryRunner func(t *testing.T) internalmanager.DryRunner
+		expCode   int
+		expBody   string
+	}{
+		"a request with a method other than POST should return 405": {
+			method:    http.MethodGet,
+			auth:      caller,
+			dryRunner: expNoDryRun,
+			expCode:   http.StatusMethodNotAllowed,
+			expBody:   "method GET not allowed\n",
+		},
+		"a request with a body that fails to decode should return 400": {
+			method:    http.MethodPost,
+			body:      `{"certificateRequest": `,
+			auth:      caller,
+			dryRunner: expNoDryRun,
+			expCode:   http.StatusBadRequest,
+			expBody:   "failed to decode request body: unexpected EOF\n",
+		},
+		"a request with no CertificateRequest should return 400": {
+			method:    http.MethodPost,
+			body:      `{"policyName": "foo"}`,
+			auth:      caller,
+			dryRunner: expNoDryRun,
+			expCode:   http.StatusBadRequest,
+			expBody:   "failed to decode CertificateRequest: there is no content to decode\n",
+		},
+		"a request which is not authenticated should return 401": {
+			method:    http.MethodPost,
+			body:      `{"certificateRequest": ` + crJSON + `}`,
+			auth:      &fakeAuthorizer{err: errUnauthenticated},
+			dryRunner: expNoDryRun,
+			expCode:   http.StatusUnauthorized,
+			expBody:   "unauthorized\n",
+		},
+		"a request which fails to authenticate should return 500": {
+			method:    http.MethodPost,
+			body:      `{"certificateRequest": ` + crJSON + `}`,
+			auth:      &fakeAuthorizer{err: errors.New("this is an error")},
+			dryRunner: expNoDryRun,
+			expCode:   http.StatusInternalServerError,
+			expBody:   "failed to authenticate request, check the approver-policy logs\n",
+		},
+		"a request for a CertificateRequest with no requester should be evaluated as the caller": {
+			method: http.MethodPost,
+			body:   `{"certificateRequest": {"apiVersion": "cert-manager.io/v1", "kind": "CertificateRequest", "metadata": {"name": "test-req", "namespace": "test-ns"}}}`,
+			auth: &fakeAuthorizer{user: authnv1.UserInfo{
+				Username: "example", UID: "abc", Groups: []string{"group-1"}, Extra: map[string]authnv1.ExtraValue{"scopes": {"a"}},
+			}},
+			dryRunner: func(t *testing.T) internalmanager.DryRunner {
+				return fakeDryRunner(func(_ context.Context, cr *cmapi.CertificateRequest, _ string) (internalmanager.DryRunResponse, error) {
+					assert.Equal(t, "example", cr.Spec.Username)
+					assert.Equal(t, "abc", cr.Spec.UID)
+					assert.Equal(t, []string{"group-1"}, cr.Spec.Groups)
+					assert.Equal(t, map[string][]string{"scopes": {"a"}}, cr.Spec.Extra)
+					return internalmanager.DryRunResponse{Result: internalmanager.DryRunResultApproved}, nil
+				})
+			},
+			expCode: http.StatusOK,
+			expBody: `{"result":"Approved","message":"","policies":null}` + "\n",
+		},
+		"a request for a CertificateRequest of another requester which the caller may not impersonate should return 403": {
+			method: http.MethodPost,
+			body:   `{"certificateRequest": {"apiVersion": "cert-manager.io/v1", "kind": "CertificateRequest", "metadata": {"name": "test-req", "namespace": "test-ns"}, "spec": {"username": "other", "groups": ["group-1"]}}}`,
+			auth: &fakeAuthorizer{
+				user:    authnv1.UserInfo{Username: "example"},
+				allowed: []authzv1.ResourceAttributes{{Verb: "impersonate", Resource: "users", Name: "other"}},
+			},
+			dryRunner: expNoDryRun,
+			expCode:   http.StatusForbidden,
+			expBody:   "user \"example\" cannot impersonate groups \"group-1\" at the cluster scope\n",
+		},
+		"a request for a CertificateRequest of another requester which the caller may impersonate should be evaluated": {
+			method: http.MethodPost,
+			body:   `{"certificateRequest": {"apiVersion": "cert-manager.io/v1", "kind": "CertificateRequest", "metadata": {"name": "test-req", "namespace": "test-ns"}, "spec": {"username": "other", "groups": ["group-1"]}}}`,
+			auth: &fakeAuthorizer{
+				user: authnv1.UserInfo{Username: "example"},
+				allowed: []authzv1.ResourceAttributes{
+					{Verb: "impersonate", Resource: "users", Name: "other"},
+					{Verb: "impersonate", Resource: "groups", Name: "group-1"},
+				},
+			},
+			dryRunner: func(t *testing.T) internalmanager.DryRunner {
+				return fakeDryRunner(func(_ context.Context, cr *cmapi.CertificateRequest, _ string) (internalmanager.DryRunResponse, error) {
+					assert.Equal(t, "other", cr.Spec.Username)
+					assert.Equal(t, []string{"group-1"}, cr.Spec.Groups)
+					return internalmanager.DryRunResponse{Result: internalmanager.DryRunResultApproved}, nil
+				})
+			},
+			expCode: http.StatusOK,
+			expBody: `{"result":"Approved","message":"","policies":null}` + "\n",
+		},
+		"a dry run which errors should return 500": {
+			method: http.MethodPost,
+			body:   `{"certificateRequest": ` + crJSON + `}`,
+			auth:   caller,
+			dryRunner: func(t *testing.T) internalmanager.DryRunner {
+				return fakeDryRunner(func(context.Context, *cmapi.CertificateRequest, string) (internalmanager.DryRunResponse, error) {
+					return internalmanager.DryRunResponse{}, errors.New("this is an error")
+				})
+			},
+			expCode: http.StatusInternalServerError,
+			expBody: "failed to evaluate CertificateRequest, check the approver-policy logs\n",
+		},
+		"a dry run should be passed the decoded CertificateRequest and policy name, and return its response": {
+			method: http.MethodPost,
+			body:   `{"policyName": "test-policy", "certificateRequest": ` + crJSON + `}`,
+			auth:   caller,
+			dryRunner: func(t *testing.T) internalmanager.DryRunner {
+				return fakeDryRunner(func(_ context.Context, cr *cmapi.CertificateRequest, policyName string) (internalmanager.DryRunResponse, error) {
+					assert.Equal(t, "test-ns", cr.Namespace)
+					assert.Equal(t, "test-req", cr.Name)
+					assert.Equal(t, "example", cr.Spec.Username)
+					assert.Equal(t, "test-policy", policyName)
+					return internalmanager.DryRunResponse{
+						Result:   internalmanager.DryRunResultDenied,
+						Message:  "No policy approved this request: [test-policy: denied]",
+						Policies: []internalmanager.DryRunPolicy{{Name: "test-policy", Approved: false, Message: "denied"}},
+					}, nil
+				})
+			},
+			expCode: http.StatusOK,
+			expBody: `{"result":"Denied","message":"No policy approved this request: [test-policy: denied]","policies":[{"name":"test-policy","approved":false,"message":"denied"}]}` + "\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			decoder, err := admission.NewDecoder(policyapi.GlobalScheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := &evaluator{log: klogr.New(), auth: test.auth, dryRunner: test.dryRunner(t), decoder: decoder}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(test.method, "/evaluate", strings.NewReader(test.body)))
+
+			assert.Equal(t, test.expCode, rec.Code)
+			assert.Equal(t, test.expBody, rec.Body.String())
+		})
+	}
+}
+
+func Test_impersonationAttributes(t *testing.T) {
+	cr := &cmapi.CertificateRequest{
+		Spec: cmapi.CertificateRequestSpec{
+			Username: "example",
+			Groups:   []string{"group-1", "group-2"},
+			UID:      "abc",
+			Extra:    map[string][]string{"scopes": {"a", "b"}, "foo": {"bar"}},
+		},
+	}
+
+	assert.Equal(t, []authzv1.ResourceAttributes{
+		{Verb: "impersonate", Resource: "users", Name: "example"},
+		{Verb: "impersonate", Resource: "groups", Name: "group-1"},
+		{Verb: "impersonate", Resource: "groups", Name: "group-2"},
+		{Verb: "impersonate", Group: "authentication.k8s.io", Resource: "uids", Name: "abc"},
+		{Verb: "impersonate", Group: "authentication.k8s.io", Resource: "userextras", Subresource: "foo", Name: "bar"},
+		{Verb: "impersonate", Group: "authentication.k8s.io", Resource: "userextras", Subresource: "scopes", Name: "a"},
+		{Verb: "impersonate", Group: "authentication.k8s.io", Resource: "userextras", Subresource: "scopes", Name: "b"},
+	}, impersonationAttributes(cr))
+}
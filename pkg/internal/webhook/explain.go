@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	authzv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+)
+
+// explainer is a HTTP handler which explains how an existing
+// CertificateRequest is reviewed against every CertificateRequestPolicy,
+// i.e. whether each policy matched the request, and which fields of each
+// evaluated policy denied it. The request is given by the `namespace` and
+// `name` query parameters. The response is human readable, or JSON if the
+// `output` query parameter is "json". The handler never mutates cluster
+// state.
+//
+// Callers must be authenticated, and allowed to get the CertificateRequest.
+type explainer struct {
+	log       logr.Logger
+	auth      authorizer
+	lister    client.Reader
+	explainer internalmanager.Explainer
+}
+
+// ServeHTTP implements http.Handler.
+func (e *explainer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := authenticate(e.log, w, r, e.auth)
+	if !ok {
+		return
+	}
+
+	var (
+		query     = r.URL.Query()
+		namespace = query.Get("namespace")
+		name      = query.Get("name")
+		output    = query.Get("output")
+	)
+	if len(namespace) == 0 || len(name) == 0 {
+		http.Error(w, "the namespace and name query parameters are required", http.StatusBadRequest)
+		return
+	}
+	if output != "" && output != "json" && output != "text" {
+		http.Error(w, fmt.Sprintf("unsupported output %q, must be one of \"text\", \"json\"", output), http.StatusBadRequest)
+		return
+	}
+
+	log := e.log.WithValues("namespace", namespace, "name", name, "user", user.Username)
+	log.V(2).Info("received explain request")
+
+	if !authorize(log, w, r, e.auth, user, authzv1.ResourceAttributes{
+		Verb:      "get",
+		Group:     "cert-manager.io",
+		Resource:  "certificaterequests",
+		Namespace: namespace,
+		Name:      name,
+	}) {
+		return
+	}
+
+	var cr cmapi.CertificateRequest
+	if err := e.lister.Get(r.Context(), client.ObjectKey{Namespace: namespace, Name: name}, &cr); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("CertificateRequest %s/%s not found", namespace, name), http.StatusNotFound)
+			return
+		}
+		log.Error(err, "failed to get CertificateRequest")
+		http.Error(w, "failed to get CertificateRequest, check the approver-policy logs", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := e.explainer.Explain(r.Context(), &cr)
+	if err != nil {
+		log.Error(err, "failed to explain CertificateRequest")
+		http.Error(w, "failed to explain CertificateRequest, check the approver-policy logs", http.StatusInternalServerError)
+		return
+	}
+
+	if output == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		err = writeExplanation(w, &cr, response)
+	}
+	if err != nil {
+		log.Error(err, "failed to write explain response")
+	}
+}
+
+// writeExplanation writes the human readable explanation of how the
+// CertificateRequest is reviewed.
+func writeExplanation(w io.Writer, cr *cmapi.CertificateRequest, response internalmanager.ExplainResponse) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "CertificateRequest: %s/%s\n", cr.Namespace, cr.Name)
+	fmt.Fprintf(&b, "Result:             %s\n", response.Result)
+	fmt.Fprintf(&b, "Message:            %s\n", response.Message)
+	for _, warning := range response.Warnings {
+		fmt.Fprintf(&b, "Warning:            %s\n", warning)
+	}
+
+	b.WriteString("\nPolicies:\n")
+	if len(response.Policies) == 0 {
+		b.WriteString("  <none>\n")
+	}
+	for _, policy := range response.Policies {
+		var state string
+		switch {
+		case policy.Evaluated && policy.Approved:
+			state = "matched, approved"
+		case policy.Evaluated:
+			state = "matched, denied"
+		case policy.Matched:
+			state = "matched, not evaluated"
+		default:
+			state = "not matched"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", policy.Name, state)
+
+		if len(policy.Reason) > 0 {
+			fmt.Fprintf(&b, "    %s\n", policy.Reason)
+		}
+		for _, denial := range policy.Denials {
+			if len(denial.Field) > 0 {
+				fmt.Fprintf(&b, "    %s: %s\n", denial.Field, denial.Detail)
+			} else {
+				fmt.Fprintf(&b, "    %s\n", denial.Detail)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver/manager"
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+)
+
+// fakeExplainer is an Explainer which calls the given func.
+type fakeExplainer func(context.Context, *cmapi.CertificateRequest) (internalmanager.ExplainResponse, error)
+
+func (f fakeExplainer) Explain(ctx context.Context, cr *cmapi.CertificateRequest) (internalmanager.ExplainResponse, error) {
+	return f(ctx, cr)
+}
+
+func Test_explainerServeHTTP(t *testing.T) {
+	existingCR := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-req", Namespace: "test-ns"},
+		Spec:       cmapi.CertificateRequestSpec{Username: "example"},
+	}
+
+	expNoExplain := func(t *testing.T) internalmanager.Explainer {
+		return fakeExplainer(func(context.Context, *cmapi.CertificateRequest) (internalmanager.ExplainResponse, error) {
+			t.Fatal("unexpected explain call")
+			return internalmanager.ExplainResponse{}, nil
+		})
+	}
+
+	deniedExplainer := func(t *testing.T) internalmanager.Explainer {
+		return fakeExplainer(func(_ context.Context, cr *cmapi.CertificateRequest) (internalmanager.ExplainResponse, error) {
+			assert.Equal(t, "example", cr.Spec.Username)
+			return internalmanager.ExplainResponse{
+				Result:  internalmanager.DryRunResultDenied,
+				Message: "No policy approved this request: [test-policy-a: denied]",
+				Policies: []internalmanager.ExplainPolicy{
+					{
+						Name: "test-policy-a", Matched: true, Evaluated: true,
+						Denials: []manager.DenialReason{{Policy: "test-policy-a", Field: "spec.allowed.dnsNames.values", Detail: "Invalid value"}},
+					},
+					{Name: "test-policy-b", Reason: "spec.selector.namespace: does not match the request's namespace"},
+				},
+			}, nil
+		})
+	}
+
+	getAllowed := func(name string) *fakeAuthorizer {
+		return &fakeAuthorizer{
+			user: authnv1.UserInfo{Username: "example"},
+			allowed: []authzv1.ResourceAttributes{
+				{Verb: "get", Group: "cert-manager.io", Resource: "certificaterequests", Namespace: "test-ns", Name: name},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		method    string
+		target    string
+		auth      *fakeAuthorizer
+		explainer func(t *testing.T) internalmanager.Explainer
+		expCode   int
+		expBody   string
+	}{
+		"a request with a method other than GET should return 405": {
+			method:    http.MethodPost,
+			target:    "/explain?namespace=test-ns&name=test-req",
+			auth:      getAllowed("test-req"),
+			explainer: expNoExplain,
+			expCode:   http.StatusMethodNotAllowed,
+			expBody:   "method POST not allowed\n",
+		},
+		"a request without a name should return 400": {
+			method:    http.MethodGet,
+			target:    "/explain?namespace=test-ns",
+			auth:      getAllowed(""),
+			explainer: expNoExplain,
+			expCode:   http.StatusBadRequest,
+			expBody:   "the namespace and name query parameters are required\n",
+		},
+		"a request with an unsupported output should return 400": {
+			method:    http.MethodGet,
+			target:    "/explain?namespace=test-ns&name=test-req&output=yaml",
+			auth:      getAllowed("test-req"),
+			explainer: expNoExplain,
+			expCode:   http.StatusBadRequest,
+			expBody:   "unsupported output \"yaml\", must be one of \"text\", \"json\"\n",
+		},
+		"a request which is not authenticated should return 401": {
+			method:    http.MethodGet,
+			target:    "/explain?namespace=test-ns&name=test-req",
+			auth:      &fakeAuthorizer{err: errUnauthenticated},
+			explainer: expNoExplain,
+			expCode:   http.StatusUnauthorized,
+			expBody:   "unauthorized\n",
+		},
+		"a request by a user who may not get the CertificateRequest should return 403": {
+			method:    http.MethodGet,
+			target:    "/explain?namespace=test-ns&name=test-req",
+			auth:      getAllowed("other-req"),
+			explainer: expNoExplain,
+			expCode:   http.StatusForbidden,
+			expBody:   "user \"example\" cannot get certificaterequests.cert-manager.io \"test-req\" in namespace \"test-ns\"\n",
+		},
+		"a request for a CertificateRequest which doesn't exist should return 404": {
+			method:    http.MethodGet,
+			target:    "/explain?namespace=test-ns&name=not-found",
+			auth:      getAllowed("not-found"),
+			explainer: expNoExplain,
+			expCode:   http.StatusNotFound,
+			expBody:   "CertificateRequest test-ns/not-found not found\n",
+		},
+		"an explain which errors should return 500": {
+			method: http.MethodGet,
+			target: "/explain?namespace=test-ns&name=test-req",
+			auth:   getAllowed("test-req"),
+			explainer: func(t *testing.T) internalmanager.Explainer {
+				return fakeExplainer(func(context.Context, *cmapi.CertificateRequest) (internalmanager.ExplainResponse, error) {
+					return internalmanager.ExplainResponse{}, errors.New("this is an error")
+				})
+			},
+			expCode: http.StatusInternalServerError,
+			expBody: "failed to explain CertificateRequest, check the approver-policy logs\n",
+		},
+		"a request should return the human readable explanation of the CertificateRequest": {
+			method:    http.MethodGet,
+			target:    "/explain?namespace=test-ns&name=test-req",
+			auth:      getAllowed("test-req"),
+			explainer: deniedExplainer,
+			expCode:   http.StatusOK,
+			expBody: `CertificateRequest: test-ns/test-req
+Result:             Denied
+Message:            No policy approved this request: [test-policy-a: denied]
+
+Policies:
+  test-policy-a: matched, denied
+    spec.allowed.dnsNames.values: Invalid value
+  test-policy-b: not matched
+    spec.selector.namespace: does not match the request's namespace
+`,
+		},
+		"a request with json output should return the explanation as JSON": {
+			method:    http.MethodGet,
+			target:    "/explain?namespace=test-ns&name=test-req&output=json",
+			auth:      getAllowed("test-req"),
+			explainer: deniedExplainer,
+			expCode:   http.StatusOK,
+			expBody: `{"result":"Denied","message":"No policy approved this request: [test-policy-a: denied]","policies":[` +
+				`{"name":"test-policy-a","matched":true,"evaluated":true,"approved":false,"denials":[{"policy":"test-policy-a","field":"spec.allowed.dnsNames.values","detail":"Invalid value"}]},` +
+				`{"name":"test-policy-b","matched":false,"evaluated":false,"approved":false,"reason":"spec.selector.namespace: does not match the request's namespace"}]}` + "\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithObjects(existingCR).
+				Build()
+
+			e := &explainer{log: klogr.New(), auth: test.auth, lister: fakeclient, explainer: test.explainer(t)}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(test.method, test.target, nil))
+
+			assert.Equal(t, test.expCode, rec.Code)
+			assert.Equal(t, test.expBody, rec.Body.String())
+		})
+	}
+}
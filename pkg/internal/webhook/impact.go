@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	policyevaluator "github.com/cert-manager/approver-policy/pkg/evaluator"
+	"github.com/cert-manager/approver-policy/pkg/internal/inherit"
+)
+
+// maxImpactRequests is the maximum number of pending CertificateRequests that
+// are evaluated when computing the impact of a policy, so that admission
+// stays cheap in clusters with many pending requests.
+const maxImpactRequests = 100
+
+// impactOutcome is the outcome of evaluating a single policy against a
+// request.
+type impactOutcome int
+
+const (
+	// impactUnselected is the outcome when the policy doesn't select the
+	// request.
+	impactUnselected impactOutcome = iota
+
+	// impactAllowed is the outcome when the policy selects and approves the
+	// request.
+	impactAllowed
+
+	// impactDenied is the outcome when the policy selects and denies the
+	// request.
+	impactDenied
+)
+
+// impact returns an admission warning summarising how many pending
+// CertificateRequests the created or updated policy would newly allow or
+// deny, compared to the old policy if updated. Only the policy itself is
+// considered, not other policies which may also select the requests, nor
+// whether the policy is bound to the requesters with RBAC. At most
+// maxImpactRequests pending requests are evaluated.
+func (v *validator) impact(ctx context.Context, oldPolicy, policy *policyapi.CertificateRequestPolicy) (string, error) {
+	var requests cmapi.CertificateRequestList
+	if err := v.lister.List(ctx, &requests); err != nil {
+		return "", fmt.Errorf("failed to list CertificateRequests: %w", err)
+	}
+
+	var pending []*cmapi.CertificateRequest
+	for i := range requests.Items {
+		request := &requests.Items[i]
+		if apiutil.CertificateRequestIsApproved(request) || apiutil.CertificateRequestIsDenied(request) {
+			continue
+		}
+		pending = append(pending, request)
+	}
+
+	evaluated := pending
+	if len(evaluated) > maxImpactRequests {
+		evaluated = evaluated[:maxImpactRequests]
+	}
+
+	var allowed, denied int
+	for _, request := range evaluated {
+		outcome, err := v.impactOutcome(ctx, policy, request)
+		if err != nil {
+			return "", err
+		}
+
+		oldOutcome := impactUnselected
+		if oldPolicy != nil {
+			oldOutcome, err = v.impactOutcome(ctx, oldPolicy, request)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		switch {
+		case outcome == impactAllowed && oldOutcome != impactAllowed:
+			allowed++
+		case outcome == impactDenied && oldOutcome != impactDenied:
+			denied++
+		}
+	}
+
+	warning := fmt.Sprintf("policy would newly allow %d and newly deny %d pending CertificateRequests", allowed, denied)
+	if len(pending) > len(evaluated) {
+		warning += fmt.Sprintf(" (evaluated %d of %d pending CertificateRequests)", len(evaluated), len(pending))
+	}
+	return warning, nil
+}
+
+// impactOutcome returns whether the policy selects the request, and if so
+// whether it would approve or deny it.
+func (v *validator) impactOutcome(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (impactOutcome, error) {
+	selected, err := v.selects(ctx, policy, request)
+	if err != nil || !selected {
+		return impactUnselected, err
+	}
+
+	resolved, err := inherit.Resolve(ctx, v.lister, policy)
+	if err != nil {
+		return impactUnselected, fmt.Errorf("failed to resolve base policies: %w", err)
+	}
+
+	response, err := policyevaluator.New(v.evaluators...).Review(ctx, resolved, request)
+	if err != nil {
+		return impactUnselected, err
+	}
+
+	if response.Decision == policyevaluator.DecisionDenied {
+		return impactDenied, nil
+	}
+	return impactAllowed, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+)
+
+func Test_validatorImpact(t *testing.T) {
+	// evaluator denies requests whose name begins with "deny-", unless the
+	// policy is annotated to allow all requests.
+	evaluator := fake.NewFakeEvaluator().WithEvaluate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+		if _, ok := policy.Annotations["allow-all"]; !ok && strings.HasPrefix(cr.Name, "deny-") {
+			return approver.EvaluationResponse{Result: approver.ResultDenied, Message: "denied"}, nil
+		}
+		return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+	})
+
+	policyForNamespaces := func(annotations map[string]string, namespaces ...string) *policyapi.CertificateRequestPolicy {
+		return &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Annotations: annotations},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				Selector: policyapi.CertificateRequestPolicySelector{
+					IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{},
+					Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{MatchNames: namespaces},
+				},
+			},
+		}
+	}
+
+	request := func(namespace, name string, mods ...gen.CertificateRequestModifier) client.Object {
+		return gen.CertificateRequest(name, append([]gen.CertificateRequestModifier{gen.SetCertificateRequestNamespace(namespace)}, mods...)...)
+	}
+
+	// requests are pending in namespaces "foo" and "bar", along with requests
+	// which have already been approved or denied and are never evaluated.
+	requests := []client.Object{
+		request("foo", "allow-1"),
+		request("foo", "allow-2"),
+		request("foo", "deny-1"),
+		request("bar", "allow-3"),
+		request("bar", "deny-2"),
+		request("foo", "deny-approved", gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionApproved, Status: cmmeta.ConditionTrue})),
+		request("foo", "allow-denied", gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionDenied, Status: cmmeta.ConditionTrue})),
+	}
+
+	tests := map[string]struct {
+		existingObjects []client.Object
+		oldPolicy       *policyapi.CertificateRequestPolicy
+		policy          *policyapi.CertificateRequestPolicy
+		expWarning      string
+	}{
+		"if there are no pending requests, the policy should have no impact": {
+			policy:     policyForNamespaces(nil, "foo", "bar"),
+			expWarning: "policy would newly allow 0 and newly deny 0 pending CertificateRequests",
+		},
+		"if a policy is created, all pending requests it selects should be newly allowed or denied": {
+			existingObjects: requests,
+			policy:          policyForNamespaces(nil, "foo"),
+			expWarning:      "policy would newly allow 2 and newly deny 1 pending CertificateRequests",
+		},
+		"if a policy is updated to select more namespaces, only the newly selected requests should be counted": {
+			existingObjects: requests,
+			oldPolicy:       policyForNamespaces(nil, "foo"),
+			policy:          policyForNamespaces(nil, "foo", "bar"),
+			expWarning:      "policy would newly allow 1 and newly deny 1 pending CertificateRequests",
+		},
+		"if a policy is updated to allow previously denied requests, they should be newly allowed": {
+			existingObjects: requests,
+			oldPolicy:       policyForNamespaces(nil, "foo", "bar"),
+			policy:          policyForNamespaces(map[string]string{"allow-all": ""}, "foo", "bar"),
+			expWarning:      "policy would newly allow 2 and newly deny 0 pending CertificateRequests",
+		},
+		"if a policy is updated without changing its outcome, it should have no impact": {
+			existingObjects: requests,
+			oldPolicy:       policyForNamespaces(nil, "foo"),
+			policy:          policyForNamespaces(nil, "foo"),
+			expWarning:      "policy would newly allow 0 and newly deny 0 pending CertificateRequests",
+		},
+		"if there are more pending requests than are evaluated, the warning should say how many were evaluated": {
+			existingObjects: func() []client.Object {
+				var objects []client.Object
+				for i := 0; i < maxImpactRequests+5; i++ {
+					objects = append(objects, request("foo", fmt.Sprintf("allow-%03d", i)))
+				}
+				return objects
+			}(),
+			policy:     policyForNamespaces(nil, "foo"),
+			expWarning: "policy would newly allow 100 and newly deny 0 pending CertificateRequests (evaluated 100 of 105 pending CertificateRequests)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithObjects(test.existingObjects...).
+				Build()
+
+			v := &validator{lister: fakeclient, log: klogr.New(), evaluators: []approver.Evaluator{evaluator}}
+			warning, err := v.impact(context.TODO(), test.oldPolicy, test.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expWarning, warning)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/go-logr/logr"
+	authzv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/internal/inherit"
+)
+
+// policyLister is a HTTP handler which returns, as JSON, every
+// CertificateRequestPolicy in the approver's cache along with its effective
+// allowed attributes and constraints, once its base policies are resolved.
+// The cache is the view of policies that requests are evaluated against, so
+// this helps diagnose drift from the policies stored in the API server. The
+// handler never mutates cluster state.
+//
+// Callers must be authenticated, and allowed to list
+// CertificateRequestPolicies.
+type policyLister struct {
+	log    logr.Logger
+	auth   authorizer
+	lister client.Reader
+}
+
+// policiesResponse is the response of the policies endpoint.
+type policiesResponse struct {
+	// Policies are the policies in the cache, ordered by name.
+	Policies []policyView `json:"policies"`
+}
+
+// policyView is a CertificateRequestPolicy as seen by the approver.
+type policyView struct {
+	// Name, ResourceVersion and Generation identify the cached version of the
+	// policy.
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion"`
+	Generation      int64  `json:"generation"`
+
+	// Ready is whether the policy is Ready, so evaluates requests.
+	Ready bool `json:"ready"`
+
+	// Spec is the spec of the policy as cached.
+	Spec policyapi.CertificateRequestPolicySpec `json:"spec"`
+
+	// Effective is the allowed attributes and constraints of the policy with
+	// those of its base policies merged in. Omitted if they can't be
+	// resolved, in which case Error is set.
+	Effective *effectivePolicy `json:"effective,omitempty"`
+
+	// Error is why the base policies of the policy can't be resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// effectivePolicy is the allowed attributes and constraints that requests are
+// evaluated against.
+type effectivePolicy struct {
+	Allowed     *policyapi.CertificateRequestPolicyAllowed     `json:"allowed,omitempty"`
+	Constraints *policyapi.CertificateRequestPolicyConstraints `json:"constraints,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (p *policyLister) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := authenticate(p.log, w, r, p.auth)
+	if !ok {
+		return
+	}
+
+	log := p.log.WithValues("user", user.Username)
+	log.V(2).Info("received policies request")
+
+	if !authorize(log, w, r, p.auth, user, authzv1.ResourceAttributes{
+		Verb:     "list",
+		Group:    "policy.cert-manager.io",
+		Resource: "certificaterequestpolicies",
+	}) {
+		return
+	}
+
+	var policyList policyapi.CertificateRequestPolicyList
+	if err := p.lister.List(r.Context(), &policyList); err != nil {
+		log.Error(err, "failed to list CertificateRequestPolicies")
+		http.Error(w, "failed to list CertificateRequestPolicies, check the approver-policy logs", http.StatusInternalServerError)
+		return
+	}
+
+	sort.Slice(policyList.Items, func(i, j int) bool {
+		return policyList.Items[i].Name < policyList.Items[j].Name
+	})
+
+	response := policiesResponse{Policies: make([]policyView, 0, len(policyList.Items))}
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+
+		view := policyView{
+			Name:            policy.Name,
+			ResourceVersion: policy.ResourceVersion,
+			Generation:      policy.Generation,
+			Ready:           policyReady(policy),
+			Spec:            policy.Spec,
+		}
+		if resolved, err := inherit.Resolve(r.Context(), p.lister, policy); err != nil {
+			view.Error = err.Error()
+		} else {
+			view.Effective = &effectivePolicy{Allowed: resolved.Spec.Allowed, Constraints: resolved.Spec.Constraints}
+		}
+
+		response.Policies = append(response.Policies, view)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err, "failed to write policies response")
+	}
+}
+
+// policyReady returns whether the policy has a Ready condition with status
+// True.
+func policyReady(policy *policyapi.CertificateRequestPolicy) bool {
+	for _, condition := range policy.Status.Conditions {
+		if condition.Type == policyapi.CertificateRequestPolicyConditionReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/klogr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+func Test_policyListerServeHTTP(t *testing.T) {
+	var (
+		base = &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "base", ResourceVersion: "1", Generation: 2},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				Allowed:     &policyapi.CertificateRequestPolicyAllowed{IsCA: pointer.Bool(false)},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{MaxDuration: &metav1.Duration{Duration: time.Hour}},
+				Selector:    policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			},
+			Status: policyapi.CertificateRequestPolicyStatus{
+				Conditions: []policyapi.CertificateRequestPolicyCondition{
+					{Type: policyapi.CertificateRequestPolicyConditionReady, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+		child = &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "child", ResourceVersion: "1", Generation: 1},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				BaseRef:  &policyapi.CertificateRequestPolicyBaseRef{Name: "base"},
+				Allowed:  &policyapi.CertificateRequestPolicyAllowed{Usages: &[]cmapi.KeyUsage{cmapi.UsageServerAuth}},
+				Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			},
+		}
+		orphan = &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "orphan", ResourceVersion: "1", Generation: 1},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				BaseRef:  &policyapi.CertificateRequestPolicyBaseRef{Name: "not-found"},
+				Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			},
+		}
+	)
+
+	listAllowed := &fakeAuthorizer{
+		user: authnv1.UserInfo{Username: "example"},
+		allowed: []authzv1.ResourceAttributes{
+			{Verb: "list", Group: "policy.cert-manager.io", Resource: "certificaterequestpolicies"},
+		},
+	}
+
+	tests := map[string]struct {
+		method   string
+		auth     *fakeAuthorizer
+		policies []client.Object
+		expCode  int
+		expBody  string
+	}{
+		"if the method is not GET, return method not allowed": {
+			method:  http.MethodPost,
+			auth:    listAllowed,
+			expCode: http.StatusMethodNotAllowed,
+			expBody: "method POST not allowed\n",
+		},
+		"if the request is not authenticated, return unauthorized": {
+			method:   http.MethodGet,
+			auth:     &fakeAuthorizer{err: errUnauthenticated},
+			policies: []client.Object{base},
+			expCode:  http.StatusUnauthorized,
+			expBody:  "unauthorized\n",
+		},
+		"if the user may not list policies, return forbidden": {
+			method:   http.MethodGet,
+			auth:     &fakeAuthorizer{user: authnv1.UserInfo{Username: "example"}},
+			policies: []client.Object{base},
+			expCode:  http.StatusForbidden,
+			expBody:  "user \"example\" cannot list certificaterequestpolicies.policy.cert-manager.io at the cluster scope\n",
+		},
+		"if there are no policies, return an empty list": {
+			method:  http.MethodGet,
+			auth:    listAllowed,
+			expCode: http.StatusOK,
+			expBody: `{"policies":[]}`,
+		},
+		"if there are policies, return each policy with its effective allowed and constraints ordered by name": {
+			method:   http.MethodGet,
+			auth:     listAllowed,
+			policies: []client.Object{orphan, child, base},
+			expCode:  http.StatusOK,
+			expBody: `{"policies":[` +
+				`{"name":"base","resourceVersion":"1","generation":2,"ready":true,` +
+				`"spec":{"allowed":{"isCA":false},"constraints":{"maxDuration":"1h0m0s"},"selector":{"issuerRef":{},"namespace":null,"serviceAccount":null}},` +
+				`"effective":{"allowed":{"isCA":false},"constraints":{"maxDuration":"1h0m0s"}}},` +
+				`{"name":"child","resourceVersion":"1","generation":1,"ready":false,` +
+				`"spec":{"allowed":{"usages":["server auth"]},"selector":{"issuerRef":{},"namespace":null,"serviceAccount":null},"baseRef":{"name":"base"}},` +
+				`"effective":{"allowed":{"isCA":false,"usages":["server auth"]},"constraints":{"maxDuration":"1h0m0s"}}},` +
+				`{"name":"orphan","resourceVersion":"1","generation":1,"ready":false,` +
+				`"spec":{"selector":{"issuerRef":{},"namespace":null,"serviceAccount":null},"baseRef":{"name":"not-found"}},` +
+				`"error":"failed to get base policy \"not-found\": certificaterequestpolicies.policy.cert-manager.io \"not-found\" not found"}` +
+				`]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithObjects(test.policies...).
+				Build()
+
+			p := &policyLister{log: klogr.New(), auth: test.auth, lister: fakeclient}
+
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest(test.method, "/policies", nil))
+
+			assert.Equal(t, test.expCode, rec.Code)
+			if test.expCode == http.StatusOK {
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+				assert.JSONEq(t, test.expBody, rec.Body.String())
+			} else {
+				assert.Equal(t, test.expBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -21,20 +21,44 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	"github.com/cert-manager/cert-manager/pkg/apis/certmanager"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/cert-manager/approver-policy/pkg/apis/policy"
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	policyhub "github.com/cert-manager/approver-policy/pkg/apis/policy/v1beta1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/manager/predicate"
+	"github.com/cert-manager/approver-policy/pkg/internal/inherit"
+	"github.com/cert-manager/approver-policy/pkg/internal/metrics"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 )
 
+// defaultReadyTimeout is the default maximum time that the readiness check
+// will wait for registered webhooks to report that they are ready.
+const defaultReadyTimeout = 5 * time.Second
+
 // validator validates against policy.cert-manager.io resources.
 type validator struct {
 	lock sync.RWMutex
@@ -43,8 +67,40 @@ type validator struct {
 	registeredPlugins []string
 	webhooks          []approver.Webhook
 
+	// allowedIssuerKinds are the issuer kinds that policies may select on. If
+	// empty, all issuer kinds are allowed.
+	allowedIssuerKinds []string
+
+	// allowedMetricsLabelKeys are the keys that policies may use in
+	// `spec.metricsLabels`.
+	allowedMetricsLabelKeys []string
+
+	// policyNamePattern is the pattern that the names of created policies
+	// must match. If nil, any name is allowed.
+	policyNamePattern *regexp.Regexp
+
+	// impactWarnings marks whether created and updated policies are evaluated
+	// against pending requests, with the number of requests they would newly
+	// allow or deny returned as a warning.
+	impactWarnings bool
+
+	// maxRequestBytes is the maximum size in bytes of admitted policies. If
+	// 0, the size is not limited.
+	maxRequestBytes int
+
+	// evaluators are used to evaluate pending requests when computing the
+	// impact of a policy.
+	evaluators []approver.Evaluator
+
+	// readyTimeout is the maximum time the readiness check waits for
+	// webhooks to report ready. Defaults to defaultReadyTimeout.
+	readyTimeout time.Duration
+
 	lister  client.Reader
 	decoder *admission.Decoder
+
+	// selectors caches compiled matchLabels across admission requests.
+	selectors util.SelectorCache
 }
 
 // Handle is a Kubernetes validation webhook server handler. Returns an
@@ -57,45 +113,229 @@ func (v *validator) Handle(ctx context.Context, req admission.Request) admission
 		return admission.Errored(http.StatusBadRequest, errors.New("no resource kind sent in request"))
 	}
 
-	switch *req.RequestKind {
-	case metav1.GroupVersionKind{Group: policy.GroupName, Version: "v1alpha1", Kind: "CertificateRequestPolicy"}:
-		log = log.WithValues("kind", "CertificateRequestPolicy")
+	switch kind := *req.RequestKind; {
+	case kind.Group == policy.GroupName && kind.Kind == policyapi.CertificateRequestPolicyKind:
+		log = log.WithValues("kind", "CertificateRequestPolicy", "version", kind.Version)
+
+		if req.Operation == admissionv1.Delete {
+			return v.handleDelete(ctx, log, req)
+		}
 
-		var policy policyapi.CertificateRequestPolicy
-		v.lock.RLock()
-		err := v.decoder.Decode(req, &policy)
-		v.lock.RUnlock()
+		// Oversized policies are denied before being decoded, so that they
+		// can't be used to exhaust the webhook.
+		if v.maxRequestBytes > 0 && len(req.Object.Raw) > v.maxRequestBytes {
+			log.V(2).Info("denied admission of oversized policy", "bytes", len(req.Object.Raw))
+			metrics.CertificateRequestPolicyAdmissionDenied.Inc()
+			return admission.Denied(fmt.Sprintf("CertificateRequestPolicy is %d bytes, which is larger than the maximum of %d bytes", len(req.Object.Raw), v.maxRequestBytes))
+		}
 
+		policy, err := v.decodePolicy(req.Object, kind.Version)
 		if err != nil {
 			log.Error(err, "failed to decode CertificateRequestPolicy")
 			return admission.Errored(http.StatusBadRequest, err)
 		}
 
-		el, err := v.certificateRequestPolicy(ctx, &policy)
+		el, warnings, err := v.certificateRequestPolicy(ctx, policy)
 		if err != nil {
 			log.Error(err, "internal error occurred validating request")
 			return admission.Errored(http.StatusInternalServerError, err)
 		}
 
+		// Existing policies are not required to match the name pattern, so that
+		// it may be introduced without blocking updates to them.
+		if req.Operation == admissionv1.Create && v.policyNamePattern != nil && !v.policyNamePattern.MatchString(policy.Name) {
+			el = append(el, field.Invalid(field.NewPath("metadata", "name"), policy.Name,
+				fmt.Sprintf("must match the policy name pattern %q", v.policyNamePattern.String())))
+		}
+
+		// Selectors may only be narrowed on update when explicitly allowed,
+		// since the policy may already be in use.
+		var oldPolicy *policyapi.CertificateRequestPolicy
+		if req.Operation == admissionv1.Update {
+			oldPolicy, err = v.decodePolicy(req.OldObject, kind.Version)
+			if err != nil {
+				log.Error(err, "failed to decode old CertificateRequestPolicy")
+				return admission.Errored(http.StatusBadRequest, err)
+			}
+
+			el = append(el, validateSelectorUpdate(field.NewPath("spec", "selector"), oldPolicy, policy)...)
+		}
+
 		if len(el) > 0 {
 			v.log.V(2).Info("denied admission", "errors", err)
-			return admission.Denied(el.ToAggregate().Error())
+			metrics.CertificateRequestPolicyAdmissionDenied.Inc()
+			return admission.Denied(el.ToAggregate().Error()).WithWarnings(warnings...)
+		}
+
+		// The impact is only informational, so failing to compute it doesn't
+		// deny the request.
+		if v.impactWarnings {
+			warning, err := v.impact(ctx, oldPolicy, policy)
+			if err != nil {
+				log.Error(err, "failed to compute impact of policy on pending requests")
+			} else {
+				warnings = append(warnings, warning)
+			}
 		}
 
 		log.V(2).Info("allowed request")
-		return admission.Allowed("CertificateRequestPolicy validated")
+		metrics.CertificateRequestPolicyAdmissionAllowed.Inc()
+		return admission.Allowed("CertificateRequestPolicy validated").WithWarnings(warnings...)
 
 	default:
 		return admission.Denied(fmt.Sprintf("validation request for unrecognised resource type: %s/%s %s", req.RequestKind.Group, req.RequestKind.Version, req.RequestKind.Kind))
 	}
 }
 
+// handleDelete handles the deletion of a CertificateRequestPolicy. Deleting a
+// policy which selects pending CertificateRequests may mean they are no longer
+// approved, so is denied unless the policy has the ForceDeleteAnnotationKey
+// annotation.
+func (v *validator) handleDelete(ctx context.Context, log logr.Logger, req admission.Request) admission.Response {
+	policy, err := v.decodePolicy(req.OldObject, req.RequestKind.Version)
+	if err != nil {
+		log.Error(err, "failed to decode deleted CertificateRequestPolicy")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	el, err := v.validateDelete(ctx, policy)
+	if err != nil {
+		log.Error(err, "internal error occurred validating deletion")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if len(el) > 0 {
+		log.V(2).Info("denied admission", "errors", el)
+		metrics.CertificateRequestPolicyAdmissionDenied.Inc()
+		return admission.Denied(el.ToAggregate().Error())
+	}
+
+	log.V(2).Info("allowed request")
+	metrics.CertificateRequestPolicyAdmissionAllowed.Inc()
+	return admission.Allowed("CertificateRequestPolicy deletion validated")
+}
+
+// decodePolicy decodes the raw CertificateRequestPolicy of the given served
+// version by way of the hub version, so that every served version is decoded
+// through the same conversion as stored objects.
+// Validating the hub type itself is deferred: the validator, plugin webhooks
+// (approver.Webhook) and policy inheritance all operate on v1alpha1, so the
+// hub is converted back to v1alpha1 before validation. Since the conversion is
+// lossless, this validates the same policy which would be stored.
+func (v *validator) decodePolicy(raw runtime.RawExtension, version string) (*policyapi.CertificateRequestPolicy, error) {
+	obj, err := policyapi.GlobalScheme.New(schema.GroupVersionKind{Group: policy.GroupName, Version: version, Kind: policyapi.CertificateRequestPolicyKind})
+	if err != nil {
+		return nil, err
+	}
+
+	v.lock.RLock()
+	err = v.decoder.DecodeRaw(raw, obj)
+	v.lock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	hub, ok := obj.(*policyhub.CertificateRequestPolicy)
+	if !ok {
+		convertible, ok := obj.(conversion.Convertible)
+		if !ok {
+			return nil, fmt.Errorf("CertificateRequestPolicy version %q can't be converted to the hub version", version)
+		}
+		hub = new(policyhub.CertificateRequestPolicy)
+		if err := convertible.ConvertTo(hub); err != nil {
+			return nil, fmt.Errorf("failed to convert CertificateRequestPolicy to the hub version: %w", err)
+		}
+	}
+
+	policy := new(policyapi.CertificateRequestPolicy)
+	if err := policy.ConvertFrom(hub); err != nil {
+		return nil, fmt.Errorf("failed to convert CertificateRequestPolicy from the hub version: %w", err)
+	}
+	return policy, nil
+}
+
+// validateDelete returns an error if the given CertificateRequestPolicy
+// selects CertificateRequests which are pending, i.e. neither approved nor
+// denied, and the policy doesn't have the ForceDeleteAnnotationKey annotation.
+// Only the policy's selector is considered, not whether it is ready, active or
+// bound to the requester with RBAC.
+func (v *validator) validateDelete(ctx context.Context, policy *policyapi.CertificateRequestPolicy) (field.ErrorList, error) {
+	if _, ok := policy.GetAnnotations()[policyapi.ForceDeleteAnnotationKey]; ok {
+		return nil, nil
+	}
+
+	var requests cmapi.CertificateRequestList
+	if err := v.lister.List(ctx, &requests); err != nil {
+		return nil, fmt.Errorf("failed to list CertificateRequests: %w", err)
+	}
+
+	var pending []string
+	for i := range requests.Items {
+		request := &requests.Items[i]
+		if apiutil.CertificateRequestIsApproved(request) || apiutil.CertificateRequestIsDenied(request) {
+			continue
+		}
+
+		selected, err := v.selects(ctx, policy, request)
+		if err != nil {
+			return nil, err
+		}
+
+		if selected {
+			pending = append(pending, request.Namespace+"/"+request.Name)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	// Sort list so testing is deterministic.
+	sort.Strings(pending)
+	return field.ErrorList{field.Forbidden(field.NewPath("metadata", "name"),
+		fmt.Sprintf("policy may not be deleted, as it selects pending CertificateRequests which may no longer be approved: %s; set the %q annotation to allow",
+			strings.Join(pending, ", "), policyapi.ForceDeleteAnnotationKey),
+	)}, nil
+}
+
+// selects returns whether the policy's selector matches the request. Whether
+// the policy is ready, active or bound to the requester with RBAC is not
+// considered.
+func (v *validator) selects(ctx context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (bool, error) {
+	predicates := []predicate.Predicate{
+		predicate.SelectorIssuerRef(v.lister),
+		predicate.SelectorNamespace(v.lister),
+		predicate.SelectorServiceAccount(v.lister),
+		predicate.SelectorCertificateLabels(v.lister),
+		predicate.SelectorSecret(v.lister),
+		predicate.SelectorIsRenewal(v.lister),
+		predicate.SelectorRequester,
+		predicate.SelectorRequestAnnotations,
+		predicate.SelectorRequestLabels(),
+		predicate.SelectorDuration,
+	}
+
+	policies := []policyapi.CertificateRequestPolicy{*policy}
+	for _, fn := range predicates {
+		var err error
+		policies, err = fn(ctx, request, policies)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return len(policies) > 0, nil
+}
+
 // certificateRequestPolicy validates the given CertificateRequestPolicy with
 // the base validations, along with all webhook validations registered.
-func (v *validator) certificateRequestPolicy(ctx context.Context, policy *policyapi.CertificateRequestPolicy) (field.ErrorList, error) {
+// Returns the validation errors, along with any warnings from the registered
+// webhooks.
+func (v *validator) certificateRequestPolicy(ctx context.Context, policy *policyapi.CertificateRequestPolicy) (field.ErrorList, []string, error) {
 	var (
-		el      field.ErrorList
-		fldPath = field.NewPath("spec")
+		el       field.ErrorList
+		warnings []string
+		fldPath  = field.NewPath("spec")
 	)
 
 	// Ensure no plugin has been defined which is not registered.
@@ -122,29 +362,714 @@ func (v *validator) certificateRequestPolicy(ctx context.Context, policy *policy
 		}
 	}
 
-	if policy.Spec.Selector.IssuerRef == nil && policy.Spec.Selector.Namespace == nil {
-		el = append(el, field.Required(fldPath.Child("selector"), "one of issuerRef or namespace must be defined, hint: `{}` on either matches everything"))
+	el = append(el, validatePluginValues(fldPath.Child("plugins"), policy.Spec.Plugins, v.webhooks)...)
+
+	// Plugin selectors are evaluated without looking up the issuer of the
+	// request, so can't select on issuer labels.
+	for _, name := range sets.List(sets.KeySet(policy.Spec.Plugins)) {
+		if selector := policy.Spec.Plugins[name].Selector; selector != nil && selector.IssuerRef != nil && selector.IssuerRef.MatchLabels != nil {
+			el = append(el, field.Forbidden(fldPath.Child("plugins").Key(name).Child("selector", "issuerRef", "matchLabels"), "matchLabels is not supported on plugin selectors"))
+		}
+	}
+
+	if policy.Spec.Selector.IssuerRef == nil && policy.Spec.Selector.IssuerRefs == nil && policy.Spec.Selector.Namespace == nil && policy.Spec.Selector.ServiceAccount == nil {
+		el = append(el, field.Required(fldPath.Child("selector"), "one of issuerRef, issuerRefs, namespace or serviceAccount must be defined, hint: `{}` on any matches everything"))
+	}
+
+	if policy.Spec.Selector.IssuerRef != nil && policy.Spec.Selector.IssuerRefs != nil {
+		el = append(el, field.Forbidden(fldPath.Child("selector", "issuerRefs"), "issuerRefs may not be defined together with issuerRef"))
+	}
+
+	issRefSels := util.IssuerRefSelectors(&policy.Spec.Selector)
+
+	// issRefField returns the field name of the i-th issuerRef selector, as
+	// used by the predicate to cache its compiled matchLabels, and issRefPath
+	// its path.
+	issRefField := func(i int) string {
+		if policy.Spec.Selector.IssuerRef != nil {
+			return "issuerRef"
+		}
+		return fmt.Sprintf("issuerRefs[%d]", i)
+	}
+	issRefPath := func(i int) *field.Path {
+		if policy.Spec.Selector.IssuerRef != nil {
+			return fldPath.Child("selector", "issuerRef")
+		}
+		return fldPath.Child("selector", "issuerRefs").Index(i)
+	}
+
+	for i, issRefSel := range issRefSels {
+		if len(issRefSel.MatchLabels) == 0 {
+			continue
+		}
+
+		if _, err := v.selectors.Selector(policy, issRefField(i), issRefSel.MatchLabels); err != nil {
+			el = append(el, field.Invalid(issRefPath(i).Child("matchLabels"), issRefSel.MatchLabels, err.Error()))
+		}
+
+		// When combined with matchLabels, name must be a valid issuer name,
+		// optionally containing wildcards.
+		if name := issRefSel.Name; name != nil && len(*name) > 0 {
+			for _, msg := range validation.IsDNS1123Subdomain(strings.ReplaceAll(*name, "*", "x")) {
+				el = append(el, field.Invalid(issRefPath(i).Child("name"), *name, msg))
+			}
+		}
+	}
+
+	// If issuer kinds are restricted, policies must select on exactly one of
+	// the allowed kinds. Omitted or wildcard kinds may match any kind, so are
+	// not allowed. Every issuerRefs entry must select on an allowed kind.
+	if len(v.allowedIssuerKinds) > 0 {
+		if len(issRefSels) == 0 {
+			el = append(el, field.NotSupported(fldPath.Child("selector", "issuerRef", "kind"), "", v.allowedIssuerKinds))
+		}
+		for i, issRefSel := range issRefSels {
+			var kind string
+			if issRefSel.Kind != nil {
+				kind = *issRefSel.Kind
+			}
+			if !sets.New(v.allowedIssuerKinds...).Has(kind) {
+				el = append(el, field.NotSupported(issRefPath(i).Child("kind"), kind, v.allowedIssuerKinds))
+			}
+		}
 	}
 
 	if nsSel := policy.Spec.Selector.Namespace; nsSel != nil && len(nsSel.MatchLabels) > 0 {
-		if _, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: nsSel.MatchLabels}); err != nil {
+		if _, err := v.selectors.Selector(policy, "namespace", nsSel.MatchLabels); err != nil {
 			el = append(el, field.Invalid(fldPath.Child("selector", "namespace", "matchLabels"), nsSel.MatchLabels, err.Error()))
 		}
 	}
 
+	if saSel := policy.Spec.Selector.ServiceAccount; saSel != nil {
+		for i, name := range saSel.MatchNames {
+			if len(name) == 0 || strings.Count(name, ":") > 1 {
+				el = append(el, field.Invalid(fldPath.Child("selector", "serviceAccount", "matchNames").Index(i), name, "must be in the form `<name>` or `<namespace>:<name>`"))
+			}
+		}
+		if len(saSel.MatchLabels) > 0 {
+			if _, err := v.selectors.Selector(policy, "serviceAccount", saSel.MatchLabels); err != nil {
+				el = append(el, field.Invalid(fldPath.Child("selector", "serviceAccount", "matchLabels"), saSel.MatchLabels, err.Error()))
+			}
+		}
+	}
+
+	if certSel := policy.Spec.Selector.CertificateLabels; certSel != nil && len(certSel.MatchLabels) > 0 {
+		if _, err := v.selectors.Selector(policy, "certificateLabels", certSel.MatchLabels); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("selector", "certificateLabels", "matchLabels"), certSel.MatchLabels, err.Error()))
+		}
+	}
+
+	if secretSel := policy.Spec.Selector.SecretLabels; secretSel != nil && len(secretSel.MatchLabels) > 0 {
+		if _, err := v.selectors.Selector(policy, "secretLabels", secretSel.MatchLabels); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("selector", "secretLabels", "matchLabels"), secretSel.MatchLabels, err.Error()))
+		}
+	}
+
+	for _, key := range sets.List(sets.KeySet(policy.Spec.Selector.RequestAnnotations)) {
+		for _, msg := range validation.IsQualifiedName(key) {
+			el = append(el, field.Invalid(fldPath.Child("selector", "requestAnnotations").Key(key), key, msg))
+		}
+	}
+
+	if reqSel := policy.Spec.Selector.RequestLabels; reqSel != nil {
+		el = append(el, validateRequestLabels(fldPath.Child("selector", "requestLabels"), reqSel)...)
+	}
+
+	if minDur := policy.Spec.Selector.MinDuration; minDur != nil && minDur.Duration < 0 {
+		el = append(el, field.Invalid(fldPath.Child("selector", "minDuration"), minDur.Duration.String(), "minDuration must be a value greater or equal to 0"))
+	}
+	if maxDur := policy.Spec.Selector.MaxDuration; maxDur != nil && maxDur.Duration < 0 {
+		el = append(el, field.Invalid(fldPath.Child("selector", "maxDuration"), maxDur.Duration.String(), "maxDuration must be a value greater or equal to 0"))
+	}
+	if minDur, maxDur := policy.Spec.Selector.MinDuration, policy.Spec.Selector.MaxDuration; minDur != nil && maxDur != nil && maxDur.Duration < minDur.Duration {
+		el = append(el, field.Invalid(fldPath.Child("selector", "maxDuration"), maxDur.Duration.String(), "maxDuration must be the same value as minDuration or larger"))
+	}
+
+	if policy.Spec.Priority != nil && *policy.Spec.Priority < 0 {
+		el = append(el, field.Invalid(fldPath.Child("priority"), *policy.Spec.Priority, "must be greater than or equal to 0"))
+	}
+
+	if enforcement := policy.Spec.Enforcement; enforcement != nil {
+		switch *enforcement {
+		case policyapi.EnforcementDeny:
+		case policyapi.EnforcementWarn:
+			warnings = append(warnings, fmt.Sprintf("%s: requests which this policy would deny are approved with a warning", fldPath.Child("enforcement")))
+		default:
+			el = append(el, field.NotSupported(fldPath.Child("enforcement"), *enforcement, []string{string(policyapi.EnforcementDeny), string(policyapi.EnforcementWarn)}))
+		}
+	}
+
+	el = append(el, validateActiveSchedule(fldPath.Child("activeSchedule"), policy.Spec.ActiveSchedule)...)
+
+	el = append(el, validateRateLimit(fldPath.Child("rateLimit"), policy.Spec.RateLimit)...)
+
+	el = append(el, validateMetricsLabels(fldPath.Child("metricsLabels"), policy.Spec.MetricsLabels, v.allowedMetricsLabelKeys)...)
+
+	el = append(el, validateSatisfiable(fldPath, policy.Spec)...)
+
+	baseEl, err := v.validateBaseRef(ctx, fldPath.Child("baseRef"), policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	el = append(el, baseEl...)
+
+	bootstrapEl, err := v.validateBootstrapOnly(ctx, fldPath, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	el = append(el, bootstrapEl...)
+
 	for _, webhook := range v.webhooks {
 		response, err := webhook.Validate(ctx, policy)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if !response.Allowed {
 			el = append(el, response.Errors...)
 		}
+		warnings = append(warnings, response.Warnings...)
+	}
+
+	return el, warnings, nil
+}
+
+// validateBaseRef returns errors if the base policy referenced by the policy
+// doesn't exist, or its chain of base policies forms a cycle or is too deep.
+func (v *validator) validateBaseRef(ctx context.Context, fldPath *field.Path, policy *policyapi.CertificateRequestPolicy) (field.ErrorList, error) {
+	baseRef := policy.Spec.BaseRef
+	if baseRef == nil {
+		return nil, nil
+	}
+
+	if len(baseRef.Name) == 0 {
+		return field.ErrorList{field.Required(fldPath.Child("name"), "the name of the base policy must be defined")}, nil
+	}
+
+	_, err := inherit.Chain(ctx, v.lister, policy)
+	switch {
+	case err == nil:
+		return nil, nil
+	case apierrors.IsNotFound(err):
+		return field.ErrorList{field.NotFound(fldPath.Child("name"), baseRef.Name)}, nil
+	case errors.Is(err, inherit.ErrCycle), errors.Is(err, inherit.ErrMaxDepth):
+		return field.ErrorList{field.Invalid(fldPath.Child("name"), baseRef.Name, err.Error())}, nil
+	default:
+		return nil, err
+	}
+}
+
+// validateBootstrapOnly returns errors if the policy is bootstrap only, but may
+// select an issuer which isn't SelfSigned: either because its selector doesn't
+// select exactly one issuer, or because a selected issuer exists and isn't
+// SelfSigned. Issuers which don't exist yet can't be checked, so are
+// permitted.
+func (v *validator) validateBootstrapOnly(ctx context.Context, fldPath *field.Path, policy *policyapi.CertificateRequestPolicy) (field.ErrorList, error) {
+	if !policy.Spec.BootstrapOnly {
+		return nil, nil
+	}
+
+	issRefPath := fldPath.Child("selector", "issuerRef")
+	issRefSel := policy.Spec.Selector.IssuerRef
+	if issRefSel == nil {
+		return field.ErrorList{field.Required(issRefPath, "bootstrapOnly policies must select a SelfSigned issuer")}, nil
+	}
+
+	var (
+		el                field.ErrorList
+		name, kind, group string
+		namespaces        = []string{""}
+		supportedKinds    = []string{cmapi.IssuerKind, cmapi.ClusterIssuerKind}
+		namespacePath     = fldPath.Child("selector", "namespace", "matchNames")
+	)
+	if issRefSel.Name != nil {
+		name = *issRefSel.Name
+	}
+	if issRefSel.Kind != nil {
+		kind = *issRefSel.Kind
+	}
+	if issRefSel.Group != nil {
+		group = *issRefSel.Group
+	}
+
+	if len(name) == 0 || strings.Contains(name, "*") {
+		el = append(el, field.Invalid(issRefPath.Child("name"), name, "bootstrapOnly policies must select the name of a SelfSigned issuer exactly"))
+	}
+	if !sets.New(supportedKinds...).Has(kind) {
+		el = append(el, field.NotSupported(issRefPath.Child("kind"), kind, supportedKinds))
+	}
+	if group != certmanager.GroupName {
+		el = append(el, field.NotSupported(issRefPath.Child("group"), group, []string{certmanager.GroupName}))
+	}
+	if kind == cmapi.IssuerKind {
+		nsSel := policy.Spec.Selector.Namespace
+		if nsSel == nil || len(nsSel.MatchNames) == 0 {
+			el = append(el, field.Required(namespacePath, "bootstrapOnly policies selecting an Issuer must select its namespaces by name"))
+		} else {
+			for i, namespace := range nsSel.MatchNames {
+				if strings.Contains(namespace, "*") {
+					el = append(el, field.Invalid(namespacePath.Index(i), namespace, "bootstrapOnly policies selecting an Issuer must select its namespaces exactly"))
+				}
+			}
+			namespaces = nsSel.MatchNames
+		}
+	}
+	if len(el) > 0 {
+		return el, nil
+	}
+
+	for _, namespace := range namespaces {
+		var issuer cmapi.GenericIssuer = new(cmapi.ClusterIssuer)
+		if kind == cmapi.IssuerKind {
+			issuer = new(cmapi.Issuer)
+		}
+		key := client.ObjectKey{Namespace: namespace, Name: name}
+		if err := v.lister.Get(ctx, key, issuer); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s %s: %w", kind, key, err)
+		}
+		if issuer.GetSpec().SelfSigned == nil {
+			el = append(el, field.Invalid(issRefPath.Child("name"), name, fmt.Sprintf("bootstrapOnly policies must select a SelfSigned issuer, but %s %q is not SelfSigned", kind, strings.TrimPrefix(key.String(), "/"))))
+		}
 	}
 
 	return el, nil
 }
 
+// validateMetricsLabels returns errors if the given metrics labels use a key
+// which is not allowed, or a value which is not a valid label value.
+func validateMetricsLabels(fldPath *field.Path, metricsLabels map[string]string, allowedKeys []string) field.ErrorList {
+	var (
+		el      field.ErrorList
+		allowed = sets.New(allowedKeys...)
+	)
+	for _, key := range sets.List(sets.KeySet(metricsLabels)) {
+		if !allowed.Has(key) {
+			el = append(el, field.NotSupported(fldPath, key, allowedKeys))
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(metricsLabels[key]) {
+			el = append(el, field.Invalid(fldPath.Key(key), metricsLabels[key], msg))
+		}
+	}
+	return el
+}
+
+// validateActiveSchedule returns errors if the given schedule has an unknown
+// time zone, or a window which can't be parsed.
+func validateActiveSchedule(fldPath *field.Path, schedule *policyapi.CertificateRequestPolicyActiveSchedule) field.ErrorList {
+	if schedule == nil {
+		return nil
+	}
+
+	var el field.ErrorList
+
+	if _, err := util.LoadTimeZone(schedule.TimeZone); err != nil {
+		el = append(el, field.Invalid(fldPath.Child("timeZone"), schedule.TimeZone, err.Error()))
+	}
+
+	if len(schedule.Windows) == 0 {
+		el = append(el, field.Required(fldPath.Child("windows"), "at least one window must be defined"))
+	}
+
+	for i, window := range schedule.Windows {
+		fldPath := fldPath.Child("windows").Index(i)
+		for j, day := range window.Days {
+			if _, ok := util.ParseWeekday(day); !ok {
+				el = append(el, field.NotSupported(fldPath.Child("days").Index(j), day, util.Weekdays))
+			}
+		}
+		if _, err := util.ParseTimeOfDay(window.Start); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("start"), window.Start, err.Error()))
+		}
+		if _, err := util.ParseTimeOfDay(window.End); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("end"), window.End, err.Error()))
+		}
+	}
+
+	return el
+}
+
+// validateRateLimit returns errors if the given rate limit would never permit
+// an approval.
+func validateRateLimit(fldPath *field.Path, rateLimit *policyapi.CertificateRequestPolicyRateLimit) field.ErrorList {
+	if rateLimit == nil {
+		return nil
+	}
+
+	var el field.ErrorList
+	if rateLimit.Requests < 1 {
+		el = append(el, field.Invalid(fldPath.Child("requests"), rateLimit.Requests, "must be greater than or equal to 1"))
+	}
+	if rateLimit.Window.Duration <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("window"), rateLimit.Window.Duration.String(), "must be greater than 0"))
+	}
+	return el
+}
+
+// validateSatisfiable returns errors for combinations of allowed and
+// constraints fields which no CertificateRequest could ever satisfy, so that
+// logically impossible policies are rejected at admission time. Fields which
+// are impossible on their own are validated by their approver.
+func validateSatisfiable(fldPath *field.Path, spec policyapi.CertificateRequestPolicySpec) field.ErrorList {
+	var (
+		el       field.ErrorList
+		allowed  = spec.Allowed
+		consts   = spec.Constraints
+		required = func(slice *policyapi.CertificateRequestPolicyAllowedStringSlice) bool {
+			return slice != nil && slice.Required != nil && *slice.Required
+		}
+	)
+
+	if allowed == nil {
+		allowed = new(policyapi.CertificateRequestPolicyAllowed)
+	}
+
+	type stringSlice struct {
+		path    *field.Path
+		slice   *policyapi.CertificateRequestPolicyAllowedStringSlice
+		sanType string
+	}
+
+	allowedPath := fldPath.Child("allowed")
+	sans := []stringSlice{
+		{allowedPath.Child("dnsNames"), allowed.DNSNames, "DNS"},
+		{allowedPath.Child("ipAddresses"), allowed.IPAddresses, "IP"},
+		{allowedPath.Child("uris"), allowed.URIs, "URI"},
+		{allowedPath.Child("emailAddresses"), allowed.EmailAddresses, "Email"},
+	}
+
+	slices := append([]stringSlice{}, sans...)
+	if sub := allowed.Subject; sub != nil {
+		subPath := allowedPath.Child("subject")
+		slices = append(slices,
+			stringSlice{path: subPath.Child("organizations"), slice: sub.Organizations},
+			stringSlice{path: subPath.Child("countries"), slice: sub.Countries},
+			stringSlice{path: subPath.Child("organizationalUnits"), slice: sub.OrganizationalUnits},
+			stringSlice{path: subPath.Child("localities"), slice: sub.Localities},
+			stringSlice{path: subPath.Child("provinces"), slice: sub.Provinces},
+			stringSlice{path: subPath.Child("streetAddresses"), slice: sub.StreetAddresses},
+			stringSlice{path: subPath.Child("postalCodes"), slice: sub.PostalCodes},
+		)
+	}
+
+	// A required field with an empty list of values, and no values from a
+	// ConfigMap, can never be satisfied. Required fields with no values are
+	// rejected by the allowed approver.
+	for _, slice := range slices {
+		if required(slice.slice) && slice.slice.Values != nil && len(*slice.slice.Values) == 0 && slice.slice.ValuesFrom == nil {
+			el = append(el, field.Invalid(slice.path.Child("values"), *slice.slice.Values, "values must not be empty if required is true, otherwise all requests are denied"))
+		}
+	}
+
+	if consts == nil {
+		return el
+	}
+
+	if consts.IsCA != nil && *consts.IsCA && (allowed.IsCA == nil || !*allowed.IsCA) {
+		el = append(el, field.Invalid(fldPath.Child("constraints", "isCA"), *consts.IsCA, "allowed.isCA must be true if the isCA constraint is true, otherwise all requests are denied"))
+	}
+
+	// Every required SAN type must be requested at least once, which must fit
+	// within maxSANCount.
+	if consts.MaxSANCount != nil {
+		var requiredSANs int
+		for _, san := range sans {
+			if required(san.slice) {
+				requiredSANs++
+			}
+		}
+		if requiredSANs > 0 && consts.MaxSANCountPerType != nil && *consts.MaxSANCountPerType {
+			requiredSANs = 1
+		}
+		if requiredSANs > *consts.MaxSANCount {
+			el = append(el, field.Invalid(fldPath.Child("constraints", "maxSANCount"), *consts.MaxSANCount, fmt.Sprintf("maxSANCount must be at least %d to allow the required SAN types, otherwise all requests are denied", requiredSANs)))
+		}
+	}
+
+	// A required SAN type must be requested, so can't be forbidden.
+	if consts.AllowedSANTypes != nil {
+		sanTypes := sets.New(consts.AllowedSANTypes...)
+		for _, san := range sans {
+			if required(san.slice) && !sanTypes.Has(san.sanType) {
+				el = append(el, field.Invalid(fldPath.Child("constraints", "allowedSANTypes"), consts.AllowedSANTypes, fmt.Sprintf("allowedSANTypes must contain %q if %s is required, otherwise all requests are denied", san.sanType, san.path)))
+			}
+		}
+	}
+
+	return el
+}
+
+// validateSelectorUpdate validates that an update to a
+// CertificateRequestPolicy doesn't narrow its selector. Narrowing the selector
+// of a policy which is in use means requests it previously selected may no
+// longer be, and so may no longer be approved. Narrowing is permitted if the
+// updated policy has the AllowSelectorChangeAnnotationKey annotation.
+// A change is considered narrowing unless it can only select more requests.
+// Wildcard patterns are only considered wider than a changed pattern when they
+// are "*".
+func validateSelectorUpdate(fldPath *field.Path, oldPolicy, policy *policyapi.CertificateRequestPolicy) field.ErrorList {
+	if _, ok := policy.GetAnnotations()[policyapi.AllowSelectorChangeAnnotationKey]; ok {
+		return nil
+	}
+
+	var (
+		el     field.ErrorList
+		oldSel = oldPolicy.Spec.Selector
+		newSel = policy.Spec.Selector
+		detail = fmt.Sprintf("selector may not be narrowed on update, as requests it selects may no longer be approved; set the %q annotation to allow", policyapi.AllowSelectorChangeAnnotationKey)
+	)
+
+	if newSel.IssuerRef != nil && len(oldSel.IssuerRefs) == 0 {
+		fldPath := fldPath.Child("issuerRef")
+		oldRef := oldSel.IssuerRef
+		if oldRef == nil {
+			oldRef = new(policyapi.CertificateRequestPolicySelectorIssuerRef)
+		}
+		if !patternWidened(oldSel.IssuerRef != nil, oldRef.Name, newSel.IssuerRef.Name) {
+			el = append(el, field.Forbidden(fldPath.Child("name"), detail))
+		}
+		if !patternWidened(oldSel.IssuerRef != nil, oldRef.Kind, newSel.IssuerRef.Kind) {
+			el = append(el, field.Forbidden(fldPath.Child("kind"), detail))
+		}
+		if !patternWidened(oldSel.IssuerRef != nil, oldRef.Group, newSel.IssuerRef.Group) {
+			el = append(el, field.Forbidden(fldPath.Child("group"), detail))
+		}
+		if !labelsWidened(oldRef.MatchLabels, newSel.IssuerRef.MatchLabels) {
+			el = append(el, field.Forbidden(fldPath.Child("matchLabels"), detail))
+		}
+	} else if newRefs := util.IssuerRefSelectors(&newSel); len(newRefs) > 0 {
+		// Every issuer selected by an old entry must still be selected by one
+		// of the new entries. An omitted old selector selected every issuer.
+		oldRefs := util.IssuerRefSelectors(&oldSel)
+		oldDefined := len(oldRefs) > 0
+		if !oldDefined {
+			oldRefs = []policyapi.CertificateRequestPolicySelectorIssuerRef{{}}
+		}
+		fldName := "issuerRefs"
+		if newSel.IssuerRef != nil {
+			fldName = "issuerRef"
+		}
+		for _, oldRef := range oldRefs {
+			if !issuerRefsWidened(oldDefined, oldRef, newRefs) {
+				el = append(el, field.Forbidden(fldPath.Child(fldName), detail))
+				break
+			}
+		}
+	}
+
+	if newSel.Namespace != nil {
+		fldPath := fldPath.Child("namespace")
+		var old policyapi.CertificateRequestPolicySelectorNamespace
+		if oldSel.Namespace != nil {
+			old = *oldSel.Namespace
+		}
+		if !namesWidened(old.MatchNames, newSel.Namespace.MatchNames) {
+			el = append(el, field.Forbidden(fldPath.Child("matchNames"), detail))
+		}
+		if !labelsWidened(old.MatchLabels, newSel.Namespace.MatchLabels) {
+			el = append(el, field.Forbidden(fldPath.Child("matchLabels"), detail))
+		}
+	}
+
+	if newSel.ServiceAccount != nil {
+		fldPath := fldPath.Child("serviceAccount")
+		if oldSel.ServiceAccount == nil {
+			// A ServiceAccount selector never selects requests which weren't
+			// created by a ServiceAccount.
+			el = append(el, field.Forbidden(fldPath, detail))
+		} else {
+			if !namesWidened(oldSel.ServiceAccount.MatchNames, newSel.ServiceAccount.MatchNames) {
+				el = append(el, field.Forbidden(fldPath.Child("matchNames"), detail))
+			}
+			if !labelsWidened(oldSel.ServiceAccount.MatchLabels, newSel.ServiceAccount.MatchLabels) {
+				el = append(el, field.Forbidden(fldPath.Child("matchLabels"), detail))
+			}
+		}
+	}
+
+	if newSel.CertificateLabels != nil {
+		fldPath := fldPath.Child("certificateLabels")
+		if oldSel.CertificateLabels == nil {
+			// A Certificate labels selector never selects requests which aren't
+			// owned by a Certificate.
+			el = append(el, field.Forbidden(fldPath, detail))
+		} else if !labelsWidened(oldSel.CertificateLabels.MatchLabels, newSel.CertificateLabels.MatchLabels) {
+			el = append(el, field.Forbidden(fldPath.Child("matchLabels"), detail))
+		}
+	}
+
+	// Secret selectors never select requests which aren't owned by a
+	// Certificate, so even "*" narrows a selector which didn't select on them.
+	if newSel.SecretName != nil && (oldSel.SecretName == nil || !patternWidened(true, oldSel.SecretName, newSel.SecretName)) {
+		el = append(el, field.Forbidden(fldPath.Child("secretName"), detail))
+	}
+	if newSel.SecretLabels != nil {
+		fldPath := fldPath.Child("secretLabels")
+		if oldSel.SecretLabels == nil {
+			el = append(el, field.Forbidden(fldPath, detail))
+		} else if !labelsWidened(oldSel.SecretLabels.MatchLabels, newSel.SecretLabels.MatchLabels) {
+			el = append(el, field.Forbidden(fldPath.Child("matchLabels"), detail))
+		}
+	}
+
+	if newSel.Requester != nil {
+		fldPath := fldPath.Child("requester")
+		var old policyapi.CertificateRequestPolicySelectorRequester
+		if oldSel.Requester != nil {
+			old = *oldSel.Requester
+		}
+		// Requests without a recorded username or groups never match on them,
+		// so even "*" narrows a selector which didn't select on them.
+		if len(newSel.Requester.Usernames) > 0 && (len(old.Usernames) == 0 || !namesWidened(old.Usernames, newSel.Requester.Usernames)) {
+			el = append(el, field.Forbidden(fldPath.Child("usernames"), detail))
+		}
+		if len(newSel.Requester.Groups) > 0 && (len(old.Groups) == 0 || !namesWidened(old.Groups, newSel.Requester.Groups)) {
+			el = append(el, field.Forbidden(fldPath.Child("groups"), detail))
+		}
+	}
+
+	if newSel.RequestLabels != nil {
+		fldPath := fldPath.Child("requestLabels")
+		var old policyapi.CertificateRequestPolicySelectorRequestLabels
+		if oldSel.RequestLabels != nil {
+			old = *oldSel.RequestLabels
+		}
+		if !labelsWidened(old.MatchLabels, newSel.RequestLabels.MatchLabels) {
+			el = append(el, field.Forbidden(fldPath.Child("matchLabels"), detail))
+		}
+		if !expressionsWidened(old.MatchExpressions, newSel.RequestLabels.MatchExpressions) {
+			el = append(el, field.Forbidden(fldPath.Child("matchExpressions"), detail))
+		}
+	}
+
+	for key, value := range newSel.RequestAnnotations {
+		oldValue, ok := oldSel.RequestAnnotations[key]
+		if !ok || (value != "*" && value != oldValue) {
+			el = append(el, field.Forbidden(fldPath.Child("requestAnnotations"), detail))
+			break
+		}
+	}
+
+	// The duration selectors only select on requests if either MinDuration or
+	// MaxDuration are defined.
+	var (
+		oldDurationSelected = oldSel.MinDuration != nil || oldSel.MaxDuration != nil
+		newDurationSelected = newSel.MinDuration != nil || newSel.MaxDuration != nil
+	)
+	if newDurationSelected {
+		if newSel.MinDuration != nil && (oldSel.MinDuration == nil || newSel.MinDuration.Duration > oldSel.MinDuration.Duration) {
+			el = append(el, field.Forbidden(fldPath.Child("minDuration"), detail))
+		}
+		if newSel.MaxDuration != nil && (oldSel.MaxDuration == nil || newSel.MaxDuration.Duration < oldSel.MaxDuration.Duration) {
+			el = append(el, field.Forbidden(fldPath.Child("maxDuration"), detail))
+		}
+		oldSelectsMissing := !oldDurationSelected || (oldSel.SelectOnMissingDuration != nil && *oldSel.SelectOnMissingDuration)
+		newSelectsMissing := newSel.SelectOnMissingDuration != nil && *newSel.SelectOnMissingDuration
+		if oldSelectsMissing && !newSelectsMissing {
+			el = append(el, field.Forbidden(fldPath.Child("selectOnMissingDuration"), detail))
+		}
+	}
+
+	if newSel.IsRenewal != nil && (oldSel.IsRenewal == nil || *oldSel.IsRenewal != *newSel.IsRenewal) {
+		el = append(el, field.Forbidden(fldPath.Child("isRenewal"), detail))
+	}
+
+	return el
+}
+
+// issuerRefsWidened returns whether any of the new issuerRef selectors
+// selects at least the issuers selected by the old issuerRef selector.
+// oldDefined is false if the old selector was omitted.
+func issuerRefsWidened(oldDefined bool, oldRef policyapi.CertificateRequestPolicySelectorIssuerRef, newRefs []policyapi.CertificateRequestPolicySelectorIssuerRef) bool {
+	for _, newRef := range newRefs {
+		if patternWidened(oldDefined, oldRef.Name, newRef.Name) &&
+			patternWidened(oldDefined, oldRef.Kind, newRef.Kind) &&
+			patternWidened(oldDefined, oldRef.Group, newRef.Group) &&
+			labelsWidened(oldRef.MatchLabels, newRef.MatchLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// patternWidened returns whether the new wildcard pattern selects at least the
+// values selected by the old pattern, i.e. it is omitted, "*", or unchanged.
+// oldDefined is false if the old pattern's selector was omitted, in which case
+// all values were selected.
+func patternWidened(oldDefined bool, oldPattern, newPattern *string) bool {
+	if newPattern == nil || *newPattern == "*" {
+		return true
+	}
+	return oldDefined && oldPattern != nil && *oldPattern == *newPattern
+}
+
+// namesWidened returns whether the new names select at least the names
+// selected by the old names. Empty names select all.
+func namesWidened(oldNames, newNames []string) bool {
+	if len(newNames) == 0 {
+		return true
+	}
+	newSet := sets.New(newNames...)
+	if newSet.Has("*") {
+		return true
+	}
+	return len(oldNames) > 0 && newSet.HasAll(oldNames...)
+}
+
+// labelsWidened returns whether the new labels select at least the objects
+// selected by the old labels, i.e. the new labels are a subset of the old.
+// nil labels select all.
+func labelsWidened(oldLabels, newLabels map[string]string) bool {
+	if newLabels == nil {
+		return true
+	}
+	if oldLabels == nil {
+		return false
+	}
+	for key, value := range newLabels {
+		if oldValue, ok := oldLabels[key]; !ok || oldValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// expressionsWidened returns whether the new label selector requirements
+// select at least the labels that the old requirements select, i.e. every new
+// requirement is also an old requirement.
+func expressionsWidened(oldExprs, newExprs []metav1.LabelSelectorRequirement) bool {
+	for _, newExpr := range newExprs {
+		var found bool
+		for _, oldExpr := range oldExprs {
+			if apiequality.Semantic.DeepEqual(oldExpr, newExpr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRequestLabels validates that the matchLabels and each of the
+// matchExpressions of the request labels selector are valid label selectors.
+func validateRequestLabels(fldPath *field.Path, reqSel *policyapi.CertificateRequestPolicySelectorRequestLabels) field.ErrorList {
+	var el field.ErrorList
+	if _, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: reqSel.MatchLabels}); err != nil {
+		el = append(el, field.Invalid(fldPath.Child("matchLabels"), reqSel.MatchLabels, err.Error()))
+	}
+	for i, expr := range reqSel.MatchExpressions {
+		if _, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{expr}}); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("matchExpressions").Index(i), expr, err.Error()))
+		}
+	}
+	return el
+}
+
 // InjectDecoder is used by the controller-runtime manager to inject an object
 // decoder to convert into know policy.cert-manager.io types.
 func (v *validator) InjectDecoder(d *admission.Decoder) error {
@@ -156,14 +1081,134 @@ func (v *validator) InjectDecoder(d *admission.Decoder) error {
 }
 
 // check is used by the shared readiness manager to expose whether the server
-// is ready.
-func (v *validator) check(_ *http.Request) error {
+// is ready. The server is not ready if any registered webhook which
+// implements approver.ReadyChecker reports that it is not ready.
+func (v *validator) check(req *http.Request) error {
 	v.lock.RLock()
-	defer v.lock.RUnlock()
+	ready := v.decoder != nil
+	v.lock.RUnlock()
+
+	if !ready {
+		return errors.New("not ready")
+	}
+
+	return v.checkWebhooks(req.Context())
+}
+
+// checkWebhooks concurrently runs the ready check of all registered webhooks
+// which implement approver.ReadyChecker. Each check must complete within the
+// ready timeout, so that a single hung webhook does not block the probe.
+// Returns an error naming each webhook which is not ready.
+func (v *validator) checkWebhooks(ctx context.Context) error {
+	timeout := v.readyTimeout
+	if timeout == 0 {
+		timeout = defaultReadyTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	var checkers int
+	results := make(chan result, len(v.webhooks))
+	for _, webhook := range v.webhooks {
+		checker, ok := webhook.(approver.ReadyChecker)
+		if !ok {
+			continue
+		}
+
+		checkers++
+		go func(name string, checker approver.ReadyChecker) {
+			results <- result{name: name, err: checker.CheckReady(ctx)}
+		}(webhookName(webhook), checker)
+	}
+
+	var notReady []string
+	for pending := checkers; pending > 0; pending-- {
+		select {
+		case res := <-results:
+			if res.err != nil {
+				notReady = append(notReady, fmt.Sprintf("%s: %s", res.name, res.err))
+			}
+		case <-ctx.Done():
+			notReady = append(notReady, fmt.Sprintf("%d plugin(s) did not report ready within %s", pending, timeout))
+			pending = 0
+		}
+	}
+
+	if len(notReady) == 0 {
+		return nil
+	}
+
+	sort.Strings(notReady)
+	return fmt.Errorf("plugins not ready: [%s]", strings.Join(notReady, ", "))
+}
+
+// validatePluginValues returns errors if the values of any of the given
+// plugins don't satisfy the schema declared by the plugin's webhook. Plugins
+// whose webhook doesn't declare a schema are not validated.
+func validatePluginValues(fldPath *field.Path, plugins map[string]policyapi.CertificateRequestPolicyPluginData, webhooks []approver.Webhook) field.ErrorList {
+	var el field.ErrorList
+	for _, webhook := range webhooks {
+		provider, ok := webhook.(approver.ValuesSchemaProvider)
+		if !ok {
+			continue
+		}
+		name := webhookName(webhook)
+		plugin, ok := plugins[name]
+		if !ok {
+			continue
+		}
 
-	if v.decoder != nil {
+		fldPath := fldPath.Key(name).Child("values")
+		schema := provider.ValuesSchema()
+		for _, key := range sets.List(sets.KeySet(schema)) {
+			value, ok := plugin.Values[key]
+			if !ok {
+				if schema[key].Required {
+					el = append(el, field.Required(fldPath.Key(key), fmt.Sprintf("value is required by plugin %q", name)))
+				}
+				continue
+			}
+			if err := validatePluginValue(schema[key].Type, value); err != nil {
+				el = append(el, field.Invalid(fldPath.Key(key), value, err.Error()))
+			}
+		}
+	}
+	return el
+}
+
+// validatePluginValue returns an error if the given value can't be parsed as
+// the given type.
+func validatePluginValue(valueType approver.ValueType, value string) error {
+	var err error
+	switch valueType {
+	case "", approver.ValueTypeString:
 		return nil
+	case approver.ValueTypeInteger:
+		_, err = strconv.Atoi(value)
+	case approver.ValueTypeBoolean:
+		_, err = strconv.ParseBool(value)
+	case approver.ValueTypeDuration:
+		_, err = time.ParseDuration(value)
+	default:
+		return fmt.Errorf("plugin declares unknown value type %q", valueType)
+	}
+	if err != nil {
+		return fmt.Errorf("must be a value of type %s", valueType)
 	}
+	return nil
+}
 
-	return errors.New("not ready")
+// webhookName returns the name of the given webhook, or its type if the
+// webhook doesn't expose a name.
+func webhookName(webhook approver.Webhook) string {
+	if named, ok := webhook.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", webhook)
 }
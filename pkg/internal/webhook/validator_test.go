@@ -19,29 +19,51 @@ package webhook
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
 	"github.com/stretchr/testify/assert"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2/klogr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
 	"github.com/cert-manager/approver-policy/pkg/approver/fake"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 )
 
 func Test_validatorHandle(t *testing.T) {
+	oversizedPolicy := []byte(`{"apiVersion": "policy.cert-manager.io/v1alpha1", "kind": "CertificateRequestPolicy", "metadata": {"name": "testing", "annotations": {"padding": "` +
+		strings.Repeat("a", 2048) + `"}}}`)
+
 	tests := map[string]struct {
 		req               admission.Request
 		webhook           approver.Webhook
 		expResp           admission.Response
 		registeredPlugins []string
+		// allowedIssuerKinds are the issuer kinds policies may select on.
+		allowedIssuerKinds []string
+		// policyNamePattern is the pattern created policy names must match.
+		policyNamePattern string
+		// maxRequestBytes is the maximum size of admitted policies.
+		maxRequestBytes int
+		existingObjects []client.Object
 	}{
 		"a request with no kind sent should return an Error response": {
 			req: admission.Request{
@@ -133,6 +155,27 @@ func Test_validatorHandle(t *testing.T) {
 				},
 			},
 		},
+		"a CertificateRequestPolicy larger than the maximum request size should return a Denied response before being decoded": {
+			maxRequestBytes: 1024,
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: oversizedPolicy},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Reason: metav1.StatusReason(fmt.Sprintf("CertificateRequestPolicy is %d bytes, which is larger than the maximum of 1024 bytes", len(oversizedPolicy))), Code: 403},
+				},
+			},
+		},
 		"a CertificateRequestPolicy which fails validation should return a Denied response": {
 			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
 				return approver.WebhookValidationResponse{
@@ -200,6 +243,51 @@ func Test_validatorHandle(t *testing.T) {
 		}
 	}
 }
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy of the hub version should be converted and validated": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(_ context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				if issRef := policy.Spec.Selector.IssuerRef; issRef == nil || issRef.Name == nil || *issRef.Name != "my-issuer" {
+					return approver.WebhookValidationResponse{
+						Allowed: false, Errors: field.ErrorList{field.Required(field.NewPath("spec.selector.issuerRef.name"), "expected to be converted")},
+					}, nil
+				}
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1beta1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1beta1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "issuerRef": {
+		    "name": "my-issuer"
+		  }
+		}
+	}
+}
 `),
 					},
 				},
@@ -281,7 +369,7 @@ func Test_validatorHandle(t *testing.T) {
 			expResp: admission.Response{
 				AdmissionResponse: admissionv1.AdmissionResponse{
 					Allowed: false,
-					Result:  &metav1.Status{Reason: "spec.selector: Required value: one of issuerRef or namespace must be defined, hint: `{}` on either matches everything", Code: 403},
+					Result:  &metav1.Status{Reason: "spec.selector: Required value: one of issuerRef, issuerRefs, namespace or serviceAccount must be defined, hint: `{}` on any matches everything", Code: 403},
 				},
 			},
 		},
@@ -322,7 +410,7 @@ func Test_validatorHandle(t *testing.T) {
 			expResp: admission.Response{
 				AdmissionResponse: admissionv1.AdmissionResponse{
 					Allowed: false,
-					Result:  &metav1.Status{Reason: "[spec.plugins: Unsupported value: \"plugin-1\", spec.plugins: Unsupported value: \"plugin-2\", spec.plugins: Unsupported value: \"plugin-3\", spec.selector: Required value: one of issuerRef or namespace must be defined, hint: `{}` on either matches everything]", Code: 403},
+					Result:  &metav1.Status{Reason: "[spec.plugins: Unsupported value: \"plugin-1\", spec.plugins: Unsupported value: \"plugin-2\", spec.plugins: Unsupported value: \"plugin-3\", spec.selector: Required value: one of issuerRef, issuerRefs, namespace or serviceAccount must be defined, hint: `{}` on any matches everything]", Code: 403},
 				},
 			},
 		},
@@ -365,7 +453,7 @@ func Test_validatorHandle(t *testing.T) {
 			expResp: admission.Response{
 				AdmissionResponse: admissionv1.AdmissionResponse{
 					Allowed: false,
-					Result:  &metav1.Status{Reason: "[spec.plugins: Unsupported value: \"plugin-3\": supported values: \"plugin-1\", \"plugin-2\", spec.plugins: Unsupported value: \"plugin-4\": supported values: \"plugin-1\", \"plugin-2\", spec.selector: Required value: one of issuerRef or namespace must be defined, hint: `{}` on either matches everything]", Code: 403},
+					Result:  &metav1.Status{Reason: "[spec.plugins: Unsupported value: \"plugin-3\": supported values: \"plugin-1\", \"plugin-2\", spec.plugins: Unsupported value: \"plugin-4\": supported values: \"plugin-1\", \"plugin-2\", spec.selector: Required value: one of issuerRef, issuerRefs, namespace or serviceAccount must be defined, hint: `{}` on any matches everything]", Code: 403},
 				},
 			},
 		},
@@ -414,6 +502,105 @@ func Test_validatorHandle(t *testing.T) {
 				},
 			},
 		},
+		"a CertificateRequestPolicy with a plugin selector on the issuerRef, should return Allowed": {
+			registeredPlugins: []string{"plugin-1", "plugin-2"},
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+	  "plugins": {
+			"plugin-1": {
+				"selector": {
+					"issuerRef": {
+						"name": "hsm-*",
+						"kind": "ClusterIssuer"
+					}
+				}
+			}
+		},
+		"selector": {
+		  "issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with a plugin selector on issuer labels, should return error of forbidden": {
+			registeredPlugins: []string{"plugin-1", "plugin-2"},
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+	  "plugins": {
+			"plugin-1": {
+				"selector": {
+					"issuerRef": {
+						"matchLabels": {
+							"hsm": "true"
+						}
+					}
+				}
+			}
+		},
+		"selector": {
+		  "issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Reason: "spec.plugins[plugin-1].selector.issuerRef.matchLabels: Forbidden: matchLabels is not supported on plugin selectors", Code: 403},
+				},
+			},
+		},
 		"a CertificateRequestPolicy where the namespace selector matchLabels definition is invalid, should return error of invalid": {
 			registeredPlugins: []string{"plugin-1", "plugin-2"},
 			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
@@ -464,21 +651,2139 @@ func Test_validatorHandle(t *testing.T) {
 				},
 			},
 		},
+		"a CertificateRequestPolicy where the certificateLabels selector matchLabels definition is invalid, should return error of invalid": {
+			registeredPlugins: []string{"plugin-1", "plugin-2"},
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+	  "plugins": {
+			"plugin-1": {},
+			"plugin-2": {}
+		},
+		"selector": {
+		  "issuerRef": {},
+		  "certificateLabels": {
+				"matchLabels": {
+				  "%%%": "@@@"
+				}
+			}
+		}
 	}
-
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			fakeclient := fakeclient.NewClientBuilder().
-				WithScheme(policyapi.GlobalScheme).
-				Build()
-
-			decoder, err := admission.NewDecoder(policyapi.GlobalScheme)
-			if err != nil {
-				t.Fatal(err)
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: `spec.selector.certificateLabels.matchLabels: Invalid value: map[string]string{"%%%":"@@@"}: [key: Invalid value: "%%%": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]'), values[0][%%%]: Invalid value: "@@@": a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')]`,
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with only a serviceAccount selector should return Allowed": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "serviceAccount": {
+				"matchNames": ["app-1", "sandbox:*"]
 			}
-
-			v := &validator{lister: fakeclient, decoder: decoder, log: klogr.New(), webhooks: []approver.Webhook{test.webhook}, registeredPlugins: test.registeredPlugins}
-			assert.Equal(t, test.expResp, v.Handle(context.TODO(), test.req), "expected the same admission response")
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy where the serviceAccount selector matchNames definition is invalid, should return error of invalid": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "serviceAccount": {
+				"matchNames": ["app-1", "sandbox:app:1"]
+			}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.serviceAccount.matchNames[1]: Invalid value: \"sandbox:app:1\": must be in the form `<name>` or `<namespace>:<name>`",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with a priority should return Allowed": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"priority": 10,
+		"selector": {
+		  "issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with a negative priority should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"priority": -1,
+		"selector": {
+		  "issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Reason: "spec.priority: Invalid value: -1: must be greater than or equal to 0", Code: 403},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with Warn enforcement should return Allowed with a warning": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"enforcement": "Warn",
+		"selector": {
+		  "issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed:  true,
+					Result:   &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+					Warnings: []string{"spec.enforcement: requests which this policy would deny are approved with a warning"},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with an unknown enforcement should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"enforcement": "Audit",
+		"selector": {
+		  "issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Reason: `spec.enforcement: Unsupported value: "Audit": supported values: "Deny", "Warn"`, Code: 403},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with an issuerRef matchLabels and wildcard name should return Allowed": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "issuerRef": {
+				"name": "issuer-*",
+				"matchLabels": {
+				  "team": "foo"
+				}
+			}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with an issuerRef matchLabels and invalid name should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "issuerRef": {
+				"name": "Issuer_*",
+				"matchLabels": {
+				  "team": "foo"
+				}
+			}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: `spec.selector.issuerRef.name: Invalid value: "Issuer_*": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+						Code:   403,
+					},
+				},
+			},
+		}, "a CertificateRequestPolicy with an invalid requestAnnotations key should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "issuerRef": {},
+			"requestAnnotations": {
+				"example.com/ticket-id": "*",
+				"-ticket-id": "*"
+			}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.requestAnnotations[-ticket-id]: Invalid value: \"-ticket-id\": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')",
+						Code:   403,
+					},
+				},
+			},
+		}, "if webhook returns warnings, they should be returned in the response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true, Warnings: []string{"spec.constraints.privateKey.minSize: minSize is ignored"}}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed:  true,
+					Result:   &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+					Warnings: []string{"spec.constraints.privateKey.minSize: minSize is ignored"},
+				},
+			},
+		}, "a CertificateRequestPolicy with a selector minDuration larger than maxDuration should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "issuerRef": {},
+			"minDuration": "24h",
+			"maxDuration": "1h"
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.maxDuration: Invalid value: \"1h0m0s\": maxDuration must be the same value as minDuration or larger",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with a valid selector duration window should return Allowed": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+		  "issuerRef": {},
+			"minDuration": "1h",
+			"maxDuration": "24h",
+			"selectOnMissingDuration": true
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy selecting on an issuer kind not in the allowed issuer kinds should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			allowedIssuerKinds: []string{"Issuer", "ClusterIssuer"},
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {"kind": "VaultIssuer", "group": "vault.example.com"}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.issuerRef.kind: Unsupported value: \"VaultIssuer\": supported values: \"Issuer\", \"ClusterIssuer\"",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy not selecting on an issuer kind when issuer kinds are restricted should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			allowedIssuerKinds: []string{"Issuer", "ClusterIssuer"},
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {"name": "my-ca"}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.issuerRef.kind: Unsupported value: \"\": supported values: \"Issuer\", \"ClusterIssuer\"",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy selecting on an allowed issuer kind should return Allowed": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			allowedIssuerKinds: []string{"Issuer", "ClusterIssuer"},
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {"kind": "ClusterIssuer", "group": "cert-manager.io"}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy defining both issuerRef and issuerRefs should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {"name": "my-ca"},
+			"issuerRefs": [{"name": "my-other-ca"}]
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.issuerRefs: Forbidden: issuerRefs may not be defined together with issuerRef",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy with an issuerRefs entry not selecting on an allowed issuer kind should return 403": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			allowedIssuerKinds: []string{"Issuer", "ClusterIssuer"},
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+ "apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"issuerRefs": [{"name": "my-ca", "kind": "Issuer"}, {"name": "my-other-ca", "kind": "*"}]
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.issuerRefs[1].kind: Unsupported value: \"*\": supported values: \"Issuer\", \"ClusterIssuer\"",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy created with a narrow selector should return an Allowed response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo"]}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy created with a name matching the policy name pattern should return an Allowed response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			policyNamePattern: "team-.+",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "team-payments"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy created with a name not matching the policy name pattern should return a Denied response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			policyNamePattern: "team-.+",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "payments"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "metadata.name: Invalid value: \"payments\": must match the policy name pattern \"^(?:team-.+)$\"",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"an existing CertificateRequestPolicy with a name not matching the policy name pattern should be allowed to be updated": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			policyNamePattern: "team-.+",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Update,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "payments"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {}
+		}
+	}
+}
+`),
+					},
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "payments"
+	},
+	"spec": {
+		"selector": {
+			"issuerRef": {}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy updated to narrow its selector should return a Denied response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Update,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo"]}
+		}
+	}
+}
+`),
+					},
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo", "bar"]}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result: &metav1.Status{
+						Reason: "spec.selector.namespace.matchNames: Forbidden: selector may not be narrowed on update, as requests it selects may no longer be approved; set the \"policy.cert-manager.io/allow-selector-change\" annotation to allow",
+						Code:   403,
+					},
+				},
+			},
+		},
+		"a CertificateRequestPolicy updated to narrow its selector with the allow-selector-change annotation should return an Allowed response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Update,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing",
+		"annotations": {"policy.cert-manager.io/allow-selector-change": ""}
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo"]}
+		}
+	}
+}
+`),
+					},
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo", "bar"]}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy updated to widen its selector should return an Allowed response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Update,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo", "bar", "baz"]}
+		}
+	}
+}
+`),
+					},
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo", "bar"]}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy updated with an old object that fails to decode should return an Error response": {
+			webhook: fake.NewFakeWebhook().WithValidate(func(context.Context, *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+				return approver.WebhookValidationResponse{Allowed: true}, nil
+			}),
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Update,
+					Object: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo"]}
+		}
+	}
+}
+`),
+					},
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`{"spec": "foo"}`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Message: "json: cannot unmarshal string into Go struct field CertificateRequestPolicy.spec of type v1alpha1.CertificateRequestPolicySpec", Code: 400},
+				},
+			},
+		},
+		"a CertificateRequestPolicy deleted which selects pending CertificateRequests should return a Denied response": {
+			existingObjects: []client.Object{
+				gen.CertificateRequest("pending-1", gen.SetCertificateRequestNamespace("foo")),
+				gen.CertificateRequest("pending-2", gen.SetCertificateRequestNamespace("foo")),
+				gen.CertificateRequest("other-namespace", gen.SetCertificateRequestNamespace("bar")),
+			},
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Delete,
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo"]}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Reason: `metadata.name: Forbidden: policy may not be deleted, as it selects pending CertificateRequests which may no longer be approved: foo/pending-1, foo/pending-2; set the "policy.cert-manager.io/force-delete" annotation to allow`, Code: 403},
+				},
+			},
+		},
+		"a CertificateRequestPolicy deleted which selects pending CertificateRequests with the force delete annotation should return an Allowed response": {
+			existingObjects: []client.Object{
+				gen.CertificateRequest("pending-1", gen.SetCertificateRequestNamespace("foo")),
+				gen.CertificateRequest("pending-2", gen.SetCertificateRequestNamespace("foo")),
+				gen.CertificateRequest("other-namespace", gen.SetCertificateRequestNamespace("bar")),
+			},
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Delete,
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing",
+		"annotations": {"policy.cert-manager.io/force-delete": ""}
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo"]}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy deletion validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy deleted which only selects approved or denied CertificateRequests should return an Allowed response": {
+			existingObjects: []client.Object{
+				gen.CertificateRequest("approved", gen.SetCertificateRequestNamespace("foo"), gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+					Type: cmapi.CertificateRequestConditionApproved, Status: cmmeta.ConditionTrue,
+				})),
+				gen.CertificateRequest("denied", gen.SetCertificateRequestNamespace("foo"), gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+					Type: cmapi.CertificateRequestConditionDenied, Status: cmmeta.ConditionTrue,
+				})),
+				gen.CertificateRequest("other-namespace", gen.SetCertificateRequestNamespace("bar")),
+			},
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Delete,
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`
+{
+	"apiVersion": "policy.cert-manager.io/v1alpha1",
+	"kind": "CertificateRequestPolicy",
+	"metadata": {
+		"name": "testing"
+	},
+	"spec": {
+		"selector": {
+			"namespace": {"matchNames": ["foo"]}
+		}
+	}
+}
+`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+					Result:  &metav1.Status{Reason: "CertificateRequestPolicy deletion validated", Code: 200},
+				},
+			},
+		},
+		"a CertificateRequestPolicy deleted with an old object that fails to decode should return an Error response": {
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UID: types.UID("abc"),
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   "policy.cert-manager.io",
+						Version: "v1alpha1",
+						Kind:    "CertificateRequestPolicy",
+					},
+					Operation: admissionv1.Delete,
+					OldObject: runtime.RawExtension{
+						Raw: []byte(`{"spec": "foo"}`),
+					},
+				},
+			},
+			expResp: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Message: "json: cannot unmarshal string into Go struct field CertificateRequestPolicy.spec of type v1alpha1.CertificateRequestPolicySpec", Code: 400},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithObjects(test.existingObjects...).
+				Build()
+
+			decoder, err := admission.NewDecoder(policyapi.GlobalScheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := &validator{lister: fakeclient, decoder: decoder, log: klogr.New(), webhooks: []approver.Webhook{test.webhook}, registeredPlugins: test.registeredPlugins, allowedIssuerKinds: test.allowedIssuerKinds, maxRequestBytes: test.maxRequestBytes}
+			if len(test.policyNamePattern) > 0 {
+				v.policyNamePattern, err = util.CompileRegex(test.policyNamePattern)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			assert.Equal(t, test.expResp, v.Handle(context.TODO(), test.req), "expected the same admission response")
+		})
+	}
+}
+
+func Test_validatorCheck(t *testing.T) {
+	decoder, err := admission.NewDecoder(policyapi.GlobalScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hung := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := map[string]struct {
+		decoder  *admission.Decoder
+		webhooks []approver.Webhook
+		expErr   string
+	}{
+		"if decoder is not set, expect not ready": {
+			decoder: nil,
+			expErr:  "not ready",
+		},
+		"if no webhooks are registered, expect ready": {
+			decoder: decoder,
+			expErr:  "",
+		},
+		"if all webhooks are ready, expect ready": {
+			decoder: decoder,
+			webhooks: []approver.Webhook{
+				namedWebhook{"plugin-a", fake.NewFakeWebhook()},
+				namedWebhook{"plugin-b", fake.NewFakeWebhook().WithCheckReady(func(context.Context) error { return nil })},
+			},
+			expErr: "",
+		},
+		"if a webhook is not ready, expect not ready naming the plugin": {
+			decoder: decoder,
+			webhooks: []approver.Webhook{
+				namedWebhook{"plugin-a", fake.NewFakeWebhook()},
+				namedWebhook{"plugin-b", fake.NewFakeWebhook().WithCheckReady(func(context.Context) error { return errors.New("connection refused") })},
+			},
+			expErr: "plugins not ready: [plugin-b: connection refused]",
+		},
+		"if a webhook hangs, expect not ready once the timeout is reached": {
+			decoder: decoder,
+			webhooks: []approver.Webhook{
+				namedWebhook{"plugin-a", fake.NewFakeWebhook().WithCheckReady(func(context.Context) error { return errors.New("unhealthy") })},
+				namedWebhook{"plugin-b", fake.NewFakeWebhook().WithCheckReady(hung)},
+			},
+			expErr: "plugins not ready: [1 plugin(s) did not report ready within 50ms, plugin-a: unhealthy]",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := &validator{log: klogr.New(), decoder: test.decoder, webhooks: test.webhooks, readyTimeout: time.Millisecond * 50}
+			err := v.check(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if len(test.expErr) == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expErr)
+			}
+		})
+	}
+}
+
+// namedWebhook wraps a fake webhook with a name, as registered approvers do.
+type namedWebhook struct {
+	name string
+	*fake.FakeWebhook
+}
+
+func (n namedWebhook) Name() string {
+	return n.name
+}
+
+// schemaWebhook is a named fake webhook which declares a values schema, as
+// plugin approvers may do.
+type schemaWebhook struct {
+	namedWebhook
+	schema approver.ValuesSchema
+}
+
+func (s schemaWebhook) ValuesSchema() approver.ValuesSchema {
+	return s.schema
+}
+
+func Test_validatePluginValues(t *testing.T) {
+	fldPath := field.NewPath("spec", "plugins")
+
+	webhooks := []approver.Webhook{
+		schemaWebhook{
+			namedWebhook: namedWebhook{"my-plugin", fake.NewFakeWebhook()},
+			schema: approver.ValuesSchema{
+				"endpoint": {Required: true},
+				"timeout":  {Type: approver.ValueTypeDuration},
+			},
+		},
+		namedWebhook{"other-plugin", fake.NewFakeWebhook()},
+	}
+
+	tests := map[string]struct {
+		plugins map[string]policyapi.CertificateRequestPolicyPluginData
+		expEl   field.ErrorList
+	}{
+		"if no plugins are used, expect no errors": {
+			plugins: nil,
+			expEl:   nil,
+		},
+		"if values satisfy the schema, expect no errors": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"my-plugin": {Values: map[string]string{"endpoint": "https://example.com", "timeout": "5s", "extra": "foo"}},
+			},
+			expEl: nil,
+		},
+		"if an optional value is omitted, expect no errors": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"my-plugin": {Values: map[string]string{"endpoint": "https://example.com"}},
+			},
+			expEl: nil,
+		},
+		"if a required value is missing, expect an error": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"my-plugin": {Values: map[string]string{"timeout": "5s"}},
+			},
+			expEl: field.ErrorList{
+				field.Required(fldPath.Key("my-plugin").Child("values").Key("endpoint"), `value is required by plugin "my-plugin"`),
+			},
+		},
+		"if a value has the wrong type and a required value is missing, expect errors": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"my-plugin": {Values: map[string]string{"timeout": "5"}},
+			},
+			expEl: field.ErrorList{
+				field.Required(fldPath.Key("my-plugin").Child("values").Key("endpoint"), `value is required by plugin "my-plugin"`),
+				field.Invalid(fldPath.Key("my-plugin").Child("values").Key("timeout"), "5", "must be a value of type Duration"),
+			},
+		},
+		"if a value can't be parsed as its type, expect an error": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"my-plugin": {Values: map[string]string{"endpoint": "https://example.com", "timeout": "five seconds"}},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Key("my-plugin").Child("values").Key("timeout"), "five seconds", "must be a value of type Duration"),
+			},
+		},
+		"if a plugin doesn't declare a schema, expect no errors": {
+			plugins: map[string]policyapi.CertificateRequestPolicyPluginData{
+				"other-plugin": {Values: map[string]string{"timeout": "five seconds"}},
+			},
+			expEl: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expEl, validatePluginValues(fldPath, test.plugins, webhooks))
+		})
+	}
+}
+
+func Test_validatePluginValue(t *testing.T) {
+	tests := map[string]struct {
+		valueType approver.ValueType
+		value     string
+		expErr    bool
+	}{
+		"empty type accepts any value":     {valueType: "", value: "anything"},
+		"String accepts any value":         {valueType: approver.ValueTypeString, value: "anything"},
+		"Integer accepts an integer":       {valueType: approver.ValueTypeInteger, value: "-10"},
+		"Integer rejects a float":          {valueType: approver.ValueTypeInteger, value: "1.5", expErr: true},
+		"Boolean accepts true":             {valueType: approver.ValueTypeBoolean, value: "true"},
+		"Boolean rejects yes":              {valueType: approver.ValueTypeBoolean, value: "yes", expErr: true},
+		"Duration accepts a duration":      {valueType: approver.ValueTypeDuration, value: "1h30m"},
+		"Duration rejects a number":        {valueType: approver.ValueTypeDuration, value: "90", expErr: true},
+		"unknown type rejects every value": {valueType: "Float", value: "1.5", expErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validatePluginValue(test.valueType, test.value)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func Test_validateSatisfiable(t *testing.T) {
+	fldPath := field.NewPath("spec")
+
+	tests := map[string]struct {
+		spec  policyapi.CertificateRequestPolicySpec
+		expEl field.ErrorList
+	}{
+		"if no allowed or constraints are defined, expect no errors": {
+			spec:  policyapi.CertificateRequestPolicySpec{},
+			expEl: nil,
+		},
+		"if a required field has values, expect no errors": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*.example.com"}, Required: pointer.Bool(true)},
+				},
+			},
+			expEl: nil,
+		},
+		"if a required field has empty values, expect an error": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{}, Required: pointer.Bool(true)},
+					Subject: &policyapi.CertificateRequestPolicyAllowedX509Subject{
+						Organizations: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{}, Required: pointer.Bool(true)},
+						Countries:     &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{}, Required: pointer.Bool(false)},
+					},
+				},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("allowed", "dnsNames", "values"), []string{}, "values must not be empty if required is true, otherwise all requests are denied"),
+				field.Invalid(fldPath.Child("allowed", "subject", "organizations", "values"), []string{}, "values must not be empty if required is true, otherwise all requests are denied"),
+			},
+		},
+		"if a required field has no values, expect no errors since it is validated by the allowed approver": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames: &policyapi.CertificateRequestPolicyAllowedStringSlice{Required: pointer.Bool(true)},
+				},
+			},
+			expEl: nil,
+		},
+		"if the isCA constraint is true and allowed isCA is not defined, expect an error": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{IsCA: pointer.Bool(true)},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("constraints", "isCA"), true, "allowed.isCA must be true if the isCA constraint is true, otherwise all requests are denied"),
+			},
+		},
+		"if the isCA constraint is true and allowed isCA is false, expect an error": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed:     &policyapi.CertificateRequestPolicyAllowed{IsCA: pointer.Bool(false)},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{IsCA: pointer.Bool(true)},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("constraints", "isCA"), true, "allowed.isCA must be true if the isCA constraint is true, otherwise all requests are denied"),
+			},
+		},
+		"if the isCA constraint is true and allowed isCA is true, expect no errors": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed:     &policyapi.CertificateRequestPolicyAllowed{IsCA: pointer.Bool(true)},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{IsCA: pointer.Bool(true)},
+			},
+			expEl: nil,
+		},
+		"if maxSANCount is smaller than the number of required SAN types, expect an error": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Required: pointer.Bool(true)},
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Required: pointer.Bool(true)},
+				},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(1)},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("constraints", "maxSANCount"), 1, "maxSANCount must be at least 2 to allow the required SAN types, otherwise all requests are denied"),
+			},
+		},
+		"if maxSANCount is per type and at least 1, expect no errors": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Required: pointer.Bool(true)},
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Required: pointer.Bool(true)},
+				},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(1), MaxSANCountPerType: pointer.Bool(true)},
+			},
+			expEl: nil,
+		},
+		"if maxSANCount is 0 per type and a SAN type is required, expect an error": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					URIs: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Required: pointer.Bool(true)},
+				},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(0), MaxSANCountPerType: pointer.Bool(true)},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("constraints", "maxSANCount"), 0, "maxSANCount must be at least 1 to allow the required SAN types, otherwise all requests are denied"),
+			},
+		},
+		"if a required SAN type is not an allowed SAN type, expect an error": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Allowed: &policyapi.CertificateRequestPolicyAllowed{
+					DNSNames:    &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Required: pointer.Bool(true)},
+					IPAddresses: &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &[]string{"*"}, Required: pointer.Bool(true)},
+				},
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{AllowedSANTypes: []string{"DNS"}},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("constraints", "allowedSANTypes"), []string{"DNS"}, `allowedSANTypes must contain "IP" if spec.allowed.ipAddresses is required, otherwise all requests are denied`),
+			},
+		},
+		"if maxSANCount is 0 and no SAN types are required, expect no errors": {
+			spec: policyapi.CertificateRequestPolicySpec{
+				Constraints: &policyapi.CertificateRequestPolicyConstraints{MaxSANCount: pointer.Int(0)},
+			},
+			expEl: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expEl, validateSatisfiable(fldPath, test.spec))
+		})
+	}
+}
+
+func Test_validateBaseRef(t *testing.T) {
+	policyWithBase := func(name, base string) *policyapi.CertificateRequestPolicy {
+		policy := &policyapi.CertificateRequestPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(base) > 0 {
+			policy.Spec.BaseRef = &policyapi.CertificateRequestPolicyBaseRef{Name: base}
+		}
+		return policy
+	}
+
+	tests := map[string]struct {
+		policy          *policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+		expErrs         field.ErrorList
+	}{
+		"if policy has no baseRef, expect no errors": {
+			policy:  policyWithBase("child", ""),
+			expErrs: nil,
+		},
+		"if baseRef has no name, expect required error": {
+			policy: &policyapi.CertificateRequestPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "child"},
+				Spec:       policyapi.CertificateRequestPolicySpec{BaseRef: &policyapi.CertificateRequestPolicyBaseRef{}},
+			},
+			expErrs: field.ErrorList{
+				field.Required(field.NewPath("spec", "baseRef", "name"), "the name of the base policy must be defined"),
+			},
+		},
+		"if base policies exist, expect no errors": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{policyWithBase("team", "org"), policyWithBase("org", "")},
+			expErrs:         nil,
+		},
+		"if base policy doesn't exist, expect not found error": {
+			policy:  policyWithBase("child", "team"),
+			expErrs: field.ErrorList{field.NotFound(field.NewPath("spec", "baseRef", "name"), "team")},
+		},
+		"if base policy references the policy, expect cycle error": {
+			policy:          policyWithBase("child", "team"),
+			existingObjects: []runtime.Object{policyWithBase("team", "child"), policyWithBase("child", "")},
+			expErrs: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "baseRef", "name"), "team", `baseRef forms a cycle: "child"`),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			v := &validator{lister: fakeclient, log: klogr.New()}
+			el, err := v.validateBaseRef(context.TODO(), field.NewPath("spec", "baseRef"), test.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expErrs, el)
+		})
+	}
+}
+
+func Test_validateBootstrapOnly(t *testing.T) {
+	bootstrapPolicy := func(issRefSel *policyapi.CertificateRequestPolicySelectorIssuerRef, namespaces ...string) *policyapi.CertificateRequestPolicy {
+		policy := &policyapi.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "bootstrap"},
+			Spec: policyapi.CertificateRequestPolicySpec{
+				BootstrapOnly: true,
+				Selector:      policyapi.CertificateRequestPolicySelector{IssuerRef: issRefSel},
+			},
+		}
+		if len(namespaces) > 0 {
+			policy.Spec.Selector.Namespace = &policyapi.CertificateRequestPolicySelectorNamespace{MatchNames: namespaces}
+		}
+		return policy
+	}
+	issuerRef := func(name, kind, group string) *policyapi.CertificateRequestPolicySelectorIssuerRef {
+		return &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String(name), Kind: pointer.String(kind), Group: pointer.String(group)}
+	}
+
+	selfSignedClusterIssuer := &cmapi.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "selfsigned-issuer"},
+		Spec:       cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{SelfSigned: new(cmapi.SelfSignedIssuer)}},
+	}
+	caClusterIssuer := &cmapi.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca-issuer"},
+		Spec:       cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{CA: &cmapi.CAIssuer{SecretName: "ca"}}},
+	}
+	caIssuer := &cmapi.Issuer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "ca-issuer"},
+		Spec:       cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{CA: &cmapi.CAIssuer{SecretName: "ca"}}},
+	}
+
+	tests := map[string]struct {
+		policy          *policyapi.CertificateRequestPolicy
+		existingObjects []runtime.Object
+		expErrs         field.ErrorList
+	}{
+		"if policy is not bootstrapOnly, expect no errors": {
+			policy: &policyapi.CertificateRequestPolicy{
+				Spec: policyapi.CertificateRequestPolicySpec{
+					Selector: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+				},
+			},
+			existingObjects: []runtime.Object{caClusterIssuer},
+			expErrs:         nil,
+		},
+		"if bootstrapOnly policy selects a SelfSigned ClusterIssuer, expect no errors": {
+			policy:          bootstrapPolicy(issuerRef("selfsigned-issuer", "ClusterIssuer", "cert-manager.io")),
+			existingObjects: []runtime.Object{selfSignedClusterIssuer, caClusterIssuer},
+			expErrs:         nil,
+		},
+		"if bootstrapOnly policy selects a ClusterIssuer which doesn't exist, expect no errors": {
+			policy:  bootstrapPolicy(issuerRef("selfsigned-issuer", "ClusterIssuer", "cert-manager.io")),
+			expErrs: nil,
+		},
+		"if bootstrapOnly policy selects a CA ClusterIssuer, expect invalid error": {
+			policy:          bootstrapPolicy(issuerRef("ca-issuer", "ClusterIssuer", "cert-manager.io")),
+			existingObjects: []runtime.Object{selfSignedClusterIssuer, caClusterIssuer},
+			expErrs: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "selector", "issuerRef", "name"), "ca-issuer", `bootstrapOnly policies must select a SelfSigned issuer, but ClusterIssuer "ca-issuer" is not SelfSigned`),
+			},
+		},
+		"if bootstrapOnly policy selects a CA Issuer in one of its namespaces, expect invalid error": {
+			policy:          bootstrapPolicy(issuerRef("ca-issuer", "Issuer", "cert-manager.io"), "team-a", "team-b"),
+			existingObjects: []runtime.Object{caIssuer},
+			expErrs: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "selector", "issuerRef", "name"), "ca-issuer", `bootstrapOnly policies must select a SelfSigned issuer, but Issuer "team-a/ca-issuer" is not SelfSigned`),
+			},
+		},
+		"if bootstrapOnly policy doesn't select an issuer, expect required error": {
+			policy: bootstrapPolicy(nil),
+			expErrs: field.ErrorList{
+				field.Required(field.NewPath("spec", "selector", "issuerRef"), "bootstrapOnly policies must select a SelfSigned issuer"),
+			},
+		},
+		"if bootstrapOnly policy may select any issuer, expect errors on the name, kind, group and namespaces": {
+			policy: bootstrapPolicy(issuerRef("*", "Issuer", "*"), "team-*"),
+			expErrs: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "selector", "issuerRef", "name"), "*", "bootstrapOnly policies must select the name of a SelfSigned issuer exactly"),
+				field.NotSupported(field.NewPath("spec", "selector", "issuerRef", "group"), "*", []string{"cert-manager.io"}),
+				field.Invalid(field.NewPath("spec", "selector", "namespace", "matchNames").Index(0), "team-*", "bootstrapOnly policies selecting an Issuer must select its namespaces exactly"),
+			},
+		},
+		"if bootstrapOnly policy selects an external issuer kind of any group, expect errors": {
+			policy: bootstrapPolicy(&policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("selfsigned-issuer"), Kind: pointer.String("AWSPCAIssuer")}),
+			expErrs: field.ErrorList{
+				field.NotSupported(field.NewPath("spec", "selector", "issuerRef", "kind"), "AWSPCAIssuer", []string{"Issuer", "ClusterIssuer"}),
+				field.NotSupported(field.NewPath("spec", "selector", "issuerRef", "group"), "", []string{"cert-manager.io"}),
+			},
+		},
+		"if bootstrapOnly policy selects an Issuer without selecting its namespaces, expect required error": {
+			policy: bootstrapPolicy(issuerRef("selfsigned-issuer", "Issuer", "cert-manager.io")),
+			expErrs: field.ErrorList{
+				field.Required(field.NewPath("spec", "selector", "namespace", "matchNames"), "bootstrapOnly policies selecting an Issuer must select its namespaces by name"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeclient := fakeclient.NewClientBuilder().
+				WithScheme(policyapi.GlobalScheme).
+				WithRuntimeObjects(test.existingObjects...).
+				Build()
+
+			v := &validator{lister: fakeclient, log: klogr.New()}
+			el, err := v.validateBootstrapOnly(context.TODO(), field.NewPath("spec"), test.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expErrs, el)
+		})
+	}
+}
+
+func Test_validateActiveSchedule(t *testing.T) {
+	fldPath := field.NewPath("spec", "activeSchedule")
+
+	_, tzErr := time.LoadLocation("Not/AZone")
+	_, startErr := util.ParseTimeOfDay("9am")
+	_, endErr := util.ParseTimeOfDay("24:00")
+
+	tests := map[string]struct {
+		schedule *policyapi.CertificateRequestPolicyActiveSchedule
+		expEl    field.ErrorList
+	}{
+		"if no schedule is defined, expect no errors": {
+			schedule: nil,
+			expEl:    nil,
+		},
+		"if schedule is valid, expect no errors": {
+			schedule: &policyapi.CertificateRequestPolicyActiveSchedule{
+				TimeZone: "Europe/London",
+				Windows: []policyapi.CertificateRequestPolicyActiveWindow{
+					{Days: []string{"Mon", "Fri"}, Start: "18:00", End: "09:00"},
+					{Start: "00:00", End: "00:00"},
+				},
+			},
+			expEl: nil,
+		},
+		"if schedule has no windows, expect an error": {
+			schedule: &policyapi.CertificateRequestPolicyActiveSchedule{},
+			expEl: field.ErrorList{
+				field.Required(fldPath.Child("windows"), "at least one window must be defined"),
+			},
+		},
+		"if schedule is malformed, expect errors": {
+			schedule: &policyapi.CertificateRequestPolicyActiveSchedule{
+				TimeZone: "Not/AZone",
+				Windows: []policyapi.CertificateRequestPolicyActiveWindow{
+					{Days: []string{"Mon", "Monday"}, Start: "9am", End: "24:00"},
+				},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("timeZone"), "Not/AZone", tzErr.Error()),
+				field.NotSupported(fldPath.Child("windows").Index(0).Child("days").Index(1), "Monday", util.Weekdays),
+				field.Invalid(fldPath.Child("windows").Index(0).Child("start"), "9am", startErr.Error()),
+				field.Invalid(fldPath.Child("windows").Index(0).Child("end"), "24:00", endErr.Error()),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expEl, validateActiveSchedule(fldPath, test.schedule))
+		})
+	}
+}
+
+func Test_validateRateLimit(t *testing.T) {
+	fldPath := field.NewPath("spec", "rateLimit")
+
+	tests := map[string]struct {
+		rateLimit *policyapi.CertificateRequestPolicyRateLimit
+		expEl     field.ErrorList
+	}{
+		"if no rate limit is defined, expect no errors": {
+			rateLimit: nil,
+			expEl:     nil,
+		},
+		"if rate limit is valid, expect no errors": {
+			rateLimit: &policyapi.CertificateRequestPolicyRateLimit{Requests: 10, Window: metav1.Duration{Duration: time.Minute}},
+			expEl:     nil,
+		},
+		"if rate limit would never permit an approval, expect errors": {
+			rateLimit: &policyapi.CertificateRequestPolicyRateLimit{Requests: 0, Window: metav1.Duration{Duration: -time.Minute}},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("requests"), 0, "must be greater than or equal to 1"),
+				field.Invalid(fldPath.Child("window"), "-1m0s", "must be greater than 0"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expEl, validateRateLimit(fldPath, test.rateLimit))
+		})
+	}
+}
+
+func Test_validateMetricsLabels(t *testing.T) {
+	fldPath := field.NewPath("spec", "metricsLabels")
+	allowedKeys := []string{"team", "example.com/cost-centre"}
+
+	tests := map[string]struct {
+		metricsLabels map[string]string
+		expEl         field.ErrorList
+	}{
+		"if no metrics labels are defined, expect no errors": {
+			metricsLabels: nil,
+			expEl:         nil,
+		},
+		"if metrics labels use allowed keys with valid values, expect no errors": {
+			metricsLabels: map[string]string{"team": "payments", "example.com/cost-centre": "cc-1"},
+			expEl:         nil,
+		},
+		"if metrics labels use keys which are not allowed, expect errors": {
+			metricsLabels: map[string]string{"team": "payments", "owner": "foo", "env": "prod"},
+			expEl: field.ErrorList{
+				field.NotSupported(fldPath, "env", allowedKeys),
+				field.NotSupported(fldPath, "owner", allowedKeys),
+			},
+		},
+		"if metrics labels have an invalid value, expect an error": {
+			metricsLabels: map[string]string{"team": "payments team"},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Key("team"), "payments team", validation.IsValidLabelValue("payments team")[0]),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expEl, validateMetricsLabels(fldPath, test.metricsLabels, allowedKeys))
+		})
+	}
+}
+
+func Test_validateRequestLabels(t *testing.T) {
+	fldPath := field.NewPath("spec", "selector", "requestLabels")
+
+	tests := map[string]struct {
+		reqSel *policyapi.CertificateRequestPolicySelectorRequestLabels
+		expEl  field.ErrorList
+	}{
+		"if matchLabels and matchExpressions are valid, expect no errors": {
+			reqSel: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+				MatchLabels:      map[string]string{"team": "payments"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "environment", Operator: metav1.LabelSelectorOpIn, Values: []string{"production"}}},
+			},
+			expEl: nil,
+		},
+		"if matchLabels has an invalid key, expect an error": {
+			reqSel: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+				MatchLabels: map[string]string{"team payments": "a"},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("matchLabels"), map[string]string{"team payments": "a"}, `key: Invalid value: "team payments": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`),
+			},
+		},
+		"if matchExpressions are invalid, expect an error for each invalid expression": {
+			reqSel: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "environment", Operator: metav1.LabelSelectorOpIn},
+					{Key: "team", Operator: metav1.LabelSelectorOpExists},
+					{Key: "team", Operator: "Matches", Values: []string{"payments"}},
+				},
+			},
+			expEl: field.ErrorList{
+				field.Invalid(fldPath.Child("matchExpressions").Index(0), metav1.LabelSelectorRequirement{Key: "environment", Operator: metav1.LabelSelectorOpIn}, "values: Invalid value: []string(nil): for 'in', 'notin' operators, values set can't be empty"),
+				field.Invalid(fldPath.Child("matchExpressions").Index(2), metav1.LabelSelectorRequirement{Key: "team", Operator: "Matches", Values: []string{"payments"}}, `"Matches" is not a valid label selector operator`),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expEl, validateRequestLabels(fldPath, test.reqSel))
+		})
+	}
+}
+
+func Test_validateSelectorUpdate(t *testing.T) {
+	fldPath := field.NewPath("spec", "selector")
+	detail := `selector may not be narrowed on update, as requests it selects may no longer be approved; set the "policy.cert-manager.io/allow-selector-change" annotation to allow`
+
+	tests := map[string]struct {
+		oldSel      policyapi.CertificateRequestPolicySelector
+		newSel      policyapi.CertificateRequestPolicySelector
+		annotations map[string]string
+		expErr      field.ErrorList
+	}{
+		"unchanged selector should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}},
+			expErr: nil,
+		},
+		"issuerRef name changed should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("other-ca")}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("issuerRef", "name"), detail)},
+		},
+		"issuerRef name changed to wildcard should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("*")}},
+			expErr: nil,
+		},
+		"issuerRef name added where issuerRef was omitted should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}},
+			newSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}, IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("issuerRef", "name"), detail)},
+		},
+		"issuerRef removed should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}, Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}},
+			newSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}},
+			expErr: nil,
+		},
+		"issuerRef extended to issuerRefs should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRefs: []policyapi.CertificateRequestPolicySelectorIssuerRef{{Name: pointer.String("other-ca")}, {Name: pointer.String("my-ca")}}},
+			expErr: nil,
+		},
+		"issuerRefs entry removed should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRefs: []policyapi.CertificateRequestPolicySelectorIssuerRef{{Name: pointer.String("other-ca")}, {Name: pointer.String("my-ca")}}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRefs: []policyapi.CertificateRequestPolicySelectorIssuerRef{{Name: pointer.String("my-ca")}}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("issuerRefs"), detail)},
+		},
+		"issuerRefs replaced by a wildcard issuerRef should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRefs: []policyapi.CertificateRequestPolicySelectorIssuerRef{{Name: pointer.String("other-ca")}, {Name: pointer.String("my-ca")}}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("*")}},
+			expErr: nil,
+		},
+		"issuerRefs added where issuerRef was omitted should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}},
+			newSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}, IssuerRefs: []policyapi.CertificateRequestPolicySelectorIssuerRef{{Name: pointer.String("my-ca")}, {Name: pointer.String("other-ca")}}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("issuerRefs"), detail)},
+		},
+		"matchLabels added should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{MatchLabels: map[string]string{"foo": "bar"}}},
+			newSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{MatchLabels: map[string]string{"foo": "bar", "team": "a"}}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("namespace", "matchLabels"), detail)},
+		},
+		"matchLabels removed should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{MatchLabels: map[string]string{"foo": "bar", "team": "a"}}},
+			newSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{MatchLabels: map[string]string{"foo": "bar"}}},
+			expErr: nil,
+		},
+		"serviceAccount added should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}},
+			newSel: policyapi.CertificateRequestPolicySelector{Namespace: &policyapi.CertificateRequestPolicySelectorNamespace{}, ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("serviceAccount"), detail)},
+		},
+		"serviceAccount matchNames removed should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{MatchNames: []string{"foo", "bar"}}},
+			newSel: policyapi.CertificateRequestPolicySelector{ServiceAccount: &policyapi.CertificateRequestPolicySelectorServiceAccount{MatchNames: []string{"foo"}}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("serviceAccount", "matchNames"), detail)},
+		},
+		"secretName added should return error, even if a wildcard": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, SecretName: pointer.String("*")},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("secretName"), detail)},
+		},
+		"secretName changed to wildcard should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{SecretName: pointer.String("payments-*")},
+			newSel: policyapi.CertificateRequestPolicySelector{SecretName: pointer.String("*")},
+			expErr: nil,
+		},
+		"secretLabels matchLabels added should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{SecretLabels: &policyapi.CertificateRequestPolicySelectorSecretLabels{}},
+			newSel: policyapi.CertificateRequestPolicySelector{SecretLabels: &policyapi.CertificateRequestPolicySelectorSecretLabels{MatchLabels: map[string]string{"team": "a"}}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("secretLabels", "matchLabels"), detail)},
+		},
+		"requestLabels matchExpressions added should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: metav1.LabelSelectorOpExists}},
+			}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("requestLabels", "matchExpressions"), detail)},
+		},
+		"requestLabels matchExpressions removed should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+				MatchLabels:      map[string]string{"team": "a"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: metav1.LabelSelectorOpExists}},
+			}},
+			newSel: policyapi.CertificateRequestPolicySelector{RequestLabels: &policyapi.CertificateRequestPolicySelectorRequestLabels{
+				MatchLabels: map[string]string{"team": "a"},
+			}},
+			expErr: nil,
+		},
+		"requester groups added should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, Requester: &policyapi.CertificateRequestPolicySelectorRequester{Groups: []string{"*"}}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("requester", "groups"), detail)},
+		},
+		"requester groups extended should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, Requester: &policyapi.CertificateRequestPolicySelectorRequester{Groups: []string{"team-a"}}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, Requester: &policyapi.CertificateRequestPolicySelectorRequester{Groups: []string{"team-a", "team-b"}}},
+			expErr: nil,
+		},
+		"requestAnnotations value changed should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, RequestAnnotations: map[string]string{"team": "a"}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, RequestAnnotations: map[string]string{"team": "b"}},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("requestAnnotations"), detail)},
+		},
+		"requestAnnotations value changed to wildcard should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, RequestAnnotations: map[string]string{"team": "a"}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, RequestAnnotations: map[string]string{"team": "*"}},
+			expErr: nil,
+		},
+		"minDuration raised and maxDuration lowered should return errors": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, MinDuration: &metav1.Duration{Duration: time.Hour}, MaxDuration: &metav1.Duration{Duration: 24 * time.Hour}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, MinDuration: &metav1.Duration{Duration: 2 * time.Hour}, MaxDuration: &metav1.Duration{Duration: 12 * time.Hour}},
+			expErr: field.ErrorList{
+				field.Forbidden(fldPath.Child("minDuration"), detail),
+				field.Forbidden(fldPath.Child("maxDuration"), detail),
+			},
+		},
+		"minDuration lowered should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, MinDuration: &metav1.Duration{Duration: 2 * time.Hour}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, MinDuration: &metav1.Duration{Duration: time.Hour}},
+			expErr: nil,
+		},
+		"maxDuration added should return errors as requests without a duration are no longer selected": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, MaxDuration: &metav1.Duration{Duration: time.Hour}},
+			expErr: field.ErrorList{
+				field.Forbidden(fldPath.Child("maxDuration"), detail),
+				field.Forbidden(fldPath.Child("selectOnMissingDuration"), detail),
+			},
+		},
+		"isRenewal added should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, IsRenewal: pointer.Bool(true)},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("isRenewal"), detail)},
+		},
+		"isRenewal changed should return error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, IsRenewal: pointer.Bool(false)},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, IsRenewal: pointer.Bool(true)},
+			expErr: field.ErrorList{field.Forbidden(fldPath.Child("isRenewal"), detail)},
+		},
+		"isRenewal removed should return no error": {
+			oldSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}, IsRenewal: pointer.Bool(true)},
+			newSel: policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{}},
+			expErr: nil,
+		},
+		"narrowed selector with allow-selector-change annotation should return no error": {
+			oldSel:      policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("my-ca")}},
+			newSel:      policyapi.CertificateRequestPolicySelector{IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{Name: pointer.String("other-ca")}},
+			annotations: map[string]string{policyapi.AllowSelectorChangeAnnotationKey: ""},
+			expErr:      nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			oldPolicy := &policyapi.CertificateRequestPolicy{Spec: policyapi.CertificateRequestPolicySpec{Selector: test.oldSel}}
+			policy := &policyapi.CertificateRequestPolicy{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Spec:       policyapi.CertificateRequestPolicySpec{Selector: test.newSel},
+			}
+			assert.Equal(t, test.expErr, validateSelectorUpdate(fldPath, oldPolicy, policy))
 		})
 	}
 }
@@ -19,12 +19,18 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
 	"github.com/cert-manager/approver-policy/pkg/approver"
+	internalmanager "github.com/cert-manager/approver-policy/pkg/internal/approver/manager"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
 	"github.com/cert-manager/approver-policy/pkg/internal/webhook/tls"
 	"github.com/cert-manager/approver-policy/pkg/registry"
 )
@@ -38,6 +44,10 @@ type Options struct {
 	// shared webhook server.
 	Webhooks []approver.Webhook
 
+	// Evaluators is the list of registered Evaluators that will be used by the
+	// dry run evaluate endpoint.
+	Evaluators []approver.Evaluator
+
 	// WebhookCertificatesDir is the directory that holds the certificate and key
 	// (tls.crt, tls.key) which are used to server the Webhook server. The
 	// TLS proivder waits for these files to become available before returning
@@ -48,6 +58,33 @@ type Options struct {
 	// cert-manager-approver-policy-tls Secret is stored.
 	CASecretNamespace string
 
+	// AllowedIssuerKinds are the issuer kinds that CertificateRequestPolicies
+	// may select on. If empty, all issuer kinds are allowed.
+	AllowedIssuerKinds []string
+
+	// AllowedMetricsLabelKeys are the keys that CertificateRequestPolicies may
+	// use in `spec.metricsLabels`.
+	AllowedMetricsLabelKeys []string
+
+	// PolicyNamePattern is a regular expression that the names of created
+	// CertificateRequestPolicies must match. If empty, any name is allowed.
+	PolicyNamePattern string
+
+	// ImpactWarnings marks whether created and updated
+	// CertificateRequestPolicies are evaluated against pending
+	// CertificateRequests, with the number of requests they would newly allow
+	// or deny returned as an admission warning.
+	ImpactWarnings bool
+
+	// MaxRequestBytes is the maximum size in bytes of admitted
+	// CertificateRequestPolicies. If 0, the size is not limited.
+	MaxRequestBytes int
+
+	// PolicyCombineMode is how the decisions of the highest priority
+	// CertificateRequestPolicies are combined by the dry run evaluate and
+	// explain endpoints. Must be the same as the approver's.
+	PolicyCombineMode internalmanager.CombineMode
+
 	// ServiceName is the name of the service that exposes the webhook server.
 	// This name will be used as the DNS SAN entry to the webhook's serving
 	// certificate.
@@ -83,21 +120,64 @@ func Register(ctx context.Context, opts Options) error {
 
 	var registerdPlugins []string
 	for _, approver := range registry.Shared.Approvers() {
-		if name := approver.Name(); name != "allowed" && name != "constraints" {
+		if name := approver.Name(); name != "allowed" && name != "constraints" && name != "validations" {
 			registerdPlugins = append(registerdPlugins, name)
 		}
 	}
 
+	var policyNamePattern *regexp.Regexp
+	if len(opts.PolicyNamePattern) > 0 {
+		policyNamePattern, err = util.CompileRegex(opts.PolicyNamePattern)
+		if err != nil {
+			return fmt.Errorf("failed to compile policy name pattern: %w", err)
+		}
+	}
+
 	log.Info("registering webhook endpoints")
 	validator := &validator{
-		log:               log.WithName("validation"),
-		lister:            opts.Manager.GetCache(),
-		webhooks:          opts.Webhooks,
-		registeredPlugins: registerdPlugins,
+		log:                     log.WithName("validation"),
+		lister:                  opts.Manager.GetCache(),
+		webhooks:                opts.Webhooks,
+		registeredPlugins:       registerdPlugins,
+		allowedIssuerKinds:      opts.AllowedIssuerKinds,
+		allowedMetricsLabelKeys: opts.AllowedMetricsLabelKeys,
+		policyNamePattern:       policyNamePattern,
+		impactWarnings:          opts.ImpactWarnings,
+		maxRequestBytes:         opts.MaxRequestBytes,
+		evaluators:              opts.Evaluators,
 	}
 
 	opts.Manager.GetWebhookServer().Register("/validate", &webhook.Admission{Handler: validator})
+	// Conversion between the served versions of CertificateRequestPolicy. The
+	// CustomResourceDefinition stores v1alpha1 and converts using this webhook.
+	opts.Manager.GetWebhookServer().Register("/convert", &conversion.Webhook{})
 	opts.Manager.AddReadyzCheck("validator", validator.check)
 
+	decoder, err := admission.NewDecoder(policyapi.GlobalScheme)
+	if err != nil {
+		return fmt.Errorf("failed to build evaluate decoder: %w", err)
+	}
+	// The evaluate, explain and policies endpoints expose cluster state, so
+	// callers are authenticated and authorized against the API server.
+	auth := &delegatingAuthorizer{client: opts.Manager.GetClient()}
+
+	opts.Manager.GetWebhookServer().Register("/evaluate", &evaluator{
+		log:       log.WithName("evaluate"),
+		auth:      auth,
+		dryRunner: internalmanager.NewDryRunner(opts.Manager.GetCache(), opts.Manager.GetClient(), opts.Evaluators, opts.PolicyCombineMode),
+		decoder:   decoder,
+	})
+	opts.Manager.GetWebhookServer().Register("/explain", &explainer{
+		log:       log.WithName("explain"),
+		auth:      auth,
+		lister:    opts.Manager.GetCache(),
+		explainer: internalmanager.NewExplainer(opts.Manager.GetCache(), opts.Manager.GetClient(), opts.Evaluators, opts.PolicyCombineMode),
+	})
+	opts.Manager.GetWebhookServer().Register("/policies", &policyLister{
+		log:    log.WithName("policies"),
+		auth:   auth,
+		lister: opts.Manager.GetCache(),
+	})
+
 	return nil
 }
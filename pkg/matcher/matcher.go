@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package matcher holds the Matchers which policies may reference by name to
+// match requested values with the allowed values of a field. Downstream builds
+// may register custom Matchers in the Shared registry, typically in an init
+// function, so that policies can reference them with
+// `allowed.<field>.matcher`.
+package matcher
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	// Shared is a registry of Matchers. This is intended as a global shared
+	// registry.
+	Shared = &Registry{}
+)
+
+// Matcher matches requested values with the allowed values of a field.
+type Matcher interface {
+	// Name is the name of the Matcher, which policies use to reference it.
+	Name() string
+
+	// Matches returns whether the requested value matches the allowed value.
+	// Allowed values negated with "!" are negated before being passed to
+	// Matches.
+	Matches(allowed, requested string) bool
+}
+
+// Registry is a store of Matchers. Consumers can store Matchers, and load
+// registered Matchers by name. Matchers must be uniquely named.
+type Registry struct {
+	lock     sync.RWMutex
+	matchers map[string]Matcher
+}
+
+// Store will store Matchers into the registry.
+func (r *Registry) Store(matchers ...Matcher) *Registry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.matchers == nil {
+		r.matchers = make(map[string]Matcher)
+	}
+	for _, toStore := range matchers {
+		if _, ok := r.matchers[toStore.Name()]; ok {
+			panic("matcher already registered with same name: " + toStore.Name())
+		}
+		r.matchers[toStore.Name()] = toStore
+	}
+	return r
+}
+
+// Get returns the Matcher registered with the given name. Returns false if no
+// Matcher is registered with that name.
+func (r *Registry) Get(name string) (Matcher, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	matcher, ok := r.matchers[name]
+	return matcher, ok
+}
+
+// Names returns the sorted names of the Matchers registered in the registry.
+func (r *Registry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	names := make([]string, 0, len(r.matchers))
+	for name := range r.matchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prefix is an example custom Matcher which matches requested values which
+// start with the allowed value.
+type prefix struct{}
+
+func (prefix) Name() string                           { return "prefix" }
+func (prefix) Matches(allowed, requested string) bool { return strings.HasPrefix(requested, allowed) }
+
+func Test_Registry(t *testing.T) {
+	r := new(Registry).Store(prefix{})
+
+	m, ok := r.Get("prefix")
+	assert.True(t, ok)
+	assert.True(t, m.Matches("device-", "device-123"))
+	assert.False(t, m.Matches("device-", "host-123"))
+
+	_, ok = r.Get("unknown")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"prefix"}, r.Names())
+
+	assert.PanicsWithValue(t, "matcher already registered with same name: prefix", func() {
+		r.Store(prefix{})
+	})
+}
+
+func Test_Shared(t *testing.T) {
+	m, ok := Shared.Get(WildcardName)
+	if !assert.True(t, ok, "built-in wildcard matcher should be registered") {
+		return
+	}
+	assert.True(t, m.Matches("*.example.com", "foo.example.com"))
+	assert.False(t, m.Matches("*.example.com", "foo.example.net"))
+}
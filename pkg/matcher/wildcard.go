@@ -0,0 +1,44 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matcher
+
+import (
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// WildcardName is the name of the built-in wildcard Matcher.
+const WildcardName = "wildcard"
+
+// Load the built-in Matchers into the shared registry.
+func init() {
+	Shared.Store(wildcard{})
+}
+
+// wildcard is the built-in Matcher which matches requested values with
+// wildcards "*" in allowed values.
+type wildcard struct{}
+
+// Name of the wildcard Matcher is "wildcard".
+func (wildcard) Name() string {
+	return WildcardName
+}
+
+// Matches returns whether the requested value matches the allowed wildcard
+// pattern.
+func (wildcard) Matches(allowed, requested string) bool {
+	return util.WildcardMatches(allowed, requested)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/evaluator"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/allowed"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/constraints"
+	"github.com/cert-manager/approver-policy/pkg/internal/approver/validations"
+)
+
+// Evaluate evaluates the request against the policy with the built-in
+// allowed, constraints and validations evaluators, followed by the given
+// evaluators, as approver-policy does. Evaluators of plugins only evaluate
+// the requests selected by the plugin's selector. Fails the test if the
+// request can't be evaluated.
+func Evaluate(t testing.TB, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest, evaluators ...approver.Evaluator) evaluator.Response {
+	t.Helper()
+
+	builtin := []approver.Evaluator{allowed.Approver(), constraints.Approver(), validations.Approver()}
+	response, err := evaluator.New(append(builtin, evaluators...)...).Review(context.TODO(), policy, request)
+	if err != nil {
+		t.Fatalf("failed to evaluate request: %s", err)
+	}
+	return response
+}
+
+// AssertApproved asserts that the response approves the request.
+func AssertApproved(t testing.TB, response evaluator.Response) bool {
+	t.Helper()
+	return assert.Equal(t, evaluator.DecisionApproved, response.Decision, "expected request to be approved, got errors: %v", response.Errors.ToAggregate())
+}
+
+// AssertDenied asserts that the response denies the request. If any errors
+// are given, the request must be denied with exactly those errors.
+func AssertDenied(t testing.TB, response evaluator.Response, expErrs ...*field.Error) bool {
+	t.Helper()
+	if !assert.Equal(t, evaluator.DecisionDenied, response.Decision, "expected request to be denied") {
+		return false
+	}
+	if len(expErrs) == 0 {
+		return true
+	}
+	return assert.Equal(t, field.ErrorList(expErrs), response.Errors, "unexpected denial errors")
+}
+
+// AssertAllowed asserts that the webhook allows the policy. Fails the test if
+// the webhook errors.
+func AssertAllowed(t testing.TB, webhook approver.Webhook, policy *policyapi.CertificateRequestPolicy) bool {
+	t.Helper()
+	response := validate(t, webhook, policy)
+	return assert.True(t, response.Allowed, "expected policy to be allowed, got errors: %v", response.Errors.ToAggregate())
+}
+
+// AssertRejected asserts that the webhook doesn't allow the policy. If any
+// errors are given, the policy must be rejected with exactly those errors.
+// Fails the test if the webhook errors.
+func AssertRejected(t testing.TB, webhook approver.Webhook, policy *policyapi.CertificateRequestPolicy, expErrs ...*field.Error) bool {
+	t.Helper()
+	response := validate(t, webhook, policy)
+	if !assert.False(t, response.Allowed, "expected policy to be rejected") {
+		return false
+	}
+	if len(expErrs) == 0 {
+		return true
+	}
+	return assert.Equal(t, field.ErrorList(expErrs), response.Errors, "unexpected validation errors")
+}
+
+// validate returns the response of the webhook validating the policy, failing
+// the test if the webhook errors.
+func validate(t testing.TB, webhook approver.Webhook, policy *policyapi.CertificateRequestPolicy) approver.WebhookValidationResponse {
+	t.Helper()
+	response, err := webhook.Validate(context.TODO(), policy)
+	if err != nil {
+		t.Fatalf("failed to validate policy: %s", err)
+	}
+	return response
+}
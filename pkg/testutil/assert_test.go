@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+	"github.com/cert-manager/approver-policy/pkg/approver"
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+// teamPlugin is an example plugin, which requires the common name of
+// requests to be prefixed by the team configured in its values.
+type teamPlugin struct{}
+
+func (teamPlugin) Name() string {
+	return "team"
+}
+
+func (teamPlugin) Evaluate(_ context.Context, policy *policyapi.CertificateRequestPolicy, request *cmapi.CertificateRequest) (approver.EvaluationResponse, error) {
+	csr, err := util.DecodeCSR(request.Spec.Request)
+	if err != nil {
+		return approver.EvaluationResponse{}, err
+	}
+
+	team := policy.Spec.Plugins["team"].Values["team"]
+	if !strings.HasPrefix(csr.Subject.CommonName, team+".") {
+		fldPath := field.NewPath("spec", "plugins").Key("team").Child("values").Key("team")
+		return approver.EvaluationResponse{
+			Result: approver.ResultDenied,
+			Errors: field.ErrorList{field.Invalid(fldPath, csr.Subject.CommonName, fmt.Sprintf("common name must be prefixed by %q", team+"."))},
+		}, nil
+	}
+
+	return approver.EvaluationResponse{Result: approver.ResultNotDenied}, nil
+}
+
+func (teamPlugin) Validate(_ context.Context, policy *policyapi.CertificateRequestPolicy) (approver.WebhookValidationResponse, error) {
+	if len(policy.Spec.Plugins["team"].Values["team"]) == 0 {
+		fldPath := field.NewPath("spec", "plugins").Key("team").Child("values").Key("team")
+		return approver.WebhookValidationResponse{Allowed: false, Errors: field.ErrorList{field.Required(fldPath, "team must be defined")}}, nil
+	}
+	return approver.WebhookValidationResponse{Allowed: true}, nil
+}
+
+func Test_teamPlugin(t *testing.T) {
+	policy := NewPolicy("team-a",
+		WithAllowedCommonName("*"),
+		WithMaxDuration(time.Hour*24),
+		WithPlugin("team", map[string]string{"team": "team-a"}),
+	)
+
+	AssertAllowed(t, teamPlugin{}, policy)
+	AssertRejected(t, teamPlugin{}, NewPolicy("no-team", WithPlugin("team", nil)),
+		field.Required(field.NewPath("spec", "plugins").Key("team").Child("values").Key("team"), "team must be defined"),
+	)
+
+	AssertApproved(t, Evaluate(t, policy, MustNewRequest(t, RequestParams{CommonName: "team-a.example.com", Duration: time.Hour}), teamPlugin{}))
+	AssertDenied(t, Evaluate(t, policy, MustNewRequest(t, RequestParams{CommonName: "team-b.example.com", Duration: time.Hour}), teamPlugin{}),
+		field.Invalid(field.NewPath("spec", "plugins").Key("team").Child("values").Key("team"), "team-b.example.com", `common name must be prefixed by "team-a."`),
+	)
+
+	// The built-in evaluators evaluate the request along with the plugin.
+	AssertDenied(t, Evaluate(t, policy, MustNewRequest(t, RequestParams{CommonName: "team-a.example.com", Duration: time.Hour * 48}), teamPlugin{}),
+		field.Invalid(field.NewPath("spec", "constraints", "maxDuration"), "48h0m0s", "24h0m0s"),
+	)
+}
+
+func Test_Assert(t *testing.T) {
+	var (
+		policy = NewPolicy("test", WithAllowedDNSNames("*.example.com"))
+
+		approved = Evaluate(t, policy, MustNewRequest(t, RequestParams{DNSNames: []string{"foo.example.com"}}))
+		denied   = Evaluate(t, policy, MustNewRequest(t, RequestParams{DNSNames: []string{"foo.example.net"}}))
+		deniedBy = field.Invalid(field.NewPath("spec", "allowed", "dnsNames", "values"), []string{"foo.example.net"}, "*.example.com")
+	)
+
+	tests := map[string]struct {
+		assert func(t testing.TB) bool
+		expOK  bool
+	}{
+		"if an approved response is asserted to be approved, return true": {
+			assert: func(t testing.TB) bool { return AssertApproved(t, approved) },
+			expOK:  true,
+		},
+		"if a denied response is asserted to be approved, return false": {
+			assert: func(t testing.TB) bool { return AssertApproved(t, denied) },
+			expOK:  false,
+		},
+		"if a denied response is asserted to be denied, return true": {
+			assert: func(t testing.TB) bool { return AssertDenied(t, denied) },
+			expOK:  true,
+		},
+		"if a denied response is asserted to be denied with its errors, return true": {
+			assert: func(t testing.TB) bool { return AssertDenied(t, denied, deniedBy) },
+			expOK:  true,
+		},
+		"if a denied response is asserted to be denied with other errors, return false": {
+			assert: func(t testing.TB) bool {
+				return AssertDenied(t, denied, field.Invalid(field.NewPath("spec", "allowed", "commonName", "value"), "foo", "bar"))
+			},
+			expOK: false,
+		},
+		"if an approved response is asserted to be denied, return false": {
+			assert: func(t testing.TB) bool { return AssertDenied(t, approved) },
+			expOK:  false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Failed assertions are recorded on a separate test, so they don't
+			// fail this one.
+			assert.Equal(t, test.expOK, test.assert(new(testing.T)))
+		})
+	}
+}
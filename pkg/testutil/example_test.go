@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+
+	"github.com/cert-manager/approver-policy/pkg/evaluator"
+	"github.com/cert-manager/approver-policy/pkg/testutil"
+)
+
+func ExampleNewRequest() {
+	policy := testutil.NewPolicy("example",
+		testutil.WithIssuerRef("my-ca", "Issuer", "cert-manager.io"),
+		testutil.WithAllowedDNSNames("*.example.com"),
+		testutil.WithMaxDuration(time.Hour*24*90),
+	)
+
+	for _, dnsName := range []string{"foo.example.com", "foo.example.net"} {
+		request, err := testutil.NewRequest(testutil.RequestParams{
+			Namespace: "default",
+			IssuerRef: cmmeta.ObjectReference{Name: "my-ca", Kind: "Issuer", Group: "cert-manager.io"},
+			Duration:  time.Hour * 24 * 30,
+			DNSNames:  []string{dnsName},
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		decision, errs, err := evaluator.Evaluate(context.TODO(), policy, request)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println(dnsName, decision, errs.ToAggregate())
+	}
+
+	// Output:
+	// foo.example.com Approved <nil>
+	// foo.example.net Denied spec.allowed.dnsNames.values: Invalid value: []string{"foo.example.net"}: *.example.com
+}
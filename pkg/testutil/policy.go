@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyapi "github.com/cert-manager/approver-policy/pkg/apis/policy/v1alpha1"
+)
+
+// PolicyModifier modifies a CertificateRequestPolicy built by NewPolicy.
+type PolicyModifier func(*policyapi.CertificateRequestPolicy)
+
+// NewPolicy returns a CertificateRequestPolicy with the given name, which
+// selects every request and allows nothing, modified by the given modifiers
+// in order.
+func NewPolicy(name string, mods ...PolicyModifier) *policyapi.CertificateRequestPolicy {
+	policy := &policyapi.CertificateRequestPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: policyapi.CertificateRequestPolicySpec{
+			Selector: policyapi.CertificateRequestPolicySelector{
+				IssuerRef: &policyapi.CertificateRequestPolicySelectorIssuerRef{},
+			},
+		},
+	}
+	for _, mod := range mods {
+		mod(policy)
+	}
+	return policy
+}
+
+// allowedOf returns the allowed attributes of the policy, defining them if
+// omitted.
+func allowedOf(policy *policyapi.CertificateRequestPolicy) *policyapi.CertificateRequestPolicyAllowed {
+	if policy.Spec.Allowed == nil {
+		policy.Spec.Allowed = new(policyapi.CertificateRequestPolicyAllowed)
+	}
+	return policy.Spec.Allowed
+}
+
+// constraintsOf returns the constraints of the policy, defining them if
+// omitted.
+func constraintsOf(policy *policyapi.CertificateRequestPolicy) *policyapi.CertificateRequestPolicyConstraints {
+	if policy.Spec.Constraints == nil {
+		policy.Spec.Constraints = new(policyapi.CertificateRequestPolicyConstraints)
+	}
+	return policy.Spec.Constraints
+}
+
+// WithAllowedCommonName allows the common name, which may contain wildcards.
+func WithAllowedCommonName(value string) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		allowedOf(policy).CommonName = &policyapi.CertificateRequestPolicyAllowedString{Value: &value}
+	}
+}
+
+// WithAllowedDNSNames allows the DNS names, which may contain wildcards.
+func WithAllowedDNSNames(values ...string) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		allowedOf(policy).DNSNames = &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &values}
+	}
+}
+
+// WithAllowedIPAddresses allows the IP addresses, which may contain wildcards.
+func WithAllowedIPAddresses(values ...string) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		allowedOf(policy).IPAddresses = &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &values}
+	}
+}
+
+// WithAllowedURIs allows the URIs, which may contain wildcards.
+func WithAllowedURIs(values ...string) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		allowedOf(policy).URIs = &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &values}
+	}
+}
+
+// WithAllowedEmailAddresses allows the email addresses, which may contain
+// wildcards.
+func WithAllowedEmailAddresses(values ...string) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		allowedOf(policy).EmailAddresses = &policyapi.CertificateRequestPolicyAllowedStringSlice{Values: &values}
+	}
+}
+
+// WithAllowedIsCA allows requests with the given `spec.isCA`.
+func WithAllowedIsCA(isCA bool) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		allowedOf(policy).IsCA = &isCA
+	}
+}
+
+// WithAllowedUsages allows the key usages.
+func WithAllowedUsages(usages ...cmapi.KeyUsage) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		allowedOf(policy).Usages = &usages
+	}
+}
+
+// WithMinDuration constrains the requested duration to at least the given
+// duration.
+func WithMinDuration(duration time.Duration) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		constraintsOf(policy).MinDuration = &metav1.Duration{Duration: duration}
+	}
+}
+
+// WithMaxDuration constrains the requested duration to at most the given
+// duration.
+func WithMaxDuration(duration time.Duration) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		constraintsOf(policy).MaxDuration = &metav1.Duration{Duration: duration}
+	}
+}
+
+// WithIssuerRef selects requests for the issuer with the given name, kind and
+// group, which may contain wildcards. Empty values match any value.
+func WithIssuerRef(name, kind, group string) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		policy.Spec.Selector.IssuerRef = &policyapi.CertificateRequestPolicySelectorIssuerRef{
+			Name:  optional(name),
+			Kind:  optional(kind),
+			Group: optional(group),
+		}
+	}
+}
+
+// optional returns a pointer to the value, or nil if it is empty.
+func optional(value string) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	return &value
+}
+
+// WithPlugin configures the named plugin with the given values.
+func WithPlugin(name string, values map[string]string) PolicyModifier {
+	return func(policy *policyapi.CertificateRequestPolicy) {
+		if policy.Spec.Plugins == nil {
+			policy.Spec.Plugins = make(map[string]policyapi.CertificateRequestPolicyPluginData)
+		}
+		policy.Spec.Plugins[name] = policyapi.CertificateRequestPolicyPluginData{Values: values}
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutil provides helpers for unit testing approver-policy plugins
+// against realistic inputs. CertificateRequests are built with real CSRs from
+// parameters, policies are built from modifiers, and requests are evaluated
+// by the same evaluator used by approver-policy.
+package testutil
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RequestParams are the parameters of a CertificateRequest built by
+// NewRequest. Zero values are omitted from the request.
+type RequestParams struct {
+	// Name and Namespace are the name and namespace of the request. Name
+	// defaults to "test".
+	Name, Namespace string
+
+	// IssuerRef is the `spec.issuerRef` of the request.
+	IssuerRef cmmeta.ObjectReference
+
+	// Username and Groups are the identity of the requester, as recorded by
+	// cert-manager in `spec.username` and `spec.groups`.
+	Username string
+	Groups   []string
+
+	// Duration is the requested duration of the certificate.
+	Duration time.Duration
+
+	// IsCA and Usages are the `spec.isCA` and `spec.usages` of the request.
+	// They are not encoded in the CSR.
+	IsCA   bool
+	Usages []cmapi.KeyUsage
+
+	// KeyAlgorithm is the algorithm of the key which signs the CSR, one of
+	// x509.RSA, x509.ECDSA or x509.Ed25519. Defaults to x509.ECDSA.
+	KeyAlgorithm x509.PublicKeyAlgorithm
+
+	// CommonName and Subject are the subject of the CSR, where CommonName
+	// takes precedence over the common name of Subject.
+	CommonName string
+	Subject    pkix.Name
+
+	// DNSNames, IPAddresses, URIs and EmailAddresses are the subject
+	// alternative names of the CSR. IPAddresses and URIs must be parsable.
+	DNSNames       []string
+	IPAddresses    []string
+	URIs           []string
+	EmailAddresses []string
+}
+
+// NewRequest returns a CertificateRequest built from the parameters, which
+// contains a CSR signed by a newly generated key.
+func NewRequest(params RequestParams) (*cmapi.CertificateRequest, error) {
+	keyAlgorithm := params.KeyAlgorithm
+	if keyAlgorithm == x509.UnknownPublicKeyAlgorithm {
+		keyAlgorithm = x509.ECDSA
+	}
+
+	csr, _, err := gen.CSR(keyAlgorithm,
+		func(csr *x509.CertificateRequest) error {
+			csr.Subject = params.Subject
+			if len(params.CommonName) > 0 {
+				csr.Subject.CommonName = params.CommonName
+			}
+			return nil
+		},
+		gen.SetCSRDNSNames(params.DNSNames...),
+		gen.SetCSRIPAddressesFromStrings(params.IPAddresses...),
+		gen.SetCSRURIsFromStrings(params.URIs...),
+		gen.SetCSREmails(params.EmailAddresses),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CSR: %w", err)
+	}
+
+	name := params.Name
+	if len(name) == 0 {
+		name = "test"
+	}
+
+	request := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: params.Namespace},
+		Spec: cmapi.CertificateRequestSpec{
+			Request:   csr,
+			IssuerRef: params.IssuerRef,
+			Username:  params.Username,
+			Groups:    params.Groups,
+			IsCA:      params.IsCA,
+			Usages:    params.Usages,
+		},
+	}
+	if params.Duration > 0 {
+		request.Spec.Duration = &metav1.Duration{Duration: params.Duration}
+	}
+
+	return request, nil
+}
+
+// MustNewRequest returns a CertificateRequest built from the parameters as
+// NewRequest, failing the test if it can't be built.
+func MustNewRequest(t testing.TB, params RequestParams) *cmapi.CertificateRequest {
+	t.Helper()
+	request, err := NewRequest(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
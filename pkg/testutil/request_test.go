@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cert-manager/approver-policy/pkg/internal/util"
+)
+
+func Test_NewRequest(t *testing.T) {
+	tests := map[string]struct {
+		params RequestParams
+
+		expKeyAlgorithm x509.PublicKeyAlgorithm
+		expErr          bool
+	}{
+		"if no parameters are given, return a request with an ECDSA CSR": {
+			params:          RequestParams{},
+			expKeyAlgorithm: x509.ECDSA,
+		},
+		"if the key algorithm is given, return a request with a CSR of that algorithm": {
+			params:          RequestParams{KeyAlgorithm: x509.Ed25519},
+			expKeyAlgorithm: x509.Ed25519,
+		},
+		"if an IP address can't be parsed, return an error": {
+			params: RequestParams{IPAddresses: []string{"not-an-ip"}},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			request, err := NewRequest(test.params)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			if test.expErr {
+				return
+			}
+
+			csr, err := util.DecodeCSR(request.Spec.Request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expKeyAlgorithm, csr.PublicKeyAlgorithm)
+			assert.NoError(t, csr.CheckSignature())
+		})
+	}
+}
+
+func Test_NewRequest_params(t *testing.T) {
+	request := MustNewRequest(t, RequestParams{
+		Name:           "my-request",
+		Namespace:      "team-a",
+		IssuerRef:      cmmeta.ObjectReference{Name: "my-ca", Kind: "Issuer", Group: "cert-manager.io"},
+		Username:       "system:serviceaccount:team-a:app",
+		Groups:         []string{"system:serviceaccounts"},
+		Duration:       time.Hour,
+		IsCA:           true,
+		Usages:         []cmapi.KeyUsage{cmapi.UsageCertSign},
+		CommonName:     "example.com",
+		Subject:        pkix.Name{CommonName: "ignored", Organization: []string{"example"}},
+		DNSNames:       []string{"example.com"},
+		IPAddresses:    []string{"10.0.0.1"},
+		URIs:           []string{"spiffe://example.com/app"},
+		EmailAddresses: []string{"app@example.com"},
+	})
+
+	assert.Equal(t, metav1.ObjectMeta{Name: "my-request", Namespace: "team-a"}, request.ObjectMeta)
+	assert.Equal(t, cmmeta.ObjectReference{Name: "my-ca", Kind: "Issuer", Group: "cert-manager.io"}, request.Spec.IssuerRef)
+	assert.Equal(t, "system:serviceaccount:team-a:app", request.Spec.Username)
+	assert.Equal(t, []string{"system:serviceaccounts"}, request.Spec.Groups)
+	assert.Equal(t, &metav1.Duration{Duration: time.Hour}, request.Spec.Duration)
+	assert.True(t, request.Spec.IsCA)
+	assert.Equal(t, []cmapi.KeyUsage{cmapi.UsageCertSign}, request.Spec.Usages)
+
+	csr, err := util.DecodeCSR(request.Spec.Request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "example.com", csr.Subject.CommonName)
+	assert.Equal(t, []string{"example"}, csr.Subject.Organization)
+	assert.Equal(t, []string{"example.com"}, csr.DNSNames)
+	assert.Equal(t, "10.0.0.1", csr.IPAddresses[0].String())
+	assert.Equal(t, "spiffe://example.com/app", csr.URIs[0].String())
+	assert.Equal(t, []string{"app@example.com"}, csr.EmailAddresses)
+}